@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/fastrand"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+const (
+	// Secp256k1PublicKeySize defines the size of (compressed) secp256k1 public keys in bytes.
+	Secp256k1PublicKeySize = secp256k1.PubKeyBytesLenCompressed
+
+	// Secp256k1SecretKeySize defines the size of secp256k1 secret keys in bytes.
+	Secp256k1SecretKeySize = secp256k1.PrivKeyBytesLen
+
+	// Secp256k1SignatureSize defines the size of secp256k1 signatures in bytes,
+	// the R and S components of the ECDSA signature, each encoded as a 32-byte big-endian value.
+	Secp256k1SignatureSize = 64
+)
+
+var (
+	// ErrInvalidSecp256k1PublicKey is returned if a secp256k1 public key is provided,
+	// which is not a valid (compressed) point on the secp256k1 curve.
+	ErrInvalidSecp256k1PublicKey = errors.New("invalid secp256k1 public key")
+)
+
+type (
+	// Secp256k1PublicKey is a compressed secp256k1 public key,
+	// which can be used to verify secp256k1 signatures.
+	Secp256k1PublicKey [Secp256k1PublicKeySize]byte
+
+	// Secp256k1SecretKey can be used to sign data for the corresponding secp256k1 public key.
+	Secp256k1SecretKey [Secp256k1SecretKeySize]byte
+
+	// Secp256k1Signature is a canonical (low-S) ECDSA signature over the secp256k1 curve,
+	// proving that data was signed by the owner of a particular secp256k1 public key's corresponding secret key.
+	Secp256k1Signature [Secp256k1SignatureSize]byte
+)
+
+var (
+	// nilSecp256k1SecretKey defines a pure nil secp256k1 secret key
+	nilSecp256k1SecretKey = Secp256k1SecretKey{}
+	// nilSecp256k1PublicKey defines a pure nil secp256k1 public key
+	nilSecp256k1PublicKey = Secp256k1PublicKey{}
+)
+
+// PublicKey returns the (compressed) public key that corresponds to a secp256k1 secret key.
+func (sk Secp256k1SecretKey) PublicKey() (pk Secp256k1PublicKey) {
+	copy(pk[:], secp256k1.PrivKeyFromBytes(sk[:]).PubKey().SerializeCompressed())
+	return
+}
+
+// IsNil returns true if this secret key equals to a pure zero array.
+func (sk Secp256k1SecretKey) IsNil() bool {
+	return sk == nilSecp256k1SecretKey
+}
+
+// IsNil returns true if this public key equals to a pure zero array.
+func (pk Secp256k1PublicKey) IsNil() bool {
+	return pk == nilSecp256k1PublicKey
+}
+
+// GenerateSecp256k1KeyPair creates a secp256k1 public-secret keypair
+// that can be used to sign and verify messages.
+func GenerateSecp256k1KeyPair() (sk Secp256k1SecretKey, pk Secp256k1PublicKey) {
+	var entropy [EntropySize]byte
+	fastrand.Read(entropy[:])
+	return GenerateSecp256k1KeyPairDeterministic(entropy)
+}
+
+// GenerateSecp256k1KeyPairDeterministic generates secp256k1 keys deterministically using the input
+// entropy. The input entropy must be 32 bytes in length.
+func GenerateSecp256k1KeyPairDeterministic(entropy [EntropySize]byte) (sk Secp256k1SecretKey, pk Secp256k1PublicKey) {
+	var scalar secp256k1.ModNScalar
+	// the entropy is only used as-is if it is a valid secret key (0 < key < N),
+	// otherwise it is hashed until it is, which is astronomically unlikely to be required
+	for overflow := scalar.SetBytes(&entropy) != 0; overflow || scalar.IsZero(); overflow = scalar.SetBytes(&entropy) != 0 {
+		entropy = HashBytes(entropy[:])
+	}
+	sk = Secp256k1SecretKey(scalar.Bytes())
+	pk = sk.PublicKey()
+	return
+}
+
+// SignHashSecp256k1 signs a message using a secp256k1 secret key.
+// The produced signature is deterministic and canonical, in accordance with RFC6979 and BIP0062.
+func SignHashSecp256k1(data Hash, sk Secp256k1SecretKey) (sig Secp256k1Signature) {
+	// the compact format prefixes the R and S components with a recovery code,
+	// which is not part of our signatures
+	compactSig := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(sk[:]), data[:], true)
+	copy(sig[:], compactSig[1:])
+	return
+}
+
+// VerifyHashSecp256k1 uses a secp256k1 public key and input data to verify a signature.
+// Only canonical (low-S) signatures are considered valid,
+// such that a signature cannot be malleated into another valid signature.
+func VerifyHashSecp256k1(data Hash, pk Secp256k1PublicKey, sig Secp256k1Signature) error {
+	pubKey, err := secp256k1.ParsePubKey(pk[:])
+	if err != nil {
+		return ErrInvalidSecp256k1PublicKey
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:32]) || r.IsZero() {
+		return ErrInvalidSignature
+	}
+	if s.SetByteSlice(sig[32:]) || s.IsZero() || s.IsOverHalfOrder() {
+		return ErrInvalidSignature
+	}
+	if !ecdsa.NewSignature(&r, &s).Verify(data[:], pubKey) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestSecp256k1PublicKey ensures the public key of a known secret key
+// is the compressed generator point of the secp256k1 curve.
+func TestSecp256k1PublicKey(t *testing.T) {
+	var sk Secp256k1SecretKey
+	sk[Secp256k1SecretKeySize-1] = 1
+	pk := sk.PublicKey()
+	const expected = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	if str := hex.EncodeToString(pk[:]); str != expected {
+		t.Errorf("unexpected public key: %s != %s", str, expected)
+	}
+}
+
+// TestUnitSecp256k1Signing creates a bunch of keypairs and signs random data with each of
+// them, ensuring the signatures are deterministic and canonical.
+func TestUnitSecp256k1Signing(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		sk, pk := GenerateSecp256k1KeyPair()
+		if sk.IsNil() || pk.IsNil() {
+			t.Fatal("generated nil key pair")
+		}
+		if sk.PublicKey() != pk {
+			t.Fatal("public key does not match secret key")
+		}
+
+		var data Hash
+		fastrand.Read(data[:])
+		sig := SignHashSecp256k1(data, sk)
+		if err := VerifyHashSecp256k1(data, pk, sig); err != nil {
+			t.Fatal(err)
+		}
+		if SignHashSecp256k1(data, sk) != sig {
+			t.Fatal("signature is expected to be deterministic")
+		}
+
+		// tampered data is not accepted
+		data[0]++
+		if err := VerifyHashSecp256k1(data, pk, sig); err != ErrInvalidSignature {
+			t.Fatalf("expected tampered data to be rejected, got: %v", err)
+		}
+		data[0]--
+
+		// the malleated (high-S) signature is not accepted
+		var s, n big.Int
+		s.SetBytes(sig[32:])
+		n.SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+		s.Sub(&n, &s)
+		malleated := sig
+		sBytes := s.Bytes()
+		for j := range malleated[32:] {
+			malleated[32+j] = 0
+		}
+		copy(malleated[Secp256k1SignatureSize-len(sBytes):], sBytes)
+		if err := VerifyHashSecp256k1(data, pk, malleated); err != ErrInvalidSignature {
+			t.Fatalf("expected high-S signature to be rejected, got: %v", err)
+		}
+	}
+}
+
+// TestSecp256k1KeyGenerationDeterministic ensures the same entropy results in the same keys.
+func TestSecp256k1KeyGenerationDeterministic(t *testing.T) {
+	var entropy [EntropySize]byte
+	fastrand.Read(entropy[:])
+	skA, pkA := GenerateSecp256k1KeyPairDeterministic(entropy)
+	skB, pkB := GenerateSecp256k1KeyPairDeterministic(entropy)
+	if skA != skB || pkA != pkB {
+		t.Error("key generation is expected to be deterministic")
+	}
+
+	// entropy which is not a valid secret key is still usable
+	for i := range entropy {
+		entropy[i] = 0xff
+	}
+	sk, pk := GenerateSecp256k1KeyPairDeterministic(entropy)
+	if sk.IsNil() || pk.IsNil() {
+		t.Error("expected a valid key pair for overflowing entropy")
+	}
+	var data Hash
+	if err := VerifyHashSecp256k1(data, pk, SignHashSecp256k1(data, sk)); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSecp256k1InvalidPublicKey ensures invalid public keys are rejected.
+func TestSecp256k1InvalidPublicKey(t *testing.T) {
+	sk, _ := GenerateSecp256k1KeyPair()
+	var data Hash
+	sig := SignHashSecp256k1(data, sk)
+	var pk Secp256k1PublicKey
+	if err := VerifyHashSecp256k1(data, pk, sig); err != ErrInvalidSecp256k1PublicKey {
+		t.Errorf("expected nil public key to be rejected, got: %v", err)
+	}
+}
@@ -10,8 +10,15 @@ import (
 	"github.com/threefoldtech/rivine/examples/rivchain/pkg/config"
 
 	"github.com/threefoldtech/rivine/examples/rivchain/pkg/types"
+	adaptorswapcli "github.com/threefoldtech/rivine/extensions/adaptorswap/client"
 	authcointxcli "github.com/threefoldtech/rivine/extensions/authcointx/client"
+	compositeconditioncli "github.com/threefoldtech/rivine/extensions/compositecondition/client"
 	mintingcli "github.com/threefoldtech/rivine/extensions/minting/client"
+	nftcli "github.com/threefoldtech/rivine/extensions/nft/client"
+	paymentchannelcli "github.com/threefoldtech/rivine/extensions/paymentchannel/client"
+	thresholdsigcli "github.com/threefoldtech/rivine/extensions/thresholdsig/client"
+	tokenscli "github.com/threefoldtech/rivine/extensions/tokens/client"
+	vaultcli "github.com/threefoldtech/rivine/extensions/vault/client"
 
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/pkg/client"
@@ -54,6 +61,55 @@ func main() {
 		},
 	)
 
+	// register tokens specific commands
+	err = tokenscli.CreateConsensusCmd(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = tokenscli.CreateExploreCmd(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = tokenscli.CreateWalletCmds(
+		cliClient.CommandLineClient,
+		types.TransactionVersionTokenIssuance,
+		types.TransactionVersionTokenTransfer,
+	)
+	exitIfError(err)
+
+	// register NFT specific commands
+	err = nftcli.CreateConsensusCmd(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = nftcli.CreateExploreCmd(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = nftcli.CreateWalletCmds(
+		cliClient.CommandLineClient,
+		types.TransactionVersionNFTMint,
+		types.TransactionVersionNFTTransfer,
+		types.TransactionVersionNFTBurn,
+	)
+	exitIfError(err)
+
+	// register payment channel specific commands
+	err = paymentchannelcli.CreateConsensusCmd(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = paymentchannelcli.CreateExploreCmd(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = paymentchannelcli.CreateWalletCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+
+	// register threshold signature specific commands
+	err = thresholdsigcli.CreateWalletCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+
+	// register composite condition specific commands
+	err = compositeconditioncli.CreateWalletCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+
+	// register vault specific commands
+	err = vaultcli.CreateWalletCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+
+	// register adaptor swap specific commands
+	err = adaptorswapcli.CreateAdaptorSwapCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+
 	// define preRun function
 	cliClient.PreRunE = func(cfg *client.Config) (*client.Config, error) {
 		if cfg == nil {
@@ -4,8 +4,18 @@ import (
 	rivchaintypes "github.com/threefoldtech/rivine/examples/rivchain/pkg/types"
 	"github.com/threefoldtech/rivine/extensions/authcointx"
 	authcointxcli "github.com/threefoldtech/rivine/extensions/authcointx/client"
+	"github.com/threefoldtech/rivine/extensions/compositecondition"
 	"github.com/threefoldtech/rivine/extensions/minting"
 	mintingcli "github.com/threefoldtech/rivine/extensions/minting/client"
+	"github.com/threefoldtech/rivine/extensions/nft"
+	nftcli "github.com/threefoldtech/rivine/extensions/nft/client"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	paymentchannelcli "github.com/threefoldtech/rivine/extensions/paymentchannel/client"
+	"github.com/threefoldtech/rivine/extensions/relativetimelock"
+	"github.com/threefoldtech/rivine/extensions/thresholdsig"
+	"github.com/threefoldtech/rivine/extensions/tokens"
+	tokenscli "github.com/threefoldtech/rivine/extensions/tokens/client"
+	"github.com/threefoldtech/rivine/extensions/vault"
 	"github.com/threefoldtech/rivine/types"
 
 	"github.com/threefoldtech/rivine/pkg/client"
@@ -13,6 +23,11 @@ import (
 
 func RegisterDevnetTransactions(bc client.BaseClient) {
 	registerTransactions(bc)
+	// threshold signature, relative time lock, composite and vault conditions are only supported on devnet
+	thresholdsig.RegisterUnlockTypes()
+	relativetimelock.RegisterUnlockConditionType()
+	compositecondition.RegisterUnlockTypes()
+	vault.RegisterUnlockConditionTypes()
 }
 
 func RegisterStandardTransactions(bc client.BaseClient) {
@@ -50,4 +65,49 @@ func registerTransactions(bc client.BaseClient) {
 		AuthInfoGetter:     authCoinTxCLI,
 		TransactionVersion: rivchaintypes.TransactionVersionAuthAddressUpdate,
 	})
+
+	// create tokens plugin client...
+	tokensCLI := tokenscli.NewPluginConsensusClient(bc)
+	// ...and register tokens tx types
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionTokenIssuance, tokens.TokenIssuanceTransactionController{
+		AssetGetter:        tokensCLI,
+		TransactionVersion: rivchaintypes.TransactionVersionTokenIssuance,
+	})
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionTokenTransfer, tokens.TokenTransferTransactionController{
+		TokenOutputGetter:  tokensCLI,
+		TransactionVersion: rivchaintypes.TransactionVersionTokenTransfer,
+	})
+
+	// create NFT plugin client...
+	nftCLI := nftcli.NewPluginConsensusClient(bc)
+	// ...and register NFT tx types
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionNFTMint, nft.NFTMintTransactionController{
+		TransactionVersion: rivchaintypes.TransactionVersionNFTMint,
+	})
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionNFTTransfer, nft.NFTTransferTransactionController{
+		NFTGetter:          nftCLI,
+		TransactionVersion: rivchaintypes.TransactionVersionNFTTransfer,
+	})
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionNFTBurn, nft.NFTBurnTransactionController{
+		NFTGetter:          nftCLI,
+		TransactionVersion: rivchaintypes.TransactionVersionNFTBurn,
+	})
+
+	// create payment channel plugin client...
+	paymentChannelCLI := paymentchannelcli.NewPluginConsensusClient(bc)
+	// ...and register payment channel tx types
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionChannelOpen, paymentchannel.ChannelOpenTransactionController{
+		TransactionVersion: rivchaintypes.TransactionVersionChannelOpen,
+	})
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionChannelClose, paymentchannel.ChannelCloseTransactionController{
+		ChannelGetter:      paymentChannelCLI,
+		TransactionVersion: rivchaintypes.TransactionVersionChannelClose,
+	})
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionChannelUnilateralClose, paymentchannel.ChannelUnilateralCloseTransactionController{
+		ChannelGetter:      paymentChannelCLI,
+		TransactionVersion: rivchaintypes.TransactionVersionChannelUnilateralClose,
+	})
+	types.RegisterTransactionVersion(rivchaintypes.TransactionVersionChannelSettle, paymentchannel.ChannelSettleTransactionController{
+		TransactionVersion: rivchaintypes.TransactionVersionChannelSettle,
+	})
 }
@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/threefoldtech/rivine/persist"
 	"github.com/threefoldtech/rivine/pkg/cli"
 	"github.com/threefoldtech/rivine/pkg/daemon"
 	"github.com/threefoldtech/rivine/profile"
@@ -49,6 +50,10 @@ func (cmds *commands) rootCommand(*cobra.Command, []string) {
 	// Process the config variables, cleaning up slightly invalid values
 	cmds.cfg.Config = daemon.ProcessConfig(cmds.cfg.Config)
 
+	// Configure the format, levels and rotation of all module loggers,
+	// prior to any module (or the profiler) opening its log file.
+	persist.SetLogConfig(cmds.cfg.LogConfig)
+
 	// Create the profiling directory if profiling is enabled.
 	if cmds.cfg.Profile {
 		go profile.StartContinuousProfile(cmds.cfg.ProfileDir, cmds.cfg.BlockchainInfo, cmds.cfg.VerboseLogging)
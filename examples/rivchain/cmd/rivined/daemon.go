@@ -20,16 +20,33 @@ import (
 	"github.com/threefoldtech/rivine/extensions/authcointx"
 	authcointxapi "github.com/threefoldtech/rivine/extensions/authcointx/api"
 
+	"github.com/threefoldtech/rivine/extensions/tokens"
+	tokensapi "github.com/threefoldtech/rivine/extensions/tokens/api"
+
+	"github.com/threefoldtech/rivine/extensions/compositecondition"
+	"github.com/threefoldtech/rivine/extensions/nft"
+	nftapi "github.com/threefoldtech/rivine/extensions/nft/api"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	paymentchannelapi "github.com/threefoldtech/rivine/extensions/paymentchannel/api"
+	paymentchannelmanager "github.com/threefoldtech/rivine/extensions/paymentchannel/manager"
+	"github.com/threefoldtech/rivine/extensions/relativetimelock"
+	"github.com/threefoldtech/rivine/extensions/thresholdsig"
+	"github.com/threefoldtech/rivine/extensions/vault"
+
 	"github.com/julienschmidt/httprouter"
 	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/atomicswap"
 	"github.com/threefoldtech/rivine/modules/blockcreator"
 	"github.com/threefoldtech/rivine/modules/consensus"
 	"github.com/threefoldtech/rivine/modules/explorer"
+	"github.com/threefoldtech/rivine/modules/explorer/sqlindexer"
 	"github.com/threefoldtech/rivine/modules/gateway"
 	"github.com/threefoldtech/rivine/modules/transactionpool"
 	"github.com/threefoldtech/rivine/modules/wallet"
+	"github.com/threefoldtech/rivine/modules/webhooks"
 	rivineapi "github.com/threefoldtech/rivine/pkg/api"
 	"github.com/threefoldtech/rivine/pkg/daemon"
+	"github.com/threefoldtech/rivine/pkg/metrics"
 )
 
 const (
@@ -94,6 +111,22 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 		// which requires a user agent should one be configured
 		srv.Handle("/", rivineapi.RequireUserAgentHandler(router, cfg.RequiredUserAgent))
 
+		// metrics are served over their own router,
+		// as scrapers such as Prometheus use their own user agent
+		metricsRegistry := metrics.NewRegistry()
+		metricsRouter := httprouter.New()
+		rivineapi.RegisterMetricsHTTPHandlers(metricsRouter, metricsRegistry, cfg.APIPassword)
+		srv.Handle("/metrics", metricsRouter)
+		registerMetrics := func(name string, module interface{}) bool {
+			err := metricsRegistry.RegisterSource(module)
+			if err != nil {
+				servErrs <- fmt.Errorf("failed to register the metrics of the %s: %v", name, err)
+				cancel()
+				return false
+			}
+			return true
+		}
+
 		var cs modules.ConsensusSet
 
 		// register our special daemon HTTP handlers
@@ -127,6 +160,7 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 			}
 			cancel()
 		})
+		rivineapi.RegisterLoggingHTTPHandlers(router, cfg.APIPassword)
 
 		// Initialize the Rivine modules
 		var g modules.Gateway
@@ -148,10 +182,16 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					fmt.Println("Error during gateway shutdown:", err)
 				}
 			}()
+			if !registerMetrics("gateway", g) {
+				return
+			}
 		}
 
 		var mintingPlugin *minting.Plugin
 		var authCoinTxPlugin *authcointx.Plugin
+		var tokensPlugin *tokens.Plugin
+		var nftPlugin *nft.Plugin
+		var paymentChannelPlugin *paymentchannel.Plugin
 
 		if moduleIdentifiers.Contains(daemon.ConsensusSetModule.Identifier()) {
 			printModuleIsLoading("consensus set")
@@ -171,6 +211,9 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					fmt.Println("Error during consensus set shutdown:", err)
 				}
 			}()
+			if !registerMetrics("consensus set", cs) {
+				return
+			}
 		}
 
 		var tpool modules.TransactionPool
@@ -192,29 +235,29 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					fmt.Println("Error during transaction pool shutdown:", err)
 				}
 			}()
+			if !registerMetrics("transaction pool", tpool) {
+				return
+			}
 		}
 
 		if cs != nil {
-			// create the minting extension plugin
-			mintingPlugin = minting.NewMintingPlugin(
-				setupNetworkCfg.GenesisMintCondition,
-				rivchaintypes.TransactionVersionMinterDefinition,
-				rivchaintypes.TransactionVersionCoinCreation,
-				&minting.PluginOptions{
-					CoinDestructionTransactionVersion: rivchaintypes.TransactionVersionCoinDestruction,
-				},
-			)
-			// add the HTTP handlers for the auth coin tx extension as well
+			// stream consensus, transaction pool (if loaded) and address events
+			rivineapi.RegisterEventsHTTPHandlers(router, cs, tpool)
+		}
+
+		if cs != nil {
+			// create the extension plugins
+			// > NOTE: the auth coin tx plugin also overwrites the standard tx controllers!!!!
+			plugins := newExtensionPlugins(setupNetworkCfg)
+			mintingPlugin = plugins.minting
+			authCoinTxPlugin = plugins.authCoinTx
+			tokensPlugin = plugins.tokens
+			nftPlugin = plugins.nft
+			paymentChannelPlugin = plugins.paymentChannel
+
+			// add the HTTP handlers for the minting extension
 			mintingapi.RegisterConsensusMintingHTTPHandlers(router, mintingPlugin)
 
-			// create the auth coin tx plugin
-			// > NOTE: this also overwrites the standard tx controllers!!!!
-			authCoinTxPlugin = authcointx.NewPlugin(
-				setupNetworkCfg.GenesisAuthCondition,
-				rivchaintypes.TransactionVersionAuthAddressUpdate,
-				rivchaintypes.TransactionVersionAuthConditionUpdate,
-				nil, // no custom opts
-			)
 			// add the HTTP handlers for the auth coin tx extension as well
 			if tpool != nil {
 				authcointxapi.RegisterConsensusAuthCoinHTTPHandlers(
@@ -228,6 +271,15 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					rivchaintypes.TransactionVersionAuthAddressUpdate)
 			}
 
+			// add the HTTP handlers for the tokens extension as well
+			tokensapi.RegisterConsensusTokensHTTPHandlers(router, tokensPlugin)
+
+			// add the HTTP handlers for the NFT extension as well
+			nftapi.RegisterConsensusNFTHTTPHandlers(router, nftPlugin)
+
+			// add the HTTP handlers for the payment channel extension as well
+			paymentchannelapi.RegisterConsensusPaymentChannelHTTPHandlers(router, paymentChannelPlugin)
+
 			// register the minting extension plugin
 			err = cs.RegisterPlugin(ctx, "minting", mintingPlugin)
 			if err != nil {
@@ -251,6 +303,42 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 				cancel()
 				return
 			}
+
+			// register the tokens extension plugin
+			err = cs.RegisterPlugin(ctx, "tokens", tokensPlugin)
+			if err != nil {
+				servErrs <- fmt.Errorf("failed to register the tokens extension: %v", err)
+				err = tokensPlugin.Close() //make sure any resources are released
+				if err != nil {
+					fmt.Println("Error during closing of the tokensPlugin :", err)
+				}
+				cancel()
+				return
+			}
+
+			// register the NFT extension plugin
+			err = cs.RegisterPlugin(ctx, "nft", nftPlugin)
+			if err != nil {
+				servErrs <- fmt.Errorf("failed to register the NFT extension: %v", err)
+				err = nftPlugin.Close() //make sure any resources are released
+				if err != nil {
+					fmt.Println("Error during closing of the nftPlugin :", err)
+				}
+				cancel()
+				return
+			}
+
+			// register the payment channel extension plugin
+			err = cs.RegisterPlugin(ctx, "paymentchannel", paymentChannelPlugin)
+			if err != nil {
+				servErrs <- fmt.Errorf("failed to register the payment channel extension: %v", err)
+				err = paymentChannelPlugin.Close() //make sure any resources are released
+				if err != nil {
+					fmt.Println("Error during closing of the paymentChannelPlugin :", err)
+				}
+				cancel()
+				return
+			}
 		}
 
 		var w modules.Wallet
@@ -272,7 +360,36 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					fmt.Println("Error during wallet shutdown:", err)
 				}
 			}()
+			if !registerMetrics("wallet", w) {
+				return
+			}
 
+			// manage the payment channels of the wallet
+			if cs != nil && tpool != nil {
+				pcm, err := paymentchannelmanager.New(cs, tpool, w, paymentChannelPlugin,
+					paymentchannelmanager.TransactionVersions{
+						Open:            rivchaintypes.TransactionVersionChannelOpen,
+						Close:           rivchaintypes.TransactionVersionChannelClose,
+						UnilateralClose: rivchaintypes.TransactionVersionChannelUnilateralClose,
+						Settle:          rivchaintypes.TransactionVersionChannelSettle,
+					},
+					networkCfg.Constants.MinimumTransactionFee,
+					filepath.Join(cfg.RootPersistentDir, modules.WalletDir, paymentchannelmanager.PersistDir),
+					cfg.BlockchainInfo, cfg.VerboseLogging)
+				if err != nil {
+					servErrs <- err
+					cancel()
+					return
+				}
+				paymentchannelapi.RegisterManagerPaymentChannelHTTPHandlers(router, pcm, cfg.APIPassword)
+				defer func() {
+					fmt.Println("Closing payment channel manager...")
+					err := pcm.Close()
+					if err != nil {
+						fmt.Println("Error during payment channel manager shutdown:", err)
+					}
+				}()
+			}
 		}
 		var b modules.BlockCreator
 		if moduleIdentifiers.Contains(daemon.BlockCreatorModule.Identifier()) {
@@ -293,13 +410,56 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					fmt.Println("Error during block creator shutdown:", err)
 				}
 			}()
+			if !registerMetrics("block creator", b) {
+				return
+			}
+		}
+		if moduleIdentifiers.Contains(daemon.AtomicSwapModule.Identifier()) {
+			printModuleIsLoading("atomic swap")
+			asm, err := atomicswap.New(cs, tpool, w,
+				networkCfg.Constants.DefaultTransactionVersion,
+				networkCfg.Constants.MinimumTransactionFee,
+				filepath.Join(cfg.RootPersistentDir, modules.AtomicSwapDir),
+				cfg.BlockchainInfo, cfg.VerboseLogging)
+			if err != nil {
+				servErrs <- err
+				cancel()
+				return
+			}
+			rivineapi.RegisterAtomicSwapHTTPHandlers(router, asm, cfg.APIPassword)
+			defer func() {
+				fmt.Println("Closing atomic swap manager...")
+				err := asm.Close()
+				if err != nil {
+					fmt.Println("Error during atomic swap manager shutdown:", err)
+				}
+			}()
+		}
+		if moduleIdentifiers.Contains(daemon.WebhooksModule.Identifier()) {
+			printModuleIsLoading("webhooks")
+			whm, err := webhooks.New(cs, tpool,
+				filepath.Join(cfg.RootPersistentDir, modules.WebhooksDir),
+				cfg.BlockchainInfo, cfg.VerboseLogging)
+			if err != nil {
+				servErrs <- err
+				cancel()
+				return
+			}
+			rivineapi.RegisterWebhooksHTTPHandlers(router, whm, cfg.APIPassword)
+			defer func() {
+				fmt.Println("Closing webhook manager...")
+				err := whm.Close()
+				if err != nil {
+					fmt.Println("Error during webhook manager shutdown:", err)
+				}
+			}()
 		}
 		var e modules.Explorer
 		if moduleIdentifiers.Contains(daemon.ExplorerModule.Identifier()) {
 			printModuleIsLoading("explorer")
 			e, err = explorer.New(cs,
 				filepath.Join(cfg.RootPersistentDir, modules.ExplorerDir),
-				cfg.BlockchainInfo, networkCfg.Constants, cfg.VerboseLogging)
+				cfg.BlockchainInfo, networkCfg.Constants, cfg.VerboseLogging, cfg.ExplorerIndexArbitraryData)
 			if err != nil {
 				servErrs <- err
 				cancel()
@@ -326,6 +486,36 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					nil, rivchaintypes.TransactionVersionAuthConditionUpdate,
 					rivchaintypes.TransactionVersionAuthAddressUpdate)
 			}
+			tokensapi.RegisterExplorerTokensHTTPHandlers(router, tokensPlugin)
+			nftapi.RegisterExplorerNFTHTTPHandlers(router, nftPlugin)
+			paymentchannelapi.RegisterExplorerPaymentChannelHTTPHandlers(router, paymentChannelPlugin)
+
+			if cfg.ExplorerSQLDriver != "" {
+				fmt.Println("Loading explorer SQL indexer...")
+				db, dialect, err := sqlindexer.Open(cfg.ExplorerSQLDriver, cfg.ExplorerSQLDataSource)
+				if err != nil {
+					servErrs <- fmt.Errorf("failed to open explorer SQL database: %v", err)
+					cancel()
+					return
+				}
+				defer db.Close()
+				sqlIndexer, err := sqlindexer.New(cs, db, dialect,
+					filepath.Join(cfg.RootPersistentDir, modules.ExplorerDir),
+					cfg.BlockchainInfo, networkCfg.Constants, cfg.VerboseLogging)
+				if err != nil {
+					servErrs <- err
+					cancel()
+					return
+				}
+				rivineapi.RegisterExplorerSQLIndexerHTTPHandlers(router, sqlIndexer)
+				defer func() {
+					fmt.Println("Closing explorer SQL indexer...")
+					err := sqlIndexer.Close()
+					if err != nil {
+						fmt.Println("Error during explorer SQL indexer shutdown:", err)
+					}
+				}()
+			}
 		}
 
 		if cs != nil {
@@ -365,6 +555,52 @@ type setupNetworkConfig struct {
 	GenesisAuthCondition types.UnlockConditionProxy
 }
 
+// extensionPlugins are the consensus plugins of the extensions used by the daemon.
+type extensionPlugins struct {
+	minting        *minting.Plugin
+	authCoinTx     *authcointx.Plugin
+	tokens         *tokens.Plugin
+	nft            *nft.Plugin
+	paymentChannel *paymentchannel.Plugin
+}
+
+// newExtensionPlugins creates the consensus plugins of the extensions used by the daemon,
+// which registers their transaction versions as well, such that blocks can be decoded
+// even if the plugins themselves aren't registered with the consensus set.
+func newExtensionPlugins(setupNetworkCfg setupNetworkConfig) extensionPlugins {
+	return extensionPlugins{
+		minting: minting.NewMintingPlugin(
+			setupNetworkCfg.GenesisMintCondition,
+			rivchaintypes.TransactionVersionMinterDefinition,
+			rivchaintypes.TransactionVersionCoinCreation,
+			&minting.PluginOptions{
+				CoinDestructionTransactionVersion: rivchaintypes.TransactionVersionCoinDestruction,
+			},
+		),
+		authCoinTx: authcointx.NewPlugin(
+			setupNetworkCfg.GenesisAuthCondition,
+			rivchaintypes.TransactionVersionAuthAddressUpdate,
+			rivchaintypes.TransactionVersionAuthConditionUpdate,
+			nil, // no custom opts
+		),
+		tokens: tokens.NewPlugin(
+			rivchaintypes.TransactionVersionTokenIssuance,
+			rivchaintypes.TransactionVersionTokenTransfer,
+		),
+		nft: nft.NewPlugin(
+			rivchaintypes.TransactionVersionNFTMint,
+			rivchaintypes.TransactionVersionNFTTransfer,
+			rivchaintypes.TransactionVersionNFTBurn,
+		),
+		paymentChannel: paymentchannel.NewPlugin(
+			rivchaintypes.TransactionVersionChannelOpen,
+			rivchaintypes.TransactionVersionChannelClose,
+			rivchaintypes.TransactionVersionChannelUnilateralClose,
+			rivchaintypes.TransactionVersionChannelSettle,
+		),
+	}
+}
+
 // setupNetwork injects the correct chain constants and genesis nodes based on the chosen network,
 // it also ensures that features added during the lifetime of the blockchain,
 // only get activated on a certain block height, giving everyone sufficient time to upgrade should such features be introduced,
@@ -375,6 +611,17 @@ func setupNetwork(cfg ExtendedDaemonConfig) (setupNetworkConfig, error) {
 	switch cfg.BlockchainInfo.NetworkName {
 
 	case config.NetworkNameDevnet:
+		// secp256k1 signatures are supported from the genesis block on devnet
+		types.EnableSecp256k1SignatureAlgorithm(0)
+		// threshold signature conditions are supported from the genesis block on devnet
+		thresholdsig.RegisterUnlockTypes()
+		// relative time lock conditions are supported from the genesis block on devnet
+		relativetimelock.RegisterUnlockConditionType()
+		// composite conditions are supported from the genesis block on devnet
+		compositecondition.RegisterUnlockTypes()
+		// vault and clawback conditions are supported from the genesis block on devnet
+		vault.RegisterUnlockConditionTypes()
+
 		constants := config.GetDevnetGenesis()
 		bootstrapPeers := cfg.BootstrapPeers
 		if len(bootstrapPeers) == 0 {
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/consensus"
+	"github.com/threefoldtech/rivine/modules/explorer"
+	"github.com/threefoldtech/rivine/modules/gateway"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// explorerProgressInterval is the minimum interval
+// in which the progress of an explorer command is printed.
+const explorerProgressInterval = time.Second
+
+// registerExplorerCommands adds the explorer commands, used to maintain the explorer database
+// while the daemon isn't running, as subcommands of the given root command.
+func (cmds *commands) registerExplorerCommands(root *cobra.Command) {
+	explorerCmd := &cobra.Command{
+		Use:   "explorer",
+		Short: "Maintain the explorer database while the daemon is stopped",
+		Long: `Maintain the explorer database while the daemon is stopped,
+using the blocks of the consensus database stored in the same persistent directory.`,
+	}
+	flags := explorerCmd.PersistentFlags()
+	flags.StringVarP(&cmds.cfg.RootPersistentDir, "persistent-directory", "d", cmds.cfg.RootPersistentDir,
+		"location of the root directory used to store the daemon's persistent data")
+	flags.StringVarP(&cmds.cfg.BlockchainInfo.NetworkName, "network", "n", cmds.cfg.BlockchainInfo.NetworkName,
+		"the name of the network of the daemon")
+	flags.BoolVarP(&cmds.cfg.VerboseLogging, "verboselogging", "v", false,
+		"enable logging of debug information in the logfiles of the modules")
+	flags.BoolVar(&cmds.cfg.ExplorerIndexArbitraryData, "explorer-index-arbitrary-data", cmds.cfg.ExplorerIndexArbitraryData,
+		"index transactions by their arbitrary data, as the daemon does when started with this flag")
+
+	explorerCmd.AddCommand(&cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the explorer database from scratch",
+		Long: `Rebuild the explorer database from scratch, processing all blocks of the consensus database.
+The existing explorer database is only replaced once all blocks have been processed.`,
+		Args: cobra.NoArgs,
+		Run:  cmds.explorerReindexCommand,
+	})
+	explorerCmd.AddCommand(&cobra.Command{
+		Use:   "backfill",
+		Short: "Build missing indices and process missing blocks",
+		Long: `Open the existing explorer database, building any index it is missing (e.g. an index added
+by a new release), and process the blocks of the consensus database it didn't process yet.`,
+		Args: cobra.NoArgs,
+		Run:  cmds.explorerBackfillCommand,
+	})
+	explorerCmd.AddCommand(&cobra.Command{
+		Use:   "verify",
+		Short: "Cross-check the explorer database with the consensus database",
+		Long: `Cross-check the explorer database with the consensus database, ensuring all blocks and
+unspent outputs are indexed, and the indexed balances and supply statistics match these outputs.
+Exits with a non-zero exit code if any inconsistency is found.`,
+		Args: cobra.NoArgs,
+		Run:  cmds.explorerVerifyCommand,
+	})
+
+	root.AddCommand(explorerCmd)
+}
+
+func (cmds *commands) explorerReindexCommand(*cobra.Command, []string) {
+	cmds.runExplorerCommand(func(cs modules.ConsensusSet, persistDir string, chainCts types.ChainConstants) error {
+		return explorer.Reindex(cs, persistDir, cmds.cfg.BlockchainInfo, chainCts,
+			cmds.cfg.VerboseLogging, cmds.cfg.ExplorerIndexArbitraryData, newExplorerProgressPrinter("Processed"))
+	})
+	fmt.Println("Explorer database rebuilt successfully")
+}
+
+func (cmds *commands) explorerBackfillCommand(*cobra.Command, []string) {
+	cmds.runExplorerCommand(func(cs modules.ConsensusSet, persistDir string, chainCts types.ChainConstants) error {
+		return explorer.Backfill(cs, persistDir, cmds.cfg.BlockchainInfo, chainCts,
+			cmds.cfg.VerboseLogging, cmds.cfg.ExplorerIndexArbitraryData, newExplorerProgressPrinter("Processed"))
+	})
+	fmt.Println("Explorer database is up to date")
+}
+
+func (cmds *commands) explorerVerifyCommand(*cobra.Command, []string) {
+	var report explorer.VerifyReport
+	cmds.runExplorerCommand(func(cs modules.ConsensusSet, persistDir string, chainCts types.ChainConstants) (err error) {
+		report, err = explorer.Verify(cs, persistDir, cmds.cfg.BlockchainInfo, chainCts,
+			cmds.cfg.VerboseLogging, cmds.cfg.ExplorerIndexArbitraryData, newExplorerProgressPrinter("Verified"))
+		return
+	})
+
+	fmt.Printf("Explorer height: %d\n", report.Height)
+	fmt.Printf("Checked blocks:  %d\n", report.CheckedBlocks)
+	fmt.Printf("Checked outputs: %d\n", report.CheckedOutputs)
+	if report.Consistent() {
+		fmt.Println("Explorer database is consistent with the consensus database")
+		return
+	}
+	for _, inconsistency := range report.Inconsistencies {
+		fmt.Println("  -", inconsistency)
+	}
+	if n := report.InconsistencyCount - uint64(len(report.Inconsistencies)); n > 0 {
+		fmt.Printf("  ... and %d more\n", n)
+	}
+	cli.DieWithExitCode(cli.ExitCodeGeneral, fmt.Sprintf(
+		"found %d inconsistencies, run `explorer reindex` to rebuild the explorer database", report.InconsistencyCount))
+}
+
+// runExplorerCommand loads the consensus set offline, and calls the given callback
+// with it and the explorer directory, exiting the process if anything fails.
+func (cmds *commands) runExplorerCommand(fn func(cs modules.ConsensusSet, persistDir string, chainCts types.ChainConstants) error) {
+	// use the same subdirectory for the network as the daemon does
+	cmds.cfg.RootPersistentDir = filepath.Join(cmds.cfg.RootPersistentDir, cmds.cfg.BlockchainInfo.NetworkName)
+
+	cs, chainCts, closeCS, err := loadOfflineConsensusSet(cmds.cfg)
+	if err != nil {
+		cli.DieWithError("failed to load consensus set", err)
+	}
+	err = fn(cs, filepath.Join(cmds.cfg.RootPersistentDir, modules.ExplorerDir), chainCts)
+	closeCS()
+	if err != nil {
+		cli.DieWithError("explorer command failed", err)
+	}
+}
+
+// loadOfflineConsensusSet loads the consensus set stored in the persistent directory,
+// without synchronizing it with the network. The returned function closes the consensus set.
+func loadOfflineConsensusSet(cfg ExtendedDaemonConfig) (modules.ConsensusSet, types.ChainConstants, func(), error) {
+	setupNetworkCfg, err := setupNetwork(cfg)
+	if err != nil {
+		return nil, types.ChainConstants{}, nil, fmt.Errorf("failed to create network config: %v", err)
+	}
+	chainCts := setupNetworkCfg.NetworkConfig.Constants
+	err = chainCts.Validate()
+	if err != nil {
+		return nil, types.ChainConstants{}, nil, fmt.Errorf("failed to validate network config: %v", err)
+	}
+	// the plugins aren't registered, but are created to register their transaction versions,
+	// such that the blocks of the consensus set can be decoded
+	newExtensionPlugins(setupNetworkCfg)
+
+	consensusDir := filepath.Join(cfg.RootPersistentDir, modules.ConsensusDir)
+	if _, err = os.Stat(filepath.Join(consensusDir, consensus.DatabaseFilename)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, types.ChainConstants{}, nil, errors.New("no consensus database found in " + consensusDir)
+		}
+		return nil, types.ChainConstants{}, nil, err
+	}
+
+	// the consensus set requires a gateway, which is created in a temporary directory,
+	// and doesn't connect to any peer
+	gatewayDir, err := ioutil.TempDir("", modules.GatewayDir)
+	if err != nil {
+		return nil, types.ChainConstants{}, nil, err
+	}
+	g, err := gateway.New("localhost:0", false, maxConcurrentRPC, gatewayDir,
+		cfg.BlockchainInfo, chainCts, nil, cfg.VerboseLogging)
+	if err != nil {
+		os.RemoveAll(gatewayDir)
+		return nil, types.ChainConstants{}, nil, err
+	}
+	closeGateway := func() {
+		if err := g.Close(); err != nil {
+			fmt.Println("Error during gateway shutdown:", err)
+		}
+		os.RemoveAll(gatewayDir)
+	}
+	cs, err := consensus.New(g, false, consensusDir,
+		cfg.BlockchainInfo, chainCts, cfg.VerboseLogging, "")
+	if err != nil {
+		closeGateway()
+		return nil, types.ChainConstants{}, nil, err
+	}
+	return cs, chainCts, func() {
+		if err := cs.Close(); err != nil {
+			fmt.Println("Error during consensus set shutdown:", err)
+		}
+		closeGateway()
+	}, nil
+}
+
+// newExplorerProgressPrinter returns a progress function printing the progress of an explorer command,
+// at most once per explorerProgressInterval, and always once the target height is reached.
+func newExplorerProgressPrinter(action string) explorer.ProgressFunc {
+	var last time.Time
+	return func(height, target types.BlockHeight) {
+		if height < target && time.Since(last) < explorerProgressInterval {
+			return
+		}
+		last = time.Now()
+		percentage := 100.0
+		if target > 0 {
+			percentage = float64(height) / float64(target) * 100
+		}
+		fmt.Printf("\r%s block %d/%d (%.1f%%)", action, height, target, percentage)
+		if height >= target {
+			fmt.Println()
+		}
+	}
+}
@@ -49,6 +49,8 @@ func main() {
 		Run:   cmds.modulesCommand,
 	})
 
+	cmds.registerExplorerCommands(rootCommand)
+
 	// Parse cmdline flags, overwriting both the default values and the config
 	// file values.
 	if err := rootCommand.Execute(); err != nil {
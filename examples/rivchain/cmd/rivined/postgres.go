@@ -0,0 +1,5 @@
+package main
+
+// The PostgreSQL driver, which can be used by the explorer SQL indexer
+// (--explorer-sql-driver postgres), is always linked, as it is a pure Go driver.
+import _ "github.com/lib/pq"
@@ -0,0 +1,9 @@
+//go:build sqlite
+// +build sqlite
+
+package main
+
+// The SQLite driver, which can be used by the explorer SQL indexer
+// (--explorer-sql-driver sqlite3), is only linked when building with the sqlite tag,
+// as it requires cgo.
+import _ "github.com/mattn/go-sqlite3"
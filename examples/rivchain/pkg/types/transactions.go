@@ -16,3 +16,24 @@ const (
 	TransactionVersionAuthAddressUpdate   types.TransactionVersion = 177
 	TransactionVersionAuthConditionUpdate types.TransactionVersion = 176
 )
+
+// Tokens Extension Transaction Versions
+const (
+	TransactionVersionTokenIssuance types.TransactionVersion = 200
+	TransactionVersionTokenTransfer types.TransactionVersion = 201
+)
+
+// NFT Extension Transaction Versions
+const (
+	TransactionVersionNFTMint     types.TransactionVersion = 208
+	TransactionVersionNFTTransfer types.TransactionVersion = 209
+	TransactionVersionNFTBurn     types.TransactionVersion = 210
+)
+
+// Payment Channel Extension Transaction Versions
+const (
+	TransactionVersionChannelOpen            types.TransactionVersion = 212
+	TransactionVersionChannelClose           types.TransactionVersion = 213
+	TransactionVersionChannelUnilateralClose types.TransactionVersion = 214
+	TransactionVersionChannelSettle          types.TransactionVersion = 215
+)
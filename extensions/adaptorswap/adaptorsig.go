@@ -0,0 +1,217 @@
+package adaptorswap
+
+// adaptorsig.go implements adaptor signatures, also known as (one-time) verifiably encrypted signatures:
+// a pre-signature is created for a message and an adaptor point T = t*G,
+// which can be verified by anyone, but can only be completed into a valid signature
+// by someone who knows the adaptor secret t. Once the completed signature is published,
+// anyone who knows the pre-signature can extract the adaptor secret from it.
+//
+// For Ed25519 the pre-signature is (R, s'), where R = r*G + T and s' = r + H(R || A || M) * a,
+// which is completed into the standard Ed25519 signature (R, s' + t).
+
+import (
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"github.com/NebulousLabs/fastrand"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// ErrUnsupportedSignatureAlgorithm is returned in case no adaptor signature algorithm
+	// is registered for the signature algorithm of a public key.
+	ErrUnsupportedSignatureAlgorithm = errors.New("adaptor signatures are not supported for the signature algorithm")
+	// ErrInvalidPreSignature is returned in case a pre-signature could not be verified.
+	ErrInvalidPreSignature = errors.New("invalid adaptor pre-signature")
+	// ErrInvalidAdaptorSecret is returned in case an adaptor secret does not match its adaptor point.
+	ErrInvalidAdaptorSecret = errors.New("adaptor secret does not match adaptor point")
+)
+
+// The domain separation tag used to derive the nonce of Ed25519 pre-signatures.
+var ed25519AdaptorNonceContext = []byte("rivine ED25519 adaptor nonce")
+
+type (
+	// AdaptorSignatureAlgorithm defines the functionality a signature algorithm has to provide,
+	// in order to be used for adaptor signatures. All keys, points and signatures are
+	// given and returned using the raw formats of the matching types.SignatureAlgorithm.
+	AdaptorSignatureAlgorithm interface {
+		// NewAdaptorSecret creates a new (crypto-random) adaptor secret and its adaptor point.
+		NewAdaptorSecret() (secret, point types.ByteSlice, err error)
+		// PreSignHash creates a pre-signature of the given hash for the adaptor point,
+		// using the given secret key.
+		PreSignHash(hash crypto.Hash, key, point types.ByteSlice) (types.ByteSlice, error)
+		// VerifyPreSignature verifies the pre-signature of the given hash for the adaptor point,
+		// using the given public key.
+		VerifyPreSignature(hash crypto.Hash, pk, point, preSignature types.ByteSlice) error
+		// Adapt completes the pre-signature into a signature, using the adaptor secret.
+		Adapt(preSignature, secret types.ByteSlice) (types.ByteSlice, error)
+		// Extract extracts the adaptor secret from a pre-signature and the signature completed from it.
+		Extract(preSignature, signature, point types.ByteSlice) (types.ByteSlice, error)
+	}
+)
+
+// RegisterAdaptorSignatureAlgorithm is used to register an adaptor signature algorithm,
+// linking it to the signature algorithm type it creates signatures for.
+//
+// RegisterAdaptorSignatureAlgorithm can also be used to unregister an adaptor signature algorithm,
+// by calling this function with nil as the AdaptorSignatureAlgorithm.
+func RegisterAdaptorSignatureAlgorithm(sat types.SignatureAlgoType, algorithm AdaptorSignatureAlgorithm) {
+	if algorithm == nil {
+		delete(_RegisteredAdaptorSignatureAlgorithms, sat)
+		return
+	}
+	_RegisteredAdaptorSignatureAlgorithms[sat] = algorithm
+}
+
+// GetAdaptorSignatureAlgorithm returns the adaptor signature algorithm registered for the given type,
+// returning ErrUnsupportedSignatureAlgorithm in case no algorithm is registered for it.
+func GetAdaptorSignatureAlgorithm(sat types.SignatureAlgoType) (AdaptorSignatureAlgorithm, error) {
+	algorithm, ok := _RegisteredAdaptorSignatureAlgorithms[sat]
+	if !ok {
+		return nil, ErrUnsupportedSignatureAlgorithm
+	}
+	return algorithm, nil
+}
+
+// Hidden global used to collect the adaptor signature algorithms,
+// each linked to the signature algorithm type they create signatures for.
+// Only Ed25519 is supported by default: (ECDSA) secp256k1 has no linear signature equation
+// to adapt, such that keys of that type result in ErrUnsupportedSignatureAlgorithm.
+//
+// Manipulated by the RegisterAdaptorSignatureAlgorithm function.
+var _RegisteredAdaptorSignatureAlgorithms = map[types.SignatureAlgoType]AdaptorSignatureAlgorithm{
+	types.SignatureAlgoEd25519: ed25519AdaptorSignatureAlgorithm{},
+}
+
+// ed25519AdaptorSignatureAlgorithm implements adaptor signatures for the Ed25519 signature algorithm.
+type ed25519AdaptorSignatureAlgorithm struct{}
+
+// NewAdaptorSecret implements AdaptorSignatureAlgorithm.NewAdaptorSecret
+func (ed25519AdaptorSignatureAlgorithm) NewAdaptorSecret() (types.ByteSlice, types.ByteSlice, error) {
+	t, err := edwards25519.NewScalar().SetUniformBytes(fastrand.Bytes(64))
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.Bytes(), new(edwards25519.Point).ScalarBaseMult(t).Bytes(), nil
+}
+
+// PreSignHash implements AdaptorSignatureAlgorithm.PreSignHash
+func (ed25519AdaptorSignatureAlgorithm) PreSignHash(hash crypto.Hash, key, point types.ByteSlice) (types.ByteSlice, error) {
+	if len(key) != crypto.SecretKeySize {
+		return nil, errors.New("invalid secret key size")
+	}
+	T, err := new(edwards25519.Point).SetBytes(point)
+	if err != nil {
+		return nil, err
+	}
+	// derive the secret scalar and nonce prefix from the seed, as done by Ed25519 itself
+	h := sha512.Sum512(key[:32])
+	a, err := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		return nil, err
+	}
+	// the nonce commits to the adaptor point, such that it is never reused
+	// for the regular signature or another pre-signature of the same hash
+	nonceHash := sha512.New()
+	nonceHash.Write(ed25519AdaptorNonceContext)
+	nonceHash.Write(h[32:])
+	nonceHash.Write(point)
+	nonceHash.Write(hash[:])
+	r, err := edwards25519.NewScalar().SetUniformBytes(nonceHash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	R := new(edwards25519.Point).Add(new(edwards25519.Point).ScalarBaseMult(r), T)
+	k, err := ed25519Challenge(R.Bytes(), key[32:], hash)
+	if err != nil {
+		return nil, err
+	}
+	s := edwards25519.NewScalar().MultiplyAdd(k, a, r)
+	return append(R.Bytes(), s.Bytes()...), nil
+}
+
+// VerifyPreSignature implements AdaptorSignatureAlgorithm.VerifyPreSignature
+func (ed25519AdaptorSignatureAlgorithm) VerifyPreSignature(hash crypto.Hash, pk, point, preSignature types.ByteSlice) error {
+	if len(pk) != crypto.PublicKeySize || len(preSignature) != crypto.SignatureSize {
+		return ErrInvalidPreSignature
+	}
+	A, err := new(edwards25519.Point).SetBytes(pk)
+	if err != nil {
+		return err
+	}
+	T, err := new(edwards25519.Point).SetBytes(point)
+	if err != nil {
+		return err
+	}
+	R, err := new(edwards25519.Point).SetBytes(preSignature[:32])
+	if err != nil {
+		return ErrInvalidPreSignature
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(preSignature[32:])
+	if err != nil {
+		return ErrInvalidPreSignature
+	}
+	k, err := ed25519Challenge(preSignature[:32], pk, hash)
+	if err != nil {
+		return err
+	}
+	// s'*G == (R - T) + k*A
+	expected := new(edwards25519.Point).Subtract(R, T)
+	expected.Add(expected, new(edwards25519.Point).ScalarMult(k, A))
+	if new(edwards25519.Point).ScalarBaseMult(s).Equal(expected) != 1 {
+		return ErrInvalidPreSignature
+	}
+	return nil
+}
+
+// Adapt implements AdaptorSignatureAlgorithm.Adapt
+func (ed25519AdaptorSignatureAlgorithm) Adapt(preSignature, secret types.ByteSlice) (types.ByteSlice, error) {
+	if len(preSignature) != crypto.SignatureSize {
+		return nil, ErrInvalidPreSignature
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(preSignature[32:])
+	if err != nil {
+		return nil, ErrInvalidPreSignature
+	}
+	t, err := edwards25519.NewScalar().SetCanonicalBytes(secret)
+	if err != nil {
+		return nil, err
+	}
+	signature := append(types.ByteSlice(nil), preSignature[:32]...)
+	return append(signature, edwards25519.NewScalar().Add(s, t).Bytes()...), nil
+}
+
+// Extract implements AdaptorSignatureAlgorithm.Extract
+func (ed25519AdaptorSignatureAlgorithm) Extract(preSignature, signature, point types.ByteSlice) (types.ByteSlice, error) {
+	if len(preSignature) != crypto.SignatureSize || len(signature) != crypto.SignatureSize {
+		return nil, ErrInvalidPreSignature
+	}
+	sPre, err := edwards25519.NewScalar().SetCanonicalBytes(preSignature[32:])
+	if err != nil {
+		return nil, ErrInvalidPreSignature
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(signature[32:])
+	if err != nil {
+		return nil, err
+	}
+	t := edwards25519.NewScalar().Subtract(s, sPre)
+	T, err := new(edwards25519.Point).SetBytes(point)
+	if err != nil {
+		return nil, err
+	}
+	if new(edwards25519.Point).ScalarBaseMult(t).Equal(T) != 1 {
+		return nil, ErrInvalidAdaptorSecret
+	}
+	return t.Bytes(), nil
+}
+
+// ed25519Challenge computes the Ed25519 challenge scalar H(R || A || M).
+func ed25519Challenge(R, A []byte, hash crypto.Hash) (*edwards25519.Scalar, error) {
+	h := sha512.New()
+	h.Write(R)
+	h.Write(A)
+	h.Write(hash[:])
+	return edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+}
@@ -0,0 +1,578 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/extensions/adaptorswap"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+)
+
+// CreateAdaptorSwapCmds adds the adaptorswap root command and its subcommands,
+// used to create, redeem and refund adaptor swap contracts.
+// All results are printed (JSON-encoded) to the STDOUT.
+func CreateAdaptorSwapCmds(ccli *client.CommandLineClient) error {
+	adaptorSwapCmd := &adaptorSwapCmd{
+		cli: ccli,
+	}
+
+	var (
+		rootCmd = &cobra.Command{
+			Use:   "adaptorswap",
+			Short: "Create and interact with adaptor swap contracts",
+			Long: `Create and interact with adaptor swap contracts.
+
+An adaptor swap is an atomic swap without a hashed secret: the redeem of each contract
+is pre-signed by its sender for an adaptor point, and can only be completed by the
+receiver using the adaptor secret. The secret is revealed to the other party
+by the published redeem transaction, which only contains regular signatures.
+The coins of a contract are locked by a regular 2-of-2 multi signature condition,
+such that a contract looks like any other multi signature payment.
+
+Only Ed25519 wallet keys can pre-sign a redeem, so the sender of a contract
+cannot use a secp256k1 key. The receiver can use a key of any signature algorithm.
+
+A swap is done as follows:
+
+  1. the initiator creates a contract using 'initiate', sharing the contract and adaptor point;
+  2. the participant signs the refund of that contract using 'signrefund',
+     and creates a contract using 'participate', sharing the contract;
+  3. the initiator signs the refund of the contract of the participant using 'signrefund';
+  4. both parties publish their own contract using 'fund', once its refund has been signed;
+  5. both parties pre-sign the redeem of their own contract using 'presign',
+     and share the pre-signed redeem with the other party;
+  6. the initiator redeems the contract of the participant using 'redeem' and the adaptor secret;
+  7. the participant extracts the adaptor secret using 'extractsecret',
+     and redeems the contract of the initiator using 'redeem'.
+
+Each party can refund its own contract using 'refund' and the signed refund,
+once its time lock has been reached. A contract should therefore be redeemed well
+before its time lock, as its sender could otherwise double spend the redeem using the refund.`,
+		}
+		initiateCmd = &cobra.Command{
+			Use:   "initiate <participant address> <amount>",
+			Short: "Create an adaptor swap contract as initiator",
+			Long: `Create an adaptor swap contract as initiator,
+generating a new adaptor secret and point. The secret has to be kept private,
+while the contract and the adaptor point are shared with the participant.
+
+The contract is only funded by the wallet and not yet published,
+use 'fund' to publish it once the participant signed its refund.`,
+			Args: cobra.ExactArgs(2),
+			Run:  adaptorSwapCmd.initiateCmd,
+		}
+		participateCmd = &cobra.Command{
+			Use:   "participate <initiator address> <amount>",
+			Short: "Create an adaptor swap contract as participant",
+			Long: `Create an adaptor swap contract as participant.
+
+The contract is only funded by the wallet and not yet published,
+use 'fund' to publish it once the initiator signed its refund.`,
+			Args: cobra.ExactArgs(2),
+			Run:  adaptorSwapCmd.participateCmd,
+		}
+		signRefundCmd = &cobra.Command{
+			Use:   "signrefund <contract>",
+			Short: "Sign the refund of an adaptor swap contract, as receiver",
+			Long: `Sign the refund of the given (JSON-encoded) adaptor swap contract,
+as created by 'initiate' or 'participate', and owned by this wallet as receiver.
+The signed refund has to be given to the sender of the contract.`,
+			Args: cobra.ExactArgs(1),
+			Run:  adaptorSwapCmd.signRefundCmd,
+		}
+		fundCmd = &cobra.Command{
+			Use:   "fund <contract> <refund>",
+			Short: "Publish an adaptor swap contract, as sender",
+			Long: `Sign and publish the funding transaction of the given (JSON-encoded) adaptor swap contract,
+once its (JSON-encoded) refund transaction has been signed by the receiver.
+The signed refund has to be kept, in order to refund the contract using 'refund'.`,
+			Args: cobra.ExactArgs(2),
+			Run:  adaptorSwapCmd.fundCmd,
+		}
+		auditCmd = &cobra.Command{
+			Use:   "auditcontract <outputid> <sender address>",
+			Short: "Audit an adaptor swap contract",
+			Args:  cobra.ExactArgs(2),
+			Run:   adaptorSwapCmd.auditCmd,
+		}
+		preSignCmd = &cobra.Command{
+			Use:   "presign <outputid> <adaptorpoint>",
+			Short: "Pre-sign the redeem of an adaptor swap contract, as sender",
+			Long: `Pre-sign the redeem of an adaptor swap contract created by this wallet,
+sending the value of the contract (minus the miner fee) to its receiver.
+The pre-signed redeem can only be completed using the adaptor secret of the given point.`,
+			Args: cobra.ExactArgs(2),
+			Run:  adaptorSwapCmd.preSignCmd,
+		}
+		redeemCmd = &cobra.Command{
+			Use:   "redeem <presignedredeem> <secret>",
+			Short: "Redeem an adaptor swap contract, as receiver",
+			Long: `Redeem an adaptor swap contract, by completing the (JSON-encoded) pre-signed redeem
+of its sender using the adaptor secret, and publishing it.`,
+			Args: cobra.ExactArgs(2),
+			Run:  adaptorSwapCmd.redeemCmd,
+		}
+		extractSecretCmd = &cobra.Command{
+			Use:   "extractsecret <transactionid> <presignedredeem>",
+			Short: "Extract the adaptor secret from a published redeem transaction",
+			Long: `Extract the adaptor secret from a published redeem transaction,
+using the (JSON-encoded) pre-signed redeem it was completed from.`,
+			Args: cobra.ExactArgs(2),
+			Run:  adaptorSwapCmd.extractSecretCmd,
+		}
+		refundCmd = &cobra.Command{
+			Use:   "refund <refund>",
+			Short: "Refund an adaptor swap contract, as sender, once its time lock has been reached",
+			Long: `Refund an adaptor swap contract, by completing the (JSON-encoded) refund transaction
+signed by its receiver, and publishing it. The refunded coins are locked until the time lock of the refund.`,
+			Args: cobra.ExactArgs(1),
+			Run:  adaptorSwapCmd.refundCmd,
+		}
+	)
+
+	rootCmd.AddCommand(
+		initiateCmd,
+		participateCmd,
+		signRefundCmd,
+		fundCmd,
+		auditCmd,
+		preSignCmd,
+		redeemCmd,
+		extractSecretCmd,
+		refundCmd,
+	)
+
+	initiateCmd.Flags().DurationVarP(
+		&adaptorSwapCmd.initiateDuration, "duration", "d",
+		time.Hour*48, "the duration of the adaptor swap contract, the amount of time the participant has to collect")
+	participateCmd.Flags().DurationVarP(
+		&adaptorSwapCmd.participateDuration, "duration", "d",
+		time.Hour*24, "the duration of the adaptor swap contract, the amount of time the initiator has to collect")
+
+	ccli.RootCmd.AddCommand(rootCmd)
+
+	return nil
+}
+
+type adaptorSwapCmd struct {
+	cli                 *client.CommandLineClient
+	initiateDuration    time.Duration
+	participateDuration time.Duration
+}
+
+// contractCreation is printed when an adaptor swap contract is created,
+// containing its (unsigned) funding and refund transactions.
+// It is shared with the receiver, in order to sign the refund.
+type contractCreation struct {
+	Coins       types.Currency       `json:"coins"`
+	Contract    adaptorswap.Contract `json:"contract"`
+	ContractID  types.UnlockHash     `json:"contractid"`
+	OutputID    types.CoinOutputID   `json:"outputid"`
+	TimeLock    types.Timestamp      `json:"timelock"`
+	Transaction types.Transaction    `json:"transaction"`
+	Refund      types.Transaction    `json:"refund"`
+}
+
+// contractInitiation is printed when an adaptor swap contract is created by the initiator,
+// of which only the contract and adaptor point are to be shared with the participant.
+type contractInitiation struct {
+	Contract     contractCreation `json:"contract"`
+	Secret       types.ByteSlice  `json:"secret"`
+	AdaptorPoint types.ByteSlice  `json:"adaptorpoint"`
+}
+
+// contractAudit is printed when an (unspent) adaptor swap contract is audited.
+type contractAudit struct {
+	Coins      types.Currency       `json:"coins"`
+	Contract   adaptorswap.Contract `json:"contract"`
+	ContractID types.UnlockHash     `json:"contractid"`
+	OutputID   types.CoinOutputID   `json:"outputid"`
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) initiateCmd(cmd *cobra.Command, args []string) {
+	receiver := parseUnlockHash(cmd, args[0], "participant address")
+	amount := adaptorSwapCmd.parseAmount(cmd, args[1])
+	sender := adaptorSwapCmd.newWalletAddress()
+
+	pk, _ := adaptorSwapCmd.spendableKey(sender)
+	algorithm, err := adaptorswap.GetAdaptorSignatureAlgorithm(pk.Algorithm)
+	if err != nil {
+		cli.DieWithError("failed to initiate adaptor swap", err)
+	}
+	secret, point, err := algorithm.NewAdaptorSecret()
+	if err != nil {
+		cli.DieWithError("failed to generate adaptor secret", err)
+	}
+	encodeJSON(contractInitiation{
+		Contract: adaptorSwapCmd.createContract(amount, adaptorswap.Contract{
+			Sender:   sender,
+			Receiver: receiver,
+		}, types.OffsetTimestamp(adaptorSwapCmd.initiateDuration)),
+		Secret:       secret,
+		AdaptorPoint: point,
+	})
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) participateCmd(cmd *cobra.Command, args []string) {
+	receiver := parseUnlockHash(cmd, args[0], "initiator address")
+	amount := adaptorSwapCmd.parseAmount(cmd, args[1])
+	encodeJSON(adaptorSwapCmd.createContract(amount, adaptorswap.Contract{
+		Sender:   adaptorSwapCmd.newWalletAddress(),
+		Receiver: receiver,
+	}, types.OffsetTimestamp(adaptorSwapCmd.participateDuration)))
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) signRefundCmd(cmd *cobra.Command, args []string) {
+	var creation contractCreation
+	parseJSON(cmd, args[0], &creation, "contract")
+	// ensure the funding transaction creates the contract, and the refund spends it
+	var found bool
+	for idx, co := range creation.Transaction.CoinOutputs {
+		if creation.Transaction.CoinOutputID(uint64(idx)) == creation.OutputID {
+			found = co.Condition.Equal(creation.Contract.Condition()) && co.Value.Equals(creation.Coins)
+			break
+		}
+	}
+	if !found {
+		cli.Die("contract transaction does not create the contract output", creation.OutputID.String())
+	}
+	if len(creation.Refund.CoinInputs) != 1 || creation.Refund.CoinInputs[0].ParentID != creation.OutputID {
+		cli.Die("refund does not spend the contract output", creation.OutputID.String())
+	}
+	refundCondition := types.NewCondition(creation.Contract.RefundCondition(creation.TimeLock))
+	for _, co := range creation.Refund.CoinOutputs {
+		if !co.Condition.Equal(refundCondition) {
+			cli.Die("refund sends coins to", co.Condition.UnlockHash().String(), "instead of the time-locked contract sender")
+		}
+	}
+	refund := creation.Refund
+	pk, sk := adaptorSwapCmd.spendableKey(creation.Contract.Receiver)
+	err := adaptorswap.SignContractInput(&refund, 0, pk, sk)
+	if err != nil {
+		cli.DieWithError("failed to sign refund", err)
+	}
+	encodeJSON(refund)
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) fundCmd(cmd *cobra.Command, args []string) {
+	var creation contractCreation
+	parseJSON(cmd, args[0], &creation, "contract")
+	var refund types.Transaction
+	parseJSON(cmd, args[1], &refund, "refund")
+	if len(refund.CoinInputs) != 1 || refund.CoinInputs[0].ParentID != creation.OutputID {
+		cli.Die("refund does not spend the contract output", creation.OutputID.String())
+	}
+	timeLock, err := creation.Contract.VerifyRefund(refund, 0)
+	if err != nil {
+		cli.DieWithError("invalid refund", err)
+	}
+	if timeLock != creation.TimeLock {
+		cli.Die("refund is time-locked until", timeLock.String(), "instead of", creation.TimeLock.String())
+	}
+	txn := creation.Transaction
+	b, err := json.Marshal(txn)
+	if err != nil {
+		cli.DieWithError("failed to encode contract transaction", err)
+	}
+	err = adaptorSwapCmd.cli.PostWithResponse("/wallet/sign", string(b), &txn)
+	if err != nil {
+		cli.DieWithError("failed to sign contract transaction", err)
+	}
+	encodeJSON(struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+	}{adaptorSwapCmd.commitTransaction(txn)})
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) auditCmd(cmd *cobra.Command, args []string) {
+	outputID := parseCoinOutputID(cmd, args[0])
+	sender := parseUnlockHash(cmd, args[1], "sender address")
+	contract, output := adaptorSwapCmd.getContract(outputID, sender)
+	encodeJSON(contractAudit{
+		Coins:      output.Value,
+		Contract:   contract,
+		ContractID: output.Condition.UnlockHash(),
+		OutputID:   outputID,
+	})
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) preSignCmd(cmd *cobra.Command, args []string) {
+	outputID := parseCoinOutputID(cmd, args[0])
+	var point types.ByteSlice
+	err := point.LoadString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid adaptor point", err)
+	}
+	contract, output := adaptorSwapCmd.getWalletContract(outputID)
+	txn := adaptorSwapCmd.spendTransaction(outputID, output.Value, types.NewUnlockHashCondition(contract.Receiver))
+	pk, sk := adaptorSwapCmd.spendableKey(contract.Sender)
+	psr, err := adaptorswap.NewPreSignedRedeem(txn, 0, pk, sk, point)
+	if err != nil {
+		cli.DieWithError("failed to pre-sign redeem", err)
+	}
+	encodeJSON(psr)
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) redeemCmd(cmd *cobra.Command, args []string) {
+	var psr adaptorswap.PreSignedRedeem
+	parseJSON(cmd, args[0], &psr, "pre-signed redeem")
+	var secret types.ByteSlice
+	err := secret.LoadString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid adaptor secret", err)
+	}
+	if psr.InputIndex >= uint64(len(psr.Transaction.CoinInputs)) {
+		cli.Die("pre-signed redeem does not define the coin input it redeems")
+	}
+	// ensure the pre-signed redeem is signed by the sender and pays the receiver
+	sender, err := types.NewPubKeyUnlockHash(psr.PublicKey)
+	if err != nil {
+		cli.DieWithError("invalid public key of pre-signed redeem", err)
+	}
+	contract, _ := adaptorSwapCmd.getContract(psr.Transaction.CoinInputs[psr.InputIndex].ParentID, sender)
+	for _, co := range psr.Transaction.CoinOutputs {
+		if co.Condition.UnlockHash().Cmp(contract.Receiver) != 0 {
+			cli.Die("pre-signed redeem sends coins to", co.Condition.UnlockHash().String(), "instead of the contract receiver")
+		}
+	}
+	pk, sk := adaptorSwapCmd.spendableKey(contract.Receiver)
+	txn, err := psr.Complete(secret, pk, sk)
+	if err != nil {
+		cli.DieWithError("failed to complete pre-signed redeem", err)
+	}
+	encodeJSON(struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+	}{adaptorSwapCmd.commitTransaction(txn)})
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) extractSecretCmd(cmd *cobra.Command, args []string) {
+	var txnID types.TransactionID
+	err := txnID.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid transaction ID", err)
+	}
+	var psr adaptorswap.PreSignedRedeem
+	parseJSON(cmd, args[1], &psr, "pre-signed redeem")
+	var txn api.ConsensusGetTransaction
+	err = adaptorSwapCmd.cli.GetWithResponse("/consensus/transactions/"+txnID.String(), &txn)
+	if err != nil {
+		cli.DieWithError("failed to get transaction "+txnID.String(), err)
+	}
+	secret, err := psr.ExtractSecret(txn.Transaction)
+	if err != nil {
+		cli.DieWithError("failed to extract adaptor secret", err)
+	}
+	encodeJSON(struct {
+		Secret types.ByteSlice `json:"secret"`
+	}{secret})
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) refundCmd(cmd *cobra.Command, args []string) {
+	var refund types.Transaction
+	parseJSON(cmd, args[0], &refund, "refund")
+	if len(refund.CoinInputs) != 1 {
+		cli.Die("refund has to spend a single contract output")
+	}
+	contract, _ := adaptorSwapCmd.getWalletContract(refund.CoinInputs[0].ParentID)
+	timeLock, err := contract.VerifyRefund(refund, 0)
+	if err != nil {
+		cli.DieWithError("invalid refund", err)
+	}
+	if now := types.CurrentTimestamp(); now < timeLock {
+		cli.Die("adaptor swap contract cannot be refunded until", timeLock.String())
+	}
+	pk, sk := adaptorSwapCmd.spendableKey(contract.Sender)
+	err = adaptorswap.SignContractInput(&refund, 0, pk, sk)
+	if err != nil {
+		cli.DieWithError("failed to sign refund", err)
+	}
+	encodeJSON(struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+	}{adaptorSwapCmd.commitTransaction(refund)})
+}
+
+// createContract funds the given contract using the wallet, without signing or publishing it,
+// and creates its (unsigned) refund transaction, time-locked until the given timestamp.
+func (adaptorSwapCmd *adaptorSwapCmd) createContract(amount types.Currency, contract adaptorswap.Contract, timeLock types.Timestamp) contractCreation {
+	fee := adaptorSwapCmd.cli.Config.MinimumTransactionFee
+	var resp api.WalletFundCoins
+	err := adaptorSwapCmd.cli.GetWithResponse("/wallet/fund/coins?refund=true&amount="+amount.Add(fee).String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to fund contract transaction", err)
+	}
+	condition := types.NewCondition(contract.Condition())
+	txn := types.Transaction{
+		Version:     adaptorSwapCmd.cli.Config.DefaultTransactionVersion,
+		CoinInputs:  resp.CoinInputs,
+		CoinOutputs: []types.CoinOutput{{Value: amount, Condition: condition}},
+		MinerFees:   []types.Currency{fee},
+	}
+	if resp.RefundCoinOutput != nil {
+		txn.CoinOutputs = append(txn.CoinOutputs, *resp.RefundCoinOutput)
+	}
+	outputID := txn.CoinOutputID(0)
+	return contractCreation{
+		Coins:       amount,
+		Contract:    contract,
+		ContractID:  condition.UnlockHash(),
+		OutputID:    outputID,
+		TimeLock:    timeLock,
+		Transaction: txn,
+		Refund:      adaptorSwapCmd.spendTransaction(outputID, amount, contract.RefundCondition(timeLock)),
+	}
+}
+
+// getContract returns the adaptor swap contract of the given sender, locking the given unspent coin output.
+func (adaptorSwapCmd *adaptorSwapCmd) getContract(outputID types.CoinOutputID, sender types.UnlockHash) (adaptorswap.Contract, types.CoinOutput) {
+	output := adaptorSwapCmd.getUnspentCoinOutput(outputID)
+	contract, err := adaptorswap.ParseContract(output.Condition, sender)
+	if err != nil {
+		cli.DieWithError("invalid coin output "+outputID.String(), err)
+	}
+	return contract, output
+}
+
+// getWalletContract returns the adaptor swap contract locking the given unspent coin output,
+// of which the sender is owned by the wallet.
+func (adaptorSwapCmd *adaptorSwapCmd) getWalletContract(outputID types.CoinOutputID) (adaptorswap.Contract, types.CoinOutput) {
+	output := adaptorSwapCmd.getUnspentCoinOutput(outputID)
+	ms, ok := output.Condition.Condition.(*types.MultiSignatureCondition)
+	if !ok {
+		cli.DieWithError("invalid coin output "+outputID.String(), adaptorswap.ErrInvalidContract)
+	}
+	for _, uh := range ms.UnlockHashes {
+		var resp api.WalletKeyGet
+		if adaptorSwapCmd.cli.GetWithResponse("/wallet/key/"+uh.String(), &resp) != nil {
+			continue // not owned by the wallet
+		}
+		contract, err := adaptorswap.ParseContract(output.Condition, uh)
+		if err != nil {
+			cli.DieWithError("invalid coin output "+outputID.String(), err)
+		}
+		return contract, output
+	}
+	cli.Die("adaptor swap contract of coin output", outputID.String(), "was not created by this wallet")
+	return adaptorswap.Contract{}, types.CoinOutput{}
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) getUnspentCoinOutput(outputID types.CoinOutputID) types.CoinOutput {
+	var resp api.ConsensusGetUnspentCoinOutput
+	err := adaptorSwapCmd.cli.GetWithResponse("/consensus/unspent/coinoutputs/"+outputID.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get unspent coin output "+outputID.String(), err)
+	}
+	return resp.Output
+}
+
+// spendTransaction creates an unsigned transaction sending the value of the contract,
+// minus the miner fee, to an output locked by the given condition.
+func (adaptorSwapCmd *adaptorSwapCmd) spendTransaction(outputID types.CoinOutputID, value types.Currency, condition types.MarshalableUnlockCondition) types.Transaction {
+	fee := adaptorSwapCmd.cli.Config.MinimumTransactionFee
+	if value.Cmp(fee) != 1 {
+		cli.Die("adaptor swap contract has to lock a value greater than the miner fee of", fee.String())
+	}
+	return types.Transaction{
+		Version:    adaptorSwapCmd.cli.Config.DefaultTransactionVersion,
+		CoinInputs: []types.CoinInput{{ParentID: outputID}},
+		CoinOutputs: []types.CoinOutput{{
+			Value:     value.Sub(fee),
+			Condition: types.NewCondition(condition),
+		}},
+		MinerFees: []types.Currency{fee},
+	}
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) commitTransaction(txn types.Transaction) types.TransactionID {
+	b, err := json.Marshal(txn)
+	if err != nil {
+		cli.DieWithError("failed to encode transaction", err)
+	}
+	var resp api.TransactionPoolPOST
+	err = adaptorSwapCmd.cli.PostWithResponse("/transactionpool/transactions", string(b), &resp)
+	if err != nil {
+		cli.DieWithError("failed to publish transaction", err)
+	}
+	return resp.TransactionID
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) newWalletAddress() types.UnlockHash {
+	var resp api.WalletAddressGET
+	err := adaptorSwapCmd.cli.GetWithResponse("/wallet/address", &resp)
+	if err != nil {
+		cli.DieWithError("failed to generate new address", err)
+	}
+	return resp.Address
+}
+
+// spendableKey returns the key pair linked to the given unlock hash, owned by the wallet.
+func (adaptorSwapCmd *adaptorSwapCmd) spendableKey(uh types.UnlockHash) (types.PublicKey, types.ByteSlice) {
+	var resp api.WalletKeyGet
+	err := adaptorSwapCmd.cli.GetWithResponse("/wallet/key/"+uh.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get the wallet key of "+uh.String(), err)
+	}
+	pk := types.PublicKey{
+		Key: resp.PublicKey,
+	}
+	err = pk.Algorithm.LoadSpecifier(resp.AlgorithmSpecifier)
+	if err != nil {
+		cli.DieWithError("invalid public key algorithm specifier loaded", err)
+	}
+	return pk, resp.SecretKey
+}
+
+func (adaptorSwapCmd *adaptorSwapCmd) parseAmount(cmd *cobra.Command, str string) types.Currency {
+	amount, err := adaptorSwapCmd.cli.CreateCurrencyConvertor().ParseCoinString(str)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid amount", err)
+	}
+	if amount.Cmp(adaptorSwapCmd.cli.Config.MinimumTransactionFee) != 1 {
+		cli.Die("an adaptor swap contract has to lock a value greater than the minimum transaction fee")
+	}
+	return amount
+}
+
+func parseCoinOutputID(cmd *cobra.Command, str string) types.CoinOutputID {
+	var id types.CoinOutputID
+	err := id.LoadString(str)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid coin output ID", err)
+	}
+	return id
+}
+
+func parseUnlockHash(cmd *cobra.Command, str, name string) types.UnlockHash {
+	var uh types.UnlockHash
+	err := uh.LoadString(str)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid "+name, err)
+	}
+	return uh
+}
+
+func parseJSON(cmd *cobra.Command, str string, value interface{}, name string) {
+	err := json.Unmarshal([]byte(str), value)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid JSON-encoded "+name, err)
+	}
+}
+
+// encodeJSON encodes the value as JSON to the STDOUT
+func encodeJSON(value interface{}) {
+	err := json.NewEncoder(os.Stdout).Encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
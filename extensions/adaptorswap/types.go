@@ -0,0 +1,256 @@
+package adaptorswap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// ErrInvalidContract is returned in case a condition is not an adaptor swap contract.
+	ErrInvalidContract = errors.New("condition is not an adaptor swap contract")
+	// ErrRedeemSignatureNotFound is returned in case a transaction does not redeem the contract
+	// of a pre-signed redeem, using the signature of its sender.
+	ErrRedeemSignatureNotFound = errors.New("transaction does not contain the redeem signature of the contract sender")
+	// ErrInvalidRefund is returned in case a transaction is not a refund of a contract,
+	// signed by its receiver and sending all coins to its sender once the time lock has been reached.
+	ErrInvalidRefund = errors.New("transaction is not a refund signed by the contract receiver")
+)
+
+type (
+	// Contract defines an adaptor swap contract. The coins it locks can be redeemed
+	// by the receiver using the signatures of both the sender and receiver,
+	// or refunded by the sender using a refund transaction signed by the receiver.
+	//
+	// The contract is locked by a regular multi signature condition, while the redeem
+	// is signed by the sender as an adaptor pre-signature, which the receiver can only complete
+	// using the adaptor secret. The redeem transaction therefore only contains regular signatures,
+	// from which the sender can extract the adaptor secret, once published.
+	//
+	// The refund transaction is signed by the receiver before the contract is funded,
+	// and sends the coins to an output which is time-locked to the sender.
+	Contract struct {
+		Sender   types.UnlockHash `json:"sender"`
+		Receiver types.UnlockHash `json:"receiver"`
+	}
+
+	// PreSignedRedeem is created by the sender of a contract, and given to its receiver.
+	// It contains the redeem transaction, pre-signed by the sender for the adaptor point.
+	PreSignedRedeem struct {
+		Transaction  types.Transaction `json:"transaction"`
+		InputIndex   uint64            `json:"inputindex"`
+		PublicKey    types.PublicKey   `json:"publickey"`
+		PreSignature types.ByteSlice   `json:"presignature"`
+		AdaptorPoint types.ByteSlice   `json:"adaptorpoint"`
+	}
+)
+
+// Condition returns the multi signature condition locking the coins of this contract.
+func (c Contract) Condition() *types.MultiSignatureCondition {
+	return types.NewMultiSignatureCondition(types.UnlockHashSlice{c.Sender, c.Receiver}, 2)
+}
+
+// RefundCondition returns the condition locking the coins refunded to the sender of this contract,
+// until the given time lock has been reached.
+func (c Contract) RefundCondition(timeLock types.Timestamp) *types.TimeLockCondition {
+	return types.NewTimeLockCondition(uint64(timeLock), types.NewUnlockHashCondition(c.Sender))
+}
+
+// ParseContract parses the adaptor swap contract of the given sender from the given condition,
+// returning ErrInvalidContract in case it is not a condition created by Contract.Condition.
+// The receiver is the other address of the multi signature condition.
+func ParseContract(condition types.UnlockConditionProxy, sender types.UnlockHash) (Contract, error) {
+	ms, ok := condition.Condition.(*types.MultiSignatureCondition)
+	if !ok || ms.MinimumSignatureCount != 2 || len(ms.UnlockHashes) != 2 {
+		return Contract{}, ErrInvalidContract
+	}
+	var receiver types.UnlockHash
+	switch {
+	case ms.UnlockHashes[0].Cmp(sender) == 0:
+		receiver = ms.UnlockHashes[1]
+	case ms.UnlockHashes[1].Cmp(sender) == 0:
+		receiver = ms.UnlockHashes[0]
+	default:
+		return Contract{}, ErrInvalidContract
+	}
+	if receiver.Cmp(sender) == 0 {
+		return Contract{}, ErrInvalidContract
+	}
+	return Contract{
+		Sender:   sender,
+		Receiver: receiver,
+	}, nil
+}
+
+// VerifyRefund verifies that the given transaction refunds the contract spent by the coin input
+// at the given index, returning the time lock until which the refunded coins are locked.
+// All coin outputs have to be locked by the refund condition, and the input has to be signed by the receiver.
+func (c Contract) VerifyRefund(txn types.Transaction, inputIndex uint64) (types.Timestamp, error) {
+	if inputIndex >= uint64(len(txn.CoinInputs)) {
+		return 0, fmt.Errorf("coin input index %d is out of range", inputIndex)
+	}
+	if len(txn.CoinOutputs) == 0 {
+		return 0, ErrInvalidRefund
+	}
+	tl, ok := txn.CoinOutputs[0].Condition.Condition.(*types.TimeLockCondition)
+	if !ok || tl.LockTime < types.LockTimeMinTimestampValue {
+		return 0, ErrInvalidRefund
+	}
+	timeLock := types.Timestamp(tl.LockTime)
+	condition := types.NewCondition(c.RefundCondition(timeLock))
+	for _, co := range txn.CoinOutputs {
+		if !co.Condition.Equal(condition) {
+			return 0, ErrInvalidRefund
+		}
+	}
+	msf, ok := txn.CoinInputs[inputIndex].Fulfillment.Fulfillment.(*types.MultiSignatureFulfillment)
+	if !ok {
+		return 0, ErrInvalidRefund
+	}
+	for _, pair := range msf.Pairs {
+		uh, err := types.NewPubKeyUnlockHash(pair.PublicKey)
+		if err != nil || uh.Cmp(c.Receiver) != 0 {
+			continue
+		}
+		algorithm, err := types.GetSignatureAlgorithm(pair.PublicKey.Algorithm)
+		if err != nil {
+			return 0, err
+		}
+		hash, err := redeemSignatureHash(txn, inputIndex, pair.PublicKey)
+		if err != nil {
+			return 0, err
+		}
+		if algorithm.VerifyHash(hash, pair.PublicKey.Key, pair.Signature) != nil {
+			return 0, ErrInvalidRefund
+		}
+		return timeLock, nil
+	}
+	return 0, ErrInvalidRefund
+}
+
+// SignContractInput signs the coin input at the given index, which spends a contract,
+// adding the signature of the given key pair to its multi signature fulfillment.
+// It is used by the receiver to sign the refund of a contract, and by the sender to complete it.
+func SignContractInput(txn *types.Transaction, inputIndex uint64, pk types.PublicKey, sk types.ByteSlice) error {
+	if inputIndex >= uint64(len(txn.CoinInputs)) {
+		return fmt.Errorf("coin input index %d is out of range", inputIndex)
+	}
+	msf := types.NewMultiSignatureFulfillment(nil)
+	if existing, ok := txn.CoinInputs[inputIndex].Fulfillment.Fulfillment.(*types.MultiSignatureFulfillment); ok {
+		msf.Pairs = append(msf.Pairs, existing.Pairs...)
+	}
+	err := msf.Sign(types.FulfillmentSignContext{
+		ExtraObjects: []interface{}{inputIndex},
+		Transaction:  *txn,
+		Key:          types.KeyPair{PublicKey: pk, PrivateKey: sk},
+	})
+	if err != nil {
+		return err
+	}
+	txn.CoinInputs = append([]types.CoinInput(nil), txn.CoinInputs...)
+	txn.CoinInputs[inputIndex].Fulfillment = types.NewFulfillment(msf)
+	return nil
+}
+
+// NewPreSignedRedeem pre-signs the redeem of the contract, spent by the coin input at the given index,
+// using the given key pair of the contract sender and the adaptor point of the receiver.
+func NewPreSignedRedeem(txn types.Transaction, inputIndex uint64, pk types.PublicKey, sk types.ByteSlice, adaptorPoint types.ByteSlice) (PreSignedRedeem, error) {
+	algorithm, err := GetAdaptorSignatureAlgorithm(pk.Algorithm)
+	if err != nil {
+		return PreSignedRedeem{}, err
+	}
+	hash, err := redeemSignatureHash(txn, inputIndex, pk)
+	if err != nil {
+		return PreSignedRedeem{}, err
+	}
+	preSignature, err := algorithm.PreSignHash(hash, sk, adaptorPoint)
+	if err != nil {
+		return PreSignedRedeem{}, err
+	}
+	return PreSignedRedeem{
+		Transaction:  txn,
+		InputIndex:   inputIndex,
+		PublicKey:    pk,
+		PreSignature: preSignature,
+		AdaptorPoint: adaptorPoint,
+	}, nil
+}
+
+// Verify verifies the pre-signature of the contract sender.
+func (psr PreSignedRedeem) Verify() error {
+	if psr.InputIndex >= uint64(len(psr.Transaction.CoinInputs)) {
+		return fmt.Errorf("coin input index %d is out of range", psr.InputIndex)
+	}
+	algorithm, err := GetAdaptorSignatureAlgorithm(psr.PublicKey.Algorithm)
+	if err != nil {
+		return err
+	}
+	hash, err := redeemSignatureHash(psr.Transaction, psr.InputIndex, psr.PublicKey)
+	if err != nil {
+		return err
+	}
+	return algorithm.VerifyPreSignature(hash, psr.PublicKey.Key, psr.AdaptorPoint, psr.PreSignature)
+}
+
+// Complete completes the redeem transaction, by adapting the pre-signature using the adaptor secret,
+// and signing the redeem using the given key of the contract receiver.
+func (psr PreSignedRedeem) Complete(secret types.ByteSlice, pk types.PublicKey, sk types.ByteSlice) (types.Transaction, error) {
+	err := psr.Verify()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	algorithm, err := GetAdaptorSignatureAlgorithm(psr.PublicKey.Algorithm)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	signature, err := algorithm.Adapt(psr.PreSignature, secret)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	txn := psr.Transaction
+	txn.CoinInputs = append([]types.CoinInput(nil), psr.Transaction.CoinInputs...)
+	txn.CoinInputs[psr.InputIndex].Fulfillment = types.NewFulfillment(types.NewMultiSignatureFulfillment(
+		[]types.PublicKeySignaturePair{{PublicKey: psr.PublicKey, Signature: signature}}))
+	err = SignContractInput(&txn, psr.InputIndex, pk, sk)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	return txn, nil
+}
+
+// ExtractSecret extracts the adaptor secret from the given (published) transaction,
+// which redeems the contract of this pre-signed redeem.
+func (psr PreSignedRedeem) ExtractSecret(txn types.Transaction) (types.ByteSlice, error) {
+	if psr.InputIndex >= uint64(len(psr.Transaction.CoinInputs)) {
+		return nil, fmt.Errorf("coin input index %d is out of range", psr.InputIndex)
+	}
+	algorithm, err := GetAdaptorSignatureAlgorithm(psr.PublicKey.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	parentID := psr.Transaction.CoinInputs[psr.InputIndex].ParentID
+	for _, ci := range txn.CoinInputs {
+		if ci.ParentID != parentID {
+			continue
+		}
+		msf, ok := ci.Fulfillment.Fulfillment.(*types.MultiSignatureFulfillment)
+		if !ok {
+			return nil, ErrRedeemSignatureNotFound
+		}
+		for _, pair := range msf.Pairs {
+			if pair.PublicKey.Algorithm == psr.PublicKey.Algorithm && bytes.Equal(pair.PublicKey.Key, psr.PublicKey.Key) {
+				return algorithm.Extract(psr.PreSignature, pair.Signature, psr.AdaptorPoint)
+			}
+		}
+	}
+	return nil, ErrRedeemSignatureNotFound
+}
+
+// redeemSignatureHash returns the hash signed by the given public key,
+// as part of the multi signature fulfillment spending the contract.
+func redeemSignatureHash(txn types.Transaction, inputIndex uint64, pk types.PublicKey) (crypto.Hash, error) {
+	return txn.SignatureHash(inputIndex, pk)
+}
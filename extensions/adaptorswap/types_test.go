@@ -0,0 +1,283 @@
+package adaptorswap
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type testKey struct {
+	sk types.ByteSlice
+	pk types.PublicKey
+	uh types.UnlockHash
+}
+
+func newTestKey(t *testing.T) testKey {
+	sk, pk := crypto.GenerateKeyPair()
+	key := testKey{sk: types.ByteSlice(sk[:]), pk: types.Ed25519PublicKey(pk)}
+	var err error
+	key.uh, err = types.NewPubKeyUnlockHash(key.pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestEd25519AdaptorSignature(t *testing.T) {
+	algorithm, err := GetAdaptorSignatureAlgorithm(types.SignatureAlgoEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := newTestKey(t)
+	secret, point, err := algorithm.NewAdaptorSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.HashBytes([]byte("adaptor signature message"))
+
+	preSignature, err := algorithm.PreSignHash(hash, key.sk, point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = algorithm.VerifyPreSignature(hash, key.pk.Key, point, preSignature); err != nil {
+		t.Fatal(err)
+	}
+	// a pre-signature is not a valid signature by itself
+	ed25519, _ := types.GetSignatureAlgorithm(types.SignatureAlgoEd25519)
+	if err = ed25519.VerifyHash(hash, key.pk.Key, preSignature); err == nil {
+		t.Error("expected pre-signature to be an invalid signature")
+	}
+	// nor is it valid for another adaptor point or hash
+	_, otherPoint, _ := algorithm.NewAdaptorSecret()
+	if err = algorithm.VerifyPreSignature(hash, key.pk.Key, otherPoint, preSignature); err != ErrInvalidPreSignature {
+		t.Errorf("expected error %v, got: %v", ErrInvalidPreSignature, err)
+	}
+	if err = algorithm.VerifyPreSignature(crypto.HashBytes([]byte("other")), key.pk.Key, point, preSignature); err != ErrInvalidPreSignature {
+		t.Errorf("expected error %v, got: %v", ErrInvalidPreSignature, err)
+	}
+
+	// the adapted signature is a regular Ed25519 signature, from which the secret can be extracted
+	signature, err := algorithm.Adapt(preSignature, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ed25519.VerifyHash(hash, key.pk.Key, signature); err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := algorithm.Extract(preSignature, signature, point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.String() != secret.String() {
+		t.Errorf("extracted secret %v != %v", extracted, secret)
+	}
+	if _, err = algorithm.Extract(preSignature, signature, otherPoint); err != ErrInvalidAdaptorSecret {
+		t.Errorf("expected error %v, got: %v", ErrInvalidAdaptorSecret, err)
+	}
+
+	if _, err = GetAdaptorSignatureAlgorithm(types.SignatureAlgoSecp256k1); err != ErrUnsupportedSignatureAlgorithm {
+		t.Errorf("expected error %v, got: %v", ErrUnsupportedSignatureAlgorithm, err)
+	}
+}
+
+func TestContractCondition(t *testing.T) {
+	sender, receiver, other := newTestKey(t), newTestKey(t), newTestKey(t)
+	contract := Contract{Sender: sender.uh, Receiver: receiver.uh}
+	condition := types.NewCondition(contract.Condition())
+	if err := condition.IsStandardCondition(types.ValidationContext{}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded types.UnlockConditionProxy
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseContract(decoded, sender.uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != contract {
+		t.Errorf("parsed contract %v != %v", parsed, contract)
+	}
+	// the receiver is the other address, regardless of the order of the addresses
+	parsed, err = ParseContract(types.NewCondition(
+		types.NewMultiSignatureCondition(types.UnlockHashSlice{receiver.uh, sender.uh}, 2)), sender.uh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != contract {
+		t.Errorf("parsed contract %v != %v", parsed, contract)
+	}
+
+	for _, c := range []types.MarshalableUnlockCondition{
+		types.NewUnlockHashCondition(sender.uh),
+		types.NewTimeLockCondition(1530169858, contract.Condition()),
+		types.NewMultiSignatureCondition(types.UnlockHashSlice{sender.uh, receiver.uh}, 1),
+		types.NewMultiSignatureCondition(types.UnlockHashSlice{sender.uh, receiver.uh, other.uh}, 2),
+		types.NewMultiSignatureCondition(types.UnlockHashSlice{receiver.uh, other.uh}, 2),
+		types.NewMultiSignatureCondition(types.UnlockHashSlice{sender.uh, sender.uh}, 2),
+	} {
+		if _, err = ParseContract(types.NewCondition(c), sender.uh); err != ErrInvalidContract {
+			t.Errorf("expected error %v for %v, got: %v", ErrInvalidContract, c, err)
+		}
+	}
+}
+
+func TestPreSignedRedeem(t *testing.T) {
+	sender, receiver := newTestKey(t), newTestKey(t)
+	contract := Contract{Sender: sender.uh, Receiver: receiver.uh}
+	condition := contract.Condition()
+
+	txn := types.Transaction{
+		Version:    types.TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		CoinOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(9),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(receiver.uh)),
+		}},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	algorithm, _ := GetAdaptorSignatureAlgorithm(types.SignatureAlgoEd25519)
+	secret, point, err := algorithm.NewAdaptorSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the sender pre-signs the redeem, which the receiver can verify but not publish
+	psr, err := NewPreSignedRedeem(txn, 0, sender.pk, sender.sk, point)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(psr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded PreSignedRedeem
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err = decoded.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	otherSecret, _, _ := algorithm.NewAdaptorSecret()
+	redeemTxn, err := decoded.Complete(otherSecret, receiver.pk, receiver.sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := types.FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		BlockHeight:  1,
+		BlockTime:    1530169857,
+		Transaction:  redeemTxn,
+	}
+	if err = condition.Fulfill(redeemTxn.CoinInputs[0].Fulfillment.Fulfillment, ctx); err == nil {
+		t.Error("expected redeem completed using the wrong secret to fail")
+	}
+
+	// the receiver completes the redeem using the secret, from which the sender can extract it
+	redeemTxn, err = decoded.Complete(secret, receiver.pk, receiver.sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Transaction = redeemTxn
+	if err = condition.Fulfill(redeemTxn.CoinInputs[0].Fulfillment.Fulfillment, ctx); err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := psr.ExtractSecret(redeemTxn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.String() != secret.String() {
+		t.Errorf("extracted secret %v != %v", extracted, secret)
+	}
+	if _, err = psr.ExtractSecret(txn); err != ErrRedeemSignatureNotFound {
+		t.Errorf("expected error %v, got: %v", ErrRedeemSignatureNotFound, err)
+	}
+
+	// the refund is only valid once signed by the receiver, and sends the coins time-locked to the sender
+	refundTxn := txn
+	refundTxn.CoinOutputs = []types.CoinOutput{{
+		Value:     types.NewCurrency64(9),
+		Condition: types.NewCondition(contract.RefundCondition(1530169858)),
+	}}
+	if _, err = contract.VerifyRefund(refundTxn, 0); err != ErrInvalidRefund {
+		t.Errorf("expected error %v for unsigned refund, got: %v", ErrInvalidRefund, err)
+	}
+	invalidRefundTxn := refundTxn
+	if err = SignContractInput(&invalidRefundTxn, 0, sender.pk, sender.sk); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = contract.VerifyRefund(invalidRefundTxn, 0); err != ErrInvalidRefund {
+		t.Errorf("expected error %v for refund signed by the sender, got: %v", ErrInvalidRefund, err)
+	}
+	invalidRefundTxn = txn
+	if err = SignContractInput(&invalidRefundTxn, 0, receiver.pk, receiver.sk); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = contract.VerifyRefund(invalidRefundTxn, 0); err != ErrInvalidRefund {
+		t.Errorf("expected error %v for refund sent to the receiver, got: %v", ErrInvalidRefund, err)
+	}
+	if err = SignContractInput(&refundTxn, 0, receiver.pk, receiver.sk); err != nil {
+		t.Fatal(err)
+	}
+	timeLock, err := contract.VerifyRefund(refundTxn, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeLock != 1530169858 {
+		t.Errorf("unexpected refund time lock: %v", timeLock)
+	}
+	ctx.Transaction = refundTxn
+	if err = condition.Fulfill(refundTxn.CoinInputs[0].Fulfillment.Fulfillment, ctx); err == nil {
+		t.Error("expected refund signed only by the receiver to fail")
+	}
+	if err = SignContractInput(&refundTxn, 0, sender.pk, sender.sk); err != nil {
+		t.Fatal(err)
+	}
+	ctx.Transaction = refundTxn
+	if err = condition.Fulfill(refundTxn.CoinInputs[0].Fulfillment.Fulfillment, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = contract.VerifyRefund(refundTxn, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPreSignedRedeemUnsupportedSignatureAlgorithm(t *testing.T) {
+	sk, pk := crypto.GenerateSecp256k1KeyPair()
+	sender := types.Secp256k1PublicKey(pk)
+	txn := types.Transaction{
+		Version:    types.TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+	}
+	_, point, err := ed25519AdaptorSignatureAlgorithm{}.NewAdaptorSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// secp256k1 keys cannot pre-sign a redeem, nor can a redeem pre-signed by them be verified or extracted
+	if _, err = NewPreSignedRedeem(txn, 0, sender, types.ByteSlice(sk[:]), point); err != ErrUnsupportedSignatureAlgorithm {
+		t.Errorf("expected error %v, got: %v", ErrUnsupportedSignatureAlgorithm, err)
+	}
+	psr := PreSignedRedeem{
+		Transaction:  txn,
+		PublicKey:    sender,
+		PreSignature: make(types.ByteSlice, crypto.SignatureSize),
+		AdaptorPoint: point,
+	}
+	if err = psr.Verify(); err != ErrUnsupportedSignatureAlgorithm {
+		t.Errorf("expected error %v, got: %v", ErrUnsupportedSignatureAlgorithm, err)
+	}
+	receiver := newTestKey(t)
+	if _, err = psr.Complete(point, receiver.pk, receiver.sk); err != ErrUnsupportedSignatureAlgorithm {
+		t.Errorf("expected error %v, got: %v", ErrUnsupportedSignatureAlgorithm, err)
+	}
+	if _, err = psr.ExtractSecret(txn); err != ErrUnsupportedSignatureAlgorithm {
+		t.Errorf("expected error %v, got: %v", ErrUnsupportedSignatureAlgorithm, err)
+	}
+}
@@ -109,6 +109,8 @@ func NewPlugin(genesisAuthCondition types.UnlockConditionProxy, authAddressUpdat
 		AuthInfoGetter:     p,
 		TransactionVersion: authConditionUpdateTransactionVersion,
 	})
+	modules.RegisterExplorerTransactionExtension(authAddressUpdateTransactionVersion, authAddressUpdateExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(authConditionUpdateTransactionVersion, authConditionUpdateExplorerExtension{})
 	return p
 }
 
@@ -0,0 +1,86 @@
+package authcointx
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// AuthAddressUpdateExplorerView is the explorer view on the extension data of an AuthAddressUpdateTransaction.
+	AuthAddressUpdateExplorerView struct {
+		Nonce           types.TransactionNonce       `json:"nonce"`
+		AuthAddresses   []types.UnlockHash           `json:"authaddresses"`
+		DeauthAddresses []types.UnlockHash           `json:"deauthaddresses"`
+		AuthFulfillment types.UnlockFulfillmentProxy `json:"authfulfillment"`
+	}
+
+	// AuthConditionUpdateExplorerView is the explorer view on the extension data of an AuthConditionUpdateTransaction.
+	AuthConditionUpdateExplorerView struct {
+		Nonce           types.TransactionNonce       `json:"nonce"`
+		AuthCondition   types.UnlockConditionProxy   `json:"authcondition"`
+		AuthFulfillment types.UnlockFulfillmentProxy `json:"authfulfillment"`
+	}
+
+	authAddressUpdateExplorerExtension   struct{}
+	authConditionUpdateExplorerExtension struct{}
+)
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (authAddressUpdateExplorerExtension) TransactionType() string { return "auth address update" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (authAddressUpdateExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	auext, ok := txn.Extension.(*AuthAddressUpdateTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return AuthAddressUpdateExplorerView{
+		Nonce:           auext.Nonce,
+		AuthAddresses:   auext.AuthAddresses,
+		DeauthAddresses: auext.DeauthAddresses,
+		AuthFulfillment: auext.AuthFulfillment,
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the authority which signed it, if known.
+func (authAddressUpdateExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	auext, ok := txn.Extension.(*AuthAddressUpdateTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return fulfillmentAddresses(auext.AuthFulfillment), nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (authConditionUpdateExplorerExtension) TransactionType() string { return "auth condition update" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (authConditionUpdateExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	acext, ok := txn.Extension.(*AuthConditionUpdateTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return AuthConditionUpdateExplorerView{
+		Nonce:           acext.Nonce,
+		AuthCondition:   acext.AuthCondition,
+		AuthFulfillment: acext.AuthFulfillment,
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the authority which signed it, if known.
+func (authConditionUpdateExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	acext, ok := txn.Extension.(*AuthConditionUpdateTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return fulfillmentAddresses(acext.AuthFulfillment), nil
+}
+
+func fulfillmentAddresses(fulfillment types.UnlockFulfillmentProxy) []types.UnlockHash {
+	if uh, ok := modules.FulfillmentAddress(fulfillment); ok {
+		return []types.UnlockHash{uh}
+	}
+	return nil
+}
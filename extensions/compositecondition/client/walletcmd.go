@@ -0,0 +1,242 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/extensions/compositecondition"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the composite condition extension,
+// used to compute the address of composite conditions and to sign the inputs they lock.
+func CreateWalletCmds(ccli *client.CommandLineClient) error {
+	walletCmd := &walletCmd{
+		cli: ccli,
+	}
+
+	var (
+		compositeCmd = &cobra.Command{
+			Use:   "composite",
+			Short: "Create and sign for composite (AND/OR/threshold) conditions",
+		}
+		addressCmd = &cobra.Command{
+			Use:   "address <conditionjson>",
+			Short: "Print the address of a (JSON-encoded) composite condition",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.addressCmd,
+		}
+		signCmd = &cobra.Command{
+			Use:   "sign <txnjson>",
+			Short: "Sign the inputs of a transaction which are locked by a composite condition",
+			Long: `Sign the inputs of the given (JSON-encoded) transaction,
+which spend outputs locked by a composite condition.
+
+All branches which are fulfillable at the current height,
+and locked by an address of this wallet, are signed.
+Fulfillments already defined by other signers are kept,
+such that the transaction can be passed from signer to signer.
+The returned (JSON-encoded) transaction can be sent using the send transaction command,
+once enough branches are fulfilled.`,
+			Args: cobra.ExactArgs(1),
+			Run:  walletCmd.signCmd,
+		}
+	)
+
+	compositeCmd.AddCommand(
+		addressCmd,
+		signCmd,
+	)
+	ccli.WalletCmd.AddCommand(compositeCmd)
+
+	return nil
+}
+
+type walletCmd struct {
+	cli *client.CommandLineClient
+}
+
+func (walletCmd *walletCmd) addressCmd(cmd *cobra.Command, args []string) {
+	var condition types.UnlockConditionProxy
+	parseJSON(cmd, args[0], &condition, "condition")
+	if condition.ConditionType() != compositecondition.ConditionTypeComposite {
+		cli.Die("condition is not a composite condition")
+	}
+	err := condition.IsStandardCondition(types.ValidationContext{})
+	if err != nil {
+		cli.DieWithError("condition is not standard", err)
+	}
+	fmt.Println("address:", condition.UnlockHash().String())
+	fmt.Print("condition: ")
+	encodeJSON(condition)
+}
+
+func (walletCmd *walletCmd) signCmd(cmd *cobra.Command, args []string) {
+	var txn types.Transaction
+	parseJSON(cmd, args[0], &txn, "transaction")
+
+	var consensus api.ConsensusGET
+	err := walletCmd.cli.GetWithResponse("/consensus", &consensus)
+	if err != nil {
+		cli.DieWithError("failed to get the current consensus state", err)
+	}
+	// the transaction can at the earliest be part of the next block
+	ctx := types.FulfillableContext{
+		BlockHeight: consensus.Height + 1,
+		BlockTime:   types.CurrentTimestamp(),
+	}
+
+	var signed int
+	for idx, ci := range txn.CoinInputs {
+		condition := walletCmd.coinOutputCondition(ci.ParentID)
+		signed += walletCmd.signInput(&txn, &txn.CoinInputs[idx].Fulfillment, uint64(idx), condition, ctx)
+	}
+	for idx, bsi := range txn.BlockStakeInputs {
+		condition := walletCmd.blockStakeOutputCondition(bsi.ParentID)
+		signed += walletCmd.signInput(&txn, &txn.BlockStakeInputs[idx].Fulfillment, uint64(idx), condition, ctx)
+	}
+	if signed == 0 {
+		cli.Die("wallet cannot sign any composite condition branch of the transaction")
+	}
+	encodeJSON(txn)
+}
+
+// signInput signs all branches of the composite condition (if it is one) that can be signed by the wallet,
+// adding them to the (optionally existing) composite fulfillment of the input.
+// The amount of signed branches is returned.
+func (walletCmd *walletCmd) signInput(txn *types.Transaction, fulfillment *types.UnlockFulfillmentProxy, idx uint64, condition types.UnlockConditionProxy, ctx types.FulfillableContext) int {
+	cc, ok := condition.Condition.(*compositecondition.CompositeCondition)
+	if !ok {
+		return 0
+	}
+	cf, ok := fulfillment.Fulfillment.(*compositecondition.CompositeFulfillment)
+	if !ok || len(cf.Fulfillments) != len(cc.Conditions) {
+		cf = newEmptyFulfillment(cc)
+	}
+	keys := walletCmd.fulfillBranches(cc, cf, nil, ctx)
+	if len(keys) == 0 {
+		return 0
+	}
+	*fulfillment = types.NewFulfillment(cf)
+	for _, key := range keys {
+		err := fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: []interface{}{idx},
+			Transaction:  *txn,
+			Key:          key,
+		})
+		if err != nil {
+			cli.DieWithError("failed to sign composite condition branch", err)
+		}
+	}
+	return len(keys)
+}
+
+// fulfillBranches defines an (unsigned) fulfillment for all undefined branches
+// of the composite condition which can be signed by the wallet,
+// returning the keys to sign them with.
+func (walletCmd *walletCmd) fulfillBranches(cc *compositecondition.CompositeCondition, cf *compositecondition.CompositeFulfillment, path []int, ctx types.FulfillableContext) []compositecondition.SignKey {
+	var keys []compositecondition.SignKey
+	for idx, condition := range cc.Conditions {
+		branchPath := append(append([]int(nil), path...), idx)
+		if nested, ok := condition.Condition.(*compositecondition.CompositeCondition); ok {
+			nf, ok := cf.Fulfillments[idx].Fulfillment.(*compositecondition.CompositeFulfillment)
+			if !ok {
+				if cf.Fulfillments[idx].FulfillmentType() != types.FulfillmentTypeNil {
+					continue
+				}
+				nf = newEmptyFulfillment(nested)
+			}
+			nestedKeys := walletCmd.fulfillBranches(nested, nf, branchPath, ctx)
+			if len(nestedKeys) > 0 {
+				cf.Fulfillments[idx] = types.NewFulfillment(nf)
+				keys = append(keys, nestedKeys...)
+			}
+			continue
+		}
+		if cf.Fulfillments[idx].FulfillmentType() != types.FulfillmentTypeNil || !condition.Fulfillable(ctx) {
+			continue
+		}
+		// both unlock hash and time lock conditions are fulfilled using a single signature,
+		// of the key linked to their unlock hash
+		switch condition.ConditionType() {
+		case types.ConditionTypeUnlockHash, types.ConditionTypeTimeLock:
+		default:
+			continue
+		}
+		uh := condition.UnlockHash()
+		if uh.Type != types.UnlockTypePubKey {
+			continue
+		}
+		pk, sk, ok := walletCmd.spendableKey(uh)
+		if !ok {
+			continue
+		}
+		cf.Fulfillments[idx] = types.NewFulfillment(types.NewSingleSignatureFulfillment(pk))
+		keys = append(keys, compositecondition.SignKey{Path: branchPath, Key: sk})
+	}
+	return keys
+}
+
+// spendableKey returns the key pair linked to the given unlock hash,
+// if it is owned by the wallet.
+func (walletCmd *walletCmd) spendableKey(uh types.UnlockHash) (types.PublicKey, types.ByteSlice, bool) {
+	var resp api.WalletKeyGet
+	err := walletCmd.cli.GetWithResponse("/wallet/key/"+uh.String(), &resp)
+	if err != nil || len(resp.SecretKey) == 0 {
+		return types.PublicKey{}, nil, false
+	}
+	pk := types.PublicKey{
+		Key: resp.PublicKey,
+	}
+	err = pk.Algorithm.LoadSpecifier(resp.AlgorithmSpecifier)
+	if err != nil {
+		cli.DieWithError("invalid public key algorithm specifier loaded", err)
+	}
+	return pk, resp.SecretKey, true
+}
+
+func (walletCmd *walletCmd) coinOutputCondition(id types.CoinOutputID) types.UnlockConditionProxy {
+	var resp api.ConsensusGetUnspentCoinOutput
+	err := walletCmd.cli.GetWithResponse("/consensus/unspent/coinoutputs/"+id.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get unspent coin output "+id.String(), err)
+	}
+	return resp.Output.Condition
+}
+
+func (walletCmd *walletCmd) blockStakeOutputCondition(id types.BlockStakeOutputID) types.UnlockConditionProxy {
+	var resp api.ConsensusGetUnspentBlockstakeOutput
+	err := walletCmd.cli.GetWithResponse("/consensus/unspent/blockstakeoutputs/"+id.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get unspent block stake output "+id.String(), err)
+	}
+	return resp.Output.Condition
+}
+
+// newEmptyFulfillment creates a composite fulfillment for the given condition,
+// which does not fulfill any of its conditions.
+func newEmptyFulfillment(cc *compositecondition.CompositeCondition) *compositecondition.CompositeFulfillment {
+	return compositecondition.NewCompositeFulfillment(make([]types.MarshalableUnlockFulfillment, len(cc.Conditions))...)
+}
+
+func parseJSON(cmd *cobra.Command, str string, value interface{}, name string) {
+	err := json.Unmarshal([]byte(str), value)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid JSON-encoded "+name, err)
+	}
+}
+
+// encodeJSON encodes the value as JSON to the STDOUT
+func encodeJSON(value interface{}) {
+	err := json.NewEncoder(os.Stdout).Encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
@@ -0,0 +1,438 @@
+package compositecondition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// ConditionTypeComposite defines an unlock condition which combines other conditions,
+	// requiring all (AND), any (OR) or a minimum amount (threshold) of them to be fulfilled.
+	// Composite conditions can be nested, such that policies can be expressed as a tree,
+	// of which the leaves are any other standard condition.
+	ConditionTypeComposite types.ConditionType = 7
+
+	// FulfillmentTypeComposite defines the fulfillment of a CompositeCondition,
+	// defining for each of its conditions either the fulfillment of that condition,
+	// or a nil fulfillment for conditions that are not fulfilled.
+	FulfillmentTypeComposite types.FulfillmentType = 5
+
+	// UnlockTypeComposite is the unlock type of the unlock hash (address)
+	// of a CompositeCondition.
+	UnlockTypeComposite types.UnlockType = 5
+)
+
+// The operators that define how many conditions of a CompositeCondition have to be fulfilled.
+const (
+	// OperatorAnd requires all conditions to be fulfilled.
+	OperatorAnd Operator = iota
+	// OperatorOr requires at least one condition to be fulfilled.
+	OperatorOr
+	// OperatorThreshold requires at least MinimumFulfilledCount conditions to be fulfilled.
+	OperatorThreshold
+)
+
+const (
+	// MaxConditionCount is the maximum amount of conditions
+	// a standard CompositeCondition can combine.
+	MaxConditionCount = 16
+	// MaxDepth is the maximum depth of a standard CompositeCondition,
+	// a composite condition which only combines non-composite conditions has a depth of 1.
+	MaxDepth = 8
+)
+
+var (
+	// ErrInsufficientFulfillments is returned in case less conditions of a CompositeCondition
+	// are fulfilled than required by its operator.
+	ErrInsufficientFulfillments = errors.New("insufficient conditions fulfilled")
+	// ErrFulfillmentCountMismatch is returned in case a CompositeFulfillment does not define
+	// exactly one (optionally nil) fulfillment for each condition of a CompositeCondition.
+	ErrFulfillmentCountMismatch = errors.New("fulfillment count does not match condition count")
+)
+
+// RegisterUnlockTypes registers the composite condition and fulfillment types,
+// such that outputs can be locked by (and inputs unlocked by) a combination of other conditions.
+//
+// As these types are part of the consensus rules,
+// all nodes of a network should register them at the same time.
+func RegisterUnlockTypes() {
+	types.RegisterUnlockConditionType(ConditionTypeComposite,
+		func() types.MarshalableUnlockCondition { return &CompositeCondition{} })
+	types.RegisterUnlockFulfillmentType(FulfillmentTypeComposite,
+		func() types.MarshalableUnlockFulfillment { return &CompositeFulfillment{} })
+}
+
+// UnregisterUnlockTypes unregisters the composite condition and fulfillment types.
+func UnregisterUnlockTypes() {
+	types.RegisterUnlockConditionType(ConditionTypeComposite, nil)
+	types.RegisterUnlockFulfillmentType(FulfillmentTypeComposite, nil)
+}
+
+type (
+	// Operator defines how many conditions of a CompositeCondition have to be fulfilled.
+	Operator uint8
+
+	// CompositeCondition implements the ConditionTypeComposite ConditionType.
+	// See ConditionTypeComposite for more information.
+	CompositeCondition struct {
+		Operator Operator `json:"operator"`
+		// MinimumFulfilledCount is only defined for the OperatorThreshold operator.
+		MinimumFulfilledCount uint64                       `json:"minimumfulfilledcount,omitempty"`
+		Conditions            []types.UnlockConditionProxy `json:"conditions"`
+	}
+
+	// CompositeFulfillment implements the FulfillmentTypeComposite FulfillmentType.
+	// See FulfillmentTypeComposite for more information.
+	CompositeFulfillment struct {
+		Fulfillments []types.UnlockFulfillmentProxy `json:"fulfillments"`
+	}
+
+	// SignKey is the key used to sign a CompositeFulfillment.
+	// It signs the fulfillment at the given path, using the given key,
+	// where the path contains the index of the fulfillment at each depth of the tree.
+	SignKey struct {
+		Path []int
+		Key  interface{}
+	}
+)
+
+var (
+	_ types.MarshalableUnlockCondition   = (*CompositeCondition)(nil)
+	_ types.MarshalableUnlockFulfillment = (*CompositeFulfillment)(nil)
+)
+
+// String implements fmt.Stringer.String
+func (op Operator) String() string {
+	switch op {
+	case OperatorAnd:
+		return "and"
+	case OperatorOr:
+		return "or"
+	case OperatorThreshold:
+		return "threshold"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(op))
+	}
+}
+
+// LoadString loads an operator from its string representation.
+func (op *Operator) LoadString(str string) error {
+	switch str {
+	case "and":
+		*op = OperatorAnd
+	case "or":
+		*op = OperatorOr
+	case "threshold":
+		*op = OperatorThreshold
+	default:
+		return fmt.Errorf("unknown operator %q", str)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (op Operator) MarshalJSON() ([]byte, error) {
+	if op > OperatorThreshold {
+		return nil, fmt.Errorf("unknown operator %d", uint8(op))
+	}
+	return json.Marshal(op.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (op *Operator) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	return op.LoadString(str)
+}
+
+// NewAndCondition creates a composite condition which requires all given conditions to be fulfilled.
+func NewAndCondition(conditions ...types.MarshalableUnlockCondition) *CompositeCondition {
+	return newCompositeCondition(OperatorAnd, 0, conditions)
+}
+
+// NewOrCondition creates a composite condition which requires any of the given conditions to be fulfilled.
+func NewOrCondition(conditions ...types.MarshalableUnlockCondition) *CompositeCondition {
+	return newCompositeCondition(OperatorOr, 0, conditions)
+}
+
+// NewThresholdCondition creates a composite condition which requires
+// at least minimumFulfilledCount of the given conditions to be fulfilled.
+func NewThresholdCondition(minimumFulfilledCount uint64, conditions ...types.MarshalableUnlockCondition) *CompositeCondition {
+	return newCompositeCondition(OperatorThreshold, minimumFulfilledCount, conditions)
+}
+
+func newCompositeCondition(op Operator, minimumFulfilledCount uint64, conditions []types.MarshalableUnlockCondition) *CompositeCondition {
+	cc := &CompositeCondition{
+		Operator:              op,
+		MinimumFulfilledCount: minimumFulfilledCount,
+		Conditions:            make([]types.UnlockConditionProxy, 0, len(conditions)),
+	}
+	for _, condition := range conditions {
+		if condition == nil {
+			condition = &types.NilCondition{}
+		}
+		cc.Conditions = append(cc.Conditions, types.NewCondition(condition))
+	}
+	return cc
+}
+
+// RequiredFulfilledCount returns the amount of conditions which have to be fulfilled,
+// in order to fulfill this condition.
+func (cc *CompositeCondition) RequiredFulfilledCount() uint64 {
+	switch cc.Operator {
+	case OperatorAnd:
+		return uint64(len(cc.Conditions))
+	case OperatorOr:
+		return 1
+	default:
+		return cc.MinimumFulfilledCount
+	}
+}
+
+// Fulfill implements UnlockCondition.Fulfill
+//
+// Each non-nil fulfillment has to fulfill the condition at the same index,
+// and at least the required amount of conditions has to be fulfilled.
+func (cc *CompositeCondition) Fulfill(fulfillment types.UnlockFulfillment, ctx types.FulfillContext) error {
+	cf, ok := fulfillment.(*CompositeFulfillment)
+	if !ok {
+		return types.ErrUnexpectedUnlockFulfillment
+	}
+	if len(cf.Fulfillments) != len(cc.Conditions) {
+		return ErrFulfillmentCountMismatch
+	}
+	var fulfilled uint64
+	for idx, f := range cf.Fulfillments {
+		if f.FulfillmentType() == types.FulfillmentTypeNil {
+			continue
+		}
+		err := cc.Conditions[idx].Fulfill(f, ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fulfill condition #%d: %v", idx, err)
+		}
+		fulfilled++
+	}
+	if required := cc.RequiredFulfilledCount(); required == 0 || fulfilled < required {
+		return ErrInsufficientFulfillments
+	}
+	return nil
+}
+
+// ConditionType implements UnlockCondition.ConditionType
+func (cc *CompositeCondition) ConditionType() types.ConditionType {
+	return ConditionTypeComposite
+}
+
+// IsStandardCondition implements UnlockCondition.IsStandardCondition
+func (cc *CompositeCondition) IsStandardCondition(ctx types.ValidationContext) error {
+	return cc.isStandardCondition(ctx, 1)
+}
+
+func (cc *CompositeCondition) isStandardCondition(ctx types.ValidationContext, depth int) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("composite condition cannot be nested more than %d levels deep", MaxDepth)
+	}
+	n := len(cc.Conditions)
+	if n < 2 {
+		return errors.New("composite condition has to combine at least two conditions")
+	}
+	if n > MaxConditionCount {
+		return fmt.Errorf("composite condition cannot combine more than %d conditions", MaxConditionCount)
+	}
+	switch cc.Operator {
+	case OperatorAnd, OperatorOr:
+		if cc.MinimumFulfilledCount != 0 {
+			return fmt.Errorf("minimum fulfilled count cannot be defined for the %s operator", cc.Operator)
+		}
+	case OperatorThreshold:
+		if cc.MinimumFulfilledCount == 0 || cc.MinimumFulfilledCount > uint64(n) {
+			return fmt.Errorf("invalid minimum fulfilled count %d for %d conditions", cc.MinimumFulfilledCount, n)
+		}
+	default:
+		return fmt.Errorf("unknown operator %d", uint8(cc.Operator))
+	}
+	for idx, condition := range cc.Conditions {
+		var err error
+		if child, ok := condition.Condition.(*CompositeCondition); ok {
+			err = child.isStandardCondition(ctx, depth+1)
+		} else {
+			err = condition.IsStandardCondition(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("non-standard condition #%d: %v", idx, err)
+		}
+	}
+	return nil
+}
+
+// UnlockHash implements UnlockCondition.UnlockHash
+//
+// The unlock hash commits to the entire tree of conditions,
+// including the properties (e.g. lock times) that are not committed to by the unlock hashes of the conditions.
+func (cc *CompositeCondition) UnlockHash() types.UnlockHash {
+	h, err := crypto.HashAll(uint8(cc.Operator), cc.MinimumFulfilledCount, cc.Conditions)
+	if err != nil {
+		return types.NilUnlockHash
+	}
+	return types.NewUnlockHash(UnlockTypeComposite, h)
+}
+
+// Equal implements UnlockCondition.Equal
+func (cc *CompositeCondition) Equal(c types.UnlockCondition) bool {
+	occ, ok := c.(*CompositeCondition)
+	if !ok {
+		return false
+	}
+	if cc.Operator != occ.Operator || cc.MinimumFulfilledCount != occ.MinimumFulfilledCount ||
+		len(cc.Conditions) != len(occ.Conditions) {
+		return false
+	}
+	for idx, condition := range cc.Conditions {
+		if !condition.Equal(occ.Conditions[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fulfillable implements UnlockCondition.Fulfillable
+func (cc *CompositeCondition) Fulfillable(ctx types.FulfillableContext) bool {
+	var fulfillable uint64
+	for _, condition := range cc.Conditions {
+		if condition.Fulfillable(ctx) {
+			fulfillable++
+		}
+	}
+	required := cc.RequiredFulfilledCount()
+	return required != 0 && fulfillable >= required
+}
+
+// Marshal implements MarshalableUnlockCondition.Marshal
+func (cc *CompositeCondition) Marshal(f types.MarshalFunc) ([]byte, error) {
+	return f(uint8(cc.Operator), cc.MinimumFulfilledCount, cc.Conditions)
+}
+
+// Unmarshal implements MarshalableUnlockCondition.Unmarshal
+func (cc *CompositeCondition) Unmarshal(b []byte, f types.UnmarshalFunc) error {
+	var op uint8
+	err := f(b, &op, &cc.MinimumFulfilledCount, &cc.Conditions)
+	if err != nil {
+		return err
+	}
+	cc.Operator = Operator(op)
+	return nil
+}
+
+// NewCompositeFulfillment creates a new composite fulfillment,
+// using the given fulfillments, one for each condition of the composite condition to fulfill.
+// Nil fulfillments can be given for conditions that are not fulfilled.
+func NewCompositeFulfillment(fulfillments ...types.MarshalableUnlockFulfillment) *CompositeFulfillment {
+	cf := &CompositeFulfillment{
+		Fulfillments: make([]types.UnlockFulfillmentProxy, 0, len(fulfillments)),
+	}
+	for _, fulfillment := range fulfillments {
+		if fulfillment == nil {
+			fulfillment = &types.NilFulfillment{}
+		}
+		cf.Fulfillments = append(cf.Fulfillments, types.NewFulfillment(fulfillment))
+	}
+	return cf
+}
+
+// FulfillmentType implements UnlockFulfillment.FulfillmentType
+func (cf *CompositeFulfillment) FulfillmentType() types.FulfillmentType {
+	return FulfillmentTypeComposite
+}
+
+// IsStandardFulfillment implements UnlockFulfillment.IsStandardFulfillment
+func (cf *CompositeFulfillment) IsStandardFulfillment(ctx types.ValidationContext) error {
+	if len(cf.Fulfillments) > MaxConditionCount {
+		return fmt.Errorf("composite fulfillment cannot define more than %d fulfillments", MaxConditionCount)
+	}
+	var defined int
+	for idx, fulfillment := range cf.Fulfillments {
+		if fulfillment.FulfillmentType() == types.FulfillmentTypeNil {
+			continue
+		}
+		err := fulfillment.IsStandardFulfillment(ctx)
+		if err != nil {
+			return fmt.Errorf("non-standard fulfillment #%d: %v", idx, err)
+		}
+		defined++
+	}
+	if defined == 0 {
+		return errors.New("composite fulfillment has to define at least one fulfillment")
+	}
+	return nil
+}
+
+// Equal implements UnlockFulfillment.Equal
+func (cf *CompositeFulfillment) Equal(f types.UnlockFulfillment) bool {
+	ocf, ok := f.(*CompositeFulfillment)
+	if !ok || len(cf.Fulfillments) != len(ocf.Fulfillments) {
+		return false
+	}
+	for idx, fulfillment := range cf.Fulfillments {
+		if !fulfillment.Equal(ocf.Fulfillments[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign implements UnlockFulfillment.Sign
+//
+// The key is expected to be a SignKey, as a composite fulfillment can contain
+// fulfillments signed by different keys. The fulfillment at the path of the key
+// has to be defined (unsigned) prior to calling this method.
+func (cf *CompositeFulfillment) Sign(ctx types.FulfillmentSignContext) error {
+	key, ok := ctx.Key.(SignKey)
+	if !ok {
+		return errors.New("invalid composite sign key to sign this input")
+	}
+	if len(key.Path) == 0 {
+		return errors.New("composite sign key requires a path")
+	}
+	idx := key.Path[0]
+	if idx < 0 || idx >= len(cf.Fulfillments) {
+		return fmt.Errorf("invalid composite sign key path: no fulfillment #%d", idx)
+	}
+	fulfillment := cf.Fulfillments[idx]
+	if fulfillment.FulfillmentType() == types.FulfillmentTypeNil {
+		return fmt.Errorf("invalid composite sign key path: fulfillment #%d is not defined", idx)
+	}
+	if len(key.Path) > 1 {
+		// sign the fulfillment of the nested composite condition
+		ctx.Key = SignKey{Path: key.Path[1:], Key: key.Key}
+	} else {
+		ctx.Key = key.Key
+	}
+	return fulfillment.Sign(ctx)
+}
+
+// Marshal implements MarshalableUnlockFulfillment.Marshal
+//
+// Undefined fulfillments are marshaled as explicit nil fulfillments,
+// as the binary encoding of an undefined proxy cannot be decoded as part of a slice.
+func (cf *CompositeFulfillment) Marshal(f types.MarshalFunc) ([]byte, error) {
+	fulfillments := make([]types.UnlockFulfillmentProxy, 0, len(cf.Fulfillments))
+	for _, fulfillment := range cf.Fulfillments {
+		if fulfillment.Fulfillment == nil {
+			fulfillment = types.NewFulfillment(&types.NilFulfillment{})
+		}
+		fulfillments = append(fulfillments, fulfillment)
+	}
+	return f(fulfillments)
+}
+
+// Unmarshal implements MarshalableUnlockFulfillment.Unmarshal
+func (cf *CompositeFulfillment) Unmarshal(b []byte, f types.UnmarshalFunc) error {
+	return f(b, &cf.Fulfillments)
+}
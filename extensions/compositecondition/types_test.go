@@ -0,0 +1,286 @@
+package compositecondition
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type testKey struct {
+	sk crypto.SecretKey
+	pk types.PublicKey
+	uh types.UnlockHash
+}
+
+func newTestKey(t *testing.T) testKey {
+	sk, pk := crypto.GenerateKeyPair()
+	key := testKey{sk: sk, pk: types.Ed25519PublicKey(pk)}
+	var err error
+	key.uh, err = types.NewPubKeyUnlockHash(key.pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// recoveryCondition returns the "2-of-3 OR (owner AND after lockTime)" recovery policy.
+func recoveryCondition(guardians []testKey, owner testKey, lockTime uint64) *CompositeCondition {
+	return NewOrCondition(
+		NewThresholdCondition(2,
+			types.NewUnlockHashCondition(guardians[0].uh),
+			types.NewUnlockHashCondition(guardians[1].uh),
+			types.NewUnlockHashCondition(guardians[2].uh),
+		),
+		NewAndCondition(
+			types.NewUnlockHashCondition(owner.uh),
+			types.NewTimeLockCondition(lockTime, nil),
+		),
+	)
+}
+
+func TestCompositeConditionEncoding(t *testing.T) {
+	RegisterUnlockTypes()
+	defer UnregisterUnlockTypes()
+
+	guardians := []testKey{newTestKey(t), newTestKey(t), newTestKey(t)}
+	owner := newTestKey(t)
+	condition := types.NewCondition(recoveryCondition(guardians, owner, 1600000000))
+	if err := condition.IsStandardCondition(types.ValidationContext{}); err != nil {
+		t.Fatal(err)
+	}
+	if uh := condition.UnlockHash(); uh.Type != UnlockTypeComposite {
+		t.Fatalf("unexpected unlock hash type: %d", uh.Type)
+	}
+	// the unlock hash commits to the entire tree
+	if condition.UnlockHash() == types.NewCondition(recoveryCondition(guardians, owner, 1600000001)).UnlockHash() {
+		t.Fatal("expected conditions with a different lock time to have a different unlock hash")
+	}
+
+	b, err := json.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonCondition types.UnlockConditionProxy
+	if err = json.Unmarshal(b, &jsonCondition); err != nil {
+		t.Fatal(err)
+	}
+	b, err = siabin.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var siaCondition types.UnlockConditionProxy
+	if err = siabin.Unmarshal(b, &siaCondition); err != nil {
+		t.Fatal(err)
+	}
+	b, err = rivbin.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rivCondition types.UnlockConditionProxy
+	if err = rivbin.Unmarshal(b, &rivCondition); err != nil {
+		t.Fatal(err)
+	}
+	for _, decoded := range []types.UnlockConditionProxy{jsonCondition, siaCondition, rivCondition} {
+		if !condition.Equal(decoded) {
+			t.Errorf("%v != %v", decoded, condition)
+		}
+	}
+
+	fulfillment := types.NewFulfillment(NewCompositeFulfillment(
+		NewCompositeFulfillment(
+			types.NewSingleSignatureFulfillment(guardians[0].pk),
+			nil,
+			types.NewSingleSignatureFulfillment(guardians[2].pk),
+		),
+		nil,
+	))
+	b, err = json.Marshal(fulfillment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonFulfillment types.UnlockFulfillmentProxy
+	if err = json.Unmarshal(b, &jsonFulfillment); err != nil {
+		t.Fatal(err)
+	}
+	b, err = rivbin.Marshal(fulfillment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rivFulfillment types.UnlockFulfillmentProxy
+	if err = rivbin.Unmarshal(b, &rivFulfillment); err != nil {
+		t.Fatal(err)
+	}
+	for _, decoded := range []types.UnlockFulfillmentProxy{jsonFulfillment, rivFulfillment} {
+		if !fulfillment.Equal(decoded) {
+			t.Errorf("%v != %v", decoded, fulfillment)
+		}
+	}
+}
+
+func TestCompositeConditionIsStandard(t *testing.T) {
+	key := newTestKey(t)
+	leaf := types.NewUnlockHashCondition(key.uh)
+	nested := NewOrCondition(leaf, leaf)
+	for i := 1; i < MaxDepth; i++ {
+		nested = NewOrCondition(leaf, nested)
+	}
+	if err := nested.IsStandardCondition(types.ValidationContext{}); err != nil {
+		t.Fatalf("expected a condition of the maximum depth to be standard: %v", err)
+	}
+
+	leaves := make([]types.MarshalableUnlockCondition, MaxConditionCount+1)
+	for i := range leaves {
+		leaves[i] = leaf
+	}
+	for idx, condition := range []*CompositeCondition{
+		NewAndCondition(leaf),
+		NewOrCondition(leaves...),
+		NewThresholdCondition(0, leaf, leaf),
+		NewThresholdCondition(3, leaf, leaf),
+		{Operator: OperatorAnd, MinimumFulfilledCount: 1, Conditions: NewAndCondition(leaf, leaf).Conditions},
+		{Operator: Operator(3), Conditions: NewAndCondition(leaf, leaf).Conditions},
+		NewAndCondition(leaf, types.NewUnlockHashCondition(types.NilUnlockHash)),
+		NewOrCondition(leaf, nested),
+	} {
+		if err := condition.IsStandardCondition(types.ValidationContext{}); err == nil {
+			t.Errorf("expected condition #%d to be non-standard", idx)
+		}
+	}
+}
+
+func TestCompositeConditionFulfill(t *testing.T) {
+	guardians := []testKey{newTestKey(t), newTestKey(t), newTestKey(t)}
+	owner := newTestKey(t)
+	const lockTime = 1600000000
+	condition := recoveryCondition(guardians, owner, lockTime)
+
+	txn := types.Transaction{
+		Version: types.TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []types.CoinInput{
+			{Fulfillment: types.NewFulfillment(NewCompositeFulfillment())},
+		},
+	}
+	// sign creates a fulfillment for the condition,
+	// signing the (single signature) fulfillments at the given paths with the given keys
+	sign := func(fulfillment *CompositeFulfillment, keys map[*testKey][][]int) *CompositeFulfillment {
+		for key, paths := range keys {
+			for _, path := range paths {
+				err := fulfillment.Sign(types.FulfillmentSignContext{
+					ExtraObjects: []interface{}{uint64(0)},
+					Transaction:  txn,
+					Key:          SignKey{Path: path, Key: key.sk},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		return fulfillment
+	}
+	fulfill := func(fulfillment *CompositeFulfillment, blockTime types.Timestamp) error {
+		return condition.Fulfill(fulfillment, types.FulfillContext{
+			ExtraObjects: []interface{}{uint64(0)},
+			BlockHeight:  100,
+			BlockTime:    blockTime,
+			Transaction:  txn,
+		})
+	}
+
+	// any 2 of the 3 guardians can spend the output
+	fulfillment := sign(NewCompositeFulfillment(
+		NewCompositeFulfillment(
+			types.NewSingleSignatureFulfillment(guardians[0].pk),
+			nil,
+			types.NewSingleSignatureFulfillment(guardians[2].pk),
+		),
+		nil,
+	), map[*testKey][][]int{
+		&guardians[0]: {{0, 0}},
+		&guardians[2]: {{0, 2}},
+	})
+	if err := fulfillment.IsStandardFulfillment(types.ValidationContext{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fulfill(fulfillment, lockTime-1); err != nil {
+		t.Fatal(err)
+	}
+
+	// a single guardian cannot
+	fulfillment = sign(NewCompositeFulfillment(
+		NewCompositeFulfillment(nil, types.NewSingleSignatureFulfillment(guardians[1].pk), nil),
+		nil,
+	), map[*testKey][][]int{
+		&guardians[1]: {{0, 1}},
+	})
+	if err := fulfill(fulfillment, lockTime-1); err == nil {
+		t.Fatal("expected a single guardian not to be able to spend the output")
+	}
+
+	// the owner can only spend the output once the lock time has been reached
+	fulfillment = sign(NewCompositeFulfillment(
+		nil,
+		NewCompositeFulfillment(
+			types.NewSingleSignatureFulfillment(owner.pk),
+			types.NewSingleSignatureFulfillment(owner.pk),
+		),
+	), map[*testKey][][]int{
+		&owner: {{1, 0}, {1, 1}},
+	})
+	if err := fulfill(fulfillment, lockTime-1); err == nil {
+		t.Fatal("expected the owner not to be able to spend the output prior to the lock time")
+	}
+	if err := fulfill(fulfillment, lockTime); err != nil {
+		t.Fatal(err)
+	}
+
+	// a fulfillment signed by the wrong key invalidates the entire fulfillment,
+	// even if enough other conditions are fulfilled
+	fulfillment = sign(NewCompositeFulfillment(
+		NewCompositeFulfillment(
+			types.NewSingleSignatureFulfillment(guardians[0].pk),
+			types.NewSingleSignatureFulfillment(guardians[1].pk),
+			types.NewSingleSignatureFulfillment(guardians[2].pk),
+		),
+		nil,
+	), map[*testKey][][]int{
+		&guardians[0]: {{0, 0}, {0, 1}},
+		&guardians[2]: {{0, 2}},
+	})
+	if err := fulfill(fulfillment, lockTime); err == nil {
+		t.Fatal("expected an invalid signature to invalidate the fulfillment")
+	}
+
+	// a fulfillment has to be defined for each condition
+	if err := fulfill(NewCompositeFulfillment(nil), lockTime); err != ErrFulfillmentCountMismatch {
+		t.Fatalf("expected fulfillment count mismatch error, got: %v", err)
+	}
+	if err := condition.Fulfill(types.NewSingleSignatureFulfillment(owner.pk), types.FulfillContext{}); err != types.ErrUnexpectedUnlockFulfillment {
+		t.Fatalf("expected unexpected fulfillment error, got: %v", err)
+	}
+}
+
+func TestCompositeConditionFulfillable(t *testing.T) {
+	key := newTestKey(t)
+	leaf := types.NewUnlockHashCondition(key.uh)
+	locked := types.NewTimeLockCondition(1000, leaf)
+	testCases := []struct {
+		Condition   *CompositeCondition
+		Fulfillable bool
+	}{
+		{NewAndCondition(leaf, leaf), true},
+		{NewAndCondition(leaf, locked), false},
+		{NewOrCondition(leaf, locked), true},
+		{NewOrCondition(locked, locked), false},
+		{NewThresholdCondition(2, leaf, locked, leaf), true},
+		{NewThresholdCondition(2, locked, locked, leaf), false},
+	}
+	for idx, testCase := range testCases {
+		if fulfillable := testCase.Condition.Fulfillable(types.FulfillableContext{BlockHeight: 100}); fulfillable != testCase.Fulfillable {
+			t.Errorf("test case #%d: expected fulfillable to be %v", idx, testCase.Fulfillable)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package plugintest provides the fixtures shared by the tests of the extensions,
+// such that their consensus set plugins can be initialised, and their transactions
+// validated, applied and reverted, without a consensus set.
+package plugintest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+var bucketPlugin = []byte("plugin")
+
+// Storage is a test database containing the bucket of a single plugin.
+// It implements modules.PluginViewStorage, and is closed when the plugin is closed.
+type Storage struct {
+	db     *persist.BoltDatabase
+	plugin modules.ConsensusSetPlugin
+}
+
+// NewStorage opens a new test database, and initialises the given plugin within it.
+func NewStorage(t *testing.T, plugin modules.ConsensusSetPlugin) *Storage {
+	t.Helper()
+	testdir := build.TempDir("plugintest", t.Name())
+	err := os.MkdirAll(testdir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(testdir, "plugin.db")
+	os.Remove(dbPath)
+	db, err := persist.OpenDatabase(persist.Metadata{Header: "plugintest", Version: "1.0.0"}, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Storage{db: db, plugin: plugin}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketPlugin)
+		if err != nil {
+			return err
+		}
+		_, err = plugin.InitPlugin(nil, bucket, s, nil)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	return s
+}
+
+// View implements modules.PluginViewStorage.View
+func (s *Storage) View(callback func(bucket *bolt.Bucket) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return callback(tx.Bucket(bucketPlugin))
+	})
+}
+
+// Close implements modules.PluginViewStorage.Close,
+// closing the test database.
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// ValidateTransaction validates the given transaction, as confirmed at its block height and time,
+// using the validators the plugin maps to its transaction version.
+func (s *Storage) ValidateTransaction(txn modules.ConsensusTransaction) error {
+	constants := types.StandardnetChainConstants()
+	ctx := types.TransactionValidationContext{
+		ValidationContext: types.ValidationContext{
+			Confirmed:   true,
+			BlockHeight: txn.BlockHeight,
+			BlockTime:   txn.BlockTime,
+		},
+		BlockSizeLimit:         constants.BlockSizeLimit,
+		ArbitraryDataSizeLimit: constants.ArbitraryDataSizeLimit,
+		MinimumMinerFee:        constants.MinimumTransactionFee,
+	}
+	return s.db.View(func(tx *bolt.Tx) error {
+		bucket := persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(bucketPlugin), nil
+		})
+		for _, validate := range s.plugin.TransactionValidatorVersionFunctionMapping()[txn.Version] {
+			err := validate(txn, ctx, bucket)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ApplyTransaction applies the given transaction to the plugin bucket.
+func (s *Storage) ApplyTransaction(txn modules.ConsensusTransaction) error {
+	return s.update(func(bucket *persist.LazyBoltBucket) error {
+		return s.plugin.ApplyTransaction(txn, bucket)
+	})
+}
+
+// RevertTransaction reverts the given transaction from the plugin bucket.
+func (s *Storage) RevertTransaction(txn modules.ConsensusTransaction) error {
+	return s.update(func(bucket *persist.LazyBoltBucket) error {
+		return s.plugin.RevertTransaction(txn, bucket)
+	})
+}
+
+func (s *Storage) update(fn func(bucket *persist.LazyBoltBucket) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(bucketPlugin), nil
+		}))
+	})
+}
+
+// KeyPair is an Ed25519 key pair, owning the unlock hash (condition) of its public key.
+type KeyPair struct {
+	SecretKey crypto.SecretKey
+	PublicKey crypto.PublicKey
+}
+
+// NewKeyPair generates a new (random) key pair.
+func NewKeyPair() KeyPair {
+	sk, pk := crypto.GenerateKeyPair()
+	return KeyPair{SecretKey: sk, PublicKey: pk}
+}
+
+// UnlockHash returns the unlock hash of the public key of the key pair.
+func (kp KeyPair) UnlockHash() types.UnlockHash {
+	uh, err := types.NewEd25519PubKeyUnlockHash(kp.PublicKey)
+	if err != nil {
+		panic(err) // Ed25519 is always registered
+	}
+	return uh
+}
+
+// Condition returns the unlock hash condition owned by the key pair.
+func (kp KeyPair) Condition() types.UnlockConditionProxy {
+	return types.NewCondition(types.NewUnlockHashCondition(kp.UnlockHash()))
+}
+
+// Fulfillment returns a single signature fulfillment of the given transaction,
+// signed by the key pair, using the given extra objects.
+func (kp KeyPair) Fulfillment(t *testing.T, txn types.Transaction, extraObjects ...interface{}) types.UnlockFulfillmentProxy {
+	t.Helper()
+	fulfillment := types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(kp.PublicKey)))
+	err := fulfillment.Sign(types.FulfillmentSignContext{
+		ExtraObjects: extraObjects,
+		Transaction:  txn,
+		Key:          kp.SecretKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fulfillment
+}
+
+// SignExtension signs all fulfillments of the extension of the given transaction,
+// each using the first given key pair which owns the condition of that fulfillment.
+// Conditions owned by none of the key pairs are signed by the first key pair,
+// such that the transaction can be signed by the wrong party.
+func SignExtension(t *testing.T, txn *types.Transaction, keys ...KeyPair) {
+	t.Helper()
+	if len(keys) == 0 {
+		t.Fatal("no key pairs given to sign the transaction extension with")
+	}
+	err := txn.SignExtension(func(fulfillment *types.UnlockFulfillmentProxy, condition types.UnlockConditionProxy, extraObjects ...interface{}) error {
+		signer := keys[0]
+		for _, kp := range keys {
+			if condition.UnlockHash() == kp.UnlockHash() {
+				signer = kp
+				break
+			}
+		}
+		*fulfillment = signer.Fulfillment(t, *txn, extraObjects...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// UnlockHashCondition returns the unlock hash condition of the given (string-encoded) unlock hash.
+func UnlockHashCondition(t *testing.T, str string) types.UnlockConditionProxy {
+	t.Helper()
+	var uh types.UnlockHash
+	err := uh.LoadString(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.NewCondition(types.NewUnlockHashCondition(uh))
+}
+
+// FakeFulfillment returns a single signature fulfillment with a zero key and signature,
+// for transactions which are only used for encoding purposes.
+func FakeFulfillment() types.UnlockFulfillmentProxy {
+	return types.NewFulfillment(&types.SingleSignatureFulfillment{
+		PublicKey: types.PublicKey{
+			Algorithm: types.SignatureAlgoEd25519,
+			Key:       make(types.ByteSlice, 32),
+		},
+		Signature: make(types.ByteSlice, 64),
+	})
+}
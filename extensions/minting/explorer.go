@@ -0,0 +1,107 @@
+package minting
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// MinterDefinitionExplorerView is the explorer view on the extension data of a MinterDefinitionTransaction.
+	MinterDefinitionExplorerView struct {
+		Nonce           types.TransactionNonce       `json:"nonce"`
+		MintCondition   types.UnlockConditionProxy   `json:"mintcondition"`
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+	}
+
+	// CoinCreationExplorerView is the explorer view on the extension data of a CoinCreationTransaction.
+	CoinCreationExplorerView struct {
+		Nonce           types.TransactionNonce       `json:"nonce"`
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+		// MintedCoins is the sum of the coin outputs created by the transaction.
+		MintedCoins types.Currency `json:"mintedcoins"`
+	}
+
+	minterDefinitionExplorerExtension struct{}
+	coinCreationExplorerExtension     struct{}
+	coinDestructionExplorerExtension  struct{}
+)
+
+// registerExplorerTransactionExtensions registers the explorer extensions of the minting transaction versions.
+func registerExplorerTransactionExtensions(minterDefinitionTransactionVersion, coinCreationTransactionVersion types.TransactionVersion, coinDestructionTransactionVersion *types.TransactionVersion) {
+	modules.RegisterExplorerTransactionExtension(minterDefinitionTransactionVersion, minterDefinitionExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(coinCreationTransactionVersion, coinCreationExplorerExtension{})
+	if coinDestructionTransactionVersion != nil {
+		modules.RegisterExplorerTransactionExtension(*coinDestructionTransactionVersion, coinDestructionExplorerExtension{})
+	}
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (minterDefinitionExplorerExtension) TransactionType() string { return "minter definition" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (minterDefinitionExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	mdext, ok := txn.Extension.(*MinterDefinitionTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return MinterDefinitionExplorerView{
+		Nonce:           mdext.Nonce,
+		MintCondition:   mdext.MintCondition,
+		MintFulfillment: mdext.MintFulfillment,
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the minter which signed it, if known.
+func (minterDefinitionExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	mdext, ok := txn.Extension.(*MinterDefinitionTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return fulfillmentAddresses(mdext.MintFulfillment), nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (coinCreationExplorerExtension) TransactionType() string { return "coin creation" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (coinCreationExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	ccext, ok := txn.Extension.(*CoinCreationTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	view := CoinCreationExplorerView{
+		Nonce:           ccext.Nonce,
+		MintFulfillment: ccext.MintFulfillment,
+	}
+	for _, co := range txn.CoinOutputs {
+		view.MintedCoins = view.MintedCoins.Add(co.Value)
+	}
+	return view, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the minter which signed it, if known.
+func (coinCreationExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	ccext, ok := txn.Extension.(*CoinCreationTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return fulfillmentAddresses(ccext.MintFulfillment), nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (coinDestructionExplorerExtension) TransactionType() string { return "coin destruction" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView,
+// a CoinDestructionTransaction has no extension data.
+func (coinDestructionExplorerExtension) TransactionView(types.Transaction) (interface{}, error) {
+	return nil, nil
+}
+
+func fulfillmentAddresses(fulfillment types.UnlockFulfillmentProxy) []types.UnlockHash {
+	if uh, ok := modules.FulfillmentAddress(fulfillment); ok {
+		return []types.UnlockHash{uh}
+	}
+	return nil
+}
@@ -91,6 +91,7 @@ func NewMintingPlugin(genesisMintCondition types.UnlockConditionProxy, minterDef
 		MintConditionGetter: p,
 		TransactionVersion:  coinCreationTransactionVersion,
 	})
+	registerExplorerTransactionExtensions(minterDefinitionTransactionVersion, coinCreationTransactionVersion, p.coinDestructionTransactionVersion)
 	if legacyEncoding {
 		p.binMarshal = siabin.Marshal
 		p.binUnmarshal = siabin.Unmarshal
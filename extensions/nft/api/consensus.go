@@ -0,0 +1,12 @@
+package api
+
+import (
+	"github.com/threefoldtech/rivine/extensions/nft"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+)
+
+// RegisterConsensusNFTHTTPHandlers registers the default Rivine handlers for all default Rivine consensus NFT HTTP endpoints.
+func RegisterConsensusNFTHTTPHandlers(router rapi.Router, plugin *nft.Plugin) {
+	router.GET("/consensus/nft/tokens/:id", NewGetNFTHandler(plugin))
+	router.GET("/consensus/nft/owners/:unlockhash", NewGetNFTsOwnedByHandler(plugin))
+}
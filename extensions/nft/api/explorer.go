@@ -0,0 +1,12 @@
+package api
+
+import (
+	"github.com/threefoldtech/rivine/extensions/nft"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+)
+
+// RegisterExplorerNFTHTTPHandlers registers the default Rivine handlers for all default Rivine explorer NFT HTTP endpoints.
+func RegisterExplorerNFTHTTPHandlers(router rapi.Router, plugin *nft.Plugin) {
+	router.GET("/explorer/nft/tokens/:id", NewGetNFTHandler(plugin))
+	router.GET("/explorer/nft/owners/:unlockhash", NewGetNFTsOwnedByHandler(plugin))
+}
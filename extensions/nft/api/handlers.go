@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/extensions/nft"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// GetNFT contains a requested (unburned) NFT.
+	GetNFT struct {
+		NFT nft.NFT `json:"nft"`
+	}
+
+	// GetNFTsOwnedBy contains all (unburned) NFTs owned by a requested address.
+	GetNFTsOwnedBy struct {
+		NFTs []nft.NFT `json:"nfts"`
+	}
+)
+
+// NewGetNFTHandler creates a handler to handle the API calls to /nft/tokens/:id.
+func NewGetNFTHandler(plugin *nft.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id nft.NFTID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid NFT ID given: %v", err)}, http.StatusBadRequest)
+			return
+		}
+		token, err := plugin.GetNFT(id)
+		if err != nil {
+			if err == nft.ErrNFTNotFound {
+				rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusNoContent)
+				return
+			}
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetNFT{
+			NFT: token,
+		})
+	}
+}
+
+// NewGetNFTsOwnedByHandler creates a handler to handle the API calls to /nft/owners/:unlockhash.
+func NewGetNFTsOwnedByHandler(plugin *nft.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var uh types.UnlockHash
+		err := uh.LoadString(ps.ByName("unlockhash"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid unlock hash given: %v", err)}, http.StatusBadRequest)
+			return
+		}
+		nfts, err := plugin.GetNFTsOwnedBy(uh)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetNFTsOwnedBy{
+			NFTs: nfts,
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"fmt"
+
+	nft "github.com/threefoldtech/rivine/extensions/nft"
+	"github.com/threefoldtech/rivine/extensions/nft/api"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	client "github.com/threefoldtech/rivine/pkg/client"
+	types "github.com/threefoldtech/rivine/types"
+)
+
+// PluginClient is used to be able to get the NFTs tracked by the NFT plugin,
+// such that the CLI can sign NFT transactions,
+// without requiring access to the consensus-extended database.
+type PluginClient struct {
+	client       client.BaseClient
+	rootEndpoint string
+}
+
+// NewPluginConsensusClient creates a new PluginClient,
+// that can be used for easy interaction with the NFT API exposed via the Consensus endpoints
+func NewPluginConsensusClient(cli client.BaseClient) *PluginClient {
+	if cli == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		client:       cli,
+		rootEndpoint: "/consensus",
+	}
+}
+
+// NewPluginExplorerClient creates a new PluginClient,
+// that can be used for easy interaction with the NFT API exposed via the Explorer endpoints
+func NewPluginExplorerClient(cli client.BaseClient) *PluginClient {
+	if cli == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		client:       cli,
+		rootEndpoint: "/explorer",
+	}
+}
+
+var (
+	// ensure PluginClient implements the NFTGetter interface
+	_ nft.NFTGetter = (*PluginClient)(nil)
+)
+
+// GetNFT implements nft.NFTGetter.GetNFT
+func (cli *PluginClient) GetNFT(id nft.NFTID) (nft.NFT, error) {
+	var result api.GetNFT
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/nft/tokens/"+id.String(), &result)
+	if err != nil {
+		if err == rapi.ErrStatusNotFound {
+			return nft.NFT{}, nft.ErrNFTNotFound
+		}
+		return nft.NFT{}, fmt.Errorf(
+			"failed to get NFT %s from daemon: %v", id.String(), err)
+	}
+	return result.NFT, nil
+}
+
+// GetNFTsOwnedBy returns all (unburned) NFTs owned by the given address.
+func (cli *PluginClient) GetNFTsOwnedBy(uh types.UnlockHash) ([]nft.NFT, error) {
+	var result api.GetNFTsOwnedBy
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/nft/owners/"+uh.String(), &result)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get NFTs owned by %s from daemon: %v", uh.String(), err)
+	}
+	return result.NFTs, nil
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	nft "github.com/threefoldtech/rivine/extensions/nft"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+	types "github.com/threefoldtech/rivine/types"
+)
+
+// CreateExploreCmd adds the explorer cli subcommands for the NFT plugin
+func CreateExploreCmd(cli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(cli)
+	if err != nil {
+		return err
+	}
+	createCmd(cli.ExploreCmd, NewPluginExplorerClient(bc))
+	return nil
+}
+
+// CreateConsensusCmd adds the consensus cli subcommands for the NFT plugin
+func CreateConsensusCmd(cli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(cli)
+	if err != nil {
+		return err
+	}
+	createCmd(cli.ConsensusCmd, NewPluginConsensusClient(bc))
+	return nil
+}
+
+func createCmd(rootCmd *cobra.Command, pluginClient *PluginClient) {
+	subCmds := &subCmd{
+		pluginClient: pluginClient,
+	}
+
+	// create root nft command and all subs
+	var (
+		nftCmd = &cobra.Command{
+			Use:   "nft",
+			Short: "Get information about non-fungible tokens (NFTs)",
+		}
+		getNFTCmd = &cobra.Command{
+			Use:   "get <nftID>",
+			Short: "Get an NFT, including its metadata hash, issuer and current owner",
+			Args:  cobra.ExactArgs(1),
+			Run:   subCmds.getNFT,
+		}
+		getNFTsOwnedByCmd = &cobra.Command{
+			Use:   "owned <address>",
+			Short: "Get all NFTs owned by an address",
+			Args:  cobra.ExactArgs(1),
+			Run:   subCmds.getNFTsOwnedBy,
+		}
+	)
+
+	nftCmd.PersistentFlags().Var(
+		cli.NewEncodingTypeFlag(cli.EncodingTypeHuman, &subCmds.cfg.EncodingType, cli.EncodingTypeJSON|cli.EncodingTypeHuman), "encoding",
+		cli.EncodingTypeFlagDescription(cli.EncodingTypeJSON|cli.EncodingTypeHuman))
+
+	nftCmd.AddCommand(
+		getNFTCmd,
+		getNFTsOwnedByCmd,
+	)
+	rootCmd.AddCommand(nftCmd)
+}
+
+type subCmd struct {
+	pluginClient *PluginClient
+	cfg          struct {
+		EncodingType cli.EncodingType
+	}
+}
+
+func (subCmds *subCmd) getNFT(cmd *cobra.Command, args []string) {
+	var id nft.NFTID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid NFT ID given", err)
+	}
+	token, err := subCmds.pluginClient.GetNFT(id)
+	if err != nil {
+		cli.DieWithError("failed to get the NFT", err)
+	}
+	subCmds.encode(token)
+}
+
+func (subCmds *subCmd) getNFTsOwnedBy(cmd *cobra.Command, args []string) {
+	var uh types.UnlockHash
+	err := uh.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid address given", err)
+	}
+	nfts, err := subCmds.pluginClient.GetNFTsOwnedBy(uh)
+	if err != nil {
+		cli.DieWithError("failed to get the NFTs owned by the address", err)
+	}
+	subCmds.encode(nfts)
+}
+
+// encode depending on the encoding flag
+func (subCmds *subCmd) encode(value interface{}) {
+	var encode func(interface{}) error
+	switch subCmds.cfg.EncodingType {
+	case cli.EncodingTypeHuman:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		encode = e.Encode
+	case cli.EncodingTypeJSON:
+		encode = json.NewEncoder(os.Stdout).Encode
+	}
+	err := encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
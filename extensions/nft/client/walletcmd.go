@@ -0,0 +1,293 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/crypto"
+	nft "github.com/threefoldtech/rivine/extensions/nft"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the NFT plugin
+func CreateWalletCmds(ccli *client.CommandLineClient, mintTxVersion, transferTxVersion, burnTxVersion types.TransactionVersion) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+	walletCmd := &walletCmd{
+		cli:               ccli,
+		walletClient:      client.NewWalletClient(bc),
+		txPoolClient:      client.NewTransactionPoolClient(bc),
+		pluginClient:      NewPluginConsensusClient(bc),
+		mintTxVersion:     mintTxVersion,
+		transferTxVersion: transferTxVersion,
+		burnTxVersion:     burnTxVersion,
+	}
+
+	var (
+		createNFTMintTxCmd = &cobra.Command{
+			Use:   "nftminttransaction <issuer>|<rawCondition> <owner>|<rawCondition> <metadataHash>",
+			Short: "Create a new NFT mint transaction",
+			Long: `Create a new NFT mint transaction, minting a unique token for the given metadata hash.
+
+The issuer is recorded as the minter of the NFT, and has to sign the transaction,
+while the owner is the one who initially owns the minted NFT.
+Both can be given as a raw output condition (or address, which resolves to a singlesignature condition).
+
+The Minimum Miner Fee is funded using the coins of this wallet.
+
+The returned (raw) NFTMintTransaction still has to be signed, prior to sending.
+`,
+			Args: cobra.ExactArgs(3),
+			Run:  walletCmd.createNFTMintTxCmd,
+		}
+		sendNFTCmd = &cobra.Command{
+			Use:   "nft <nftID> <dest>|<rawCondition>",
+			Short: "Transfer an NFT owned by this wallet to an address or raw condition",
+			Args:  cobra.ExactArgs(2),
+			Run:   walletCmd.sendNFTCmd,
+		}
+		burnNFTCmd = &cobra.Command{
+			Use:   "nft <nftID>",
+			Short: "Burn an NFT owned by this wallet",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.burnNFTCmd,
+		}
+		listNFTsCmd = &cobra.Command{
+			Use:   "nfts",
+			Short: "List the NFTs owned by this wallet",
+			Args:  cobra.NoArgs,
+			Run:   walletCmd.listNFTsCmd,
+		}
+	)
+
+	// add commands as wallet sub commands
+	ccli.WalletCmd.RootCmdCreate.AddCommand(createNFTMintTxCmd)
+	ccli.WalletCmd.RootCmdSend.AddCommand(sendNFTCmd)
+	ccli.WalletCmd.AddCommand(listNFTsCmd)
+
+	// get the burn root command or create it
+	var burnRootCmd *cobra.Command
+	for _, cmd := range ccli.WalletCmd.Commands() {
+		if cmd.Name() == "burn" {
+			burnRootCmd = cmd
+			break
+		}
+	}
+	if burnRootCmd == nil {
+		burnRootCmd = &cobra.Command{
+			Use:   "burn",
+			Short: "burn resources, using an available command",
+		}
+		ccli.WalletCmd.AddCommand(burnRootCmd)
+	}
+	burnRootCmd.AddCommand(burnNFTCmd)
+
+	// set the flags
+	createNFTMintTxCmd.Flags().StringVar(
+		&walletCmd.nftMintTxCfg.MetadataURI,
+		"uri", "", "optionally define where the metadata of the NFT can be found")
+	for _, cmd := range []*cobra.Command{createNFTMintTxCmd, sendNFTCmd, burnNFTCmd} {
+		cli.ArbitraryDataFlagVar(cmd.Flags(), &walletCmd.txCfg.Description,
+			"description", "optionally add a description to the transaction, added as arbitrary data")
+		cmd.Flags().StringVar(
+			&walletCmd.txCfg.RefundAddress,
+			"refund-address", "", "define a custom refund address for the coins used to fund the miner fee")
+		cmd.Flags().BoolVar(
+			&walletCmd.txCfg.RefundAddressNew,
+			"refund-address-new", false, "generate a new refund address if a coin refund needs to happen")
+	}
+
+	return nil
+}
+
+type walletCmd struct {
+	cli          *client.CommandLineClient
+	walletClient *client.WalletClient
+	txPoolClient *client.TransactionPoolClient
+	pluginClient *PluginClient
+
+	mintTxVersion, transferTxVersion, burnTxVersion types.TransactionVersion
+
+	nftMintTxCfg struct {
+		MetadataURI string
+	}
+	txCfg struct {
+		Description      []byte
+		RefundAddress    string
+		RefundAddressNew bool
+	}
+}
+
+func (walletCmd *walletCmd) createNFTMintTxCmd(cmd *cobra.Command, args []string) {
+	issuer, err := parseConditionString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid issuer", err)
+	}
+	owner, err := parseConditionString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid owner", err)
+	}
+	var metadataHash crypto.Hash
+	err = metadataHash.LoadString(args[2])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid metadata hash", err)
+	}
+
+	tx := nft.NFTMintTransaction{
+		Nonce:        types.RandomTransactionNonce(),
+		MetadataHash: metadataHash,
+		MetadataURI:  walletCmd.nftMintTxCfg.MetadataURI,
+		Issuer:       issuer,
+		Owner:        owner,
+		MinerFees:    []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	tx.CoinInputs, tx.CoinOutputs, tx.ArbitraryData = walletCmd.fundMinerFee()
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction(walletCmd.mintTxVersion))
+	if err != nil {
+		cli.DieWithError("failed to encode NFT mint transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) sendNFTCmd(cmd *cobra.Command, args []string) {
+	var id nft.NFTID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid NFT ID", err)
+	}
+	newOwner, err := parseConditionString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+
+	tx := nft.NFTTransferTransaction{
+		NFTID:     id,
+		NewOwner:  newOwner,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	tx.CoinInputs, tx.CoinOutputs, tx.ArbitraryData = walletCmd.fundMinerFee()
+
+	txID := walletCmd.signAndSend(tx.Transaction(walletCmd.transferTxVersion), "NFT transfer")
+	fmt.Println(txID.String())
+}
+
+func (walletCmd *walletCmd) burnNFTCmd(cmd *cobra.Command, args []string) {
+	var id nft.NFTID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid NFT ID", err)
+	}
+
+	tx := nft.NFTBurnTransaction{
+		NFTID:     id,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	tx.CoinInputs, tx.CoinOutputs, tx.ArbitraryData = walletCmd.fundMinerFee()
+
+	txID := walletCmd.signAndSend(tx.Transaction(walletCmd.burnTxVersion), "NFT burn")
+	fmt.Println(txID.String())
+}
+
+func (walletCmd *walletCmd) listNFTsCmd(*cobra.Command, []string) {
+	var addrs api.WalletAddressesGET
+	err := walletCmd.cli.GetWithResponse("/wallet/addresses", &addrs)
+	if err != nil {
+		cli.DieWithError("failed to fetch wallet addresses", err)
+	}
+	var nfts []nft.NFT
+	for _, addr := range addrs.Addresses {
+		owned, err := walletCmd.pluginClient.GetNFTsOwnedBy(addr)
+		if err != nil {
+			cli.DieWithError("failed to get the NFTs owned by this wallet", err)
+		}
+		nfts = append(nfts, owned...)
+	}
+	if len(nfts) == 0 {
+		fmt.Println("This wallet does not own any NFTs.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NFT\tMETADATA HASH\tURI")
+	for _, token := range nfts {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", token.ID.String(), token.MetadataHash.String(), token.MetadataURI)
+	}
+	w.Flush()
+}
+
+// fundMinerFee funds the minimum miner fee using the coins of this wallet,
+// returning the coin inputs, the optional refund coin output and the optional arbitrary data.
+func (walletCmd *walletCmd) fundMinerFee() ([]types.CoinInput, []types.CoinOutput, []byte) {
+	var refundAddress *types.UnlockHash
+	if walletCmd.txCfg.RefundAddress != "" {
+		refundAddress = new(types.UnlockHash)
+		err := refundAddress.LoadString(walletCmd.txCfg.RefundAddress)
+		if err != nil {
+			cli.DieWithError("invalid refund address", err)
+		}
+	}
+	coinInputs, refundCoinOutput, err := walletCmd.walletClient.FundCoins(
+		walletCmd.cli.Config.MinimumTransactionFee, refundAddress, walletCmd.txCfg.RefundAddressNew)
+	if err != nil {
+		cli.DieWithError("failed to fund the miner fee of the transaction", err)
+	}
+	var coinOutputs []types.CoinOutput
+	if refundCoinOutput != nil {
+		coinOutputs = append(coinOutputs, *refundCoinOutput)
+	}
+	var arbitraryData []byte
+	if n := len(walletCmd.txCfg.Description); n > 0 {
+		arbitraryData = make([]byte, n)
+		copy(arbitraryData[:], walletCmd.txCfg.Description[:])
+	}
+	return coinInputs, coinOutputs, arbitraryData
+}
+
+// signAndSend signs the transaction using the wallet and sends it to the transaction pool.
+func (walletCmd *walletCmd) signAndSend(tx types.Transaction, name string) types.TransactionID {
+	err := walletCmd.walletClient.GreedySignTx(&tx)
+	if err != nil {
+		cli.DieWithError(fmt.Sprintf("failed to sign %s transaction", name), err)
+	}
+	txID, err := walletCmd.txPoolClient.AddTransactiom(tx)
+	if err != nil {
+		cli.DieWithError(fmt.Sprintf("failed to send %s transaction", name), err)
+	}
+	return txID
+}
+
+// try to parse the string first as an unlock hash,
+// if that fails parse it as a JSON-encoded unlock condition
+func parseConditionString(str string) (condition types.UnlockConditionProxy, err error) {
+	// try to parse it as an unlock hash
+	var uh types.UnlockHash
+	err = uh.LoadString(str)
+	if err == nil {
+		condition = types.NewCondition(types.NewUnlockHashCondition(uh))
+		return
+	}
+
+	// try to parse it as a JSON-encoded unlock condition
+	err = condition.UnmarshalJSON([]byte(str))
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf(
+			"condition has to be UnlockHash or JSON-encoded UnlockCondition, output %q is neither", str)
+	}
+	return
+}
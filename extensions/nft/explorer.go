@@ -0,0 +1,115 @@
+package nft
+
+import (
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// NFTMintExplorerView is the explorer view on the extension data of a NFTMintTransaction.
+	NFTMintExplorerView struct {
+		NFTID             NFTID                        `json:"nftid"`
+		Nonce             types.TransactionNonce       `json:"nonce"`
+		MetadataHash      crypto.Hash                  `json:"metadatahash"`
+		MetadataURI       string                       `json:"metadatauri,omitempty"`
+		Issuer            types.UnlockConditionProxy   `json:"issuer"`
+		IssuerFulfillment types.UnlockFulfillmentProxy `json:"issuerfulfillment"`
+		Owner             types.UnlockConditionProxy   `json:"owner"`
+	}
+
+	// NFTTransferExplorerView is the explorer view on the extension data of a NFTTransferTransaction.
+	NFTTransferExplorerView struct {
+		NFTID            NFTID                        `json:"nftid"`
+		OwnerFulfillment types.UnlockFulfillmentProxy `json:"ownerfulfillment"`
+		NewOwner         types.UnlockConditionProxy   `json:"newowner"`
+	}
+
+	// NFTBurnExplorerView is the explorer view on the extension data of a NFTBurnTransaction.
+	NFTBurnExplorerView struct {
+		NFTID            NFTID                        `json:"nftid"`
+		OwnerFulfillment types.UnlockFulfillmentProxy `json:"ownerfulfillment"`
+	}
+
+	nftMintExplorerExtension     struct{}
+	nftTransferExplorerExtension struct{}
+	nftBurnExplorerExtension     struct{}
+)
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (nftMintExplorerExtension) TransactionType() string { return "nft mint" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (nftMintExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	nmext, ok := txn.Extension.(*NFTMintTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return NFTMintExplorerView{
+		NFTID:             NewNFTID(nmext.Nonce, nmext.Issuer, nmext.MetadataHash),
+		Nonce:             nmext.Nonce,
+		MetadataHash:      nmext.MetadataHash,
+		MetadataURI:       nmext.MetadataURI,
+		Issuer:            nmext.Issuer,
+		IssuerFulfillment: nmext.IssuerFulfillment,
+		Owner:             nmext.Owner,
+	}, nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (nftTransferExplorerExtension) TransactionType() string { return "nft transfer" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (nftTransferExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	ntext, ok := txn.Extension.(*NFTTransferTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return NFTTransferExplorerView{
+		NFTID:            ntext.NFTID,
+		OwnerFulfillment: ntext.OwnerFulfillment,
+		NewOwner:         ntext.NewOwner,
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the previous owner of the NFT, if known.
+func (nftTransferExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	ntext, ok := txn.Extension.(*NFTTransferTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return fulfillmentAddresses(ntext.OwnerFulfillment), nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (nftBurnExplorerExtension) TransactionType() string { return "nft burn" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (nftBurnExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	nbext, ok := txn.Extension.(*NFTBurnTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return NFTBurnExplorerView{
+		NFTID:            nbext.NFTID,
+		OwnerFulfillment: nbext.OwnerFulfillment,
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the owner of the burned NFT, if known.
+func (nftBurnExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	nbext, ok := txn.Extension.(*NFTBurnTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return fulfillmentAddresses(nbext.OwnerFulfillment), nil
+}
+
+func fulfillmentAddresses(fulfillment types.UnlockFulfillmentProxy) []types.UnlockHash {
+	if uh, ok := modules.FulfillmentAddress(fulfillment); ok {
+		return []types.UnlockHash{uh}
+	}
+	return nil
+}
@@ -0,0 +1,627 @@
+package nft
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+const (
+	pluginDBVersion = "1.0.0.0"
+	pluginDBHeader  = "nftPlugin"
+)
+
+var (
+	bucketNFTs           = []byte("nfts")
+	bucketBurnedNFTs     = []byte("burnednfts")
+	bucketPreviousOwners = []byte("previousowners")
+	bucketOwners         = []byte("owners")
+)
+
+type (
+	// Plugin is a struct defines the NFT plugin,
+	// tracking all NFTs and their current owners.
+	Plugin struct {
+		mintTransactionVersion     types.TransactionVersion
+		transferTransactionVersion types.TransactionVersion
+		burnTransactionVersion     types.TransactionVersion
+		storage                    modules.PluginViewStorage
+		unregisterCallback         modules.PluginUnregisterCallback
+	}
+
+	// previousOwner is the owner of an NFT prior to a transfer, together with the block
+	// in which it became the owner, stored such that it can be restored when reverting the transfer.
+	previousOwner struct {
+		Owner       types.UnlockConditionProxy
+		OwnerHeight types.BlockHeight
+		OwnerTime   types.Timestamp
+	}
+)
+
+// NewPlugin creates a new Plugin and registers the NFT transaction versions.
+func NewPlugin(mintTransactionVersion, transferTransactionVersion, burnTransactionVersion types.TransactionVersion) *Plugin {
+	p := &Plugin{
+		mintTransactionVersion:     mintTransactionVersion,
+		transferTransactionVersion: transferTransactionVersion,
+		burnTransactionVersion:     burnTransactionVersion,
+	}
+	types.RegisterTransactionVersion(mintTransactionVersion, NFTMintTransactionController{
+		TransactionVersion: mintTransactionVersion,
+	})
+	types.RegisterTransactionVersion(transferTransactionVersion, NFTTransferTransactionController{
+		NFTGetter:          p,
+		TransactionVersion: transferTransactionVersion,
+	})
+	types.RegisterTransactionVersion(burnTransactionVersion, NFTBurnTransactionController{
+		NFTGetter:          p,
+		TransactionVersion: burnTransactionVersion,
+	})
+	modules.RegisterExplorerTransactionExtension(mintTransactionVersion, nftMintExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(transferTransactionVersion, nftTransferExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(burnTransactionVersion, nftBurnExplorerExtension{})
+	return p
+}
+
+// InitPlugin initializes the Bucket for the first time
+func (p *Plugin) InitPlugin(metadata *persist.Metadata, bucket *bolt.Bucket, storage modules.PluginViewStorage, unregisterCallback modules.PluginUnregisterCallback) (persist.Metadata, error) {
+	p.storage = storage
+	p.unregisterCallback = unregisterCallback
+	if metadata == nil {
+		for _, name := range [][]byte{bucketNFTs, bucketBurnedNFTs, bucketPreviousOwners, bucketOwners} {
+			_, err := bucket.CreateBucketIfNotExists(name)
+			if err != nil {
+				return persist.Metadata{}, fmt.Errorf("failed to create %s bucket: %v", string(name), err)
+			}
+		}
+		metadata = &persist.Metadata{
+			Version: pluginDBVersion,
+			Header:  pluginDBHeader,
+		}
+	} else if metadata.Version != pluginDBVersion {
+		return persist.Metadata{}, errors.New("There is only 1 version of this plugin, version mismatch")
+	} else if metadata.Header != pluginDBHeader {
+		return persist.Metadata{}, errors.New("There is only 1 header of this plugin, header mismatch")
+	}
+	return *metadata, nil
+}
+
+// ApplyBlock applies a block's NFT transactions to the NFT buckets.
+func (p *Plugin) ApplyBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("NFT bucket does not exist")
+	}
+	var err error
+	for idx, txn := range block.Transactions {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            txn,
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.ApplyTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyBlockHeader applies nothing and has no effect on this plugin.
+func (p *Plugin) ApplyBlockHeader(modules.ConsensusBlockHeader, *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// ApplyTransaction applies a NFT transaction to the NFT buckets.
+func (p *Plugin) ApplyTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("NFT bucket does not exist")
+	}
+	if !p.isNFTTransactionVersion(txn.Version) {
+		return nil // not a transaction we care about
+	}
+	nftsBucket, err := bucket.Bucket(bucketNFTs)
+	if err != nil {
+		return err
+	}
+	ownersBucket, err := bucket.Bucket(bucketOwners)
+	if err != nil {
+		return err
+	}
+	// check the version and handle the ones we care about
+	switch txn.Version {
+	case p.mintTransactionVersion:
+		nmtx, err := NFTMintTransactionFromTransaction(txn.Transaction, p.mintTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the NFT mint tx type: %v", err)
+		}
+		nft := NFT{
+			ID:                    nmtx.NFTID(),
+			MetadataHash:          nmtx.MetadataHash,
+			MetadataURI:           nmtx.MetadataURI,
+			Issuer:                nmtx.Issuer,
+			Owner:                 nmtx.Owner,
+			CreationHeight:        txn.BlockHeight,
+			CreationTransactionID: txn.ID(),
+			OwnerHeight:           txn.BlockHeight,
+			OwnerTime:             txn.BlockTime,
+		}
+		err = putNFTInBucket(nftsBucket, nft)
+		if err != nil {
+			return err
+		}
+		return mapNFTOwner(ownersBucket, nft)
+
+	case p.transferTransactionVersion:
+		nttx, err := NFTTransferTransactionFromTransaction(txn.Transaction, p.transferTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the NFT transfer tx type: %v", err)
+		}
+		previousOwnersBucket, err := bucket.Bucket(bucketPreviousOwners)
+		if err != nil {
+			return err
+		}
+		nft, err := getNFTFromBucket(nftsBucket, nttx.NFTID)
+		if err != nil {
+			return fmt.Errorf("failed to transfer NFT %s: %v", nttx.NFTID.String(), err)
+		}
+		// store the previous owner, such that it can be restored when reverting
+		txID := txn.ID()
+		b, err := rivbin.Marshal(previousOwner{
+			Owner:       nft.Owner,
+			OwnerHeight: nft.OwnerHeight,
+			OwnerTime:   nft.OwnerTime,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to (rivbin) marshal previous owner of NFT %s: %v", nft.ID.String(), err)
+		}
+		err = previousOwnersBucket.Put(txID[:], b)
+		if err != nil {
+			return fmt.Errorf("failed to store previous owner of NFT %s: %v", nft.ID.String(), err)
+		}
+		err = unmapNFTOwner(ownersBucket, nft)
+		if err != nil {
+			return err
+		}
+		nft.Owner = nttx.NewOwner
+		nft.OwnerHeight = txn.BlockHeight
+		nft.OwnerTime = txn.BlockTime
+		err = putNFTInBucket(nftsBucket, nft)
+		if err != nil {
+			return err
+		}
+		return mapNFTOwner(ownersBucket, nft)
+
+	case p.burnTransactionVersion:
+		nbtx, err := NFTBurnTransactionFromTransaction(txn.Transaction, p.burnTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the NFT burn tx type: %v", err)
+		}
+		burnedNFTsBucket, err := bucket.Bucket(bucketBurnedNFTs)
+		if err != nil {
+			return err
+		}
+		nft, err := getNFTFromBucket(nftsBucket, nbtx.NFTID)
+		if err != nil {
+			return fmt.Errorf("failed to burn NFT %s: %v", nbtx.NFTID.String(), err)
+		}
+		// move the NFT to the burned NFTs, such that it can be restored when reverting,
+		// and such that it can never be minted again
+		err = nftsBucket.Delete(nft.ID[:])
+		if err != nil {
+			return fmt.Errorf("failed to delete burned NFT %s: %v", nft.ID.String(), err)
+		}
+		err = putNFTInBucket(burnedNFTsBucket, nft)
+		if err != nil {
+			return err
+		}
+		return unmapNFTOwner(ownersBucket, nft)
+	}
+	return nil
+}
+
+// RevertBlock reverts a block's NFT transactions from the NFT buckets
+func (p *Plugin) RevertBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("NFT bucket does not exist")
+	}
+	// revert the transactions in reverse order,
+	// as a transaction can transfer or burn an NFT minted or transferred by a previous transaction in the same block
+	var err error
+	for idx := len(block.Transactions) - 1; idx >= 0; idx-- {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            block.Transactions[idx],
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.RevertTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlockHeader reverts nothing and has no effect on this plugin.
+func (p *Plugin) RevertBlockHeader(modules.ConsensusBlockHeader, *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// RevertTransaction reverts a NFT transaction from the NFT buckets.
+func (p *Plugin) RevertTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("NFT bucket does not exist")
+	}
+	if !p.isNFTTransactionVersion(txn.Version) {
+		return nil // not a transaction we care about
+	}
+	nftsBucket, err := bucket.Bucket(bucketNFTs)
+	if err != nil {
+		return err
+	}
+	ownersBucket, err := bucket.Bucket(bucketOwners)
+	if err != nil {
+		return err
+	}
+	// check the version and handle the ones we care about
+	switch txn.Version {
+	case p.mintTransactionVersion:
+		nmtx, err := NFTMintTransactionFromTransaction(txn.Transaction, p.mintTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the NFT mint tx type: %v", err)
+		}
+		nft, err := getNFTFromBucket(nftsBucket, nmtx.NFTID())
+		if err != nil {
+			return fmt.Errorf("failed to revert minted NFT %s: %v", nmtx.NFTID().String(), err)
+		}
+		err = nftsBucket.Delete(nft.ID[:])
+		if err != nil {
+			return fmt.Errorf("failed to delete minted NFT %s: %v", nft.ID.String(), err)
+		}
+		return unmapNFTOwner(ownersBucket, nft)
+
+	case p.transferTransactionVersion:
+		nttx, err := NFTTransferTransactionFromTransaction(txn.Transaction, p.transferTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the NFT transfer tx type: %v", err)
+		}
+		previousOwnersBucket, err := bucket.Bucket(bucketPreviousOwners)
+		if err != nil {
+			return err
+		}
+		nft, err := getNFTFromBucket(nftsBucket, nttx.NFTID)
+		if err != nil {
+			return fmt.Errorf("failed to revert transferred NFT %s: %v", nttx.NFTID.String(), err)
+		}
+		txID := txn.ID()
+		b := previousOwnersBucket.Get(txID[:])
+		if len(b) == 0 {
+			return fmt.Errorf("failed to find previous owner of NFT %s", nft.ID.String())
+		}
+		var previous previousOwner
+		err = rivbin.Unmarshal(b, &previous)
+		if err != nil {
+			return fmt.Errorf("failed to decode previous owner of NFT %s: %v", nft.ID.String(), err)
+		}
+		err = previousOwnersBucket.Delete(txID[:])
+		if err != nil {
+			return fmt.Errorf("failed to delete previous owner of NFT %s: %v", nft.ID.String(), err)
+		}
+		err = unmapNFTOwner(ownersBucket, nft)
+		if err != nil {
+			return err
+		}
+		nft.Owner = previous.Owner
+		nft.OwnerHeight = previous.OwnerHeight
+		nft.OwnerTime = previous.OwnerTime
+		err = putNFTInBucket(nftsBucket, nft)
+		if err != nil {
+			return err
+		}
+		return mapNFTOwner(ownersBucket, nft)
+
+	case p.burnTransactionVersion:
+		nbtx, err := NFTBurnTransactionFromTransaction(txn.Transaction, p.burnTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the NFT burn tx type: %v", err)
+		}
+		burnedNFTsBucket, err := bucket.Bucket(bucketBurnedNFTs)
+		if err != nil {
+			return err
+		}
+		nft, err := getNFTFromBucket(burnedNFTsBucket, nbtx.NFTID)
+		if err != nil {
+			return fmt.Errorf("failed to restore burned NFT %s: %v", nbtx.NFTID.String(), err)
+		}
+		err = burnedNFTsBucket.Delete(nft.ID[:])
+		if err != nil {
+			return fmt.Errorf("failed to delete burned NFT %s: %v", nft.ID.String(), err)
+		}
+		err = putNFTInBucket(nftsBucket, nft)
+		if err != nil {
+			return err
+		}
+		return mapNFTOwner(ownersBucket, nft)
+	}
+	return nil
+}
+
+func (p *Plugin) isNFTTransactionVersion(version types.TransactionVersion) bool {
+	return version == p.mintTransactionVersion ||
+		version == p.transferTransactionVersion ||
+		version == p.burnTransactionVersion
+}
+
+// GetNFT implements NFTGetter.GetNFT
+func (p *Plugin) GetNFT(id NFTID) (NFT, error) {
+	var nft NFT
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		nftsBucket := bucket.Bucket(bucketNFTs)
+		if nftsBucket == nil {
+			return errors.New("NFTs bucket does not exist")
+		}
+		var err error
+		nft, err = getNFTFromBucket(nftsBucket, id)
+		return err
+	})
+	return nft, err
+}
+
+// GetNFTsOwnedBy returns all (unburned) NFTs owned by the given unlock hash.
+func (p *Plugin) GetNFTsOwnedBy(uh types.UnlockHash) ([]NFT, error) {
+	var nfts []NFT
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		nftsBucket := bucket.Bucket(bucketNFTs)
+		if nftsBucket == nil {
+			return errors.New("NFTs bucket does not exist")
+		}
+		ownersBucket := bucket.Bucket(bucketOwners)
+		if ownersBucket == nil {
+			return errors.New("owners bucket does not exist")
+		}
+		key, err := rivbin.Marshal(uh)
+		if err != nil {
+			return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+		}
+		ownerBucket := ownersBucket.Bucket(key)
+		if ownerBucket == nil {
+			return nil // no NFTs owned by this address
+		}
+		return ownerBucket.ForEach(func(k, _ []byte) error {
+			var id NFTID
+			copy(id[:], k)
+			nft, err := getNFTFromBucket(nftsBucket, id)
+			if err != nil {
+				return err
+			}
+			nfts = append(nfts, nft)
+			return nil
+		})
+	})
+	return nfts, err
+}
+
+// TransactionValidatorVersionFunctionMapping returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidatorVersionFunctionMapping() map[types.TransactionVersion][]modules.PluginTransactionValidationFunction {
+	return map[types.TransactionVersion][]modules.PluginTransactionValidationFunction{
+		p.mintTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateNFTMintTx,
+		},
+		p.transferTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateNFTTransferTx,
+		},
+		p.burnTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateNFTBurnTx,
+		},
+	}
+}
+
+// TransactionValidators returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidators() []modules.PluginTransactionValidationFunction {
+	return nil
+}
+
+func (p *Plugin) validateNFTMintTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	nmtx, err := NFTMintTransactionFromTransaction(tx.Transaction, p.mintTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a NFT mint tx: %v", err)
+	}
+
+	// ensure the Nonce is not Nil
+	if nmtx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for a NFT mint transaction")
+	}
+	// ensure the metadata is defined
+	if nmtx.MetadataHash == (crypto.Hash{}) {
+		return errors.New("nil metadata hash is not allowed for a NFT mint transaction")
+	}
+	if len(nmtx.MetadataURI) > MaxMetadataURILength {
+		return fmt.Errorf("metadata URI is too long: %d > %d", len(nmtx.MetadataURI), MaxMetadataURILength)
+	}
+	err = validateOwnerCondition(nmtx.Issuer, ctx.ValidationContext)
+	if err != nil {
+		return fmt.Errorf("invalid issuer: %v", err)
+	}
+	err = validateOwnerCondition(nmtx.Owner, ctx.ValidationContext)
+	if err != nil {
+		return fmt.Errorf("invalid owner: %v", err)
+	}
+
+	// ensure the NFT is unique, even if it was burned already
+	id := nmtx.NFTID()
+	for _, name := range [][]byte{bucketNFTs, bucketBurnedNFTs} {
+		b, err := bucket.Bucket(name)
+		if err != nil {
+			return err
+		}
+		if b.Get(id[:]) != nil {
+			return fmt.Errorf("NFT %s was already minted", id.String())
+		}
+	}
+
+	// check if IssuerFulfillment fulfills the issuer condition
+	// the issuer condition is defined by this transaction
+	err = nmtx.Issuer.Fulfill(nmtx.IssuerFulfillment, types.FulfillContext{
+		BlockHeight:          ctx.BlockHeight,
+		BlockTime:            ctx.BlockTime,
+		OutputCreationHeight: ctx.BlockHeight,
+		OutputCreationTime:   ctx.BlockTime,
+		HasOutputCreation:    true,
+		Transaction:          tx.Transaction,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill issuer condition for NFT mint transaction: %v", err)
+	}
+
+	return validateCoinOutputsAreBalanced(tx, ctx)
+}
+
+func (p *Plugin) validateNFTTransferTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	nttx, err := NFTTransferTransactionFromTransaction(tx.Transaction, p.transferTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a NFT transfer tx: %v", err)
+	}
+	err = validateOwnerCondition(nttx.NewOwner, ctx.ValidationContext)
+	if err != nil {
+		return fmt.Errorf("invalid new owner: %v", err)
+	}
+	err = p.validateOwnerFulfillment(nttx.NFTID, nttx.OwnerFulfillment, tx, ctx, bucket)
+	if err != nil {
+		return err
+	}
+	return validateCoinOutputsAreBalanced(tx, ctx)
+}
+
+func (p *Plugin) validateNFTBurnTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	nbtx, err := NFTBurnTransactionFromTransaction(tx.Transaction, p.burnTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a NFT burn tx: %v", err)
+	}
+	err = p.validateOwnerFulfillment(nbtx.NFTID, nbtx.OwnerFulfillment, tx, ctx, bucket)
+	if err != nil {
+		return err
+	}
+	return validateCoinOutputsAreBalanced(tx, ctx)
+}
+
+// validateOwnerFulfillment ensures the NFT exists and the fulfillment fulfills its current owner condition.
+func (p *Plugin) validateOwnerFulfillment(id NFTID, fulfillment types.UnlockFulfillmentProxy, tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	nftsBucket, err := bucket.Bucket(bucketNFTs)
+	if err != nil {
+		return err
+	}
+	nft, err := getNFTFromBucket(nftsBucket, id)
+	if err != nil {
+		return fmt.Errorf("NFT %s: %v", id.String(), err)
+	}
+	err = nft.Owner.Fulfill(fulfillment, types.FulfillContext{
+		BlockHeight:          ctx.BlockHeight,
+		BlockTime:            ctx.BlockTime,
+		OutputCreationHeight: nft.OwnerHeight,
+		OutputCreationTime:   nft.OwnerTime,
+		HasOutputCreation:    true,
+		Transaction:          tx.Transaction,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill owner condition of NFT %s: %v", id.String(), err)
+	}
+	return nil
+}
+
+// validateCoinOutputsAreBalanced ensures that the coin inputs
+// fund exactly the coin outputs and miner fees of the transaction.
+func validateCoinOutputsAreBalanced(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+	var coinInputSum types.Currency
+	for _, ci := range tx.CoinInputs {
+		co, ok := tx.SpentCoinOutputs[ci.ParentID]
+		if !ok {
+			return fmt.Errorf(
+				"unable to find parent ID %s as an unspent coin output in the current consensus transaction at block height %d",
+				ci.ParentID.String(), ctx.BlockHeight)
+		}
+		coinInputSum = coinInputSum.Add(co.Value)
+	}
+	if coinOutputSum := tx.CoinOutputSum(); !coinInputSum.Equals(coinOutputSum) {
+		return fmt.Errorf(
+			"coin inputs (%s) and coin outputs including miner fees (%s) are not balanced for tx %s",
+			coinInputSum.String(), coinOutputSum.String(), tx.ID().String())
+	}
+	return nil
+}
+
+// Close unregisters the plugin from the consensus
+func (p *Plugin) Close() error {
+	if p.storage == nil {
+		return nil
+	}
+	return p.storage.Close()
+}
+
+func getNFTFromBucket(bucket *bolt.Bucket, id NFTID) (NFT, error) {
+	b := bucket.Get(id[:])
+	if len(b) == 0 {
+		return NFT{}, ErrNFTNotFound
+	}
+	var nft NFT
+	err := rivbin.Unmarshal(b, &nft)
+	if err != nil {
+		return NFT{}, fmt.Errorf("failed to decode NFT %s: %v", id.String(), err)
+	}
+	return nft, nil
+}
+
+func putNFTInBucket(bucket *bolt.Bucket, nft NFT) error {
+	b, err := rivbin.Marshal(nft)
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal NFT %s: %v", nft.ID.String(), err)
+	}
+	err = bucket.Put(nft.ID[:], b)
+	if err != nil {
+		return fmt.Errorf("failed to put NFT %s: %v", nft.ID.String(), err)
+	}
+	return nil
+}
+
+// mapNFTOwner links the NFT to the unlock hash of its owner condition.
+func mapNFTOwner(ownersBucket *bolt.Bucket, nft NFT) error {
+	key, err := rivbin.Marshal(nft.Owner.UnlockHash())
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+	}
+	ownerBucket, err := ownersBucket.CreateBucketIfNotExists(key)
+	if err != nil {
+		return fmt.Errorf("failed to create owner bucket: %v", err)
+	}
+	return ownerBucket.Put(nft.ID[:], []byte{})
+}
+
+// unmapNFTOwner removes the link between the NFT and the unlock hash of its owner condition.
+func unmapNFTOwner(ownersBucket *bolt.Bucket, nft NFT) error {
+	key, err := rivbin.Marshal(nft.Owner.UnlockHash())
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+	}
+	ownerBucket := ownersBucket.Bucket(key)
+	if ownerBucket == nil {
+		return nil
+	}
+	err = ownerBucket.Delete(nft.ID[:])
+	if err != nil {
+		return err
+	}
+	if k, _ := ownerBucket.Cursor().First(); k == nil {
+		return ownersBucket.DeleteBucket(key)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package nft
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/internal/plugintest"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// newTestPlugin creates a NFT plugin, using the test transaction versions,
+// initialised in a test database. The returned function closes the plugin,
+// and unregisters its transaction versions.
+func newTestPlugin(t *testing.T) (*Plugin, *plugintest.Storage, func()) {
+	plugin := NewPlugin(testMintTxVersion, testTransferTxVersion, testBurnTxVersion)
+	storage := plugintest.NewStorage(t, plugin)
+	return plugin, storage, func() {
+		plugin.Close()
+		for _, version := range []types.TransactionVersion{testMintTxVersion, testTransferTxVersion, testBurnTxVersion} {
+			types.RegisterTransactionVersion(version, nil)
+			modules.RegisterExplorerTransactionExtension(version, nil)
+		}
+	}
+}
+
+// testConsensusTx funds the miner fee of the given NFT transaction using a single coin input,
+// signs it using the given key pairs (if any), and returns it as confirmed at the given height.
+func testConsensusTx(t *testing.T, txn types.Transaction, height types.BlockHeight, signers ...plugintest.KeyPair) modules.ConsensusTransaction {
+	parentID := types.CoinOutputID{byte(height)}
+	fee := types.NewCurrency64(1)
+	txn.CoinInputs = []types.CoinInput{{ParentID: parentID}}
+	txn.MinerFees = []types.Currency{fee}
+	if len(signers) != 0 {
+		plugintest.SignExtension(t, &txn, signers...)
+	}
+	return modules.ConsensusTransaction{
+		Transaction: txn,
+		BlockHeight: height,
+		BlockTime:   types.Timestamp(height) * 600,
+		SpentCoinOutputs: map[types.CoinOutputID]types.CoinOutput{
+			parentID: {Value: fee},
+		},
+	}
+}
+
+func TestPluginNFTLifecycle(t *testing.T) {
+	plugin, storage, closePlugin := newTestPlugin(t)
+	defer closePlugin()
+
+	issuer, alice, bob := plugintest.NewKeyPair(), plugintest.NewKeyPair(), plugintest.NewKeyPair()
+	mint := NFTMintTransaction{
+		Nonce:        types.TransactionNonce{1, 2, 3, 4, 5, 6, 7, 8},
+		MetadataHash: crypto.HashBytes([]byte("certificate")),
+		MetadataURI:  "https://example.org/certificates/1",
+		Issuer:       issuer.Condition(),
+		Owner:        alice.Condition(),
+	}
+	id := mint.NFTID()
+	transferTo := func(owner plugintest.KeyPair) types.Transaction {
+		return (&NFTTransferTransaction{NFTID: id, NewOwner: owner.Condition()}).Transaction(testTransferTxVersion)
+	}
+	burn := (&NFTBurnTransaction{NFTID: id}).Transaction(testBurnTxVersion)
+
+	// only the issuer can mint the NFT, and the NFT has to exist in order to be transferred or burned
+	err := storage.ValidateTransaction(testConsensusTx(t, mint.Transaction(testMintTxVersion), 1, alice))
+	if err == nil {
+		t.Error("expected a NFT minted by the wrong party to be rejected")
+	}
+	unknownNFT := NFTTransferTransaction{NFTID: id, OwnerFulfillment: plugintest.FakeFulfillment(), NewOwner: bob.Condition()}
+	err = storage.ValidateTransaction(testConsensusTx(t, unknownNFT.Transaction(testTransferTxVersion), 1))
+	if err == nil {
+		t.Error("expected the transfer of an unknown NFT to be rejected")
+	}
+
+	minted := testConsensusTx(t, mint.Transaction(testMintTxVersion), 1, issuer)
+	err = storage.ValidateTransaction(minted)
+	if err != nil {
+		t.Fatalf("failed to validate the mint of a new NFT: %v", err)
+	}
+	err = storage.ApplyTransaction(minted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNFTOwner(t, plugin, id, alice)
+
+	// a NFT can only be minted once
+	err = storage.ValidateTransaction(testConsensusTx(t, mint.Transaction(testMintTxVersion), 2, issuer))
+	if err == nil {
+		t.Error("expected a NFT to be minted only once")
+	}
+	// only the owner can transfer or burn the NFT, not even its issuer
+	for _, signer := range []plugintest.KeyPair{issuer, bob} {
+		err = storage.ValidateTransaction(testConsensusTx(t, transferTo(bob), 2, signer))
+		if err == nil {
+			t.Error("expected a NFT transferred by the wrong party to be rejected")
+		}
+		err = storage.ValidateTransaction(testConsensusTx(t, burn, 2, signer))
+		if err == nil {
+			t.Error("expected a NFT burned by the wrong party to be rejected")
+		}
+	}
+
+	transfer := testConsensusTx(t, transferTo(bob), 2, alice)
+	err = storage.ValidateTransaction(transfer)
+	if err != nil {
+		t.Fatalf("failed to validate the transfer of a NFT by its owner: %v", err)
+	}
+	err = storage.ApplyTransaction(transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNFTOwner(t, plugin, id, bob)
+	checkNFTsOwnedBy(t, plugin, alice, 0)
+	// the previous owner can no longer transfer the NFT
+	err = storage.ValidateTransaction(testConsensusTx(t, transferTo(alice), 3, alice))
+	if err == nil {
+		t.Error("expected a NFT transferred by its previous owner to be rejected")
+	}
+
+	burned := testConsensusTx(t, burn, 3, bob)
+	err = storage.ValidateTransaction(burned)
+	if err != nil {
+		t.Fatalf("failed to validate the burn of a NFT by its owner: %v", err)
+	}
+	err = storage.ApplyTransaction(burned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = plugin.GetNFT(id); err != ErrNFTNotFound {
+		t.Errorf("expected the burned NFT not to be found, got: %v", err)
+	}
+	checkNFTsOwnedBy(t, plugin, bob, 0)
+	// a burned NFT can neither be transferred, nor minted again
+	burnedNFT := NFTTransferTransaction{NFTID: id, OwnerFulfillment: plugintest.FakeFulfillment(), NewOwner: bob.Condition()}
+	err = storage.ValidateTransaction(testConsensusTx(t, burnedNFT.Transaction(testTransferTxVersion), 4))
+	if err == nil {
+		t.Error("expected the transfer of a burned NFT to be rejected")
+	}
+	err = storage.ValidateTransaction(testConsensusTx(t, mint.Transaction(testMintTxVersion), 4, issuer))
+	if err == nil {
+		t.Error("expected a burned NFT not to be minted again")
+	}
+
+	// reverting restores the NFT and its previous owners, in reverse order
+	err = storage.RevertTransaction(burned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNFTOwner(t, plugin, id, bob)
+	err = storage.RevertTransaction(transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNFTOwner(t, plugin, id, alice)
+	checkNFTsOwnedBy(t, plugin, bob, 0)
+	err = storage.ValidateTransaction(testConsensusTx(t, transferTo(bob), 2, alice))
+	if err != nil {
+		t.Errorf("failed to validate the transfer of a NFT by its restored owner: %v", err)
+	}
+	err = storage.RevertTransaction(minted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = plugin.GetNFT(id); err != ErrNFTNotFound {
+		t.Errorf("expected the reverted NFT not to be found, got: %v", err)
+	}
+	checkNFTsOwnedBy(t, plugin, alice, 0)
+	err = storage.ValidateTransaction(testConsensusTx(t, mint.Transaction(testMintTxVersion), 1, issuer))
+	if err != nil {
+		t.Errorf("failed to validate the mint of a reverted NFT: %v", err)
+	}
+}
+
+func checkNFTOwner(t *testing.T, plugin *Plugin, id NFTID, owner plugintest.KeyPair) {
+	t.Helper()
+	nft, err := plugin.GetNFT(id)
+	if err != nil {
+		t.Fatalf("failed to get NFT %s: %v", id.String(), err)
+	}
+	if nft.Owner.UnlockHash() != owner.UnlockHash() {
+		t.Errorf("unexpected owner of NFT %s: %s != %s", id.String(), nft.Owner.UnlockHash().String(), owner.UnlockHash().String())
+	}
+	checkNFTsOwnedBy(t, plugin, owner, 1)
+}
+
+func checkNFTsOwnedBy(t *testing.T, plugin *Plugin, owner plugintest.KeyPair, count int) {
+	t.Helper()
+	nfts, err := plugin.GetNFTsOwnedBy(owner.UnlockHash())
+	if err != nil {
+		t.Fatalf("failed to get NFTs owned by %s: %v", owner.UnlockHash().String(), err)
+	}
+	if len(nfts) != count {
+		t.Errorf("unexpected amount of NFTs owned by %s: %d != %d", owner.UnlockHash().String(), len(nfts), count)
+	}
+}
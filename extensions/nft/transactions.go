@@ -0,0 +1,736 @@
+package nft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// NFTMintTransactionController defines a rivine-specific transaction controller,
+	// for an NFTMint Transaction. It allows anyone to mint a new NFT,
+	// for which the minter is recorded as the issuer of the NFT.
+	NFTMintTransactionController struct {
+		// TransactionVersion is used to validate/set the transaction version
+		// of an NFT mint transaction.
+		TransactionVersion types.TransactionVersion
+	}
+
+	// NFTTransferTransactionController defines a rivine-specific transaction controller,
+	// for an NFTTransfer Transaction. It allows the owner of an NFT to transfer it to a new owner.
+	NFTTransferTransactionController struct {
+		// NFTGetter is used to get the current owner of the NFT,
+		// in order to sign the owner fulfillment.
+		NFTGetter NFTGetter
+
+		// TransactionVersion is used to validate/set the transaction version
+		// of an NFT transfer transaction.
+		TransactionVersion types.TransactionVersion
+	}
+
+	// NFTBurnTransactionController defines a rivine-specific transaction controller,
+	// for an NFTBurn Transaction. It allows the owner of an NFT to destroy it.
+	NFTBurnTransactionController struct {
+		// NFTGetter is used to get the current owner of the NFT,
+		// in order to sign the owner fulfillment.
+		NFTGetter NFTGetter
+
+		// TransactionVersion is used to validate/set the transaction version
+		// of an NFT burn transaction.
+		TransactionVersion types.TransactionVersion
+	}
+)
+
+// ensure our controllers implement all desired interfaces
+var (
+	// ensure at compile time that NFTMintTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = NFTMintTransactionController{}
+	_ types.TransactionExtensionSigner           = NFTMintTransactionController{}
+	_ types.TransactionSignatureHasher           = NFTMintTransactionController{}
+	_ types.TransactionIDEncoder                 = NFTMintTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = NFTMintTransactionController{}
+
+	// ensure at compile time that NFTTransferTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = NFTTransferTransactionController{}
+	_ types.TransactionExtensionSigner           = NFTTransferTransactionController{}
+	_ types.TransactionSignatureHasher           = NFTTransferTransactionController{}
+	_ types.TransactionIDEncoder                 = NFTTransferTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = NFTTransferTransactionController{}
+
+	// ensure at compile time that NFTBurnTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController      = NFTBurnTransactionController{}
+	_ types.TransactionExtensionSigner = NFTBurnTransactionController{}
+	_ types.TransactionSignatureHasher = NFTBurnTransactionController{}
+	_ types.TransactionIDEncoder       = NFTBurnTransactionController{}
+)
+
+// NFTMintTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (nmtc NFTMintTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	nmtx, err := NFTMintTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NFTMintTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(nmtx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (nmtc NFTMintTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var nmtx NFTMintTransaction
+	err := rivbin.NewDecoder(r).Decode(&nmtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a NFTMintTx: %v", err)
+	}
+	// return NFT mint tx as regular rivine tx data
+	return nmtx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (nmtc NFTMintTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	nmtx, err := NFTMintTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a NFTMintTx: %v", err)
+	}
+	return json.Marshal(nmtx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (nmtc NFTMintTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var nmtx NFTMintTransaction
+	err := json.Unmarshal(data, &nmtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a NFTMintTx: %v", err)
+	}
+	// return NFT mint tx as regular rivine tx data
+	return nmtx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (nmtc NFTMintTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NFTMintTransactionExtension,
+	// which contains the issuer fulfillment that can be used to fulfill the issuer condition
+	nmTxExtension, ok := extension.(*NFTMintTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a NFTMintTx")
+	}
+	err := sign(&nmTxExtension.IssuerFulfillment, nmTxExtension.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign issuer fulfillment of NFTMintTx: %v", err)
+	}
+	return nmTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (nmtc NFTMintTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	nmtx, err := NFTMintTransactionFromTransaction(t, nmtc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a NFTMintTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierNFTMintTransaction,
+		nmtx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		nmtx.MetadataHash,
+		nmtx.MetadataURI,
+		nmtx.Issuer,
+		nmtx.Owner,
+		coinParentIDs(nmtx.CoinInputs),
+		nmtx.CoinOutputs,
+		nmtx.MinerFees,
+		nmtx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (nmtc NFTMintTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	nmtx, err := NFTMintTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NFTMintTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierNFTMintTransaction, nmtx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData
+func (nmtc NFTMintTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	nmext, ok := extension.(*NFTMintTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a NFTMintTx")
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: []types.UnlockConditionProxy{nmext.Issuer, nmext.Owner},
+	}, nil
+}
+
+// NFTTransferTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (nttc NFTTransferTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	nttx, err := NFTTransferTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NFTTransferTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(nttx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (nttc NFTTransferTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var nttx NFTTransferTransaction
+	err := rivbin.NewDecoder(r).Decode(&nttx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a NFTTransferTx: %v", err)
+	}
+	// return NFT transfer tx as regular rivine tx data
+	return nttx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (nttc NFTTransferTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	nttx, err := NFTTransferTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a NFTTransferTx: %v", err)
+	}
+	return json.Marshal(nttx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (nttc NFTTransferTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var nttx NFTTransferTransaction
+	err := json.Unmarshal(data, &nttx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a NFTTransferTx: %v", err)
+	}
+	// return NFT transfer tx as regular rivine tx data
+	return nttx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (nttc NFTTransferTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NFTTransferTransactionExtension,
+	// which contains the owner fulfillment that can be used to fulfill the current owner condition
+	ntTxExtension, ok := extension.(*NFTTransferTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a NFTTransferTx")
+	}
+	err := signOwnerFulfillment(nttc.NFTGetter, ntTxExtension.NFTID, &ntTxExtension.OwnerFulfillment, sign)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign owner fulfillment of NFTTransferTx: %v", err)
+	}
+	return ntTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (nttc NFTTransferTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	nttx, err := NFTTransferTransactionFromTransaction(t, nttc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a NFTTransferTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierNFTTransferTransaction,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		nttx.NFTID,
+		nttx.NewOwner,
+		coinParentIDs(nttx.CoinInputs),
+		nttx.CoinOutputs,
+		nttx.MinerFees,
+		nttx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (nttc NFTTransferTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	nttx, err := NFTTransferTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NFTTransferTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierNFTTransferTransaction, nttx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData
+func (nttc NFTTransferTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	ntext, ok := extension.(*NFTTransferTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a NFTTransferTx")
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: []types.UnlockConditionProxy{ntext.NewOwner},
+	}, nil
+}
+
+// NFTBurnTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (nbtc NFTBurnTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	nbtx, err := NFTBurnTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NFTBurnTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(nbtx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (nbtc NFTBurnTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var nbtx NFTBurnTransaction
+	err := rivbin.NewDecoder(r).Decode(&nbtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a NFTBurnTx: %v", err)
+	}
+	// return NFT burn tx as regular rivine tx data
+	return nbtx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (nbtc NFTBurnTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	nbtx, err := NFTBurnTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a NFTBurnTx: %v", err)
+	}
+	return json.Marshal(nbtx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (nbtc NFTBurnTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var nbtx NFTBurnTransaction
+	err := json.Unmarshal(data, &nbtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a NFTBurnTx: %v", err)
+	}
+	// return NFT burn tx as regular rivine tx data
+	return nbtx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (nbtc NFTBurnTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NFTBurnTransactionExtension,
+	// which contains the owner fulfillment that can be used to fulfill the current owner condition
+	nbTxExtension, ok := extension.(*NFTBurnTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a NFTBurnTx")
+	}
+	err := signOwnerFulfillment(nbtc.NFTGetter, nbTxExtension.NFTID, &nbTxExtension.OwnerFulfillment, sign)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign owner fulfillment of NFTBurnTx: %v", err)
+	}
+	return nbTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (nbtc NFTBurnTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	nbtx, err := NFTBurnTransactionFromTransaction(t, nbtc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a NFTBurnTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierNFTBurnTransaction,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		nbtx.NFTID,
+		coinParentIDs(nbtx.CoinInputs),
+		nbtx.CoinOutputs,
+		nbtx.MinerFees,
+		nbtx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (nbtc NFTBurnTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	nbtx, err := NFTBurnTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NFTBurnTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierNFTBurnTransaction, nbtx)
+}
+
+// signOwnerFulfillment signs the given owner fulfillment,
+// using the current owner condition of the NFT.
+func signOwnerFulfillment(getter NFTGetter, id NFTID, fulfillment *types.UnlockFulfillmentProxy, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) error {
+	if getter == nil {
+		return errors.New("no NFT getter available to get the owner condition of the NFT")
+	}
+	nft, err := getter.GetNFT(id)
+	if err != nil {
+		return fmt.Errorf("failed to get NFT %s: %v", id.String(), err)
+	}
+	return sign(fulfillment, nft.Owner)
+}
+
+func coinParentIDs(inputs []types.CoinInput) []types.CoinOutputID {
+	parentIDSlice := make([]types.CoinOutputID, 0, len(inputs))
+	for _, ci := range inputs {
+		parentIDSlice = append(parentIDSlice, ci.ParentID)
+	}
+	return parentIDSlice
+}
+
+// validateCoinTransactionData ensures the regular transaction data
+// contains at least one coin input and miner fee, and no block stake inputs/outputs,
+// as required by all NFT transactions.
+func validateCoinTransactionData(txData types.TransactionData) error {
+	if len(txData.CoinInputs) == 0 {
+		return errors.New("at least one coin input is required")
+	}
+	if len(txData.MinerFees) == 0 {
+		return errors.New("at least one miner fee is required")
+	}
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return errors.New("no block stake inputs/outputs are allowed")
+	}
+	return nil
+}
+
+type (
+	// NFTMintTransaction is used to mint a new NFT,
+	// recording the minter as the issuer of the NFT.
+	NFTMintTransaction struct {
+		// Nonce used to ensure the uniqueness of a NFTMintTransaction's ID, signature and NFT ID.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// MetadataHash is the hash of the (off-chain) metadata of the minted NFT.
+		MetadataHash crypto.Hash `json:"metadatahash"`
+		// MetadataURI optionally defines where the metadata of the minted NFT can be found.
+		MetadataURI string `json:"metadatauri,omitempty"`
+		// Issuer is the condition of the minter, recorded as the issuer of the NFT.
+		Issuer types.UnlockConditionProxy `json:"issuer"`
+		// IssuerFulfillment proves the minter owns the issuer condition.
+		IssuerFulfillment types.UnlockFulfillmentProxy `json:"issuerfulfillment"`
+		// Owner is the condition that initially owns the minted NFT.
+		Owner types.UnlockConditionProxy `json:"owner"`
+		// CoinInputs are used to fund the MinerFees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs can be used to refund coins from the CoinInputs.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this NFT mint transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// NFTMintTransactionExtension defines the NFTMintTransaction Extension Data
+	NFTMintTransactionExtension struct {
+		Nonce             types.TransactionNonce
+		MetadataHash      crypto.Hash
+		MetadataURI       string
+		Issuer            types.UnlockConditionProxy
+		IssuerFulfillment types.UnlockFulfillmentProxy
+		Owner             types.UnlockConditionProxy
+	}
+
+	// NFTTransferTransaction is used by the owner of an NFT to transfer it to a new owner.
+	NFTTransferTransaction struct {
+		// NFTID identifies the transferred NFT.
+		NFTID NFTID `json:"nftid"`
+		// OwnerFulfillment fulfills the current owner condition of the NFT.
+		OwnerFulfillment types.UnlockFulfillmentProxy `json:"ownerfulfillment"`
+		// NewOwner is the condition that owns the NFT once transferred.
+		NewOwner types.UnlockConditionProxy `json:"newowner"`
+		// CoinInputs are used to fund the MinerFees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs can be used to refund coins from the CoinInputs.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this NFT transfer transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// NFTTransferTransactionExtension defines the NFTTransferTransaction Extension Data
+	NFTTransferTransactionExtension struct {
+		NFTID            NFTID
+		OwnerFulfillment types.UnlockFulfillmentProxy
+		NewOwner         types.UnlockConditionProxy
+	}
+
+	// NFTBurnTransaction is used by the owner of an NFT to destroy it.
+	NFTBurnTransaction struct {
+		// NFTID identifies the burned NFT.
+		NFTID NFTID `json:"nftid"`
+		// OwnerFulfillment fulfills the current owner condition of the NFT.
+		OwnerFulfillment types.UnlockFulfillmentProxy `json:"ownerfulfillment"`
+		// CoinInputs are used to fund the MinerFees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs can be used to refund coins from the CoinInputs.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this NFT burn transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// NFTBurnTransactionExtension defines the NFTBurnTransaction Extension Data
+	NFTBurnTransactionExtension struct {
+		NFTID            NFTID
+		OwnerFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// NFTMintTransactionFromTransaction creates a NFTMintTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `NFTMintTransactionFromTransactionData` constructor.
+func NFTMintTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (NFTMintTransaction, error) {
+	if tx.Version != expectedVersion {
+		return NFTMintTransaction{}, fmt.Errorf(
+			"a NFT mint transaction requires tx version %d",
+			expectedVersion)
+	}
+	return NFTMintTransactionFromTransactionData(transactionDataFromTransaction(tx))
+}
+
+// NFTMintTransactionFromTransactionData creates a NFTMintTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func NFTMintTransactionFromTransactionData(txData types.TransactionData) (NFTMintTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NFTMintTransactionExtension,
+	// which contains all NFT-specific data
+	extensionData, ok := txData.Extension.(*NFTMintTransactionExtension)
+	if !ok {
+		return NFTMintTransaction{}, errors.New("invalid extension data for a NFTMintTransaction")
+	}
+	err := validateCoinTransactionData(txData)
+	if err != nil {
+		return NFTMintTransaction{}, fmt.Errorf("invalid NFTMintTransaction: %v", err)
+	}
+	// return the NFTMintTransaction, with the data extracted from the TransactionData
+	return NFTMintTransaction{
+		Nonce:             extensionData.Nonce,
+		MetadataHash:      extensionData.MetadataHash,
+		MetadataURI:       extensionData.MetadataURI,
+		Issuer:            extensionData.Issuer,
+		IssuerFulfillment: extensionData.IssuerFulfillment,
+		Owner:             extensionData.Owner,
+		CoinInputs:        txData.CoinInputs,
+		CoinOutputs:       txData.CoinOutputs,
+		MinerFees:         txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// NFTID returns the ID of the NFT minted by this transaction.
+func (nmtx *NFTMintTransaction) NFTID() NFTID {
+	return NewNFTID(nmtx.Nonce, nmtx.Issuer, nmtx.MetadataHash)
+}
+
+// TransactionData returns this NFTMintTransaction
+// as regular rivine transaction data.
+func (nmtx *NFTMintTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    nmtx.CoinInputs,
+		CoinOutputs:   nmtx.CoinOutputs,
+		MinerFees:     nmtx.MinerFees,
+		ArbitraryData: nmtx.ArbitraryData,
+		Extension: &NFTMintTransactionExtension{
+			Nonce:             nmtx.Nonce,
+			MetadataHash:      nmtx.MetadataHash,
+			MetadataURI:       nmtx.MetadataURI,
+			Issuer:            nmtx.Issuer,
+			IssuerFulfillment: nmtx.IssuerFulfillment,
+			Owner:             nmtx.Owner,
+		},
+	}
+}
+
+// Transaction returns this NFTMintTransaction
+// as regular rivine transaction, using the given version.
+func (nmtx *NFTMintTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	return transactionFromTransactionData(version, nmtx.TransactionData())
+}
+
+// NFTTransferTransactionFromTransaction creates a NFTTransferTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `NFTTransferTransactionFromTransactionData` constructor.
+func NFTTransferTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (NFTTransferTransaction, error) {
+	if tx.Version != expectedVersion {
+		return NFTTransferTransaction{}, fmt.Errorf(
+			"a NFT transfer transaction requires tx version %d",
+			expectedVersion)
+	}
+	return NFTTransferTransactionFromTransactionData(transactionDataFromTransaction(tx))
+}
+
+// NFTTransferTransactionFromTransactionData creates a NFTTransferTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func NFTTransferTransactionFromTransactionData(txData types.TransactionData) (NFTTransferTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NFTTransferTransactionExtension,
+	// which contains all NFT-specific data
+	extensionData, ok := txData.Extension.(*NFTTransferTransactionExtension)
+	if !ok {
+		return NFTTransferTransaction{}, errors.New("invalid extension data for a NFTTransferTransaction")
+	}
+	err := validateCoinTransactionData(txData)
+	if err != nil {
+		return NFTTransferTransaction{}, fmt.Errorf("invalid NFTTransferTransaction: %v", err)
+	}
+	// return the NFTTransferTransaction, with the data extracted from the TransactionData
+	return NFTTransferTransaction{
+		NFTID:            extensionData.NFTID,
+		OwnerFulfillment: extensionData.OwnerFulfillment,
+		NewOwner:         extensionData.NewOwner,
+		CoinInputs:       txData.CoinInputs,
+		CoinOutputs:      txData.CoinOutputs,
+		MinerFees:        txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this NFTTransferTransaction
+// as regular rivine transaction data.
+func (nttx *NFTTransferTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    nttx.CoinInputs,
+		CoinOutputs:   nttx.CoinOutputs,
+		MinerFees:     nttx.MinerFees,
+		ArbitraryData: nttx.ArbitraryData,
+		Extension: &NFTTransferTransactionExtension{
+			NFTID:            nttx.NFTID,
+			OwnerFulfillment: nttx.OwnerFulfillment,
+			NewOwner:         nttx.NewOwner,
+		},
+	}
+}
+
+// Transaction returns this NFTTransferTransaction
+// as regular rivine transaction, using the given version.
+func (nttx *NFTTransferTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	return transactionFromTransactionData(version, nttx.TransactionData())
+}
+
+// NFTBurnTransactionFromTransaction creates a NFTBurnTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `NFTBurnTransactionFromTransactionData` constructor.
+func NFTBurnTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (NFTBurnTransaction, error) {
+	if tx.Version != expectedVersion {
+		return NFTBurnTransaction{}, fmt.Errorf(
+			"a NFT burn transaction requires tx version %d",
+			expectedVersion)
+	}
+	return NFTBurnTransactionFromTransactionData(transactionDataFromTransaction(tx))
+}
+
+// NFTBurnTransactionFromTransactionData creates a NFTBurnTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func NFTBurnTransactionFromTransactionData(txData types.TransactionData) (NFTBurnTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NFTBurnTransactionExtension,
+	// which contains all NFT-specific data
+	extensionData, ok := txData.Extension.(*NFTBurnTransactionExtension)
+	if !ok {
+		return NFTBurnTransaction{}, errors.New("invalid extension data for a NFTBurnTransaction")
+	}
+	err := validateCoinTransactionData(txData)
+	if err != nil {
+		return NFTBurnTransaction{}, fmt.Errorf("invalid NFTBurnTransaction: %v", err)
+	}
+	// return the NFTBurnTransaction, with the data extracted from the TransactionData
+	return NFTBurnTransaction{
+		NFTID:            extensionData.NFTID,
+		OwnerFulfillment: extensionData.OwnerFulfillment,
+		CoinInputs:       txData.CoinInputs,
+		CoinOutputs:      txData.CoinOutputs,
+		MinerFees:        txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this NFTBurnTransaction
+// as regular rivine transaction data.
+func (nbtx *NFTBurnTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    nbtx.CoinInputs,
+		CoinOutputs:   nbtx.CoinOutputs,
+		MinerFees:     nbtx.MinerFees,
+		ArbitraryData: nbtx.ArbitraryData,
+		Extension: &NFTBurnTransactionExtension{
+			NFTID:            nbtx.NFTID,
+			OwnerFulfillment: nbtx.OwnerFulfillment,
+		},
+	}
+}
+
+// Transaction returns this NFTBurnTransaction
+// as regular rivine transaction, using the given version.
+func (nbtx *NFTBurnTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	return transactionFromTransactionData(version, nbtx.TransactionData())
+}
+
+func transactionDataFromTransaction(tx types.Transaction) types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	}
+}
+
+func transactionFromTransactionData(version types.TransactionVersion, txData types.TransactionData) types.Transaction {
+	return types.Transaction{
+		Version:       version,
+		CoinInputs:    txData.CoinInputs,
+		CoinOutputs:   txData.CoinOutputs,
+		MinerFees:     txData.MinerFees,
+		ArbitraryData: txData.ArbitraryData,
+		Extension:     txData.Extension,
+	}
+}
@@ -0,0 +1,166 @@
+package nft
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/internal/plugintest"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	testMintTxVersion     types.TransactionVersion = 208
+	testTransferTxVersion types.TransactionVersion = 209
+	testBurnTxVersion     types.TransactionVersion = 210
+)
+
+func registerTestTransactionVersions() func() {
+	types.RegisterTransactionVersion(testMintTxVersion, NFTMintTransactionController{TransactionVersion: testMintTxVersion})
+	types.RegisterTransactionVersion(testTransferTxVersion, NFTTransferTransactionController{TransactionVersion: testTransferTxVersion})
+	types.RegisterTransactionVersion(testBurnTxVersion, NFTBurnTransactionController{TransactionVersion: testBurnTxVersion})
+	return func() {
+		types.RegisterTransactionVersion(testMintTxVersion, nil)
+		types.RegisterTransactionVersion(testTransferTxVersion, nil)
+		types.RegisterTransactionVersion(testBurnTxVersion, nil)
+	}
+}
+
+func testNFTTransactions(t *testing.T) []types.Transaction {
+	issuer := plugintest.UnlockHashCondition(t, "015a080a9259b9d4aaa550e2156f49b1a79a64c7ea463d810d4493e8242e6791584fbdac553e6f")
+	owner := plugintest.UnlockHashCondition(t, "01b5e42056ef394f2ad9b511a61cec874d25bebe2095682dd37455cbafed4bec154e382a23f90e")
+
+	mintTx := NFTMintTransaction{
+		Nonce:             types.TransactionNonce{1, 2, 3, 4, 5, 6, 7, 8},
+		MetadataHash:      crypto.HashBytes([]byte("certificate")),
+		MetadataURI:       "https://example.org/certificates/1",
+		Issuer:            issuer,
+		IssuerFulfillment: plugintest.FakeFulfillment(),
+		Owner:             owner,
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 2}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(42), Condition: issuer},
+		},
+		MinerFees:     []types.Currency{types.NewCurrency64(1)},
+		ArbitraryData: []byte("first certificate"),
+	}
+	transferTx := NFTTransferTransaction{
+		NFTID:            mintTx.NFTID(),
+		OwnerFulfillment: plugintest.FakeFulfillment(),
+		NewOwner:         issuer,
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 3}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	burnTx := NFTBurnTransaction{
+		NFTID:            mintTx.NFTID(),
+		OwnerFulfillment: plugintest.FakeFulfillment(),
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 4}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	return []types.Transaction{
+		mintTx.Transaction(testMintTxVersion),
+		transferTx.Transaction(testTransferTxVersion),
+		burnTx.Transaction(testBurnTxVersion),
+	}
+}
+
+func TestNFTTransactionEncodingRoundtrip(t *testing.T) {
+	defer registerTestTransactionVersions()()
+
+	for idx, tx := range testNFTTransactions(t) {
+		// JSON roundtrip
+		b, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to JSON-marshal: %v", idx, err)
+		}
+		var jsonTx types.Transaction
+		err = json.Unmarshal(b, &jsonTx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to JSON-unmarshal: %v", idx, err)
+		}
+		if jsonTx.ID() != tx.ID() {
+			t.Errorf("tx #%d: JSON roundtrip changed ID: %v != %v", idx, jsonTx.ID(), tx.ID())
+		}
+
+		// binary roundtrip
+		b, err = siabin.Marshal(tx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to binary-marshal: %v", idx, err)
+		}
+		var binTx types.Transaction
+		err = siabin.Unmarshal(b, &binTx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to binary-unmarshal: %v", idx, err)
+		}
+		if binTx.ID() != tx.ID() {
+			t.Errorf("tx #%d: binary roundtrip changed ID: %v != %v", idx, binTx.ID(), tx.ID())
+		}
+		if !reflect.DeepEqual(binTx.Extension, jsonTx.Extension) {
+			t.Errorf("tx #%d: binary and JSON decoded extensions differ: %v != %v", idx, binTx.Extension, jsonTx.Extension)
+		}
+	}
+}
+
+func TestNFTTransactionFromTransaction(t *testing.T) {
+	defer registerTestTransactionVersions()()
+
+	txs := testNFTTransactions(t)
+
+	nmtx, err := NFTMintTransactionFromTransaction(txs[0], testMintTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nttx, err := NFTTransferTransactionFromTransaction(txs[1], testTransferTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nttx.NFTID != nmtx.NFTID() {
+		t.Errorf("unexpected NFT ID: %v != %v", nttx.NFTID, nmtx.NFTID())
+	}
+	nbtx, err := NFTBurnTransactionFromTransaction(txs[2], testBurnTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nbtx.NFTID != nmtx.NFTID() {
+		t.Errorf("unexpected NFT ID: %v != %v", nbtx.NFTID, nmtx.NFTID())
+	}
+
+	// a transaction of one NFT type can never be used as another
+	_, err = NFTMintTransactionFromTransaction(txs[1], testMintTxVersion)
+	if err == nil {
+		t.Error("expected a NFT transfer tx not to be accepted as a NFT mint tx")
+	}
+	_, err = NFTBurnTransactionFromTransaction(txs[1], testTransferTxVersion)
+	if err == nil {
+		t.Error("expected a NFT transfer tx not to be accepted as a NFT burn tx")
+	}
+}
+
+func TestNewNFTID(t *testing.T) {
+	issuer := plugintest.UnlockHashCondition(t, "015a080a9259b9d4aaa550e2156f49b1a79a64c7ea463d810d4493e8242e6791584fbdac553e6f")
+	other := plugintest.UnlockHashCondition(t, "01b5e42056ef394f2ad9b511a61cec874d25bebe2095682dd37455cbafed4bec154e382a23f90e")
+	nonce := types.TransactionNonce{1}
+	metadataHash := crypto.HashBytes([]byte("metadata"))
+
+	id := NewNFTID(nonce, issuer, metadataHash)
+	if id != NewNFTID(nonce, issuer, metadataHash) {
+		t.Error("NFT ID is expected to be deterministic")
+	}
+	if id == NewNFTID(types.TransactionNonce{2}, issuer, metadataHash) {
+		t.Error("NFT ID is expected to depend on the nonce")
+	}
+	if id == NewNFTID(nonce, other, metadataHash) {
+		t.Error("NFT ID is expected to depend on the issuer")
+	}
+	if id == NewNFTID(nonce, issuer, crypto.HashBytes([]byte("other metadata"))) {
+		t.Error("NFT ID is expected to depend on the metadata hash")
+	}
+}
@@ -0,0 +1,117 @@
+package nft
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// These Specifiers are used internally when calculating a Transaction's ID,
+// and the IDs of non-fungible tokens.
+// See Rivine's Specifier for more details.
+var (
+	SpecifierNFTMintTransaction     = types.Specifier{'n', 'f', 't', ' ', 'm', 'i', 'n', 't', ' ', 't', 'x'}
+	SpecifierNFTTransferTransaction = types.Specifier{'n', 'f', 't', ' ', 't', 'r', 'a', 'n', 's', 'f', 'e', 'r', ' ', 't', 'x'}
+	SpecifierNFTBurnTransaction     = types.Specifier{'n', 'f', 't', ' ', 'b', 'u', 'r', 'n', ' ', 't', 'x'}
+	SpecifierNFT                    = types.Specifier{'n', 'f', 't'}
+)
+
+const (
+	// MaxMetadataURILength defines the maximum length (in bytes) of the metadata URI of an NFT.
+	MaxMetadataURILength = 256
+)
+
+var (
+	// ErrNFTNotFound is returned in case an NFT could not be found,
+	// either because it never existed or because it was burned.
+	ErrNFTNotFound = errors.New("NFT not found")
+)
+
+type (
+	// NFTID uniquely identifies a non-fungible token.
+	NFTID crypto.Hash
+
+	// NFT is the state of a non-fungible token as tracked by the consensus plugin.
+	NFT struct {
+		ID NFTID `json:"id"`
+		// MetadataHash is the hash of the (off-chain) metadata of the NFT,
+		// e.g. the hash of the document certified by this NFT.
+		MetadataHash crypto.Hash `json:"metadatahash"`
+		// MetadataURI optionally defines where the metadata of the NFT can be found.
+		MetadataURI string `json:"metadatauri,omitempty"`
+		// Issuer is the condition which minted this NFT,
+		// allowing anyone to verify the origin of the NFT.
+		Issuer types.UnlockConditionProxy `json:"issuer"`
+		// Owner is the condition that has to be fulfilled
+		// in order to transfer or burn this NFT.
+		Owner types.UnlockConditionProxy `json:"owner"`
+		// CreationHeight is the height of the block which contains the
+		// transaction that minted this NFT.
+		CreationHeight types.BlockHeight `json:"creationheight"`
+		// CreationTransactionID is the ID of the transaction that minted this NFT.
+		CreationTransactionID types.TransactionID `json:"creationtransactionid"`
+		// OwnerHeight and OwnerTime define the block which contains the transaction
+		// that minted or transferred this NFT to its current owner.
+		OwnerHeight types.BlockHeight `json:"ownerheight"`
+		OwnerTime   types.Timestamp   `json:"ownertime"`
+	}
+)
+
+// NewNFTID computes the ID of a new NFT,
+// using the nonce of the transaction that mints it, its issuer and its metadata hash.
+func NewNFTID(nonce types.TransactionNonce, issuer types.UnlockConditionProxy, metadataHash crypto.Hash) (id NFTID) {
+	h := crypto.NewHash()
+	rivbin.NewEncoder(h).EncodeAll(
+		SpecifierNFT,
+		nonce,
+		issuer,
+		metadataHash,
+	)
+	h.Sum(id[:0])
+	return
+}
+
+// String prints the NFT id in hex.
+func (id NFTID) String() string {
+	return crypto.Hash(id).String()
+}
+
+// LoadString loads the given NFT id from a hex string
+func (id *NFTID) LoadString(str string) error {
+	return (*crypto.Hash)(id).LoadString(str)
+}
+
+// MarshalJSON marshals an NFT id as a hex string.
+func (id NFTID) MarshalJSON() ([]byte, error) {
+	return crypto.Hash(id).MarshalJSON()
+}
+
+// UnmarshalJSON decodes the json hex string of the NFT id.
+func (id *NFTID) UnmarshalJSON(b []byte) error {
+	return (*crypto.Hash)(id).UnmarshalJSON(b)
+}
+
+// validateOwnerCondition ensures the condition can be used as the owner (or issuer) of an NFT.
+func validateOwnerCondition(condition types.UnlockConditionProxy, ctx types.ValidationContext) error {
+	if condition.ConditionType() == types.ConditionTypeNil {
+		return errors.New("the nil condition cannot be used, as it would allow anyone to claim the NFT")
+	}
+	err := condition.IsStandardCondition(ctx)
+	if err != nil {
+		return fmt.Errorf("condition is not standard: %v", err)
+	}
+	return nil
+}
+
+// NFTGetter allows you to look up an (unburned) NFT.
+//
+// For the daemon this interface is implemented directly by the consensus plugin,
+// while for a client this is implemented using the REST API of a rivine daemon.
+type NFTGetter interface {
+	// GetNFT returns the NFT for the given ID,
+	// returning ErrNFTNotFound if it doesn't exist or is burned.
+	GetNFT(id NFTID) (NFT, error)
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+)
+
+// RegisterConsensusPaymentChannelHTTPHandlers registers the default Rivine handlers for all default Rivine consensus payment channel HTTP endpoints.
+func RegisterConsensusPaymentChannelHTTPHandlers(router rapi.Router, plugin *paymentchannel.Plugin) {
+	router.GET("/consensus/paymentchannel/channels/:id", NewGetChannelHandler(plugin))
+	router.GET("/consensus/paymentchannel/parties/:unlockhash", NewGetChannelsOfHandler(plugin))
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+)
+
+// RegisterExplorerPaymentChannelHTTPHandlers registers the default Rivine handlers for all default Rivine explorer payment channel HTTP endpoints.
+func RegisterExplorerPaymentChannelHTTPHandlers(router rapi.Router, plugin *paymentchannel.Plugin) {
+	router.GET("/explorer/paymentchannel/channels/:id", NewGetChannelHandler(plugin))
+	router.GET("/explorer/paymentchannel/parties/:unlockhash", NewGetChannelsOfHandler(plugin))
+}
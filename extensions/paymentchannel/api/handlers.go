@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// GetChannel contains a requested payment channel.
+	GetChannel struct {
+		Channel paymentchannel.Channel `json:"channel"`
+	}
+
+	// GetChannelsOf contains all payment channels in which a requested address is a party.
+	GetChannelsOf struct {
+		Channels []paymentchannel.Channel `json:"channels"`
+	}
+)
+
+// NewGetChannelHandler creates a handler to handle the API calls to /paymentchannel/channels/:id.
+func NewGetChannelHandler(plugin *paymentchannel.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id paymentchannel.ChannelID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid payment channel ID given: %v", err)}, http.StatusBadRequest)
+			return
+		}
+		ch, err := plugin.GetChannel(id)
+		if err != nil {
+			if err == paymentchannel.ErrChannelNotFound {
+				rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusNoContent)
+				return
+			}
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetChannel{
+			Channel: ch,
+		})
+	}
+}
+
+// NewGetChannelsOfHandler creates a handler to handle the API calls to /paymentchannel/parties/:unlockhash.
+func NewGetChannelsOfHandler(plugin *paymentchannel.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var uh types.UnlockHash
+		err := uh.LoadString(ps.ByName("unlockhash"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid unlock hash given: %v", err)}, http.StatusBadRequest)
+			return
+		}
+		channels, err := plugin.GetChannelsOf(uh)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetChannelsOf{
+			Channels: channels,
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel/manager"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// GetManagedChannels contains all payment channels managed by the wallet.
+	GetManagedChannels struct {
+		Channels []manager.ManagedChannel `json:"channels"`
+	}
+
+	// GetManagedChannel contains a requested payment channel managed by the wallet.
+	GetManagedChannel struct {
+		Channel manager.ManagedChannel `json:"channel"`
+	}
+
+	// PostOpenChannel contains the parameters of a payment channel to open.
+	PostOpenChannel struct {
+		Receiver      types.PublicKey   `json:"receiver"`
+		Capacity      types.Currency    `json:"capacity"`
+		DisputePeriod types.BlockHeight `json:"disputeperiod"`
+	}
+	// PostOpenChannelResponse contains the ID of the opened payment channel,
+	// as well as the ID of the transaction which opened it.
+	PostOpenChannelResponse struct {
+		ChannelID     paymentchannel.ChannelID `json:"channelid"`
+		TransactionID types.TransactionID      `json:"transactionid"`
+	}
+
+	// PostChannelPayment contains the amount to pay to the counterparty of a payment channel.
+	PostChannelPayment struct {
+		Amount types.Currency `json:"amount"`
+	}
+
+	// PostChannelCommitment contains a (partially) signed commitment of a payment channel.
+	PostChannelCommitment struct {
+		Commitment paymentchannel.SignedChannelCommitment `json:"commitment"`
+	}
+
+	// PostChannelTransaction contains a payment channel transaction,
+	// which still has to be signed by the counterparty.
+	PostChannelTransaction struct {
+		Transaction types.Transaction `json:"transaction"`
+	}
+
+	// PostChannelTransactionID contains the ID of a submitted payment channel transaction.
+	PostChannelTransactionID struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+	}
+)
+
+// RegisterManagerPaymentChannelHTTPHandlers registers the default Rivine handlers for all default Rivine wallet payment channel HTTP endpoints.
+func RegisterManagerPaymentChannelHTTPHandlers(router rapi.Router, m *manager.Manager, requiredPassword string) {
+	router.GET("/wallet/paymentchannels", rapi.RequirePasswordHandler(NewGetManagedChannelsHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels", rapi.RequirePasswordHandler(NewPostOpenChannelHandler(m), requiredPassword))
+	router.GET("/wallet/paymentchannels/:id", rapi.RequirePasswordHandler(NewGetManagedChannelHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels/:id/track", rapi.RequirePasswordHandler(NewPostTrackChannelHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels/:id/pay", rapi.RequirePasswordHandler(NewPostChannelPaymentHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels/:id/sign", rapi.RequirePasswordHandler(NewPostSignChannelCommitmentHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels/:id/update", rapi.RequirePasswordHandler(NewPostUpdateChannelCommitmentHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels/:id/close", rapi.RequirePasswordHandler(NewPostCooperativeCloseChannelHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels/:id/forceclose", rapi.RequirePasswordHandler(NewPostUnilateralCloseChannelHandler(m), requiredPassword))
+	router.POST("/wallet/paymentchannels/:id/settle", rapi.RequirePasswordHandler(NewPostSettleChannelHandler(m), requiredPassword))
+}
+
+// NewGetManagedChannelsHandler creates a handler to handle the API calls to GET /wallet/paymentchannels.
+func NewGetManagedChannelsHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		channels, err := m.Channels()
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetManagedChannels{
+			Channels: channels,
+		})
+	}
+}
+
+// NewPostOpenChannelHandler creates a handler to handle the API calls to POST /wallet/paymentchannels.
+func NewPostOpenChannelHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body PostOpenChannel
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			rapi.WriteError(w, rapi.Error{Message: "error decoding the supplied payment channel parameters: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		id, txID, err := m.Open(body.Receiver, body.Capacity, body.DisputePeriod)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: "error after call to POST /wallet/paymentchannels: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, PostOpenChannelResponse{
+			ChannelID:     id,
+			TransactionID: txID,
+		})
+	}
+}
+
+// NewGetManagedChannelHandler creates a handler to handle the API calls to GET /wallet/paymentchannels/:id.
+func NewGetManagedChannelHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := channelIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		ch, err := m.Channel(id)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteJSON(w, GetManagedChannel{
+			Channel: ch,
+		})
+	}
+}
+
+// NewPostTrackChannelHandler creates a handler to handle the API calls to POST /wallet/paymentchannels/:id/track.
+func NewPostTrackChannelHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := channelIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		err := m.Track(id)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteSuccess(w)
+	}
+}
+
+// NewPostChannelPaymentHandler creates a handler to handle the API calls to POST /wallet/paymentchannels/:id/pay.
+func NewPostChannelPaymentHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := channelIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		var body PostChannelPayment
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			rapi.WriteError(w, rapi.Error{Message: "error decoding the supplied payment: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		proposal, err := m.Pay(id, body.Amount)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteJSON(w, PostChannelCommitment{
+			Commitment: proposal,
+		})
+	}
+}
+
+// NewPostSignChannelCommitmentHandler creates a handler to handle the API calls to POST /wallet/paymentchannels/:id/sign.
+func NewPostSignChannelCommitmentHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		body, ok := channelCommitmentFromRequest(w, req, ps)
+		if !ok {
+			return
+		}
+		commitment, err := m.Sign(body.Commitment)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteJSON(w, PostChannelCommitment{
+			Commitment: commitment,
+		})
+	}
+}
+
+// NewPostUpdateChannelCommitmentHandler creates a handler to handle the API calls to POST /wallet/paymentchannels/:id/update.
+func NewPostUpdateChannelCommitmentHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		body, ok := channelCommitmentFromRequest(w, req, ps)
+		if !ok {
+			return
+		}
+		err := m.Update(body.Commitment)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteSuccess(w)
+	}
+}
+
+// NewPostCooperativeCloseChannelHandler creates a handler to handle the API calls to POST /wallet/paymentchannels/:id/close.
+func NewPostCooperativeCloseChannelHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := channelIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		tx, err := m.CooperativeClose(id)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteJSON(w, PostChannelTransaction{
+			Transaction: tx,
+		})
+	}
+}
+
+// NewPostUnilateralCloseChannelHandler creates a handler to handle the API calls to POST /wallet/paymentchannels/:id/forceclose.
+func NewPostUnilateralCloseChannelHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := channelIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		txID, err := m.UnilateralClose(id)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteJSON(w, PostChannelTransactionID{
+			TransactionID: txID,
+		})
+	}
+}
+
+// NewPostSettleChannelHandler creates a handler to handle the API calls to POST /wallet/paymentchannels/:id/settle.
+func NewPostSettleChannelHandler(m *manager.Manager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := channelIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		txID, err := m.Settle(id)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, managerErrorToHTTPStatus(err))
+			return
+		}
+		rapi.WriteJSON(w, PostChannelTransactionID{
+			TransactionID: txID,
+		})
+	}
+}
+
+// channelIDFromParams parses the payment channel ID from the URL,
+// writing an error to the response if it is invalid.
+func channelIDFromParams(w http.ResponseWriter, ps httprouter.Params) (paymentchannel.ChannelID, bool) {
+	var id paymentchannel.ChannelID
+	err := id.LoadString(ps.ByName("id"))
+	if err != nil {
+		rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid payment channel ID given: %v", err)}, http.StatusBadRequest)
+		return paymentchannel.ChannelID{}, false
+	}
+	return id, true
+}
+
+// channelCommitmentFromRequest parses the commitment from the request body,
+// ensuring it belongs to the payment channel identified by the URL.
+func channelCommitmentFromRequest(w http.ResponseWriter, req *http.Request, ps httprouter.Params) (PostChannelCommitment, bool) {
+	id, ok := channelIDFromParams(w, ps)
+	if !ok {
+		return PostChannelCommitment{}, false
+	}
+	var body PostChannelCommitment
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		rapi.WriteError(w, rapi.Error{Message: "error decoding the supplied commitment: " + err.Error()}, http.StatusBadRequest)
+		return PostChannelCommitment{}, false
+	}
+	if body.Commitment.Commitment.ChannelID != id {
+		rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf(
+			"commitment is for payment channel %s instead of %s", body.Commitment.Commitment.ChannelID.String(), id.String())}, http.StatusBadRequest)
+		return PostChannelCommitment{}, false
+	}
+	return body, true
+}
+
+// managerErrorToHTTPStatus returns the HTTP status code matching the given manager error.
+func managerErrorToHTTPStatus(err error) int {
+	if err == manager.ErrChannelNotManaged || err == paymentchannel.ErrChannelNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel/api"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	client "github.com/threefoldtech/rivine/pkg/client"
+	types "github.com/threefoldtech/rivine/types"
+)
+
+// PluginClient is used to be able to get the payment channels tracked by the payment channel plugin,
+// such that the CLI can sign payment channel transactions,
+// without requiring access to the consensus-extended database.
+type PluginClient struct {
+	client       client.BaseClient
+	rootEndpoint string
+}
+
+// NewPluginConsensusClient creates a new PluginClient,
+// that can be used for easy interaction with the payment channel API exposed via the Consensus endpoints
+func NewPluginConsensusClient(cli client.BaseClient) *PluginClient {
+	if cli == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		client:       cli,
+		rootEndpoint: "/consensus",
+	}
+}
+
+// NewPluginExplorerClient creates a new PluginClient,
+// that can be used for easy interaction with the payment channel API exposed via the Explorer endpoints
+func NewPluginExplorerClient(cli client.BaseClient) *PluginClient {
+	if cli == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		client:       cli,
+		rootEndpoint: "/explorer",
+	}
+}
+
+var (
+	// ensure PluginClient implements the ChannelGetter interface
+	_ paymentchannel.ChannelGetter = (*PluginClient)(nil)
+)
+
+// GetChannel implements paymentchannel.ChannelGetter.GetChannel
+func (cli *PluginClient) GetChannel(id paymentchannel.ChannelID) (paymentchannel.Channel, error) {
+	var result api.GetChannel
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/paymentchannel/channels/"+id.String(), &result)
+	if err != nil {
+		if err == rapi.ErrStatusNotFound {
+			return paymentchannel.Channel{}, paymentchannel.ErrChannelNotFound
+		}
+		return paymentchannel.Channel{}, fmt.Errorf(
+			"failed to get payment channel %s from daemon: %v", id.String(), err)
+	}
+	return result.Channel, nil
+}
+
+// GetChannelsOf returns all payment channels in which the given address is a party.
+func (cli *PluginClient) GetChannelsOf(uh types.UnlockHash) ([]paymentchannel.Channel, error) {
+	var result api.GetChannelsOf
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/paymentchannel/parties/"+uh.String(), &result)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get payment channels of %s from daemon: %v", uh.String(), err)
+	}
+	return result.Channels, nil
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+	types "github.com/threefoldtech/rivine/types"
+)
+
+// CreateExploreCmd adds the explorer cli subcommands for the payment channel plugin
+func CreateExploreCmd(cli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(cli)
+	if err != nil {
+		return err
+	}
+	createCmd(cli.ExploreCmd, NewPluginExplorerClient(bc))
+	return nil
+}
+
+// CreateConsensusCmd adds the consensus cli subcommands for the payment channel plugin
+func CreateConsensusCmd(cli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(cli)
+	if err != nil {
+		return err
+	}
+	createCmd(cli.ConsensusCmd, NewPluginConsensusClient(bc))
+	return nil
+}
+
+func createCmd(rootCmd *cobra.Command, pluginClient *PluginClient) {
+	subCmds := &subCmd{
+		pluginClient: pluginClient,
+	}
+
+	// create root paymentchannel command and all subs
+	var (
+		paymentChannelCmd = &cobra.Command{
+			Use:   "paymentchannel",
+			Short: "Get information about payment channels",
+		}
+		getChannelCmd = &cobra.Command{
+			Use:   "get <channelID>",
+			Short: "Get a payment channel, including its status and last submitted commitment",
+			Args:  cobra.ExactArgs(1),
+			Run:   subCmds.getChannel,
+		}
+		getChannelsOfCmd = &cobra.Command{
+			Use:   "party <address>",
+			Short: "Get all payment channels in which an address is the sender or receiver",
+			Args:  cobra.ExactArgs(1),
+			Run:   subCmds.getChannelsOf,
+		}
+	)
+
+	paymentChannelCmd.PersistentFlags().Var(
+		cli.NewEncodingTypeFlag(cli.EncodingTypeHuman, &subCmds.cfg.EncodingType, cli.EncodingTypeJSON|cli.EncodingTypeHuman), "encoding",
+		cli.EncodingTypeFlagDescription(cli.EncodingTypeJSON|cli.EncodingTypeHuman))
+
+	paymentChannelCmd.AddCommand(
+		getChannelCmd,
+		getChannelsOfCmd,
+	)
+	rootCmd.AddCommand(paymentChannelCmd)
+}
+
+type subCmd struct {
+	pluginClient *PluginClient
+	cfg          struct {
+		EncodingType cli.EncodingType
+	}
+}
+
+func (subCmds *subCmd) getChannel(cmd *cobra.Command, args []string) {
+	var id paymentchannel.ChannelID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid payment channel ID given", err)
+	}
+	ch, err := subCmds.pluginClient.GetChannel(id)
+	if err != nil {
+		cli.DieWithError("failed to get the payment channel", err)
+	}
+	subCmds.encode(ch)
+}
+
+func (subCmds *subCmd) getChannelsOf(cmd *cobra.Command, args []string) {
+	var uh types.UnlockHash
+	err := uh.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid address given", err)
+	}
+	channels, err := subCmds.pluginClient.GetChannelsOf(uh)
+	if err != nil {
+		cli.DieWithError("failed to get the payment channels of the address", err)
+	}
+	subCmds.encode(channels)
+}
+
+// encode depending on the encoding flag
+func (subCmds *subCmd) encode(value interface{}) {
+	var encode func(interface{}) error
+	switch subCmds.cfg.EncodingType {
+	case cli.EncodingTypeHuman:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		encode = e.Encode
+	case cli.EncodingTypeJSON:
+		encode = json.NewEncoder(os.Stdout).Encode
+	}
+	err := encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
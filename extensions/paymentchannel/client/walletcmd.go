@@ -0,0 +1,299 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	"github.com/threefoldtech/rivine/extensions/paymentchannel/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the payment channel manager
+func CreateWalletCmds(ccli *client.CommandLineClient) error {
+	walletCmd := &walletCmd{
+		cli: ccli,
+	}
+
+	var (
+		channelCmd = &cobra.Command{
+			Use:   "channel",
+			Short: "Manage the payment channels of this wallet",
+			Long: `Manage the payment channels of this wallet.
+
+Payments are made off-chain, by exchanging commitments signed by both parties
+of the channel. Only the opening and closing of a channel requires a transaction.`,
+		}
+		openChannelCmd = &cobra.Command{
+			Use:   "open <receiverPublicKey> <capacity> <disputePeriod>",
+			Short: "Open a payment channel to a receiver, locking the capacity from this wallet",
+			Long: `Open a payment channel to a receiver, locking the capacity using the coins of this wallet.
+
+The dispute period, defined in blocks, is the time the counterparty has
+to dispute an outdated commitment, used to close the channel unilaterally.`,
+			Args: cobra.ExactArgs(3),
+			Run:  walletCmd.openChannelCmd,
+		}
+		listChannelsCmd = &cobra.Command{
+			Use:   "list",
+			Short: "List the payment channels managed by this wallet",
+			Args:  cobra.NoArgs,
+			Run:   walletCmd.listChannelsCmd,
+		}
+		getChannelCmd = &cobra.Command{
+			Use:   "get <channelID>",
+			Short: "Get a payment channel managed by this wallet, including its latest commitment",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.getChannelCmd,
+		}
+		trackChannelCmd = &cobra.Command{
+			Use:   "track <channelID>",
+			Short: "Manage a payment channel, opened to (or by) this wallet",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.trackChannelCmd,
+		}
+		payChannelCmd = &cobra.Command{
+			Use:   "pay <channelID> <amount>",
+			Short: "Create a commitment paying an amount to the counterparty",
+			Long: `Create a commitment paying an amount to the counterparty, signed by this wallet.
+
+The returned (JSON-encoded) commitment has to be signed by the counterparty,
+using the sign command, after which the returned commitment is to be
+stored using the update command.`,
+			Args: cobra.ExactArgs(2),
+			Run:  walletCmd.payChannelCmd,
+		}
+		signChannelCmd = &cobra.Command{
+			Use:   "sign <commitment>",
+			Short: "Sign a commitment proposed by the counterparty",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.signChannelCmd,
+		}
+		updateChannelCmd = &cobra.Command{
+			Use:   "update <commitment>",
+			Short: "Store a commitment signed by both parties",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.updateChannelCmd,
+		}
+		closeChannelCmd = &cobra.Command{
+			Use:   "close <channelID>",
+			Short: "Create a transaction closing the channel cooperatively",
+			Long: `Create a transaction closing the channel cooperatively,
+according to the latest commitment, with the miner fee paid from the channel balance of this wallet.
+
+The returned (JSON-encoded) transaction still has to be signed by the counterparty, prior to sending.`,
+			Args: cobra.ExactArgs(1),
+			Run:  walletCmd.closeChannelCmd,
+		}
+		forceCloseChannelCmd = &cobra.Command{
+			Use:   "forceclose <channelID>",
+			Short: "Close the channel unilaterally, using the latest commitment",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.forceCloseChannelCmd,
+		}
+		settleChannelCmd = &cobra.Command{
+			Use:   "settle <channelID>",
+			Short: "Settle a unilaterally closed channel, once its dispute period is over",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.settleChannelCmd,
+		}
+	)
+
+	channelCmd.AddCommand(
+		openChannelCmd,
+		listChannelsCmd,
+		getChannelCmd,
+		trackChannelCmd,
+		payChannelCmd,
+		signChannelCmd,
+		updateChannelCmd,
+		closeChannelCmd,
+		forceCloseChannelCmd,
+		settleChannelCmd,
+	)
+	ccli.WalletCmd.AddCommand(channelCmd)
+
+	return nil
+}
+
+type walletCmd struct {
+	cli *client.CommandLineClient
+}
+
+func (walletCmd *walletCmd) openChannelCmd(cmd *cobra.Command, args []string) {
+	var receiver types.PublicKey
+	err := receiver.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid receiver public key", err)
+	}
+	capacity, err := walletCmd.cli.CreateCurrencyConvertor().ParseCoinString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid capacity", err)
+	}
+	disputePeriod, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid dispute period", err)
+	}
+
+	var resp api.PostOpenChannelResponse
+	walletCmd.post("/wallet/paymentchannels", api.PostOpenChannel{
+		Receiver:      receiver,
+		Capacity:      capacity,
+		DisputePeriod: types.BlockHeight(disputePeriod),
+	}, &resp, "failed to open payment channel")
+	fmt.Println("opened payment channel", resp.ChannelID.String())
+	fmt.Println("transaction:", resp.TransactionID.String())
+}
+
+func (walletCmd *walletCmd) listChannelsCmd(*cobra.Command, []string) {
+	var resp api.GetManagedChannels
+	err := walletCmd.cli.GetWithResponse("/wallet/paymentchannels", &resp)
+	if err != nil {
+		cli.DieWithError("failed to list the payment channels of this wallet", err)
+	}
+	if len(resp.Channels) == 0 {
+		fmt.Println("This wallet does not manage any payment channels.")
+		return
+	}
+
+	currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tPARTY\tSTATUS\tSEQUENCE\tBALANCE\tCAPACITY")
+	for _, mc := range resp.Channels {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			mc.Channel.ID.String(), mc.Party.String(), mc.Channel.Status.String(),
+			mc.Latest.Commitment.Sequence,
+			currencyConvertor.ToCoinStringWithUnit(mc.Latest.Commitment.Balance(mc.Party)),
+			currencyConvertor.ToCoinStringWithUnit(mc.Channel.Capacity))
+	}
+	w.Flush()
+}
+
+func (walletCmd *walletCmd) getChannelCmd(cmd *cobra.Command, args []string) {
+	id := parseChannelID(cmd, args[0])
+	var resp api.GetManagedChannel
+	err := walletCmd.cli.GetWithResponse("/wallet/paymentchannels/"+id.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get payment channel", err)
+	}
+	encodeJSON(resp.Channel)
+}
+
+func (walletCmd *walletCmd) trackChannelCmd(cmd *cobra.Command, args []string) {
+	id := parseChannelID(cmd, args[0])
+	walletCmd.post("/wallet/paymentchannels/"+id.String()+"/track", nil, nil, "failed to track payment channel")
+	fmt.Println("payment channel", id.String(), "is now managed by this wallet")
+}
+
+func (walletCmd *walletCmd) payChannelCmd(cmd *cobra.Command, args []string) {
+	id := parseChannelID(cmd, args[0])
+	amount, err := walletCmd.cli.CreateCurrencyConvertor().ParseCoinString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid amount", err)
+	}
+	var resp api.PostChannelCommitment
+	walletCmd.post("/wallet/paymentchannels/"+id.String()+"/pay", api.PostChannelPayment{
+		Amount: amount,
+	}, &resp, "failed to create payment")
+	encodeJSON(resp.Commitment)
+}
+
+func (walletCmd *walletCmd) signChannelCmd(cmd *cobra.Command, args []string) {
+	commitment := parseCommitment(cmd, args[0])
+	var resp api.PostChannelCommitment
+	walletCmd.post("/wallet/paymentchannels/"+commitment.Commitment.ChannelID.String()+"/sign", api.PostChannelCommitment{
+		Commitment: commitment,
+	}, &resp, "failed to sign commitment")
+	encodeJSON(resp.Commitment)
+}
+
+func (walletCmd *walletCmd) updateChannelCmd(cmd *cobra.Command, args []string) {
+	commitment := parseCommitment(cmd, args[0])
+	walletCmd.post("/wallet/paymentchannels/"+commitment.Commitment.ChannelID.String()+"/update", api.PostChannelCommitment{
+		Commitment: commitment,
+	}, nil, "failed to update payment channel")
+	fmt.Println("payment channel", commitment.Commitment.ChannelID.String(),
+		"updated to commitment", commitment.Commitment.Sequence)
+}
+
+func (walletCmd *walletCmd) closeChannelCmd(cmd *cobra.Command, args []string) {
+	id := parseChannelID(cmd, args[0])
+	var resp api.PostChannelTransaction
+	walletCmd.post("/wallet/paymentchannels/"+id.String()+"/close", nil, &resp, "failed to close payment channel")
+	encodeJSON(resp.Transaction)
+}
+
+func (walletCmd *walletCmd) forceCloseChannelCmd(cmd *cobra.Command, args []string) {
+	id := parseChannelID(cmd, args[0])
+	var resp api.PostChannelTransactionID
+	walletCmd.post("/wallet/paymentchannels/"+id.String()+"/forceclose", nil, &resp, "failed to close payment channel")
+	fmt.Println(resp.TransactionID.String())
+}
+
+func (walletCmd *walletCmd) settleChannelCmd(cmd *cobra.Command, args []string) {
+	id := parseChannelID(cmd, args[0])
+	var resp api.PostChannelTransactionID
+	walletCmd.post("/wallet/paymentchannels/"+id.String()+"/settle", nil, &resp, "failed to settle payment channel")
+	fmt.Println(resp.TransactionID.String())
+}
+
+// post sends the JSON-encoded body to the given endpoint,
+// decoding the response in resp, if defined.
+func (walletCmd *walletCmd) post(endpoint string, body, resp interface{}, errMsg string) {
+	var data string
+	if body != nil {
+		bytes, err := json.Marshal(body)
+		if err != nil {
+			cli.DieWithError("failed to encode request body", err)
+		}
+		data = string(bytes)
+	}
+	var err error
+	if resp == nil {
+		err = walletCmd.cli.Post(endpoint, data)
+	} else {
+		err = walletCmd.cli.PostWithResponse(endpoint, data, resp)
+	}
+	if err != nil {
+		cli.DieWithError(errMsg, err)
+	}
+}
+
+func parseChannelID(cmd *cobra.Command, str string) paymentchannel.ChannelID {
+	var id paymentchannel.ChannelID
+	err := id.LoadString(str)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid payment channel ID", err)
+	}
+	return id
+}
+
+func parseCommitment(cmd *cobra.Command, str string) paymentchannel.SignedChannelCommitment {
+	var commitment paymentchannel.SignedChannelCommitment
+	err := json.Unmarshal([]byte(str), &commitment)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid JSON-encoded commitment", err)
+	}
+	return commitment
+}
+
+// encodeJSON encodes the value as JSON to the STDOUT
+func encodeJSON(value interface{}) {
+	err := json.NewEncoder(os.Stdout).Encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
@@ -0,0 +1,138 @@
+package paymentchannel
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// ChannelOpenExplorerView is the explorer view on the extension data of a ChannelOpenTransaction.
+	ChannelOpenExplorerView struct {
+		ChannelID     ChannelID              `json:"channelid"`
+		Nonce         types.TransactionNonce `json:"nonce"`
+		Sender        types.PublicKey        `json:"sender"`
+		Receiver      types.PublicKey        `json:"receiver"`
+		Capacity      types.Currency         `json:"capacity"`
+		DisputePeriod types.BlockHeight      `json:"disputeperiod"`
+	}
+
+	// ChannelCloseExplorerView is the explorer view on the extension data of a ChannelCloseTransaction.
+	ChannelCloseExplorerView struct {
+		ChannelID           ChannelID                    `json:"channelid"`
+		SenderFulfillment   types.UnlockFulfillmentProxy `json:"senderfulfillment"`
+		ReceiverFulfillment types.UnlockFulfillmentProxy `json:"receiverfulfillment"`
+	}
+
+	// ChannelUnilateralCloseExplorerView is the explorer view on the extension data of a ChannelUnilateralCloseTransaction.
+	ChannelUnilateralCloseExplorerView struct {
+		Party            ChannelParty                 `json:"party"`
+		PartyFulfillment types.UnlockFulfillmentProxy `json:"partyfulfillment"`
+		Commitment       SignedChannelCommitment      `json:"commitment"`
+	}
+
+	// ChannelSettleExplorerView is the explorer view on the extension data of a ChannelSettleTransaction.
+	ChannelSettleExplorerView struct {
+		ChannelID ChannelID `json:"channelid"`
+	}
+
+	channelOpenExplorerExtension            struct{}
+	channelCloseExplorerExtension           struct{}
+	channelUnilateralCloseExplorerExtension struct{}
+	channelSettleExplorerExtension          struct{}
+)
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (channelOpenExplorerExtension) TransactionType() string { return "channel open" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (channelOpenExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	coext, ok := txn.Extension.(*ChannelOpenTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return ChannelOpenExplorerView{
+		ChannelID:     NewChannelID(coext.Nonce, coext.Sender, coext.Receiver),
+		Nonce:         coext.Nonce,
+		Sender:        coext.Sender,
+		Receiver:      coext.Receiver,
+		Capacity:      coext.Capacity,
+		DisputePeriod: coext.DisputePeriod,
+	}, nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (channelCloseExplorerExtension) TransactionType() string { return "channel close" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (channelCloseExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	ccext, ok := txn.Extension.(*ChannelCloseTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return ChannelCloseExplorerView{
+		ChannelID:           ccext.ChannelID,
+		SenderFulfillment:   ccext.SenderFulfillment,
+		ReceiverFulfillment: ccext.ReceiverFulfillment,
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to both parties of the channel, if known.
+func (channelCloseExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	ccext, ok := txn.Extension.(*ChannelCloseTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	var uhs []types.UnlockHash
+	for _, fulfillment := range []types.UnlockFulfillmentProxy{ccext.SenderFulfillment, ccext.ReceiverFulfillment} {
+		if uh, ok := modules.FulfillmentAddress(fulfillment); ok {
+			uhs = append(uhs, uh)
+		}
+	}
+	return uhs, nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (channelUnilateralCloseExplorerExtension) TransactionType() string {
+	return "channel unilateral close"
+}
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (channelUnilateralCloseExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	ucext, ok := txn.Extension.(*ChannelUnilateralCloseTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return ChannelUnilateralCloseExplorerView{
+		Party:            ucext.Party,
+		PartyFulfillment: ucext.PartyFulfillment,
+		Commitment:       ucext.Commitment,
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the party which closed the channel, if known.
+func (channelUnilateralCloseExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	ucext, ok := txn.Extension.(*ChannelUnilateralCloseTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	if uh, ok := modules.FulfillmentAddress(ucext.PartyFulfillment); ok {
+		return []types.UnlockHash{uh}, nil
+	}
+	return nil, nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (channelSettleExplorerExtension) TransactionType() string { return "channel settle" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (channelSettleExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	csext, ok := txn.Extension.(*ChannelSettleTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return ChannelSettleExplorerView{
+		ChannelID: csext.ChannelID,
+	}, nil
+}
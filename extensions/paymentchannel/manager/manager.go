@@ -0,0 +1,578 @@
+// Package manager provides the off-chain side of payment channels:
+// it keeps track of the commitments of the channels in which the wallet is a party,
+// signs channel updates using the keys of the wallet and watches the chain
+// in order to dispute outdated unilateral closes.
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	siasync "github.com/threefoldtech/rivine/sync"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	errNilCS     = errors.New("payment channel manager cannot use a nil consensus set")
+	errNilTpool  = errors.New("payment channel manager cannot use a nil transaction pool")
+	errNilWallet = errors.New("payment channel manager cannot use a nil wallet")
+	errNilGetter = errors.New("payment channel manager cannot use a nil channel getter")
+
+	// ErrChannelNotManaged is returned in case a payment channel is not managed by the manager.
+	ErrChannelNotManaged = errors.New("payment channel is not managed by this wallet")
+)
+
+type (
+	// TransactionVersions defines the transaction versions
+	// of the payment channel transactions, as registered by the chain.
+	TransactionVersions struct {
+		Open            types.TransactionVersion
+		Close           types.TransactionVersion
+		UnilateralClose types.TransactionVersion
+		Settle          types.TransactionVersion
+	}
+
+	// ManagedChannel is a payment channel in which the wallet is a party,
+	// together with the off-chain commitments known to the wallet.
+	ManagedChannel struct {
+		// Channel is the on-chain state of the payment channel.
+		Channel paymentchannel.Channel `json:"channel"`
+		// Party is the party of the channel owned by the wallet.
+		Party paymentchannel.ChannelParty `json:"party"`
+		// Latest is the most recent commitment signed by both parties.
+		Latest paymentchannel.SignedChannelCommitment `json:"latest"`
+		// Proposal is the most recent payment signed by the wallet,
+		// still awaiting the signature of the counterparty.
+		Proposal *paymentchannel.SignedChannelCommitment `json:"proposal,omitempty"`
+	}
+
+	// managedChannel is the persisted (off-chain) state of a managed channel.
+	managedChannel struct {
+		ID       paymentchannel.ChannelID                `json:"id"`
+		Party    paymentchannel.ChannelParty             `json:"party"`
+		Latest   paymentchannel.SignedChannelCommitment  `json:"latest"`
+		Proposal *paymentchannel.SignedChannelCommitment `json:"proposal,omitempty"`
+	}
+
+	// Manager manages the payment channels in which the wallet is a party.
+	Manager struct {
+		cs       modules.ConsensusSet
+		tpool    modules.TransactionPool
+		wallet   modules.Wallet
+		getter   paymentchannel.ChannelGetter
+		versions TransactionVersions
+		minerFee types.Currency
+
+		persistDir string
+		bcInfo     types.BlockchainInfo
+		log        *persist.Logger
+
+		// channels contains the off-chain state of all managed channels,
+		// while disputed tracks the commitment sequence last submitted to dispute a channel.
+		channels map[paymentchannel.ChannelID]*managedChannel
+		disputed map[paymentchannel.ChannelID]uint64
+		mu       sync.Mutex
+
+		changes chan struct{}
+		tg      siasync.ThreadGroup
+	}
+)
+
+// New creates a new payment channel manager, loading the persisted channels,
+// and subscribing to consensus in order to watch the managed channels.
+func New(cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, getter paymentchannel.ChannelGetter, versions TransactionVersions, minerFee types.Currency, persistDir string, bcInfo types.BlockchainInfo, verboseLogging bool) (*Manager, error) {
+	// Check that input modules are non-nil
+	if cs == nil {
+		return nil, errNilCS
+	}
+	if tpool == nil {
+		return nil, errNilTpool
+	}
+	if wallet == nil {
+		return nil, errNilWallet
+	}
+	if getter == nil {
+		return nil, errNilGetter
+	}
+
+	m := &Manager{
+		cs:         cs,
+		tpool:      tpool,
+		wallet:     wallet,
+		getter:     getter,
+		versions:   versions,
+		minerFee:   minerFee,
+		persistDir: persistDir,
+		bcInfo:     bcInfo,
+		channels:   make(map[paymentchannel.ChannelID]*managedChannel),
+		disputed:   make(map[paymentchannel.ChannelID]uint64),
+		changes:    make(chan struct{}, 1),
+	}
+
+	// Initialize the persistent structures.
+	err := m.initPersist(verboseLogging)
+	if err != nil {
+		return nil, err
+	}
+
+	// watch the managed channels in a separate thread,
+	// as disputing requires the consensus set, wallet and transaction pool
+	go m.threadedWatchChannels()
+
+	err = cs.ConsensusSetSubscribe(m, modules.ConsensusChangeRecent, nil)
+	if err != nil {
+		return nil, errors.New("payment channel manager subscription failed: " + err.Error())
+	}
+	return m, nil
+}
+
+// Close unsubscribes the manager from consensus and stops watching the managed channels.
+func (m *Manager) Close() error {
+	m.cs.Unsubscribe(m)
+	err := m.tg.Stop()
+	if err != nil {
+		return err
+	}
+	if m.log != nil {
+		err = m.log.Close()
+		if err != nil {
+			// State of the logger is unknown, a println will suffice.
+			fmt.Println("Error shutting down payment channel manager logger:", err)
+		}
+	}
+	return nil
+}
+
+// Open opens a new payment channel with the given receiver,
+// funding its capacity (and the miner fee) using the coins of the wallet.
+func (m *Manager) Open(receiver types.PublicKey, capacity types.Currency, disputePeriod types.BlockHeight) (paymentchannel.ChannelID, types.TransactionID, error) {
+	if err := m.tg.Add(); err != nil {
+		return paymentchannel.ChannelID{}, types.TransactionID{}, err
+	}
+	defer m.tg.Done()
+
+	addr, err := m.wallet.NextAddress()
+	if err != nil {
+		return paymentchannel.ChannelID{}, types.TransactionID{}, fmt.Errorf("failed to get a new address from the wallet: %v", err)
+	}
+	sender, _, err := m.wallet.GetKey(addr)
+	if err != nil {
+		return paymentchannel.ChannelID{}, types.TransactionID{}, fmt.Errorf("failed to get the key of address %s: %v", addr.String(), err)
+	}
+
+	tx := paymentchannel.ChannelOpenTransaction{
+		Nonce:         types.RandomTransactionNonce(),
+		Sender:        sender,
+		Receiver:      receiver,
+		Capacity:      capacity,
+		DisputePeriod: disputePeriod,
+		MinerFees:     []types.Currency{m.minerFee},
+	}
+	tx.CoinInputs, tx.CoinOutputs, err = m.fundCoins(capacity.Add(m.minerFee))
+	if err != nil {
+		return paymentchannel.ChannelID{}, types.TransactionID{}, err
+	}
+	txID, err := m.signAndSend(tx.Transaction(m.versions.Open))
+	if err != nil {
+		return paymentchannel.ChannelID{}, types.TransactionID{}, err
+	}
+
+	// start tracking the channel right away, as the sender owns the initial commitment
+	id := tx.ChannelID()
+	ch := paymentchannel.Channel{
+		ID:       id,
+		Sender:   sender,
+		Receiver: receiver,
+		Capacity: capacity,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channels[id] = &managedChannel{
+		ID:     id,
+		Party:  paymentchannel.ChannelPartySender,
+		Latest: paymentchannel.SignedChannelCommitment{Commitment: ch.InitialCommitment()},
+	}
+	err = m.saveSync()
+	if err != nil {
+		return paymentchannel.ChannelID{}, types.TransactionID{}, err
+	}
+	m.log.Printf("opened payment channel %s with a capacity of %s in tx %s", id.String(), capacity.String(), txID.String())
+	return id, txID, nil
+}
+
+// Track starts managing an (open) payment channel,
+// in which the wallet owns either the sender or receiver key.
+func (m *Manager) Track(id paymentchannel.ChannelID) error {
+	ch, err := m.getter.GetChannel(id)
+	if err != nil {
+		return err
+	}
+	if ch.Status != paymentchannel.ChannelStatusOpen {
+		return fmt.Errorf("payment channel %s is %s", id.String(), ch.Status.String())
+	}
+	var party paymentchannel.ChannelParty
+	for _, candidate := range []paymentchannel.ChannelParty{paymentchannel.ChannelPartySender, paymentchannel.ChannelPartyReceiver} {
+		if _, _, err := m.wallet.GetKey(ch.UnlockHash(candidate)); err == nil {
+			party = candidate
+			break
+		}
+	}
+	if party == 0 {
+		return fmt.Errorf("wallet owns neither the sender nor the receiver key of payment channel %s", id.String())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.channels[id]; ok {
+		return nil // already managed
+	}
+	m.channels[id] = &managedChannel{
+		ID:     id,
+		Party:  party,
+		Latest: paymentchannel.SignedChannelCommitment{Commitment: ch.InitialCommitment()},
+	}
+	return m.saveSync()
+}
+
+// Channels returns all managed payment channels.
+func (m *Manager) Channels() ([]ManagedChannel, error) {
+	m.mu.Lock()
+	ids := make([]paymentchannel.ChannelID, 0, len(m.channels))
+	for id := range m.channels {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	channels := make([]ManagedChannel, 0, len(ids))
+	for _, id := range ids {
+		mc, err := m.Channel(id)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, mc)
+	}
+	return channels, nil
+}
+
+// Channel returns a managed payment channel.
+func (m *Manager) Channel(id paymentchannel.ChannelID) (ManagedChannel, error) {
+	m.mu.Lock()
+	mc, ok := m.channels[id]
+	if !ok {
+		m.mu.Unlock()
+		return ManagedChannel{}, ErrChannelNotManaged
+	}
+	result := ManagedChannel{
+		Party:    mc.Party,
+		Latest:   mc.Latest,
+		Proposal: mc.Proposal,
+	}
+	m.mu.Unlock()
+
+	ch, err := m.getter.GetChannel(id)
+	if err != nil {
+		return ManagedChannel{}, err
+	}
+	result.Channel = ch
+	return result, nil
+}
+
+// Pay creates a new commitment, paying the given amount to the counterparty of the channel,
+// signed by the wallet. The returned commitment has to be signed by the counterparty,
+// which returns the commitment signed by both parties to be stored using Update.
+//
+// Payments can be pipelined, as each payment builds on the most recent proposal.
+func (m *Manager) Pay(id paymentchannel.ChannelID, amount types.Currency) (paymentchannel.SignedChannelCommitment, error) {
+	if amount.IsZero() {
+		return paymentchannel.SignedChannelCommitment{}, errors.New("cannot pay a zero amount")
+	}
+	ch, err := m.getter.GetChannel(id)
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, err
+	}
+	if ch.Status != paymentchannel.ChannelStatusOpen {
+		return paymentchannel.SignedChannelCommitment{}, fmt.Errorf("payment channel %s is %s", id.String(), ch.Status.String())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mc, ok := m.channels[id]
+	if !ok {
+		return paymentchannel.SignedChannelCommitment{}, ErrChannelNotManaged
+	}
+	base := mc.Latest.Commitment
+	if mc.Proposal != nil {
+		base = mc.Proposal.Commitment
+	}
+	if len(base.HTLCs) != 0 {
+		return paymentchannel.SignedChannelCommitment{}, errors.New("payments on top of pending HTLCs are not supported")
+	}
+	if base.Balance(mc.Party).Cmp(amount) < 0 {
+		return paymentchannel.SignedChannelCommitment{}, fmt.Errorf(
+			"insufficient channel balance: %s < %s", base.Balance(mc.Party).String(), amount.String())
+	}
+
+	commitment := paymentchannel.ChannelCommitment{
+		ChannelID: id,
+		Sequence:  base.Sequence + 1,
+	}
+	if mc.Party == paymentchannel.ChannelPartySender {
+		commitment.SenderBalance = base.SenderBalance.Sub(amount)
+		commitment.ReceiverBalance = base.ReceiverBalance.Add(amount)
+	} else {
+		commitment.SenderBalance = base.SenderBalance.Add(amount)
+		commitment.ReceiverBalance = base.ReceiverBalance.Sub(amount)
+	}
+	proposal := paymentchannel.SignedChannelCommitment{Commitment: commitment}
+	err = m.signCommitment(ch, mc.Party, &proposal)
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, err
+	}
+	mc.Proposal = &proposal
+	err = m.saveSync()
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, err
+	}
+	return proposal, nil
+}
+
+// Sign signs a commitment proposed (and signed) by the counterparty,
+// storing it as the latest commitment of the channel.
+// Only commitments which do not decrease the balance of the wallet are signed.
+func (m *Manager) Sign(proposal paymentchannel.SignedChannelCommitment) (paymentchannel.SignedChannelCommitment, error) {
+	id := proposal.Commitment.ChannelID
+	ch, err := m.getter.GetChannel(id)
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, err
+	}
+	if ch.Status != paymentchannel.ChannelStatusOpen {
+		return paymentchannel.SignedChannelCommitment{}, fmt.Errorf("payment channel %s is %s", id.String(), ch.Status.String())
+	}
+	err = proposal.Commitment.ValidateBalances(ch.Capacity)
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, err
+	}
+	if len(proposal.Commitment.HTLCs) != 0 {
+		return paymentchannel.SignedChannelCommitment{}, errors.New("commitments with pending HTLCs are not supported")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mc, ok := m.channels[id]
+	if !ok {
+		return paymentchannel.SignedChannelCommitment{}, ErrChannelNotManaged
+	}
+	counterparty := mc.Party.Counterparty()
+	err = paymentchannel.VerifyCommitmentSignature(
+		ch.PublicKey(counterparty), proposal.Commitment.Hash(), proposal.Signature(counterparty))
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, fmt.Errorf("invalid %s signature: %v", counterparty.String(), err)
+	}
+	if proposal.Commitment.Sequence <= mc.Latest.Commitment.Sequence {
+		return paymentchannel.SignedChannelCommitment{}, fmt.Errorf(
+			"commitment sequence %d is not more recent than the latest commitment sequence %d",
+			proposal.Commitment.Sequence, mc.Latest.Commitment.Sequence)
+	}
+	if proposal.Commitment.Balance(mc.Party).Cmp(mc.Latest.Commitment.Balance(mc.Party)) < 0 {
+		return paymentchannel.SignedChannelCommitment{}, errors.New("commitment decreases the channel balance of this wallet")
+	}
+
+	err = m.signCommitment(ch, mc.Party, &proposal)
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, err
+	}
+	mc.Latest = proposal
+	err = m.saveSync()
+	if err != nil {
+		return paymentchannel.SignedChannelCommitment{}, err
+	}
+	return proposal, nil
+}
+
+// Update stores a commitment signed by both parties as the latest commitment of the channel,
+// in case it is more recent than the latest commitment known to the wallet.
+func (m *Manager) Update(commitment paymentchannel.SignedChannelCommitment) error {
+	id := commitment.Commitment.ChannelID
+	ch, err := m.getter.GetChannel(id)
+	if err != nil {
+		return err
+	}
+	err = commitment.Verify(ch)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mc, ok := m.channels[id]
+	if !ok {
+		return ErrChannelNotManaged
+	}
+	if commitment.Commitment.Sequence <= mc.Latest.Commitment.Sequence {
+		return nil // nothing to do
+	}
+	mc.Latest = commitment
+	if mc.Proposal != nil && mc.Proposal.Commitment.Sequence <= commitment.Commitment.Sequence {
+		mc.Proposal = nil
+	}
+	return m.saveSync()
+}
+
+// CooperativeClose creates a transaction closing the channel according to the latest commitment,
+// signed by the wallet. The miner fee is paid using the channel balance of the wallet.
+// The returned transaction still has to be signed by the counterparty, prior to sending it.
+func (m *Manager) CooperativeClose(id paymentchannel.ChannelID) (types.Transaction, error) {
+	ch, latest, party, err := m.channelState(id)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if ch.Status == paymentchannel.ChannelStatusClosed {
+		return types.Transaction{}, fmt.Errorf("payment channel %s is already closed", id.String())
+	}
+	if len(latest.Commitment.HTLCs) != 0 {
+		return types.Transaction{}, errors.New("cannot close a payment channel cooperatively with pending HTLCs")
+	}
+	if latest.Commitment.Balance(party).Cmp(m.minerFee) <= 0 {
+		return types.Transaction{}, errors.New("insufficient channel balance to pay the miner fee")
+	}
+
+	tx := paymentchannel.ChannelCloseTransaction{
+		ChannelID: id,
+		MinerFees: []types.Currency{m.minerFee},
+	}
+	for _, co := range ch.PayoutCoinOutputs(latest.Commitment) {
+		if co.Condition.Equal(ch.Condition(party)) {
+			co.Value = co.Value.Sub(m.minerFee)
+		}
+		tx.CoinOutputs = append(tx.CoinOutputs, co)
+	}
+	return m.wallet.GreedySign(tx.Transaction(m.versions.Close))
+}
+
+// UnilateralClose closes the channel using the latest commitment known to the wallet,
+// starting (or disputing) the dispute period of the channel.
+func (m *Manager) UnilateralClose(id paymentchannel.ChannelID) (types.TransactionID, error) {
+	if err := m.tg.Add(); err != nil {
+		return types.TransactionID{}, err
+	}
+	defer m.tg.Done()
+	ch, latest, party, err := m.channelState(id)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	if ch.Status == paymentchannel.ChannelStatusClosed {
+		return types.TransactionID{}, fmt.Errorf("payment channel %s is already closed", id.String())
+	}
+	if ch.Status == paymentchannel.ChannelStatusClosing && ch.Commitment.Sequence >= latest.Commitment.Sequence {
+		return types.TransactionID{}, fmt.Errorf("payment channel %s is already closing using commitment %d", id.String(), ch.Commitment.Sequence)
+	}
+	return m.submitCommitment(party, latest)
+}
+
+// Settle settles a unilaterally closed channel, once its dispute period is over.
+func (m *Manager) Settle(id paymentchannel.ChannelID) (types.TransactionID, error) {
+	if err := m.tg.Add(); err != nil {
+		return types.TransactionID{}, err
+	}
+	defer m.tg.Done()
+	ch, _, _, err := m.channelState(id)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	if ch.Status != paymentchannel.ChannelStatusClosing {
+		return types.TransactionID{}, fmt.Errorf("payment channel %s is %s", id.String(), ch.Status.String())
+	}
+	if height := m.cs.Height(); height < ch.DisputeDeadline {
+		return types.TransactionID{}, fmt.Errorf(
+			"payment channel %s cannot be settled until block height %d (current height: %d)",
+			id.String(), ch.DisputeDeadline, height)
+	}
+
+	tx := paymentchannel.ChannelSettleTransaction{
+		ChannelID:   id,
+		CoinOutputs: ch.PayoutCoinOutputs(ch.Commitment),
+		MinerFees:   []types.Currency{m.minerFee},
+	}
+	coinInputs, refundCoinOutputs, err := m.fundCoins(m.minerFee)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	tx.CoinInputs = coinInputs
+	tx.CoinOutputs = append(tx.CoinOutputs, refundCoinOutputs...)
+	return m.signAndSend(tx.Transaction(m.versions.Settle))
+}
+
+// channelState returns the on-chain state of a managed channel,
+// together with the latest commitment and the party owned by the wallet.
+func (m *Manager) channelState(id paymentchannel.ChannelID) (paymentchannel.Channel, paymentchannel.SignedChannelCommitment, paymentchannel.ChannelParty, error) {
+	m.mu.Lock()
+	mc, ok := m.channels[id]
+	if !ok {
+		m.mu.Unlock()
+		return paymentchannel.Channel{}, paymentchannel.SignedChannelCommitment{}, 0, ErrChannelNotManaged
+	}
+	latest, party := mc.Latest, mc.Party
+	m.mu.Unlock()
+
+	ch, err := m.getter.GetChannel(id)
+	if err != nil {
+		return paymentchannel.Channel{}, paymentchannel.SignedChannelCommitment{}, 0, err
+	}
+	return ch, latest, party, nil
+}
+
+// submitCommitment submits the given commitment on-chain,
+// using a unilateral close transaction funded by the wallet.
+func (m *Manager) submitCommitment(party paymentchannel.ChannelParty, commitment paymentchannel.SignedChannelCommitment) (types.TransactionID, error) {
+	tx := paymentchannel.ChannelUnilateralCloseTransaction{
+		Party:      party,
+		Commitment: commitment,
+		MinerFees:  []types.Currency{m.minerFee},
+	}
+	var err error
+	tx.CoinInputs, tx.CoinOutputs, err = m.fundCoins(m.minerFee)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	return m.signAndSend(tx.Transaction(m.versions.UnilateralClose))
+}
+
+// signCommitment signs the commitment as the given party, using the key of the wallet.
+func (m *Manager) signCommitment(ch paymentchannel.Channel, party paymentchannel.ChannelParty, commitment *paymentchannel.SignedChannelCommitment) error {
+	_, sk, err := m.wallet.GetKey(ch.UnlockHash(party))
+	if err != nil {
+		return fmt.Errorf("failed to get the %s key of payment channel %s: %v", party.String(), ch.ID.String(), err)
+	}
+	return commitment.Sign(ch, party, sk)
+}
+
+// fundCoins funds the given amount using the coins of the wallet,
+// returning the coin inputs and the optional refund coin output.
+func (m *Manager) fundCoins(amount types.Currency) ([]types.CoinInput, []types.CoinOutput, error) {
+	txbuilder := m.wallet.StartTransaction()
+	// drop the builder, as it is only used to select the coin inputs
+	defer txbuilder.Drop()
+	err := txbuilder.FundCoins(amount, nil, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fund %s coins: %v", amount.String(), err)
+	}
+	txn, _ := txbuilder.View()
+	return txn.CoinInputs, txn.CoinOutputs, nil
+}
+
+// signAndSend signs the transaction using the wallet and sends it to the transaction pool.
+func (m *Manager) signAndSend(tx types.Transaction) (types.TransactionID, error) {
+	tx, err := m.wallet.GreedySign(tx)
+	if err != nil {
+		return types.TransactionID{}, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	err = m.tpool.AcceptTransactionSet([]types.Transaction{tx})
+	if err != nil {
+		return types.TransactionID{}, fmt.Errorf("failed to send transaction: %v", err)
+	}
+	return tx.ID(), nil
+}
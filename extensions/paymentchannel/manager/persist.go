@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/threefoldtech/rivine/persist"
+)
+
+const (
+	// PersistDir is the name of the directory, relative to the wallet directory,
+	// used by the manager to persist the managed payment channels.
+	PersistDir = "paymentchannels"
+
+	logFile     = "paymentchannels.log"
+	persistFile = "paymentchannels.json"
+)
+
+var persistMetadata = persist.Metadata{
+	Header:  "Payment Channel Manager",
+	Version: "1.0.0",
+}
+
+// persistence is the data that is kept when the manager is restarted.
+type persistence struct {
+	Channels []managedChannel `json:"channels"`
+}
+
+// initPersist initializes the persistent structures of the manager.
+func (m *Manager) initPersist(verbose bool) error {
+	// Make the persist directory
+	err := os.MkdirAll(m.persistDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	// Initialize the logger.
+	m.log, err = persist.NewFileLogger(m.bcInfo, filepath.Join(m.persistDir, logFile), verbose)
+	if err != nil {
+		return err
+	}
+
+	// Load the managed channels, if any were persisted before.
+	var data persistence
+	err = persist.LoadJSON(persistMetadata, &data, filepath.Join(m.persistDir, persistFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for idx := range data.Channels {
+		mc := data.Channels[idx]
+		m.channels[mc.ID] = &mc
+	}
+	return nil
+}
+
+// saveSync persists the managed channels,
+// it is expected that the caller holds the lock of the manager.
+func (m *Manager) saveSync() error {
+	data := persistence{
+		Channels: make([]managedChannel, 0, len(m.channels)),
+	}
+	for _, mc := range m.channels {
+		data.Channels = append(data.Channels, *mc)
+	}
+	err := persist.SaveJSON(persistMetadata, data, filepath.Join(m.persistDir, persistFile))
+	if err != nil {
+		m.log.Printf("failed to persist the managed payment channels: %v", err)
+	}
+	return err
+}
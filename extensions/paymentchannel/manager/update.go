@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"github.com/threefoldtech/rivine/extensions/paymentchannel"
+	"github.com/threefoldtech/rivine/modules"
+)
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber,
+// signaling the watcher that the managed channels have to be checked.
+//
+// The channels are not checked in this call, as a dispute requires
+// the consensus set, which is locked while a consensus change is processed.
+func (m *Manager) ProcessConsensusChange(cc modules.ConsensusChange) {
+	if len(cc.AppliedBlocks) == 0 {
+		return
+	}
+	select {
+	case m.changes <- struct{}{}:
+	default:
+		// a check is already pending
+	}
+}
+
+// threadedWatchChannels checks the managed channels after each consensus change,
+// until the manager is closed.
+func (m *Manager) threadedWatchChannels() {
+	if err := m.tg.Add(); err != nil {
+		return
+	}
+	defer m.tg.Done()
+	for {
+		select {
+		case <-m.tg.StopChan():
+			return
+		case <-m.changes:
+			m.checkChannels()
+		}
+	}
+}
+
+// checkChannels disputes all managed channels which are being closed
+// using a commitment older than the latest commitment known to the wallet.
+// Channels which are closed on-chain are no longer managed.
+func (m *Manager) checkChannels() {
+	height := m.cs.Height()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var changed bool
+	for id, mc := range m.channels {
+		ch, err := m.getter.GetChannel(id)
+		if err != nil {
+			if err != paymentchannel.ErrChannelNotFound {
+				m.log.Printf("failed to get payment channel %s: %v", id.String(), err)
+			}
+			// the open transaction might not have been confirmed yet
+			continue
+		}
+		switch ch.Status {
+		case paymentchannel.ChannelStatusClosed:
+			m.log.Printf("payment channel %s is closed and is no longer managed", id.String())
+			delete(m.channels, id)
+			delete(m.disputed, id)
+			changed = true
+
+		case paymentchannel.ChannelStatusClosing:
+			if ch.Commitment.Sequence >= mc.Latest.Commitment.Sequence || height >= ch.DisputeDeadline {
+				continue
+			}
+			if m.disputed[id] >= mc.Latest.Commitment.Sequence {
+				continue // dispute already submitted
+			}
+			txID, err := m.submitCommitment(mc.Party, mc.Latest)
+			if err != nil {
+				m.log.Printf("failed to dispute payment channel %s closed using commitment %d: %v",
+					id.String(), ch.Commitment.Sequence, err)
+				continue
+			}
+			m.disputed[id] = mc.Latest.Commitment.Sequence
+			m.log.Printf("disputed payment channel %s closed using commitment %d with commitment %d in tx %s",
+				id.String(), ch.Commitment.Sequence, mc.Latest.Commitment.Sequence, txID.String())
+		}
+	}
+	if changed {
+		m.saveSync()
+	}
+}
@@ -0,0 +1,640 @@
+package paymentchannel
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+const (
+	pluginDBVersion = "1.0.0.0"
+	pluginDBHeader  = "paymentChannelPlugin"
+)
+
+var (
+	bucketChannels         = []byte("channels")
+	bucketPreviousChannels = []byte("previouschannels")
+	bucketParties          = []byte("parties")
+)
+
+type (
+	// Plugin is a struct defines the payment channel plugin,
+	// tracking the on-chain state of all payment channels.
+	Plugin struct {
+		openTransactionVersion            types.TransactionVersion
+		closeTransactionVersion           types.TransactionVersion
+		unilateralCloseTransactionVersion types.TransactionVersion
+		settleTransactionVersion          types.TransactionVersion
+		storage                           modules.PluginViewStorage
+		unregisterCallback                modules.PluginUnregisterCallback
+	}
+)
+
+// NewPlugin creates a new Plugin and registers the payment channel transaction versions.
+func NewPlugin(openTransactionVersion, closeTransactionVersion, unilateralCloseTransactionVersion, settleTransactionVersion types.TransactionVersion) *Plugin {
+	p := &Plugin{
+		openTransactionVersion:            openTransactionVersion,
+		closeTransactionVersion:           closeTransactionVersion,
+		unilateralCloseTransactionVersion: unilateralCloseTransactionVersion,
+		settleTransactionVersion:          settleTransactionVersion,
+	}
+	types.RegisterTransactionVersion(openTransactionVersion, ChannelOpenTransactionController{
+		TransactionVersion: openTransactionVersion,
+	})
+	types.RegisterTransactionVersion(closeTransactionVersion, ChannelCloseTransactionController{
+		ChannelGetter:      p,
+		TransactionVersion: closeTransactionVersion,
+	})
+	types.RegisterTransactionVersion(unilateralCloseTransactionVersion, ChannelUnilateralCloseTransactionController{
+		ChannelGetter:      p,
+		TransactionVersion: unilateralCloseTransactionVersion,
+	})
+	types.RegisterTransactionVersion(settleTransactionVersion, ChannelSettleTransactionController{
+		TransactionVersion: settleTransactionVersion,
+	})
+	modules.RegisterExplorerTransactionExtension(openTransactionVersion, channelOpenExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(closeTransactionVersion, channelCloseExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(unilateralCloseTransactionVersion, channelUnilateralCloseExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(settleTransactionVersion, channelSettleExplorerExtension{})
+	return p
+}
+
+// InitPlugin initializes the Bucket for the first time
+func (p *Plugin) InitPlugin(metadata *persist.Metadata, bucket *bolt.Bucket, storage modules.PluginViewStorage, unregisterCallback modules.PluginUnregisterCallback) (persist.Metadata, error) {
+	p.storage = storage
+	p.unregisterCallback = unregisterCallback
+	if metadata == nil {
+		for _, name := range [][]byte{bucketChannels, bucketPreviousChannels, bucketParties} {
+			_, err := bucket.CreateBucketIfNotExists(name)
+			if err != nil {
+				return persist.Metadata{}, fmt.Errorf("failed to create %s bucket: %v", string(name), err)
+			}
+		}
+		metadata = &persist.Metadata{
+			Version: pluginDBVersion,
+			Header:  pluginDBHeader,
+		}
+	} else if metadata.Version != pluginDBVersion {
+		return persist.Metadata{}, errors.New("There is only 1 version of this plugin, version mismatch")
+	} else if metadata.Header != pluginDBHeader {
+		return persist.Metadata{}, errors.New("There is only 1 header of this plugin, header mismatch")
+	}
+	return *metadata, nil
+}
+
+// ApplyBlock applies a block's payment channel transactions to the payment channel buckets.
+func (p *Plugin) ApplyBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("payment channel bucket does not exist")
+	}
+	var err error
+	for idx, txn := range block.Transactions {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            txn,
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.ApplyTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyBlockHeader applies nothing and has no effect on this plugin.
+func (p *Plugin) ApplyBlockHeader(modules.ConsensusBlockHeader, *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// ApplyTransaction applies a payment channel transaction to the payment channel buckets.
+func (p *Plugin) ApplyTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("payment channel bucket does not exist")
+	}
+	if !p.isChannelTransactionVersion(txn.Version) {
+		return nil // not a transaction we care about
+	}
+	channelsBucket, err := bucket.Bucket(bucketChannels)
+	if err != nil {
+		return err
+	}
+	// check the version and handle the ones we care about
+	var (
+		id     ChannelID
+		update func(ch *Channel)
+	)
+	switch txn.Version {
+	case p.openTransactionVersion:
+		cotx, err := ChannelOpenTransactionFromTransaction(txn.Transaction, p.openTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the channel open tx type: %v", err)
+		}
+		partiesBucket, err := bucket.Bucket(bucketParties)
+		if err != nil {
+			return err
+		}
+		ch := Channel{
+			ID:                    cotx.ChannelID(),
+			Sender:                cotx.Sender,
+			Receiver:              cotx.Receiver,
+			Capacity:              cotx.Capacity,
+			DisputePeriod:         cotx.DisputePeriod,
+			Status:                ChannelStatusOpen,
+			CreationHeight:        txn.BlockHeight,
+			CreationTime:          txn.BlockTime,
+			CreationTransactionID: txn.ID(),
+		}
+		err = putChannelInBucket(channelsBucket, ch)
+		if err != nil {
+			return err
+		}
+		return mapChannelParties(partiesBucket, ch)
+
+	case p.closeTransactionVersion:
+		cctx, err := ChannelCloseTransactionFromTransaction(txn.Transaction, p.closeTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the channel close tx type: %v", err)
+		}
+		id = cctx.ChannelID
+		update = func(ch *Channel) {
+			ch.Status = ChannelStatusClosed
+		}
+
+	case p.unilateralCloseTransactionVersion:
+		uctx, err := ChannelUnilateralCloseTransactionFromTransaction(txn.Transaction, p.unilateralCloseTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the channel unilateral close tx type: %v", err)
+		}
+		id = uctx.ChannelID()
+		update = func(ch *Channel) {
+			ch.Commitment = uctx.Commitment.Commitment
+			// a dispute replaces the commitment, but does not extend the dispute period
+			if ch.Status == ChannelStatusOpen {
+				ch.Status = ChannelStatusClosing
+				ch.DisputeDeadline = txn.BlockHeight + ch.DisputePeriod
+			}
+		}
+
+	case p.settleTransactionVersion:
+		cstx, err := ChannelSettleTransactionFromTransaction(txn.Transaction, p.settleTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the channel settle tx type: %v", err)
+		}
+		id = cstx.ChannelID
+		update = func(ch *Channel) {
+			ch.Status = ChannelStatusClosed
+		}
+	}
+
+	previousChannelsBucket, err := bucket.Bucket(bucketPreviousChannels)
+	if err != nil {
+		return err
+	}
+	ch, err := getChannelFromBucket(channelsBucket, id)
+	if err != nil {
+		return fmt.Errorf("failed to update payment channel %s: %v", id.String(), err)
+	}
+	// store the previous channel state, such that it can be restored when reverting
+	txID := txn.ID()
+	b, err := rivbin.Marshal(ch)
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal previous state of payment channel %s: %v", id.String(), err)
+	}
+	err = previousChannelsBucket.Put(txID[:], b)
+	if err != nil {
+		return fmt.Errorf("failed to store previous state of payment channel %s: %v", id.String(), err)
+	}
+	update(&ch)
+	return putChannelInBucket(channelsBucket, ch)
+}
+
+// RevertBlock reverts a block's payment channel transactions from the payment channel buckets
+func (p *Plugin) RevertBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("payment channel bucket does not exist")
+	}
+	// revert the transactions in reverse order,
+	// as a transaction can update a channel opened or updated by a previous transaction in the same block
+	var err error
+	for idx := len(block.Transactions) - 1; idx >= 0; idx-- {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            block.Transactions[idx],
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.RevertTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlockHeader reverts nothing and has no effect on this plugin.
+func (p *Plugin) RevertBlockHeader(modules.ConsensusBlockHeader, *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// RevertTransaction reverts a payment channel transaction from the payment channel buckets.
+func (p *Plugin) RevertTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("payment channel bucket does not exist")
+	}
+	if !p.isChannelTransactionVersion(txn.Version) {
+		return nil // not a transaction we care about
+	}
+	channelsBucket, err := bucket.Bucket(bucketChannels)
+	if err != nil {
+		return err
+	}
+
+	if txn.Version == p.openTransactionVersion {
+		cotx, err := ChannelOpenTransactionFromTransaction(txn.Transaction, p.openTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the channel open tx type: %v", err)
+		}
+		partiesBucket, err := bucket.Bucket(bucketParties)
+		if err != nil {
+			return err
+		}
+		ch, err := getChannelFromBucket(channelsBucket, cotx.ChannelID())
+		if err != nil {
+			return fmt.Errorf("failed to revert opened payment channel %s: %v", cotx.ChannelID().String(), err)
+		}
+		err = channelsBucket.Delete(ch.ID[:])
+		if err != nil {
+			return fmt.Errorf("failed to delete opened payment channel %s: %v", ch.ID.String(), err)
+		}
+		return unmapChannelParties(partiesBucket, ch)
+	}
+
+	// all other channel transactions update an existing channel,
+	// and are reverted by restoring its previous state
+	previousChannelsBucket, err := bucket.Bucket(bucketPreviousChannels)
+	if err != nil {
+		return err
+	}
+	txID := txn.ID()
+	b := previousChannelsBucket.Get(txID[:])
+	if len(b) == 0 {
+		return fmt.Errorf("failed to find previous payment channel state for tx %s", txID.String())
+	}
+	var ch Channel
+	err = rivbin.Unmarshal(b, &ch)
+	if err != nil {
+		return fmt.Errorf("failed to decode previous payment channel state for tx %s: %v", txID.String(), err)
+	}
+	err = previousChannelsBucket.Delete(txID[:])
+	if err != nil {
+		return fmt.Errorf("failed to delete previous state of payment channel %s: %v", ch.ID.String(), err)
+	}
+	return putChannelInBucket(channelsBucket, ch)
+}
+
+func (p *Plugin) isChannelTransactionVersion(version types.TransactionVersion) bool {
+	return version == p.openTransactionVersion ||
+		version == p.closeTransactionVersion ||
+		version == p.unilateralCloseTransactionVersion ||
+		version == p.settleTransactionVersion
+}
+
+// GetChannel implements ChannelGetter.GetChannel
+func (p *Plugin) GetChannel(id ChannelID) (Channel, error) {
+	var ch Channel
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		channelsBucket := bucket.Bucket(bucketChannels)
+		if channelsBucket == nil {
+			return errors.New("channels bucket does not exist")
+		}
+		var err error
+		ch, err = getChannelFromBucket(channelsBucket, id)
+		return err
+	})
+	return ch, err
+}
+
+// GetChannelsOf returns all payment channels in which the given unlock hash is a party.
+func (p *Plugin) GetChannelsOf(uh types.UnlockHash) ([]Channel, error) {
+	var channels []Channel
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		channelsBucket := bucket.Bucket(bucketChannels)
+		if channelsBucket == nil {
+			return errors.New("channels bucket does not exist")
+		}
+		partiesBucket := bucket.Bucket(bucketParties)
+		if partiesBucket == nil {
+			return errors.New("parties bucket does not exist")
+		}
+		key, err := rivbin.Marshal(uh)
+		if err != nil {
+			return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+		}
+		partyBucket := partiesBucket.Bucket(key)
+		if partyBucket == nil {
+			return nil // no channels for this address
+		}
+		return partyBucket.ForEach(func(k, _ []byte) error {
+			var id ChannelID
+			copy(id[:], k)
+			ch, err := getChannelFromBucket(channelsBucket, id)
+			if err != nil {
+				return err
+			}
+			channels = append(channels, ch)
+			return nil
+		})
+	})
+	return channels, err
+}
+
+// TransactionValidatorVersionFunctionMapping returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidatorVersionFunctionMapping() map[types.TransactionVersion][]modules.PluginTransactionValidationFunction {
+	return map[types.TransactionVersion][]modules.PluginTransactionValidationFunction{
+		p.openTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateChannelOpenTx,
+		},
+		p.closeTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateChannelCloseTx,
+		},
+		p.unilateralCloseTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateChannelUnilateralCloseTx,
+		},
+		p.settleTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateChannelSettleTx,
+		},
+	}
+}
+
+// TransactionValidators returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidators() []modules.PluginTransactionValidationFunction {
+	return nil
+}
+
+func (p *Plugin) validateChannelOpenTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	cotx, err := ChannelOpenTransactionFromTransaction(tx.Transaction, p.openTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a channel open tx: %v", err)
+	}
+
+	// ensure the Nonce is not Nil
+	if cotx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for a channel open transaction")
+	}
+	err = validatePartyPublicKey(cotx.Sender, ctx.BlockHeight)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %v", err)
+	}
+	err = validatePartyPublicKey(cotx.Receiver, ctx.BlockHeight)
+	if err != nil {
+		return fmt.Errorf("invalid receiver: %v", err)
+	}
+	if cotx.Sender.Algorithm == cotx.Receiver.Algorithm && string(cotx.Sender.Key) == string(cotx.Receiver.Key) {
+		return errors.New("the sender and receiver of a payment channel have to be different")
+	}
+	if cotx.Capacity.IsZero() {
+		return errors.New("a payment channel requires a non-zero capacity")
+	}
+	if cotx.DisputePeriod < MinDisputePeriod {
+		return fmt.Errorf("dispute period is too short: %d < %d", cotx.DisputePeriod, MinDisputePeriod)
+	}
+
+	// ensure the channel is unique
+	channelsBucket, err := bucket.Bucket(bucketChannels)
+	if err != nil {
+		return err
+	}
+	id := cotx.ChannelID()
+	if channelsBucket.Get(id[:]) != nil {
+		return fmt.Errorf("payment channel %s was already opened", id.String())
+	}
+
+	// the capacity is locked in the channel
+	return validateCoinBalance(tx, ctx, types.Currency{}, cotx.Capacity)
+}
+
+// channelFulfillContext returns the context in which the conditions of the channel are fulfilled
+// by the given transaction, the conditions being created by the transaction which opened the channel.
+func channelFulfillContext(ch Channel, tx modules.ConsensusTransaction, ctx types.TransactionValidationContext) types.FulfillContext {
+	return types.FulfillContext{
+		BlockHeight:          ctx.BlockHeight,
+		BlockTime:            ctx.BlockTime,
+		OutputCreationHeight: ch.CreationHeight,
+		OutputCreationTime:   ch.CreationTime,
+		HasOutputCreation:    true,
+		Transaction:          tx.Transaction,
+	}
+}
+
+func (p *Plugin) validateChannelCloseTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	cctx, err := ChannelCloseTransactionFromTransaction(tx.Transaction, p.closeTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a channel close tx: %v", err)
+	}
+	ch, err := p.getUnsettledChannel(cctx.ChannelID, bucket)
+	if err != nil {
+		return err
+	}
+	fulfillCtx := channelFulfillContext(ch, tx, ctx)
+	err = ch.Condition(ChannelPartySender).Fulfill(cctx.SenderFulfillment, fulfillCtx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill sender condition of payment channel %s: %v", ch.ID.String(), err)
+	}
+	err = ch.Condition(ChannelPartyReceiver).Fulfill(cctx.ReceiverFulfillment, fulfillCtx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill receiver condition of payment channel %s: %v", ch.ID.String(), err)
+	}
+	// the capacity is released from the channel
+	return validateCoinBalance(tx, ctx, ch.Capacity, types.Currency{})
+}
+
+func (p *Plugin) validateChannelUnilateralCloseTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	uctx, err := ChannelUnilateralCloseTransactionFromTransaction(tx.Transaction, p.unilateralCloseTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a channel unilateral close tx: %v", err)
+	}
+	if uctx.Party != ChannelPartySender && uctx.Party != ChannelPartyReceiver {
+		return fmt.Errorf("invalid channel party: %v", uctx.Party)
+	}
+	ch, err := p.getUnsettledChannel(uctx.ChannelID(), bucket)
+	if err != nil {
+		return err
+	}
+	if ch.Status == ChannelStatusClosing {
+		if ctx.BlockHeight >= ch.DisputeDeadline {
+			return fmt.Errorf("dispute period of payment channel %s is over since block height %d", ch.ID.String(), ch.DisputeDeadline)
+		}
+		if uctx.Commitment.Commitment.Sequence <= ch.Commitment.Sequence {
+			return fmt.Errorf(
+				"a dispute of payment channel %s requires a commitment more recent than sequence %d",
+				ch.ID.String(), ch.Commitment.Sequence)
+		}
+	}
+	err = uctx.Commitment.Verify(ch)
+	if err != nil {
+		return fmt.Errorf("invalid commitment for payment channel %s: %v", ch.ID.String(), err)
+	}
+	err = ch.Condition(uctx.Party).Fulfill(uctx.PartyFulfillment, channelFulfillContext(ch, tx, ctx))
+	if err != nil {
+		return fmt.Errorf("failed to fulfill %s condition of payment channel %s: %v", uctx.Party.String(), ch.ID.String(), err)
+	}
+	return validateCoinBalance(tx, ctx, types.Currency{}, types.Currency{})
+}
+
+func (p *Plugin) validateChannelSettleTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	cstx, err := ChannelSettleTransactionFromTransaction(tx.Transaction, p.settleTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a channel settle tx: %v", err)
+	}
+	ch, err := p.getUnsettledChannel(cstx.ChannelID, bucket)
+	if err != nil {
+		return err
+	}
+	if ch.Status != ChannelStatusClosing {
+		return fmt.Errorf("payment channel %s has to be closed unilaterally prior to settling it", ch.ID.String())
+	}
+	if ctx.BlockHeight < ch.DisputeDeadline {
+		return fmt.Errorf("payment channel %s cannot be settled until block height %d", ch.ID.String(), ch.DisputeDeadline)
+	}
+	// the channel has to be paid out exactly as defined by the last submitted commitment
+	payouts := ch.PayoutCoinOutputs(ch.Commitment)
+	if len(cstx.CoinOutputs) < len(payouts) {
+		return fmt.Errorf("payment channel %s requires %d payout coin outputs", ch.ID.String(), len(payouts))
+	}
+	for idx, payout := range payouts {
+		co := cstx.CoinOutputs[idx]
+		if !co.Value.Equals(payout.Value) || !co.Condition.Equal(payout.Condition) {
+			return fmt.Errorf("coin output #%d does not match the expected payout of payment channel %s", idx, ch.ID.String())
+		}
+	}
+	// the capacity is released from the channel
+	return validateCoinBalance(tx, ctx, ch.Capacity, types.Currency{})
+}
+
+// getUnsettledChannel returns the channel, ensuring it wasn't closed (cooperatively) or settled already.
+func (p *Plugin) getUnsettledChannel(id ChannelID, bucket *persist.LazyBoltBucket) (Channel, error) {
+	channelsBucket, err := bucket.Bucket(bucketChannels)
+	if err != nil {
+		return Channel{}, err
+	}
+	ch, err := getChannelFromBucket(channelsBucket, id)
+	if err != nil {
+		return Channel{}, fmt.Errorf("payment channel %s: %v", id.String(), err)
+	}
+	if ch.Status == ChannelStatusClosed {
+		return Channel{}, fmt.Errorf("payment channel %s is already closed", id.String())
+	}
+	return ch, nil
+}
+
+// validateCoinBalance ensures that the coin inputs, together with the coins released from a payment channel,
+// fund exactly the coin outputs and miner fees of the transaction, together with the coins locked in a payment channel.
+func validateCoinBalance(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, released, locked types.Currency) error {
+	var coinInputSum types.Currency
+	for _, ci := range tx.CoinInputs {
+		co, ok := tx.SpentCoinOutputs[ci.ParentID]
+		if !ok {
+			return fmt.Errorf(
+				"unable to find parent ID %s as an unspent coin output in the current consensus transaction at block height %d",
+				ci.ParentID.String(), ctx.BlockHeight)
+		}
+		coinInputSum = coinInputSum.Add(co.Value)
+	}
+	funded := coinInputSum.Add(released)
+	spent := tx.CoinOutputSum().Add(locked)
+	if !funded.Equals(spent) {
+		return fmt.Errorf(
+			"coin inputs and released channel capacity (%s) and coin outputs including miner fees and locked channel capacity (%s) are not balanced for tx %s",
+			funded.String(), spent.String(), tx.ID().String())
+	}
+	return nil
+}
+
+// Close unregisters the plugin from the consensus
+func (p *Plugin) Close() error {
+	if p.storage == nil {
+		return nil
+	}
+	return p.storage.Close()
+}
+
+func getChannelFromBucket(bucket *bolt.Bucket, id ChannelID) (Channel, error) {
+	b := bucket.Get(id[:])
+	if len(b) == 0 {
+		return Channel{}, ErrChannelNotFound
+	}
+	var ch Channel
+	err := rivbin.Unmarshal(b, &ch)
+	if err != nil {
+		return Channel{}, fmt.Errorf("failed to decode payment channel %s: %v", id.String(), err)
+	}
+	return ch, nil
+}
+
+func putChannelInBucket(bucket *bolt.Bucket, ch Channel) error {
+	b, err := rivbin.Marshal(ch)
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal payment channel %s: %v", ch.ID.String(), err)
+	}
+	err = bucket.Put(ch.ID[:], b)
+	if err != nil {
+		return fmt.Errorf("failed to put payment channel %s: %v", ch.ID.String(), err)
+	}
+	return nil
+}
+
+// mapChannelParties links the channel to the unlock hashes of both its parties.
+func mapChannelParties(partiesBucket *bolt.Bucket, ch Channel) error {
+	for _, party := range []ChannelParty{ChannelPartySender, ChannelPartyReceiver} {
+		key, err := rivbin.Marshal(ch.UnlockHash(party))
+		if err != nil {
+			return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+		}
+		partyBucket, err := partiesBucket.CreateBucketIfNotExists(key)
+		if err != nil {
+			return fmt.Errorf("failed to create party bucket: %v", err)
+		}
+		err = partyBucket.Put(ch.ID[:], []byte{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmapChannelParties removes the link between the channel and the unlock hashes of both its parties.
+func unmapChannelParties(partiesBucket *bolt.Bucket, ch Channel) error {
+	for _, party := range []ChannelParty{ChannelPartySender, ChannelPartyReceiver} {
+		key, err := rivbin.Marshal(ch.UnlockHash(party))
+		if err != nil {
+			return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+		}
+		partyBucket := partiesBucket.Bucket(key)
+		if partyBucket == nil {
+			continue
+		}
+		err = partyBucket.Delete(ch.ID[:])
+		if err != nil {
+			return err
+		}
+		if k, _ := partyBucket.Cursor().First(); k == nil {
+			err = partiesBucket.DeleteBucket(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+package paymentchannel
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/extensions/internal/plugintest"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// newTestPlugin creates a payment channel plugin, using the test transaction versions,
+// initialised in a test database. The returned function closes the plugin,
+// and unregisters its transaction versions.
+func newTestPlugin(t *testing.T) (*Plugin, *plugintest.Storage, func()) {
+	plugin := NewPlugin(testOpenTxVersion, testCloseTxVersion, testUnilateralCloseTxVersion, testSettleTxVersion)
+	storage := plugintest.NewStorage(t, plugin)
+	return plugin, storage, func() {
+		plugin.Close()
+		for _, version := range []types.TransactionVersion{testOpenTxVersion, testCloseTxVersion, testUnilateralCloseTxVersion, testSettleTxVersion} {
+			types.RegisterTransactionVersion(version, nil)
+			modules.RegisterExplorerTransactionExtension(version, nil)
+		}
+	}
+}
+
+// testConsensusTx funds the given payment channel transaction using a single coin input of the given value (if any),
+// signs it using the given key pairs (if any), and returns it as confirmed at the given height.
+func testConsensusTx(t *testing.T, txn types.Transaction, height types.BlockHeight, funding types.Currency, signers ...plugintest.KeyPair) modules.ConsensusTransaction {
+	parentID := types.CoinOutputID{byte(height)}
+	if !funding.IsZero() {
+		txn.CoinInputs = []types.CoinInput{{ParentID: parentID}}
+	}
+	if len(signers) != 0 {
+		plugintest.SignExtension(t, &txn, signers...)
+	}
+	return modules.ConsensusTransaction{
+		Transaction: txn,
+		BlockHeight: height,
+		BlockTime:   types.Timestamp(height) * 600,
+		SpentCoinOutputs: map[types.CoinOutputID]types.CoinOutput{
+			parentID: {Value: funding},
+		},
+	}
+}
+
+// openTestChannel opens a payment channel with a capacity of 100 coins at height 1,
+// between a freshly generated sender and receiver.
+func openTestChannel(t *testing.T, plugin *Plugin, storage *plugintest.Storage) (Channel, plugintest.KeyPair, plugintest.KeyPair) {
+	sender, receiver := plugintest.NewKeyPair(), plugintest.NewKeyPair()
+	open := testOpenTx(sender, receiver, types.NewCurrency64(100), MinDisputePeriod)
+	err := storage.ApplyTransaction(testConsensusTx(t, open.Transaction(testOpenTxVersion), 1, types.NewCurrency64(101)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := plugin.GetChannel(open.ChannelID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, sender, receiver
+}
+
+func testOpenTx(sender, receiver plugintest.KeyPair, capacity types.Currency, disputePeriod types.BlockHeight) ChannelOpenTransaction {
+	return ChannelOpenTransaction{
+		Nonce:         types.TransactionNonce{1, 2, 3, 4, 5, 6, 7, 8},
+		Sender:        types.Ed25519PublicKey(sender.PublicKey),
+		Receiver:      types.Ed25519PublicKey(receiver.PublicKey),
+		Capacity:      capacity,
+		DisputePeriod: disputePeriod,
+		MinerFees:     []types.Currency{types.NewCurrency64(1)},
+	}
+}
+
+// testCommitment returns a commitment of the given channel, signed by the given key pairs.
+func testCommitment(t *testing.T, ch Channel, sequence, senderBalance, receiverBalance uint64, sender, receiver plugintest.KeyPair) SignedChannelCommitment {
+	commitment := SignedChannelCommitment{
+		Commitment: ChannelCommitment{
+			ChannelID:       ch.ID,
+			Sequence:        sequence,
+			SenderBalance:   types.NewCurrency64(senderBalance),
+			ReceiverBalance: types.NewCurrency64(receiverBalance),
+		},
+	}
+	err := commitment.Sign(ch, ChannelPartySender, sender.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitment.Sign(ch, ChannelPartyReceiver, receiver.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commitment
+}
+
+func TestPluginChannelOpen(t *testing.T) {
+	plugin, storage, closePlugin := newTestPlugin(t)
+	defer closePlugin()
+
+	sender, receiver := plugintest.NewKeyPair(), plugintest.NewKeyPair()
+	capacity := types.NewCurrency64(100)
+	funding := types.NewCurrency64(101)
+
+	invalidOpens := []struct {
+		Description string
+		Open        ChannelOpenTransaction
+		Funding     types.Currency
+	}{
+		{"a dispute period below the minimum", testOpenTx(sender, receiver, capacity, MinDisputePeriod-1), funding},
+		{"no capacity", testOpenTx(sender, receiver, types.Currency{}, MinDisputePeriod), types.NewCurrency64(1)},
+		{"the same sender and receiver", testOpenTx(sender, sender, capacity, MinDisputePeriod), funding},
+		{"an underfunded capacity", testOpenTx(sender, receiver, capacity, MinDisputePeriod), capacity},
+		{"an overfunded capacity", testOpenTx(sender, receiver, capacity, MinDisputePeriod), types.NewCurrency64(102)},
+	}
+	for _, invalid := range invalidOpens {
+		err := storage.ValidateTransaction(testConsensusTx(t, invalid.Open.Transaction(testOpenTxVersion), 1, invalid.Funding))
+		if err == nil {
+			t.Errorf("expected a channel open with %s to be rejected", invalid.Description)
+		}
+	}
+
+	open := testOpenTx(sender, receiver, capacity, MinDisputePeriod)
+	opened := testConsensusTx(t, open.Transaction(testOpenTxVersion), 1, funding)
+	err := storage.ValidateTransaction(opened)
+	if err != nil {
+		t.Fatalf("failed to validate a channel open: %v", err)
+	}
+	err = storage.ApplyTransaction(opened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChannelStatus(t, plugin, open.ChannelID(), ChannelStatusOpen)
+	for _, party := range []plugintest.KeyPair{sender, receiver} {
+		checkChannelsOf(t, plugin, party, 1)
+	}
+	// a channel can only be opened once
+	err = storage.ValidateTransaction(testConsensusTx(t, open.Transaction(testOpenTxVersion), 2, funding))
+	if err == nil {
+		t.Error("expected a channel to be opened only once")
+	}
+
+	// reverting the open deletes the channel
+	err = storage.RevertTransaction(opened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = plugin.GetChannel(open.ChannelID()); err != ErrChannelNotFound {
+		t.Errorf("expected the reverted channel not to be found, got: %v", err)
+	}
+	for _, party := range []plugintest.KeyPair{sender, receiver} {
+		checkChannelsOf(t, plugin, party, 0)
+	}
+}
+
+func TestPluginChannelClose(t *testing.T) {
+	plugin, storage, closePlugin := newTestPlugin(t)
+	defer closePlugin()
+
+	ch, sender, receiver := openTestChannel(t, plugin, storage)
+	closeTx := func(senderPayout, receiverPayout uint64) types.Transaction {
+		return (&ChannelCloseTransaction{
+			ChannelID: ch.ID,
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(senderPayout), Condition: ch.Condition(ChannelPartySender)},
+				{Value: types.NewCurrency64(receiverPayout), Condition: ch.Condition(ChannelPartyReceiver)},
+			},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}).Transaction(testCloseTxVersion)
+	}
+
+	// a cooperative close has to be signed by both parties
+	for _, signers := range [][]plugintest.KeyPair{{sender}, {receiver}} {
+		err := storage.ValidateTransaction(testConsensusTx(t, closeTx(59, 40), 2, types.Currency{}, signers...))
+		if err == nil {
+			t.Error("expected a cooperative close signed by a single party to be rejected")
+		}
+	}
+	// the payouts and miner fees have to add up to the capacity
+	for _, payouts := range [][2]uint64{{60, 40}, {58, 40}} {
+		err := storage.ValidateTransaction(testConsensusTx(t, closeTx(payouts[0], payouts[1]), 2, types.Currency{}, sender, receiver))
+		if err == nil {
+			t.Errorf("expected a cooperative close paying out %v to be rejected", payouts)
+		}
+	}
+
+	closed := testConsensusTx(t, closeTx(59, 40), 2, types.Currency{}, sender, receiver)
+	err := storage.ValidateTransaction(closed)
+	if err != nil {
+		t.Fatalf("failed to validate a cooperative close: %v", err)
+	}
+	err = storage.ApplyTransaction(closed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChannelStatus(t, plugin, ch.ID, ChannelStatusClosed)
+	// a closed channel can neither be closed again, nor be closed unilaterally
+	err = storage.ValidateTransaction(testConsensusTx(t, closeTx(59, 40), 3, types.Currency{}, sender, receiver))
+	if err == nil {
+		t.Error("expected a closed channel not to be closed again")
+	}
+	unilateralClose := (&ChannelUnilateralCloseTransaction{
+		Party:            ChannelPartySender,
+		PartyFulfillment: plugintest.FakeFulfillment(),
+		Commitment:       SignedChannelCommitment{Commitment: ch.InitialCommitment()},
+		MinerFees:        []types.Currency{types.NewCurrency64(1)},
+	}).Transaction(testUnilateralCloseTxVersion)
+	err = storage.ValidateTransaction(testConsensusTx(t, unilateralClose, 3, types.NewCurrency64(1)))
+	if err == nil {
+		t.Error("expected a closed channel not to be closed unilaterally")
+	}
+
+	// reverting the close reopens the channel
+	err = storage.RevertTransaction(closed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChannelStatus(t, plugin, ch.ID, ChannelStatusOpen)
+	err = storage.ValidateTransaction(testConsensusTx(t, closeTx(59, 40), 2, types.Currency{}, sender, receiver))
+	if err != nil {
+		t.Errorf("failed to validate the cooperative close of a reverted channel: %v", err)
+	}
+}
+
+func TestPluginChannelUnilateralCloseAndSettle(t *testing.T) {
+	plugin, storage, closePlugin := newTestPlugin(t)
+	defer closePlugin()
+
+	ch, sender, receiver := openTestChannel(t, plugin, storage)
+	fee := types.NewCurrency64(1)
+	unilateralClose := func(party ChannelParty, commitment SignedChannelCommitment) types.Transaction {
+		return (&ChannelUnilateralCloseTransaction{
+			Party:      party,
+			Commitment: commitment,
+			MinerFees:  []types.Currency{fee},
+		}).Transaction(testUnilateralCloseTxVersion)
+	}
+	settle := func(commitment ChannelCommitment) types.Transaction {
+		return (&ChannelSettleTransaction{
+			ChannelID:   ch.ID,
+			CoinOutputs: ch.PayoutCoinOutputs(commitment),
+			MinerFees:   []types.Currency{fee},
+		}).Transaction(testSettleTxVersion)
+	}
+	first := testCommitment(t, ch, 1, 90, 10, sender, receiver)
+	second := testCommitment(t, ch, 2, 70, 30, sender, receiver)
+	third := testCommitment(t, ch, 3, 50, 50, sender, receiver)
+
+	// a unilateral close has to be signed by the closing party,
+	// and requires a commitment signed by both parties, which conserves the capacity
+	err := storage.ValidateTransaction(testConsensusTx(t, unilateralClose(ChannelPartySender, first), 2, fee, receiver))
+	if err == nil {
+		t.Error("expected a unilateral close signed by the wrong party to be rejected")
+	}
+	unsigned := first
+	unsigned.ReceiverSignature = nil
+	err = storage.ValidateTransaction(testConsensusTx(t, unilateralClose(ChannelPartySender, unsigned), 2, fee, sender))
+	if err == nil {
+		t.Error("expected a unilateral close using a commitment signed by a single party to be rejected")
+	}
+	inflated := testCommitment(t, ch, 1, 90, 20, sender, receiver)
+	err = storage.ValidateTransaction(testConsensusTx(t, unilateralClose(ChannelPartySender, inflated), 2, fee, sender))
+	if err == nil {
+		t.Error("expected a unilateral close using a commitment exceeding the capacity to be rejected")
+	}
+	// a channel has to be closed unilaterally before it can be settled
+	err = storage.ValidateTransaction(testConsensusTx(t, settle(ch.InitialCommitment()), 2, fee))
+	if err == nil {
+		t.Error("expected an open channel not to be settled")
+	}
+
+	closing := testConsensusTx(t, unilateralClose(ChannelPartySender, first), 2, fee, sender)
+	err = storage.ValidateTransaction(closing)
+	if err != nil {
+		t.Fatalf("failed to validate a unilateral close: %v", err)
+	}
+	err = storage.ApplyTransaction(closing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := 2 + ch.DisputePeriod
+	checkChannelCommitment(t, plugin, ch.ID, ChannelStatusClosing, 1, deadline)
+
+	// a dispute requires a more recent commitment
+	for _, stale := range []SignedChannelCommitment{{Commitment: ch.InitialCommitment()}, first} {
+		err = storage.ValidateTransaction(testConsensusTx(t, unilateralClose(ChannelPartyReceiver, stale), 3, fee, receiver))
+		if err == nil {
+			t.Errorf("expected a dispute using stale commitment %d to be rejected", stale.Commitment.Sequence)
+		}
+	}
+	// the channel cannot be settled during the dispute period
+	err = storage.ValidateTransaction(testConsensusTx(t, settle(first.Commitment), deadline-1, fee))
+	if err == nil {
+		t.Error("expected a channel not to be settled during its dispute period")
+	}
+
+	dispute := testConsensusTx(t, unilateralClose(ChannelPartyReceiver, second), deadline-1, fee, receiver)
+	err = storage.ValidateTransaction(dispute)
+	if err != nil {
+		t.Fatalf("failed to validate a dispute using a more recent commitment: %v", err)
+	}
+	err = storage.ApplyTransaction(dispute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a dispute does not extend the dispute period
+	checkChannelCommitment(t, plugin, ch.ID, ChannelStatusClosing, 2, deadline)
+	err = storage.ValidateTransaction(testConsensusTx(t, unilateralClose(ChannelPartySender, third), deadline, fee, sender))
+	if err == nil {
+		t.Error("expected a dispute after the dispute period to be rejected")
+	}
+
+	// the channel has to be paid out exactly as defined by the last submitted commitment
+	for _, payout := range []ChannelCommitment{first.Commitment, third.Commitment} {
+		err = storage.ValidateTransaction(testConsensusTx(t, settle(payout), deadline, fee))
+		if err == nil {
+			t.Errorf("expected a settlement paying out commitment %d to be rejected", payout.Sequence)
+		}
+	}
+	err = storage.ValidateTransaction(testConsensusTx(t, settle(second.Commitment), deadline, types.NewCurrency64(2)))
+	if err == nil {
+		t.Error("expected a settlement with unbalanced coin outputs to be rejected")
+	}
+	settled := testConsensusTx(t, settle(second.Commitment), deadline, fee)
+	err = storage.ValidateTransaction(settled)
+	if err != nil {
+		t.Fatalf("failed to validate a settlement after the dispute period: %v", err)
+	}
+	err = storage.ApplyTransaction(settled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChannelStatus(t, plugin, ch.ID, ChannelStatusClosed)
+	err = storage.ValidateTransaction(testConsensusTx(t, settle(second.Commitment), deadline+1, fee))
+	if err == nil {
+		t.Error("expected a channel to be settled only once")
+	}
+
+	// reverting restores the previous channel states, in reverse order
+	err = storage.RevertTransaction(settled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChannelCommitment(t, plugin, ch.ID, ChannelStatusClosing, 2, deadline)
+	err = storage.RevertTransaction(dispute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChannelCommitment(t, plugin, ch.ID, ChannelStatusClosing, 1, deadline)
+	err = storage.RevertTransaction(closing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChannelCommitment(t, plugin, ch.ID, ChannelStatusOpen, 0, 0)
+}
+
+func checkChannelStatus(t *testing.T, plugin *Plugin, id ChannelID, status ChannelStatus) {
+	t.Helper()
+	ch, err := plugin.GetChannel(id)
+	if err != nil {
+		t.Fatalf("failed to get payment channel %s: %v", id.String(), err)
+	}
+	if ch.Status != status {
+		t.Errorf("unexpected status of payment channel %s: %s != %s", id.String(), ch.Status.String(), status.String())
+	}
+}
+
+func checkChannelCommitment(t *testing.T, plugin *Plugin, id ChannelID, status ChannelStatus, sequence uint64, deadline types.BlockHeight) {
+	t.Helper()
+	checkChannelStatus(t, plugin, id, status)
+	ch, err := plugin.GetChannel(id)
+	if err != nil {
+		t.Fatalf("failed to get payment channel %s: %v", id.String(), err)
+	}
+	if ch.Commitment.Sequence != sequence || ch.DisputeDeadline != deadline {
+		t.Errorf("unexpected commitment of payment channel %s: sequence %d (deadline %d) != sequence %d (deadline %d)",
+			id.String(), ch.Commitment.Sequence, ch.DisputeDeadline, sequence, deadline)
+	}
+}
+
+func checkChannelsOf(t *testing.T, plugin *Plugin, party plugintest.KeyPair, count int) {
+	t.Helper()
+	channels, err := plugin.GetChannelsOf(party.UnlockHash())
+	if err != nil {
+		t.Fatalf("failed to get payment channels of %s: %v", party.UnlockHash().String(), err)
+	}
+	if len(channels) != count {
+		t.Errorf("unexpected amount of payment channels of %s: %d != %d", party.UnlockHash().String(), len(channels), count)
+	}
+}
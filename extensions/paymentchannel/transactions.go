@@ -0,0 +1,920 @@
+package paymentchannel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// ChannelOpenTransactionController defines a rivine-specific transaction controller,
+	// for a ChannelOpen Transaction. It allows anyone to open a payment channel,
+	// locking the funded capacity in the channel, owned by the sender at first.
+	ChannelOpenTransactionController struct {
+		// TransactionVersion is used to validate/set the transaction version
+		// of a channel open transaction.
+		TransactionVersion types.TransactionVersion
+	}
+
+	// ChannelCloseTransactionController defines a rivine-specific transaction controller,
+	// for a ChannelClose Transaction. It allows both parties of a payment channel
+	// to cooperatively close the channel, paying out its capacity as agreed upon by both.
+	ChannelCloseTransactionController struct {
+		// ChannelGetter is used to get the parties of the channel,
+		// in order to sign the party fulfillments.
+		ChannelGetter ChannelGetter
+
+		// TransactionVersion is used to validate/set the transaction version
+		// of a channel close transaction.
+		TransactionVersion types.TransactionVersion
+	}
+
+	// ChannelUnilateralCloseTransactionController defines a rivine-specific transaction controller,
+	// for a ChannelUnilateralClose Transaction. It allows a single party of a payment channel
+	// to close the channel using the latest commitment known to it, starting the dispute period,
+	// or to dispute a pending close by submitting a more recent commitment.
+	ChannelUnilateralCloseTransactionController struct {
+		// ChannelGetter is used to get the parties of the channel,
+		// in order to sign the party fulfillment.
+		ChannelGetter ChannelGetter
+
+		// TransactionVersion is used to validate/set the transaction version
+		// of a channel unilateral close transaction.
+		TransactionVersion types.TransactionVersion
+	}
+
+	// ChannelSettleTransactionController defines a rivine-specific transaction controller,
+	// for a ChannelSettle Transaction. It allows anyone to settle a unilaterally closed payment channel
+	// once its dispute period is over, paying out the capacity according to the last submitted commitment.
+	ChannelSettleTransactionController struct {
+		// TransactionVersion is used to validate/set the transaction version
+		// of a channel settle transaction.
+		TransactionVersion types.TransactionVersion
+	}
+)
+
+// ensure our controllers implement all desired interfaces
+var (
+	// ensure at compile time that ChannelOpenTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = ChannelOpenTransactionController{}
+	_ types.TransactionSignatureHasher           = ChannelOpenTransactionController{}
+	_ types.TransactionIDEncoder                 = ChannelOpenTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = ChannelOpenTransactionController{}
+
+	// ensure at compile time that ChannelCloseTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController      = ChannelCloseTransactionController{}
+	_ types.TransactionExtensionSigner = ChannelCloseTransactionController{}
+	_ types.TransactionSignatureHasher = ChannelCloseTransactionController{}
+	_ types.TransactionIDEncoder       = ChannelCloseTransactionController{}
+
+	// ensure at compile time that ChannelUnilateralCloseTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController      = ChannelUnilateralCloseTransactionController{}
+	_ types.TransactionExtensionSigner = ChannelUnilateralCloseTransactionController{}
+	_ types.TransactionSignatureHasher = ChannelUnilateralCloseTransactionController{}
+	_ types.TransactionIDEncoder       = ChannelUnilateralCloseTransactionController{}
+
+	// ensure at compile time that ChannelSettleTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController      = ChannelSettleTransactionController{}
+	_ types.TransactionSignatureHasher = ChannelSettleTransactionController{}
+	_ types.TransactionIDEncoder       = ChannelSettleTransactionController{}
+)
+
+// ChannelOpenTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (cotc ChannelOpenTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	cotx, err := ChannelOpenTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelOpenTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(cotx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (cotc ChannelOpenTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var cotx ChannelOpenTransaction
+	err := rivbin.NewDecoder(r).Decode(&cotx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a ChannelOpenTx: %v", err)
+	}
+	// return channel open tx as regular rivine tx data
+	return cotx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (cotc ChannelOpenTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	cotx, err := ChannelOpenTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a ChannelOpenTx: %v", err)
+	}
+	return json.Marshal(cotx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (cotc ChannelOpenTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var cotx ChannelOpenTransaction
+	err := json.Unmarshal(data, &cotx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a ChannelOpenTx: %v", err)
+	}
+	// return channel open tx as regular rivine tx data
+	return cotx.TransactionData(), nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (cotc ChannelOpenTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	cotx, err := ChannelOpenTransactionFromTransaction(t, cotc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a ChannelOpenTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierChannelOpenTransaction,
+		cotx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		cotx.Sender,
+		cotx.Receiver,
+		cotx.Capacity,
+		cotx.DisputePeriod,
+		coinParentIDs(cotx.CoinInputs),
+		cotx.CoinOutputs,
+		cotx.MinerFees,
+		cotx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (cotc ChannelOpenTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	cotx, err := ChannelOpenTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelOpenTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierChannelOpenTransaction, cotx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData
+func (cotc ChannelOpenTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	coext, ok := extension.(*ChannelOpenTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a ChannelOpenTx")
+	}
+	ch := Channel{Sender: coext.Sender, Receiver: coext.Receiver}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: []types.UnlockConditionProxy{
+			ch.Condition(ChannelPartySender),
+			ch.Condition(ChannelPartyReceiver),
+		},
+	}, nil
+}
+
+// ChannelCloseTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (cctc ChannelCloseTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	cctx, err := ChannelCloseTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelCloseTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(cctx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (cctc ChannelCloseTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var cctx ChannelCloseTransaction
+	err := rivbin.NewDecoder(r).Decode(&cctx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a ChannelCloseTx: %v", err)
+	}
+	// return channel close tx as regular rivine tx data
+	return cctx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (cctc ChannelCloseTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	cctx, err := ChannelCloseTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a ChannelCloseTx: %v", err)
+	}
+	return json.Marshal(cctx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (cctc ChannelCloseTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var cctx ChannelCloseTransaction
+	err := json.Unmarshal(data, &cctx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a ChannelCloseTx: %v", err)
+	}
+	// return channel close tx as regular rivine tx data
+	return cctx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (cctc ChannelCloseTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid ChannelCloseTransactionExtension,
+	// which contains the fulfillments that can be used to fulfill the conditions of both parties
+	ccTxExtension, ok := extension.(*ChannelCloseTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a ChannelCloseTx")
+	}
+	ch, err := getChannel(cctc.ChannelGetter, ccTxExtension.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	// sign all we can, the other party signs the transaction as well prior to sending it
+	err = sign(&ccTxExtension.SenderFulfillment, ch.Condition(ChannelPartySender))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign sender fulfillment of ChannelCloseTx: %v", err)
+	}
+	err = sign(&ccTxExtension.ReceiverFulfillment, ch.Condition(ChannelPartyReceiver))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign receiver fulfillment of ChannelCloseTx: %v", err)
+	}
+	return ccTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (cctc ChannelCloseTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	cctx, err := ChannelCloseTransactionFromTransaction(t, cctc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a ChannelCloseTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierChannelCloseTransaction,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		cctx.ChannelID,
+		coinParentIDs(cctx.CoinInputs),
+		cctx.CoinOutputs,
+		cctx.MinerFees,
+		cctx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (cctc ChannelCloseTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	cctx, err := ChannelCloseTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelCloseTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierChannelCloseTransaction, cctx)
+}
+
+// ChannelUnilateralCloseTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (uctc ChannelUnilateralCloseTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	uctx, err := ChannelUnilateralCloseTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelUnilateralCloseTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(uctx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (uctc ChannelUnilateralCloseTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var uctx ChannelUnilateralCloseTransaction
+	err := rivbin.NewDecoder(r).Decode(&uctx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a ChannelUnilateralCloseTx: %v", err)
+	}
+	// return channel unilateral close tx as regular rivine tx data
+	return uctx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (uctc ChannelUnilateralCloseTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	uctx, err := ChannelUnilateralCloseTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a ChannelUnilateralCloseTx: %v", err)
+	}
+	return json.Marshal(uctx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (uctc ChannelUnilateralCloseTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var uctx ChannelUnilateralCloseTransaction
+	err := json.Unmarshal(data, &uctx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a ChannelUnilateralCloseTx: %v", err)
+	}
+	// return channel unilateral close tx as regular rivine tx data
+	return uctx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (uctc ChannelUnilateralCloseTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid ChannelUnilateralCloseTransactionExtension,
+	// which contains the fulfillment that can be used to fulfill the condition of the closing party
+	ucTxExtension, ok := extension.(*ChannelUnilateralCloseTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a ChannelUnilateralCloseTx")
+	}
+	ch, err := getChannel(uctc.ChannelGetter, ucTxExtension.Commitment.Commitment.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	err = sign(&ucTxExtension.PartyFulfillment, ch.Condition(ucTxExtension.Party))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign party fulfillment of ChannelUnilateralCloseTx: %v", err)
+	}
+	return ucTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (uctc ChannelUnilateralCloseTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	uctx, err := ChannelUnilateralCloseTransactionFromTransaction(t, uctc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a ChannelUnilateralCloseTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierChannelUnilateralCloseTransaction,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		uctx.Party,
+		uctx.Commitment,
+		coinParentIDs(uctx.CoinInputs),
+		uctx.CoinOutputs,
+		uctx.MinerFees,
+		uctx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (uctc ChannelUnilateralCloseTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	uctx, err := ChannelUnilateralCloseTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelUnilateralCloseTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierChannelUnilateralCloseTransaction, uctx)
+}
+
+// ChannelSettleTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (cstc ChannelSettleTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	cstx, err := ChannelSettleTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelSettleTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(cstx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (cstc ChannelSettleTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var cstx ChannelSettleTransaction
+	err := rivbin.NewDecoder(r).Decode(&cstx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a ChannelSettleTx: %v", err)
+	}
+	// return channel settle tx as regular rivine tx data
+	return cstx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (cstc ChannelSettleTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	cstx, err := ChannelSettleTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a ChannelSettleTx: %v", err)
+	}
+	return json.Marshal(cstx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (cstc ChannelSettleTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var cstx ChannelSettleTransaction
+	err := json.Unmarshal(data, &cstx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a ChannelSettleTx: %v", err)
+	}
+	// return channel settle tx as regular rivine tx data
+	return cstx.TransactionData(), nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (cstc ChannelSettleTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	cstx, err := ChannelSettleTransactionFromTransaction(t, cstc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a ChannelSettleTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierChannelSettleTransaction,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		cstx.ChannelID,
+		coinParentIDs(cstx.CoinInputs),
+		cstx.CoinOutputs,
+		cstx.MinerFees,
+		cstx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (cstc ChannelSettleTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	cstx, err := ChannelSettleTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a ChannelSettleTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierChannelSettleTransaction, cstx)
+}
+
+// getChannel gets the channel using the given getter,
+// as required to sign the party fulfillments of a channel transaction.
+func getChannel(getter ChannelGetter, id ChannelID) (Channel, error) {
+	if getter == nil {
+		return Channel{}, errors.New("no channel getter available to get the parties of the payment channel")
+	}
+	ch, err := getter.GetChannel(id)
+	if err != nil {
+		return Channel{}, fmt.Errorf("failed to get payment channel %s: %v", id.String(), err)
+	}
+	return ch, nil
+}
+
+func coinParentIDs(inputs []types.CoinInput) []types.CoinOutputID {
+	parentIDSlice := make([]types.CoinOutputID, 0, len(inputs))
+	for _, ci := range inputs {
+		parentIDSlice = append(parentIDSlice, ci.ParentID)
+	}
+	return parentIDSlice
+}
+
+// validateCoinTransactionData ensures the regular transaction data
+// contains at least one miner fee, and no block stake inputs/outputs,
+// as required by all payment channel transactions.
+func validateCoinTransactionData(txData types.TransactionData) error {
+	if len(txData.MinerFees) == 0 {
+		return errors.New("at least one miner fee is required")
+	}
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return errors.New("no block stake inputs/outputs are allowed")
+	}
+	return nil
+}
+
+type (
+	// ChannelOpenTransaction is used to open a new payment channel,
+	// locking the capacity of the channel, funded by the coin inputs.
+	ChannelOpenTransaction struct {
+		// Nonce used to ensure the uniqueness of a ChannelOpenTransaction's ID, signature and channel ID.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// Sender is the public key of the party owning the full capacity of the opened channel.
+		Sender types.PublicKey `json:"sender"`
+		// Receiver is the public key of the counterparty of the opened channel.
+		Receiver types.PublicKey `json:"receiver"`
+		// Capacity is the amount of coins locked in the channel.
+		Capacity types.Currency `json:"capacity"`
+		// DisputePeriod is the amount of blocks the channel remains in the closing state,
+		// after it was closed unilaterally.
+		DisputePeriod types.BlockHeight `json:"disputeperiod"`
+		// CoinInputs are used to fund the Capacity and MinerFees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs can be used to refund coins from the CoinInputs.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this channel open transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// ChannelOpenTransactionExtension defines the ChannelOpenTransaction Extension Data
+	ChannelOpenTransactionExtension struct {
+		Nonce         types.TransactionNonce
+		Sender        types.PublicKey
+		Receiver      types.PublicKey
+		Capacity      types.Currency
+		DisputePeriod types.BlockHeight
+	}
+
+	// ChannelCloseTransaction is used by both parties of a payment channel to close it cooperatively.
+	ChannelCloseTransaction struct {
+		// ChannelID identifies the closed channel.
+		ChannelID ChannelID `json:"channelid"`
+		// SenderFulfillment fulfills the condition of the sender of the channel.
+		SenderFulfillment types.UnlockFulfillmentProxy `json:"senderfulfillment"`
+		// ReceiverFulfillment fulfills the condition of the receiver of the channel.
+		ReceiverFulfillment types.UnlockFulfillmentProxy `json:"receiverfulfillment"`
+		// CoinInputs can optionally be used to fund the MinerFees.
+		CoinInputs []types.CoinInput `json:"coininputs,omitempty"`
+		// CoinOutputs pay out the capacity of the channel,
+		// as well as any coins refunded from the CoinInputs.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this channel close transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// ChannelCloseTransactionExtension defines the ChannelCloseTransaction Extension Data
+	ChannelCloseTransactionExtension struct {
+		ChannelID           ChannelID
+		SenderFulfillment   types.UnlockFulfillmentProxy
+		ReceiverFulfillment types.UnlockFulfillmentProxy
+	}
+
+	// ChannelUnilateralCloseTransaction is used by a single party of a payment channel
+	// to close it using a signed commitment, or to dispute a pending close using a more recent commitment.
+	ChannelUnilateralCloseTransaction struct {
+		// Party identifies the party closing (or disputing) the channel.
+		Party ChannelParty `json:"party"`
+		// PartyFulfillment fulfills the condition of the closing (or disputing) party.
+		PartyFulfillment types.UnlockFulfillmentProxy `json:"partyfulfillment"`
+		// Commitment is the commitment, signed by both parties, used to settle the channel.
+		Commitment SignedChannelCommitment `json:"commitment"`
+		// CoinInputs are used to fund the MinerFees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs can be used to refund coins from the CoinInputs.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this channel unilateral close transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// ChannelUnilateralCloseTransactionExtension defines the ChannelUnilateralCloseTransaction Extension Data
+	ChannelUnilateralCloseTransactionExtension struct {
+		Party            ChannelParty
+		PartyFulfillment types.UnlockFulfillmentProxy
+		Commitment       SignedChannelCommitment
+	}
+
+	// ChannelSettleTransaction is used to settle a payment channel
+	// once the dispute period of its unilateral close is over.
+	ChannelSettleTransaction struct {
+		// ChannelID identifies the settled channel.
+		ChannelID ChannelID `json:"channelid"`
+		// CoinInputs are used to fund the MinerFees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs start with the payout coin outputs of the channel,
+		// followed by any coins refunded from the CoinInputs.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs"`
+		// Minerfees, a fee paid for this channel settle transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// ChannelSettleTransactionExtension defines the ChannelSettleTransaction Extension Data
+	ChannelSettleTransactionExtension struct {
+		ChannelID ChannelID
+	}
+)
+
+// ChannelOpenTransactionFromTransaction creates a ChannelOpenTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `ChannelOpenTransactionFromTransactionData` constructor.
+func ChannelOpenTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (ChannelOpenTransaction, error) {
+	if tx.Version != expectedVersion {
+		return ChannelOpenTransaction{}, fmt.Errorf(
+			"a channel open transaction requires tx version %d",
+			expectedVersion)
+	}
+	return ChannelOpenTransactionFromTransactionData(transactionDataFromTransaction(tx))
+}
+
+// ChannelOpenTransactionFromTransactionData creates a ChannelOpenTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func ChannelOpenTransactionFromTransactionData(txData types.TransactionData) (ChannelOpenTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid ChannelOpenTransactionExtension,
+	// which contains all channel-specific data
+	extensionData, ok := txData.Extension.(*ChannelOpenTransactionExtension)
+	if !ok {
+		return ChannelOpenTransaction{}, errors.New("invalid extension data for a ChannelOpenTransaction")
+	}
+	if len(txData.CoinInputs) == 0 {
+		return ChannelOpenTransaction{}, errors.New("invalid ChannelOpenTransaction: at least one coin input is required")
+	}
+	err := validateCoinTransactionData(txData)
+	if err != nil {
+		return ChannelOpenTransaction{}, fmt.Errorf("invalid ChannelOpenTransaction: %v", err)
+	}
+	// return the ChannelOpenTransaction, with the data extracted from the TransactionData
+	return ChannelOpenTransaction{
+		Nonce:         extensionData.Nonce,
+		Sender:        extensionData.Sender,
+		Receiver:      extensionData.Receiver,
+		Capacity:      extensionData.Capacity,
+		DisputePeriod: extensionData.DisputePeriod,
+		CoinInputs:    txData.CoinInputs,
+		CoinOutputs:   txData.CoinOutputs,
+		MinerFees:     txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// ChannelID returns the ID of the payment channel opened by this transaction.
+func (cotx *ChannelOpenTransaction) ChannelID() ChannelID {
+	return NewChannelID(cotx.Nonce, cotx.Sender, cotx.Receiver)
+}
+
+// TransactionData returns this ChannelOpenTransaction
+// as regular rivine transaction data.
+func (cotx *ChannelOpenTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    cotx.CoinInputs,
+		CoinOutputs:   cotx.CoinOutputs,
+		MinerFees:     cotx.MinerFees,
+		ArbitraryData: cotx.ArbitraryData,
+		Extension: &ChannelOpenTransactionExtension{
+			Nonce:         cotx.Nonce,
+			Sender:        cotx.Sender,
+			Receiver:      cotx.Receiver,
+			Capacity:      cotx.Capacity,
+			DisputePeriod: cotx.DisputePeriod,
+		},
+	}
+}
+
+// Transaction returns this ChannelOpenTransaction
+// as regular rivine transaction, using the given version.
+func (cotx *ChannelOpenTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	return transactionFromTransactionData(version, cotx.TransactionData())
+}
+
+// ChannelCloseTransactionFromTransaction creates a ChannelCloseTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `ChannelCloseTransactionFromTransactionData` constructor.
+func ChannelCloseTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (ChannelCloseTransaction, error) {
+	if tx.Version != expectedVersion {
+		return ChannelCloseTransaction{}, fmt.Errorf(
+			"a channel close transaction requires tx version %d",
+			expectedVersion)
+	}
+	return ChannelCloseTransactionFromTransactionData(transactionDataFromTransaction(tx))
+}
+
+// ChannelCloseTransactionFromTransactionData creates a ChannelCloseTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func ChannelCloseTransactionFromTransactionData(txData types.TransactionData) (ChannelCloseTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid ChannelCloseTransactionExtension,
+	// which contains all channel-specific data
+	extensionData, ok := txData.Extension.(*ChannelCloseTransactionExtension)
+	if !ok {
+		return ChannelCloseTransaction{}, errors.New("invalid extension data for a ChannelCloseTransaction")
+	}
+	err := validateCoinTransactionData(txData)
+	if err != nil {
+		return ChannelCloseTransaction{}, fmt.Errorf("invalid ChannelCloseTransaction: %v", err)
+	}
+	// return the ChannelCloseTransaction, with the data extracted from the TransactionData
+	return ChannelCloseTransaction{
+		ChannelID:           extensionData.ChannelID,
+		SenderFulfillment:   extensionData.SenderFulfillment,
+		ReceiverFulfillment: extensionData.ReceiverFulfillment,
+		CoinInputs:          txData.CoinInputs,
+		CoinOutputs:         txData.CoinOutputs,
+		MinerFees:           txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this ChannelCloseTransaction
+// as regular rivine transaction data.
+func (cctx *ChannelCloseTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    cctx.CoinInputs,
+		CoinOutputs:   cctx.CoinOutputs,
+		MinerFees:     cctx.MinerFees,
+		ArbitraryData: cctx.ArbitraryData,
+		Extension: &ChannelCloseTransactionExtension{
+			ChannelID:           cctx.ChannelID,
+			SenderFulfillment:   cctx.SenderFulfillment,
+			ReceiverFulfillment: cctx.ReceiverFulfillment,
+		},
+	}
+}
+
+// Transaction returns this ChannelCloseTransaction
+// as regular rivine transaction, using the given version.
+func (cctx *ChannelCloseTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	return transactionFromTransactionData(version, cctx.TransactionData())
+}
+
+// ChannelUnilateralCloseTransactionFromTransaction creates a ChannelUnilateralCloseTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `ChannelUnilateralCloseTransactionFromTransactionData` constructor.
+func ChannelUnilateralCloseTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (ChannelUnilateralCloseTransaction, error) {
+	if tx.Version != expectedVersion {
+		return ChannelUnilateralCloseTransaction{}, fmt.Errorf(
+			"a channel unilateral close transaction requires tx version %d",
+			expectedVersion)
+	}
+	return ChannelUnilateralCloseTransactionFromTransactionData(transactionDataFromTransaction(tx))
+}
+
+// ChannelUnilateralCloseTransactionFromTransactionData creates a ChannelUnilateralCloseTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func ChannelUnilateralCloseTransactionFromTransactionData(txData types.TransactionData) (ChannelUnilateralCloseTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid ChannelUnilateralCloseTransactionExtension,
+	// which contains all channel-specific data
+	extensionData, ok := txData.Extension.(*ChannelUnilateralCloseTransactionExtension)
+	if !ok {
+		return ChannelUnilateralCloseTransaction{}, errors.New("invalid extension data for a ChannelUnilateralCloseTransaction")
+	}
+	if len(txData.CoinInputs) == 0 {
+		return ChannelUnilateralCloseTransaction{}, errors.New("invalid ChannelUnilateralCloseTransaction: at least one coin input is required")
+	}
+	err := validateCoinTransactionData(txData)
+	if err != nil {
+		return ChannelUnilateralCloseTransaction{}, fmt.Errorf("invalid ChannelUnilateralCloseTransaction: %v", err)
+	}
+	// return the ChannelUnilateralCloseTransaction, with the data extracted from the TransactionData
+	return ChannelUnilateralCloseTransaction{
+		Party:            extensionData.Party,
+		PartyFulfillment: extensionData.PartyFulfillment,
+		Commitment:       extensionData.Commitment,
+		CoinInputs:       txData.CoinInputs,
+		CoinOutputs:      txData.CoinOutputs,
+		MinerFees:        txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// ChannelID returns the ID of the payment channel closed by this transaction.
+func (uctx *ChannelUnilateralCloseTransaction) ChannelID() ChannelID {
+	return uctx.Commitment.Commitment.ChannelID
+}
+
+// TransactionData returns this ChannelUnilateralCloseTransaction
+// as regular rivine transaction data.
+func (uctx *ChannelUnilateralCloseTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    uctx.CoinInputs,
+		CoinOutputs:   uctx.CoinOutputs,
+		MinerFees:     uctx.MinerFees,
+		ArbitraryData: uctx.ArbitraryData,
+		Extension: &ChannelUnilateralCloseTransactionExtension{
+			Party:            uctx.Party,
+			PartyFulfillment: uctx.PartyFulfillment,
+			Commitment:       uctx.Commitment,
+		},
+	}
+}
+
+// Transaction returns this ChannelUnilateralCloseTransaction
+// as regular rivine transaction, using the given version.
+func (uctx *ChannelUnilateralCloseTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	return transactionFromTransactionData(version, uctx.TransactionData())
+}
+
+// ChannelSettleTransactionFromTransaction creates a ChannelSettleTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `ChannelSettleTransactionFromTransactionData` constructor.
+func ChannelSettleTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (ChannelSettleTransaction, error) {
+	if tx.Version != expectedVersion {
+		return ChannelSettleTransaction{}, fmt.Errorf(
+			"a channel settle transaction requires tx version %d",
+			expectedVersion)
+	}
+	return ChannelSettleTransactionFromTransactionData(transactionDataFromTransaction(tx))
+}
+
+// ChannelSettleTransactionFromTransactionData creates a ChannelSettleTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func ChannelSettleTransactionFromTransactionData(txData types.TransactionData) (ChannelSettleTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid ChannelSettleTransactionExtension,
+	// which contains all channel-specific data
+	extensionData, ok := txData.Extension.(*ChannelSettleTransactionExtension)
+	if !ok {
+		return ChannelSettleTransaction{}, errors.New("invalid extension data for a ChannelSettleTransaction")
+	}
+	if len(txData.CoinInputs) == 0 {
+		return ChannelSettleTransaction{}, errors.New("invalid ChannelSettleTransaction: at least one coin input is required")
+	}
+	err := validateCoinTransactionData(txData)
+	if err != nil {
+		return ChannelSettleTransaction{}, fmt.Errorf("invalid ChannelSettleTransaction: %v", err)
+	}
+	// return the ChannelSettleTransaction, with the data extracted from the TransactionData
+	return ChannelSettleTransaction{
+		ChannelID:   extensionData.ChannelID,
+		CoinInputs:  txData.CoinInputs,
+		CoinOutputs: txData.CoinOutputs,
+		MinerFees:   txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this ChannelSettleTransaction
+// as regular rivine transaction data.
+func (cstx *ChannelSettleTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    cstx.CoinInputs,
+		CoinOutputs:   cstx.CoinOutputs,
+		MinerFees:     cstx.MinerFees,
+		ArbitraryData: cstx.ArbitraryData,
+		Extension: &ChannelSettleTransactionExtension{
+			ChannelID: cstx.ChannelID,
+		},
+	}
+}
+
+// Transaction returns this ChannelSettleTransaction
+// as regular rivine transaction, using the given version.
+func (cstx *ChannelSettleTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	return transactionFromTransactionData(version, cstx.TransactionData())
+}
+
+func transactionDataFromTransaction(tx types.Transaction) types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	}
+}
+
+func transactionFromTransactionData(version types.TransactionVersion, txData types.TransactionData) types.Transaction {
+	return types.Transaction{
+		Version:       version,
+		CoinInputs:    txData.CoinInputs,
+		CoinOutputs:   txData.CoinOutputs,
+		MinerFees:     txData.MinerFees,
+		ArbitraryData: txData.ArbitraryData,
+		Extension:     txData.Extension,
+	}
+}
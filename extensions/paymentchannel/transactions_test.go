@@ -0,0 +1,265 @@
+package paymentchannel
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/internal/plugintest"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	testOpenTxVersion            types.TransactionVersion = 212
+	testCloseTxVersion           types.TransactionVersion = 213
+	testUnilateralCloseTxVersion types.TransactionVersion = 214
+	testSettleTxVersion          types.TransactionVersion = 215
+)
+
+func registerTestTransactionVersions() func() {
+	types.RegisterTransactionVersion(testOpenTxVersion, ChannelOpenTransactionController{TransactionVersion: testOpenTxVersion})
+	types.RegisterTransactionVersion(testCloseTxVersion, ChannelCloseTransactionController{TransactionVersion: testCloseTxVersion})
+	types.RegisterTransactionVersion(testUnilateralCloseTxVersion, ChannelUnilateralCloseTransactionController{TransactionVersion: testUnilateralCloseTxVersion})
+	types.RegisterTransactionVersion(testSettleTxVersion, ChannelSettleTransactionController{TransactionVersion: testSettleTxVersion})
+	return func() {
+		types.RegisterTransactionVersion(testOpenTxVersion, nil)
+		types.RegisterTransactionVersion(testCloseTxVersion, nil)
+		types.RegisterTransactionVersion(testUnilateralCloseTxVersion, nil)
+		types.RegisterTransactionVersion(testSettleTxVersion, nil)
+	}
+}
+
+// testChannel returns an open payment channel between two freshly generated keys,
+// as well as the secret keys of the sender and receiver.
+func testChannel() (Channel, crypto.SecretKey, crypto.SecretKey) {
+	senderSK, senderPK := crypto.GenerateKeyPair()
+	receiverSK, receiverPK := crypto.GenerateKeyPair()
+	tx := ChannelOpenTransaction{
+		Nonce:         types.TransactionNonce{1, 2, 3, 4, 5, 6, 7, 8},
+		Sender:        types.Ed25519PublicKey(senderPK),
+		Receiver:      types.Ed25519PublicKey(receiverPK),
+		Capacity:      types.NewCurrency64(100),
+		DisputePeriod: MinDisputePeriod,
+	}
+	return Channel{
+		ID:            tx.ChannelID(),
+		Sender:        tx.Sender,
+		Receiver:      tx.Receiver,
+		Capacity:      tx.Capacity,
+		DisputePeriod: tx.DisputePeriod,
+		Status:        ChannelStatusOpen,
+	}, senderSK, receiverSK
+}
+
+func testChannelTransactions(t *testing.T) []types.Transaction {
+	ch, senderSK, receiverSK := testChannel()
+
+	openTx := ChannelOpenTransaction{
+		Nonce:         types.TransactionNonce{1, 2, 3, 4, 5, 6, 7, 8},
+		Sender:        ch.Sender,
+		Receiver:      ch.Receiver,
+		Capacity:      ch.Capacity,
+		DisputePeriod: ch.DisputePeriod,
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 2}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(42), Condition: ch.Condition(ChannelPartySender)},
+		},
+		MinerFees:     []types.Currency{types.NewCurrency64(1)},
+		ArbitraryData: []byte("metered service"),
+	}
+	commitment := SignedChannelCommitment{
+		Commitment: ChannelCommitment{
+			ChannelID:       ch.ID,
+			Sequence:        3,
+			SenderBalance:   types.NewCurrency64(60),
+			ReceiverBalance: types.NewCurrency64(30),
+			HTLCs: []ChannelHTLC{
+				{
+					Payer:        ChannelPartySender,
+					Amount:       types.NewCurrency64(10),
+					HashedSecret: types.AtomicSwapHashedSecret{1, 2, 3},
+					TimeLock:     types.Timestamp(1560000000),
+				},
+			},
+		},
+	}
+	if err := commitment.Sign(ch, ChannelPartySender, senderSK); err != nil {
+		t.Fatal(err)
+	}
+	if err := commitment.Sign(ch, ChannelPartyReceiver, receiverSK); err != nil {
+		t.Fatal(err)
+	}
+	closeTx := ChannelCloseTransaction{
+		ChannelID:           ch.ID,
+		SenderFulfillment:   plugintest.FakeFulfillment(),
+		ReceiverFulfillment: plugintest.FakeFulfillment(),
+		CoinOutputs:         ch.PayoutCoinOutputs(ch.InitialCommitment()),
+		MinerFees:           []types.Currency{types.NewCurrency64(1)},
+	}
+	unilateralCloseTx := ChannelUnilateralCloseTransaction{
+		Party:            ChannelPartyReceiver,
+		PartyFulfillment: plugintest.FakeFulfillment(),
+		Commitment:       commitment,
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 3}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	settleTx := ChannelSettleTransaction{
+		ChannelID: ch.ID,
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 4}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		CoinOutputs: ch.PayoutCoinOutputs(commitment.Commitment),
+		MinerFees:   []types.Currency{types.NewCurrency64(1)},
+	}
+	return []types.Transaction{
+		openTx.Transaction(testOpenTxVersion),
+		closeTx.Transaction(testCloseTxVersion),
+		unilateralCloseTx.Transaction(testUnilateralCloseTxVersion),
+		settleTx.Transaction(testSettleTxVersion),
+	}
+}
+
+func TestChannelTransactionEncodingRoundtrip(t *testing.T) {
+	defer registerTestTransactionVersions()()
+
+	for idx, tx := range testChannelTransactions(t) {
+		// JSON roundtrip
+		b, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to JSON-marshal: %v", idx, err)
+		}
+		var jsonTx types.Transaction
+		err = json.Unmarshal(b, &jsonTx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to JSON-unmarshal: %v", idx, err)
+		}
+		if jsonTx.ID() != tx.ID() {
+			t.Errorf("tx #%d: JSON roundtrip changed ID: %v != %v", idx, jsonTx.ID(), tx.ID())
+		}
+
+		// binary roundtrip
+		b, err = siabin.Marshal(tx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to binary-marshal: %v", idx, err)
+		}
+		var binTx types.Transaction
+		err = siabin.Unmarshal(b, &binTx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to binary-unmarshal: %v", idx, err)
+		}
+		if binTx.ID() != tx.ID() {
+			t.Errorf("tx #%d: binary roundtrip changed ID: %v != %v", idx, binTx.ID(), tx.ID())
+		}
+		if !reflect.DeepEqual(binTx.Extension, jsonTx.Extension) {
+			t.Errorf("tx #%d: binary and JSON decoded extensions differ: %v != %v", idx, binTx.Extension, jsonTx.Extension)
+		}
+	}
+}
+
+func TestChannelTransactionFromTransaction(t *testing.T) {
+	defer registerTestTransactionVersions()()
+
+	txs := testChannelTransactions(t)
+
+	cotx, err := ChannelOpenTransactionFromTransaction(txs[0], testOpenTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cctx, err := ChannelCloseTransactionFromTransaction(txs[1], testCloseTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cctx.ChannelID != cotx.ChannelID() {
+		t.Errorf("unexpected channel ID: %v != %v", cctx.ChannelID, cotx.ChannelID())
+	}
+	uctx, err := ChannelUnilateralCloseTransactionFromTransaction(txs[2], testUnilateralCloseTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uctx.ChannelID() != cotx.ChannelID() {
+		t.Errorf("unexpected channel ID: %v != %v", uctx.ChannelID(), cotx.ChannelID())
+	}
+	cstx, err := ChannelSettleTransactionFromTransaction(txs[3], testSettleTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cstx.ChannelID != cotx.ChannelID() {
+		t.Errorf("unexpected channel ID: %v != %v", cstx.ChannelID, cotx.ChannelID())
+	}
+
+	// a transaction of one channel type can never be used as another
+	_, err = ChannelOpenTransactionFromTransaction(txs[1], testOpenTxVersion)
+	if err == nil {
+		t.Error("expected a channel close tx not to be accepted as a channel open tx")
+	}
+	_, err = ChannelSettleTransactionFromTransaction(txs[1], testCloseTxVersion)
+	if err == nil {
+		t.Error("expected a channel close tx not to be accepted as a channel settle tx")
+	}
+}
+
+func TestSignedChannelCommitmentVerify(t *testing.T) {
+	ch, senderSK, receiverSK := testChannel()
+
+	// the initial commitment requires no signatures
+	initial := SignedChannelCommitment{Commitment: ch.InitialCommitment()}
+	if err := initial.Verify(ch); err != nil {
+		t.Errorf("expected initial commitment to be valid: %v", err)
+	}
+
+	commitment := SignedChannelCommitment{
+		Commitment: ChannelCommitment{
+			ChannelID:       ch.ID,
+			Sequence:        1,
+			SenderBalance:   types.NewCurrency64(90),
+			ReceiverBalance: types.NewCurrency64(10),
+		},
+	}
+	if err := commitment.Sign(ch, ChannelPartySender, senderSK); err != nil {
+		t.Fatal(err)
+	}
+	if err := commitment.Verify(ch); err == nil {
+		t.Error("expected commitment signed only by the sender to be invalid")
+	}
+	if err := commitment.Sign(ch, ChannelPartyReceiver, senderSK); err != nil {
+		t.Fatal(err)
+	}
+	if err := commitment.Verify(ch); err == nil {
+		t.Error("expected commitment signed using the wrong receiver key to be invalid")
+	}
+	if err := commitment.Sign(ch, ChannelPartyReceiver, receiverSK); err != nil {
+		t.Fatal(err)
+	}
+	if err := commitment.Verify(ch); err != nil {
+		t.Errorf("expected commitment signed by both parties to be valid: %v", err)
+	}
+
+	// any change to a signed commitment invalidates it
+	tampered := commitment
+	tampered.Commitment.ReceiverBalance = types.NewCurrency64(20)
+	tampered.Commitment.SenderBalance = types.NewCurrency64(80)
+	if err := tampered.Verify(ch); err == nil {
+		t.Error("expected tampered commitment to be invalid")
+	}
+
+	// balances cannot exceed the capacity of the channel
+	invalid := SignedChannelCommitment{
+		Commitment: ChannelCommitment{
+			ChannelID:       ch.ID,
+			Sequence:        2,
+			SenderBalance:   types.NewCurrency64(90),
+			ReceiverBalance: types.NewCurrency64(20),
+		},
+	}
+	invalid.Sign(ch, ChannelPartySender, senderSK)
+	invalid.Sign(ch, ChannelPartyReceiver, receiverSK)
+	if err := invalid.Verify(ch); err == nil {
+		t.Error("expected commitment exceeding the channel capacity to be invalid")
+	}
+}
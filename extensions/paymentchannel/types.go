@@ -0,0 +1,458 @@
+package paymentchannel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// These Specifiers are used internally when calculating a Transaction's ID,
+// the IDs of payment channels and the hashes of channel commitments.
+// See Rivine's Specifier for more details.
+var (
+	SpecifierChannelOpenTransaction            = types.Specifier{'c', 'h', 'a', 'n', 'n', 'e', 'l', ' ', 'o', 'p', 'e', 'n', ' ', 't', 'x'}
+	SpecifierChannelCloseTransaction           = types.Specifier{'c', 'h', 'a', 'n', 'n', 'e', 'l', ' ', 'c', 'l', 'o', 's', 'e', ' ', 't', 'x'}
+	SpecifierChannelUnilateralCloseTransaction = types.Specifier{'c', 'h', 'a', 'n', 'n', 'e', 'l', ' ', 'u', 'c', 'l', 'o', 's', 'e', ' ', 't'}
+	SpecifierChannelSettleTransaction          = types.Specifier{'c', 'h', 'a', 'n', 'n', 'e', 'l', ' ', 's', 'e', 't', 't', 'l', 'e', ' ', 't'}
+	SpecifierPaymentChannel                    = types.Specifier{'p', 'a', 'y', 'm', 'e', 'n', 't', ' ', 'c', 'h', 'a', 'n', 'n', 'e', 'l'}
+	SpecifierChannelCommitment                 = types.Specifier{'c', 'h', 'a', 'n', 'n', 'e', 'l', ' ', 'c', 'o', 'm', 'm', 'i', 't'}
+)
+
+const (
+	// MinDisputePeriod defines the minimum amount of blocks a payment channel
+	// has to remain in the closing state after a unilateral close,
+	// giving the counterparty the time to dispute the submitted commitment.
+	MinDisputePeriod types.BlockHeight = 6
+)
+
+var (
+	// ErrChannelNotFound is returned in case a payment channel could not be found.
+	ErrChannelNotFound = errors.New("payment channel not found")
+)
+
+type (
+	// ChannelID uniquely identifies a payment channel.
+	ChannelID crypto.Hash
+
+	// ChannelParty identifies one of the two parties of a payment channel.
+	ChannelParty uint8
+
+	// ChannelStatus defines the on-chain status of a payment channel.
+	ChannelStatus uint8
+
+	// Channel is the state of a payment channel as tracked by the consensus plugin.
+	Channel struct {
+		ID ChannelID `json:"id"`
+		// Sender is the public key of the party that funded the channel,
+		// and which owns the full capacity of the channel at the time it is opened.
+		Sender types.PublicKey `json:"sender"`
+		// Receiver is the public key of the counterparty of the channel.
+		Receiver types.PublicKey `json:"receiver"`
+		// Capacity is the total amount of coins locked in the channel.
+		Capacity types.Currency `json:"capacity"`
+		// DisputePeriod is the amount of blocks the channel remains in the closing state,
+		// after it was closed unilaterally.
+		DisputePeriod types.BlockHeight `json:"disputeperiod"`
+		// Status is the current on-chain status of the channel.
+		Status ChannelStatus `json:"status"`
+		// Commitment is the most recent commitment submitted on-chain,
+		// only defined once the channel was closed unilaterally.
+		Commitment ChannelCommitment `json:"commitment"`
+		// DisputeDeadline is the block height starting from which the channel can be settled,
+		// only defined once the channel was closed unilaterally.
+		DisputeDeadline types.BlockHeight `json:"disputedeadline"`
+		// CreationHeight is the height of the block which contains the
+		// transaction that opened this channel.
+		CreationHeight types.BlockHeight `json:"creationheight"`
+		// CreationTime is the timestamp of the block which contains the
+		// transaction that opened this channel.
+		CreationTime types.Timestamp `json:"creationtime"`
+		// CreationTransactionID is the ID of the transaction that opened this channel.
+		CreationTransactionID types.TransactionID `json:"creationtransactionid"`
+	}
+
+	// ChannelCommitment defines how the capacity of a payment channel is divided
+	// among its parties. Commitments are exchanged and signed off-chain,
+	// with each update increasing the sequence number.
+	ChannelCommitment struct {
+		ChannelID ChannelID `json:"channelid"`
+		// Sequence orders the commitments of a channel,
+		// a commitment with a higher sequence number replaces all commitments before it.
+		Sequence uint64 `json:"sequence"`
+		// SenderBalance is the amount of coins owned by the sender.
+		SenderBalance types.Currency `json:"senderbalance"`
+		// ReceiverBalance is the amount of coins owned by the receiver.
+		ReceiverBalance types.Currency `json:"receiverbalance"`
+		// HTLCs are the hash-locked time-locked payments that are still pending.
+		HTLCs []ChannelHTLC `json:"htlcs,omitempty"`
+	}
+
+	// ChannelHTLC is a hash-locked time-locked payment, pending within a channel commitment.
+	// When the channel gets settled it is paid out as an atomic swap output,
+	// which can be claimed by the payee using the secret or refunded to the payer once the time lock expired.
+	ChannelHTLC struct {
+		// Payer is the party paying the amount, the other party is the payee.
+		Payer        ChannelParty                 `json:"payer"`
+		Amount       types.Currency               `json:"amount"`
+		HashedSecret types.AtomicSwapHashedSecret `json:"hashedsecret"`
+		TimeLock     types.Timestamp              `json:"timelock"`
+	}
+
+	// SignedChannelCommitment is a channel commitment signed by both parties of the channel.
+	SignedChannelCommitment struct {
+		Commitment        ChannelCommitment `json:"commitment"`
+		SenderSignature   types.ByteSlice   `json:"sendersignature,omitempty"`
+		ReceiverSignature types.ByteSlice   `json:"receiversignature,omitempty"`
+	}
+)
+
+// The parties of a payment channel.
+const (
+	ChannelPartySender ChannelParty = iota + 1
+	ChannelPartyReceiver
+)
+
+// The on-chain statuses of a payment channel.
+const (
+	// ChannelStatusOpen is the status of a channel that can be used for off-chain payments.
+	ChannelStatusOpen ChannelStatus = iota + 1
+	// ChannelStatusClosing is the status of a channel that was closed unilaterally,
+	// and which can still be disputed until its dispute deadline.
+	ChannelStatusClosing
+	// ChannelStatusClosed is the status of a channel that was closed cooperatively or settled.
+	ChannelStatusClosed
+)
+
+// NewChannelID computes the ID of a new payment channel,
+// using the nonce of the transaction that opens it and the public keys of both parties.
+func NewChannelID(nonce types.TransactionNonce, sender, receiver types.PublicKey) (id ChannelID) {
+	h := crypto.NewHash()
+	rivbin.NewEncoder(h).EncodeAll(
+		SpecifierPaymentChannel,
+		nonce,
+		sender,
+		receiver,
+	)
+	h.Sum(id[:0])
+	return
+}
+
+// String prints the channel id in hex.
+func (id ChannelID) String() string {
+	return crypto.Hash(id).String()
+}
+
+// LoadString loads the given channel id from a hex string
+func (id *ChannelID) LoadString(str string) error {
+	return (*crypto.Hash)(id).LoadString(str)
+}
+
+// MarshalJSON marshals a channel id as a hex string.
+func (id ChannelID) MarshalJSON() ([]byte, error) {
+	return crypto.Hash(id).MarshalJSON()
+}
+
+// UnmarshalJSON decodes the json hex string of the channel id.
+func (id *ChannelID) UnmarshalJSON(b []byte) error {
+	return (*crypto.Hash)(id).UnmarshalJSON(b)
+}
+
+// String returns the channel party as a human-readable string.
+func (party ChannelParty) String() string {
+	switch party {
+	case ChannelPartySender:
+		return "sender"
+	case ChannelPartyReceiver:
+		return "receiver"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(party))
+	}
+}
+
+// Counterparty returns the other party of the channel.
+func (party ChannelParty) Counterparty() ChannelParty {
+	if party == ChannelPartySender {
+		return ChannelPartyReceiver
+	}
+	return ChannelPartySender
+}
+
+// MarshalJSON marshals a channel party as a string.
+func (party ChannelParty) MarshalJSON() ([]byte, error) {
+	return json.Marshal(party.String())
+}
+
+// UnmarshalJSON decodes a channel party from a string.
+func (party *ChannelParty) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	switch str {
+	case "sender":
+		*party = ChannelPartySender
+	case "receiver":
+		*party = ChannelPartyReceiver
+	default:
+		return fmt.Errorf("unknown channel party %q", str)
+	}
+	return nil
+}
+
+// String returns the channel status as a human-readable string.
+func (status ChannelStatus) String() string {
+	switch status {
+	case ChannelStatusOpen:
+		return "open"
+	case ChannelStatusClosing:
+		return "closing"
+	case ChannelStatusClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(status))
+	}
+}
+
+// MarshalJSON marshals a channel status as a string.
+func (status ChannelStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(status.String())
+}
+
+// UnmarshalJSON decodes a channel status from a string.
+func (status *ChannelStatus) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	switch str {
+	case "open":
+		*status = ChannelStatusOpen
+	case "closing":
+		*status = ChannelStatusClosing
+	case "closed":
+		*status = ChannelStatusClosed
+	default:
+		return fmt.Errorf("unknown channel status %q", str)
+	}
+	return nil
+}
+
+// PublicKey returns the public key of the given party.
+func (ch *Channel) PublicKey(party ChannelParty) types.PublicKey {
+	if party == ChannelPartySender {
+		return ch.Sender
+	}
+	return ch.Receiver
+}
+
+// UnlockHash returns the (public key) unlock hash of the given party.
+func (ch *Channel) UnlockHash(party ChannelParty) types.UnlockHash {
+	uh, _ := types.NewPubKeyUnlockHash(ch.PublicKey(party))
+	return uh
+}
+
+// Condition returns the condition which has to be fulfilled by the given party,
+// and which is used to pay out the balance of that party.
+func (ch *Channel) Condition(party ChannelParty) types.UnlockConditionProxy {
+	return types.NewCondition(types.NewUnlockHashCondition(ch.UnlockHash(party)))
+}
+
+// PartyOf returns the party owning the given unlock hash,
+// returning false if the unlock hash belongs to neither party.
+func (ch *Channel) PartyOf(uh types.UnlockHash) (ChannelParty, bool) {
+	switch uh {
+	case ch.UnlockHash(ChannelPartySender):
+		return ChannelPartySender, true
+	case ch.UnlockHash(ChannelPartyReceiver):
+		return ChannelPartyReceiver, true
+	default:
+		return 0, false
+	}
+}
+
+// InitialCommitment returns the implicit commitment of a freshly opened channel,
+// assigning the full capacity to the sender.
+func (ch *Channel) InitialCommitment() ChannelCommitment {
+	return ChannelCommitment{
+		ChannelID:     ch.ID,
+		Sequence:      0,
+		SenderBalance: ch.Capacity,
+	}
+}
+
+// PayoutCoinOutputs returns the coin outputs used to settle the channel using the given commitment:
+// the (non-zero) balances of the sender and receiver, followed by an atomic swap output for each pending HTLC.
+func (ch *Channel) PayoutCoinOutputs(commitment ChannelCommitment) []types.CoinOutput {
+	var outputs []types.CoinOutput
+	for _, party := range []ChannelParty{ChannelPartySender, ChannelPartyReceiver} {
+		if balance := commitment.Balance(party); !balance.IsZero() {
+			outputs = append(outputs, types.CoinOutput{
+				Value:     balance,
+				Condition: ch.Condition(party),
+			})
+		}
+	}
+	for _, htlc := range commitment.HTLCs {
+		outputs = append(outputs, types.CoinOutput{
+			Value: htlc.Amount,
+			Condition: types.NewCondition(&types.AtomicSwapCondition{
+				Sender:       ch.UnlockHash(htlc.Payer),
+				Receiver:     ch.UnlockHash(htlc.Payer.Counterparty()),
+				HashedSecret: htlc.HashedSecret,
+				TimeLock:     htlc.TimeLock,
+			}),
+		})
+	}
+	return outputs
+}
+
+// Balance returns the balance of the given party.
+func (c *ChannelCommitment) Balance(party ChannelParty) types.Currency {
+	if party == ChannelPartySender {
+		return c.SenderBalance
+	}
+	return c.ReceiverBalance
+}
+
+// Hash returns the hash of the commitment, as signed by both parties.
+func (c *ChannelCommitment) Hash() (hash crypto.Hash) {
+	h := crypto.NewHash()
+	rivbin.NewEncoder(h).EncodeAll(
+		SpecifierChannelCommitment,
+		c.ChannelID,
+		c.Sequence,
+		c.SenderBalance,
+		c.ReceiverBalance,
+		c.HTLCs,
+	)
+	h.Sum(hash[:0])
+	return
+}
+
+// ValidateBalances ensures the balances and pending HTLCs of the commitment
+// add up exactly to the given channel capacity.
+func (c *ChannelCommitment) ValidateBalances(capacity types.Currency) error {
+	sum := c.SenderBalance.Add(c.ReceiverBalance)
+	for idx, htlc := range c.HTLCs {
+		if htlc.Payer != ChannelPartySender && htlc.Payer != ChannelPartyReceiver {
+			return fmt.Errorf("HTLC #%d has an invalid payer: %v", idx, htlc.Payer)
+		}
+		if htlc.Amount.IsZero() {
+			return fmt.Errorf("HTLC #%d has no amount", idx)
+		}
+		if htlc.HashedSecret == (types.AtomicSwapHashedSecret{}) {
+			return fmt.Errorf("HTLC #%d has a nil hashed secret", idx)
+		}
+		sum = sum.Add(htlc.Amount)
+	}
+	if !sum.Equals(capacity) {
+		return fmt.Errorf("commitment assigns %s coins, while the channel capacity is %s", sum.String(), capacity.String())
+	}
+	return nil
+}
+
+// Verify ensures the signed commitment is a valid commitment for the given channel,
+// signed by both parties. The initial commitment (sequence 0) does not require any signatures.
+func (sc *SignedChannelCommitment) Verify(ch Channel) error {
+	if sc.Commitment.ChannelID != ch.ID {
+		return fmt.Errorf("commitment is for channel %s, not for channel %s", sc.Commitment.ChannelID.String(), ch.ID.String())
+	}
+	err := sc.Commitment.ValidateBalances(ch.Capacity)
+	if err != nil {
+		return err
+	}
+	if sc.Commitment.Sequence == 0 {
+		initial := ch.InitialCommitment()
+		if sc.Commitment.Hash() != initial.Hash() {
+			return errors.New("commitment with sequence 0 has to be the initial commitment of the channel")
+		}
+		return nil
+	}
+	hash := sc.Commitment.Hash()
+	err = VerifyCommitmentSignature(ch.Sender, hash, sc.SenderSignature)
+	if err != nil {
+		return fmt.Errorf("invalid sender signature: %v", err)
+	}
+	err = VerifyCommitmentSignature(ch.Receiver, hash, sc.ReceiverSignature)
+	if err != nil {
+		return fmt.Errorf("invalid receiver signature: %v", err)
+	}
+	return nil
+}
+
+// Signature returns the signature of the given party.
+func (sc *SignedChannelCommitment) Signature(party ChannelParty) types.ByteSlice {
+	if party == ChannelPartySender {
+		return sc.SenderSignature
+	}
+	return sc.ReceiverSignature
+}
+
+// Sign signs the commitment as the given party of the channel,
+// using the given (private) key which has to match the public key of that party.
+func (sc *SignedChannelCommitment) Sign(ch Channel, party ChannelParty, key interface{}) error {
+	sig, err := SignCommitmentHash(ch.PublicKey(party), sc.Commitment.Hash(), key)
+	if err != nil {
+		return err
+	}
+	if party == ChannelPartySender {
+		sc.SenderSignature = sig
+	} else {
+		sc.ReceiverSignature = sig
+	}
+	return nil
+}
+
+// SignCommitmentHash signs the hash of a channel commitment,
+// using the given (private) key, interpreted using the algorithm of the given public key.
+func SignCommitmentHash(pk types.PublicKey, hash crypto.Hash, key interface{}) (types.ByteSlice, error) {
+	algorithm, err := types.GetSignatureAlgorithm(pk.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return algorithm.SignHash(hash, key)
+}
+
+// VerifyCommitmentSignature verifies the signature of a channel commitment hash,
+// using the algorithm of the given public key.
+func VerifyCommitmentSignature(pk types.PublicKey, hash crypto.Hash, sig types.ByteSlice) error {
+	algorithm, err := types.GetSignatureAlgorithm(pk.Algorithm)
+	if err != nil {
+		return err
+	}
+	err = algorithm.ValidatePublicKey(pk.Key)
+	if err != nil {
+		return err
+	}
+	return algorithm.VerifyHash(hash, pk.Key, sig)
+}
+
+// validatePartyPublicKey ensures the public key can be used as a party of a payment channel,
+// opened in a block of the given height.
+func validatePartyPublicKey(pk types.PublicKey, height types.BlockHeight) error {
+	algorithm, err := types.GetSignatureAlgorithm(pk.Algorithm)
+	if err != nil {
+		return err
+	}
+	err = types.ValidateSignatureAlgorithmActivation(pk, height)
+	if err != nil {
+		return err
+	}
+	return algorithm.ValidatePublicKey(pk.Key)
+}
+
+// ChannelGetter allows you to look up a payment channel.
+//
+// For the daemon this interface is implemented directly by the consensus plugin,
+// while for a client this is implemented using the REST API of a rivine daemon.
+type ChannelGetter interface {
+	// GetChannel returns the payment channel for the given ID,
+	// returning ErrChannelNotFound if it doesn't exist.
+	GetChannel(id ChannelID) (Channel, error)
+}
@@ -0,0 +1,303 @@
+package relativetimelock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// ConditionTypeRelativeTimeLock defines an unlock condition which locks another condition,
+	// until a given amount of blocks or seconds has passed since the output was created.
+	// The internal condition has to be one of: [
+	// NilCondition,
+	// UnlockHashCondition (0x01 unlock hash type is the only standard one at the moment, others aren't allowed),
+	// MultiSignatureCondition,
+	// ]
+	ConditionTypeRelativeTimeLock types.ConditionType = 6
+
+	// MaxLockDurationBlocks is the maximum lock duration of a standard condition expressed in blocks.
+	MaxLockDurationBlocks uint64 = 1 << 24
+	// MaxLockDurationSeconds is the maximum lock duration of a standard condition expressed in seconds.
+	MaxLockDurationSeconds uint64 = 1 << 32
+)
+
+// The units in which the lock duration of a relative time lock condition can be expressed.
+const (
+	// LockUnitBlocks expresses the lock duration as an amount of blocks.
+	LockUnitBlocks LockUnit = iota
+	// LockUnitSeconds expresses the lock duration as an amount of seconds.
+	LockUnitSeconds
+)
+
+var (
+	// ErrRelativeTimeLockNotReached is returned in case a RelativeTimeLockCondition is fulfilled,
+	// prior to its lock duration having passed since the creation of the output.
+	ErrRelativeTimeLockNotReached = errors.New("relative time lock has not yet been reached")
+)
+
+// RegisterUnlockConditionType registers the relative time lock condition type,
+// such that outputs can be locked until some time has passed since their creation.
+//
+// As this type is part of the consensus rules,
+// all nodes of a network should register it at the same time.
+func RegisterUnlockConditionType() {
+	types.RegisterUnlockConditionType(ConditionTypeRelativeTimeLock,
+		func() types.MarshalableUnlockCondition { return &RelativeTimeLockCondition{} })
+}
+
+// UnregisterUnlockConditionType unregisters the relative time lock condition type.
+func UnregisterUnlockConditionType() {
+	types.RegisterUnlockConditionType(ConditionTypeRelativeTimeLock, nil)
+}
+
+type (
+	// LockUnit defines the unit of the lock duration of a RelativeTimeLockCondition.
+	LockUnit uint8
+
+	// RelativeTimeLockCondition implements the ConditionTypeRelativeTimeLock ConditionType.
+	// See ConditionTypeRelativeTimeLock for more information.
+	RelativeTimeLockCondition struct {
+		// LockDuration defines the amount of blocks or seconds,
+		// that have to pass since the creation of the output, before it can be spent.
+		LockDuration uint64
+		// LockUnit defines whether the LockDuration is expressed in blocks or seconds.
+		LockUnit LockUnit
+		// Condition defines the condition which has to be fulfilled
+		// on top of the lock defined by this condition.
+		// See ConditionTypeRelativeTimeLock in order to know which conditions are supported.
+		Condition types.MarshalableUnlockCondition
+	}
+)
+
+var (
+	_ types.MarshalableUnlockCondition       = (*RelativeTimeLockCondition)(nil)
+	_ types.MarshalableUnlockConditionGetter = (*RelativeTimeLockCondition)(nil)
+)
+
+// String implements fmt.Stringer.String
+func (lu LockUnit) String() string {
+	switch lu {
+	case LockUnitBlocks:
+		return "blocks"
+	case LockUnitSeconds:
+		return "seconds"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(lu))
+	}
+}
+
+// LoadString loads a lock unit from its string representation.
+func (lu *LockUnit) LoadString(str string) error {
+	switch str {
+	case "blocks":
+		*lu = LockUnitBlocks
+	case "seconds":
+		*lu = LockUnitSeconds
+	default:
+		return fmt.Errorf("unknown lock unit %q", str)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (lu LockUnit) MarshalJSON() ([]byte, error) {
+	if lu != LockUnitBlocks && lu != LockUnitSeconds {
+		return nil, fmt.Errorf("unknown lock unit %d", uint8(lu))
+	}
+	return json.Marshal(lu.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (lu *LockUnit) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	return lu.LoadString(str)
+}
+
+// NewRelativeTimeLockCondition creates a new RelativeTimeLockCondition.
+// If no MarshalableUnlockCondition is given, the NilCondition is assumed.
+func NewRelativeTimeLockCondition(lockDuration uint64, lockUnit LockUnit, condition types.MarshalableUnlockCondition) *RelativeTimeLockCondition {
+	if condition == nil {
+		condition = &types.NilCondition{}
+	}
+	return &RelativeTimeLockCondition{
+		LockDuration: lockDuration,
+		LockUnit:     lockUnit,
+		Condition:    condition,
+	}
+}
+
+// Fulfill implements UnlockCondition.Fulfill
+func (rtl *RelativeTimeLockCondition) Fulfill(fulfillment types.UnlockFulfillment, ctx types.FulfillContext) error {
+	if !rtl.Fulfillable(types.FulfillableContext{
+		BlockHeight:          ctx.BlockHeight,
+		BlockTime:            ctx.BlockTime,
+		OutputCreationHeight: ctx.OutputCreationHeight,
+		OutputCreationTime:   ctx.OutputCreationTime,
+		HasOutputCreation:    ctx.HasOutputCreation,
+	}) {
+		return ErrRelativeTimeLockNotReached
+	}
+
+	// relative time lock hash been reached,
+	// delegate the actual fulfillment to the given fulfillment, if supported
+	switch tf := fulfillment.(type) {
+	case *types.SingleSignatureFulfillment:
+		return rtl.Condition.Fulfill(tf, ctx)
+	case *types.MultiSignatureFulfillment:
+		return rtl.Condition.Fulfill(tf, ctx)
+	default:
+		return types.ErrUnexpectedUnlockFulfillment
+	}
+}
+
+// ConditionType implements UnlockCondition.ConditionType
+func (rtl *RelativeTimeLockCondition) ConditionType() types.ConditionType {
+	return ConditionTypeRelativeTimeLock
+}
+
+// IsStandardCondition implements UnlockCondition.IsStandardCondition
+func (rtl *RelativeTimeLockCondition) IsStandardCondition(ctx types.ValidationContext) error {
+	if rtl.LockDuration == 0 {
+		return errors.New("lock duration has to be defined")
+	}
+	switch rtl.LockUnit {
+	case LockUnitBlocks:
+		if rtl.LockDuration > MaxLockDurationBlocks {
+			return fmt.Errorf("lock duration cannot exceed %d blocks", MaxLockDurationBlocks)
+		}
+	case LockUnitSeconds:
+		if rtl.LockDuration > MaxLockDurationSeconds {
+			return fmt.Errorf("lock duration cannot exceed %d seconds", MaxLockDurationSeconds)
+		}
+	default:
+		return fmt.Errorf("unknown lock unit %d", uint8(rtl.LockUnit))
+	}
+	switch ct := rtl.Condition.ConditionType(); ct {
+	case types.ConditionTypeUnlockHash:
+		uh := rtl.Condition.UnlockHash()
+		if uh.Hash == (crypto.Hash{}) {
+			return errors.New("nil crypto hash cannot be used as unlock hash")
+		}
+		if uh.Type != types.UnlockTypePubKey {
+			return errors.New("non-standard unlock hash type")
+		}
+		return nil
+	case types.ConditionTypeMultiSignature:
+		return rtl.Condition.IsStandardCondition(ctx)
+	case types.ConditionTypeNil:
+		return nil
+	default:
+		return errors.New("unexpected internal unlock condition used as part of relative time lock condition")
+	}
+}
+
+// UnlockHash implements UnlockCondition.UnlockHash
+func (rtl *RelativeTimeLockCondition) UnlockHash() types.UnlockHash {
+	return rtl.Condition.UnlockHash()
+}
+
+// GetMarshalableUnlockCondition implements MarshalableUnlockConditionGetter.GetMarshalableUnlockCondition
+func (rtl *RelativeTimeLockCondition) GetMarshalableUnlockCondition() types.MarshalableUnlockCondition {
+	return rtl.Condition
+}
+
+// Equal implements UnlockCondition.Equal
+func (rtl *RelativeTimeLockCondition) Equal(c types.UnlockCondition) bool {
+	ortl, ok := c.(*RelativeTimeLockCondition)
+	if !ok {
+		return false
+	}
+	return rtl.LockDuration == ortl.LockDuration && rtl.LockUnit == ortl.LockUnit &&
+		rtl.Condition.Equal(ortl.Condition)
+}
+
+// Fulfillable implements UnlockCondition.Fulfillable
+//
+// The creation of the output is taken from the given context,
+// the condition is never fulfillable within a context which does not define it.
+func (rtl *RelativeTimeLockCondition) Fulfillable(ctx types.FulfillableContext) bool {
+	if !ctx.HasOutputCreation {
+		return false
+	}
+	// compare without adding the lock duration to the creation height (or time), as that addition can overflow
+	if rtl.LockUnit == LockUnitBlocks {
+		return ctx.BlockHeight >= ctx.OutputCreationHeight &&
+			uint64(ctx.BlockHeight-ctx.OutputCreationHeight) >= rtl.LockDuration
+	}
+	return ctx.BlockTime >= ctx.OutputCreationTime &&
+		uint64(ctx.BlockTime-ctx.OutputCreationTime) >= rtl.LockDuration
+}
+
+// Marshal implements MarshalableUnlockCondition.Marshal
+//
+// The internal condition is encoded as a condition proxy,
+// such that it can be decoded using the registered condition types.
+func (rtl *RelativeTimeLockCondition) Marshal(f types.MarshalFunc) ([]byte, error) {
+	return f(rtl.LockDuration, uint8(rtl.LockUnit), types.NewCondition(rtl.Condition))
+}
+
+// Unmarshal implements MarshalableUnlockCondition.Unmarshal
+func (rtl *RelativeTimeLockCondition) Unmarshal(b []byte, f types.UnmarshalFunc) error {
+	var (
+		lu        uint8
+		condition types.UnlockConditionProxy
+	)
+	err := f(b, &rtl.LockDuration, &lu, &condition)
+	if err != nil {
+		return err
+	}
+	rtl.LockUnit = LockUnit(lu)
+	if condition.Condition == nil {
+		rtl.Condition = &types.NilCondition{}
+	} else {
+		rtl.Condition = condition.Condition
+	}
+	return nil
+}
+
+type jsonRelativeTimeLockCondition struct {
+	LockDuration uint64                     `json:"lockduration"`
+	LockUnit     LockUnit                   `json:"lockunit"`
+	Condition    types.UnlockConditionProxy `json:"condition"`
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+//
+// This function is required, as to ensure
+// the underlying properties are properly serialized,
+// including the type of the internal condition.
+func (rtl *RelativeTimeLockCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonRelativeTimeLockCondition{
+		LockDuration: rtl.LockDuration,
+		LockUnit:     rtl.LockUnit,
+		Condition:    types.NewCondition(rtl.Condition),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+//
+// This function is required, as to be able to unmarshal
+// the internal condition based on the encoded condition type.
+func (rtl *RelativeTimeLockCondition) UnmarshalJSON(b []byte) error {
+	var jrtl jsonRelativeTimeLockCondition
+	err := json.Unmarshal(b, &jrtl)
+	if err != nil {
+		return err
+	}
+	rtl.LockDuration = jrtl.LockDuration
+	rtl.LockUnit = jrtl.LockUnit
+	if jrtl.Condition.Condition == nil {
+		rtl.Condition = &types.NilCondition{}
+	} else {
+		rtl.Condition = jrtl.Condition.Condition
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package relativetimelock
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestRelativeTimeLockConditionEncoding(t *testing.T) {
+	RegisterUnlockConditionType()
+	defer UnregisterUnlockConditionType()
+
+	var uhs types.UnlockHashSlice
+	for i := 0; i < 2; i++ {
+		_, pk := crypto.GenerateKeyPair()
+		uh, err := types.NewPubKeyUnlockHash(types.Ed25519PublicKey(pk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		uhs = append(uhs, uh)
+	}
+	for _, c := range []*RelativeTimeLockCondition{
+		NewRelativeTimeLockCondition(10, LockUnitBlocks, nil),
+		NewRelativeTimeLockCondition(3600, LockUnitSeconds, types.NewUnlockHashCondition(uhs[0])),
+		NewRelativeTimeLockCondition(5, LockUnitBlocks, types.NewMultiSignatureCondition(uhs, 1)),
+	} {
+		condition := types.NewCondition(c)
+		if err := condition.IsStandardCondition(types.ValidationContext{}); err != nil {
+			t.Fatal(err)
+		}
+		if condition.UnlockHash() != c.Condition.UnlockHash() {
+			t.Errorf("unexpected unlock hash: %v", condition.UnlockHash())
+		}
+
+		b, err := json.Marshal(condition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var jsonCondition types.UnlockConditionProxy
+		if err = json.Unmarshal(b, &jsonCondition); err != nil {
+			t.Fatal(err)
+		}
+		b, err = siabin.Marshal(condition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var siaCondition types.UnlockConditionProxy
+		if err = siabin.Unmarshal(b, &siaCondition); err != nil {
+			t.Fatal(err)
+		}
+		b, err = rivbin.Marshal(condition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rivCondition types.UnlockConditionProxy
+		if err = rivbin.Unmarshal(b, &rivCondition); err != nil {
+			t.Fatal(err)
+		}
+		for _, decoded := range []types.UnlockConditionProxy{jsonCondition, siaCondition, rivCondition} {
+			if !condition.Equal(decoded) {
+				t.Errorf("%v != %v", decoded, condition)
+			}
+		}
+	}
+
+	// a lock duration is required, and the lock unit has to be known
+	for _, c := range []*RelativeTimeLockCondition{
+		NewRelativeTimeLockCondition(0, LockUnitBlocks, nil),
+		NewRelativeTimeLockCondition(1, LockUnit(2), nil),
+	} {
+		if err := c.IsStandardCondition(types.ValidationContext{}); err == nil {
+			t.Errorf("expected %v to be non-standard", c)
+		}
+	}
+}
+
+func TestRelativeTimeLockConditionFulfill(t *testing.T) {
+	sk, pk := crypto.GenerateKeyPair()
+	fulfillment := types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(pk))
+	txn := types.Transaction{
+		Version: types.TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []types.CoinInput{
+			{Fulfillment: types.NewFulfillment(fulfillment)},
+		},
+	}
+	err := fulfillment.Sign(types.FulfillmentSignContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  txn,
+		Key:          sk,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uh, err := types.NewPubKeyUnlockHash(types.Ed25519PublicKey(pk))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Condition            *RelativeTimeLockCondition
+		BlockHeight          types.BlockHeight
+		BlockTime            types.Timestamp
+		OutputCreationHeight types.BlockHeight
+		OutputCreationTime   types.Timestamp
+		ExpectedError        error
+	}{
+		{NewRelativeTimeLockCondition(10, LockUnitBlocks, types.NewUnlockHashCondition(uh)), 109, 0, 100, 0, ErrRelativeTimeLockNotReached},
+		{NewRelativeTimeLockCondition(10, LockUnitBlocks, types.NewUnlockHashCondition(uh)), 110, 0, 100, 0, nil},
+		{NewRelativeTimeLockCondition(10, LockUnitBlocks, nil), 200, 0, 100, 0, nil},
+		{NewRelativeTimeLockCondition(3600, LockUnitSeconds, types.NewUnlockHashCondition(uh)), 0, 5000, 0, 2000, ErrRelativeTimeLockNotReached},
+		{NewRelativeTimeLockCondition(3600, LockUnitSeconds, types.NewUnlockHashCondition(uh)), 0, 5600, 0, 2000, nil},
+		// the block height does not matter for a lock expressed in seconds, and vice versa
+		{NewRelativeTimeLockCondition(3600, LockUnitSeconds, nil), 1000000, 5000, 0, 2000, ErrRelativeTimeLockNotReached},
+		{NewRelativeTimeLockCondition(10, LockUnitBlocks, nil), 109, 1000000, 100, 0, ErrRelativeTimeLockNotReached},
+		// a lock duration which overflows when added to the creation height (or time) locks the output forever
+		{NewRelativeTimeLockCondition(math.MaxUint64, LockUnitBlocks, nil), 101, 0, 100, 0, ErrRelativeTimeLockNotReached},
+		{NewRelativeTimeLockCondition(math.MaxUint64-50, LockUnitBlocks, nil), math.MaxUint64, 0, 100, 0, ErrRelativeTimeLockNotReached},
+		{NewRelativeTimeLockCondition(math.MaxUint64, LockUnitSeconds, nil), 0, 2001, 0, 2000, ErrRelativeTimeLockNotReached},
+		// an output created after the current block is never fulfillable
+		{NewRelativeTimeLockCondition(1, LockUnitBlocks, nil), 50, 0, 100, 0, ErrRelativeTimeLockNotReached},
+		{NewRelativeTimeLockCondition(1, LockUnitSeconds, nil), 0, 1000, 0, 2000, ErrRelativeTimeLockNotReached},
+	}
+	for idx, testCase := range testCases {
+		err := testCase.Condition.Fulfill(fulfillment, types.FulfillContext{
+			ExtraObjects:         []interface{}{uint64(0)},
+			BlockHeight:          testCase.BlockHeight,
+			BlockTime:            testCase.BlockTime,
+			OutputCreationHeight: testCase.OutputCreationHeight,
+			OutputCreationTime:   testCase.OutputCreationTime,
+			HasOutputCreation:    true,
+			Transaction:          txn,
+		})
+		if err != testCase.ExpectedError {
+			t.Errorf("test case #%d: expected error %v, got: %v", idx, testCase.ExpectedError, err)
+		}
+	}
+
+	// the internal condition still has to be fulfilled
+	_, otherPK := crypto.GenerateKeyPair()
+	otherUH, err := types.NewPubKeyUnlockHash(types.Ed25519PublicKey(otherPK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := NewRelativeTimeLockCondition(1, LockUnitBlocks, types.NewUnlockHashCondition(otherUH))
+	err = condition.Fulfill(fulfillment, types.FulfillContext{
+		ExtraObjects:      []interface{}{uint64(0)},
+		BlockHeight:       10,
+		HasOutputCreation: true,
+		Transaction:       txn,
+	})
+	if err == nil || err == ErrRelativeTimeLockNotReached {
+		t.Fatalf("expected fulfillment of another unlock hash to fail, got: %v", err)
+	}
+}
+
+func TestRelativeTimeLockConditionWithoutOutputCreation(t *testing.T) {
+	// a context which does not define the creation of the output never fulfills the condition,
+	// no matter how much time has passed
+	for _, condition := range []*RelativeTimeLockCondition{
+		NewRelativeTimeLockCondition(1, LockUnitBlocks, nil),
+		NewRelativeTimeLockCondition(1, LockUnitSeconds, nil),
+	} {
+		ctx := types.FulfillableContext{
+			BlockHeight: math.MaxUint64,
+			BlockTime:   math.MaxUint64,
+		}
+		if condition.Fulfillable(ctx) {
+			t.Errorf("expected condition with lock unit %d not to be fulfillable without output creation", condition.LockUnit)
+		}
+		err := condition.Fulfill(&types.NilFulfillment{}, types.FulfillContext{
+			BlockHeight: ctx.BlockHeight,
+			BlockTime:   ctx.BlockTime,
+		})
+		if err != ErrRelativeTimeLockNotReached {
+			t.Errorf("expected condition with lock unit %d not to be fulfilled without output creation, got: %v", condition.LockUnit, err)
+		}
+		ctx.HasOutputCreation = true
+		if !condition.Fulfillable(ctx) {
+			t.Errorf("expected condition with lock unit %d to be fulfillable with output creation", condition.LockUnit)
+		}
+	}
+}
+
+func TestRelativeTimeLockConditionMaxLockDuration(t *testing.T) {
+	testCases := []struct {
+		Condition     *RelativeTimeLockCondition
+		ExpectedValid bool
+	}{
+		{NewRelativeTimeLockCondition(MaxLockDurationBlocks, LockUnitBlocks, nil), true},
+		{NewRelativeTimeLockCondition(MaxLockDurationBlocks+1, LockUnitBlocks, nil), false},
+		{NewRelativeTimeLockCondition(math.MaxUint64, LockUnitBlocks, nil), false},
+		{NewRelativeTimeLockCondition(MaxLockDurationSeconds, LockUnitSeconds, nil), true},
+		{NewRelativeTimeLockCondition(MaxLockDurationSeconds+1, LockUnitSeconds, nil), false},
+		{NewRelativeTimeLockCondition(math.MaxUint64, LockUnitSeconds, nil), false},
+	}
+	for idx, testCase := range testCases {
+		err := testCase.Condition.IsStandardCondition(types.ValidationContext{})
+		if testCase.ExpectedValid && err != nil {
+			t.Errorf("test case #%d: expected condition to be standard: %v", idx, err)
+		} else if !testCase.ExpectedValid && err == nil {
+			t.Errorf("test case #%d: expected condition to be non-standard", idx)
+		}
+	}
+}
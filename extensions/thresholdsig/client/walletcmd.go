@@ -0,0 +1,481 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/thresholdsig"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+)
+
+// The metadata of the files used to store the secrets of a participant.
+var (
+	dkgStateMetadata = persist.Metadata{
+		Header:  "Threshold Signature DKG State",
+		Version: "1.0.0",
+	}
+	keyShareMetadata = persist.Metadata{
+		Header:  "Threshold Signature Key Share",
+		Version: "1.0.0",
+	}
+	signingNoncesMetadata = persist.Metadata{
+		Header:  "Threshold Signature Signing Nonces",
+		Version: "1.0.0",
+	}
+)
+
+type (
+	// signingCommitments are the commitments of a single signer,
+	// for each input of a transaction which is locked by the group.
+	// The commitments are mapped by the (hex-encoded) signature hash of the input(s) they are created for.
+	signingCommitments map[string]thresholdsig.SigningCommitment
+
+	// signingNonces are the secret nonces of a single signer,
+	// mapped by the (hex-encoded) signature hash of the input(s) they are created for.
+	signingNonces map[string]thresholdsig.SigningNonces
+
+	// signatureShares are the signature shares of a single signer,
+	// for each input of a transaction which is locked by the group,
+	// mapped by the (hex-encoded) signature hash of the input(s) they are created for.
+	signatureShares map[string]inputSignatureShare
+
+	// inputSignatureShare is the signature share of a signer,
+	// together with the commitments of all signers it was created for.
+	inputSignatureShare struct {
+		Commitments []thresholdsig.SigningCommitment `json:"commitments"`
+		Share       thresholdsig.SignatureShare      `json:"share"`
+	}
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the threshold signature extension,
+// used to create group keys and sign transactions for a group, without ever sharing the secret key shares.
+// All commands, except the commands that look up the outputs spent by a transaction, can be used offline.
+func CreateWalletCmds(ccli *client.CommandLineClient) error {
+	walletCmd := &walletCmd{
+		cli: ccli,
+	}
+
+	var (
+		thresholdCmd = &cobra.Command{
+			Use:   "threshold",
+			Short: "Create and sign for threshold signature groups",
+			Long: `Create and sign for threshold signature groups.
+
+A group of N participants shares a single public key, and any M of them
+can jointly sign for it, producing a single (aggregated) signature.
+The secret key of the group is never known by any participant.
+
+Secret material is only ever stored in the files given to these commands,
+which have to be kept private by the participant.`,
+		}
+		dkgCmd = &cobra.Command{
+			Use:   "dkg",
+			Short: "Create the key of a new group, using a distributed key generation",
+		}
+		dkgRound1Cmd = &cobra.Command{
+			Use:   "round1 <index> <minimumSignatureCount> <participantCount> <stateFile>",
+			Short: "Start the key generation as the participant with the given index",
+			Long: `Start the key generation as the participant with the given index,
+where participants are indexed starting from 1.
+
+The secret state is stored in the state file,
+while the returned (JSON-encoded) package has to be broadcasted to all other participants.`,
+			Args: cobra.ExactArgs(4),
+			Run:  walletCmd.dkgRound1Cmd,
+		}
+		dkgRound2Cmd = &cobra.Command{
+			Use:   "round2 <stateFile> <round1Package>...",
+			Short: "Process the round 1 packages of all other participants",
+			Long: `Process the (JSON-encoded) round 1 packages of all other participants.
+
+Each of the returned (JSON-encoded) packages has to be sent to its receiver,
+over a private channel, as it contains a secret share for that receiver.`,
+			Args: cobra.MinimumNArgs(2),
+			Run:  walletCmd.dkgRound2Cmd,
+		}
+		dkgFinalizeCmd = &cobra.Command{
+			Use:   "finalize <stateFile> <keyShareFile> <round2Package>...",
+			Short: "Process the round 2 packages received from all other participants",
+			Long: `Process the (JSON-encoded) round 2 packages received from all other participants,
+storing the resulting key share in the key share file and removing the state file.
+
+The address and condition of the group are printed, and should be the same for all participants.`,
+			Args: cobra.MinimumNArgs(3),
+			Run:  walletCmd.dkgFinalizeCmd,
+		}
+		addressCmd = &cobra.Command{
+			Use:   "address <keyShareFile>",
+			Short: "Print the address and condition of the group of a key share",
+			Args:  cobra.ExactArgs(1),
+			Run:   walletCmd.addressCmd,
+		}
+		signCmd = &cobra.Command{
+			Use:   "sign",
+			Short: "Sign a transaction for a group, together with the other signers",
+			Long: `Sign a transaction for a group, together with the other signers.
+
+All signers commit to their nonces for the (JSON-encoded) transaction, using the commit command.
+Once the commitments of at least the minimum amount of signers are gathered,
+each of those signers creates its signature share, using the share command.
+Finally anyone can aggregate those signature shares into the signed transaction,
+using the aggregate command.`,
+		}
+		signCommitCmd = &cobra.Command{
+			Use:   "commit <keyShareFile> <noncesFile> <txnjson>",
+			Short: "Commit to the nonces used to sign the inputs of the group",
+			Long: `Commit to the nonces used to sign the inputs of the given transaction,
+which spend outputs locked by the group.
+
+The secret nonces are stored in the nonces file, while the returned
+(JSON-encoded) commitments have to be shared with all other signers.`,
+			Args: cobra.ExactArgs(3),
+			Run:  walletCmd.signCommitCmd,
+		}
+		signShareCmd = &cobra.Command{
+			Use:   "share <keyShareFile> <noncesFile> <txnjson> <commitments>...",
+			Short: "Create the signature shares for the inputs of the group",
+			Long: `Create the signature shares for the inputs of the given transaction,
+using the (JSON-encoded) commitments of all signers, including your own.
+
+The nonces used are removed from the nonces file, such that they cannot be reused.
+The returned (JSON-encoded) signature shares are to be given to the aggregator.`,
+			Args: cobra.MinimumNArgs(4),
+			Run:  walletCmd.signShareCmd,
+		}
+		signAggregateCmd = &cobra.Command{
+			Use:   "aggregate <keyShareFile> <txnjson> <signatureShares>...",
+			Short: "Aggregate the signature shares of all signers into the signed transaction",
+			Long: `Aggregate the (JSON-encoded) signature shares of all signers into the signed transaction.
+
+The returned (JSON-encoded) transaction can be sent using the send transaction command.`,
+			Args: cobra.MinimumNArgs(3),
+			Run:  walletCmd.signAggregateCmd,
+		}
+	)
+
+	dkgCmd.AddCommand(
+		dkgRound1Cmd,
+		dkgRound2Cmd,
+		dkgFinalizeCmd,
+	)
+	signCmd.AddCommand(
+		signCommitCmd,
+		signShareCmd,
+		signAggregateCmd,
+	)
+	thresholdCmd.AddCommand(
+		dkgCmd,
+		addressCmd,
+		signCmd,
+	)
+	ccli.WalletCmd.AddCommand(thresholdCmd)
+
+	return nil
+}
+
+type walletCmd struct {
+	cli *client.CommandLineClient
+}
+
+func (walletCmd *walletCmd) dkgRound1Cmd(cmd *cobra.Command, args []string) {
+	var params [3]uint64
+	for i, name := range []string{"index", "minimum signature count", "participant count"} {
+		var err error
+		params[i], err = strconv.ParseUint(args[i], 10, 64)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithError("invalid "+name, err)
+		}
+	}
+	if _, err := os.Stat(args[3]); err == nil {
+		cli.Die("state file", args[3], "already exists")
+	}
+
+	participant, pkg, err := thresholdsig.NewDKGParticipant(params[0], params[1], params[2])
+	if err != nil {
+		cli.DieWithError("failed to start the distributed key generation", err)
+	}
+	err = persist.SaveJSON(dkgStateMetadata, participant, args[3])
+	if err != nil {
+		cli.DieWithError("failed to save the distributed key generation state", err)
+	}
+	encodeJSON(pkg)
+}
+
+func (walletCmd *walletCmd) dkgRound2Cmd(cmd *cobra.Command, args []string) {
+	var participant thresholdsig.DKGParticipant
+	err := persist.LoadJSON(dkgStateMetadata, &participant, args[0])
+	if err != nil {
+		cli.DieWithError("failed to load the distributed key generation state", err)
+	}
+	packages := make([]thresholdsig.DKGRound1Package, len(args)-1)
+	for i, arg := range args[1:] {
+		parseJSON(cmd, arg, &packages[i], "round 1 package")
+	}
+
+	shares, err := participant.Round2(packages)
+	if err != nil {
+		cli.DieWithError("failed to process the round 1 packages", err)
+	}
+	err = persist.SaveJSON(dkgStateMetadata, participant, args[0])
+	if err != nil {
+		cli.DieWithError("failed to save the distributed key generation state", err)
+	}
+	encodeJSON(shares)
+}
+
+func (walletCmd *walletCmd) dkgFinalizeCmd(cmd *cobra.Command, args []string) {
+	var participant thresholdsig.DKGParticipant
+	err := persist.LoadJSON(dkgStateMetadata, &participant, args[0])
+	if err != nil {
+		cli.DieWithError("failed to load the distributed key generation state", err)
+	}
+	if _, err := os.Stat(args[1]); err == nil {
+		cli.Die("key share file", args[1], "already exists")
+	}
+	packages := make([]thresholdsig.DKGRound2Package, len(args)-2)
+	for i, arg := range args[2:] {
+		parseJSON(cmd, arg, &packages[i], "round 2 package")
+	}
+
+	keyShare, err := participant.Finalize(packages)
+	if err != nil {
+		cli.DieWithError("failed to process the round 2 packages", err)
+	}
+	err = persist.SaveJSON(keyShareMetadata, keyShare, args[1])
+	if err != nil {
+		cli.DieWithError("failed to save the key share", err)
+	}
+	// the state contains our secret polynomial, and is no longer needed
+	err = os.Remove(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to remove the distributed key generation state:", err)
+	}
+	printGroup(keyShare.Group)
+}
+
+func (walletCmd *walletCmd) addressCmd(cmd *cobra.Command, args []string) {
+	printGroup(loadKeyShare(args[0]).Group)
+}
+
+func (walletCmd *walletCmd) signCommitCmd(cmd *cobra.Command, args []string) {
+	keyShare := loadKeyShare(args[0])
+	var txn types.Transaction
+	parseJSON(cmd, args[2], &txn, "transaction")
+	hashes := walletCmd.groupSignatureHashes(keyShare.Group, txn)
+
+	nonces := loadSigningNonces(args[1])
+	commitments := make(signingCommitments, len(hashes))
+	for _, hash := range hashes {
+		nonces[hash.String()], commitments[hash.String()] = keyShare.NewSigningNonces()
+	}
+	err := persist.SaveJSON(signingNoncesMetadata, nonces, args[1])
+	if err != nil {
+		cli.DieWithError("failed to save the signing nonces", err)
+	}
+	encodeJSON(commitments)
+}
+
+func (walletCmd *walletCmd) signShareCmd(cmd *cobra.Command, args []string) {
+	keyShare := loadKeyShare(args[0])
+	var txn types.Transaction
+	parseJSON(cmd, args[2], &txn, "transaction")
+	hashes := walletCmd.groupSignatureHashes(keyShare.Group, txn)
+	signerCommitments := make([]signingCommitments, len(args)-3)
+	for i, arg := range args[3:] {
+		parseJSON(cmd, arg, &signerCommitments[i], "signing commitments")
+	}
+
+	nonces := loadSigningNonces(args[1])
+	shares := make(signatureShares, len(hashes))
+	for _, hash := range hashes {
+		inputNonces, ok := nonces[hash.String()]
+		if !ok {
+			cli.Die("no signing nonces found for signature hash", hash.String())
+		}
+		commitments := make([]thresholdsig.SigningCommitment, 0, len(signerCommitments))
+		for _, sc := range signerCommitments {
+			commitment, ok := sc[hash.String()]
+			if !ok {
+				cli.Die("no signing commitment found for signature hash", hash.String())
+			}
+			commitments = append(commitments, commitment)
+		}
+		share, err := keyShare.Sign(hash, inputNonces, commitments)
+		if err != nil {
+			cli.DieWithError("failed to create signature share", err)
+		}
+		shares[hash.String()] = inputSignatureShare{
+			Commitments: commitments,
+			Share:       share,
+		}
+	}
+	// nonces can never be reused, as that would leak the secret share
+	for _, hash := range hashes {
+		delete(nonces, hash.String())
+	}
+	err := persist.SaveJSON(signingNoncesMetadata, nonces, args[1])
+	if err != nil {
+		cli.DieWithError("failed to save the signing nonces", err)
+	}
+	encodeJSON(shares)
+}
+
+func (walletCmd *walletCmd) signAggregateCmd(cmd *cobra.Command, args []string) {
+	group := loadKeyShare(args[0]).Group
+	var txn types.Transaction
+	parseJSON(cmd, args[1], &txn, "transaction")
+	signerShares := make([]signatureShares, len(args)-2)
+	for i, arg := range args[2:] {
+		parseJSON(cmd, arg, &signerShares[i], "signature shares")
+	}
+
+	signatures := make(map[crypto.Hash]types.ByteSlice)
+	for _, hash := range walletCmd.groupSignatureHashes(group, txn) {
+		if _, ok := signatures[hash]; ok {
+			continue
+		}
+		var commitments []thresholdsig.SigningCommitment
+		shares := make([]thresholdsig.SignatureShare, 0, len(signerShares))
+		for _, ss := range signerShares {
+			share, ok := ss[hash.String()]
+			if !ok {
+				cli.Die("no signature share found for signature hash", hash.String())
+			}
+			// all shares are created for the same commitments,
+			// which is verified as part of the aggregation
+			commitments = share.Commitments
+			shares = append(shares, share.Share)
+		}
+		signature, err := group.Aggregate(hash, commitments, shares)
+		if err != nil {
+			cli.DieWithError("failed to aggregate signature shares", err)
+		}
+		signatures[hash] = signature
+	}
+
+	// fulfill all inputs of the group using the aggregated signatures
+	condition := group.Condition()
+	for idx, ci := range txn.CoinInputs {
+		if walletCmd.coinOutputCondition(ci.ParentID).Equal(condition) {
+			txn.CoinInputs[idx].Fulfillment = walletCmd.fulfillment(txn, uint64(idx), signatures)
+		}
+	}
+	for idx, bsi := range txn.BlockStakeInputs {
+		if walletCmd.blockStakeOutputCondition(bsi.ParentID).Equal(condition) {
+			txn.BlockStakeInputs[idx].Fulfillment = walletCmd.fulfillment(txn, uint64(idx), signatures)
+		}
+	}
+	encodeJSON(txn)
+}
+
+// groupSignatureHashes returns the signature hashes of all inputs of the transaction,
+// which spend an output locked by the group.
+func (walletCmd *walletCmd) groupSignatureHashes(group thresholdsig.GroupKey, txn types.Transaction) []crypto.Hash {
+	condition := group.Condition()
+	var hashes []crypto.Hash
+	addHash := func(idx int) {
+		hash, err := thresholdsig.SignatureHash(txn, uint64(idx))
+		if err != nil {
+			cli.DieWithError("failed to compute the signature hash of the transaction", err)
+		}
+		// coin and block stake inputs at the same index share the same signature hash
+		for _, h := range hashes {
+			if h == hash {
+				return
+			}
+		}
+		hashes = append(hashes, hash)
+	}
+	for idx, ci := range txn.CoinInputs {
+		if walletCmd.coinOutputCondition(ci.ParentID).Equal(condition) {
+			addHash(idx)
+		}
+	}
+	for idx, bsi := range txn.BlockStakeInputs {
+		if walletCmd.blockStakeOutputCondition(bsi.ParentID).Equal(condition) {
+			addHash(idx)
+		}
+	}
+	if len(hashes) == 0 {
+		cli.Die("transaction does not spend any outputs locked by the group")
+	}
+	return hashes
+}
+
+func (walletCmd *walletCmd) fulfillment(txn types.Transaction, idx uint64, signatures map[crypto.Hash]types.ByteSlice) types.UnlockFulfillmentProxy {
+	hash, err := thresholdsig.SignatureHash(txn, idx)
+	if err != nil {
+		cli.DieWithError("failed to compute the signature hash of the transaction", err)
+	}
+	return types.NewFulfillment(thresholdsig.NewThresholdSignatureFulfillment(signatures[hash]))
+}
+
+func (walletCmd *walletCmd) coinOutputCondition(id types.CoinOutputID) types.UnlockConditionProxy {
+	var resp api.ConsensusGetUnspentCoinOutput
+	err := walletCmd.cli.GetWithResponse("/consensus/unspent/coinoutputs/"+id.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get unspent coin output "+id.String(), err)
+	}
+	return resp.Output.Condition
+}
+
+func (walletCmd *walletCmd) blockStakeOutputCondition(id types.BlockStakeOutputID) types.UnlockConditionProxy {
+	var resp api.ConsensusGetUnspentBlockstakeOutput
+	err := walletCmd.cli.GetWithResponse("/consensus/unspent/blockstakeoutputs/"+id.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get unspent block stake output "+id.String(), err)
+	}
+	return resp.Output.Condition
+}
+
+func loadKeyShare(filename string) thresholdsig.KeyShare {
+	var keyShare thresholdsig.KeyShare
+	err := persist.LoadJSON(keyShareMetadata, &keyShare, filename)
+	if err != nil {
+		cli.DieWithError("failed to load the key share", err)
+	}
+	return keyShare
+}
+
+func loadSigningNonces(filename string) signingNonces {
+	nonces := make(signingNonces)
+	err := persist.LoadJSON(signingNoncesMetadata, &nonces, filename)
+	if err != nil && !os.IsNotExist(err) {
+		cli.DieWithError("failed to load the signing nonces", err)
+	}
+	return nonces
+}
+
+func printGroup(group thresholdsig.GroupKey) {
+	condition := types.NewCondition(group.Condition())
+	fmt.Println("address:", condition.UnlockHash().String())
+	fmt.Printf("group: %d-of-%d\n", group.MinimumSignatureCount, group.ParticipantCount)
+	fmt.Print("condition: ")
+	encodeJSON(condition)
+}
+
+func parseJSON(cmd *cobra.Command, str string, value interface{}, name string) {
+	err := json.Unmarshal([]byte(str), value)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid JSON-encoded "+name, err)
+	}
+}
+
+// encodeJSON encodes the value as JSON to the STDOUT
+func encodeJSON(value interface{}) {
+	err := json.NewEncoder(os.Stdout).Encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
@@ -0,0 +1,642 @@
+package thresholdsig
+
+// frost.go implements FROST (Flexible Round-Optimized Schnorr Threshold signatures)
+// over the Ed25519 curve, as defined by Komlo and Goldberg, such that any
+// M-of-N subset of the participants of a group can jointly produce a single
+// signature, which is a standard Ed25519 signature for the public key of the group.
+//
+// The group key is created using a distributed key generation (DKG),
+// in which no participant ever learns the secret key of the group:
+//
+//   1. every participant creates a DKGParticipant, and broadcasts its DKGRound1Package;
+//   2. every participant processes the round 1 packages of all other participants,
+//      and sends each other participant its DKGRound2Package, over a private channel;
+//   3. every participant processes the round 2 packages it received, which results in its KeyShare.
+//
+// Signing a message is done in two rounds, of which the first one can be done in advance:
+//
+//   1. every signer creates SigningNonces, and shares the matching SigningCommitment;
+//   2. once the commitments of (at least) M signers are gathered, every signer
+//      produces its SignatureShare for the message, using the commitments of all signers;
+//   3. anyone with the GroupKey can verify and aggregate the signature shares into the final signature.
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"filippo.io/edwards25519"
+	"github.com/NebulousLabs/fastrand"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// The domain separation tags used to derive the scalars of the protocol.
+var (
+	frostContextDKGProof      = []byte("rivine FROST-ED25519 dkg proof")
+	frostContextNonce         = []byte("rivine FROST-ED25519 nonce")
+	frostContextBindingFactor = []byte("rivine FROST-ED25519 binding factor")
+	frostContextCommitments   = []byte("rivine FROST-ED25519 commitments")
+)
+
+var (
+	// ErrInvalidParticipantIndex is returned in case a participant index is used,
+	// which is not in the inclusive range [1, participantCount].
+	ErrInvalidParticipantIndex = errors.New("invalid participant index")
+	// ErrInvalidSignatureShare is returned in case a signature share could not be verified,
+	// using the verification share of its signer.
+	ErrInvalidSignatureShare = errors.New("invalid signature share")
+	// ErrInsufficientSigners is returned in case less signers than the minimum signature count
+	// of the group took part in a signing session.
+	ErrInsufficientSigners = errors.New("insufficient signers")
+)
+
+type (
+	// DKGParticipant is the secret state of a participant during the distributed key generation.
+	// It has to be kept private, and can be discarded once the KeyShare has been created.
+	DKGParticipant struct {
+		Index                 uint64 `json:"index"`
+		MinimumSignatureCount uint64 `json:"minimumsignaturecount"`
+		ParticipantCount      uint64 `json:"participantcount"`
+		// Coefficients of the secret polynomial of this participant,
+		// of which the first coefficient is its contribution to the group secret.
+		Coefficients []types.ByteSlice `json:"coefficients"`
+		// Round1Packages are the round 1 packages received from all other participants,
+		// only defined once round 2 has been completed.
+		Round1Packages []DKGRound1Package `json:"round1packages,omitempty"`
+	}
+
+	// DKGRound1Package is broadcasted by a participant to all other participants,
+	// as the first round of the distributed key generation.
+	DKGRound1Package struct {
+		Sender uint64 `json:"sender"`
+		// Commitments to the coefficients of the secret polynomial of the sender.
+		Commitments []types.ByteSlice `json:"commitments"`
+		// The proof of knowledge of the secret committed to by the first commitment,
+		// a Schnorr signature which protects against rogue-key attacks.
+		ProofCommitment types.ByteSlice `json:"proofcommitment"`
+		ProofResponse   types.ByteSlice `json:"proofresponse"`
+	}
+
+	// DKGRound2Package is sent by a participant to a single other participant,
+	// as the second round of the distributed key generation.
+	// As it contains a secret share, it has to be sent over a private channel.
+	DKGRound2Package struct {
+		Sender   uint64          `json:"sender"`
+		Receiver uint64          `json:"receiver"`
+		Share    types.ByteSlice `json:"share"`
+	}
+
+	// GroupKey is the public information of a threshold signature group,
+	// sufficient to aggregate and verify signature shares.
+	GroupKey struct {
+		MinimumSignatureCount uint64 `json:"minimumsignaturecount"`
+		ParticipantCount      uint64 `json:"participantcount"`
+		// PublicKey is the Ed25519 public key of the group.
+		PublicKey types.PublicKey `json:"publickey"`
+		// VerificationShares contains the public key share of each participant,
+		// where the share of participant i is found at index i-1.
+		VerificationShares []types.ByteSlice `json:"verificationshares"`
+	}
+
+	// KeyShare is the result of the distributed key generation for a single participant.
+	// The secret share has to be kept private, as it is required to sign for the group.
+	KeyShare struct {
+		Group       GroupKey        `json:"group"`
+		Index       uint64          `json:"index"`
+		SecretShare types.ByteSlice `json:"secretshare"`
+	}
+
+	// SigningNonces are the secret nonces of a signer, created for a single signing session.
+	// They have to be kept private, and should never be used to sign more than once.
+	SigningNonces struct {
+		Index   uint64          `json:"index"`
+		Hiding  types.ByteSlice `json:"hiding"`
+		Binding types.ByteSlice `json:"binding"`
+	}
+
+	// SigningCommitment is the public commitment to the SigningNonces of a signer,
+	// to be shared with all other signers of the same signing session.
+	SigningCommitment struct {
+		Index   uint64          `json:"index"`
+		Hiding  types.ByteSlice `json:"hiding"`
+		Binding types.ByteSlice `json:"binding"`
+	}
+
+	// SignatureShare is the contribution of a single signer to the signature of the group.
+	SignatureShare struct {
+		Index uint64          `json:"index"`
+		Share types.ByteSlice `json:"share"`
+	}
+)
+
+// NewDKGParticipant creates the secret state of a participant of a new group,
+// and returns it together with the round 1 package to broadcast to all other participants.
+// Participants are identified by an index in the inclusive range [1, participantCount].
+func NewDKGParticipant(index, minimumSignatureCount, participantCount uint64) (*DKGParticipant, DKGRound1Package, error) {
+	err := validateGroupParameters(minimumSignatureCount, participantCount)
+	if err != nil {
+		return nil, DKGRound1Package{}, err
+	}
+	if index == 0 || index > participantCount {
+		return nil, DKGRound1Package{}, ErrInvalidParticipantIndex
+	}
+
+	p := &DKGParticipant{
+		Index:                 index,
+		MinimumSignatureCount: minimumSignatureCount,
+		ParticipantCount:      participantCount,
+		Coefficients:          make([]types.ByteSlice, minimumSignatureCount),
+	}
+	pkg := DKGRound1Package{
+		Sender:      index,
+		Commitments: make([]types.ByteSlice, minimumSignatureCount),
+	}
+	coefficients := make([]*edwards25519.Scalar, minimumSignatureCount)
+	for i := range coefficients {
+		coefficients[i] = randomScalar(nil)
+		p.Coefficients[i] = coefficients[i].Bytes()
+		pkg.Commitments[i] = new(edwards25519.Point).ScalarBaseMult(coefficients[i]).Bytes()
+	}
+
+	// prove the knowledge of the first coefficient
+	k := randomScalar(p.Coefficients[0])
+	proofCommitment := new(edwards25519.Point).ScalarBaseMult(k)
+	c := dkgProofChallenge(index, pkg.Commitments[0], proofCommitment.Bytes())
+	pkg.ProofCommitment = proofCommitment.Bytes()
+	pkg.ProofResponse = edwards25519.NewScalar().MultiplyAdd(coefficients[0], c, k).Bytes()
+	return p, pkg, nil
+}
+
+// Round2 verifies the round 1 packages of all other participants,
+// and returns the round 2 packages to send to each of them.
+// The received round 1 packages are stored in the participant,
+// as they are required to finalize the distributed key generation.
+func (p *DKGParticipant) Round2(packages []DKGRound1Package) ([]DKGRound2Package, error) {
+	if uint64(len(packages)) != p.ParticipantCount-1 {
+		return nil, fmt.Errorf("expected %d round 1 packages, received %d", p.ParticipantCount-1, len(packages))
+	}
+	received := make([]DKGRound1Package, len(packages))
+	copy(received, packages)
+	sort.Slice(received, func(i, j int) bool {
+		return received[i].Sender < received[j].Sender
+	})
+	for idx, pkg := range received {
+		if pkg.Sender == 0 || pkg.Sender > p.ParticipantCount || pkg.Sender == p.Index {
+			return nil, fmt.Errorf("round 1 package #%d: %v", idx, ErrInvalidParticipantIndex)
+		}
+		if idx > 0 && received[idx-1].Sender == pkg.Sender {
+			return nil, fmt.Errorf("received multiple round 1 packages from participant %d", pkg.Sender)
+		}
+		err := pkg.verify(p.MinimumSignatureCount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid round 1 package from participant %d: %v", pkg.Sender, err)
+		}
+	}
+
+	coefficients, err := decodeScalars(p.Coefficients)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret coefficients: %v", err)
+	}
+	shares := make([]DKGRound2Package, 0, len(received))
+	for _, pkg := range received {
+		shares = append(shares, DKGRound2Package{
+			Sender:   p.Index,
+			Receiver: pkg.Sender,
+			Share:    evaluatePolynomial(coefficients, pkg.Sender).Bytes(),
+		})
+	}
+	p.Round1Packages = received
+	return shares, nil
+}
+
+// Finalize verifies the round 2 packages received from all other participants,
+// and combines them into the key share of this participant.
+// Round 2 packages addressed to other participants are ignored.
+func (p *DKGParticipant) Finalize(packages []DKGRound2Package) (KeyShare, error) {
+	if len(p.Round1Packages) == 0 {
+		return KeyShare{}, errors.New("round 2 of the distributed key generation has not been completed yet")
+	}
+	coefficients, err := decodeScalars(p.Coefficients)
+	if err != nil {
+		return KeyShare{}, fmt.Errorf("invalid secret coefficients: %v", err)
+	}
+
+	// gather the commitments of all participants, including our own
+	commitments := make(map[uint64][]*edwards25519.Point, p.ParticipantCount)
+	ownCommitments := make([]*edwards25519.Point, len(coefficients))
+	for i, coefficient := range coefficients {
+		ownCommitments[i] = new(edwards25519.Point).ScalarBaseMult(coefficient)
+	}
+	commitments[p.Index] = ownCommitments
+	for _, pkg := range p.Round1Packages {
+		commitments[pkg.Sender], err = decodePoints(pkg.Commitments)
+		if err != nil {
+			return KeyShare{}, fmt.Errorf("invalid round 1 package from participant %d: %v", pkg.Sender, err)
+		}
+	}
+
+	// verify and sum the received shares, together with our own share
+	secretShare := evaluatePolynomial(coefficients, p.Index)
+	received := make(map[uint64]struct{}, p.ParticipantCount-1)
+	for _, pkg := range packages {
+		if pkg.Receiver != p.Index {
+			continue
+		}
+		senderCommitments, ok := commitments[pkg.Sender]
+		if !ok || pkg.Sender == p.Index {
+			return KeyShare{}, fmt.Errorf("round 2 package from participant %d: %v", pkg.Sender, ErrInvalidParticipantIndex)
+		}
+		if _, ok = received[pkg.Sender]; ok {
+			return KeyShare{}, fmt.Errorf("received multiple round 2 packages from participant %d", pkg.Sender)
+		}
+		share, err := edwards25519.NewScalar().SetCanonicalBytes(pkg.Share)
+		if err != nil {
+			return KeyShare{}, fmt.Errorf("invalid share from participant %d: %v", pkg.Sender, err)
+		}
+		expected := evaluateCommitments(senderCommitments, p.Index)
+		if new(edwards25519.Point).ScalarBaseMult(share).Equal(expected) != 1 {
+			return KeyShare{}, fmt.Errorf("share from participant %d does not match its commitments", pkg.Sender)
+		}
+		received[pkg.Sender] = struct{}{}
+		secretShare.Add(secretShare, share)
+	}
+	if uint64(len(received)) != p.ParticipantCount-1 {
+		return KeyShare{}, fmt.Errorf("expected %d round 2 packages, received %d", p.ParticipantCount-1, len(received))
+	}
+
+	// compute the public key of the group and the verification shares of all participants
+	groupKey := edwards25519.NewIdentityPoint()
+	for _, c := range commitments {
+		groupKey.Add(groupKey, c[0])
+	}
+	verificationShares := make([]types.ByteSlice, p.ParticipantCount)
+	for index := uint64(1); index <= p.ParticipantCount; index++ {
+		share := edwards25519.NewIdentityPoint()
+		for _, c := range commitments {
+			share.Add(share, evaluateCommitments(c, index))
+		}
+		verificationShares[index-1] = share.Bytes()
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], groupKey.Bytes())
+	return KeyShare{
+		Group: GroupKey{
+			MinimumSignatureCount: p.MinimumSignatureCount,
+			ParticipantCount:      p.ParticipantCount,
+			PublicKey:             types.Ed25519PublicKey(pk),
+			VerificationShares:    verificationShares,
+		},
+		Index:       p.Index,
+		SecretShare: secretShare.Bytes(),
+	}, nil
+}
+
+// verify the commitments and proof of knowledge of a round 1 package.
+func (pkg *DKGRound1Package) verify(minimumSignatureCount uint64) error {
+	if uint64(len(pkg.Commitments)) != minimumSignatureCount {
+		return fmt.Errorf("expected %d commitments, received %d", minimumSignatureCount, len(pkg.Commitments))
+	}
+	commitments, err := decodePoints(pkg.Commitments)
+	if err != nil {
+		return err
+	}
+	proofCommitment, err := new(edwards25519.Point).SetBytes(pkg.ProofCommitment)
+	if err != nil {
+		return fmt.Errorf("invalid proof commitment: %v", err)
+	}
+	proofResponse, err := edwards25519.NewScalar().SetCanonicalBytes(pkg.ProofResponse)
+	if err != nil {
+		return fmt.Errorf("invalid proof response: %v", err)
+	}
+	c := dkgProofChallenge(pkg.Sender, pkg.Commitments[0], pkg.ProofCommitment)
+	expected := new(edwards25519.Point).ScalarMult(c, commitments[0])
+	expected.Add(expected, proofCommitment)
+	if new(edwards25519.Point).ScalarBaseMult(proofResponse).Equal(expected) != 1 {
+		return errors.New("invalid proof of knowledge")
+	}
+	return nil
+}
+
+// Condition returns the unlock condition which can be fulfilled by this group.
+func (gk GroupKey) Condition() *ThresholdSignatureCondition {
+	return NewThresholdSignatureCondition(gk.MinimumSignatureCount, gk.ParticipantCount, gk.PublicKey)
+}
+
+// NewSigningNonces creates the secret nonces for a single signing session,
+// and returns them together with the commitment to share with the other signers.
+func (ks KeyShare) NewSigningNonces() (SigningNonces, SigningCommitment) {
+	// the nonces are derived from the secret share as well,
+	// protecting against a (partially) broken source of randomness
+	hiding := randomScalar(ks.SecretShare)
+	binding := randomScalar(ks.SecretShare)
+	return SigningNonces{
+		Index:   ks.Index,
+		Hiding:  hiding.Bytes(),
+		Binding: binding.Bytes(),
+	}, SigningCommitment{
+		Index:   ks.Index,
+		Hiding:  new(edwards25519.Point).ScalarBaseMult(hiding).Bytes(),
+		Binding: new(edwards25519.Point).ScalarBaseMult(binding).Bytes(),
+	}
+}
+
+// Sign the message as part of a signing session, using the nonces created for that session
+// and the commitments of all signers of that session, including the commitment of this signer.
+// The nonces should be discarded after this call, and can never be used again.
+func (ks KeyShare) Sign(message crypto.Hash, nonces SigningNonces, commitments []SigningCommitment) (SignatureShare, error) {
+	if nonces.Index != ks.Index {
+		return SignatureShare{}, errors.New("signing nonces belong to another participant")
+	}
+	secretShare, err := edwards25519.NewScalar().SetCanonicalBytes(ks.SecretShare)
+	if err != nil {
+		return SignatureShare{}, fmt.Errorf("invalid secret share: %v", err)
+	}
+	hiding, err := edwards25519.NewScalar().SetCanonicalBytes(nonces.Hiding)
+	if err != nil {
+		return SignatureShare{}, fmt.Errorf("invalid hiding nonce: %v", err)
+	}
+	binding, err := edwards25519.NewScalar().SetCanonicalBytes(nonces.Binding)
+	if err != nil {
+		return SignatureShare{}, fmt.Errorf("invalid binding nonce: %v", err)
+	}
+
+	session, err := newSigningSession(ks.Group, message, commitments)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+	// ensure our own commitment is part of the session, and matches our nonces
+	commitment, ok := session.commitments[ks.Index]
+	if !ok {
+		return SignatureShare{}, errors.New("signing commitments do not contain the commitment of this signer")
+	}
+	if !bytes.Equal(commitment.Hiding, new(edwards25519.Point).ScalarBaseMult(hiding).Bytes()) ||
+		!bytes.Equal(commitment.Binding, new(edwards25519.Point).ScalarBaseMult(binding).Bytes()) {
+		return SignatureShare{}, errors.New("signing commitment of this signer does not match its nonces")
+	}
+
+	// z_i = d_i + (e_i * ρ_i) + (λ_i * s_i * c)
+	share := edwards25519.NewScalar().Multiply(session.lagrangeCoefficient(ks.Index), secretShare)
+	share.Multiply(share, session.challenge)
+	share.MultiplyAdd(binding, session.bindingFactors[ks.Index], share)
+	share.Add(share, hiding)
+	return SignatureShare{
+		Index: ks.Index,
+		Share: share.Bytes(),
+	}, nil
+}
+
+// Aggregate verifies the signature shares of all signers of a signing session,
+// and combines them into the signature of the group for the message.
+// The returned signature is a regular Ed25519 signature for the public key of the group.
+func (gk GroupKey) Aggregate(message crypto.Hash, commitments []SigningCommitment, shares []SignatureShare) (types.ByteSlice, error) {
+	session, err := newSigningSession(gk, message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) != len(commitments) {
+		return nil, fmt.Errorf("expected %d signature shares, received %d", len(commitments), len(shares))
+	}
+
+	z := edwards25519.NewScalar()
+	aggregated := make(map[uint64]struct{}, len(shares))
+	for _, share := range shares {
+		commitment, ok := session.commitments[share.Index]
+		if !ok {
+			return nil, fmt.Errorf("signature share of participant %d: %v", share.Index, ErrInvalidParticipantIndex)
+		}
+		if _, ok = aggregated[share.Index]; ok {
+			return nil, fmt.Errorf("received multiple signature shares from participant %d", share.Index)
+		}
+		zi, err := edwards25519.NewScalar().SetCanonicalBytes(share.Share)
+		if err != nil {
+			return nil, fmt.Errorf("signature share of participant %d: %v", share.Index, ErrInvalidSignatureShare)
+		}
+		verificationShare, err := new(edwards25519.Point).SetBytes(gk.VerificationShares[share.Index-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification share of participant %d: %v", share.Index, err)
+		}
+		// z_i * G == D_i + (ρ_i * E_i) + (c * λ_i * Y_i)
+		expected := new(edwards25519.Point).ScalarMult(
+			edwards25519.NewScalar().Multiply(session.challenge, session.lagrangeCoefficient(share.Index)),
+			verificationShare)
+		expected.Add(expected, commitment.commitmentShare(session.bindingFactors[share.Index]))
+		if new(edwards25519.Point).ScalarBaseMult(zi).Equal(expected) != 1 {
+			return nil, fmt.Errorf("signature share of participant %d: %v", share.Index, ErrInvalidSignatureShare)
+		}
+		aggregated[share.Index] = struct{}{}
+		z.Add(z, zi)
+	}
+
+	signature := make(types.ByteSlice, 0, crypto.SignatureSize)
+	signature = append(signature, session.groupCommitment.Bytes()...)
+	signature = append(signature, z.Bytes()...)
+	// sanity check: the aggregated signature is a regular Ed25519 signature
+	var pk crypto.PublicKey
+	copy(pk[:], gk.PublicKey.Key)
+	var sig crypto.Signature
+	copy(sig[:], signature)
+	if err = crypto.VerifyHash(message, pk, sig); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// signingSession contains the state shared by all signers of a signing session,
+// derived from the group key, message and the commitments of all signers.
+type signingSession struct {
+	commitments     map[uint64]signingCommitment
+	bindingFactors  map[uint64]*edwards25519.Scalar
+	groupCommitment *edwards25519.Point
+	challenge       *edwards25519.Scalar
+}
+
+type signingCommitment struct {
+	SigningCommitment
+	hiding, binding *edwards25519.Point
+}
+
+func newSigningSession(gk GroupKey, message crypto.Hash, commitments []SigningCommitment) (*signingSession, error) {
+	if uint64(len(commitments)) < gk.MinimumSignatureCount {
+		return nil, ErrInsufficientSigners
+	}
+	if uint64(len(gk.VerificationShares)) != gk.ParticipantCount {
+		return nil, errors.New("group key does not define a verification share for every participant")
+	}
+	if gk.PublicKey.Algorithm != types.SignatureAlgoEd25519 || len(gk.PublicKey.Key) != crypto.PublicKeySize {
+		return nil, errors.New("group key does not define a valid Ed25519 public key")
+	}
+
+	// the commitments are ordered by index, such that all signers derive the same binding factors
+	sorted := make([]SigningCommitment, len(commitments))
+	copy(sorted, commitments)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+	session := &signingSession{
+		commitments:    make(map[uint64]signingCommitment, len(sorted)),
+		bindingFactors: make(map[uint64]*edwards25519.Scalar, len(sorted)),
+	}
+	h := sha512.New()
+	h.Write(frostContextCommitments)
+	for idx, c := range sorted {
+		if c.Index == 0 || c.Index > gk.ParticipantCount {
+			return nil, fmt.Errorf("signing commitment #%d: %v", idx, ErrInvalidParticipantIndex)
+		}
+		if idx > 0 && sorted[idx-1].Index == c.Index {
+			return nil, fmt.Errorf("received multiple signing commitments from participant %d", c.Index)
+		}
+		hiding, err := new(edwards25519.Point).SetBytes(c.Hiding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hiding commitment of participant %d: %v", c.Index, err)
+		}
+		binding, err := new(edwards25519.Point).SetBytes(c.Binding)
+		if err != nil {
+			return nil, fmt.Errorf("invalid binding commitment of participant %d: %v", c.Index, err)
+		}
+		session.commitments[c.Index] = signingCommitment{
+			SigningCommitment: c,
+			hiding:            hiding,
+			binding:           binding,
+		}
+		h.Write(encodeIndex(c.Index))
+		h.Write(c.Hiding)
+		h.Write(c.Binding)
+	}
+	encodedCommitments := h.Sum(nil)
+
+	// R = Σ D_i + (ρ_i * E_i)
+	session.groupCommitment = edwards25519.NewIdentityPoint()
+	for _, c := range sorted {
+		rho := hashToScalar(frostContextBindingFactor, gk.PublicKey.Key, message[:], encodedCommitments, encodeIndex(c.Index))
+		session.bindingFactors[c.Index] = rho
+		session.groupCommitment.Add(session.groupCommitment, session.commitments[c.Index].commitmentShare(rho))
+	}
+	// c = H(R || Y || m), as defined by Ed25519
+	session.challenge = hashToScalar(session.groupCommitment.Bytes(), gk.PublicKey.Key, message[:])
+	return session, nil
+}
+
+// lagrangeCoefficient returns the Lagrange coefficient of the given signer,
+// evaluated at zero, for the set of signers of this session.
+func (session *signingSession) lagrangeCoefficient(index uint64) *edwards25519.Scalar {
+	numerator := scalarFromIndex(1)
+	denominator := scalarFromIndex(1)
+	x := scalarFromIndex(index)
+	for other := range session.commitments {
+		if other == index {
+			continue
+		}
+		xj := scalarFromIndex(other)
+		numerator.Multiply(numerator, xj)
+		denominator.Multiply(denominator, edwards25519.NewScalar().Subtract(xj, x))
+	}
+	return numerator.Multiply(numerator, denominator.Invert(denominator))
+}
+
+// commitmentShare returns D_i + (ρ_i * E_i).
+func (c signingCommitment) commitmentShare(bindingFactor *edwards25519.Scalar) *edwards25519.Point {
+	p := new(edwards25519.Point).ScalarMult(bindingFactor, c.binding)
+	return p.Add(p, c.hiding)
+}
+
+func validateGroupParameters(minimumSignatureCount, participantCount uint64) error {
+	if minimumSignatureCount == 0 {
+		return errors.New("a minimum amount of required signatures must be specified")
+	}
+	if participantCount < 2 {
+		return errors.New("a group requires at least two participants")
+	}
+	if minimumSignatureCount > participantCount {
+		return errors.New("the minimum amount of signatures can't be higher than the amount of participants")
+	}
+	return nil
+}
+
+func dkgProofChallenge(index uint64, commitment, proofCommitment []byte) *edwards25519.Scalar {
+	return hashToScalar(frostContextDKGProof, encodeIndex(index), commitment, proofCommitment)
+}
+
+// evaluatePolynomial evaluates the polynomial defined by the given coefficients at x.
+func evaluatePolynomial(coefficients []*edwards25519.Scalar, x uint64) *edwards25519.Scalar {
+	sx := scalarFromIndex(x)
+	result := edwards25519.NewScalar()
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result.MultiplyAdd(result, sx, coefficients[i])
+	}
+	return result
+}
+
+// evaluateCommitments evaluates the polynomial committed to by the given commitments at x,
+// returning the public point of the evaluated polynomial.
+func evaluateCommitments(commitments []*edwards25519.Point, x uint64) *edwards25519.Point {
+	sx := scalarFromIndex(x)
+	result := edwards25519.NewIdentityPoint()
+	for i := len(commitments) - 1; i >= 0; i-- {
+		result.ScalarMult(sx, result)
+		result.Add(result, commitments[i])
+	}
+	return result
+}
+
+// randomScalar creates a random scalar, hashed together with the given secret (if any).
+func randomScalar(secret []byte) *edwards25519.Scalar {
+	return hashToScalar(frostContextNonce, fastrand.Bytes(32), secret)
+}
+
+// hashToScalar hashes all given byte slices using SHA-512,
+// and reduces the result to a scalar.
+func hashToScalar(parts ...[]byte) *edwards25519.Scalar {
+	h := sha512.New()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	s, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		panic(err) // cannot happen, as a SHA-512 checksum is always 64 bytes
+	}
+	return s
+}
+
+func scalarFromIndex(index uint64) *edwards25519.Scalar {
+	var b [32]byte
+	binary.LittleEndian.PutUint64(b[:], index)
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(b[:])
+	if err != nil {
+		panic(err) // cannot happen, as a uint64 is always smaller than the group order
+	}
+	return s
+}
+
+func encodeIndex(index uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], index)
+	return b[:]
+}
+
+func decodeScalars(encoded []types.ByteSlice) ([]*edwards25519.Scalar, error) {
+	scalars := make([]*edwards25519.Scalar, len(encoded))
+	for i, b := range encoded {
+		s, err := edwards25519.NewScalar().SetCanonicalBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		scalars[i] = s
+	}
+	return scalars, nil
+}
+
+func decodePoints(encoded []types.ByteSlice) ([]*edwards25519.Point, error) {
+	points := make([]*edwards25519.Point, len(encoded))
+	for i, b := range encoded {
+		p, err := new(edwards25519.Point).SetBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = p
+	}
+	return points, nil
+}
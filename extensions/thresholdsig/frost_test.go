@@ -0,0 +1,191 @@
+package thresholdsig
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// generateKeyShares runs the distributed key generation for a group,
+// returning the key shares of all participants.
+func generateKeyShares(t *testing.T, minimumSignatureCount, participantCount uint64) []KeyShare {
+	participants := make([]*DKGParticipant, participantCount)
+	round1 := make([]DKGRound1Package, participantCount)
+	for i := range participants {
+		var err error
+		participants[i], round1[i], err = NewDKGParticipant(uint64(i+1), minimumSignatureCount, participantCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var round2 []DKGRound2Package
+	for i, p := range participants {
+		others := make([]DKGRound1Package, 0, participantCount-1)
+		others = append(others, round1[:i]...)
+		others = append(others, round1[i+1:]...)
+		packages, err := p.Round2(others)
+		if err != nil {
+			t.Fatal(err)
+		}
+		round2 = append(round2, packages...)
+	}
+	shares := make([]KeyShare, participantCount)
+	for i, p := range participants {
+		var err error
+		shares[i], err = p.Finalize(round2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && !shares[i].Group.Condition().Equal(shares[0].Group.Condition()) {
+			t.Fatalf("participant %d computed a different group key", i+1)
+		}
+	}
+	return shares
+}
+
+// sign the message using the given signers, returning the aggregated signature.
+func sign(t *testing.T, message crypto.Hash, signers ...KeyShare) (types.ByteSlice, error) {
+	nonces := make([]SigningNonces, len(signers))
+	commitments := make([]SigningCommitment, len(signers))
+	for i, signer := range signers {
+		nonces[i], commitments[i] = signer.NewSigningNonces()
+	}
+	shares := make([]SignatureShare, len(signers))
+	for i, signer := range signers {
+		var err error
+		shares[i], err = signer.Sign(message, nonces[i], commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signers[0].Group.Aggregate(message, commitments, shares)
+}
+
+func TestThresholdSigning(t *testing.T) {
+	shares := generateKeyShares(t, 3, 5)
+	var pk crypto.PublicKey
+	copy(pk[:], shares[0].Group.PublicKey.Key)
+
+	var message crypto.Hash
+	fastrand.Read(message[:])
+	for _, signers := range [][]KeyShare{
+		{shares[0], shares[1], shares[2]},
+		{shares[4], shares[2], shares[0]},
+		{shares[1], shares[2], shares[3], shares[4]},
+		shares,
+	} {
+		signature, err := sign(t, message, signers...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the aggregated signature is a regular Ed25519 signature
+		var sig crypto.Signature
+		copy(sig[:], signature)
+		if err = crypto.VerifyHash(message, pk, sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// less signers than the minimum signature count is not allowed
+	nonces, commitment := shares[0].NewSigningNonces()
+	_, otherCommitment := shares[1].NewSigningNonces()
+	_, err := shares[0].Sign(message, nonces, []SigningCommitment{commitment, otherCommitment})
+	if err != ErrInsufficientSigners {
+		t.Fatalf("expected insufficient signers error, got: %v", err)
+	}
+}
+
+func TestThresholdSigningInvalidShare(t *testing.T) {
+	shares := generateKeyShares(t, 2, 3)
+	var message crypto.Hash
+	fastrand.Read(message[:])
+
+	signers := []KeyShare{shares[0], shares[2]}
+	nonces := make([]SigningNonces, len(signers))
+	commitments := make([]SigningCommitment, len(signers))
+	for i, signer := range signers {
+		nonces[i], commitments[i] = signer.NewSigningNonces()
+	}
+	signatureShares := make([]SignatureShare, len(signers))
+	for i, signer := range signers {
+		var err error
+		signatureShares[i], err = signer.Sign(message, nonces[i], commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a share created for another message is rejected
+	var otherMessage crypto.Hash
+	fastrand.Read(otherMessage[:])
+	invalidShare, err := signers[1].Sign(otherMessage, nonces[1], commitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = signers[0].Group.Aggregate(message, commitments, []SignatureShare{signatureShares[0], invalidShare})
+	if err == nil {
+		t.Fatal("expected a signature share for another message to be rejected")
+	}
+
+	// nonces can only be used with their own commitment
+	_, err = signers[0].Sign(message, nonces[1], commitments)
+	if err == nil {
+		t.Fatal("expected nonces of another signer to be rejected")
+	}
+
+	// the valid shares can still be aggregated
+	if _, err = signers[0].Group.Aggregate(message, commitments, signatureShares); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDKGInvalidPackages(t *testing.T) {
+	p1, pkg1, err := NewDKGParticipant(1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pkg2, err := NewDKGParticipant(2, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pkg3, err := NewDKGParticipant(3, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = NewDKGParticipant(4, 2, 3); err != ErrInvalidParticipantIndex {
+		t.Fatalf("expected invalid participant index error, got: %v", err)
+	}
+	if _, _, err = NewDKGParticipant(1, 4, 3); err == nil {
+		t.Fatal("expected minimum signature count higher than the participant count to be rejected")
+	}
+
+	// a package with a proof for another participant is rejected
+	forged := pkg3
+	forged.Sender = 2
+	if _, err = p1.Round2([]DKGRound1Package{forged, pkg3}); err == nil {
+		t.Fatal("expected duplicate sender to be rejected")
+	}
+	forged.ProofResponse = pkg2.ProofResponse
+	if _, err = p1.Round2([]DKGRound1Package{forged, pkg3}); err == nil {
+		t.Fatal("expected invalid proof of knowledge to be rejected")
+	}
+	// our own package is not expected
+	if _, err = p1.Round2([]DKGRound1Package{pkg1, pkg3}); err == nil {
+		t.Fatal("expected own package to be rejected")
+	}
+
+	// a share which does not match the commitments of its sender is rejected
+	round2, err := p1.Round2([]DKGRound1Package{pkg2, pkg3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := []DKGRound2Package{
+		{Sender: 2, Receiver: 1, Share: round2[0].Share},
+		{Sender: 3, Receiver: 1, Share: round2[1].Share},
+	}
+	if _, err = p1.Finalize(invalid); err == nil {
+		t.Fatal("expected invalid shares to be rejected")
+	}
+}
@@ -0,0 +1,233 @@
+package thresholdsig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// ConditionTypeThresholdSignature defines an unlock condition which can only be fulfilled
+	// by a single (aggregated) signature of a group, created by at least the minimum amount of
+	// participants of that group. Contrary to the MultiSignatureCondition, the condition does not
+	// define the identities of the participants, only the public key of the group.
+	ConditionTypeThresholdSignature types.ConditionType = 5
+
+	// FulfillmentTypeThresholdSignature defines the fulfillment of a ThresholdSignatureCondition,
+	// a single Ed25519 signature, aggregated by the signers of the group.
+	FulfillmentTypeThresholdSignature types.FulfillmentType = 4
+
+	// UnlockTypeThresholdSignature is the unlock type of the unlock hash (address)
+	// of a ThresholdSignatureCondition.
+	UnlockTypeThresholdSignature types.UnlockType = 4
+)
+
+var (
+	// ErrInvalidThresholdSignature is returned in case the signature of a ThresholdSignatureFulfillment
+	// is not a valid signature of the group.
+	ErrInvalidThresholdSignature = errors.New("invalid threshold signature")
+)
+
+// RegisterUnlockTypes registers the threshold signature condition and fulfillment types,
+// such that outputs can be locked by (and inputs unlocked by) the aggregated signature of a group.
+//
+// As these types are part of the consensus rules,
+// all nodes of a network should register them at the same time.
+func RegisterUnlockTypes() {
+	types.RegisterUnlockConditionType(ConditionTypeThresholdSignature,
+		func() types.MarshalableUnlockCondition { return &ThresholdSignatureCondition{} })
+	types.RegisterUnlockFulfillmentType(FulfillmentTypeThresholdSignature,
+		func() types.MarshalableUnlockFulfillment { return &ThresholdSignatureFulfillment{} })
+}
+
+// UnregisterUnlockTypes unregisters the threshold signature condition and fulfillment types.
+func UnregisterUnlockTypes() {
+	types.RegisterUnlockConditionType(ConditionTypeThresholdSignature, nil)
+	types.RegisterUnlockFulfillmentType(FulfillmentTypeThresholdSignature, nil)
+}
+
+type (
+	// ThresholdSignatureCondition implements the ConditionTypeThresholdSignature ConditionType.
+	// See ConditionTypeThresholdSignature for more information.
+	ThresholdSignatureCondition struct {
+		MinimumSignatureCount uint64          `json:"minimumsignaturecount"`
+		ParticipantCount      uint64          `json:"participantcount"`
+		PublicKey             types.PublicKey `json:"publickey"`
+	}
+
+	// ThresholdSignatureFulfillment implements the FulfillmentTypeThresholdSignature FulfillmentType.
+	// See FulfillmentTypeThresholdSignature for more information.
+	ThresholdSignatureFulfillment struct {
+		Signature types.ByteSlice `json:"signature"`
+	}
+)
+
+var (
+	_ types.MarshalableUnlockCondition   = (*ThresholdSignatureCondition)(nil)
+	_ types.MarshalableUnlockFulfillment = (*ThresholdSignatureFulfillment)(nil)
+)
+
+// NewThresholdSignatureCondition creates a new condition,
+// which can be fulfilled by a signature of at least minimumSignatureCount participants
+// of the group identified by the given (Ed25519) public key.
+func NewThresholdSignatureCondition(minimumSignatureCount, participantCount uint64, pk types.PublicKey) *ThresholdSignatureCondition {
+	return &ThresholdSignatureCondition{
+		MinimumSignatureCount: minimumSignatureCount,
+		ParticipantCount:      participantCount,
+		PublicKey:             pk,
+	}
+}
+
+// Fulfill implements UnlockCondition.Fulfill
+func (tsc *ThresholdSignatureCondition) Fulfill(fulfillment types.UnlockFulfillment, ctx types.FulfillContext) error {
+	tsf, ok := fulfillment.(*ThresholdSignatureFulfillment)
+	if !ok {
+		return types.ErrUnexpectedUnlockFulfillment
+	}
+	hash, err := SignatureHash(ctx.Transaction, ctx.ExtraObjects...)
+	if err != nil {
+		return err
+	}
+	if len(tsc.PublicKey.Key) != crypto.PublicKeySize || len(tsf.Signature) != crypto.SignatureSize {
+		return ErrInvalidThresholdSignature
+	}
+	var (
+		pk  crypto.PublicKey
+		sig crypto.Signature
+	)
+	copy(pk[:], tsc.PublicKey.Key)
+	copy(sig[:], tsf.Signature)
+	if crypto.VerifyHash(hash, pk, sig) != nil {
+		return ErrInvalidThresholdSignature
+	}
+	return nil
+}
+
+// ConditionType implements UnlockCondition.ConditionType
+func (tsc *ThresholdSignatureCondition) ConditionType() types.ConditionType {
+	return ConditionTypeThresholdSignature
+}
+
+// IsStandardCondition implements UnlockCondition.IsStandardCondition
+func (tsc *ThresholdSignatureCondition) IsStandardCondition(types.ValidationContext) error {
+	err := validateGroupParameters(tsc.MinimumSignatureCount, tsc.ParticipantCount)
+	if err != nil {
+		return err
+	}
+	if tsc.PublicKey.Algorithm != types.SignatureAlgoEd25519 {
+		return fmt.Errorf("unsupported group public key algorithm: %s", tsc.PublicKey.Algorithm.String())
+	}
+	if len(tsc.PublicKey.Key) != crypto.PublicKeySize {
+		return errors.New("invalid group public key size")
+	}
+	return nil
+}
+
+// UnlockHash implements UnlockCondition.UnlockHash
+//
+// The unlock hash commits to the group parameters as well as the public key of the group.
+func (tsc *ThresholdSignatureCondition) UnlockHash() types.UnlockHash {
+	h, err := crypto.HashAll(tsc.MinimumSignatureCount, tsc.ParticipantCount, tsc.PublicKey)
+	if err != nil {
+		return types.NilUnlockHash
+	}
+	return types.NewUnlockHash(UnlockTypeThresholdSignature, h)
+}
+
+// GetMinimumSignatureCount returns the minimum amount of participants
+// required to sign in order to fulfill this ThresholdSignatureCondition.
+func (tsc *ThresholdSignatureCondition) GetMinimumSignatureCount() uint64 {
+	return tsc.MinimumSignatureCount
+}
+
+// Equal implements UnlockCondition.Equal
+func (tsc *ThresholdSignatureCondition) Equal(c types.UnlockCondition) bool {
+	otsc, ok := c.(*ThresholdSignatureCondition)
+	if !ok {
+		return false
+	}
+	return tsc.MinimumSignatureCount == otsc.MinimumSignatureCount &&
+		tsc.ParticipantCount == otsc.ParticipantCount &&
+		tsc.PublicKey.Algorithm == otsc.PublicKey.Algorithm &&
+		bytes.Equal(tsc.PublicKey.Key, otsc.PublicKey.Key)
+}
+
+// Fulfillable implements UnlockCondition.Fulfillable
+func (tsc *ThresholdSignatureCondition) Fulfillable(types.FulfillableContext) bool {
+	return true
+}
+
+// Marshal implements MarshalableUnlockCondition.Marshal
+func (tsc *ThresholdSignatureCondition) Marshal(f types.MarshalFunc) ([]byte, error) {
+	return f(tsc.MinimumSignatureCount, tsc.ParticipantCount, tsc.PublicKey)
+}
+
+// Unmarshal implements MarshalableUnlockCondition.Unmarshal
+func (tsc *ThresholdSignatureCondition) Unmarshal(b []byte, f types.UnmarshalFunc) error {
+	return f(b, &tsc.MinimumSignatureCount, &tsc.ParticipantCount, &tsc.PublicKey)
+}
+
+// NewThresholdSignatureFulfillment creates a new fulfillment using the given aggregated signature,
+// it can also be created unsigned, in which case the signature is to be added by calling Sign.
+func NewThresholdSignatureFulfillment(signature types.ByteSlice) *ThresholdSignatureFulfillment {
+	return &ThresholdSignatureFulfillment{Signature: signature}
+}
+
+// SignatureHash returns the hash to be signed by the group, in order to fulfill
+// a ThresholdSignatureCondition, for the input identified by the given extra objects
+// (e.g. the input index) within the given transaction.
+func SignatureHash(txn types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	return txn.SignatureHash(extraObjects...)
+}
+
+// FulfillmentType implements UnlockFulfillment.FulfillmentType
+func (tsf *ThresholdSignatureFulfillment) FulfillmentType() types.FulfillmentType {
+	return FulfillmentTypeThresholdSignature
+}
+
+// IsStandardFulfillment implements UnlockFulfillment.IsStandardFulfillment
+func (tsf *ThresholdSignatureFulfillment) IsStandardFulfillment(types.ValidationContext) error {
+	if len(tsf.Signature) != crypto.SignatureSize {
+		return fmt.Errorf("invalid threshold signature size: %d", len(tsf.Signature))
+	}
+	return nil
+}
+
+// Equal implements UnlockFulfillment.Equal
+func (tsf *ThresholdSignatureFulfillment) Equal(f types.UnlockFulfillment) bool {
+	otsf, ok := f.(*ThresholdSignatureFulfillment)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(tsf.Signature, otsf.Signature)
+}
+
+// Sign implements UnlockFulfillment.Sign
+//
+// As the signature is created by multiple participants, it cannot be created as part of this call.
+// Instead the key is expected to be the aggregated signature (as a types.ByteSlice),
+// created by the signers of the group for the hash returned by SignatureHash.
+func (tsf *ThresholdSignatureFulfillment) Sign(ctx types.FulfillmentSignContext) error {
+	signature, ok := ctx.Key.(types.ByteSlice)
+	if !ok {
+		return errors.New("invalid aggregated signature to sign this input")
+	}
+	if len(signature) != crypto.SignatureSize {
+		return fmt.Errorf("invalid threshold signature size: %d", len(signature))
+	}
+	tsf.Signature = signature
+	return nil
+}
+
+// Marshal implements MarshalableUnlockFulfillment.Marshal
+func (tsf *ThresholdSignatureFulfillment) Marshal(f types.MarshalFunc) ([]byte, error) {
+	return f(tsf.Signature)
+}
+
+// Unmarshal implements MarshalableUnlockFulfillment.Unmarshal
+func (tsf *ThresholdSignatureFulfillment) Unmarshal(b []byte, f types.UnmarshalFunc) error {
+	return f(b, &tsf.Signature)
+}
@@ -0,0 +1,116 @@
+package thresholdsig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestThresholdSignatureCondition(t *testing.T) {
+	RegisterUnlockTypes()
+	defer UnregisterUnlockTypes()
+
+	shares := generateKeyShares(t, 2, 3)
+	condition := types.NewCondition(shares[0].Group.Condition())
+	if err := condition.IsStandardCondition(types.ValidationContext{}); err != nil {
+		t.Fatal(err)
+	}
+	if uh := condition.UnlockHash(); uh.Type != UnlockTypeThresholdSignature {
+		t.Fatalf("unexpected unlock hash type: %d", uh.Type)
+	}
+
+	// the condition can be encoded using all encodings
+	b, err := json.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonCondition types.UnlockConditionProxy
+	if err = json.Unmarshal(b, &jsonCondition); err != nil {
+		t.Fatal(err)
+	}
+	b, err = siabin.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var siaCondition types.UnlockConditionProxy
+	if err = siabin.Unmarshal(b, &siaCondition); err != nil {
+		t.Fatal(err)
+	}
+	b, err = rivbin.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rivCondition types.UnlockConditionProxy
+	if err = rivbin.Unmarshal(b, &rivCondition); err != nil {
+		t.Fatal(err)
+	}
+	for _, decoded := range []types.UnlockConditionProxy{jsonCondition, siaCondition, rivCondition} {
+		if !condition.Equal(decoded) {
+			t.Errorf("%v != %v", decoded, condition)
+		}
+	}
+
+	// sign a transaction spending two outputs of the group
+	txn := types.Transaction{
+		Version: types.TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []types.CoinInput{
+			{Fulfillment: types.NewFulfillment(NewThresholdSignatureFulfillment(nil))},
+			{Fulfillment: types.NewFulfillment(NewThresholdSignatureFulfillment(nil))},
+		},
+	}
+	for idx, ci := range txn.CoinInputs {
+		hash, err := SignatureHash(txn, uint64(idx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := sign(t, hash, shares[2], shares[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ci.Fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: []interface{}{uint64(idx)},
+			Transaction:  txn,
+			Key:          signature,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for idx, ci := range txn.CoinInputs {
+		if err = ci.Fulfillment.IsStandardFulfillment(types.ValidationContext{}); err != nil {
+			t.Fatal(err)
+		}
+		err = condition.Fulfill(ci.Fulfillment, types.FulfillContext{
+			ExtraObjects: []interface{}{uint64(idx)},
+			BlockTime:    types.CurrentTimestamp(),
+			Transaction:  txn,
+		})
+		if err != nil {
+			t.Fatalf("failed to fulfill input #%d: %v", idx, err)
+		}
+	}
+
+	// the signature of one input cannot be used for another input
+	err = condition.Fulfill(txn.CoinInputs[0].Fulfillment, types.FulfillContext{
+		ExtraObjects: []interface{}{uint64(1)},
+		BlockTime:    types.CurrentTimestamp(),
+		Transaction:  txn,
+	})
+	if err != ErrInvalidThresholdSignature {
+		t.Fatalf("expected invalid threshold signature error, got: %v", err)
+	}
+
+	// a group of which the key was not generated by these participants cannot fulfill the condition
+	otherCondition := types.NewCondition(generateKeyShares(t, 2, 3)[0].Group.Condition())
+	err = otherCondition.Fulfill(txn.CoinInputs[0].Fulfillment, types.FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		BlockTime:    types.CurrentTimestamp(),
+		Transaction:  txn,
+	})
+	if err != ErrInvalidThresholdSignature {
+		t.Fatalf("expected invalid threshold signature error, got: %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/threefoldtech/rivine/extensions/tokens"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+)
+
+// RegisterConsensusTokensHTTPHandlers registers the default Rivine handlers for all default Rivine consensus tokens HTTP endpoints.
+func RegisterConsensusTokensHTTPHandlers(router rapi.Router, plugin *tokens.Plugin) {
+	router.GET("/consensus/tokens/assets", NewGetAssetsHandler(plugin))
+	router.GET("/consensus/tokens/assets/:id", NewGetAssetHandler(plugin))
+	router.GET("/consensus/tokens/outputs/:id", NewGetTokenOutputHandler(plugin))
+	router.GET("/consensus/tokens/addresses/:unlockhash", NewGetAddressTokensHandler(plugin))
+}
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/threefoldtech/rivine/extensions/tokens"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+)
+
+// RegisterExplorerTokensHTTPHandlers registers the default Rivine handlers for all default Rivine explorer tokens HTTP endpoints.
+func RegisterExplorerTokensHTTPHandlers(router rapi.Router, plugin *tokens.Plugin) {
+	router.GET("/explorer/tokens/assets", NewGetAssetsHandler(plugin))
+	router.GET("/explorer/tokens/assets/:id", NewGetAssetHandler(plugin))
+	router.GET("/explorer/tokens/outputs/:id", NewGetTokenOutputHandler(plugin))
+	router.GET("/explorer/tokens/addresses/:unlockhash", NewGetAddressTokensHandler(plugin))
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/extensions/tokens"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// GetAssets contains all assets known to the tokens plugin.
+	GetAssets struct {
+		Assets []tokens.Asset `json:"assets"`
+	}
+
+	// GetAsset contains a requested asset.
+	GetAsset struct {
+		Asset tokens.Asset `json:"asset"`
+	}
+
+	// GetTokenOutput contains a requested unspent token output.
+	GetTokenOutput struct {
+		Output tokens.TokenOutput `json:"output"`
+	}
+
+	// GetAddressTokens contains the token balances and
+	// unspent token outputs for a requested address.
+	GetAddressTokens struct {
+		Balances map[string]types.Currency   `json:"balances"`
+		Outputs  []tokens.UnspentTokenOutput `json:"outputs"`
+	}
+)
+
+// NewGetAssetsHandler creates a handler to handle the API calls to /tokens/assets.
+func NewGetAssetsHandler(plugin *tokens.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		assets, err := plugin.GetAssets()
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetAssets{
+			Assets: assets,
+		})
+	}
+}
+
+// NewGetAssetHandler creates a handler to handle the API calls to /tokens/assets/:id.
+func NewGetAssetHandler(plugin *tokens.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id tokens.AssetID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid asset ID given: %v", err)}, http.StatusBadRequest)
+			return
+		}
+		asset, err := plugin.GetAsset(id)
+		if err != nil {
+			if err == tokens.ErrAssetNotFound {
+				rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusNoContent)
+				return
+			}
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetAsset{
+			Asset: asset,
+		})
+	}
+}
+
+// NewGetTokenOutputHandler creates a handler to handle the API calls to /tokens/outputs/:id.
+func NewGetTokenOutputHandler(plugin *tokens.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id tokens.TokenOutputID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid token output ID given: %v", err)}, http.StatusBadRequest)
+			return
+		}
+		output, err := plugin.GetTokenOutput(id)
+		if err != nil {
+			if err == tokens.ErrTokenOutputNotFound {
+				rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusNoContent)
+				return
+			}
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, GetTokenOutput{
+			Output: output,
+		})
+	}
+}
+
+// NewGetAddressTokensHandler creates a handler to handle the API calls to /tokens/addresses/:unlockhash.
+func NewGetAddressTokensHandler(plugin *tokens.Plugin) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var uh types.UnlockHash
+		err := uh.LoadString(ps.ByName("unlockhash"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: fmt.Sprintf("invalid unlock hash given: %v", err)}, http.StatusBadRequest)
+			return
+		}
+		outputs, err := plugin.GetUnspentTokenOutputs(uh)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		balances := make(map[string]types.Currency)
+		for _, uto := range outputs {
+			key := uto.Output.AssetID.String()
+			balances[key] = balances[key].Add(uto.Output.Value)
+		}
+		rapi.WriteJSON(w, GetAddressTokens{
+			Balances: balances,
+			Outputs:  outputs,
+		})
+	}
+}
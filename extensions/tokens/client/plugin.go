@@ -0,0 +1,97 @@
+package client
+
+import (
+	"fmt"
+
+	tokens "github.com/threefoldtech/rivine/extensions/tokens"
+	"github.com/threefoldtech/rivine/extensions/tokens/api"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	client "github.com/threefoldtech/rivine/pkg/client"
+	types "github.com/threefoldtech/rivine/types"
+)
+
+// PluginClient is used to be able to get the assets and unspent token outputs
+// tracked by the tokens plugin, such that the CLI can sign and validate token transactions,
+// without requiring access to the consensus-extended database.
+type PluginClient struct {
+	client       client.BaseClient
+	rootEndpoint string
+}
+
+// NewPluginConsensusClient creates a new PluginClient,
+// that can be used for easy interaction with the tokens API exposed via the Consensus endpoints
+func NewPluginConsensusClient(cli client.BaseClient) *PluginClient {
+	if cli == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		client:       cli,
+		rootEndpoint: "/consensus",
+	}
+}
+
+// NewPluginExplorerClient creates a new PluginClient,
+// that can be used for easy interaction with the tokens API exposed via the Explorer endpoints
+func NewPluginExplorerClient(cli client.BaseClient) *PluginClient {
+	if cli == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		client:       cli,
+		rootEndpoint: "/explorer",
+	}
+}
+
+var (
+	// ensure PluginClient implements the TokenInfoGetter interface
+	_ tokens.TokenInfoGetter = (*PluginClient)(nil)
+)
+
+// GetAssets returns all assets known to the daemon.
+func (cli *PluginClient) GetAssets() ([]tokens.Asset, error) {
+	var result api.GetAssets
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/tokens/assets", &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets from daemon: %v", err)
+	}
+	return result.Assets, nil
+}
+
+// GetAsset implements tokens.AssetGetter.GetAsset
+func (cli *PluginClient) GetAsset(id tokens.AssetID) (tokens.Asset, error) {
+	var result api.GetAsset
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/tokens/assets/"+id.String(), &result)
+	if err != nil {
+		if err == rapi.ErrStatusNotFound {
+			return tokens.Asset{}, tokens.ErrAssetNotFound
+		}
+		return tokens.Asset{}, fmt.Errorf(
+			"failed to get asset %s from daemon: %v", id.String(), err)
+	}
+	return result.Asset, nil
+}
+
+// GetTokenOutput implements tokens.TokenOutputGetter.GetTokenOutput
+func (cli *PluginClient) GetTokenOutput(id tokens.TokenOutputID) (tokens.TokenOutput, error) {
+	var result api.GetTokenOutput
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/tokens/outputs/"+id.String(), &result)
+	if err != nil {
+		if err == rapi.ErrStatusNotFound {
+			return tokens.TokenOutput{}, tokens.ErrTokenOutputNotFound
+		}
+		return tokens.TokenOutput{}, fmt.Errorf(
+			"failed to get token output %s from daemon: %v", id.String(), err)
+	}
+	return result.Output, nil
+}
+
+// GetAddressTokens returns the token balances and unspent token outputs of the given address.
+func (cli *PluginClient) GetAddressTokens(uh types.UnlockHash) (api.GetAddressTokens, error) {
+	var result api.GetAddressTokens
+	err := cli.client.HTTP().GetWithResponse(cli.rootEndpoint+"/tokens/addresses/"+uh.String(), &result)
+	if err != nil {
+		return api.GetAddressTokens{}, fmt.Errorf(
+			"failed to get tokens of address %s from daemon: %v", uh.String(), err)
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	tokens "github.com/threefoldtech/rivine/extensions/tokens"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+	types "github.com/threefoldtech/rivine/types"
+)
+
+// CreateExploreCmd adds the explorer cli subcommands for the tokens plugin
+func CreateExploreCmd(cli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(cli)
+	if err != nil {
+		return err
+	}
+	createCmd(cli.ExploreCmd, NewPluginExplorerClient(bc))
+	return nil
+}
+
+// CreateConsensusCmd adds the consensus cli subcommands for the tokens plugin
+func CreateConsensusCmd(cli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(cli)
+	if err != nil {
+		return err
+	}
+	createCmd(cli.ConsensusCmd, NewPluginConsensusClient(bc))
+	return nil
+}
+
+func createCmd(rootCmd *cobra.Command, pluginClient *PluginClient) {
+	subCmds := &subCmd{
+		pluginClient: pluginClient,
+	}
+
+	// create root tokens command and all subs
+	var (
+		tokensCmd = &cobra.Command{
+			Use:   "tokens",
+			Short: "Get information about assets and their tokens",
+		}
+		getAssetsCmd = &cobra.Command{
+			Use:   "assets",
+			Short: "Get all assets",
+			Args:  cobra.NoArgs,
+			Run:   subCmds.getAssets,
+		}
+		getAssetCmd = &cobra.Command{
+			Use:   "asset <assetID>",
+			Short: "Get an asset, including its definition and total supply",
+			Args:  cobra.ExactArgs(1),
+			Run:   subCmds.getAsset,
+		}
+		getTokenOutputCmd = &cobra.Command{
+			Use:   "output <tokenOutputID>",
+			Short: "Get an unspent token output",
+			Args:  cobra.ExactArgs(1),
+			Run:   subCmds.getTokenOutput,
+		}
+		getAddressTokensCmd = &cobra.Command{
+			Use:   "address <address>",
+			Short: "Get the token balances and unspent token outputs of an address",
+			Args:  cobra.ExactArgs(1),
+			Run:   subCmds.getAddressTokens,
+		}
+	)
+
+	tokensCmd.PersistentFlags().Var(
+		cli.NewEncodingTypeFlag(cli.EncodingTypeHuman, &subCmds.cfg.EncodingType, cli.EncodingTypeJSON|cli.EncodingTypeHuman), "encoding",
+		cli.EncodingTypeFlagDescription(cli.EncodingTypeJSON|cli.EncodingTypeHuman))
+
+	tokensCmd.AddCommand(
+		getAssetsCmd,
+		getAssetCmd,
+		getTokenOutputCmd,
+		getAddressTokensCmd,
+	)
+	rootCmd.AddCommand(tokensCmd)
+}
+
+type subCmd struct {
+	pluginClient *PluginClient
+	cfg          struct {
+		EncodingType cli.EncodingType
+	}
+}
+
+func (subCmds *subCmd) getAssets(cmd *cobra.Command, args []string) {
+	assets, err := subCmds.pluginClient.GetAssets()
+	if err != nil {
+		cli.DieWithError("failed to get the assets", err)
+	}
+	subCmds.encode(assets)
+}
+
+func (subCmds *subCmd) getAsset(cmd *cobra.Command, args []string) {
+	var id tokens.AssetID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid asset ID given", err)
+	}
+	asset, err := subCmds.pluginClient.GetAsset(id)
+	if err != nil {
+		cli.DieWithError("failed to get the asset", err)
+	}
+	subCmds.encode(asset)
+}
+
+func (subCmds *subCmd) getTokenOutput(cmd *cobra.Command, args []string) {
+	var id tokens.TokenOutputID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid token output ID given", err)
+	}
+	output, err := subCmds.pluginClient.GetTokenOutput(id)
+	if err != nil {
+		cli.DieWithError("failed to get the unspent token output", err)
+	}
+	subCmds.encode(output)
+}
+
+func (subCmds *subCmd) getAddressTokens(cmd *cobra.Command, args []string) {
+	var uh types.UnlockHash
+	err := uh.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid address given", err)
+	}
+	result, err := subCmds.pluginClient.GetAddressTokens(uh)
+	if err != nil {
+		cli.DieWithError("failed to get the tokens of the address", err)
+	}
+	subCmds.encode(result)
+}
+
+// encode depending on the encoding flag
+func (subCmds *subCmd) encode(value interface{}) {
+	var encode func(interface{}) error
+	switch subCmds.cfg.EncodingType {
+	case cli.EncodingTypeHuman:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		encode = e.Encode
+	case cli.EncodingTypeJSON:
+		encode = json.NewEncoder(os.Stdout).Encode
+	}
+	err := encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
@@ -0,0 +1,469 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	tokens "github.com/threefoldtech/rivine/extensions/tokens"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the tokens plugin
+func CreateWalletCmds(ccli *client.CommandLineClient, issuanceTxVersion, transferTxVersion types.TransactionVersion) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+	walletCmd := &walletCmd{
+		cli:               ccli,
+		walletClient:      client.NewWalletClient(bc),
+		txPoolClient:      client.NewTransactionPoolClient(bc),
+		pluginClient:      NewPluginConsensusClient(bc),
+		issuanceTxVersion: issuanceTxVersion,
+		transferTxVersion: transferTxVersion,
+	}
+
+	var (
+		createTokenIssuanceTxCmd = &cobra.Command{
+			Use:   "tokenissuancetransaction <assetID>|<symbol> <dest>|<rawCondition> <amount> [<dest>|<rawCondition> <amount>]...",
+			Short: "Create a new token issuance transaction",
+			Long: `Create a new token issuance transaction using the given outputs.
+
+When an asset ID is given, additional tokens are issued for that existing asset.
+When a symbol is given instead, a new asset is created, in which case
+the issuer condition has to be given using the --issuer flag.
+
+The outputs can be given as a pair of value and a raw output condition (or
+address, which resolves to a singlesignature condition).
+Amounts are expressed in the unit of the asset, decimals are possible
+up to the precision defined by the asset and have to be defined using the decimal point.
+
+The Minimum Miner Fee is funded using the coins of this wallet.
+
+The returned (raw) TokenIssuanceTransaction still has to be signed, prior to sending.
+`,
+			Args: cobra.MinimumNArgs(3),
+			Run:  walletCmd.createTokenIssuanceTxCmd,
+		}
+		sendTokensCmd = &cobra.Command{
+			Use:   "tokens <assetID> <dest>|<rawCondition> <amount>",
+			Short: "Send tokens of an asset to an address or raw condition",
+			Long: `Send tokens of an asset to an address or raw condition,
+using the unspent token outputs owned by this wallet.
+
+The amount is expressed in the unit of the asset, decimals are possible
+up to the precision defined by the asset and have to be defined using the decimal point.
+
+The Minimum Miner Fee is funded using the coins of this wallet.
+`,
+			Args: cobra.ExactArgs(3),
+			Run:  walletCmd.sendTokensCmd,
+		}
+		listTokensCmd = &cobra.Command{
+			Use:   "tokens",
+			Short: "List the token balances of this wallet",
+			Args:  cobra.NoArgs,
+			Run:   walletCmd.listTokensCmd,
+		}
+	)
+
+	// add commands as wallet sub commands
+	ccli.WalletCmd.RootCmdCreate.AddCommand(createTokenIssuanceTxCmd)
+	ccli.WalletCmd.RootCmdSend.AddCommand(sendTokensCmd)
+	ccli.WalletCmd.AddCommand(listTokensCmd)
+
+	// set the flags
+	createTokenIssuanceTxCmd.Flags().StringVar(
+		&walletCmd.tokenIssuanceTxCfg.Issuer,
+		"issuer", "", "the issuer condition (or address) of a new asset, required when creating a new asset")
+	createTokenIssuanceTxCmd.Flags().StringVar(
+		&walletCmd.tokenIssuanceTxCfg.Name,
+		"name", "", "the optional (human-readable) name of a new asset")
+	createTokenIssuanceTxCmd.Flags().Uint8Var(
+		&walletCmd.tokenIssuanceTxCfg.Decimals,
+		"decimals", 0, "the amount of decimals used to display the token values of a new asset")
+	cli.ArbitraryDataFlagVar(createTokenIssuanceTxCmd.Flags(), &walletCmd.tokenIssuanceTxCfg.Description,
+		"description", "optionally add a description to describe the token issuance, added as arbitrary data")
+	createTokenIssuanceTxCmd.Flags().StringVar(
+		&walletCmd.tokenIssuanceTxCfg.RefundAddress,
+		"refund-address", "", "define a custom refund address for the coins used to fund the miner fee")
+	createTokenIssuanceTxCmd.Flags().BoolVar(
+		&walletCmd.tokenIssuanceTxCfg.RefundAddressNew,
+		"refund-address-new", false, "generate a new refund address if a coin refund needs to happen")
+
+	cli.ArbitraryDataFlagVar(sendTokensCmd.Flags(), &walletCmd.sendTokensCfg.Description,
+		"description", "optionally add a description to describe the token transfer, added as arbitrary data")
+	sendTokensCmd.Flags().StringVar(
+		&walletCmd.sendTokensCfg.RefundAddress,
+		"refund-address", "", "define a custom refund address for the remaining tokens and coins")
+	sendTokensCmd.Flags().BoolVar(
+		&walletCmd.sendTokensCfg.RefundAddressNew,
+		"refund-address-new", false, "generate a new refund address if a refund needs to happen")
+
+	return nil
+}
+
+type walletCmd struct {
+	cli          *client.CommandLineClient
+	walletClient *client.WalletClient
+	txPoolClient *client.TransactionPoolClient
+	pluginClient *PluginClient
+
+	issuanceTxVersion, transferTxVersion types.TransactionVersion
+
+	tokenIssuanceTxCfg struct {
+		Issuer           string
+		Name             string
+		Decimals         uint8
+		Description      []byte
+		RefundAddress    string
+		RefundAddressNew bool
+	}
+	sendTokensCfg struct {
+		Description      []byte
+		RefundAddress    string
+		RefundAddressNew bool
+	}
+}
+
+func (walletCmd *walletCmd) createTokenIssuanceTxCmd(cmd *cobra.Command, args []string) {
+	if len(args)%2 != 1 {
+		cmd.UsageFunc()(cmd)
+		cli.Die("Invalid arguments. Arguments must be of the form <assetID>|<symbol> <dest>|<rawCondition> <amount> [<dest>|<rawCondition> <amount>]...")
+	}
+
+	tx := tokens.TokenIssuanceTransaction{
+		Nonce:     types.RandomTransactionNonce(),
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+
+	// either issue tokens for an existing asset or define a new asset
+	var decimals uint8
+	err := tx.AssetID.LoadString(args[0])
+	if err == nil {
+		asset, err := walletCmd.pluginClient.GetAsset(tx.AssetID)
+		if err != nil {
+			cli.DieWithError("failed to get the asset to issue tokens for", err)
+		}
+		decimals = asset.Definition.Decimals
+	} else {
+		if walletCmd.tokenIssuanceTxCfg.Issuer == "" {
+			cmd.UsageFunc()(cmd)
+			cli.Die("an issuer condition (or address) is required using the --issuer flag when creating a new asset")
+		}
+		issuerCondition, err := parseConditionString(walletCmd.tokenIssuanceTxCfg.Issuer)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithError("invalid issuer condition", err)
+		}
+		tx.Definition = &tokens.AssetDefinition{
+			Symbol:          args[0],
+			Name:            walletCmd.tokenIssuanceTxCfg.Name,
+			Decimals:        walletCmd.tokenIssuanceTxCfg.Decimals,
+			IssuerCondition: issuerCondition,
+		}
+		tx.AssetID = tokens.NewAssetID(tx.Nonce, *tx.Definition)
+		decimals = tx.Definition.Decimals
+	}
+
+	// parse the remainder as output coditions and values
+	for i := 1; i < len(args); i += 2 {
+		condition, err := parseConditionString(args[i])
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithError(fmt.Sprintf("failed to parse condition for output #%d", i/2), err)
+		}
+		value, err := parseTokenAmount(args[i+1], decimals)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithError(fmt.Sprintf("failed to parse amount for output #%d", i/2), err)
+		}
+		tx.TokenOutputs = append(tx.TokenOutputs, tokens.TokenOutput{
+			AssetID:   tx.AssetID,
+			Value:     value,
+			Condition: condition,
+		})
+	}
+
+	if n := len(walletCmd.tokenIssuanceTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.tokenIssuanceTxCfg.Description[:])
+	}
+
+	// fund the miner fee using the coins of this wallet
+	refundAddress, err := parseRefundAddress(walletCmd.tokenIssuanceTxCfg.RefundAddress)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+	var refundCoinOutput *types.CoinOutput
+	tx.CoinInputs, refundCoinOutput, err = walletCmd.walletClient.FundCoins(
+		walletCmd.cli.Config.MinimumTransactionFee, refundAddress, walletCmd.tokenIssuanceTxCfg.RefundAddressNew)
+	if err != nil {
+		cli.DieWithError("failed to fund the miner fee of the token issuance transaction", err)
+	}
+	if refundCoinOutput != nil {
+		tx.CoinOutputs = append(tx.CoinOutputs, *refundCoinOutput)
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction(walletCmd.issuanceTxVersion))
+	if err != nil {
+		cli.DieWithError("failed to encode token issuance transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) sendTokensCmd(cmd *cobra.Command, args []string) {
+	var assetID tokens.AssetID
+	err := assetID.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid asset ID", err)
+	}
+	asset, err := walletCmd.pluginClient.GetAsset(assetID)
+	if err != nil {
+		cli.DieWithError("failed to get the asset to send tokens of", err)
+	}
+	condition, err := parseConditionString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+	amount, err := parseTokenAmount(args[2], asset.Definition.Decimals)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid token amount", err)
+	}
+	refundAddress, err := parseRefundAddress(walletCmd.sendTokensCfg.RefundAddress)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+
+	// collect the unspent token outputs of the asset owned by this wallet,
+	// until we have collected enough tokens to send the requested amount
+	outputs, err := walletCmd.getWalletTokenOutputs()
+	if err != nil {
+		cli.DieWithError("failed to get the unspent token outputs of this wallet", err)
+	}
+	tx := tokens.TokenTransferTransaction{
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	var (
+		collected      types.Currency
+		firstCondition types.UnlockConditionProxy
+	)
+	for _, uto := range outputs {
+		if uto.Output.AssetID != assetID {
+			continue
+		}
+		if len(tx.TokenInputs) == 0 {
+			firstCondition = uto.Output.Condition
+		}
+		// the fulfillment is defined by the wallet when signing the transaction
+		tx.TokenInputs = append(tx.TokenInputs, tokens.TokenInput{
+			ParentID: uto.ID,
+		})
+		collected = collected.Add(uto.Output.Value)
+		if collected.Cmp(amount) >= 0 {
+			break
+		}
+	}
+	if collected.Cmp(amount) < 0 {
+		cli.Die(fmt.Sprintf("insufficient tokens: wallet owns %s, while %s is required",
+			formatTokenAmount(collected, asset.Definition.Decimals), formatTokenAmount(amount, asset.Definition.Decimals)))
+	}
+	tx.TokenOutputs = append(tx.TokenOutputs, tokens.TokenOutput{
+		AssetID:   assetID,
+		Value:     amount,
+		Condition: condition,
+	})
+
+	// fund the miner fee using the coins of this wallet
+	var refundCoinOutput *types.CoinOutput
+	tx.CoinInputs, refundCoinOutput, err = walletCmd.walletClient.FundCoins(
+		walletCmd.cli.Config.MinimumTransactionFee, refundAddress, walletCmd.sendTokensCfg.RefundAddressNew)
+	if err != nil {
+		cli.DieWithError("failed to fund the miner fee of the token transfer transaction", err)
+	}
+	if refundCoinOutput != nil {
+		tx.CoinOutputs = append(tx.CoinOutputs, *refundCoinOutput)
+	}
+
+	// refund the remaining tokens, to the same address the coins are refunded to if possible
+	if remainder := collected.Sub(amount); !remainder.IsZero() {
+		var refundCondition types.UnlockConditionProxy
+		switch {
+		case refundCoinOutput != nil:
+			refundCondition = refundCoinOutput.Condition
+		case refundAddress != nil:
+			refundCondition = types.NewCondition(types.NewUnlockHashCondition(*refundAddress))
+		default:
+			// refund to the owner of the first spent token output
+			refundCondition = firstCondition
+		}
+		tx.TokenOutputs = append(tx.TokenOutputs, tokens.TokenOutput{
+			AssetID:   assetID,
+			Value:     remainder,
+			Condition: refundCondition,
+		})
+	}
+
+	if n := len(walletCmd.sendTokensCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.sendTokensCfg.Description[:])
+	}
+
+	// sign the transaction
+	txn := tx.Transaction(walletCmd.transferTxVersion)
+	err = walletCmd.walletClient.GreedySignTx(&txn)
+	if err != nil {
+		cli.DieWithError("failed to sign token transfer transaction", err)
+	}
+
+	// send the transaction
+	txID, err := walletCmd.txPoolClient.AddTransactiom(txn)
+	if err != nil {
+		cli.DieWithError("failed to send token transfer transaction", err)
+	}
+
+	// print transaction ID
+	fmt.Println(txID.String())
+}
+
+func (walletCmd *walletCmd) listTokensCmd(*cobra.Command, []string) {
+	outputs, err := walletCmd.getWalletTokenOutputs()
+	if err != nil {
+		cli.DieWithError("failed to get the unspent token outputs of this wallet", err)
+	}
+	if len(outputs) == 0 {
+		fmt.Println("This wallet does not own any tokens.")
+		return
+	}
+
+	balances := make(map[tokens.AssetID]types.Currency)
+	for _, uto := range outputs {
+		balances[uto.Output.AssetID] = balances[uto.Output.AssetID].Add(uto.Output.Value)
+	}
+	assetIDs := make([]tokens.AssetID, 0, len(balances))
+	for assetID := range balances {
+		assetIDs = append(assetIDs, assetID)
+	}
+	sort.Slice(assetIDs, func(i, j int) bool {
+		return assetIDs[i].String() < assetIDs[j].String()
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ASSET\tSYMBOL\tBALANCE")
+	for _, assetID := range assetIDs {
+		asset, err := walletCmd.pluginClient.GetAsset(assetID)
+		if err != nil {
+			cli.DieWithError(fmt.Sprintf("failed to get asset %s", assetID.String()), err)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", assetID.String(), asset.Definition.Symbol,
+			formatTokenAmount(balances[assetID], asset.Definition.Decimals))
+	}
+	w.Flush()
+}
+
+// getWalletTokenOutputs returns all unspent token outputs owned by the addresses of this wallet.
+func (walletCmd *walletCmd) getWalletTokenOutputs() ([]tokens.UnspentTokenOutput, error) {
+	var addrs api.WalletAddressesGET
+	err := walletCmd.cli.GetWithResponse("/wallet/addresses", &addrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wallet addresses: %v", err)
+	}
+	var outputs []tokens.UnspentTokenOutput
+	for _, addr := range addrs.Addresses {
+		result, err := walletCmd.pluginClient.GetAddressTokens(addr)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, result.Outputs...)
+	}
+	return outputs, nil
+}
+
+// try to parse the string first as an unlock hash,
+// if that fails parse it as a JSON-encoded unlock condition
+func parseConditionString(str string) (condition types.UnlockConditionProxy, err error) {
+	// try to parse it as an unlock hash
+	var uh types.UnlockHash
+	err = uh.LoadString(str)
+	if err == nil {
+		condition = types.NewCondition(types.NewUnlockHashCondition(uh))
+		return
+	}
+
+	// try to parse it as a JSON-encoded unlock condition
+	err = condition.UnmarshalJSON([]byte(str))
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf(
+			"condition has to be UnlockHash or JSON-encoded UnlockCondition, output %q is neither", str)
+	}
+	return
+}
+
+// parseRefundAddress parses the optional user-defined refund address
+func parseRefundAddress(str string) (*types.UnlockHash, error) {
+	if str == "" {
+		return nil, nil
+	}
+	refundAddress := new(types.UnlockHash)
+	err := refundAddress.LoadString(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refund address: %v", err)
+	}
+	return refundAddress, nil
+}
+
+// parseTokenAmount parses a (decimal) token amount,
+// expressed in the unit of an asset with the given amount of decimals,
+// into its integer value expressed in the smallest unit of the asset.
+func parseTokenAmount(str string, decimals uint8) (types.Currency, error) {
+	parts := strings.SplitN(str, ".", 2)
+	integer, fraction := parts[0], ""
+	if len(parts) == 2 {
+		fraction = strings.TrimRight(parts[1], "0")
+	}
+	if len(fraction) > int(decimals) {
+		return types.Currency{}, fmt.Errorf("amount %q has too many decimals, asset only supports %d decimals", str, decimals)
+	}
+	fraction += strings.Repeat("0", int(decimals)-len(fraction))
+	value, ok := new(big.Int).SetString(integer+fraction, 10)
+	if !ok {
+		return types.Currency{}, fmt.Errorf("invalid token amount %q", str)
+	}
+	if value.Sign() <= 0 {
+		return types.Currency{}, errors.New("token amount has to be positive")
+	}
+	return types.NewCurrency(value), nil
+}
+
+// formatTokenAmount formats an integer token value
+// in the unit of an asset with the given amount of decimals.
+func formatTokenAmount(value types.Currency, decimals uint8) string {
+	str := value.String()
+	if decimals == 0 {
+		return str
+	}
+	if n := int(decimals) + 1 - len(str); n > 0 {
+		str = strings.Repeat("0", n) + str
+	}
+	integer, fraction := str[:len(str)-int(decimals)], strings.TrimRight(str[len(str)-int(decimals):], "0")
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
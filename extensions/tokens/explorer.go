@@ -0,0 +1,100 @@
+package tokens
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// TokenIssuanceExplorerView is the explorer view on the extension data of a TokenIssuanceTransaction.
+	TokenIssuanceExplorerView struct {
+		Nonce             types.TransactionNonce       `json:"nonce"`
+		AssetID           AssetID                      `json:"assetid"`
+		Definition        *AssetDefinition             `json:"definition,omitempty"`
+		IssuerFulfillment types.UnlockFulfillmentProxy `json:"issuerfulfillment"`
+		TokenOutputs      []TokenOutput                `json:"tokenoutputs"`
+		TokenOutputIDs    []TokenOutputID              `json:"tokenoutputids"`
+	}
+
+	// TokenTransferExplorerView is the explorer view on the extension data of a TokenTransferTransaction.
+	TokenTransferExplorerView struct {
+		TokenInputs    []TokenInput    `json:"tokeninputs"`
+		TokenOutputs   []TokenOutput   `json:"tokenoutputs"`
+		TokenOutputIDs []TokenOutputID `json:"tokenoutputids"`
+	}
+
+	tokenIssuanceExplorerExtension struct{}
+	tokenTransferExplorerExtension struct{}
+)
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (tokenIssuanceExplorerExtension) TransactionType() string { return "token issuance" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (tokenIssuanceExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	tiext, ok := txn.Extension.(*TokenIssuanceTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return TokenIssuanceExplorerView{
+		Nonce:             tiext.Nonce,
+		AssetID:           tiext.AssetID,
+		Definition:        tiext.Definition,
+		IssuerFulfillment: tiext.IssuerFulfillment,
+		TokenOutputs:      tiext.TokenOutputs,
+		TokenOutputIDs:    tokenOutputIDs(txn.ID(), len(tiext.TokenOutputs)),
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the issuer which signed it, if known.
+func (tokenIssuanceExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	tiext, ok := txn.Extension.(*TokenIssuanceTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	if uh, ok := modules.FulfillmentAddress(tiext.IssuerFulfillment); ok {
+		return []types.UnlockHash{uh}, nil
+	}
+	return nil, nil
+}
+
+// TransactionType implements modules.ExplorerTransactionExtension.TransactionType
+func (tokenTransferExplorerExtension) TransactionType() string { return "token transfer" }
+
+// TransactionView implements modules.ExplorerTransactionExtension.TransactionView
+func (tokenTransferExplorerExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	ttext, ok := txn.Extension.(*TokenTransferTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	return TokenTransferExplorerView{
+		TokenInputs:    ttext.TokenInputs,
+		TokenOutputs:   ttext.TokenOutputs,
+		TokenOutputIDs: tokenOutputIDs(txn.ID(), len(ttext.TokenOutputs)),
+	}, nil
+}
+
+// TransactionAddresses implements modules.ExplorerTransactionAddressesGetter.TransactionAddresses,
+// linking the transaction to the owners of the token outputs it spends, if known.
+func (tokenTransferExplorerExtension) TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	ttext, ok := txn.Extension.(*TokenTransferTransactionExtension)
+	if !ok {
+		return nil, types.ErrUnexpectedExtensionType
+	}
+	var uhs []types.UnlockHash
+	for _, ti := range ttext.TokenInputs {
+		if uh, ok := modules.FulfillmentAddress(ti.Fulfillment); ok {
+			uhs = append(uhs, uh)
+		}
+	}
+	return uhs, nil
+}
+
+func tokenOutputIDs(txid types.TransactionID, n int) []TokenOutputID {
+	ids := make([]TokenOutputID, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, NewTokenOutputID(txid, uint64(i)))
+	}
+	return ids
+}
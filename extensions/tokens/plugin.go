@@ -0,0 +1,778 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+const (
+	pluginDBVersion = "1.0.0.0"
+	pluginDBHeader  = "tokensPlugin"
+)
+
+var (
+	bucketAssets               = []byte("assets")
+	bucketTokenOutputs         = []byte("tokenoutputs")
+	bucketSpentTokenOutputs    = []byte("spenttokenoutputs")
+	bucketTokenOutputCreations = []byte("tokenoutputcreations")
+	bucketAddresses            = []byte("addresses")
+)
+
+type (
+	// Plugin is a struct defines the tokens plugin,
+	// tracking all assets and unspent token outputs.
+	Plugin struct {
+		issuanceTransactionVersion types.TransactionVersion
+		transferTransactionVersion types.TransactionVersion
+		storage                    modules.PluginViewStorage
+		unregisterCallback         modules.PluginUnregisterCallback
+	}
+
+	// UnspentTokenOutput pairs an unspent token output with its ID.
+	UnspentTokenOutput struct {
+		ID     TokenOutputID `json:"id"`
+		Output TokenOutput   `json:"output"`
+	}
+)
+
+// NewPlugin creates a new Plugin and registers the token transaction versions.
+func NewPlugin(issuanceTransactionVersion, transferTransactionVersion types.TransactionVersion) *Plugin {
+	p := &Plugin{
+		issuanceTransactionVersion: issuanceTransactionVersion,
+		transferTransactionVersion: transferTransactionVersion,
+	}
+	types.RegisterTransactionVersion(issuanceTransactionVersion, TokenIssuanceTransactionController{
+		AssetGetter:        p,
+		TransactionVersion: issuanceTransactionVersion,
+	})
+	types.RegisterTransactionVersion(transferTransactionVersion, TokenTransferTransactionController{
+		TokenOutputGetter:  p,
+		TransactionVersion: transferTransactionVersion,
+	})
+	modules.RegisterExplorerTransactionExtension(issuanceTransactionVersion, tokenIssuanceExplorerExtension{})
+	modules.RegisterExplorerTransactionExtension(transferTransactionVersion, tokenTransferExplorerExtension{})
+	return p
+}
+
+// InitPlugin initializes the Bucket for the first time
+func (p *Plugin) InitPlugin(metadata *persist.Metadata, bucket *bolt.Bucket, storage modules.PluginViewStorage, unregisterCallback modules.PluginUnregisterCallback) (persist.Metadata, error) {
+	p.storage = storage
+	p.unregisterCallback = unregisterCallback
+	if metadata == nil {
+		for _, name := range [][]byte{bucketAssets, bucketTokenOutputs, bucketSpentTokenOutputs, bucketTokenOutputCreations, bucketAddresses} {
+			_, err := bucket.CreateBucketIfNotExists(name)
+			if err != nil {
+				return persist.Metadata{}, fmt.Errorf("failed to create %s bucket: %v", string(name), err)
+			}
+		}
+		metadata = &persist.Metadata{
+			Version: pluginDBVersion,
+			Header:  pluginDBHeader,
+		}
+	} else if metadata.Version != pluginDBVersion {
+		return persist.Metadata{}, errors.New("There is only 1 version of this plugin, version mismatch")
+	} else if metadata.Header != pluginDBHeader {
+		return persist.Metadata{}, errors.New("There is only 1 header of this plugin, header mismatch")
+	}
+	return *metadata, nil
+}
+
+// ApplyBlock applies a block's token transactions to the token buckets.
+func (p *Plugin) ApplyBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("tokens bucket does not exist")
+	}
+	var err error
+	for idx, txn := range block.Transactions {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            txn,
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.ApplyTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyBlockHeader applies nothing and has no effect on this plugin.
+func (p *Plugin) ApplyBlockHeader(modules.ConsensusBlockHeader, *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// ApplyTransaction applies a token transaction to the token buckets.
+func (p *Plugin) ApplyTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("tokens bucket does not exist")
+	}
+	// check the version and handle the ones we care about
+	switch txn.Version {
+	case p.issuanceTransactionVersion:
+		titx, err := TokenIssuanceTransactionFromTransaction(txn.Transaction, p.issuanceTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the token issuance tx type: %v", err)
+		}
+		txID := txn.ID()
+		assetsBucket, err := bucket.Bucket(bucketAssets)
+		if err != nil {
+			return err
+		}
+		var asset Asset
+		if titx.Definition != nil {
+			asset = Asset{
+				ID:                    titx.AssetID,
+				Definition:            *titx.Definition,
+				CreationHeight:        txn.BlockHeight,
+				CreationTime:          txn.BlockTime,
+				CreationTransactionID: txID,
+			}
+		} else {
+			asset, err = getAssetFromBucket(assetsBucket, titx.AssetID)
+			if err != nil {
+				return err
+			}
+		}
+		asset.Supply = asset.Supply.Add(tokenOutputSum(titx.TokenOutputs))
+		err = putAssetInBucket(assetsBucket, asset)
+		if err != nil {
+			return err
+		}
+		return p.applyTokenOutputs(bucket, txn, titx.TokenOutputs)
+
+	case p.transferTransactionVersion:
+		tttx, err := TokenTransferTransactionFromTransaction(txn.Transaction, p.transferTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the token transfer tx type: %v", err)
+		}
+		outputsBucket, err := bucket.Bucket(bucketTokenOutputs)
+		if err != nil {
+			return err
+		}
+		spentOutputsBucket, err := bucket.Bucket(bucketSpentTokenOutputs)
+		if err != nil {
+			return err
+		}
+		addressesBucket, err := bucket.Bucket(bucketAddresses)
+		if err != nil {
+			return err
+		}
+		// mark all spent token outputs as spent,
+		// keeping them around such that they can be restored when reverting
+		for _, ti := range tttx.TokenInputs {
+			to, err := getTokenOutputFromBucket(outputsBucket, ti.ParentID)
+			if err != nil {
+				return fmt.Errorf("failed to spend token output %s: %v", ti.ParentID.String(), err)
+			}
+			err = outputsBucket.Delete(ti.ParentID[:])
+			if err != nil {
+				return fmt.Errorf("failed to delete spent token output %s: %v", ti.ParentID.String(), err)
+			}
+			err = putTokenOutputInBucket(spentOutputsBucket, ti.ParentID, to)
+			if err != nil {
+				return err
+			}
+			err = unmapTokenOutputAddress(addressesBucket, ti.ParentID, to)
+			if err != nil {
+				return err
+			}
+		}
+		return p.applyTokenOutputs(bucket, txn, tttx.TokenOutputs)
+	}
+	return nil
+}
+
+func (p *Plugin) applyTokenOutputs(bucket *persist.LazyBoltBucket, txn modules.ConsensusTransaction, outputs []TokenOutput) error {
+	outputsBucket, err := bucket.Bucket(bucketTokenOutputs)
+	if err != nil {
+		return err
+	}
+	creationsBucket, err := bucket.Bucket(bucketTokenOutputCreations)
+	if err != nil {
+		return err
+	}
+	addressesBucket, err := bucket.Bucket(bucketAddresses)
+	if err != nil {
+		return err
+	}
+	txID := txn.ID()
+	creation := modules.OutputCreation{
+		BlockHeight: txn.BlockHeight,
+		BlockTime:   txn.BlockTime,
+	}
+	for idx, to := range outputs {
+		id := NewTokenOutputID(txID, uint64(idx))
+		err = putTokenOutputInBucket(outputsBucket, id, to)
+		if err != nil {
+			return err
+		}
+		// the creation is kept when the output is spent,
+		// as it is still required should the output be restored
+		err = putTokenOutputCreationInBucket(creationsBucket, id, creation)
+		if err != nil {
+			return err
+		}
+		err = mapTokenOutputAddress(addressesBucket, id, to)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlock reverts a block's token transactions from the token buckets
+func (p *Plugin) RevertBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("tokens bucket does not exist")
+	}
+	// revert the transactions in reverse order,
+	// as a transaction can spend the token outputs of a previous transaction in the same block
+	var err error
+	for idx := len(block.Transactions) - 1; idx >= 0; idx-- {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            block.Transactions[idx],
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.RevertTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlockHeader reverts nothing and has no effect on this plugin.
+func (p *Plugin) RevertBlockHeader(modules.ConsensusBlockHeader, *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// RevertTransaction reverts a token transaction from the token buckets.
+func (p *Plugin) RevertTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("tokens bucket does not exist")
+	}
+	// check the version and handle the ones we care about
+	switch txn.Version {
+	case p.issuanceTransactionVersion:
+		titx, err := TokenIssuanceTransactionFromTransaction(txn.Transaction, p.issuanceTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the token issuance tx type: %v", err)
+		}
+		err = p.revertTokenOutputs(bucket, txn.ID(), titx.TokenOutputs)
+		if err != nil {
+			return err
+		}
+		assetsBucket, err := bucket.Bucket(bucketAssets)
+		if err != nil {
+			return err
+		}
+		if titx.Definition != nil {
+			err = assetsBucket.Delete(titx.AssetID[:])
+			if err != nil {
+				return fmt.Errorf("failed to delete asset %s: %v", titx.AssetID.String(), err)
+			}
+			return nil
+		}
+		asset, err := getAssetFromBucket(assetsBucket, titx.AssetID)
+		if err != nil {
+			return err
+		}
+		asset.Supply = asset.Supply.Sub(tokenOutputSum(titx.TokenOutputs))
+		return putAssetInBucket(assetsBucket, asset)
+
+	case p.transferTransactionVersion:
+		tttx, err := TokenTransferTransactionFromTransaction(txn.Transaction, p.transferTransactionVersion)
+		if err != nil {
+			return fmt.Errorf("unexpected error while unpacking the token transfer tx type: %v", err)
+		}
+		err = p.revertTokenOutputs(bucket, txn.ID(), tttx.TokenOutputs)
+		if err != nil {
+			return err
+		}
+		outputsBucket, err := bucket.Bucket(bucketTokenOutputs)
+		if err != nil {
+			return err
+		}
+		spentOutputsBucket, err := bucket.Bucket(bucketSpentTokenOutputs)
+		if err != nil {
+			return err
+		}
+		addressesBucket, err := bucket.Bucket(bucketAddresses)
+		if err != nil {
+			return err
+		}
+		// restore all spent token outputs as unspent token outputs
+		for _, ti := range tttx.TokenInputs {
+			to, err := getTokenOutputFromBucket(spentOutputsBucket, ti.ParentID)
+			if err != nil {
+				return fmt.Errorf("failed to restore spent token output %s: %v", ti.ParentID.String(), err)
+			}
+			err = spentOutputsBucket.Delete(ti.ParentID[:])
+			if err != nil {
+				return fmt.Errorf("failed to delete spent token output %s: %v", ti.ParentID.String(), err)
+			}
+			err = putTokenOutputInBucket(outputsBucket, ti.ParentID, to)
+			if err != nil {
+				return err
+			}
+			err = mapTokenOutputAddress(addressesBucket, ti.ParentID, to)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) revertTokenOutputs(bucket *persist.LazyBoltBucket, txID types.TransactionID, outputs []TokenOutput) error {
+	outputsBucket, err := bucket.Bucket(bucketTokenOutputs)
+	if err != nil {
+		return err
+	}
+	creationsBucket, err := bucket.Bucket(bucketTokenOutputCreations)
+	if err != nil {
+		return err
+	}
+	addressesBucket, err := bucket.Bucket(bucketAddresses)
+	if err != nil {
+		return err
+	}
+	for idx, to := range outputs {
+		id := NewTokenOutputID(txID, uint64(idx))
+		err = outputsBucket.Delete(id[:])
+		if err != nil {
+			return fmt.Errorf("failed to delete token output %s: %v", id.String(), err)
+		}
+		err = creationsBucket.Delete(id[:])
+		if err != nil {
+			return fmt.Errorf("failed to delete creation of token output %s: %v", id.String(), err)
+		}
+		err = unmapTokenOutputAddress(addressesBucket, id, to)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAsset implements AssetGetter.GetAsset
+func (p *Plugin) GetAsset(id AssetID) (Asset, error) {
+	var asset Asset
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		assetsBucket := bucket.Bucket(bucketAssets)
+		if assetsBucket == nil {
+			return errors.New("assets bucket does not exist")
+		}
+		var err error
+		asset, err = getAssetFromBucket(assetsBucket, id)
+		return err
+	})
+	return asset, err
+}
+
+// GetAssets returns all assets known to this plugin.
+func (p *Plugin) GetAssets() ([]Asset, error) {
+	var assets []Asset
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		assetsBucket := bucket.Bucket(bucketAssets)
+		if assetsBucket == nil {
+			return errors.New("assets bucket does not exist")
+		}
+		return assetsBucket.ForEach(func(_, v []byte) error {
+			var asset Asset
+			err := rivbin.Unmarshal(v, &asset)
+			if err != nil {
+				return fmt.Errorf("failed to decode asset: %v", err)
+			}
+			assets = append(assets, asset)
+			return nil
+		})
+	})
+	return assets, err
+}
+
+// GetTokenOutput implements TokenOutputGetter.GetTokenOutput
+func (p *Plugin) GetTokenOutput(id TokenOutputID) (TokenOutput, error) {
+	var to TokenOutput
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		outputsBucket := bucket.Bucket(bucketTokenOutputs)
+		if outputsBucket == nil {
+			return errors.New("token outputs bucket does not exist")
+		}
+		var err error
+		to, err = getTokenOutputFromBucket(outputsBucket, id)
+		return err
+	})
+	return to, err
+}
+
+// GetUnspentTokenOutputs returns all unspent token outputs
+// which can be spent by the given unlock hash.
+func (p *Plugin) GetUnspentTokenOutputs(uh types.UnlockHash) ([]UnspentTokenOutput, error) {
+	var outputs []UnspentTokenOutput
+	err := p.storage.View(func(bucket *bolt.Bucket) error {
+		outputsBucket := bucket.Bucket(bucketTokenOutputs)
+		if outputsBucket == nil {
+			return errors.New("token outputs bucket does not exist")
+		}
+		addressesBucket := bucket.Bucket(bucketAddresses)
+		if addressesBucket == nil {
+			return errors.New("addresses bucket does not exist")
+		}
+		key, err := rivbin.Marshal(uh)
+		if err != nil {
+			return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+		}
+		addressBucket := addressesBucket.Bucket(key)
+		if addressBucket == nil {
+			return nil // no token outputs for this address
+		}
+		return addressBucket.ForEach(func(k, _ []byte) error {
+			var id TokenOutputID
+			copy(id[:], k)
+			to, err := getTokenOutputFromBucket(outputsBucket, id)
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, UnspentTokenOutput{
+				ID:     id,
+				Output: to,
+			})
+			return nil
+		})
+	})
+	return outputs, err
+}
+
+// TransactionValidatorVersionFunctionMapping returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidatorVersionFunctionMapping() map[types.TransactionVersion][]modules.PluginTransactionValidationFunction {
+	return map[types.TransactionVersion][]modules.PluginTransactionValidationFunction{
+		p.issuanceTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateTokenIssuanceTx,
+		},
+		p.transferTransactionVersion: []modules.PluginTransactionValidationFunction{
+			p.validateTokenTransferTx,
+		},
+	}
+}
+
+// TransactionValidators returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidators() []modules.PluginTransactionValidationFunction {
+	return nil
+}
+
+func (p *Plugin) validateTokenIssuanceTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	titx, err := TokenIssuanceTransactionFromTransaction(tx.Transaction, p.issuanceTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a token issuance tx: %v", err)
+	}
+
+	// ensure the Nonce is not Nil
+	if titx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for a token issuance transaction")
+	}
+
+	// all token outputs have to be valid and of the issued asset
+	for idx, to := range titx.TokenOutputs {
+		if to.AssetID != titx.AssetID {
+			return fmt.Errorf("token output #%d is of asset %s, while only asset %s is issued", idx, to.AssetID.String(), titx.AssetID.String())
+		}
+		err = validateTokenOutput(to, ctx.ValidationContext)
+		if err != nil {
+			return fmt.Errorf("invalid token output #%d: %v", idx, err)
+		}
+	}
+
+	// get the issuer condition, either from the new asset definition or from the existing asset
+	assetsBucket, err := bucket.Bucket(bucketAssets)
+	if err != nil {
+		return err
+	}
+	var (
+		issuerCondition types.UnlockConditionProxy
+		issuerCreation  modules.OutputCreation
+	)
+	if titx.Definition != nil {
+		err = titx.Definition.Validate(ctx.ValidationContext)
+		if err != nil {
+			return fmt.Errorf("invalid asset definition: %v", err)
+		}
+		if expectedID := NewAssetID(titx.Nonce, *titx.Definition); titx.AssetID != expectedID {
+			return fmt.Errorf("invalid asset ID %s for new asset, expected %s", titx.AssetID.String(), expectedID.String())
+		}
+		if assetsBucket.Get(titx.AssetID[:]) != nil {
+			return fmt.Errorf("asset %s already exists", titx.AssetID.String())
+		}
+		// the issuer condition of a new asset is created by this transaction
+		issuerCondition = titx.Definition.IssuerCondition
+		issuerCreation = modules.OutputCreation{
+			BlockHeight: ctx.BlockHeight,
+			BlockTime:   ctx.BlockTime,
+		}
+	} else {
+		asset, err := getAssetFromBucket(assetsBucket, titx.AssetID)
+		if err != nil {
+			return fmt.Errorf("cannot issue tokens of asset %s: %v", titx.AssetID.String(), err)
+		}
+		issuerCondition = asset.Definition.IssuerCondition
+		issuerCreation = modules.OutputCreation{
+			BlockHeight: asset.CreationHeight,
+			BlockTime:   asset.CreationTime,
+		}
+	}
+
+	// check if IssuerFulfillment fulfills the issuer condition of the asset
+	err = issuerCondition.Fulfill(titx.IssuerFulfillment, types.FulfillContext{
+		BlockHeight:          ctx.BlockHeight,
+		BlockTime:            ctx.BlockTime,
+		OutputCreationHeight: issuerCreation.BlockHeight,
+		OutputCreationTime:   issuerCreation.BlockTime,
+		HasOutputCreation:    true,
+		Transaction:          tx.Transaction,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill issuer condition for token issuance transaction: %v", err)
+	}
+
+	return validateCoinOutputsAreBalanced(tx, ctx)
+}
+
+func (p *Plugin) validateTokenTransferTx(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	tttx, err := TokenTransferTransactionFromTransaction(tx.Transaction, p.transferTransactionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a token transfer tx: %v", err)
+	}
+
+	outputsBucket, err := bucket.Bucket(bucketTokenOutputs)
+	if err != nil {
+		return err
+	}
+	creationsBucket, err := bucket.Bucket(bucketTokenOutputCreations)
+	if err != nil {
+		return err
+	}
+
+	// all token inputs have to spend a unique unspent token output and fulfill its condition
+	inputSums := make(map[AssetID]types.Currency)
+	spent := make(map[TokenOutputID]struct{}, len(tttx.TokenInputs))
+	for idx, ti := range tttx.TokenInputs {
+		if _, ok := spent[ti.ParentID]; ok {
+			return fmt.Errorf("token output %s is spent multiple times", ti.ParentID.String())
+		}
+		spent[ti.ParentID] = struct{}{}
+		to, err := getTokenOutputFromBucket(outputsBucket, ti.ParentID)
+		if err != nil {
+			return fmt.Errorf("token input #%d: %v", idx, err)
+		}
+		creation, hasCreation, err := getTokenOutputCreationFromBucket(creationsBucket, ti.ParentID)
+		if err != nil {
+			return fmt.Errorf("token input #%d: %v", idx, err)
+		}
+		err = to.Condition.Fulfill(ti.Fulfillment, types.FulfillContext{
+			ExtraObjects:         []interface{}{SpecifierTokenInput, uint64(idx)},
+			BlockHeight:          ctx.BlockHeight,
+			BlockTime:            ctx.BlockTime,
+			OutputCreationHeight: creation.BlockHeight,
+			OutputCreationTime:   creation.BlockTime,
+			HasOutputCreation:    hasCreation,
+			Transaction:          tx.Transaction,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fulfill token input #%d: %v", idx, err)
+		}
+		inputSums[to.AssetID] = inputSums[to.AssetID].Add(to.Value)
+	}
+
+	// all token outputs have to be valid, and balanced per asset
+	outputSums := make(map[AssetID]types.Currency)
+	for idx, to := range tttx.TokenOutputs {
+		err = validateTokenOutput(to, ctx.ValidationContext)
+		if err != nil {
+			return fmt.Errorf("invalid token output #%d: %v", idx, err)
+		}
+		outputSums[to.AssetID] = outputSums[to.AssetID].Add(to.Value)
+	}
+	for assetID, inputSum := range inputSums {
+		if !inputSum.Equals(outputSums[assetID]) {
+			return fmt.Errorf(
+				"token inputs (%s) and outputs (%s) of asset %s are not balanced",
+				inputSum.String(), outputSums[assetID].String(), assetID.String())
+		}
+	}
+	for assetID := range outputSums {
+		if _, ok := inputSums[assetID]; !ok {
+			return fmt.Errorf("token outputs of asset %s are defined without any token inputs of that asset", assetID.String())
+		}
+	}
+
+	return validateCoinOutputsAreBalanced(tx, ctx)
+}
+
+// validateTokenOutput ensures a token output has a value and a standard condition.
+func validateTokenOutput(to TokenOutput, ctx types.ValidationContext) error {
+	if to.Value.IsZero() {
+		return errors.New("token output value cannot be zero")
+	}
+	return to.Condition.IsStandardCondition(ctx)
+}
+
+// validateCoinOutputsAreBalanced ensures that the coin inputs
+// fund exactly the coin outputs and miner fees of the transaction.
+func validateCoinOutputsAreBalanced(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+	var coinInputSum types.Currency
+	for _, ci := range tx.CoinInputs {
+		co, ok := tx.SpentCoinOutputs[ci.ParentID]
+		if !ok {
+			return fmt.Errorf(
+				"unable to find parent ID %s as an unspent coin output in the current consensus transaction at block height %d",
+				ci.ParentID.String(), ctx.BlockHeight)
+		}
+		coinInputSum = coinInputSum.Add(co.Value)
+	}
+	if coinOutputSum := tx.CoinOutputSum(); !coinInputSum.Equals(coinOutputSum) {
+		return fmt.Errorf(
+			"coin inputs (%s) and coin outputs including miner fees (%s) are not balanced for tx %s",
+			coinInputSum.String(), coinOutputSum.String(), tx.ID().String())
+	}
+	return nil
+}
+
+// Close unregisters the plugin from the consensus
+func (p *Plugin) Close() error {
+	if p.storage == nil {
+		return nil
+	}
+	return p.storage.Close()
+}
+
+func tokenOutputSum(outputs []TokenOutput) (sum types.Currency) {
+	for _, to := range outputs {
+		sum = sum.Add(to.Value)
+	}
+	return
+}
+
+func getAssetFromBucket(bucket *bolt.Bucket, id AssetID) (Asset, error) {
+	b := bucket.Get(id[:])
+	if len(b) == 0 {
+		return Asset{}, ErrAssetNotFound
+	}
+	var asset Asset
+	err := rivbin.Unmarshal(b, &asset)
+	if err != nil {
+		return Asset{}, fmt.Errorf("failed to decode asset %s: %v", id.String(), err)
+	}
+	return asset, nil
+}
+
+func putAssetInBucket(bucket *bolt.Bucket, asset Asset) error {
+	b, err := rivbin.Marshal(asset)
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal asset %s: %v", asset.ID.String(), err)
+	}
+	err = bucket.Put(asset.ID[:], b)
+	if err != nil {
+		return fmt.Errorf("failed to put asset %s: %v", asset.ID.String(), err)
+	}
+	return nil
+}
+
+func getTokenOutputFromBucket(bucket *bolt.Bucket, id TokenOutputID) (TokenOutput, error) {
+	b := bucket.Get(id[:])
+	if len(b) == 0 {
+		return TokenOutput{}, ErrTokenOutputNotFound
+	}
+	var to TokenOutput
+	err := rivbin.Unmarshal(b, &to)
+	if err != nil {
+		return TokenOutput{}, fmt.Errorf("failed to decode token output %s: %v", id.String(), err)
+	}
+	return to, nil
+}
+
+func putTokenOutputInBucket(bucket *bolt.Bucket, id TokenOutputID, to TokenOutput) error {
+	b, err := rivbin.Marshal(to)
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal token output %s: %v", id.String(), err)
+	}
+	err = bucket.Put(id[:], b)
+	if err != nil {
+		return fmt.Errorf("failed to put token output %s: %v", id.String(), err)
+	}
+	return nil
+}
+
+// getTokenOutputCreationFromBucket returns the block which created the given token output,
+// returning false in case its creation is unknown.
+func getTokenOutputCreationFromBucket(bucket *bolt.Bucket, id TokenOutputID) (modules.OutputCreation, bool, error) {
+	b := bucket.Get(id[:])
+	if len(b) == 0 {
+		return modules.OutputCreation{}, false, nil
+	}
+	var creation modules.OutputCreation
+	err := rivbin.Unmarshal(b, &creation)
+	if err != nil {
+		return modules.OutputCreation{}, false, fmt.Errorf("failed to decode creation of token output %s: %v", id.String(), err)
+	}
+	return creation, true, nil
+}
+
+func putTokenOutputCreationInBucket(bucket *bolt.Bucket, id TokenOutputID, creation modules.OutputCreation) error {
+	b, err := rivbin.Marshal(creation)
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal creation of token output %s: %v", id.String(), err)
+	}
+	err = bucket.Put(id[:], b)
+	if err != nil {
+		return fmt.Errorf("failed to put creation of token output %s: %v", id.String(), err)
+	}
+	return nil
+}
+
+// mapTokenOutputAddress links the token output to the unlock hash of its condition.
+func mapTokenOutputAddress(addressesBucket *bolt.Bucket, id TokenOutputID, to TokenOutput) error {
+	key, err := rivbin.Marshal(to.Condition.UnlockHash())
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+	}
+	addressBucket, err := addressesBucket.CreateBucketIfNotExists(key)
+	if err != nil {
+		return fmt.Errorf("failed to create address bucket: %v", err)
+	}
+	return addressBucket.Put(id[:], []byte{})
+}
+
+// unmapTokenOutputAddress removes the link between the token output and the unlock hash of its condition.
+func unmapTokenOutputAddress(addressesBucket *bolt.Bucket, id TokenOutputID, to TokenOutput) error {
+	key, err := rivbin.Marshal(to.Condition.UnlockHash())
+	if err != nil {
+		return fmt.Errorf("failed to (rivbin) marshal unlock hash: %v", err)
+	}
+	addressBucket := addressesBucket.Bucket(key)
+	if addressBucket == nil {
+		return nil
+	}
+	err = addressBucket.Delete(id[:])
+	if err != nil {
+		return err
+	}
+	if k, _ := addressBucket.Cursor().First(); k == nil {
+		return addressesBucket.DeleteBucket(key)
+	}
+	return nil
+}
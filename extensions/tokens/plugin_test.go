@@ -0,0 +1,294 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/extensions/internal/plugintest"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// newTestPlugin creates a tokens plugin, using the test transaction versions,
+// initialised in a test database. The returned function closes the plugin,
+// and unregisters its transaction versions.
+func newTestPlugin(t *testing.T) (*Plugin, *plugintest.Storage, func()) {
+	plugin := NewPlugin(testIssuanceTxVersion, testTransferTxVersion)
+	storage := plugintest.NewStorage(t, plugin)
+	return plugin, storage, func() {
+		plugin.Close()
+		types.RegisterTransactionVersion(testIssuanceTxVersion, nil)
+		types.RegisterTransactionVersion(testTransferTxVersion, nil)
+		modules.RegisterExplorerTransactionExtension(testIssuanceTxVersion, nil)
+		modules.RegisterExplorerTransactionExtension(testTransferTxVersion, nil)
+	}
+}
+
+// testConsensusTx funds the miner fee of the given token transaction using a single coin input,
+// signs it using the given key pairs (if any), and returns it as confirmed at the given height.
+func testConsensusTx(t *testing.T, txn types.Transaction, height types.BlockHeight, signers ...plugintest.KeyPair) modules.ConsensusTransaction {
+	parentID := types.CoinOutputID{byte(height)}
+	fee := types.NewCurrency64(1)
+	txn.CoinInputs = []types.CoinInput{{ParentID: parentID}}
+	txn.MinerFees = []types.Currency{fee}
+	if len(signers) != 0 {
+		plugintest.SignExtension(t, &txn, signers...)
+	}
+	return modules.ConsensusTransaction{
+		Transaction: txn,
+		BlockHeight: height,
+		BlockTime:   types.Timestamp(height) * 600,
+		SpentCoinOutputs: map[types.CoinOutputID]types.CoinOutput{
+			parentID: {Value: fee},
+		},
+	}
+}
+
+func testIssuanceTx(t *testing.T, titx TokenIssuanceTransaction, height types.BlockHeight, signers ...plugintest.KeyPair) modules.ConsensusTransaction {
+	return testConsensusTx(t, titx.Transaction(testIssuanceTxVersion), height, signers...)
+}
+
+func testTransferTx(t *testing.T, tttx TokenTransferTransaction, height types.BlockHeight, signers ...plugintest.KeyPair) modules.ConsensusTransaction {
+	return testConsensusTx(t, tttx.Transaction(testTransferTxVersion), height, signers...)
+}
+
+func TestPluginTokenIssuance(t *testing.T) {
+	plugin, storage, closePlugin := newTestPlugin(t)
+	defer closePlugin()
+
+	issuer, holder := plugintest.NewKeyPair(), plugintest.NewKeyPair()
+	definition := AssetDefinition{
+		Symbol:          "TKN",
+		Name:            "Test Token",
+		IssuerCondition: issuer.Condition(),
+	}
+	nonce := types.TransactionNonce{1, 2, 3, 4, 5, 6, 7, 8}
+	assetID := NewAssetID(nonce, definition)
+	newIssuance := func(id AssetID, def *AssetDefinition, value uint64) TokenIssuanceTransaction {
+		return TokenIssuanceTransaction{
+			Nonce:      nonce,
+			AssetID:    id,
+			Definition: def,
+			TokenOutputs: []TokenOutput{
+				{AssetID: id, Value: types.NewCurrency64(value), Condition: holder.Condition()},
+			},
+		}
+	}
+
+	// the asset ID has to be derived from the nonce and definition
+	err := storage.ValidateTransaction(testIssuanceTx(t, newIssuance(AssetID{1}, &definition, 100), 1, issuer))
+	if err == nil {
+		t.Error("expected a new asset with an invalid asset ID to be rejected")
+	}
+	// only the issuer condition of the new asset authorises the issuance
+	err = storage.ValidateTransaction(testIssuanceTx(t, newIssuance(assetID, &definition, 100), 1, holder))
+	if err == nil {
+		t.Error("expected a new asset issued by the wrong party to be rejected")
+	}
+	// tokens of an unknown asset cannot be issued (nor signed, as its issuer condition is unknown)
+	unknownAsset := newIssuance(assetID, nil, 100)
+	unknownAsset.IssuerFulfillment = plugintest.FakeFulfillment()
+	err = storage.ValidateTransaction(testIssuanceTx(t, unknownAsset, 1))
+	if err == nil {
+		t.Error("expected the issuance of an unknown asset to be rejected")
+	}
+	// all issued outputs have to be of the issued asset
+	invalidOutputs := newIssuance(assetID, &definition, 100)
+	invalidOutputs.TokenOutputs[0].AssetID = AssetID{1}
+	err = storage.ValidateTransaction(testIssuanceTx(t, invalidOutputs, 1, issuer))
+	if err == nil {
+		t.Error("expected the issuance of outputs of another asset to be rejected")
+	}
+	// the issuance has to fund its miner fees
+	unbalanced := testIssuanceTx(t, newIssuance(assetID, &definition, 100), 1, issuer)
+	unbalanced.SpentCoinOutputs[unbalanced.CoinInputs[0].ParentID] = types.CoinOutput{Value: types.NewCurrency64(2)}
+	err = storage.ValidateTransaction(unbalanced)
+	if err == nil {
+		t.Error("expected an issuance with unbalanced coin outputs to be rejected")
+	}
+
+	creation := testIssuanceTx(t, newIssuance(assetID, &definition, 100), 1, issuer)
+	err = storage.ValidateTransaction(creation)
+	if err != nil {
+		t.Fatalf("failed to validate the creation of a new asset: %v", err)
+	}
+	err = storage.ApplyTransaction(creation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAssetSupply(t, plugin, assetID, 100)
+
+	// the asset cannot be created twice
+	err = storage.ValidateTransaction(testIssuanceTx(t, newIssuance(assetID, &definition, 100), 2, issuer))
+	if err == nil {
+		t.Error("expected the creation of an existing asset to be rejected")
+	}
+	// additional tokens can only be issued by the issuer of the existing asset
+	err = storage.ValidateTransaction(testIssuanceTx(t, newIssuance(assetID, nil, 50), 2, holder))
+	if err == nil {
+		t.Error("expected the issuance of an existing asset by the wrong party to be rejected")
+	}
+	issuance := testIssuanceTx(t, newIssuance(assetID, nil, 50), 2, issuer)
+	err = storage.ValidateTransaction(issuance)
+	if err != nil {
+		t.Fatalf("failed to validate the issuance of an existing asset: %v", err)
+	}
+	err = storage.ApplyTransaction(issuance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAssetSupply(t, plugin, assetID, 150)
+	checkUnspentTokenOutputs(t, plugin, holder.UnlockHash(), 2)
+
+	// reverting an issuance subtracts its supply, reverting the creation deletes the asset
+	err = storage.RevertTransaction(issuance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAssetSupply(t, plugin, assetID, 100)
+	checkUnspentTokenOutputs(t, plugin, holder.UnlockHash(), 1)
+	err = storage.RevertTransaction(creation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = plugin.GetAsset(assetID); err != ErrAssetNotFound {
+		t.Errorf("expected the reverted asset not to be found, got: %v", err)
+	}
+	checkUnspentTokenOutputs(t, plugin, holder.UnlockHash(), 0)
+}
+
+func TestPluginTokenTransfer(t *testing.T) {
+	plugin, storage, closePlugin := newTestPlugin(t)
+	defer closePlugin()
+
+	issuer, alice, bob := plugintest.NewKeyPair(), plugintest.NewKeyPair(), plugintest.NewKeyPair()
+	definition := AssetDefinition{Symbol: "TKN", IssuerCondition: issuer.Condition()}
+	nonce := types.TransactionNonce{1}
+	assetID := NewAssetID(nonce, definition)
+	creation := testIssuanceTx(t, TokenIssuanceTransaction{
+		Nonce:      nonce,
+		AssetID:    assetID,
+		Definition: &definition,
+		TokenOutputs: []TokenOutput{
+			{AssetID: assetID, Value: types.NewCurrency64(60), Condition: alice.Condition()},
+			{AssetID: assetID, Value: types.NewCurrency64(40), Condition: alice.Condition()},
+		},
+	}, 1, issuer)
+	err := storage.ApplyTransaction(creation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputIDs := []TokenOutputID{
+		NewTokenOutputID(creation.ID(), 0),
+		NewTokenOutputID(creation.ID(), 1),
+	}
+	newTransfer := func(values ...uint64) TokenTransferTransaction {
+		tttx := TokenTransferTransaction{
+			TokenInputs: []TokenInput{{ParentID: outputIDs[0]}, {ParentID: outputIDs[1]}},
+		}
+		for _, value := range values {
+			tttx.TokenOutputs = append(tttx.TokenOutputs, TokenOutput{
+				AssetID:   assetID,
+				Value:     types.NewCurrency64(value),
+				Condition: bob.Condition(),
+			})
+		}
+		return tttx
+	}
+
+	// token inputs and outputs have to be balanced
+	for _, values := range [][]uint64{{99}, {60, 41}, {100, 1}} {
+		err = storage.ValidateTransaction(testTransferTx(t, newTransfer(values...), 2, alice))
+		if err == nil {
+			t.Errorf("expected the unbalanced transfer of %v tokens to be rejected", values)
+		}
+	}
+	// outputs cannot be created for an asset without inputs of that asset
+	otherAsset := newTransfer(60, 40)
+	otherAsset.TokenOutputs = append(otherAsset.TokenOutputs, TokenOutput{
+		AssetID:   AssetID{1},
+		Value:     types.NewCurrency64(1),
+		Condition: bob.Condition(),
+	})
+	err = storage.ValidateTransaction(testTransferTx(t, otherAsset, 2, alice))
+	if err == nil {
+		t.Error("expected the transfer of tokens of an asset without inputs to be rejected")
+	}
+	// token outputs can only be spent by their owner, and only once
+	err = storage.ValidateTransaction(testTransferTx(t, newTransfer(60, 40), 2, bob))
+	if err == nil {
+		t.Error("expected a transfer signed by the wrong party to be rejected")
+	}
+	doubleSpend := newTransfer(60, 60)
+	doubleSpend.TokenInputs[1].ParentID = outputIDs[0]
+	err = storage.ValidateTransaction(testTransferTx(t, doubleSpend, 2, alice))
+	if err == nil {
+		t.Error("expected a transfer spending the same token output twice to be rejected")
+	}
+
+	transfer := testTransferTx(t, newTransfer(70, 30), 2, alice)
+	err = storage.ValidateTransaction(transfer)
+	if err != nil {
+		t.Fatalf("failed to validate a balanced transfer: %v", err)
+	}
+	err = storage.ApplyTransaction(transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUnspentTokenOutputs(t, plugin, alice.UnlockHash(), 0)
+	checkUnspentTokenOutputs(t, plugin, bob.UnlockHash(), 2)
+	if _, err = plugin.GetTokenOutput(outputIDs[0]); err != ErrTokenOutputNotFound {
+		t.Errorf("expected the spent token output not to be found, got: %v", err)
+	}
+	// spent token outputs cannot be spent again (nor signed, as they are no longer found)
+	spent := newTransfer(60, 40)
+	for idx := range spent.TokenInputs {
+		spent.TokenInputs[idx].Fulfillment = plugintest.FakeFulfillment()
+	}
+	err = storage.ValidateTransaction(testTransferTx(t, spent, 3))
+	if err == nil {
+		t.Error("expected a transfer of spent token outputs to be rejected")
+	}
+
+	// reverting the transfer restores the spent token outputs
+	err = storage.RevertTransaction(transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUnspentTokenOutputs(t, plugin, alice.UnlockHash(), 2)
+	checkUnspentTokenOutputs(t, plugin, bob.UnlockHash(), 0)
+	for _, id := range outputIDs {
+		to, err := plugin.GetTokenOutput(id)
+		if err != nil {
+			t.Errorf("failed to get restored token output %s: %v", id.String(), err)
+		} else if to.Condition.UnlockHash() != alice.UnlockHash() {
+			t.Errorf("unexpected owner of restored token output %s: %s", id.String(), to.Condition.UnlockHash().String())
+		}
+	}
+	checkAssetSupply(t, plugin, assetID, 100)
+	err = storage.ValidateTransaction(testTransferTx(t, newTransfer(60, 40), 3, alice))
+	if err != nil {
+		t.Errorf("failed to validate the transfer of restored token outputs: %v", err)
+	}
+}
+
+func checkAssetSupply(t *testing.T, plugin *Plugin, id AssetID, supply uint64) {
+	t.Helper()
+	asset, err := plugin.GetAsset(id)
+	if err != nil {
+		t.Fatalf("failed to get asset %s: %v", id.String(), err)
+	}
+	if !asset.Supply.Equals64(supply) {
+		t.Errorf("unexpected supply of asset %s: %s != %d", id.String(), asset.Supply.String(), supply)
+	}
+}
+
+func checkUnspentTokenOutputs(t *testing.T, plugin *Plugin, uh types.UnlockHash, count int) {
+	t.Helper()
+	outputs, err := plugin.GetUnspentTokenOutputs(uh)
+	if err != nil {
+		t.Fatalf("failed to get unspent token outputs of %s: %v", uh.String(), err)
+	}
+	if len(outputs) != count {
+		t.Errorf("unexpected amount of unspent token outputs of %s: %d != %d", uh.String(), len(outputs), count)
+	}
+}
@@ -0,0 +1,590 @@
+package tokens
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// TokenIssuanceTransactionController defines a rivine-specific transaction controller,
+	// for a TokenIssuance Transaction. It allows for the creation of a new asset,
+	// as well as the issuance of (additional) tokens of an existing asset,
+	// without requiring token inputs, but can only be used by the issuer of that asset.
+	TokenIssuanceTransactionController struct {
+		// AssetGetter is used to get the issuer condition of an existing asset,
+		// in order to sign the issuer fulfillment.
+		AssetGetter AssetGetter
+
+		// TransactionVersion is used to validate/set the transaction version
+		// of a token issuance transaction.
+		TransactionVersion types.TransactionVersion
+	}
+
+	// TokenTransferTransactionController defines a rivine-specific transaction controller,
+	// for a TokenTransfer Transaction. It allows tokens to be transferred,
+	// by spending token outputs and creating new ones of the same asset and value.
+	TokenTransferTransactionController struct {
+		// TokenOutputGetter is used to get the condition of the spent token outputs,
+		// in order to sign the fulfillments of the token inputs.
+		TokenOutputGetter TokenOutputGetter
+
+		// TransactionVersion is used to validate/set the transaction version
+		// of a token transfer transaction.
+		TransactionVersion types.TransactionVersion
+	}
+)
+
+// ensure our controllers implement all desired interfaces
+var (
+	// ensure at compile time that TokenIssuanceTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = TokenIssuanceTransactionController{}
+	_ types.TransactionExtensionSigner           = TokenIssuanceTransactionController{}
+	_ types.TransactionSignatureHasher           = TokenIssuanceTransactionController{}
+	_ types.TransactionIDEncoder                 = TokenIssuanceTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = TokenIssuanceTransactionController{}
+
+	// ensure at compile time that TokenTransferTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = TokenTransferTransactionController{}
+	_ types.TransactionExtensionSigner           = TokenTransferTransactionController{}
+	_ types.TransactionSignatureHasher           = TokenTransferTransactionController{}
+	_ types.TransactionIDEncoder                 = TokenTransferTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = TokenTransferTransactionController{}
+)
+
+// TokenIssuanceTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (titc TokenIssuanceTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	titx, err := TokenIssuanceTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a TokenIssuanceTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(titx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (titc TokenIssuanceTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var titx TokenIssuanceTransaction
+	err := rivbin.NewDecoder(r).Decode(&titx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a TokenIssuanceTx: %v", err)
+	}
+	// return token issuance tx as regular rivine tx data
+	return titx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (titc TokenIssuanceTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	titx, err := TokenIssuanceTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a TokenIssuanceTx: %v", err)
+	}
+	return json.Marshal(titx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (titc TokenIssuanceTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var titx TokenIssuanceTransaction
+	err := json.Unmarshal(data, &titx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a TokenIssuanceTx: %v", err)
+	}
+	// return token issuance tx as regular rivine tx data
+	return titx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (titc TokenIssuanceTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid TokenIssuanceTransactionExtension,
+	// which contains the issuer fulfillment that can be used to fulfill the issuer condition of the asset
+	tiTxExtension, ok := extension.(*TokenIssuanceTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a TokenIssuanceTx")
+	}
+
+	// a new asset defines its own issuer condition,
+	// while for an existing asset we use the issuer condition it was created with
+	var issuerCondition types.UnlockConditionProxy
+	if tiTxExtension.Definition != nil {
+		issuerCondition = tiTxExtension.Definition.IssuerCondition
+	} else {
+		if titc.AssetGetter == nil {
+			return nil, errors.New("no asset getter available to get the issuer condition of the asset")
+		}
+		asset, err := titc.AssetGetter.GetAsset(tiTxExtension.AssetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get asset %s: %v", tiTxExtension.AssetID.String(), err)
+		}
+		issuerCondition = asset.Definition.IssuerCondition
+	}
+	err := sign(&tiTxExtension.IssuerFulfillment, issuerCondition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign issuer fulfillment of TokenIssuanceTx: %v", err)
+	}
+	return tiTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (titc TokenIssuanceTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	titx, err := TokenIssuanceTransactionFromTransaction(t, titc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a TokenIssuanceTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierTokenIssuanceTransaction,
+		titx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	parentIDSlice := make([]types.CoinOutputID, 0, len(titx.CoinInputs))
+	for _, ci := range titx.CoinInputs {
+		parentIDSlice = append(parentIDSlice, ci.ParentID)
+	}
+
+	enc.EncodeAll(
+		titx.AssetID,
+		titx.Definition,
+		titx.TokenOutputs,
+		parentIDSlice,
+		titx.CoinOutputs,
+		titx.MinerFees,
+		titx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (titc TokenIssuanceTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	titx, err := TokenIssuanceTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a TokenIssuanceTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierTokenIssuanceTransaction, titx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData
+func (titc TokenIssuanceTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	tiext, ok := extension.(*TokenIssuanceTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a TokenIssuanceTx")
+	}
+	conditions := make([]types.UnlockConditionProxy, 0, len(tiext.TokenOutputs)+1)
+	if tiext.Definition != nil {
+		conditions = append(conditions, tiext.Definition.IssuerCondition)
+	}
+	for _, to := range tiext.TokenOutputs {
+		conditions = append(conditions, to.Condition)
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: conditions,
+	}, nil
+}
+
+// TokenTransferTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (tttc TokenTransferTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	tttx, err := TokenTransferTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a TokenTransferTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(tttx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (tttc TokenTransferTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var tttx TokenTransferTransaction
+	err := rivbin.NewDecoder(r).Decode(&tttx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a TokenTransferTx: %v", err)
+	}
+	// return token transfer tx as regular rivine tx data
+	return tttx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (tttc TokenTransferTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	tttx, err := TokenTransferTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a TokenTransferTx: %v", err)
+	}
+	return json.Marshal(tttx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (tttc TokenTransferTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var tttx TokenTransferTransaction
+	err := json.Unmarshal(data, &tttx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a TokenTransferTx: %v", err)
+	}
+	// return token transfer tx as regular rivine tx data
+	return tttx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (tttc TokenTransferTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid TokenTransferTransactionExtension,
+	// which contains the token inputs, of which the fulfillments have to be signed
+	ttTxExtension, ok := extension.(*TokenTransferTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a TokenTransferTx")
+	}
+	if tttc.TokenOutputGetter == nil {
+		return nil, errors.New("no token output getter available to get the conditions of the token inputs")
+	}
+	for idx := range ttTxExtension.TokenInputs {
+		ti := &ttTxExtension.TokenInputs[idx]
+		to, err := tttc.TokenOutputGetter.GetTokenOutput(ti.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent token output %s of token input #%d: %v", ti.ParentID.String(), idx, err)
+		}
+		// the specifier ensures the signature of a token input can never
+		// be used as the signature of the coin input at the same index
+		err = sign(&ti.Fulfillment, to.Condition, SpecifierTokenInput, uint64(idx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign token input #%d of TokenTransferTx: %v", idx, err)
+		}
+	}
+	return ttTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (tttc TokenTransferTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	tttx, err := TokenTransferTransactionFromTransaction(t, tttc.TransactionVersion)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a TokenTransferTx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierTokenTransferTransaction,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	tokenParentIDSlice := make([]TokenOutputID, 0, len(tttx.TokenInputs))
+	for _, ti := range tttx.TokenInputs {
+		tokenParentIDSlice = append(tokenParentIDSlice, ti.ParentID)
+	}
+	coinParentIDSlice := make([]types.CoinOutputID, 0, len(tttx.CoinInputs))
+	for _, ci := range tttx.CoinInputs {
+		coinParentIDSlice = append(coinParentIDSlice, ci.ParentID)
+	}
+
+	enc.EncodeAll(
+		tokenParentIDSlice,
+		tttx.TokenOutputs,
+		coinParentIDSlice,
+		tttx.CoinOutputs,
+		tttx.MinerFees,
+		tttx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (tttc TokenTransferTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	tttx, err := TokenTransferTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a TokenTransferTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierTokenTransferTransaction, tttx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData
+func (tttc TokenTransferTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	ttext, ok := extension.(*TokenTransferTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a TokenTransferTx")
+	}
+	conditions := make([]types.UnlockConditionProxy, 0, len(ttext.TokenOutputs))
+	for _, to := range ttext.TokenOutputs {
+		conditions = append(conditions, to.Condition)
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: conditions,
+	}, nil
+}
+
+type (
+	// TokenIssuanceTransaction is to be created only by the issuer of an asset,
+	// as a medium in order to issue tokens (token outputs) of that asset,
+	// without backing them (so without having to spend unspent token outputs).
+	//
+	// The first issuance of an asset defines the asset as well,
+	// in which case the issuer fulfillment has to fulfill the issuer condition
+	// defined by that asset definition.
+	TokenIssuanceTransaction struct {
+		// Nonce used to ensure the uniqueness of a TokenIssuanceTransaction's ID and signature.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// AssetID identifies the asset of which tokens are issued,
+		// for a new asset it has to equal NewAssetID(Nonce, *Definition).
+		AssetID AssetID `json:"assetid"`
+		// Definition defines a new asset, it is required for the first issuance
+		// of an asset and is not allowed for any issuance that follows.
+		Definition *AssetDefinition `json:"definition,omitempty"`
+		// IssuerFulfillment defines the fulfillment which is used in order to
+		// fulfill the issuer condition of the asset.
+		IssuerFulfillment types.UnlockFulfillmentProxy `json:"issuerfulfillment"`
+		// TokenOutputs defines the token outputs, which contain the freshly issued tokens,
+		// all token outputs have to be of the issued asset.
+		TokenOutputs []TokenOutput `json:"tokenoutputs"`
+		// CoinInputs are used to fund the miner fees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs can be used to refund coins left over after paying the miner fees.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this token issuance transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose,
+		// but is mostly to be used in order to define the reason/origins
+		// of the token issuance.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// TokenIssuanceTransactionExtension defines the TokenIssuanceTx Extension Data
+	TokenIssuanceTransactionExtension struct {
+		Nonce             types.TransactionNonce
+		AssetID           AssetID
+		Definition        *AssetDefinition
+		IssuerFulfillment types.UnlockFulfillmentProxy
+		TokenOutputs      []TokenOutput
+	}
+)
+
+// TokenIssuanceTransactionFromTransaction creates a TokenIssuanceTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `TokenIssuanceTransactionFromTransactionData` constructor.
+func TokenIssuanceTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (TokenIssuanceTransaction, error) {
+	if tx.Version != expectedVersion {
+		return TokenIssuanceTransaction{}, fmt.Errorf(
+			"a token issuance transaction requires tx version %d",
+			expectedVersion)
+	}
+	return TokenIssuanceTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// TokenIssuanceTransactionFromTransactionData creates a TokenIssuanceTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func TokenIssuanceTransactionFromTransactionData(txData types.TransactionData) (TokenIssuanceTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid TokenIssuanceTransactionExtension,
+	// which contains all token-specific data
+	extensionData, ok := txData.Extension.(*TokenIssuanceTransactionExtension)
+	if !ok {
+		return TokenIssuanceTransaction{}, errors.New("invalid extension data for a TokenIssuanceTransaction")
+	}
+	// at least one token output, coin input and miner fee is required
+	if len(extensionData.TokenOutputs) == 0 {
+		return TokenIssuanceTransaction{}, errors.New("at least one token output is required for a TokenIssuanceTransaction")
+	}
+	if len(txData.CoinInputs) == 0 {
+		return TokenIssuanceTransaction{}, errors.New("at least one coin input is required for a TokenIssuanceTransaction")
+	}
+	if len(txData.MinerFees) == 0 {
+		return TokenIssuanceTransaction{}, errors.New("at least one miner fee is required for a TokenIssuanceTransaction")
+	}
+	// no block stake inputs or block stake outputs are allowed
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return TokenIssuanceTransaction{}, errors.New("no block stake inputs/outputs are allowed in a TokenIssuanceTransaction")
+	}
+	// return the TokenIssuanceTransaction, with the data extracted from the TransactionData
+	return TokenIssuanceTransaction{
+		Nonce:             extensionData.Nonce,
+		AssetID:           extensionData.AssetID,
+		Definition:        extensionData.Definition,
+		IssuerFulfillment: extensionData.IssuerFulfillment,
+		TokenOutputs:      extensionData.TokenOutputs,
+		CoinInputs:        txData.CoinInputs,
+		CoinOutputs:       txData.CoinOutputs,
+		MinerFees:         txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this TokenIssuanceTransaction
+// as regular rivine transaction data.
+func (titx *TokenIssuanceTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    titx.CoinInputs,
+		CoinOutputs:   titx.CoinOutputs,
+		MinerFees:     titx.MinerFees,
+		ArbitraryData: titx.ArbitraryData,
+		Extension: &TokenIssuanceTransactionExtension{
+			Nonce:             titx.Nonce,
+			AssetID:           titx.AssetID,
+			Definition:        titx.Definition,
+			IssuerFulfillment: titx.IssuerFulfillment,
+			TokenOutputs:      titx.TokenOutputs,
+		},
+	}
+}
+
+// Transaction returns this TokenIssuanceTransaction
+// as regular rivine transaction, using the given version.
+func (titx *TokenIssuanceTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	txData := titx.TransactionData()
+	return types.Transaction{
+		Version:       version,
+		CoinInputs:    txData.CoinInputs,
+		CoinOutputs:   txData.CoinOutputs,
+		MinerFees:     txData.MinerFees,
+		ArbitraryData: txData.ArbitraryData,
+		Extension:     txData.Extension,
+	}
+}
+
+type (
+	// TokenTransferTransaction is to be used by anyone
+	// as a medium in order to transfer tokens of one or multiple assets.
+	TokenTransferTransaction struct {
+		// TokenInputs defines the token outputs that are being spent.
+		TokenInputs []TokenInput `json:"tokeninputs"`
+		// TokenOutputs defines the new token outputs,
+		// for each asset the sum of the token outputs has to equal
+		// the sum of the spent token outputs.
+		TokenOutputs []TokenOutput `json:"tokenoutputs"`
+		// CoinInputs are used to fund the miner fees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs can be used to refund coins left over after paying the miner fees.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// Minerfees, a fee paid for this token transfer transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// TokenTransferTransactionExtension defines the TokenTransferTx Extension Data
+	TokenTransferTransactionExtension struct {
+		TokenInputs  []TokenInput
+		TokenOutputs []TokenOutput
+	}
+)
+
+// TokenTransferTransactionFromTransaction creates a TokenTransferTransaction,
+// using a regular in-memory rivine transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `TokenTransferTransactionFromTransactionData` constructor.
+func TokenTransferTransactionFromTransaction(tx types.Transaction, expectedVersion types.TransactionVersion) (TokenTransferTransaction, error) {
+	if tx.Version != expectedVersion {
+		return TokenTransferTransaction{}, fmt.Errorf(
+			"a token transfer transaction requires tx version %d",
+			expectedVersion)
+	}
+	return TokenTransferTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// TokenTransferTransactionFromTransactionData creates a TokenTransferTransaction,
+// using the TransactionData from a regular in-memory rivine transaction.
+func TokenTransferTransactionFromTransactionData(txData types.TransactionData) (TokenTransferTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid TokenTransferTransactionExtension,
+	// which contains the token inputs and outputs
+	extensionData, ok := txData.Extension.(*TokenTransferTransactionExtension)
+	if !ok {
+		return TokenTransferTransaction{}, errors.New("invalid extension data for a TokenTransferTransaction")
+	}
+	// at least one token input, token output, coin input and miner fee is required
+	if len(extensionData.TokenInputs) == 0 {
+		return TokenTransferTransaction{}, errors.New("at least one token input is required for a TokenTransferTransaction")
+	}
+	if len(extensionData.TokenOutputs) == 0 {
+		return TokenTransferTransaction{}, errors.New("at least one token output is required for a TokenTransferTransaction")
+	}
+	if len(txData.CoinInputs) == 0 {
+		return TokenTransferTransaction{}, errors.New("at least one coin input is required for a TokenTransferTransaction")
+	}
+	if len(txData.MinerFees) == 0 {
+		return TokenTransferTransaction{}, errors.New("at least one miner fee is required for a TokenTransferTransaction")
+	}
+	// no block stake inputs or block stake outputs are allowed
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return TokenTransferTransaction{}, errors.New("no block stake inputs/outputs are allowed in a TokenTransferTransaction")
+	}
+	// return the TokenTransferTransaction, with the data extracted from the TransactionData
+	return TokenTransferTransaction{
+		TokenInputs:  extensionData.TokenInputs,
+		TokenOutputs: extensionData.TokenOutputs,
+		CoinInputs:   txData.CoinInputs,
+		CoinOutputs:  txData.CoinOutputs,
+		MinerFees:    txData.MinerFees,
+		// ArbitraryData is optional
+		ArbitraryData: txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this TokenTransferTransaction
+// as regular rivine transaction data.
+func (tttx *TokenTransferTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    tttx.CoinInputs,
+		CoinOutputs:   tttx.CoinOutputs,
+		MinerFees:     tttx.MinerFees,
+		ArbitraryData: tttx.ArbitraryData,
+		Extension: &TokenTransferTransactionExtension{
+			TokenInputs:  tttx.TokenInputs,
+			TokenOutputs: tttx.TokenOutputs,
+		},
+	}
+}
+
+// Transaction returns this TokenTransferTransaction
+// as regular rivine transaction, using the given version.
+func (tttx *TokenTransferTransaction) Transaction(version types.TransactionVersion) types.Transaction {
+	txData := tttx.TransactionData()
+	return types.Transaction{
+		Version:       version,
+		CoinInputs:    txData.CoinInputs,
+		CoinOutputs:   txData.CoinOutputs,
+		MinerFees:     txData.MinerFees,
+		ArbitraryData: txData.ArbitraryData,
+		Extension:     txData.Extension,
+	}
+}
@@ -0,0 +1,165 @@
+package tokens
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/threefoldtech/rivine/extensions/internal/plugintest"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	testIssuanceTxVersion types.TransactionVersion = 200
+	testTransferTxVersion types.TransactionVersion = 201
+)
+
+func testTokenTransactions(t *testing.T) []types.Transaction {
+	issuer := plugintest.UnlockHashCondition(t, "015a080a9259b9d4aaa550e2156f49b1a79a64c7ea463d810d4493e8242e6791584fbdac553e6f")
+	receiver := plugintest.UnlockHashCondition(t, "01b5e42056ef394f2ad9b511a61cec874d25bebe2095682dd37455cbafed4bec154e382a23f90e")
+
+	definition := AssetDefinition{
+		Symbol:          "TKN",
+		Name:            "Test Token",
+		Decimals:        2,
+		IssuerCondition: issuer,
+	}
+	nonce := types.TransactionNonce{1, 2, 3, 4, 5, 6, 7, 8}
+	assetID := NewAssetID(nonce, definition)
+
+	issuanceTx := TokenIssuanceTransaction{
+		Nonce:             nonce,
+		AssetID:           assetID,
+		Definition:        &definition,
+		IssuerFulfillment: plugintest.FakeFulfillment(),
+		TokenOutputs: []TokenOutput{
+			{AssetID: assetID, Value: types.NewCurrency64(100000), Condition: receiver},
+		},
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 2}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(42), Condition: issuer},
+		},
+		MinerFees:     []types.Currency{types.NewCurrency64(1)},
+		ArbitraryData: []byte("first issuance"),
+	}
+	transferTx := TokenTransferTransaction{
+		TokenInputs: []TokenInput{
+			{ParentID: NewTokenOutputID(types.TransactionID{1}, 0), Fulfillment: plugintest.FakeFulfillment()},
+		},
+		TokenOutputs: []TokenOutput{
+			{AssetID: assetID, Value: types.NewCurrency64(60000), Condition: issuer},
+			{AssetID: assetID, Value: types.NewCurrency64(40000), Condition: receiver},
+		},
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{4, 3}, Fulfillment: plugintest.FakeFulfillment()},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	return []types.Transaction{
+		issuanceTx.Transaction(testIssuanceTxVersion),
+		transferTx.Transaction(testTransferTxVersion),
+	}
+}
+
+func TestTokenTransactionEncodingRoundtrip(t *testing.T) {
+	types.RegisterTransactionVersion(testIssuanceTxVersion, TokenIssuanceTransactionController{TransactionVersion: testIssuanceTxVersion})
+	defer types.RegisterTransactionVersion(testIssuanceTxVersion, nil)
+	types.RegisterTransactionVersion(testTransferTxVersion, TokenTransferTransactionController{TransactionVersion: testTransferTxVersion})
+	defer types.RegisterTransactionVersion(testTransferTxVersion, nil)
+
+	for idx, tx := range testTokenTransactions(t) {
+		// JSON roundtrip
+		b, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to JSON-marshal: %v", idx, err)
+		}
+		var jsonTx types.Transaction
+		err = json.Unmarshal(b, &jsonTx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to JSON-unmarshal: %v", idx, err)
+		}
+		if jsonTx.ID() != tx.ID() {
+			t.Errorf("tx #%d: JSON roundtrip changed ID: %v != %v", idx, jsonTx.ID(), tx.ID())
+		}
+
+		// binary roundtrip
+		b, err = siabin.Marshal(tx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to binary-marshal: %v", idx, err)
+		}
+		var binTx types.Transaction
+		err = siabin.Unmarshal(b, &binTx)
+		if err != nil {
+			t.Fatalf("tx #%d: failed to binary-unmarshal: %v", idx, err)
+		}
+		if binTx.ID() != tx.ID() {
+			t.Errorf("tx #%d: binary roundtrip changed ID: %v != %v", idx, binTx.ID(), tx.ID())
+		}
+		if !reflect.DeepEqual(binTx.Extension, jsonTx.Extension) {
+			t.Errorf("tx #%d: binary and JSON decoded extensions differ: %v != %v", idx, binTx.Extension, jsonTx.Extension)
+		}
+	}
+}
+
+func TestTokenTransactionFromTransaction(t *testing.T) {
+	types.RegisterTransactionVersion(testIssuanceTxVersion, TokenIssuanceTransactionController{TransactionVersion: testIssuanceTxVersion})
+	defer types.RegisterTransactionVersion(testIssuanceTxVersion, nil)
+	types.RegisterTransactionVersion(testTransferTxVersion, TokenTransferTransactionController{TransactionVersion: testTransferTxVersion})
+	defer types.RegisterTransactionVersion(testTransferTxVersion, nil)
+
+	txs := testTokenTransactions(t)
+
+	titx, err := TokenIssuanceTransactionFromTransaction(txs[0], testIssuanceTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titx.Definition == nil || titx.Definition.Symbol != "TKN" {
+		t.Errorf("unexpected asset definition: %v", titx.Definition)
+	}
+	if titx.AssetID != NewAssetID(titx.Nonce, *titx.Definition) {
+		t.Error("unexpected asset ID")
+	}
+	_, err = TokenIssuanceTransactionFromTransaction(txs[1], testIssuanceTxVersion)
+	if err == nil {
+		t.Error("expected a token transfer tx not to be accepted as a token issuance tx")
+	}
+
+	tttx, err := TokenTransferTransactionFromTransaction(txs[1], testTransferTxVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tttx.TokenInputs) != 1 || len(tttx.TokenOutputs) != 2 {
+		t.Errorf("unexpected token inputs and outputs: %v / %v", tttx.TokenInputs, tttx.TokenOutputs)
+	}
+	_, err = TokenTransferTransactionFromTransaction(txs[0], testTransferTxVersion)
+	if err == nil {
+		t.Error("expected a token issuance tx not to be accepted as a token transfer tx")
+	}
+}
+
+func TestAssetDefinitionValidate(t *testing.T) {
+	issuer := plugintest.UnlockHashCondition(t, "015a080a9259b9d4aaa550e2156f49b1a79a64c7ea463d810d4493e8242e6791584fbdac553e6f")
+	testCases := []struct {
+		Definition AssetDefinition
+		Valid      bool
+	}{
+		{AssetDefinition{Symbol: "TKN", IssuerCondition: issuer}, true},
+		{AssetDefinition{Symbol: "TKN2", Name: "Token", Decimals: MaxAssetDecimals, IssuerCondition: issuer}, true},
+		{AssetDefinition{Symbol: "T", IssuerCondition: issuer}, false},
+		{AssetDefinition{Symbol: "tkn", IssuerCondition: issuer}, false},
+		{AssetDefinition{Symbol: "2TKN", IssuerCondition: issuer}, false},
+		{AssetDefinition{Symbol: "TKN", Decimals: MaxAssetDecimals + 1, IssuerCondition: issuer}, false},
+		{AssetDefinition{Symbol: "TKN"}, false},
+	}
+	for idx, testCase := range testCases {
+		err := testCase.Definition.Validate(types.ValidationContext{})
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected an error", idx)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// These Specifiers are used internally when calculating a Transaction's ID,
+// and the IDs of assets and token outputs.
+// See Rivine's Specifier for more details.
+var (
+	SpecifierTokenIssuanceTransaction = types.Specifier{'t', 'o', 'k', 'e', 'n', ' ', 'i', 's', 's', 'u', 'e', ' ', 't', 'x'}
+	SpecifierTokenTransferTransaction = types.Specifier{'t', 'o', 'k', 'e', 'n', ' ', 't', 'r', 'a', 'n', 's', 'f', 'e', 'r'}
+	SpecifierTokenAsset               = types.Specifier{'t', 'o', 'k', 'e', 'n', ' ', 'a', 's', 's', 'e', 't'}
+	SpecifierTokenOutput              = types.Specifier{'t', 'o', 'k', 'e', 'n', ' ', 'o', 'u', 't', 'p', 'u', 't'}
+	SpecifierTokenInput               = types.Specifier{'t', 'o', 'k', 'e', 'n', ' ', 'i', 'n', 'p', 'u', 't'}
+)
+
+const (
+	// MaxAssetNameLength defines the maximum length (in bytes) of the name of an asset.
+	MaxAssetNameLength = 64
+	// MaxAssetDecimals defines the maximum amount of decimals an asset can define.
+	MaxAssetDecimals = 18
+)
+
+var (
+	// ErrAssetNotFound is returned in case an asset could not be found.
+	ErrAssetNotFound = errors.New("asset not found")
+	// ErrTokenOutputNotFound is returned in case an unspent token output could not be found.
+	ErrTokenOutputNotFound = errors.New("unspent token output not found")
+)
+
+// assetSymbolRegexp defines the format of an asset symbol:
+// 2 up to 12 upper case letters or digits, starting with a letter.
+var assetSymbolRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,11}$`)
+
+type (
+	// AssetID uniquely identifies a user-defined asset (token).
+	AssetID crypto.Hash
+
+	// TokenOutputID uniquely identifies a token output.
+	TokenOutputID crypto.Hash
+
+	// AssetDefinition defines the properties of a user-defined asset,
+	// as defined by the transaction which issues it for the first time.
+	AssetDefinition struct {
+		// Symbol is the (ticker) symbol of the asset, e.g. "TKN".
+		Symbol string `json:"symbol"`
+		// Name is the optional (human-readable) name of the asset.
+		Name string `json:"name,omitempty"`
+		// Decimals defines how many decimals are used to display token values,
+		// token values are always stored as integers in the smallest unit.
+		Decimals uint8 `json:"decimals"`
+		// IssuerCondition defines the condition that has to be fulfilled
+		// in order to issue additional tokens of this asset.
+		// A NilCondition is not allowed, as this would allow anyone to issue tokens.
+		IssuerCondition types.UnlockConditionProxy `json:"issuercondition"`
+	}
+
+	// Asset is the state of an asset as tracked by the consensus plugin.
+	Asset struct {
+		ID         AssetID         `json:"id"`
+		Definition AssetDefinition `json:"definition"`
+		// Supply is the total amount of tokens issued for this asset.
+		Supply types.Currency `json:"supply"`
+		// CreationHeight is the height of the block which contains the
+		// transaction that created this asset.
+		CreationHeight types.BlockHeight `json:"creationheight"`
+		// CreationTime is the timestamp of the block which contains the
+		// transaction that created this asset.
+		CreationTime types.Timestamp `json:"creationtime"`
+		// CreationTransactionID is the ID of the transaction that created this asset.
+		CreationTransactionID types.TransactionID `json:"creationtransactionid"`
+	}
+
+	// TokenOutput defines an amount of tokens of a given asset,
+	// which can be spent by fulfilling its condition.
+	TokenOutput struct {
+		AssetID   AssetID                    `json:"assetid"`
+		Value     types.Currency             `json:"value"`
+		Condition types.UnlockConditionProxy `json:"condition"`
+	}
+
+	// TokenInput spends a previously created (and still unspent) token output.
+	TokenInput struct {
+		ParentID    TokenOutputID                `json:"parentid"`
+		Fulfillment types.UnlockFulfillmentProxy `json:"fulfillment"`
+	}
+)
+
+// NewAssetID computes the ID of a new asset,
+// using the nonce of the transaction that creates it and its definition.
+func NewAssetID(nonce types.TransactionNonce, definition AssetDefinition) (id AssetID) {
+	h := crypto.NewHash()
+	rivbin.NewEncoder(h).EncodeAll(
+		SpecifierTokenAsset,
+		nonce,
+		definition,
+	)
+	h.Sum(id[:0])
+	return
+}
+
+// NewTokenOutputID computes the ID of the token output
+// at the given index of the transaction with the given ID.
+func NewTokenOutputID(txID types.TransactionID, index uint64) (id TokenOutputID) {
+	h := crypto.NewHash()
+	rivbin.NewEncoder(h).EncodeAll(
+		SpecifierTokenOutput,
+		txID,
+		index,
+	)
+	h.Sum(id[:0])
+	return
+}
+
+// String prints the asset id in hex.
+func (id AssetID) String() string {
+	return crypto.Hash(id).String()
+}
+
+// LoadString loads the given asset id from a hex string
+func (id *AssetID) LoadString(str string) error {
+	return (*crypto.Hash)(id).LoadString(str)
+}
+
+// MarshalJSON marshals an asset id as a hex string.
+func (id AssetID) MarshalJSON() ([]byte, error) {
+	return crypto.Hash(id).MarshalJSON()
+}
+
+// UnmarshalJSON decodes the json hex string of the asset id.
+func (id *AssetID) UnmarshalJSON(b []byte) error {
+	return (*crypto.Hash)(id).UnmarshalJSON(b)
+}
+
+// String prints the token output id in hex.
+func (id TokenOutputID) String() string {
+	return crypto.Hash(id).String()
+}
+
+// LoadString loads the given token output id from a hex string
+func (id *TokenOutputID) LoadString(str string) error {
+	return (*crypto.Hash)(id).LoadString(str)
+}
+
+// MarshalJSON marshals a token output id as a hex string.
+func (id TokenOutputID) MarshalJSON() ([]byte, error) {
+	return crypto.Hash(id).MarshalJSON()
+}
+
+// UnmarshalJSON decodes the json hex string of the token output id.
+func (id *TokenOutputID) UnmarshalJSON(b []byte) error {
+	return (*crypto.Hash)(id).UnmarshalJSON(b)
+}
+
+// Validate checks if the asset definition is valid within the given context.
+func (def AssetDefinition) Validate(ctx types.ValidationContext) error {
+	if !assetSymbolRegexp.MatchString(def.Symbol) {
+		return fmt.Errorf(
+			"invalid asset symbol %q: expected 2 up to 12 upper case letters or digits, starting with a letter",
+			def.Symbol)
+	}
+	if len(def.Name) > MaxAssetNameLength {
+		return fmt.Errorf("asset name is too long: %d > %d", len(def.Name), MaxAssetNameLength)
+	}
+	if def.Decimals > MaxAssetDecimals {
+		return fmt.Errorf("asset defines too many decimals: %d > %d", def.Decimals, MaxAssetDecimals)
+	}
+	if def.IssuerCondition.ConditionType() == types.ConditionTypeNil {
+		return errors.New("the nil condition cannot be used as issuer condition")
+	}
+	err := def.IssuerCondition.IsStandardCondition(ctx)
+	if err != nil {
+		return fmt.Errorf("issuer condition is not standard: %v", err)
+	}
+	return nil
+}
+
+// TokenOutputGetter allows you to look up an unspent token output.
+type TokenOutputGetter interface {
+	// GetTokenOutput returns the unspent token output for the given ID,
+	// returning ErrTokenOutputNotFound if it doesn't exist or is already spent.
+	GetTokenOutput(id TokenOutputID) (TokenOutput, error)
+}
+
+// AssetGetter allows you to look up an asset.
+type AssetGetter interface {
+	// GetAsset returns the asset for the given ID,
+	// returning ErrAssetNotFound if it doesn't exist.
+	GetAsset(id AssetID) (Asset, error)
+}
+
+// TokenInfoGetter allows you to get the token state required
+// in order to sign and validate token transactions.
+//
+// For the daemon this interface is implemented directly by the consensus plugin,
+// while for a client this is implemented using the REST API of a rivine daemon.
+type TokenInfoGetter interface {
+	AssetGetter
+	TokenOutputGetter
+}
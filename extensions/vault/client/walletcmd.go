@@ -0,0 +1,266 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/extensions/vault"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	client "github.com/threefoldtech/rivine/pkg/client"
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the vault extension,
+// used to create vaults and to move the coins they lock.
+// The created transactions are printed (JSON-encoded), and can be sent using the send transaction command.
+func CreateWalletCmds(ccli *client.CommandLineClient) error {
+	walletCmd := &walletCmd{
+		cli: ccli,
+	}
+
+	var (
+		vaultCmd = &cobra.Command{
+			Use:   "vault",
+			Short: "Create and spend from vaults, protected by a recovery key",
+			Long: `Create and spend from vaults, protected by a recovery key.
+
+Coins sent from a vault by its owner are locked by a clawback output,
+which can only be spent by its destination once the delay of the vault has passed,
+while the recovery key can claw them back during that delay.`,
+		}
+		addressCmd = &cobra.Command{
+			Use:   "address <ownerAddress> <recoveryAddress> <delay>",
+			Short: "Print the address and condition of a vault",
+			Long: `Print the address and condition of a vault,
+owned by the owner address and protected by the recovery address,
+for the given delay in blocks.`,
+			Args: cobra.ExactArgs(3),
+			Run:  walletCmd.addressCmd,
+		}
+		unvaultCmd = &cobra.Command{
+			Use:   "unvault <destinationAddress> <coinOutputID>...",
+			Short: "Send the coins of vault outputs to the destination, as owner",
+			Long: `Send the coins of the given vault outputs to a clawback output of the destination,
+signed by the owner key of this wallet. The miner fee is funded by this wallet.`,
+			Args: cobra.MinimumNArgs(2),
+			Run:  walletCmd.unvaultCmd,
+		}
+		claimCmd = &cobra.Command{
+			Use:   "claim <destinationAddress> <coinOutputID>...",
+			Short: "Send the coins of clawback outputs to the destination, once their delay has passed",
+			Args:  cobra.MinimumNArgs(2),
+			Run:   walletCmd.claimCmd,
+		}
+		clawbackCmd = &cobra.Command{
+			Use:   "clawback <destinationAddress> <coinOutputID>...",
+			Short: "Send the coins of vault or clawback outputs to the destination, as recovery",
+			Long: `Send the coins of the given vault or clawback outputs to the destination,
+signed by the recovery key of this wallet, which can be done at any time.`,
+			Args: cobra.MinimumNArgs(2),
+			Run:  walletCmd.clawbackCmd,
+		}
+	)
+
+	vaultCmd.AddCommand(
+		addressCmd,
+		unvaultCmd,
+		claimCmd,
+		clawbackCmd,
+	)
+	ccli.WalletCmd.AddCommand(vaultCmd)
+
+	return nil
+}
+
+type walletCmd struct {
+	cli *client.CommandLineClient
+}
+
+func (walletCmd *walletCmd) addressCmd(cmd *cobra.Command, args []string) {
+	owner := parseUnlockHash(cmd, args[0], "owner address")
+	recovery := parseUnlockHash(cmd, args[1], "recovery address")
+	delay, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid delay", err)
+	}
+	condition := types.NewCondition(vault.NewVaultCondition(delay, recovery, types.NewUnlockHashCondition(owner)))
+	err = condition.IsStandardCondition(types.ValidationContext{})
+	if err != nil {
+		cli.DieWithError("vault condition is not standard", err)
+	}
+	fmt.Println("address:", condition.UnlockHash().String())
+	fmt.Print("condition: ")
+	encodeJSON(condition)
+}
+
+func (walletCmd *walletCmd) unvaultCmd(cmd *cobra.Command, args []string) {
+	destination := parseUnlockHash(cmd, args[0], "destination address")
+	var vc *vault.VaultCondition
+	txn, value := walletCmd.spendTransaction(cmd, args[1:], func(condition types.UnlockConditionProxy) {
+		c, ok := condition.Condition.(*vault.VaultCondition)
+		if !ok {
+			cli.Die("coin output is not locked by a vault")
+		}
+		if vc != nil && !vc.Equal(c) {
+			cli.Die("all coin outputs have to be locked by the same vault")
+		}
+		vc = c
+	})
+	// all coins are sent to the clawback output, such that the miner fee is funded by the wallet
+	txn.CoinOutputs = []types.CoinOutput{{
+		Value:     value,
+		Condition: types.NewCondition(vault.NewClawbackCondition(vc.Delay, vc.RecoveryUnlockHash, types.NewUnlockHashCondition(destination))),
+	}}
+	var funds api.WalletFundCoins
+	err := walletCmd.cli.GetWithResponse("/wallet/fund/coins?amount="+txn.MinerFees[0].String(), &funds)
+	if err != nil {
+		cli.DieWithError("failed to fund the miner fee", err)
+	}
+	txn.CoinInputs = append(txn.CoinInputs, funds.CoinInputs...)
+	if funds.RefundCoinOutput != nil {
+		txn.CoinOutputs = append(txn.CoinOutputs, *funds.RefundCoinOutput)
+	}
+	encodeJSON(walletCmd.walletSign(txn))
+}
+
+func (walletCmd *walletCmd) claimCmd(cmd *cobra.Command, args []string) {
+	destination := parseUnlockHash(cmd, args[0], "destination address")
+	txn, value := walletCmd.spendTransaction(cmd, args[1:], func(condition types.UnlockConditionProxy) {
+		if condition.ConditionType() != vault.ConditionTypeClawback {
+			cli.Die("coin output is not locked by a clawback condition")
+		}
+	})
+	txn.CoinOutputs = []types.CoinOutput{{
+		Value:     subtractMinerFee(value, txn.MinerFees[0]),
+		Condition: types.NewCondition(types.NewUnlockHashCondition(destination)),
+	}}
+	encodeJSON(walletCmd.walletSign(txn))
+}
+
+func (walletCmd *walletCmd) clawbackCmd(cmd *cobra.Command, args []string) {
+	destination := parseUnlockHash(cmd, args[0], "destination address")
+	var recovery types.UnlockHash
+	txn, value := walletCmd.spendTransaction(cmd, args[1:], func(condition types.UnlockConditionProxy) {
+		var uh types.UnlockHash
+		switch c := condition.Condition.(type) {
+		case *vault.VaultCondition:
+			uh = c.RecoveryUnlockHash
+		case *vault.ClawbackCondition:
+			uh = c.RecoveryUnlockHash
+		default:
+			cli.Die("coin output is not locked by a vault or clawback condition")
+		}
+		if recovery.Type != types.UnlockTypeNil && recovery.Cmp(uh) != 0 {
+			cli.Die("all coin outputs have to be protected by the same recovery key")
+		}
+		recovery = uh
+	})
+	txn.CoinOutputs = []types.CoinOutput{{
+		Value:     subtractMinerFee(value, txn.MinerFees[0]),
+		Condition: types.NewCondition(types.NewUnlockHashCondition(destination)),
+	}}
+
+	pk, sk := walletCmd.spendableKey(recovery)
+	for idx := range txn.CoinInputs {
+		txn.CoinInputs[idx].Fulfillment = types.NewFulfillment(types.NewSingleSignatureFulfillment(pk))
+		err := txn.CoinInputs[idx].Fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: []interface{}{uint64(idx)},
+			Transaction:  txn,
+			Key:          sk,
+		})
+		if err != nil {
+			cli.DieWithError("failed to sign coin input", err)
+		}
+	}
+	encodeJSON(txn)
+}
+
+// spendTransaction creates a transaction spending the given coin outputs,
+// validating the condition of each output using the given function.
+// The total value of the spent outputs is returned together with the transaction.
+func (walletCmd *walletCmd) spendTransaction(cmd *cobra.Command, ids []string, validate func(types.UnlockConditionProxy)) (types.Transaction, types.Currency) {
+	txn := types.Transaction{
+		Version:   walletCmd.cli.Config.DefaultTransactionVersion,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	var value types.Currency
+	for _, str := range ids {
+		var id types.CoinOutputID
+		err := id.LoadString(str)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithError("invalid coin output ID", err)
+		}
+		var resp api.ConsensusGetUnspentCoinOutput
+		err = walletCmd.cli.GetWithResponse("/consensus/unspent/coinoutputs/"+id.String(), &resp)
+		if err != nil {
+			cli.DieWithError("failed to get unspent coin output "+id.String(), err)
+		}
+		validate(resp.Output.Condition)
+		txn.CoinInputs = append(txn.CoinInputs, types.CoinInput{ParentID: id})
+		value = value.Add(resp.Output.Value)
+	}
+	return txn, value
+}
+
+// walletSign signs all inputs of the transaction that can be signed by the wallet.
+func (walletCmd *walletCmd) walletSign(txn types.Transaction) types.Transaction {
+	b, err := json.Marshal(txn)
+	if err != nil {
+		cli.DieWithError("failed to encode transaction", err)
+	}
+	var signedTxn types.Transaction
+	err = walletCmd.cli.PostWithResponse("/wallet/sign", string(b), &signedTxn)
+	if err != nil {
+		cli.DieWithError("failed to sign transaction", err)
+	}
+	return signedTxn
+}
+
+// spendableKey returns the key pair linked to the given unlock hash, owned by the wallet.
+func (walletCmd *walletCmd) spendableKey(uh types.UnlockHash) (types.PublicKey, types.ByteSlice) {
+	var resp api.WalletKeyGet
+	err := walletCmd.cli.GetWithResponse("/wallet/key/"+uh.String(), &resp)
+	if err != nil {
+		cli.DieWithError("failed to get the wallet key of "+uh.String(), err)
+	}
+	pk := types.PublicKey{
+		Key: resp.PublicKey,
+	}
+	err = pk.Algorithm.LoadSpecifier(resp.AlgorithmSpecifier)
+	if err != nil {
+		cli.DieWithError("invalid public key algorithm specifier loaded", err)
+	}
+	return pk, resp.SecretKey
+}
+
+func subtractMinerFee(value, fee types.Currency) types.Currency {
+	if value.Cmp(fee) != 1 {
+		cli.Die("coin outputs have to lock a value greater than the miner fee of", fee.String())
+	}
+	return value.Sub(fee)
+}
+
+func parseUnlockHash(cmd *cobra.Command, str, name string) types.UnlockHash {
+	var uh types.UnlockHash
+	err := uh.LoadString(str)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid "+name, err)
+	}
+	return uh
+}
+
+// encodeJSON encodes the value as JSON to the STDOUT
+func encodeJSON(value interface{}) {
+	err := json.NewEncoder(os.Stdout).Encode(value)
+	if err != nil {
+		cli.DieWithError("failed to encode result", err)
+	}
+}
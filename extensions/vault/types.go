@@ -0,0 +1,461 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// ConditionTypeVault defines an unlock condition which restricts where the value it locks can be sent to.
+	// The owner condition can only spend the locked value by sending it to a ClawbackCondition
+	// (or a vault) protected by the same recovery key, with at least the same delay,
+	// while the recovery key can spend the locked value without restrictions.
+	// The owner condition has to be one of: [
+	// UnlockHashCondition (0x01 unlock hash type is the only standard one at the moment, others aren't allowed),
+	// MultiSignatureCondition,
+	// ]
+	ConditionTypeVault types.ConditionType = 8
+
+	// ConditionTypeClawback defines an unlock condition which locks value sent from a vault.
+	// The destination condition can only spend the locked value once the delay has passed,
+	// since the creation of the output, while the recovery key can claw it back at any time.
+	// The destination condition supports the same conditions as the owner condition of a VaultCondition.
+	ConditionTypeClawback types.ConditionType = 9
+
+	// UnlockTypeVault is the unlock type of the unlock hash (address)
+	// of a VaultCondition.
+	UnlockTypeVault types.UnlockType = 6
+
+	// UnlockTypeClawback is the unlock type of the unlock hash (address)
+	// of a ClawbackCondition.
+	UnlockTypeClawback types.UnlockType = 7
+
+	// MaxDelay is the maximum delay (in blocks) of a standard vault or clawback condition.
+	MaxDelay uint64 = 1 << 24
+)
+
+var (
+	// ErrCovenantViolated is returned in case a VaultCondition is fulfilled by its owner,
+	// while not all value locked by vaults of the same recovery key is sent to restricted outputs.
+	ErrCovenantViolated = errors.New("vault covenant violated: locked value has to be sent to a clawback output")
+	// ErrCovenantUnverifiable is returned in case a VaultCondition is fulfilled by its owner,
+	// within a context that does not define the outputs spent by the transaction.
+	ErrCovenantUnverifiable = errors.New("vault covenant cannot be verified: spent outputs are unknown")
+	// ErrClawbackDelayNotReached is returned in case a ClawbackCondition is fulfilled by its destination,
+	// prior to its delay having passed since the creation of the output.
+	ErrClawbackDelayNotReached = errors.New("clawback delay has not yet been reached")
+)
+
+// RegisterUnlockConditionTypes registers the vault and clawback condition types,
+// such that outputs can be protected by a recovery key against the theft of the owner key.
+//
+// As these types are part of the consensus rules,
+// all nodes of a network should register them at the same time.
+func RegisterUnlockConditionTypes() {
+	types.RegisterUnlockConditionType(ConditionTypeVault,
+		func() types.MarshalableUnlockCondition { return &VaultCondition{} })
+	types.RegisterUnlockConditionType(ConditionTypeClawback,
+		func() types.MarshalableUnlockCondition { return &ClawbackCondition{} })
+}
+
+// UnregisterUnlockConditionTypes unregisters the vault and clawback condition types.
+func UnregisterUnlockConditionTypes() {
+	types.RegisterUnlockConditionType(ConditionTypeVault, nil)
+	types.RegisterUnlockConditionType(ConditionTypeClawback, nil)
+}
+
+type (
+	// VaultCondition implements the ConditionTypeVault ConditionType.
+	// See ConditionTypeVault for more information.
+	VaultCondition struct {
+		// Delay defines the minimum amount of blocks the value sent by the owner
+		// remains claimable by the recovery key.
+		Delay uint64
+		// RecoveryUnlockHash defines the (public key) unlock hash of the recovery key.
+		RecoveryUnlockHash types.UnlockHash
+		// Condition defines the condition of the owner of the vault.
+		Condition types.MarshalableUnlockCondition
+	}
+
+	// ClawbackCondition implements the ConditionTypeClawback ConditionType.
+	// See ConditionTypeClawback for more information.
+	ClawbackCondition struct {
+		// Delay defines the amount of blocks that have to pass since the creation of the output,
+		// before it can be spent by the destination.
+		Delay uint64
+		// RecoveryUnlockHash defines the (public key) unlock hash of the recovery key.
+		RecoveryUnlockHash types.UnlockHash
+		// Condition defines the condition of the destination of the value.
+		Condition types.MarshalableUnlockCondition
+	}
+)
+
+var (
+	_ types.MarshalableUnlockCondition       = (*VaultCondition)(nil)
+	_ types.MarshalableUnlockConditionGetter = (*VaultCondition)(nil)
+	_ types.MarshalableUnlockCondition       = (*ClawbackCondition)(nil)
+	_ types.MarshalableUnlockConditionGetter = (*ClawbackCondition)(nil)
+)
+
+// NewVaultCondition creates a new VaultCondition.
+// If no MarshalableUnlockCondition is given, the NilCondition is assumed.
+func NewVaultCondition(delay uint64, recovery types.UnlockHash, owner types.MarshalableUnlockCondition) *VaultCondition {
+	if owner == nil {
+		owner = &types.NilCondition{}
+	}
+	return &VaultCondition{
+		Delay:              delay,
+		RecoveryUnlockHash: recovery,
+		Condition:          owner,
+	}
+}
+
+// NewClawbackCondition creates a new ClawbackCondition.
+// If no MarshalableUnlockCondition is given, the NilCondition is assumed.
+func NewClawbackCondition(delay uint64, recovery types.UnlockHash, destination types.MarshalableUnlockCondition) *ClawbackCondition {
+	if destination == nil {
+		destination = &types.NilCondition{}
+	}
+	return &ClawbackCondition{
+		Delay:              delay,
+		RecoveryUnlockHash: recovery,
+		Condition:          destination,
+	}
+}
+
+// Fulfill implements UnlockCondition.Fulfill
+//
+// The recovery key can fulfill the condition without restrictions,
+// while the owner can only fulfill it if the transaction sends
+// all value locked by vaults of the same recovery key to restricted outputs.
+func (vc *VaultCondition) Fulfill(fulfillment types.UnlockFulfillment, ctx types.FulfillContext) error {
+	if isRecoveryFulfillment(vc.RecoveryUnlockHash, fulfillment) {
+		return types.NewUnlockHashCondition(vc.RecoveryUnlockHash).Fulfill(fulfillment, ctx)
+	}
+	err := fulfillInternalCondition(vc.Condition, fulfillment, ctx)
+	if err != nil {
+		return err
+	}
+	return vc.validateCovenant(ctx)
+}
+
+// validateCovenant validates that all value locked by vaults of the same recovery key,
+// and spent by the transaction, is sent to outputs restricted by this vault.
+// Comparing against all vaults of the recovery key, rather than only this vault,
+// ensures restricted outputs cannot be counted for multiple vaults at once.
+func (vc *VaultCondition) validateCovenant(ctx types.FulfillContext) error {
+	txn := ctx.Transaction
+	if (len(txn.CoinInputs) > 0 && ctx.SpentCoinOutputs == nil) ||
+		(len(txn.BlockStakeInputs) > 0 && ctx.SpentBlockStakeOutputs == nil) {
+		return ErrCovenantUnverifiable
+	}
+
+	var locked, restricted types.Currency
+	for _, ci := range txn.CoinInputs {
+		co, ok := ctx.SpentCoinOutputs[ci.ParentID]
+		if !ok {
+			return ErrCovenantUnverifiable
+		}
+		if vc.isLockedByRecovery(co.Condition) {
+			locked = locked.Add(co.Value)
+		}
+	}
+	for _, co := range txn.CoinOutputs {
+		if vc.isRestricted(co.Condition) {
+			restricted = restricted.Add(co.Value)
+		}
+	}
+	if restricted.Cmp(locked) < 0 {
+		return ErrCovenantViolated
+	}
+
+	locked, restricted = types.Currency{}, types.Currency{}
+	for _, bsi := range txn.BlockStakeInputs {
+		bso, ok := ctx.SpentBlockStakeOutputs[bsi.ParentID]
+		if !ok {
+			return ErrCovenantUnverifiable
+		}
+		if vc.isLockedByRecovery(bso.Condition) {
+			locked = locked.Add(bso.Value)
+		}
+	}
+	for _, bso := range txn.BlockStakeOutputs {
+		if vc.isRestricted(bso.Condition) {
+			restricted = restricted.Add(bso.Value)
+		}
+	}
+	if restricted.Cmp(locked) < 0 {
+		return ErrCovenantViolated
+	}
+	return nil
+}
+
+// isLockedByRecovery returns true if the given condition is a vault of the same recovery key.
+func (vc *VaultCondition) isLockedByRecovery(condition types.UnlockConditionProxy) bool {
+	ovc, ok := condition.Condition.(*VaultCondition)
+	return ok && ovc.RecoveryUnlockHash.Cmp(vc.RecoveryUnlockHash) == 0
+}
+
+// isRestricted returns true if the given condition is a clawback or vault condition
+// of the same recovery key, with at least the delay of this vault.
+func (vc *VaultCondition) isRestricted(condition types.UnlockConditionProxy) bool {
+	switch tc := condition.Condition.(type) {
+	case *ClawbackCondition:
+		return tc.RecoveryUnlockHash.Cmp(vc.RecoveryUnlockHash) == 0 && tc.Delay >= vc.Delay
+	case *VaultCondition:
+		return tc.RecoveryUnlockHash.Cmp(vc.RecoveryUnlockHash) == 0 && tc.Delay >= vc.Delay
+	default:
+		return false
+	}
+}
+
+// ConditionType implements UnlockCondition.ConditionType
+func (vc *VaultCondition) ConditionType() types.ConditionType {
+	return ConditionTypeVault
+}
+
+// IsStandardCondition implements UnlockCondition.IsStandardCondition
+func (vc *VaultCondition) IsStandardCondition(ctx types.ValidationContext) error {
+	return isStandardCondition(vc.Delay, vc.RecoveryUnlockHash, vc.Condition, ctx)
+}
+
+// UnlockHash implements UnlockCondition.UnlockHash
+func (vc *VaultCondition) UnlockHash() types.UnlockHash {
+	return unlockHash(UnlockTypeVault, vc.Delay, vc.RecoveryUnlockHash, vc.Condition)
+}
+
+// GetMarshalableUnlockCondition implements MarshalableUnlockConditionGetter.GetMarshalableUnlockCondition
+func (vc *VaultCondition) GetMarshalableUnlockCondition() types.MarshalableUnlockCondition {
+	return vc.Condition
+}
+
+// Equal implements UnlockCondition.Equal
+func (vc *VaultCondition) Equal(c types.UnlockCondition) bool {
+	ovc, ok := c.(*VaultCondition)
+	if !ok {
+		return false
+	}
+	return vc.Delay == ovc.Delay && vc.RecoveryUnlockHash.Cmp(ovc.RecoveryUnlockHash) == 0 &&
+		vc.Condition.Equal(ovc.Condition)
+}
+
+// Fulfillable implements UnlockCondition.Fulfillable
+//
+// A vault can always be fulfilled, by either the owner or the recovery key.
+func (vc *VaultCondition) Fulfillable(ctx types.FulfillableContext) bool {
+	return true
+}
+
+// Marshal implements MarshalableUnlockCondition.Marshal
+func (vc *VaultCondition) Marshal(f types.MarshalFunc) ([]byte, error) {
+	return f(vc.Delay, vc.RecoveryUnlockHash, types.NewCondition(vc.Condition))
+}
+
+// Unmarshal implements MarshalableUnlockCondition.Unmarshal
+func (vc *VaultCondition) Unmarshal(b []byte, f types.UnmarshalFunc) (err error) {
+	vc.Condition, err = unmarshalCondition(b, f, &vc.Delay, &vc.RecoveryUnlockHash)
+	return
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (vc *VaultCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonCondition{
+		Delay:              vc.Delay,
+		RecoveryUnlockHash: vc.RecoveryUnlockHash,
+		Condition:          types.NewCondition(vc.Condition),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (vc *VaultCondition) UnmarshalJSON(b []byte) (err error) {
+	vc.Condition, err = unmarshalJSONCondition(b, &vc.Delay, &vc.RecoveryUnlockHash)
+	return
+}
+
+// Fulfill implements UnlockCondition.Fulfill
+//
+// The recovery key can fulfill the condition at any time,
+// while the destination can only fulfill it once the delay has passed.
+func (cc *ClawbackCondition) Fulfill(fulfillment types.UnlockFulfillment, ctx types.FulfillContext) error {
+	if isRecoveryFulfillment(cc.RecoveryUnlockHash, fulfillment) {
+		return types.NewUnlockHashCondition(cc.RecoveryUnlockHash).Fulfill(fulfillment, ctx)
+	}
+	if !cc.Fulfillable(types.FulfillableContext{
+		BlockHeight:          ctx.BlockHeight,
+		BlockTime:            ctx.BlockTime,
+		OutputCreationHeight: ctx.OutputCreationHeight,
+		OutputCreationTime:   ctx.OutputCreationTime,
+		HasOutputCreation:    ctx.HasOutputCreation,
+	}) {
+		return ErrClawbackDelayNotReached
+	}
+	return fulfillInternalCondition(cc.Condition, fulfillment, ctx)
+}
+
+// ConditionType implements UnlockCondition.ConditionType
+func (cc *ClawbackCondition) ConditionType() types.ConditionType {
+	return ConditionTypeClawback
+}
+
+// IsStandardCondition implements UnlockCondition.IsStandardCondition
+func (cc *ClawbackCondition) IsStandardCondition(ctx types.ValidationContext) error {
+	return isStandardCondition(cc.Delay, cc.RecoveryUnlockHash, cc.Condition, ctx)
+}
+
+// UnlockHash implements UnlockCondition.UnlockHash
+func (cc *ClawbackCondition) UnlockHash() types.UnlockHash {
+	return unlockHash(UnlockTypeClawback, cc.Delay, cc.RecoveryUnlockHash, cc.Condition)
+}
+
+// GetMarshalableUnlockCondition implements MarshalableUnlockConditionGetter.GetMarshalableUnlockCondition
+func (cc *ClawbackCondition) GetMarshalableUnlockCondition() types.MarshalableUnlockCondition {
+	return cc.Condition
+}
+
+// Equal implements UnlockCondition.Equal
+func (cc *ClawbackCondition) Equal(c types.UnlockCondition) bool {
+	occ, ok := c.(*ClawbackCondition)
+	if !ok {
+		return false
+	}
+	return cc.Delay == occ.Delay && cc.RecoveryUnlockHash.Cmp(occ.RecoveryUnlockHash) == 0 &&
+		cc.Condition.Equal(occ.Condition)
+}
+
+// Fulfillable implements UnlockCondition.Fulfillable
+//
+// Returns true if the destination can fulfill the condition,
+// as the recovery key can always fulfill it. The creation of the output is taken from the given context,
+// the destination can never fulfill the condition within a context which does not define it.
+func (cc *ClawbackCondition) Fulfillable(ctx types.FulfillableContext) bool {
+	if !ctx.HasOutputCreation {
+		return false
+	}
+	// compare without adding the delay to the creation height, as that addition can overflow
+	return ctx.BlockHeight >= ctx.OutputCreationHeight &&
+		uint64(ctx.BlockHeight-ctx.OutputCreationHeight) >= cc.Delay
+}
+
+// Marshal implements MarshalableUnlockCondition.Marshal
+func (cc *ClawbackCondition) Marshal(f types.MarshalFunc) ([]byte, error) {
+	return f(cc.Delay, cc.RecoveryUnlockHash, types.NewCondition(cc.Condition))
+}
+
+// Unmarshal implements MarshalableUnlockCondition.Unmarshal
+func (cc *ClawbackCondition) Unmarshal(b []byte, f types.UnmarshalFunc) (err error) {
+	cc.Condition, err = unmarshalCondition(b, f, &cc.Delay, &cc.RecoveryUnlockHash)
+	return
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (cc *ClawbackCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonCondition{
+		Delay:              cc.Delay,
+		RecoveryUnlockHash: cc.RecoveryUnlockHash,
+		Condition:          types.NewCondition(cc.Condition),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (cc *ClawbackCondition) UnmarshalJSON(b []byte) (err error) {
+	cc.Condition, err = unmarshalJSONCondition(b, &cc.Delay, &cc.RecoveryUnlockHash)
+	return
+}
+
+// isRecoveryFulfillment returns true if the fulfillment is a single signature fulfillment,
+// of which the public key is linked to the recovery unlock hash.
+func isRecoveryFulfillment(recovery types.UnlockHash, fulfillment types.UnlockFulfillment) bool {
+	ss, ok := fulfillment.(*types.SingleSignatureFulfillment)
+	if !ok {
+		return false
+	}
+	uh, err := types.NewPubKeyUnlockHash(ss.PublicKey)
+	return err == nil && uh.Cmp(recovery) == 0
+}
+
+// fulfillInternalCondition delegates the fulfillment to the internal (owner or destination) condition,
+// if the fulfillment is supported.
+func fulfillInternalCondition(condition types.MarshalableUnlockCondition, fulfillment types.UnlockFulfillment, ctx types.FulfillContext) error {
+	switch tf := fulfillment.(type) {
+	case *types.SingleSignatureFulfillment:
+		return condition.Fulfill(tf, ctx)
+	case *types.MultiSignatureFulfillment:
+		return condition.Fulfill(tf, ctx)
+	default:
+		return types.ErrUnexpectedUnlockFulfillment
+	}
+}
+
+func isStandardCondition(delay uint64, recovery types.UnlockHash, condition types.MarshalableUnlockCondition, ctx types.ValidationContext) error {
+	if delay == 0 {
+		return errors.New("delay has to be defined")
+	}
+	if delay > MaxDelay {
+		return fmt.Errorf("delay cannot exceed %d blocks", MaxDelay)
+	}
+	if recovery.Hash == (crypto.Hash{}) {
+		return errors.New("nil crypto hash cannot be used as recovery unlock hash")
+	}
+	if recovery.Type != types.UnlockTypePubKey {
+		return errors.New("non-standard recovery unlock hash type")
+	}
+	switch ct := condition.ConditionType(); ct {
+	case types.ConditionTypeUnlockHash:
+		uh := condition.UnlockHash()
+		if uh.Hash == (crypto.Hash{}) {
+			return errors.New("nil crypto hash cannot be used as unlock hash")
+		}
+		if uh.Type != types.UnlockTypePubKey {
+			return errors.New("non-standard unlock hash type")
+		}
+		return nil
+	case types.ConditionTypeMultiSignature:
+		return condition.IsStandardCondition(ctx)
+	default:
+		return fmt.Errorf("unexpected internal unlock condition type %d", ct)
+	}
+}
+
+func unlockHash(ut types.UnlockType, delay uint64, recovery types.UnlockHash, condition types.MarshalableUnlockCondition) types.UnlockHash {
+	h, err := crypto.HashAll(delay, recovery, types.NewCondition(condition))
+	if err != nil {
+		return types.NilUnlockHash
+	}
+	return types.NewUnlockHash(ut, h)
+}
+
+func unmarshalCondition(b []byte, f types.UnmarshalFunc, delay *uint64, recovery *types.UnlockHash) (types.MarshalableUnlockCondition, error) {
+	var condition types.UnlockConditionProxy
+	err := f(b, delay, recovery, &condition)
+	if err != nil {
+		return nil, err
+	}
+	if condition.Condition == nil {
+		return &types.NilCondition{}, nil
+	}
+	return condition.Condition, nil
+}
+
+type jsonCondition struct {
+	Delay              uint64                     `json:"delay"`
+	RecoveryUnlockHash types.UnlockHash           `json:"recovery"`
+	Condition          types.UnlockConditionProxy `json:"condition"`
+}
+
+func unmarshalJSONCondition(b []byte, delay *uint64, recovery *types.UnlockHash) (types.MarshalableUnlockCondition, error) {
+	var jc jsonCondition
+	err := json.Unmarshal(b, &jc)
+	if err != nil {
+		return nil, err
+	}
+	*delay = jc.Delay
+	*recovery = jc.RecoveryUnlockHash
+	if jc.Condition.Condition == nil {
+		return &types.NilCondition{}, nil
+	}
+	return jc.Condition.Condition, nil
+}
@@ -0,0 +1,313 @@
+package vault
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type testKey struct {
+	sk crypto.SecretKey
+	pk types.PublicKey
+	uh types.UnlockHash
+}
+
+func newTestKey(t *testing.T) testKey {
+	sk, pk := crypto.GenerateKeyPair()
+	key := testKey{sk: sk, pk: types.Ed25519PublicKey(pk)}
+	var err error
+	key.uh, err = types.NewPubKeyUnlockHash(key.pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestConditionEncoding(t *testing.T) {
+	RegisterUnlockConditionTypes()
+	defer UnregisterUnlockConditionTypes()
+
+	owner, recovery := newTestKey(t), newTestKey(t)
+	for _, c := range []types.MarshalableUnlockCondition{
+		NewVaultCondition(144, recovery.uh, types.NewUnlockHashCondition(owner.uh)),
+		NewClawbackCondition(144, recovery.uh, types.NewUnlockHashCondition(owner.uh)),
+		NewVaultCondition(1, recovery.uh, types.NewMultiSignatureCondition(types.UnlockHashSlice{owner.uh, recovery.uh}, 1)),
+	} {
+		condition := types.NewCondition(c)
+		if err := condition.IsStandardCondition(types.ValidationContext{}); err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := json.Marshal(condition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var jsonCondition types.UnlockConditionProxy
+		if err = json.Unmarshal(b, &jsonCondition); err != nil {
+			t.Fatal(err)
+		}
+		b, err = siabin.Marshal(condition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var siaCondition types.UnlockConditionProxy
+		if err = siabin.Unmarshal(b, &siaCondition); err != nil {
+			t.Fatal(err)
+		}
+		b, err = rivbin.Marshal(condition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rivCondition types.UnlockConditionProxy
+		if err = rivbin.Unmarshal(b, &rivCondition); err != nil {
+			t.Fatal(err)
+		}
+		for _, decoded := range []types.UnlockConditionProxy{jsonCondition, siaCondition, rivCondition} {
+			if !condition.Equal(decoded) {
+				t.Errorf("%v != %v", decoded, condition)
+			}
+		}
+	}
+
+	// vaults and clawbacks of the same properties have a different address
+	vaultUH := NewVaultCondition(144, recovery.uh, types.NewUnlockHashCondition(owner.uh)).UnlockHash()
+	clawbackUH := NewClawbackCondition(144, recovery.uh, types.NewUnlockHashCondition(owner.uh)).UnlockHash()
+	if vaultUH.Type != UnlockTypeVault || clawbackUH.Type != UnlockTypeClawback || vaultUH.Hash != clawbackUH.Hash {
+		t.Errorf("unexpected unlock hashes: %v and %v", vaultUH, clawbackUH)
+	}
+
+	for _, c := range []types.MarshalableUnlockCondition{
+		NewVaultCondition(0, recovery.uh, types.NewUnlockHashCondition(owner.uh)),
+		NewVaultCondition(144, types.NilUnlockHash, types.NewUnlockHashCondition(owner.uh)),
+		NewVaultCondition(144, recovery.uh, nil),
+		NewClawbackCondition(144, vaultUH, types.NewUnlockHashCondition(owner.uh)),
+		NewClawbackCondition(144, recovery.uh, types.NewTimeLockCondition(42, types.NewUnlockHashCondition(owner.uh))),
+	} {
+		if err := c.IsStandardCondition(types.ValidationContext{}); err == nil {
+			t.Errorf("expected %v to be non-standard", c)
+		}
+	}
+}
+
+func TestVaultConditionFulfill(t *testing.T) {
+	owner, recovery, thief := newTestKey(t), newTestKey(t), newTestKey(t)
+	vault := NewVaultCondition(100, recovery.uh, types.NewUnlockHashCondition(owner.uh))
+	otherVault := NewVaultCondition(100, recovery.uh, types.NewUnlockHashCondition(thief.uh))
+	spentCoinOutputs := map[types.CoinOutputID]types.CoinOutput{
+		{1}: {Value: types.NewCurrency64(10), Condition: types.NewCondition(vault)},
+		{2}: {Value: types.NewCurrency64(10), Condition: types.NewCondition(otherVault)},
+		{3}: {Value: types.NewCurrency64(1), Condition: types.NewCondition(types.NewUnlockHashCondition(owner.uh))},
+	}
+
+	// fulfill spends the vault output {1} (and optionally other outputs), signed by the given key,
+	// sending the value to the given outputs
+	fulfill := func(key testKey, outputs []types.CoinOutput, spentCoinOutputs map[types.CoinOutputID]types.CoinOutput, otherInputs ...types.CoinOutputID) error {
+		txn := types.Transaction{
+			Version:     types.TestnetChainConstants().DefaultTransactionVersion,
+			CoinInputs:  []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+			CoinOutputs: outputs,
+			MinerFees:   []types.Currency{types.NewCurrency64(1)},
+		}
+		for _, id := range otherInputs {
+			txn.CoinInputs = append(txn.CoinInputs, types.CoinInput{ParentID: id})
+		}
+		fulfillment := types.NewSingleSignatureFulfillment(key.pk)
+		err := fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: []interface{}{uint64(0)},
+			Transaction:  txn,
+			Key:          key.sk,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vault.Fulfill(fulfillment, types.FulfillContext{
+			ExtraObjects:     []interface{}{uint64(0)},
+			BlockHeight:      42,
+			Transaction:      txn,
+			SpentCoinOutputs: spentCoinOutputs,
+		})
+	}
+	output := func(value uint64, condition types.MarshalableUnlockCondition) types.CoinOutput {
+		return types.CoinOutput{Value: types.NewCurrency64(value), Condition: types.NewCondition(condition)}
+	}
+	ownerCondition := types.NewUnlockHashCondition(owner.uh)
+	thiefCondition := types.NewUnlockHashCondition(thief.uh)
+
+	testCases := []struct {
+		Description   string
+		Key           testKey
+		Outputs       []types.CoinOutput
+		OtherInputs   []types.CoinOutputID
+		ExpectedError error
+	}{
+		{
+			"owner sends the value to a clawback output, paying the fee using another input",
+			owner, []types.CoinOutput{output(10, NewClawbackCondition(100, recovery.uh, ownerCondition))},
+			[]types.CoinOutputID{{3}}, nil,
+		},
+		{
+			"owner sends part of the value back to the vault",
+			owner, []types.CoinOutput{
+				output(4, NewClawbackCondition(200, recovery.uh, ownerCondition)),
+				output(6, vault),
+			},
+			[]types.CoinOutputID{{3}}, nil,
+		},
+		{
+			"owner pays the fee using the value of the vault",
+			owner, []types.CoinOutput{output(9, NewClawbackCondition(100, recovery.uh, ownerCondition))},
+			nil, ErrCovenantViolated,
+		},
+		{
+			"owner sends the value to an unrestricted output",
+			owner, []types.CoinOutput{output(10, thiefCondition)},
+			[]types.CoinOutputID{{3}}, ErrCovenantViolated,
+		},
+		{
+			"owner sends the value to a clawback output with a shorter delay",
+			owner, []types.CoinOutput{output(10, NewClawbackCondition(99, recovery.uh, thiefCondition))},
+			[]types.CoinOutputID{{3}}, ErrCovenantViolated,
+		},
+		{
+			"owner sends the value to a clawback output of another recovery key",
+			owner, []types.CoinOutput{output(10, NewClawbackCondition(100, thief.uh, thiefCondition))},
+			[]types.CoinOutputID{{3}}, ErrCovenantViolated,
+		},
+		{
+			"restricted outputs cannot be counted for multiple vaults",
+			owner, []types.CoinOutput{
+				output(10, NewClawbackCondition(100, recovery.uh, ownerCondition)),
+				output(10, thiefCondition),
+			},
+			[]types.CoinOutputID{{2}, {3}}, ErrCovenantViolated,
+		},
+		{
+			"recovery key sends the value anywhere",
+			recovery, []types.CoinOutput{output(9, thiefCondition)},
+			nil, nil,
+		},
+	}
+	for _, testCase := range testCases {
+		err := fulfill(testCase.Key, testCase.Outputs, spentCoinOutputs, testCase.OtherInputs...)
+		if err != testCase.ExpectedError {
+			t.Errorf("%s: expected error %v, got: %v", testCase.Description, testCase.ExpectedError, err)
+		}
+	}
+
+	// the thief cannot spend the vault, and the covenant cannot be verified without the spent outputs
+	if err := fulfill(thief, []types.CoinOutput{output(10, thiefCondition)}, spentCoinOutputs, types.CoinOutputID{3}); err == nil {
+		t.Error("expected fulfillment by another key to fail")
+	}
+	if err := fulfill(owner, []types.CoinOutput{output(10, NewClawbackCondition(100, recovery.uh, ownerCondition))}, nil, types.CoinOutputID{3}); err != ErrCovenantUnverifiable {
+		t.Errorf("expected error %v, got: %v", ErrCovenantUnverifiable, err)
+	}
+}
+
+func TestClawbackConditionFulfill(t *testing.T) {
+	destination, recovery, thief := newTestKey(t), newTestKey(t), newTestKey(t)
+	clawback := NewClawbackCondition(100, recovery.uh, types.NewUnlockHashCondition(destination.uh))
+	txn := types.Transaction{
+		Version:    types.TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+	}
+	sign := func(key testKey) types.UnlockFulfillment {
+		fulfillment := types.NewSingleSignatureFulfillment(key.pk)
+		err := fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: []interface{}{uint64(0)},
+			Transaction:  txn,
+			Key:          key.sk,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fulfillment
+	}
+
+	testCases := []struct {
+		Key           testKey
+		BlockHeight   types.BlockHeight
+		ExpectedError error
+	}{
+		{destination, 149, ErrClawbackDelayNotReached},
+		{destination, 150, nil},
+		{recovery, 50, nil},
+		{thief, 149, ErrClawbackDelayNotReached},
+	}
+	for idx, testCase := range testCases {
+		err := clawback.Fulfill(sign(testCase.Key), types.FulfillContext{
+			ExtraObjects:         []interface{}{uint64(0)},
+			BlockHeight:          testCase.BlockHeight,
+			OutputCreationHeight: 50,
+			HasOutputCreation:    true,
+			Transaction:          txn,
+		})
+		if err != testCase.ExpectedError {
+			t.Errorf("test case #%d: expected error %v, got: %v", idx, testCase.ExpectedError, err)
+		}
+	}
+	err := clawback.Fulfill(sign(thief), types.FulfillContext{
+		ExtraObjects:         []interface{}{uint64(0)},
+		BlockHeight:          150,
+		OutputCreationHeight: 50,
+		HasOutputCreation:    true,
+		Transaction:          txn,
+	})
+	if err == nil {
+		t.Error("expected fulfillment by another key to fail")
+	}
+
+	// without the creation of the output the delay is never reached,
+	// while the recovery key can still fulfill the condition
+	for _, testCase := range []struct {
+		Key           testKey
+		ExpectedError error
+	}{
+		{destination, ErrClawbackDelayNotReached},
+		{recovery, nil},
+	} {
+		err = clawback.Fulfill(sign(testCase.Key), types.FulfillContext{
+			ExtraObjects: []interface{}{uint64(0)},
+			BlockHeight:  math.MaxUint64,
+			Transaction:  txn,
+		})
+		if err != testCase.ExpectedError {
+			t.Errorf("expected error %v without output creation, got: %v", testCase.ExpectedError, err)
+		}
+	}
+	if clawback.Fulfillable(types.FulfillableContext{BlockHeight: math.MaxUint64}) {
+		t.Error("expected clawback to be unfulfillable without output creation")
+	}
+}
+
+func TestClawbackConditionDelayOverflow(t *testing.T) {
+	destination, recovery := newTestKey(t), newTestKey(t)
+
+	// a delay which overflows when added to the creation height
+	// has to lock the output forever, rather than unlock it immediately
+	clawback := NewClawbackCondition(math.MaxUint64, recovery.uh, types.NewUnlockHashCondition(destination.uh))
+	if clawback.Fulfillable(types.FulfillableContext{BlockHeight: 51, OutputCreationHeight: 50, HasOutputCreation: true}) {
+		t.Error("expected clawback with an overflowing delay to be unfulfillable")
+	}
+	if clawback.Fulfillable(types.FulfillableContext{BlockHeight: 40, OutputCreationHeight: 50, HasOutputCreation: true}) {
+		t.Error("expected clawback created after the current height to be unfulfillable")
+	}
+
+	// the owner of a vault can send to such a clawback condition,
+	// but it is not a standard condition
+	vault := NewVaultCondition(100, recovery.uh, types.NewUnlockHashCondition(destination.uh))
+	if !vault.isRestricted(types.NewCondition(clawback)) {
+		t.Error("expected clawback with a longer delay to be restricted")
+	}
+	if err := clawback.IsStandardCondition(types.ValidationContext{}); err == nil {
+		t.Error("expected clawback with a delay exceeding the maximum delay to be non-standard")
+	}
+	clawback.Delay = MaxDelay
+	if err := clawback.IsStandardCondition(types.ValidationContext{}); err != nil {
+		t.Errorf("expected clawback with the maximum delay to be standard: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// AtomicSwapDir is the name of the directory that is used to store
+	// the persistent data of the atomic swap manager.
+	AtomicSwapDir = "atomicswap"
+)
+
+var (
+	// ErrAtomicSwapNotTracked is returned in case an atomic swap contract
+	// is not tracked by the atomic swap manager.
+	ErrAtomicSwapNotTracked = errors.New("atomic swap contract is not tracked")
+	// ErrAtomicSwapOrderNotFound is returned in case an order does not exist
+	// in the orderbook of the atomic swap manager.
+	ErrAtomicSwapOrderNotFound = errors.New("atomic swap order not found")
+)
+
+// The roles the wallet can have in an atomic swap contract.
+const (
+	// AtomicSwapRoleSender is the role of the wallet in a contract it funded,
+	// which it can refund once the time lock of the contract has been reached.
+	AtomicSwapRoleSender AtomicSwapRole = iota + 1
+	// AtomicSwapRoleReceiver is the role of the wallet in a contract funded by the counterparty,
+	// which it can redeem using the secret.
+	AtomicSwapRoleReceiver
+)
+
+// The statuses of an atomic swap contract.
+const (
+	// AtomicSwapStatusPending is the status of a contract which has not been spent yet.
+	AtomicSwapStatusPending AtomicSwapStatus = iota
+	// AtomicSwapStatusRedeemed is the status of a contract redeemed by its receiver.
+	AtomicSwapStatusRedeemed
+	// AtomicSwapStatusRefunded is the status of a contract refunded to its sender.
+	AtomicSwapStatusRefunded
+)
+
+// The statuses of an atomic swap order.
+const (
+	// AtomicSwapOrderStatusOpen is the status of an order which has not been filled (or cancelled) yet.
+	AtomicSwapOrderStatusOpen AtomicSwapOrderStatus = iota
+	// AtomicSwapOrderStatusFilled is the status of an order for which an atomic swap contract has been tracked.
+	AtomicSwapOrderStatusFilled
+	// AtomicSwapOrderStatusCancelled is the status of a cancelled order.
+	AtomicSwapOrderStatusCancelled
+)
+
+type (
+	// AtomicSwapRole defines the role of the wallet in an atomic swap contract.
+	AtomicSwapRole uint8
+
+	// AtomicSwapStatus defines the status of an atomic swap contract.
+	AtomicSwapStatus uint8
+
+	// AtomicSwapOrderStatus defines the status of an atomic swap order.
+	AtomicSwapOrderStatus uint8
+
+	// AtomicSwap is an atomic swap contract tracked by the atomic swap manager.
+	AtomicSwap struct {
+		// OutputID is the ID of the coin output locked by the contract.
+		OutputID types.CoinOutputID        `json:"outputid"`
+		Contract types.AtomicSwapCondition `json:"contract"`
+		Value    types.Currency            `json:"value"`
+		Role     AtomicSwapRole            `json:"role"`
+		Status   AtomicSwapStatus          `json:"status"`
+		// Secret is defined as soon as the secret of the contract is known,
+		// either because it was given or because it was extracted from the chain.
+		Secret *types.AtomicSwapSecret `json:"secret,omitempty"`
+		// SpendTransactionID is the ID of the transaction which spent the contract,
+		// and is only defined for redeemed and refunded contracts.
+		SpendTransactionID *types.TransactionID `json:"spendtransactionid,omitempty"`
+		// OrderID is the ID of the order the contract fills, if any.
+		OrderID uint64 `json:"orderid,omitempty"`
+	}
+
+	// AtomicSwapOrder is an order of the (local) orderbook of the atomic swap manager,
+	// offering coins of this chain in exchange for an asset of another chain.
+	AtomicSwapOrder struct {
+		ID uint64 `json:"id"`
+		// Amount of coins offered.
+		Amount types.Currency `json:"amount"`
+		// Asset and AssetAmount define what is asked in return, e.g. "BTC" and "0.1".
+		Asset       string `json:"asset"`
+		AssetAmount string `json:"assetamount"`
+		// Counterparty is the (optional) address the order is reserved for.
+		Counterparty types.UnlockHash      `json:"counterparty"`
+		Status       AtomicSwapOrderStatus `json:"status"`
+		Created      types.Timestamp       `json:"created"`
+		// OutputIDs are the IDs of the atomic swap contracts which fill this order.
+		OutputIDs []types.CoinOutputID `json:"outputids,omitempty"`
+	}
+
+	// AtomicSwapManager tracks the atomic swap contracts in which the wallet is a party,
+	// redeeming them as soon as their secret is known, and refunding them
+	// once their time lock has been reached. It also keeps a local orderbook.
+	AtomicSwapManager interface {
+		io.Closer
+
+		// Track starts tracking the (unspent) atomic swap contract locking the given coin output,
+		// in which the wallet has to own either the sender or receiver key.
+		// The secret can optionally be given, in case it is already known.
+		Track(id types.CoinOutputID, secret *types.AtomicSwapSecret) (AtomicSwap, error)
+
+		// Swaps returns all tracked atomic swap contracts.
+		Swaps() ([]AtomicSwap, error)
+
+		// Swap returns a tracked atomic swap contract.
+		Swap(id types.CoinOutputID) (AtomicSwap, error)
+
+		// Orders returns all orders of the orderbook.
+		Orders() ([]AtomicSwapOrder, error)
+
+		// Order returns an order of the orderbook.
+		Order(id uint64) (AtomicSwapOrder, error)
+
+		// PlaceOrder adds a new (open) order to the orderbook.
+		PlaceOrder(order AtomicSwapOrder) (AtomicSwapOrder, error)
+
+		// CancelOrder cancels an open order of the orderbook.
+		CancelOrder(id uint64) error
+
+		// FillOrder tracks the atomic swap contract locking the given coin output,
+		// and marks the order as filled by it.
+		FillOrder(id uint64, outputID types.CoinOutputID, secret *types.AtomicSwapSecret) (AtomicSwap, error)
+	}
+)
+
+// String returns the role as a string.
+func (role AtomicSwapRole) String() string {
+	switch role {
+	case AtomicSwapRoleSender:
+		return "sender"
+	case AtomicSwapRoleReceiver:
+		return "receiver"
+	default:
+		return fmt.Sprintf("unknown role %d", role)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (role AtomicSwapRole) MarshalJSON() ([]byte, error) {
+	switch role {
+	case AtomicSwapRoleSender, AtomicSwapRoleReceiver:
+		return []byte(`"` + role.String() + `"`), nil
+	default:
+		return nil, fmt.Errorf("cannot marshal unknown atomic swap role %d", role)
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (role *AtomicSwapRole) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case `"sender"`:
+		*role = AtomicSwapRoleSender
+	case `"receiver"`:
+		*role = AtomicSwapRoleReceiver
+	default:
+		return fmt.Errorf("unknown atomic swap role %s", string(b))
+	}
+	return nil
+}
+
+// String returns the status as a string.
+func (status AtomicSwapStatus) String() string {
+	switch status {
+	case AtomicSwapStatusPending:
+		return "pending"
+	case AtomicSwapStatusRedeemed:
+		return "redeemed"
+	case AtomicSwapStatusRefunded:
+		return "refunded"
+	default:
+		return fmt.Sprintf("unknown status %d", status)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (status AtomicSwapStatus) MarshalJSON() ([]byte, error) {
+	if status > AtomicSwapStatusRefunded {
+		return nil, fmt.Errorf("cannot marshal unknown atomic swap status %d", status)
+	}
+	return []byte(`"` + status.String() + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (status *AtomicSwapStatus) UnmarshalJSON(b []byte) error {
+	for candidate := AtomicSwapStatusPending; candidate <= AtomicSwapStatusRefunded; candidate++ {
+		if string(b) == `"`+candidate.String()+`"` {
+			*status = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown atomic swap status %s", string(b))
+}
+
+// String returns the order status as a string.
+func (status AtomicSwapOrderStatus) String() string {
+	switch status {
+	case AtomicSwapOrderStatusOpen:
+		return "open"
+	case AtomicSwapOrderStatusFilled:
+		return "filled"
+	case AtomicSwapOrderStatusCancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("unknown status %d", status)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (status AtomicSwapOrderStatus) MarshalJSON() ([]byte, error) {
+	if status > AtomicSwapOrderStatusCancelled {
+		return nil, fmt.Errorf("cannot marshal unknown atomic swap order status %d", status)
+	}
+	return []byte(`"` + status.String() + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (status *AtomicSwapOrderStatus) UnmarshalJSON(b []byte) error {
+	for candidate := AtomicSwapOrderStatusOpen; candidate <= AtomicSwapOrderStatusCancelled; candidate++ {
+		if string(b) == `"`+candidate.String()+`"` {
+			*status = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown atomic swap order status %s", string(b))
+}
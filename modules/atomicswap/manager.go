@@ -0,0 +1,251 @@
+// Package atomicswap provides the atomic swap manager, which keeps track
+// of the atomic swap contracts in which the wallet is a party:
+// it extracts the secret of a swap as soon as the counterparty claimed its coins,
+// redeems the contracts of which the secret is known, and refunds the contracts
+// of which the time lock has been reached. It also provides a local orderbook.
+package atomicswap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	siasync "github.com/threefoldtech/rivine/sync"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	errNilCS     = errors.New("atomic swap manager cannot use a nil consensus set")
+	errNilTpool  = errors.New("atomic swap manager cannot use a nil transaction pool")
+	errNilWallet = errors.New("atomic swap manager cannot use a nil wallet")
+)
+
+// Manager tracks the atomic swap contracts in which the wallet is a party,
+// and implements modules.AtomicSwapManager.
+type Manager struct {
+	cs        modules.ConsensusSet
+	tpool     modules.TransactionPool
+	wallet    modules.Wallet
+	txVersion types.TransactionVersion
+	minerFee  types.Currency
+
+	persistDir string
+	bcInfo     types.BlockchainInfo
+	log        *persist.Logger
+
+	// swaps contains all tracked contracts, while orders contains the orderbook.
+	// blockTime is the timestamp of the most recent block, used to know which contracts can be refunded.
+	swaps        map[types.CoinOutputID]*modules.AtomicSwap
+	orders       map[uint64]*modules.AtomicSwapOrder
+	nextOrderID  uint64
+	recentChange modules.ConsensusChangeID
+	blockTime    types.Timestamp
+	mu           sync.Mutex
+
+	changes chan struct{}
+	tg      siasync.ThreadGroup
+}
+
+// New creates a new atomic swap manager, loading the persisted contracts and orders,
+// and subscribing to consensus in order to watch the tracked contracts.
+func New(cs modules.ConsensusSet, tpool modules.TransactionPool, wallet modules.Wallet, txVersion types.TransactionVersion, minerFee types.Currency, persistDir string, bcInfo types.BlockchainInfo, verboseLogging bool) (*Manager, error) {
+	// Check that input modules are non-nil
+	if cs == nil {
+		return nil, errNilCS
+	}
+	if tpool == nil {
+		return nil, errNilTpool
+	}
+	if wallet == nil {
+		return nil, errNilWallet
+	}
+
+	m := &Manager{
+		cs:           cs,
+		tpool:        tpool,
+		wallet:       wallet,
+		txVersion:    txVersion,
+		minerFee:     minerFee,
+		persistDir:   persistDir,
+		bcInfo:       bcInfo,
+		swaps:        make(map[types.CoinOutputID]*modules.AtomicSwap),
+		orders:       make(map[uint64]*modules.AtomicSwapOrder),
+		nextOrderID:  1,
+		recentChange: modules.ConsensusChangeRecent,
+		blockTime:    cs.CurrentBlock().Timestamp,
+		changes:      make(chan struct{}, 1),
+	}
+
+	// Initialize the persistent structures.
+	err := m.initPersist(verboseLogging)
+	if err != nil {
+		return nil, err
+	}
+
+	// watch the tracked contracts in a separate thread,
+	// as redeeming and refunding requires the consensus set, wallet and transaction pool
+	go m.threadedWatchSwaps()
+
+	// continue from the last processed change, such that no secret is missed while the daemon was offline
+	err = cs.ConsensusSetSubscribe(m, m.recentChange, nil)
+	if err == modules.ErrInvalidConsensusChangeID {
+		// Reset and rescan because the consensus set does not recognize the
+		// provided consensus change id.
+		m.log.Println("[WARN] Unknown consensus change, rescanning the tracked atomic swap contracts from the start of the chain")
+		m.mu.Lock()
+		resetErr := m.resetConsensusState()
+		m.mu.Unlock()
+		if resetErr != nil {
+			return nil, resetErr
+		}
+		err = cs.ConsensusSetSubscribe(m, modules.ConsensusChangeBeginning, nil)
+	}
+	if err != nil {
+		return nil, errors.New("atomic swap manager subscription failed: " + err.Error())
+	}
+	m.signalChange()
+	return m, nil
+}
+
+// Close unsubscribes the manager from consensus and stops watching the tracked contracts.
+func (m *Manager) Close() error {
+	m.cs.Unsubscribe(m)
+	err := m.tg.Stop()
+	if err != nil {
+		return err
+	}
+	if m.log != nil {
+		err = m.log.Close()
+		if err != nil {
+			// State of the logger is unknown, a println will suffice.
+			fmt.Println("Error shutting down atomic swap manager logger:", err)
+		}
+	}
+	return nil
+}
+
+// Track implements modules.AtomicSwapManager.Track
+func (m *Manager) Track(id types.CoinOutputID, secret *types.AtomicSwapSecret) (modules.AtomicSwap, error) {
+	swap, err := m.track(id, secret, 0)
+	if err != nil {
+		return modules.AtomicSwap{}, err
+	}
+	m.signalChange()
+	return swap, nil
+}
+
+// Swaps implements modules.AtomicSwapManager.Swaps
+func (m *Manager) Swaps() ([]modules.AtomicSwap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	swaps := make([]modules.AtomicSwap, 0, len(m.swaps))
+	for _, swap := range m.swaps {
+		swaps = append(swaps, *swap)
+	}
+	sort.Slice(swaps, func(i, j int) bool {
+		return bytes.Compare(swaps[i].OutputID[:], swaps[j].OutputID[:]) < 0
+	})
+	return swaps, nil
+}
+
+// Swap implements modules.AtomicSwapManager.Swap
+func (m *Manager) Swap(id types.CoinOutputID) (modules.AtomicSwap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	swap, ok := m.swaps[id]
+	if !ok {
+		return modules.AtomicSwap{}, modules.ErrAtomicSwapNotTracked
+	}
+	return *swap, nil
+}
+
+// track starts tracking the contract locking the given coin output,
+// linking it to the given order if the order ID is not 0.
+func (m *Manager) track(id types.CoinOutputID, secret *types.AtomicSwapSecret, orderID uint64) (modules.AtomicSwap, error) {
+	if err := m.tg.Add(); err != nil {
+		return modules.AtomicSwap{}, err
+	}
+	defer m.tg.Done()
+
+	co, err := m.cs.GetCoinOutput(id)
+	if err != nil {
+		return modules.AtomicSwap{}, fmt.Errorf("failed to get unspent coin output %s: %v", id.String(), err)
+	}
+	contract, ok := co.Condition.Condition.(*types.AtomicSwapCondition)
+	if !ok {
+		return modules.AtomicSwap{}, fmt.Errorf("coin output %s is not locked by an atomic swap contract", id.String())
+	}
+	if secret != nil && types.NewAtomicSwapHashedSecret(*secret) != contract.HashedSecret {
+		return modules.AtomicSwap{}, errors.New("secret does not match the hashed secret of the atomic swap contract")
+	}
+	var role modules.AtomicSwapRole
+	if _, _, err := m.wallet.GetKey(contract.Receiver); err == nil {
+		role = modules.AtomicSwapRoleReceiver
+	} else if _, _, err := m.wallet.GetKey(contract.Sender); err == nil {
+		role = modules.AtomicSwapRoleSender
+	} else {
+		return modules.AtomicSwap{}, fmt.Errorf("wallet owns neither the sender nor the receiver key of atomic swap contract %s", id.String())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if orderID != 0 {
+		order, ok := m.orders[orderID]
+		if !ok {
+			return modules.AtomicSwap{}, modules.ErrAtomicSwapOrderNotFound
+		}
+		if order.Status == modules.AtomicSwapOrderStatusCancelled {
+			return modules.AtomicSwap{}, fmt.Errorf("atomic swap order %d is cancelled", orderID)
+		}
+		order.Status = modules.AtomicSwapOrderStatusFilled
+		order.OutputIDs = append(order.OutputIDs, id)
+	}
+	swap, ok := m.swaps[id]
+	if !ok {
+		swap = &modules.AtomicSwap{
+			OutputID: id,
+			Contract: *contract,
+			Value:    co.Value,
+			Role:     role,
+			Status:   modules.AtomicSwapStatusPending,
+		}
+		m.swaps[id] = swap
+		m.log.Printf("tracking atomic swap contract %s as %s", id.String(), role.String())
+	}
+	if orderID != 0 {
+		swap.OrderID = orderID
+	}
+	if secret == nil {
+		secret = m.knownSecret(contract.HashedSecret)
+	}
+	if swap.Secret == nil && secret != nil {
+		s := *secret
+		swap.Secret = &s
+	}
+	return *swap, m.saveSync()
+}
+
+// knownSecret returns the secret of the given hashed secret,
+// in case it is known for any tracked contract.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) knownSecret(hashedSecret types.AtomicSwapHashedSecret) *types.AtomicSwapSecret {
+	for _, swap := range m.swaps {
+		if swap.Secret != nil && swap.Contract.HashedSecret == hashedSecret {
+			return swap.Secret
+		}
+	}
+	return nil
+}
+
+// signalChange signals the watcher that the tracked contracts have to be checked.
+func (m *Manager) signalChange() {
+	select {
+	case m.changes <- struct{}{}:
+	default:
+		// a check is already pending
+	}
+}
@@ -0,0 +1,89 @@
+package atomicswap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// Orders implements modules.AtomicSwapManager.Orders
+func (m *Manager) Orders() ([]modules.AtomicSwapOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orders := make([]modules.AtomicSwapOrder, 0, len(m.orders))
+	for _, order := range m.orders {
+		orders = append(orders, *order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].ID < orders[j].ID
+	})
+	return orders, nil
+}
+
+// Order implements modules.AtomicSwapManager.Order
+func (m *Manager) Order(id uint64) (modules.AtomicSwapOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	order, ok := m.orders[id]
+	if !ok {
+		return modules.AtomicSwapOrder{}, modules.ErrAtomicSwapOrderNotFound
+	}
+	return *order, nil
+}
+
+// PlaceOrder implements modules.AtomicSwapManager.PlaceOrder
+//
+// The ID, status and creation time of the given order are ignored.
+func (m *Manager) PlaceOrder(order modules.AtomicSwapOrder) (modules.AtomicSwapOrder, error) {
+	if order.Amount.IsZero() {
+		return modules.AtomicSwapOrder{}, errors.New("atomic swap order has to offer a non-zero amount of coins")
+	}
+	if order.Asset == "" || order.AssetAmount == "" {
+		return modules.AtomicSwapOrder{}, errors.New("atomic swap order has to define the asked asset and amount")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	order.ID = m.nextOrderID
+	order.Status = modules.AtomicSwapOrderStatusOpen
+	order.Created = types.CurrentTimestamp()
+	order.OutputIDs = nil
+	m.nextOrderID++
+	m.orders[order.ID] = &order
+	err := m.saveSync()
+	if err != nil {
+		return modules.AtomicSwapOrder{}, err
+	}
+	return order, nil
+}
+
+// CancelOrder implements modules.AtomicSwapManager.CancelOrder
+func (m *Manager) CancelOrder(id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	order, ok := m.orders[id]
+	if !ok {
+		return modules.ErrAtomicSwapOrderNotFound
+	}
+	if order.Status != modules.AtomicSwapOrderStatusOpen {
+		return fmt.Errorf("atomic swap order %d is %s", id, order.Status.String())
+	}
+	order.Status = modules.AtomicSwapOrderStatusCancelled
+	return m.saveSync()
+}
+
+// FillOrder implements modules.AtomicSwapManager.FillOrder
+func (m *Manager) FillOrder(id uint64, outputID types.CoinOutputID, secret *types.AtomicSwapSecret) (modules.AtomicSwap, error) {
+	if id == 0 {
+		return modules.AtomicSwap{}, modules.ErrAtomicSwapOrderNotFound
+	}
+	swap, err := m.track(outputID, secret, id)
+	if err != nil {
+		return modules.AtomicSwap{}, err
+	}
+	m.signalChange()
+	return swap, nil
+}
@@ -0,0 +1,99 @@
+package atomicswap
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+)
+
+const (
+	logFile     = "atomicswap.log"
+	persistFile = "atomicswap.json"
+)
+
+var persistMetadata = persist.Metadata{
+	Header:  "Atomic Swap Manager",
+	Version: "1.0.0",
+}
+
+// persistence is the data that is kept when the manager is restarted.
+type persistence struct {
+	RecentChange modules.ConsensusChangeID `json:"recentchange"`
+	Swaps        []modules.AtomicSwap      `json:"swaps"`
+	Orders       []modules.AtomicSwapOrder `json:"orders"`
+	NextOrderID  uint64                    `json:"nextorderid"`
+}
+
+// initPersist initializes the persistent structures of the manager.
+func (m *Manager) initPersist(verbose bool) error {
+	// Make the persist directory
+	err := os.MkdirAll(m.persistDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	// Initialize the logger.
+	m.log, err = persist.NewFileLogger(m.bcInfo, filepath.Join(m.persistDir, logFile), verbose)
+	if err != nil {
+		return err
+	}
+
+	// Load the tracked contracts and orders, if any were persisted before.
+	var data persistence
+	err = persist.LoadJSON(persistMetadata, &data, filepath.Join(m.persistDir, persistFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	m.recentChange = data.RecentChange
+	m.nextOrderID = data.NextOrderID
+	for idx := range data.Swaps {
+		swap := data.Swaps[idx]
+		m.swaps[swap.OutputID] = &swap
+	}
+	for idx := range data.Orders {
+		order := data.Orders[idx]
+		m.orders[order.ID] = &order
+	}
+	return nil
+}
+
+// saveSync persists the tracked contracts and orders,
+// it is expected that the caller holds the lock of the manager.
+func (m *Manager) saveSync() error {
+	data := persistence{
+		RecentChange: m.recentChange,
+		Swaps:        make([]modules.AtomicSwap, 0, len(m.swaps)),
+		Orders:       make([]modules.AtomicSwapOrder, 0, len(m.orders)),
+		NextOrderID:  m.nextOrderID,
+	}
+	for _, swap := range m.swaps {
+		data.Swaps = append(data.Swaps, *swap)
+	}
+	for _, order := range m.orders {
+		data.Orders = append(data.Orders, *order)
+	}
+	err := persist.SaveJSON(persistMetadata, data, filepath.Join(m.persistDir, persistFile))
+	if err != nil {
+		m.log.Printf("failed to persist the tracked atomic swaps: %v", err)
+	}
+	return err
+}
+
+// resetConsensusState resets all state derived from consensus changes,
+// such that it can be rebuilt by processing the chain from the start.
+// The tracked contracts, their secrets and the orders are kept, as they cannot be rebuilt.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) resetConsensusState() error {
+	for _, swap := range m.swaps {
+		swap.Status = modules.AtomicSwapStatusPending
+		swap.SpendTransactionID = nil
+	}
+	m.recentChange = modules.ConsensusChangeBeginning
+	m.blockTime = 0
+	return m.saveSync()
+}
@@ -0,0 +1,194 @@
+package atomicswap
+
+import (
+	"fmt"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// atomicSwapSecretGetter is implemented by all atomic swap fulfillments.
+type atomicSwapSecretGetter interface {
+	AtomicSwapSecret() types.AtomicSwapSecret
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber,
+// extracting the secrets revealed by the applied blocks and updating
+// the status of the tracked contracts spent (or unspent) by the change.
+//
+// The contracts are not redeemed or refunded in this call, as that requires
+// the consensus set, which is locked while a consensus change is processed.
+func (m *Manager) ProcessConsensusChange(cc modules.ConsensusChange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, block := range cc.RevertedBlocks {
+		for _, txn := range block.Transactions {
+			m.revertTransaction(txn)
+		}
+	}
+	for _, block := range cc.AppliedBlocks {
+		for _, txn := range block.Transactions {
+			m.applyTransaction(txn)
+		}
+		m.blockTime = block.Timestamp
+	}
+	m.recentChange = cc.ID
+	m.saveSync()
+
+	if len(cc.AppliedBlocks) != 0 {
+		m.signalChange()
+	}
+}
+
+// applyTransaction extracts the secrets revealed by the given transaction,
+// and marks the tracked contracts it spends as redeemed or refunded.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) applyTransaction(txn types.Transaction) {
+	txnID := txn.ID()
+	for _, ci := range txn.CoinInputs {
+		getter, ok := ci.Fulfillment.Fulfillment.(atomicSwapSecretGetter)
+		if !ok {
+			continue
+		}
+		secret := getter.AtomicSwapSecret()
+		if secret != (types.AtomicSwapSecret{}) {
+			m.revealSecret(secret)
+		}
+		swap, ok := m.swaps[ci.ParentID]
+		if !ok {
+			continue
+		}
+		if secret == (types.AtomicSwapSecret{}) {
+			swap.Status = modules.AtomicSwapStatusRefunded
+		} else {
+			swap.Status = modules.AtomicSwapStatusRedeemed
+		}
+		id := txnID
+		swap.SpendTransactionID = &id
+		m.log.Printf("atomic swap contract %s is %s in tx %s", swap.OutputID.String(), swap.Status.String(), txnID.String())
+	}
+}
+
+// revertTransaction marks the tracked contracts spent by the given transaction as pending again.
+// Revealed secrets remain known, as the counterparty has seen them regardless.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) revertTransaction(txn types.Transaction) {
+	txnID := txn.ID()
+	for _, ci := range txn.CoinInputs {
+		swap, ok := m.swaps[ci.ParentID]
+		if !ok || swap.SpendTransactionID == nil || *swap.SpendTransactionID != txnID {
+			continue
+		}
+		swap.Status = modules.AtomicSwapStatusPending
+		swap.SpendTransactionID = nil
+		m.log.Printf("spend of atomic swap contract %s in tx %s is reverted", swap.OutputID.String(), txnID.String())
+	}
+}
+
+// revealSecret stores the given secret for all tracked contracts locked by its hash.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) revealSecret(secret types.AtomicSwapSecret) {
+	hashedSecret := types.NewAtomicSwapHashedSecret(secret)
+	for _, swap := range m.swaps {
+		if swap.Secret != nil || swap.Contract.HashedSecret != hashedSecret {
+			continue
+		}
+		s := secret
+		swap.Secret = &s
+		m.log.Printf("extracted secret of atomic swap contract %s", swap.OutputID.String())
+	}
+}
+
+// threadedWatchSwaps checks the tracked contracts after each consensus change,
+// until the manager is closed.
+func (m *Manager) threadedWatchSwaps() {
+	if err := m.tg.Add(); err != nil {
+		return
+	}
+	defer m.tg.Done()
+	for {
+		select {
+		case <-m.tg.StopChan():
+			return
+		case <-m.changes:
+			m.checkSwaps()
+		}
+	}
+}
+
+// checkSwaps redeems all pending contracts of which the wallet is the receiver and the secret is known,
+// and refunds all pending contracts of which the wallet is the sender and the time lock has been reached.
+func (m *Manager) checkSwaps() {
+	m.mu.Lock()
+	var spendable []modules.AtomicSwap
+	for _, swap := range m.swaps {
+		if swap.Status != modules.AtomicSwapStatusPending {
+			continue
+		}
+		if (swap.Role == modules.AtomicSwapRoleReceiver && swap.Secret != nil) ||
+			(swap.Role == modules.AtomicSwapRoleSender && m.blockTime > swap.Contract.TimeLock) {
+			spendable = append(spendable, *swap)
+		}
+	}
+	m.mu.Unlock()
+
+	// spend the contracts without holding the lock,
+	// as the transaction pool might require the consensus set
+	for _, swap := range spendable {
+		txnID, err := m.spend(swap)
+		if err == modules.ErrDuplicateTransactionSet {
+			continue // spend already submitted
+		}
+		if err != nil {
+			m.log.Printf("failed to spend atomic swap contract %s as %s: %v", swap.OutputID.String(), swap.Role.String(), err)
+			continue
+		}
+		m.log.Printf("submitted tx %s spending atomic swap contract %s as %s", txnID.String(), swap.OutputID.String(), swap.Role.String())
+	}
+}
+
+// spend redeems (as receiver) or refunds (as sender) the given contract,
+// sending its value (minus the miner fee) to the address of the wallet.
+func (m *Manager) spend(swap modules.AtomicSwap) (types.TransactionID, error) {
+	if swap.Value.Cmp(m.minerFee) != 1 {
+		return types.TransactionID{}, fmt.Errorf("contract value %s does not cover the miner fee", swap.Value.String())
+	}
+	uh := swap.Contract.Sender
+	fulfillment := &types.AtomicSwapFulfillment{}
+	if swap.Role == modules.AtomicSwapRoleReceiver {
+		uh = swap.Contract.Receiver
+		fulfillment.Secret = *swap.Secret
+	}
+	pk, sk, err := m.wallet.GetKey(uh)
+	if err != nil {
+		return types.TransactionID{}, fmt.Errorf("failed to get the key of address %s: %v", uh.String(), err)
+	}
+	fulfillment.PublicKey = pk
+
+	txn := types.Transaction{
+		Version: m.txVersion,
+		CoinInputs: []types.CoinInput{{
+			ParentID:    swap.OutputID,
+			Fulfillment: types.NewFulfillment(fulfillment),
+		}},
+		CoinOutputs: []types.CoinOutput{{
+			Value:     swap.Value.Sub(m.minerFee),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(uh)),
+		}},
+		MinerFees: []types.Currency{m.minerFee},
+	}
+	err = txn.CoinInputs[0].Fulfillment.Sign(types.FulfillmentSignContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  txn,
+		Key:          sk,
+	})
+	if err != nil {
+		return types.TransactionID{}, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	err = m.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	return txn.ID(), nil
+}
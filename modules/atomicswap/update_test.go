@@ -0,0 +1,204 @@
+package atomicswap
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	persistDir := build.TempDir(modules.AtomicSwapDir, t.Name())
+	err := os.MkdirAll(persistDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Manager{
+		persistDir:  persistDir,
+		log:         persist.NewLogger(types.DefaultBlockchainInfo(), ioutil.Discard, false),
+		swaps:       make(map[types.CoinOutputID]*modules.AtomicSwap),
+		orders:      make(map[uint64]*modules.AtomicSwapOrder),
+		nextOrderID: 1,
+		changes:     make(chan struct{}, 1),
+	}
+}
+
+func TestApplyAndRevertTransaction(t *testing.T) {
+	m := newTestManager(t)
+	secret, err := types.NewAtomicSwapSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashedSecret := types.NewAtomicSwapHashedSecret(secret)
+
+	// two contracts locked by the same secret, as is the case for both sides of a swap
+	redeemedID := types.CoinOutputID{1}
+	otherID := types.CoinOutputID{2}
+	refundedID := types.CoinOutputID{3}
+	m.swaps[redeemedID] = &modules.AtomicSwap{
+		OutputID: redeemedID,
+		Contract: types.AtomicSwapCondition{HashedSecret: hashedSecret},
+		Role:     modules.AtomicSwapRoleSender,
+		Status:   modules.AtomicSwapStatusPending,
+	}
+	m.swaps[otherID] = &modules.AtomicSwap{
+		OutputID: otherID,
+		Contract: types.AtomicSwapCondition{HashedSecret: hashedSecret},
+		Role:     modules.AtomicSwapRoleReceiver,
+		Status:   modules.AtomicSwapStatusPending,
+	}
+	m.swaps[refundedID] = &modules.AtomicSwap{
+		OutputID: refundedID,
+		Contract: types.AtomicSwapCondition{HashedSecret: types.AtomicSwapHashedSecret{4}},
+		Role:     modules.AtomicSwapRoleSender,
+		Status:   modules.AtomicSwapStatusPending,
+	}
+
+	redeemTxn := types.Transaction{
+		CoinInputs: []types.CoinInput{{
+			ParentID:    redeemedID,
+			Fulfillment: types.NewFulfillment(&types.AtomicSwapFulfillment{Secret: secret}),
+		}},
+	}
+	refundTxn := types.Transaction{
+		CoinInputs: []types.CoinInput{{
+			ParentID:    refundedID,
+			Fulfillment: types.NewFulfillment(&types.AtomicSwapFulfillment{}),
+		}},
+	}
+	m.applyTransaction(redeemTxn)
+	m.applyTransaction(refundTxn)
+
+	if status := m.swaps[redeemedID].Status; status != modules.AtomicSwapStatusRedeemed {
+		t.Errorf("unexpected status of redeemed contract: %s", status.String())
+	}
+	if status := m.swaps[refundedID].Status; status != modules.AtomicSwapStatusRefunded {
+		t.Errorf("unexpected status of refunded contract: %s", status.String())
+	}
+	if status := m.swaps[otherID].Status; status != modules.AtomicSwapStatusPending {
+		t.Errorf("unexpected status of unspent contract: %s", status.String())
+	}
+	for _, id := range []types.CoinOutputID{redeemedID, otherID} {
+		if s := m.swaps[id].Secret; s == nil || *s != secret {
+			t.Errorf("secret of contract %s was not extracted", id.String())
+		}
+	}
+	if m.swaps[refundedID].Secret != nil {
+		t.Error("refunded contract should not have a secret")
+	}
+
+	m.revertTransaction(refundTxn)
+	if swap := m.swaps[refundedID]; swap.Status != modules.AtomicSwapStatusPending || swap.SpendTransactionID != nil {
+		t.Errorf("refund of contract was not reverted: %v", *swap)
+	}
+	if status := m.swaps[redeemedID].Status; status != modules.AtomicSwapStatusRedeemed {
+		t.Errorf("unexpected status of redeemed contract after unrelated revert: %s", status.String())
+	}
+}
+
+func TestResetConsensusState(t *testing.T) {
+	m := newTestManager(t)
+	secret, err := types.NewAtomicSwapSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spentID := types.CoinOutputID{1}
+	spendTxnID := types.TransactionID{2}
+	m.swaps[spentID] = &modules.AtomicSwap{
+		OutputID:           spentID,
+		Contract:           types.AtomicSwapCondition{HashedSecret: types.NewAtomicSwapHashedSecret(secret)},
+		Role:               modules.AtomicSwapRoleSender,
+		Status:             modules.AtomicSwapStatusRedeemed,
+		Secret:             &secret,
+		SpendTransactionID: &spendTxnID,
+	}
+	m.recentChange = modules.ConsensusChangeID{3}
+	m.blockTime = 42
+	order, err := m.PlaceOrder(modules.AtomicSwapOrder{
+		Amount:      types.NewCurrency64(42),
+		Asset:       "BTC",
+		AssetAmount: "0.1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.resetConsensusState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.recentChange != modules.ConsensusChangeBeginning || m.blockTime != 0 {
+		t.Errorf("consensus state was not reset: %x at %d", m.recentChange, m.blockTime)
+	}
+	swap := m.swaps[spentID]
+	if swap.Status != modules.AtomicSwapStatusPending || swap.SpendTransactionID != nil {
+		t.Errorf("spend of contract was not reset: %v", *swap)
+	}
+	if swap.Secret == nil || *swap.Secret != secret {
+		t.Error("secret of contract should be kept")
+	}
+	if _, ok := m.orders[order.ID]; !ok {
+		t.Error("orders should be kept")
+	}
+}
+
+func TestOrderbook(t *testing.T) {
+	m := newTestManager(t)
+
+	_, err := m.PlaceOrder(modules.AtomicSwapOrder{Asset: "BTC", AssetAmount: "0.1"})
+	if err == nil {
+		t.Error("order without amount should not be placed")
+	}
+	_, err = m.PlaceOrder(modules.AtomicSwapOrder{Amount: types.NewCurrency64(42)})
+	if err == nil {
+		t.Error("order without asset should not be placed")
+	}
+
+	order, err := m.PlaceOrder(modules.AtomicSwapOrder{
+		Amount:      types.NewCurrency64(42),
+		Asset:       "BTC",
+		AssetAmount: "0.1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != 1 || order.Status != modules.AtomicSwapOrderStatusOpen {
+		t.Errorf("unexpected placed order: %v", order)
+	}
+	second, err := m.PlaceOrder(modules.AtomicSwapOrder{
+		Amount:      types.NewCurrency64(1),
+		Asset:       "ETH",
+		AssetAmount: "2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != 2 {
+		t.Errorf("unexpected ID of second order: %d", second.ID)
+	}
+
+	err = m.CancelOrder(order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.CancelOrder(order.ID)
+	if err == nil {
+		t.Error("cancelled order should not be cancelled again")
+	}
+	err = m.CancelOrder(3)
+	if err != modules.ErrAtomicSwapOrderNotFound {
+		t.Errorf("unexpected error for unknown order: %v", err)
+	}
+
+	orders, err := m.Orders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 || orders[0].Status != modules.AtomicSwapOrderStatusCancelled || orders[1].Status != modules.AtomicSwapOrderStatusOpen {
+		t.Errorf("unexpected orders: %v", orders)
+	}
+}
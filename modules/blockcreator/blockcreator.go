@@ -8,6 +8,7 @@ import (
 	"github.com/threefoldtech/rivine/build"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/metrics"
 	rivinesync "github.com/threefoldtech/rivine/sync"
 	"github.com/threefoldtech/rivine/types"
 )
@@ -35,6 +36,11 @@ type BlockCreator struct {
 	// tg signals the BlockCreator's goroutines to shut down and blocks until all
 	// goroutines have exited before returning from Close().
 	tg rivinesync.ThreadGroup
+
+	// metrics collected by the block creator
+	metricAttempts       *metrics.Counter
+	metricBlocksCreated  *metrics.Counter
+	metricSubmitFailures *metrics.Counter
 }
 
 // startupRescan will rescan the blockchain in the event that the block creator
@@ -97,6 +103,10 @@ func New(cs modules.ConsensusSet, tpool modules.TransactionPool, w modules.Walle
 		unsolvedBlock: &types.Block{},
 
 		persistDir: persistDir,
+
+		metricAttempts:       newCounter("attempts_total", "Amount of attempts made to solve a block."),
+		metricBlocksCreated:  newCounter("blocks_created_total", "Amount of blocks solved by the block creator."),
+		metricSubmitFailures: newCounter("submit_failures_total", "Amount of solved blocks which failed to be submitted."),
 	}
 
 	err := b.initPersist(verboseLogging)
@@ -0,0 +1,25 @@
+package blockcreator
+
+import (
+	"github.com/threefoldtech/rivine/pkg/metrics"
+)
+
+// newCounter creates a counter within the block creator's metrics subsystem.
+func newCounter(name, help string) *metrics.Counter {
+	return metrics.NewCounter(metrics.Opts{
+		Namespace: metrics.Namespace,
+		Subsystem: "blockcreator",
+		Name:      name,
+		Help:      help,
+	})
+}
+
+// RegisterMetrics implements metrics.Source.RegisterMetrics,
+// registering all collectors of the block creator.
+func (b *BlockCreator) RegisterMetrics(reg *metrics.Registry) error {
+	return reg.RegisterAll(
+		b.metricAttempts,
+		b.metricBlocksCreated,
+		b.metricSubmitFailures,
+	)
+}
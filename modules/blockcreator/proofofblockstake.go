@@ -38,7 +38,9 @@ func (b *BlockCreator) SolveBlocks() {
 		now := time.Now().Unix()
 		b.log.Debugln("[BC] Attempting to solve blocks")
 		block := b.solveBlock(uint64(now), 10)
+		b.metricAttempts.Inc()
 		if block != nil {
+			b.metricBlocksCreated.Inc()
 			bjson, err := json.Marshal(block)
 			if err != nil {
 				b.log.Println("Solved block but failed to JSON-marshal it for logging purposes:", err)
@@ -49,6 +51,7 @@ func (b *BlockCreator) SolveBlocks() {
 			err = b.submitBlock(*block)
 			if err != nil {
 				b.log.Println("ERROR: An error occurred while submitting a solved block:", err)
+				b.metricSubmitFailures.Inc()
 			}
 		}
 		//sleep a while before recalculating
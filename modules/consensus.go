@@ -170,6 +170,18 @@ type (
 
 		SpentCoinOutputs       map[types.CoinOutputID]types.CoinOutput
 		SpentBlockStakeOutputs map[types.BlockStakeOutputID]types.BlockStakeOutput
+
+		// SpentCoinOutputCreations and SpentBlockStakeOutputCreations
+		// define (optionally) for the spent outputs the block in which they were created.
+		SpentCoinOutputCreations       map[types.CoinOutputID]OutputCreation
+		SpentBlockStakeOutputCreations map[types.BlockStakeOutputID]OutputCreation
+	}
+
+	// OutputCreation defines the height and time of the block
+	// in which a coin or block stake output was created.
+	OutputCreation struct {
+		BlockHeight types.BlockHeight
+		BlockTime   types.Timestamp
 	}
 
 	// A ConsensusChange enumerates a set of changes that occurred to the consensus set.
@@ -240,6 +240,7 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 	// Grab a lock on the consensus set. Lock is demoted later in the function,
 	// failure to unlock before returning an error will cause a deadlock.
 	cs.mu.Lock()
+	validationStart := time.Now()
 
 	// Start verification inside of a bolt View tx.
 	err := cs.db.View(func(tx *bolt.Tx) error {
@@ -295,6 +296,7 @@ func (cs *ConsensusSet) managedAcceptBlock(b types.Block) error {
 		cs.mu.Unlock()
 		return err
 	}
+	cs.metricBlockValidation.ObserveSince(validationStart)
 	// If appliedBlocks is 0, revertedBlocks will also be 0.
 	if len(changeEntry.AppliedBlocks) == 0 && len(changeEntry.RevertedBlocks) != 0 {
 		build.Severe("appliedBlocks and revertedBlocks are mismatched!")
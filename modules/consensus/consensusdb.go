@@ -53,6 +53,24 @@ var (
 	// blockstake outputs.
 	BlockStakeOutputs = []byte("BlockStakeOutputs")
 
+	// CoinOutputCreationHeights is a database bucket that contains the height
+	// of the block in which a coin output was created, for all unspent coin outputs.
+	CoinOutputCreationHeights = []byte("CoinOutputCreationHeights")
+
+	// BlockStakeOutputCreationHeights is a database bucket that contains the height
+	// of the block in which a blockstake output was created, for all unspent blockstake outputs.
+	BlockStakeOutputCreationHeights = []byte("BlockStakeOutputCreationHeights")
+
+	// SpentCoinOutputCreationHeights is a database bucket that contains the creation height
+	// of all spent coin outputs, keyed by the height of the spending block followed by the output ID,
+	// such that it can be restored when the spending block is reverted.
+	SpentCoinOutputCreationHeights = []byte("SpentCoinOutputCreationHeights")
+
+	// SpentBlockStakeOutputCreationHeights is a database bucket that contains the creation height
+	// of all spent blockstake outputs, keyed by the height of the spending block followed by the output ID,
+	// such that it can be restored when the spending block is reverted.
+	SpentBlockStakeOutputCreationHeights = []byte("SpentBlockStakeOutputCreationHeights")
+
 	// TransactionIDMap is a database bucket that containsall of the present
 	// transaction IDs linked to their short ID
 	TransactionIDMap = []byte("TransactionIDMap")
@@ -71,6 +89,10 @@ func (cs *ConsensusSet) createConsensusDB(tx *bolt.Tx) error {
 		Consistency,
 		CoinOutputs,
 		BlockStakeOutputs,
+		CoinOutputCreationHeights,
+		BlockStakeOutputCreationHeights,
+		SpentCoinOutputCreationHeights,
+		SpentBlockStakeOutputCreationHeights,
 		TransactionIDMap,
 		BucketPlugins,
 	}
@@ -373,6 +395,91 @@ func removeBlockStakeOutput(tx *bolt.Tx, id types.BlockStakeOutputID) {
 	}
 }
 
+// getOutputCreation returns the height and timestamp of the block which created an output,
+// using the creation height stored in the given bucket. An output created by the block that
+// is currently being applied is not yet part of the path, for such outputs
+// the given timestamp of the pending block is returned instead.
+func getOutputCreation(tx *bolt.Tx, bucket []byte, id []byte, pendingBlockTime types.Timestamp) (modules.OutputCreation, error) {
+	heightBytes := tx.Bucket(bucket).Get(id)
+	if heightBytes == nil {
+		return modules.OutputCreation{}, errNilItem
+	}
+	var creation modules.OutputCreation
+	err := siabin.Unmarshal(heightBytes, &creation.BlockHeight)
+	if err != nil {
+		return modules.OutputCreation{}, err
+	}
+	if creation.BlockHeight > blockHeight(tx) {
+		creation.BlockTime = pendingBlockTime
+		return creation, nil
+	}
+	creation.BlockTime, err = blockTimeStamp(tx, creation.BlockHeight)
+	if err != nil {
+		return modules.OutputCreation{}, err
+	}
+	return creation, nil
+}
+
+// addOutputCreationHeight stores the height of the block which created an output,
+// in the given bucket.
+func addOutputCreationHeight(tx *bolt.Tx, bucket []byte, id []byte, height types.BlockHeight) {
+	heightBytes, err := siabin.Marshal(height)
+	if err != nil {
+		build.Severe(err)
+	}
+	err = tx.Bucket(bucket).Put(id, heightBytes)
+	if err != nil {
+		build.Severe(err)
+	}
+}
+
+// removeOutputCreationHeight removes the creation height of an output from the given bucket.
+func removeOutputCreationHeight(tx *bolt.Tx, bucket []byte, id []byte) {
+	err := tx.Bucket(bucket).Delete(id)
+	if err != nil {
+		build.Severe(err)
+	}
+}
+
+// spendOutputCreationHeight moves the creation height of an output spent by the block
+// at the given height from the given bucket to the given bucket of spent outputs.
+// Nothing is moved in case the creation height of the output is unknown.
+func spendOutputCreationHeight(tx *bolt.Tx, bucket, spentBucket []byte, id []byte, spendHeight types.BlockHeight) {
+	heightBytes := tx.Bucket(bucket).Get(id)
+	if heightBytes == nil {
+		return
+	}
+	err := tx.Bucket(spentBucket).Put(spentOutputCreationKey(spendHeight, id), heightBytes)
+	if err != nil {
+		build.Severe(err)
+	}
+	removeOutputCreationHeight(tx, bucket, id)
+}
+
+// restoreOutputCreationHeight moves the creation height of an output spent by the (reverted) block
+// at the given height from the given bucket of spent outputs back to the given bucket.
+// Nothing is restored in case the creation height of the output is unknown.
+func restoreOutputCreationHeight(tx *bolt.Tx, bucket, spentBucket []byte, id []byte, spendHeight types.BlockHeight) {
+	key := spentOutputCreationKey(spendHeight, id)
+	heightBytes := tx.Bucket(spentBucket).Get(key)
+	if heightBytes == nil {
+		return
+	}
+	err := tx.Bucket(bucket).Put(id, heightBytes)
+	if err != nil {
+		build.Severe(err)
+	}
+	err = tx.Bucket(spentBucket).Delete(key)
+	if err != nil {
+		build.Severe(err)
+	}
+}
+
+// spentOutputCreationKey returns the key of a spent output in the buckets of spent outputs.
+func spentOutputCreationKey(spendHeight types.BlockHeight, id []byte) []byte {
+	return append(siabin.EncUint64(uint64(spendHeight)), id...)
+}
+
 // addTxnIDMapping adds a transaction ID mapping to the database.
 func addTxnIDMapping(tx *bolt.Tx, longID types.TransactionID, shortID types.TransactionShortID) {
 	txIDMapBucket := tx.Bucket(TransactionIDMap)
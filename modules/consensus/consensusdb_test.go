@@ -0,0 +1,86 @@
+package consensus
+
+import (
+	"errors"
+	"testing"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestOutputCreationHeights(t *testing.T) {
+	cst, err := blankConsensusSetTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cst.cs.Close()
+
+	genesis := cst.cs.blockRoot
+	if len(genesis.CoinOutputDiffs) == 0 {
+		t.Fatal("expected the genesis block to create coin outputs")
+	}
+	errRollback := errors.New("rollback")
+	err = cst.cs.db.Update(func(tx *bolt.Tx) error {
+		// outputs of the genesis block are created at height 0
+		genesisDiff := genesis.CoinOutputDiffs[0]
+		creation, err := getOutputCreation(tx, CoinOutputCreationHeights, genesisDiff.ID[:], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creation.BlockHeight != 0 || creation.BlockTime != genesis.Block.Timestamp {
+			t.Fatalf("unexpected creation of genesis output: %v", creation)
+		}
+
+		// outputs created by a block that is being applied
+		// are created at the next height, using the timestamp of the pending block
+		scod := modules.CoinOutputDiff{
+			Direction:  modules.DiffApply,
+			ID:         types.CoinOutputID{1},
+			CoinOutput: types.CoinOutput{Value: types.NewCurrency64(1)},
+		}
+		commitCoinOutputDiff(tx, scod, modules.DiffApply)
+		creation, err = getOutputCreation(tx, CoinOutputCreationHeights, scod.ID[:], 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creation.BlockHeight != 1 || creation.BlockTime != 42 {
+			t.Fatalf("unexpected creation of pending output: %v", creation)
+		}
+		// reverting the output removes its creation
+		commitCoinOutputDiff(tx, scod, modules.DiffRevert)
+		_, err = getOutputCreation(tx, CoinOutputCreationHeights, scod.ID[:], 42)
+		if err != errNilItem {
+			t.Fatalf("expected reverted output to have no creation, got: %v", err)
+		}
+
+		// spent outputs lose their creation, until the spending block is reverted
+		spendDiff := genesisDiff
+		spendDiff.Direction = modules.DiffRevert
+		commitCoinOutputDiff(tx, spendDiff, modules.DiffApply)
+		_, err = getOutputCreation(tx, CoinOutputCreationHeights, genesisDiff.ID[:], 0)
+		if err != errNilItem {
+			t.Fatalf("expected spent output to have no creation, got: %v", err)
+		}
+		if tx.Bucket(SpentCoinOutputCreationHeights).Get(spentOutputCreationKey(1, genesisDiff.ID[:])) == nil {
+			t.Fatal("expected the creation of the spent output to be kept for the spending block")
+		}
+		pushPath(tx, types.BlockID{1})
+		commitCoinOutputDiff(tx, spendDiff, modules.DiffRevert)
+		popPath(tx)
+		creation, err = getOutputCreation(tx, CoinOutputCreationHeights, genesisDiff.ID[:], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creation.BlockHeight != 0 {
+			t.Fatalf("unexpected creation of unspent genesis output: %v", creation)
+		}
+		if tx.Bucket(SpentCoinOutputCreationHeights).Get(spentOutputCreationKey(1, genesisDiff.ID[:])) != nil {
+			t.Fatal("expected the creation of the restored output to be removed from the spent outputs")
+		}
+		return errRollback
+	})
+	if err != errRollback {
+		t.Fatal(err)
+	}
+}
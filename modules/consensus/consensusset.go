@@ -18,6 +18,7 @@ import (
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/persist"
 	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/pkg/metrics"
 	"github.com/threefoldtech/rivine/sync"
 	"github.com/threefoldtech/rivine/types"
 
@@ -124,6 +125,10 @@ type ConsensusSet struct {
 	genesisBlockStakeCount types.Currency
 
 	dbDebugFile *os.File
+
+	// metrics collected by the consensus set
+	metricBlockValidation *metrics.Histogram
+	metricPluginApply     *metrics.HistogramVec
 }
 
 // New returns a new ConsensusSet, containing at least the genesis block. If
@@ -165,6 +170,9 @@ func New(gateway modules.Gateway, bootstrap bool, persistDir string, bcInfo type
 		bcInfo:                 bcInfo,
 		chainCts:               chainCts,
 		genesisBlockStakeCount: chainCts.GenesisBlockStakeCount(),
+
+		metricBlockValidation: newBlockValidationHistogram(),
+		metricPluginApply:     newPluginApplyHistogram(),
 	}
 
 	cs.blockValidator = newBlockValidator(cs)
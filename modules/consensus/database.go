@@ -21,7 +21,7 @@ var (
 
 	dbMetadata = persist.Metadata{
 		Header:  "Consensus Set Database",
-		Version: "1.1.0",
+		Version: "1.2.0",
 	}
 )
 
@@ -3,6 +3,7 @@ package consensus
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	bolt "github.com/rivine/bbolt"
 	"github.com/threefoldtech/rivine/build"
@@ -52,8 +53,23 @@ func commitDiffSetSanity(tx *bolt.Tx, pb *processedBlock, dir modules.DiffDirect
 func commitCoinOutputDiff(tx *bolt.Tx, scod modules.CoinOutputDiff, dir modules.DiffDirection) {
 	if scod.Direction == dir {
 		addCoinOutput(tx, scod.ID, scod.CoinOutput)
+		if dir == modules.DiffApply {
+			// the output is created by the block that is being applied,
+			// which is only added to the path once all its diffs are applied
+			addOutputCreationHeight(tx, CoinOutputCreationHeights, scod.ID[:], blockHeight(tx)+1)
+		} else {
+			// the block that spent the output is reverted
+			restoreOutputCreationHeight(tx, CoinOutputCreationHeights, SpentCoinOutputCreationHeights, scod.ID[:], blockHeight(tx))
+		}
 	} else {
 		removeCoinOutput(tx, scod.ID)
+		if dir == modules.DiffRevert {
+			// the block that created the output is reverted
+			removeOutputCreationHeight(tx, CoinOutputCreationHeights, scod.ID[:])
+		} else {
+			// the output is spent by the block that is being applied
+			spendOutputCreationHeight(tx, CoinOutputCreationHeights, SpentCoinOutputCreationHeights, scod.ID[:], blockHeight(tx)+1)
+		}
 	}
 }
 
@@ -61,8 +77,23 @@ func commitCoinOutputDiff(tx *bolt.Tx, scod modules.CoinOutputDiff, dir modules.
 func commitBlockStakeOutputDiff(tx *bolt.Tx, sfod modules.BlockStakeOutputDiff, dir modules.DiffDirection) {
 	if sfod.Direction == dir {
 		addBlockStakeOutput(tx, sfod.ID, sfod.BlockStakeOutput)
+		if dir == modules.DiffApply {
+			// the output is created by the block that is being applied,
+			// which is only added to the path once all its diffs are applied
+			addOutputCreationHeight(tx, BlockStakeOutputCreationHeights, sfod.ID[:], blockHeight(tx)+1)
+		} else {
+			// the block that spent the output is reverted
+			restoreOutputCreationHeight(tx, BlockStakeOutputCreationHeights, SpentBlockStakeOutputCreationHeights, sfod.ID[:], blockHeight(tx))
+		}
 	} else {
 		removeBlockStakeOutput(tx, sfod.ID)
+		if dir == modules.DiffRevert {
+			// the block that created the output is reverted
+			removeOutputCreationHeight(tx, BlockStakeOutputCreationHeights, sfod.ID[:])
+		} else {
+			// the output is spent by the block that is being applied
+			spendOutputCreationHeight(tx, BlockStakeOutputCreationHeights, SpentBlockStakeOutputCreationHeights, sfod.ID[:], blockHeight(tx)+1)
+		}
 	}
 }
 
@@ -178,6 +209,10 @@ func (cs *ConsensusSet) generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock) er
 				return fmt.Errorf("failed to find block stake input %s as unspent block stake output in current consensus state: %v", bsi.ParentID.String(), err)
 			}
 		}
+		err = addSpentOutputCreations(tx, &cTxn, pb.Block.Timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to find the creation of the outputs spent by txn %s: %v", txn.ID().String(), err)
+		}
 
 		err = cs.validTransaction(tx, cTxn, types.TransactionValidationConstants{
 			BlockSizeLimit:         cs.chainCts.BlockSizeLimit,
@@ -194,7 +229,9 @@ func (cs *ConsensusSet) generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock) er
 		// apply the transaction for each of the plugins
 		for name, plugin := range cs.plugins {
 			bucket := cs.bucketForPlugin(tx, name)
+			start := time.Now()
 			err := plugin.ApplyTransaction(cTxn, bucket)
+			cs.metricPluginApply.ObserveSince(name, start)
 			if err != nil {
 				return err
 			}
@@ -217,7 +254,9 @@ func (cs *ConsensusSet) generateAndApplyDiff(tx *bolt.Tx, pb *processedBlock) er
 		}
 		for name, plugin := range cs.plugins {
 			bucket := cs.bucketForPlugin(tx, name)
+			start := time.Now()
 			err := plugin.ApplyBlockHeader(header, bucket)
+			cs.metricPluginApply.ObserveSince(name, start)
 			if err != nil {
 				return err
 			}
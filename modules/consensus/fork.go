@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"errors"
+	"time"
 
 	bolt "github.com/rivine/bbolt"
 	"github.com/threefoldtech/rivine/build"
@@ -171,7 +172,9 @@ func (cs *ConsensusSet) forwardBlockForPlugins(tx *bolt.Tx, pb *processedBlock)
 
 	for name, plugin := range cs.plugins {
 		bucket := cs.bucketForPlugin(tx, name)
+		start := time.Now()
 		err := plugin.ApplyBlock(cBlock, bucket)
+		cs.metricPluginApply.ObserveSince(name, start)
 		if err != nil {
 			return err
 		}
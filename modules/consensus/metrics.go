@@ -0,0 +1,61 @@
+package consensus
+
+import (
+	"github.com/threefoldtech/rivine/pkg/metrics"
+)
+
+const metricsSubsystem = "consensus"
+
+// newBlockValidationHistogram creates the histogram used to measure
+// how long it takes to validate and integrate a block.
+func newBlockValidationHistogram() *metrics.Histogram {
+	return metrics.NewHistogram(metrics.HistogramOpts{
+		Opts: metrics.Opts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "block_validation_seconds",
+			Help:      "Time it takes to validate a block and add it to the block tree.",
+		},
+	})
+}
+
+// newPluginApplyHistogram creates the histogram used to measure
+// how long it takes for each plugin to apply a block, header or transaction.
+func newPluginApplyHistogram() *metrics.HistogramVec {
+	return metrics.NewHistogramVec(metrics.HistogramOpts{
+		Opts: metrics.Opts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "plugin_apply_seconds",
+			Help:      "Time it takes a consensus plugin to apply a block, block header or transaction.",
+		},
+	}, "plugin")
+}
+
+// RegisterMetrics implements metrics.Source.RegisterMetrics,
+// registering all collectors of the consensus set.
+func (cs *ConsensusSet) RegisterMetrics(reg *metrics.Registry) error {
+	return reg.RegisterAll(
+		metrics.NewGaugeFunc(metrics.Opts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "height",
+			Help:      "Height of the current block of the consensus set.",
+		}, func() float64 {
+			return float64(cs.Height())
+		}),
+		metrics.NewGaugeFunc(metrics.Opts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "synced",
+			Help:      "1 if the consensus set is synced with the network, 0 otherwise.",
+		}, func() float64 {
+			if cs.Synced() {
+				return 1
+			}
+			return 0
+		}),
+		cs.metricBlockValidation,
+		cs.metricPluginApply,
+	)
+}
@@ -15,13 +15,97 @@ import (
 )
 
 func convertLegacyDatabase(filepath string, log *persist.Logger) (*persist.BoltDatabase, error) {
-	return convertLegacyOneZeroFiveDatabase(filepath, log)
+	return convertLegacyOneOneZeroDatabase(filepath, log)
 }
 
-// convertLegacyOneZeroFiveDatabase converts a 1.0.5 consensus database,
+// convertLegacyOneOneZeroDatabase converts a 1.1.0 consensus database,
 // to a database of the current version as defined by dbMetadata.
 // It keeps the database open and returns it for further usage.
-func convertLegacyOneZeroFiveDatabase(filepath string, log *persist.Logger) (db *persist.BoltDatabase, err error) {
+func convertLegacyOneOneZeroDatabase(filepath string, log *persist.Logger) (db *persist.BoltDatabase, err error) {
+	var legacyDBMetadata = persist.Metadata{
+		Header:  "Consensus Set Database",
+		Version: "1.1.0",
+	}
+	db, err = persist.OpenDatabase(legacyDBMetadata, filepath)
+	if err != nil {
+		if err == persist.ErrBadVersion {
+			db, err = convertLegacyOneZeroFiveDatabase(filepath, log, legacyDBMetadata)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if !dbInitialized(tx) {
+			return nil // buckets are created when initializing the database
+		}
+		for _, name := range [][]byte{
+			CoinOutputCreationHeights,
+			BlockStakeOutputCreationHeights,
+			SpentCoinOutputCreationHeights,
+			SpentBlockStakeOutputCreationHeights,
+		} {
+			_, err := tx.CreateBucket(name)
+			if err != nil {
+				return err
+			}
+		}
+		log.Printf("upgrading consensus database from 1.1.0 to %v: indexing the creation height of all outputs...\n", dbMetadata.Version)
+		return addLegacyOutputCreationHeights(tx)
+	})
+	if err == nil {
+		// set the new metadata, and save it,
+		// such that next time we have the new version stored
+		db.Header, db.Version = dbMetadata.Header, dbMetadata.Version
+		err = db.SaveMetadata()
+	}
+	if err != nil {
+		err := db.Close()
+		if build.DEBUG && err != nil {
+			panic(err)
+		}
+	}
+	return
+}
+
+// addLegacyOutputCreationHeights stores the creation height of all outputs
+// created and spent by the blocks in the current path, as done for the outputs of new blocks.
+func addLegacyOutputCreationHeights(tx *bolt.Tx) error {
+	height := blockHeight(tx)
+	for h := types.BlockHeight(0); h <= height; h++ {
+		id, err := getPath(tx, h)
+		if err != nil {
+			return err
+		}
+		pb, err := getBlockMap(tx, id)
+		if err != nil {
+			return err
+		}
+		for _, scod := range pb.CoinOutputDiffs {
+			if scod.Direction == modules.DiffApply {
+				addOutputCreationHeight(tx, CoinOutputCreationHeights, scod.ID[:], h)
+			} else {
+				spendOutputCreationHeight(tx, CoinOutputCreationHeights, SpentCoinOutputCreationHeights, scod.ID[:], h)
+			}
+		}
+		for _, sfod := range pb.BlockStakeOutputDiffs {
+			if sfod.Direction == modules.DiffApply {
+				addOutputCreationHeight(tx, BlockStakeOutputCreationHeights, sfod.ID[:], h)
+			} else {
+				spendOutputCreationHeight(tx, BlockStakeOutputCreationHeights, SpentBlockStakeOutputCreationHeights, sfod.ID[:], h)
+			}
+		}
+	}
+	return nil
+}
+
+// convertLegacyOneZeroFiveDatabase converts a 1.0.5 consensus database,
+// to a database of the desired version.
+// It keeps the database open and returns it for further usage.
+func convertLegacyOneZeroFiveDatabase(filepath string, log *persist.Logger, desiredMetadata persist.Metadata) (db *persist.BoltDatabase, err error) {
 	var legacyDBMetadata = persist.Metadata{
 		Header:  "Consensus Set Database",
 		Version: "1.0.5",
@@ -45,7 +129,7 @@ func convertLegacyOneZeroFiveDatabase(filepath string, log *persist.Logger) (db
 	if err == nil {
 		// set the new metadata, and save it,
 		// such that next time we have the new version stored
-		db.Header, db.Version = dbMetadata.Header, dbMetadata.Version
+		db.Header, db.Version = desiredMetadata.Header, desiredMetadata.Version
 		err = db.SaveMetadata()
 	}
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/persist"
@@ -265,7 +266,9 @@ func (cs *ConsensusSet) initPluginSync(ctx context.Context, name string, plugin
 							}
 						}
 
+						start := time.Now()
 						err = plugin.ApplyBlock(cBlock, bucket)
+						cs.metricPluginApply.ObserveSince(name, start)
 						if err != nil {
 							return err
 						}
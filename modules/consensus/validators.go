@@ -214,11 +214,18 @@ func ValidateCoinInputsAreFulfilled(tx modules.ConsensusTransaction, ctx types.T
 				ci.ParentID.String(), ctx.BlockHeight)
 		}
 		// check if the referenced output's condition has been fulfilled
+		// outputs of which the creation is unknown do not fulfill conditions relative to it
+		creation, hasCreation := tx.SpentCoinOutputCreations[ci.ParentID]
 		err := co.Condition.Fulfill(ci.Fulfillment, types.FulfillContext{
-			ExtraObjects: []interface{}{uint64(index)},
-			BlockHeight:  ctx.BlockHeight,
-			BlockTime:    ctx.BlockTime,
-			Transaction:  tx.Transaction,
+			ExtraObjects:           []interface{}{uint64(index)},
+			BlockHeight:            ctx.BlockHeight,
+			BlockTime:              ctx.BlockTime,
+			OutputCreationHeight:   creation.BlockHeight,
+			OutputCreationTime:     creation.BlockTime,
+			HasOutputCreation:      hasCreation,
+			Transaction:            tx.Transaction,
+			SpentCoinOutputs:       tx.SpentCoinOutputs,
+			SpentBlockStakeOutputs: tx.SpentBlockStakeOutputs,
 		})
 		if err != nil {
 			return err
@@ -275,11 +282,18 @@ func ValidateBlockStakeInputsAreFulfilled(tx modules.ConsensusTransaction, ctx t
 				bsi.ParentID.String(), ctx.BlockHeight)
 		}
 		// check if the referenced output's condition has been fulfilled
+		// outputs of which the creation is unknown do not fulfill conditions relative to it
+		creation, hasCreation := tx.SpentBlockStakeOutputCreations[bsi.ParentID]
 		err = bso.Condition.Fulfill(bsi.Fulfillment, types.FulfillContext{
-			ExtraObjects: []interface{}{uint64(index)},
-			BlockHeight:  ctx.BlockHeight,
-			BlockTime:    ctx.BlockTime,
-			Transaction:  tx.Transaction,
+			ExtraObjects:           []interface{}{uint64(index)},
+			BlockHeight:            ctx.BlockHeight,
+			BlockTime:              ctx.BlockTime,
+			OutputCreationHeight:   creation.BlockHeight,
+			OutputCreationTime:     creation.BlockTime,
+			HasOutputCreation:      hasCreation,
+			Transaction:            tx.Transaction,
+			SpentCoinOutputs:       tx.SpentCoinOutputs,
+			SpentBlockStakeOutputs: tx.SpentBlockStakeOutputs,
 		})
 		if err != nil {
 			return err
@@ -49,6 +49,35 @@ func (cs *ConsensusSet) validTransaction(tx *bolt.Tx, t modules.ConsensusTransac
 	return cs.validateTransactionUsingPlugins(t, ctx, tx)
 }
 
+// addSpentOutputCreations collects the creation of all outputs spent by the given transaction,
+// such that conditions can be evaluated relative to the block in which the spent outputs were created.
+// Outputs of which the creation is unknown are skipped.
+func addSpentOutputCreations(tx *bolt.Tx, cTxn *modules.ConsensusTransaction, pendingBlockTime types.Timestamp) error {
+	cTxn.SpentCoinOutputCreations = make(map[types.CoinOutputID]modules.OutputCreation, len(cTxn.CoinInputs))
+	for _, ci := range cTxn.CoinInputs {
+		creation, err := getOutputCreation(tx, CoinOutputCreationHeights, ci.ParentID[:], pendingBlockTime)
+		if err == errNilItem {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		cTxn.SpentCoinOutputCreations[ci.ParentID] = creation
+	}
+	cTxn.SpentBlockStakeOutputCreations = make(map[types.BlockStakeOutputID]modules.OutputCreation, len(cTxn.BlockStakeInputs))
+	for _, bsi := range cTxn.BlockStakeInputs {
+		creation, err := getOutputCreation(tx, BlockStakeOutputCreationHeights, bsi.ParentID[:], pendingBlockTime)
+		if err == errNilItem {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		cTxn.SpentBlockStakeOutputCreations[bsi.ParentID] = creation
+	}
+	return nil
+}
+
 // TryTransactionSet applies the input transactions to the consensus set to
 // determine if they are valid. An error is returned IFF they are not a valid
 // set in the current consensus set. The size of the transactions and the set
@@ -103,6 +132,12 @@ func (cs *ConsensusSet) TryTransactionSet(txns []types.Transaction) (modules.Con
 					return fmt.Errorf("failed to find block stake input %s from txn %s as unspent block stake output in the consensus state: %v", bsi.ParentID.String(), txn.ID().String(), err)
 				}
 			}
+			// outputs created by the transaction set are created by the next block,
+			// for which we use the timestamp of the current block as an approximation
+			err = addSpentOutputCreations(tx, &cTxn, blockTime)
+			if err != nil {
+				return fmt.Errorf("failed to find the creation of the outputs spent by txn %s: %v", txn.ID().String(), err)
+			}
 
 			// a transaction can only be "block creating" in the context of a block,
 			// which we don't have here, so just pass in false for the "isBlockCreatingTx"
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"bytes"
 	"math/big"
 
 	"github.com/threefoldtech/rivine/types"
@@ -61,6 +62,98 @@ type (
 		BlockStakeOutputCounts []uint64 `json:"blockstakeoutputcounts"`
 	}
 
+	// AddressBalance is the balance of an address, as it is at a given block height.
+	// The confirmed balance is the sum of the locked and unlocked balance,
+	// where the locked balance consists of outputs which cannot be spent yet,
+	// such as time-locked outputs and immature miner payouts.
+	AddressBalance struct {
+		Address types.UnlockHash  `json:"address"`
+		Height  types.BlockHeight `json:"height"`
+
+		ConfirmedCoinBalance types.Currency `json:"confirmedcoinbalance"`
+		LockedCoinBalance    types.Currency `json:"lockedcoinbalance"`
+		UnlockedCoinBalance  types.Currency `json:"unlockedcoinbalance"`
+
+		ConfirmedBlockStakeBalance types.Currency `json:"confirmedblockstakebalance"`
+		LockedBlockStakeBalance    types.Currency `json:"lockedblockstakebalance"`
+		UnlockedBlockStakeBalance  types.Currency `json:"unlockedblockstakebalance"`
+	}
+
+	// AddressOutputs are the unspent outputs of an address, as they are at a given block height.
+	AddressOutputs struct {
+		Address           types.UnlockHash          `json:"address"`
+		Height            types.BlockHeight         `json:"height"`
+		CoinOutputs       []AddressCoinOutput       `json:"coinoutputs"`
+		BlockStakeOutputs []AddressBlockStakeOutput `json:"blockstakeoutputs"`
+	}
+
+	// AddressCoinOutput is an unspent coin output of an address.
+	AddressCoinOutput struct {
+		ID types.CoinOutputID `json:"id"`
+		types.CoinOutput
+		// MaturityHeight is only defined for miner payouts,
+		// which cannot be spent prior to this height.
+		MaturityHeight types.BlockHeight `json:"maturityheight,omitempty"`
+		Locked         bool              `json:"locked"`
+	}
+
+	// AddressBlockStakeOutput is an unspent block stake output of an address.
+	AddressBlockStakeOutput struct {
+		ID types.BlockStakeOutputID `json:"id"`
+		types.BlockStakeOutput
+		Locked bool `json:"locked"`
+	}
+
+	// SupplyStats are statistics about the coin and block stake supply,
+	// as they are at a given block height. The circulating coins are the total coins
+	// minus the locked coins, where the locked coins are the unspent time-locked
+	// outputs which cannot be spent yet. Burned coins are the coins spent by transactions
+	// without being sent to an output or paid as a fee, such as coin destruction transactions.
+	SupplyStats struct {
+		Height    types.BlockHeight `json:"height"`
+		Timestamp types.Timestamp   `json:"timestamp"`
+
+		TotalCoins       types.Currency `json:"totalcoins"`
+		LockedCoins      types.Currency `json:"lockedcoins"`
+		CirculatingCoins types.Currency `json:"circulatingcoins"`
+		BurnedCoins      types.Currency `json:"burnedcoins"`
+		TotalBlockStakes types.Currency `json:"totalblockstakes"`
+
+		CoinHolderCount       uint64 `json:"coinholdercount"`
+		BlockStakeHolderCount uint64 `json:"blockstakeholdercount"`
+	}
+
+	// AddressAmount is the (confirmed) amount of coins or block stakes owned by an address.
+	AddressAmount struct {
+		Address types.UnlockHash `json:"address"`
+		Amount  types.Currency   `json:"amount"`
+	}
+
+	// AddressTransactionsFilter is used to filter and paginate
+	// the transactions linked to an address.
+	AddressTransactionsFilter struct {
+		// MinHeight is the minimum block height of the transactions to return.
+		MinHeight types.BlockHeight
+		// Cursor is the cursor returned by a previous call,
+		// used to continue after the last transaction returned by that call.
+		Cursor string
+		// Limit is the maximum amount of transactions to return,
+		// all transactions are returned if it is 0.
+		Limit int
+		// Descending returns the most recent transactions first if true.
+		Descending bool
+		// ArbitraryData, if defined, only returns the transactions
+		// with arbitrary data matching the query, such as a memo.
+		ArbitraryData *ArbitraryDataQuery
+	}
+
+	// ArbitraryDataQuery is used to look up transactions by their arbitrary data,
+	// either matching the data exactly, or only its prefix.
+	ArbitraryDataQuery struct {
+		Data   []byte
+		Prefix bool
+	}
+
 	// DaemonConstants represent the constants in use by the daemon
 	DaemonConstants struct {
 		ChainInfo types.BlockchainInfo `json:"chaininfo"`
@@ -121,9 +214,28 @@ type (
 		// provided unlock hash.
 		UnlockHash(types.UnlockHash) []types.TransactionID
 
+		// AddressTransactions returns the ids of the transactions associated with the
+		// provided unlock hash, sorted by block height and filtered using the given filter,
+		// as well as the cursor to get the next transactions with, if any.
+		AddressTransactions(types.UnlockHash, AddressTransactionsFilter) ([]types.TransactionID, string, error)
+
+		// ArbitraryDataTransactions returns the ids of the transactions with arbitrary data
+		// matching the given query, paginated using the given cursor and limit, as well as
+		// the cursor to get the next transactions with, if any. An error is returned
+		// if the explorer is not configured to index arbitrary data.
+		ArbitraryDataTransactions(query ArbitraryDataQuery, cursor string, limit int) ([]types.TransactionID, string, error)
+
 		// MultiSigAddresses returns all multisig addresses this wallet address is involved in.
 		MultiSigAddresses(types.UnlockHash) []types.UnlockHash
 
+		// AddressBalance returns the confirmed, locked and unlocked
+		// balance of the provided unlock hash.
+		AddressBalance(types.UnlockHash) AddressBalance
+
+		// AddressOutputs returns the unspent coin and block stake outputs
+		// of the provided unlock hash.
+		AddressOutputs(types.UnlockHash) AddressOutputs
+
 		// CoinOutput will return the coin output associated with the
 		// input id.
 		CoinOutput(types.CoinOutputID) (types.CoinOutput, bool)
@@ -146,6 +258,22 @@ type (
 		// RangeStats return the stats for the range [`start`, `end`]
 		RangeStats(types.BlockHeight, types.BlockHeight) (*ChainStats, error)
 
+		// SupplyStats returns the coin and block stake supply statistics
+		// at the latest block height of the explorer.
+		SupplyStats() SupplyStats
+
+		// SupplyStatsHistory returns up to the given amount of the most recent
+		// snapshots of the supply statistics, the most recent snapshot first.
+		SupplyStatsHistory(int) []SupplyStats
+
+		// RichList returns up to the given amount of addresses
+		// with the highest coin balance, sorted by balance.
+		RichList(int) []AddressAmount
+
+		// BlockStakeDistribution returns up to the given amount of addresses
+		// with the highest block stake balance, sorted by balance.
+		BlockStakeDistribution(int) []AddressAmount
+
 		// Constants returns the constants in use by the chain
 		Constants() DaemonConstants
 
@@ -153,6 +281,14 @@ type (
 	}
 )
 
+// Matches returns true if the given arbitrary data matches the query.
+func (q ArbitraryDataQuery) Matches(data []byte) bool {
+	if q.Prefix {
+		return bytes.HasPrefix(data, q.Data)
+	}
+	return bytes.Equal(data, q.Data)
+}
+
 // NewChainStats initializes a new `ChainStats` object
 func NewChainStats(size int) *ChainStats {
 	if size <= 0 {
@@ -0,0 +1,391 @@
+package explorer
+
+import (
+	"fmt"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// addressBalance is the confirmed balance of an address,
+	// stored in bucketAddressBalances, such that it doesn't have to be
+	// computed from the unspent outputs of an address for every lookup.
+	addressBalance struct {
+		Coins       types.Currency
+		BlockStakes types.Currency
+	}
+
+	// addressOutput is an unspent output of an address, stored in
+	// bucketAddressCoinOutputs or bucketAddressBlockStakeOutputs.
+	addressOutput struct {
+		Value     types.Currency
+		Condition types.UnlockConditionProxy
+		// MaturityHeight is the height from which the output can be spent,
+		// only defined for miner payouts, as these are delayed by the consensus set.
+		MaturityHeight types.BlockHeight
+		// CreationHeight and CreationTime define the block which created the output,
+		// required by conditions relative to the creation of the output.
+		CreationHeight types.BlockHeight
+		CreationTime   types.Timestamp
+	}
+)
+
+// AddressBalance returns the confirmed, locked and unlocked balance of the given address,
+// as it is at the latest block height of the explorer.
+func (e *Explorer) AddressBalance(uh types.UnlockHash) (balance modules.AddressBalance) {
+	balance.Address = uh
+	err := e.db.View(func(tx *bolt.Tx) error {
+		ctx, err := e.dbGetFulfillableContext(tx)
+		if err != nil {
+			return err
+		}
+		balance.Height = ctx.BlockHeight
+
+		var ab addressBalance
+		err = dbGetAndDecode(bucketAddressBalances, uh, &ab)(tx)
+		if err == errNotExist {
+			return nil // address has no unspent outputs
+		}
+		if err != nil {
+			return err
+		}
+		balance.ConfirmedCoinBalance = ab.Coins
+		balance.ConfirmedBlockStakeBalance = ab.BlockStakes
+
+		// only the locked balance has to be computed,
+		// as it depends on the height and time of the latest block
+		err = dbForEachAddressOutput(tx, bucketAddressCoinOutputs, uh, func(_ []byte, output addressOutput) error {
+			if !output.spendable(ctx) {
+				balance.LockedCoinBalance = balance.LockedCoinBalance.Add(output.Value)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		err = dbForEachAddressOutput(tx, bucketAddressBlockStakeOutputs, uh, func(_ []byte, output addressOutput) error {
+			if !output.spendable(ctx) {
+				balance.LockedBlockStakeBalance = balance.LockedBlockStakeBalance.Add(output.Value)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		balance.UnlockedCoinBalance = balance.ConfirmedCoinBalance.Sub(balance.LockedCoinBalance)
+		balance.UnlockedBlockStakeBalance = balance.ConfirmedBlockStakeBalance.Sub(balance.LockedBlockStakeBalance)
+		return nil
+	})
+	if err != nil {
+		build.Critical(err)
+	}
+	return
+}
+
+// AddressOutputs returns all unspent coin and block stake outputs of the given address,
+// as they are at the latest block height of the explorer.
+func (e *Explorer) AddressOutputs(uh types.UnlockHash) (outputs modules.AddressOutputs) {
+	outputs.Address = uh
+	err := e.db.View(func(tx *bolt.Tx) error {
+		ctx, err := e.dbGetFulfillableContext(tx)
+		if err != nil {
+			return err
+		}
+		outputs.Height = ctx.BlockHeight
+
+		err = dbForEachAddressOutput(tx, bucketAddressCoinOutputs, uh, func(key []byte, output addressOutput) error {
+			var id types.CoinOutputID
+			err := siabin.Unmarshal(key, &id)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal coin output ID: %v", err)
+			}
+			outputs.CoinOutputs = append(outputs.CoinOutputs, modules.AddressCoinOutput{
+				ID: id,
+				CoinOutput: types.CoinOutput{
+					Value:     output.Value,
+					Condition: output.Condition,
+				},
+				MaturityHeight: output.MaturityHeight,
+				Locked:         !output.spendable(ctx),
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return dbForEachAddressOutput(tx, bucketAddressBlockStakeOutputs, uh, func(key []byte, output addressOutput) error {
+			var id types.BlockStakeOutputID
+			err := siabin.Unmarshal(key, &id)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal block stake output ID: %v", err)
+			}
+			outputs.BlockStakeOutputs = append(outputs.BlockStakeOutputs, modules.AddressBlockStakeOutput{
+				ID: id,
+				BlockStakeOutput: types.BlockStakeOutput{
+					Value:     output.Value,
+					Condition: output.Condition,
+				},
+				Locked: !output.spendable(ctx),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		build.Critical(err)
+	}
+	return
+}
+
+// spendable returns true if the output can be spent in the given context.
+func (output addressOutput) spendable(ctx types.FulfillableContext) bool {
+	if ctx.BlockHeight < output.MaturityHeight {
+		return false
+	}
+	ctx.OutputCreationHeight = output.CreationHeight
+	ctx.OutputCreationTime = output.CreationTime
+	ctx.HasOutputCreation = true
+	return output.Condition.Fulfillable(ctx)
+}
+
+// lockableCondition returns whether an output with the given condition can be locked,
+// which is the case for all conditions except those that are always fulfillable,
+// including the (nested) conditions of extensions such as relative time locks and vaults.
+func lockableCondition(condition types.UnlockConditionProxy) bool {
+	switch condition.ConditionType() {
+	case types.ConditionTypeNil, types.ConditionTypeUnlockHash,
+		types.ConditionTypeAtomicSwap, types.ConditionTypeMultiSignature:
+		return false
+	default:
+		return true
+	}
+}
+
+// dbGetOutputCreation returns the height and time of the block which created the given output,
+// being the lowest height of the transactions indexed in the given bucket for that output.
+func (e *Explorer) dbGetOutputCreation(tx *bolt.Tx, bucket []byte, id interface{}) (types.BlockHeight, types.Timestamp) {
+	var txids []types.TransactionID
+	assertNil(dbGetTransactionIDSet(bucket, id, &txids)(tx))
+	var creation types.BlockHeight
+	for idx, txid := range txids {
+		var height types.BlockHeight
+		assertNil(dbGetAndDecode(bucketTransactionIDs, txid, &height)(tx))
+		if idx == 0 || height < creation {
+			creation = height
+		}
+	}
+	block, exists := e.cs.BlockAtHeight(creation)
+	if !exists {
+		panic(fmt.Errorf("consensus set is missing block at height %d", creation))
+	}
+	return creation, block.Timestamp
+}
+
+// dbGetFulfillableContext returns the fulfillable context of the latest block of the explorer.
+func (e *Explorer) dbGetFulfillableContext(tx *bolt.Tx) (types.FulfillableContext, error) {
+	var height types.BlockHeight
+	err := dbGetInternal(internalBlockHeight, &height)(tx)
+	if err != nil {
+		return types.FulfillableContext{}, err
+	}
+	block, exists := e.cs.BlockAtHeight(height)
+	if !exists {
+		return types.FulfillableContext{}, fmt.Errorf("consensus set is missing block at height %d", height)
+	}
+	return types.FulfillableContext{
+		BlockHeight: height,
+		BlockTime:   block.Timestamp,
+	}, nil
+}
+
+// dbForEachAddressOutput calls the given callback for each unspent output
+// of the given address, stored in the given bucket.
+func dbForEachAddressOutput(tx *bolt.Tx, bucket []byte, uh types.UnlockHash, fn func(key []byte, output addressOutput) error) error {
+	uhb, err := siabin.Marshal(uh)
+	if err != nil {
+		return fmt.Errorf("failed to (siabin) marshal unlock hash: %v", err)
+	}
+	b := tx.Bucket(bucket).Bucket(uhb)
+	if b == nil {
+		return nil // no outputs
+	}
+	return b.ForEach(func(k, v []byte) error {
+		var output addressOutput
+		err := siabin.Unmarshal(v, &output)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal address output: %v", err)
+		}
+		return fn(k, output)
+	})
+}
+
+// The functions below panic on error. The panic will be caught by
+// ProcessConsensusChange.
+
+// dbApplyAddressIndex updates the unspent outputs and balances of all addresses
+// affected by the given (applied) block. It has to be called after
+// the outputs of the block have been added to bucketCoinOutputs and bucketBlockStakeOutputs.
+func (e *Explorer) dbApplyAddressIndex(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	for j, payout := range block.MinerPayouts {
+		dbAddAddressCoinOutput(tx, block.MinerPayoutID(uint64(j)), addressOutput{
+			Value:          payout.Value,
+			Condition:      types.NewCondition(types.NewUnlockHashCondition(payout.UnlockHash)),
+			MaturityHeight: height + e.chainCts.MaturityDelay,
+			CreationHeight: height,
+			CreationTime:   block.Timestamp,
+		})
+	}
+	for _, txn := range block.Transactions {
+		for _, ci := range txn.CoinInputs {
+			dbSpendAddressCoinOutput(tx, ci.ParentID)
+		}
+		for k, co := range txn.CoinOutputs {
+			dbAddAddressCoinOutput(tx, txn.CoinOutputID(uint64(k)), addressOutput{
+				Value:          co.Value,
+				Condition:      co.Condition,
+				CreationHeight: height,
+				CreationTime:   block.Timestamp,
+			})
+		}
+		for _, bsi := range txn.BlockStakeInputs {
+			dbSpendAddressBlockStakeOutput(tx, bsi.ParentID)
+		}
+		for k, bso := range txn.BlockStakeOutputs {
+			dbAddAddressBlockStakeOutput(tx, txn.BlockStakeOutputID(uint64(k)), addressOutput{
+				Value:          bso.Value,
+				Condition:      bso.Condition,
+				CreationHeight: height,
+				CreationTime:   block.Timestamp,
+			})
+		}
+	}
+}
+
+// dbRevertAddressIndex reverts the changes dbApplyAddressIndex made for the given block.
+// It has to be called before the outputs of the block are removed from
+// bucketCoinOutputs and bucketBlockStakeOutputs.
+func (e *Explorer) dbRevertAddressIndex(tx *bolt.Tx, block types.Block) {
+	// revert in the opposite order, as transactions
+	// can spend outputs created by earlier transactions of the same block
+	for i := len(block.Transactions) - 1; i >= 0; i-- {
+		txn := block.Transactions[i]
+		for k, bso := range txn.BlockStakeOutputs {
+			dbRemoveAddressOutput(tx, bucketAddressBlockStakeOutputs, bso.Condition.UnlockHash(), txn.BlockStakeOutputID(uint64(k)), false)
+		}
+		for _, bsi := range txn.BlockStakeInputs {
+			var bso types.BlockStakeOutput
+			assertNil(dbGetAndDecode(bucketBlockStakeOutputs, bsi.ParentID, &bso)(tx))
+			creationHeight, creationTime := e.dbGetOutputCreation(tx, bucketBlockStakeOutputIDs, bsi.ParentID)
+			dbAddAddressBlockStakeOutput(tx, bsi.ParentID, addressOutput{
+				Value:          bso.Value,
+				Condition:      bso.Condition,
+				CreationHeight: creationHeight,
+				CreationTime:   creationTime,
+			})
+		}
+		for k, co := range txn.CoinOutputs {
+			dbRemoveAddressOutput(tx, bucketAddressCoinOutputs, co.Condition.UnlockHash(), txn.CoinOutputID(uint64(k)), true)
+		}
+		for _, ci := range txn.CoinInputs {
+			var co types.CoinOutput
+			assertNil(dbGetAndDecode(bucketCoinOutputs, ci.ParentID, &co)(tx))
+			creationHeight, creationTime := e.dbGetOutputCreation(tx, bucketCoinOutputIDs, ci.ParentID)
+			// a miner payout can only be spent once matured,
+			// hence its maturity height is no longer relevant once restored
+			dbAddAddressCoinOutput(tx, ci.ParentID, addressOutput{
+				Value:          co.Value,
+				Condition:      co.Condition,
+				CreationHeight: creationHeight,
+				CreationTime:   creationTime,
+			})
+		}
+	}
+	for j, payout := range block.MinerPayouts {
+		dbRemoveAddressOutput(tx, bucketAddressCoinOutputs, payout.UnlockHash, block.MinerPayoutID(uint64(j)), true)
+	}
+}
+
+// Add/Spend unspent coin output of an address
+func dbAddAddressCoinOutput(tx *bolt.Tx, id types.CoinOutputID, output addressOutput) {
+	dbAddAddressOutput(tx, bucketAddressCoinOutputs, id, output, true)
+}
+func dbSpendAddressCoinOutput(tx *bolt.Tx, id types.CoinOutputID) {
+	var co types.CoinOutput
+	assertNil(dbGetAndDecode(bucketCoinOutputs, id, &co)(tx))
+	dbRemoveAddressOutput(tx, bucketAddressCoinOutputs, co.Condition.UnlockHash(), id, true)
+}
+
+// Add/Spend unspent block stake output of an address
+func dbAddAddressBlockStakeOutput(tx *bolt.Tx, id types.BlockStakeOutputID, output addressOutput) {
+	dbAddAddressOutput(tx, bucketAddressBlockStakeOutputs, id, output, false)
+}
+func dbSpendAddressBlockStakeOutput(tx *bolt.Tx, id types.BlockStakeOutputID) {
+	var bso types.BlockStakeOutput
+	assertNil(dbGetAndDecode(bucketBlockStakeOutputs, id, &bso)(tx))
+	dbRemoveAddressOutput(tx, bucketAddressBlockStakeOutputs, bso.Condition.UnlockHash(), id, false)
+}
+
+func dbAddAddressOutput(tx *bolt.Tx, bucket []byte, id interface{}, output addressOutput, coins bool) {
+	uh := output.Condition.UnlockHash()
+	b, err := tx.Bucket(bucket).CreateBucketIfNotExists(assertSiaMarshal(uh))
+	assertNil(err)
+	mustPut(b, id, output)
+	if coins && lockableCondition(output.Condition) {
+		mustPut(tx.Bucket(bucketLockableCoinOutputs), id, output)
+	}
+	dbUpdateAddressBalance(tx, uh, func(balance *addressBalance) {
+		if coins {
+			balance.Coins = balance.Coins.Add(output.Value)
+		} else {
+			balance.BlockStakes = balance.BlockStakes.Add(output.Value)
+		}
+	})
+}
+func dbRemoveAddressOutput(tx *bolt.Tx, bucket []byte, uh types.UnlockHash, id interface{}, coins bool) {
+	ab := tx.Bucket(bucket)
+	uhb := assertSiaMarshal(uh)
+	b := ab.Bucket(uhb)
+	if b == nil {
+		build.Critical(fmt.Errorf("no unspent outputs indexed for address %s", uh.String()))
+		return
+	}
+	idb := assertSiaMarshal(id)
+	var output addressOutput
+	assertNil(siabin.Unmarshal(b.Get(idb), &output))
+	assertNil(b.Delete(idb))
+	if bucketIsEmpty(b) {
+		assertNil(ab.DeleteBucket(uhb))
+	}
+	if coins && lockableCondition(output.Condition) {
+		assertNil(tx.Bucket(bucketLockableCoinOutputs).Delete(idb))
+	}
+	dbUpdateAddressBalance(tx, uh, func(balance *addressBalance) {
+		if coins {
+			balance.Coins = balance.Coins.Sub(output.Value)
+		} else {
+			balance.BlockStakes = balance.BlockStakes.Sub(output.Value)
+		}
+	})
+}
+
+// dbUpdateAddressBalance updates the balance of an address using the given callback,
+// deleting the balance once it has no coins and block stakes left.
+// The supply statistics and rich lists are updated accordingly.
+func dbUpdateAddressBalance(tx *bolt.Tx, uh types.UnlockHash, fn func(*addressBalance)) {
+	var balance addressBalance
+	err := dbGetAndDecode(bucketAddressBalances, uh, &balance)(tx)
+	if err != nil && err != errNotExist {
+		build.Critical(err)
+	}
+	previous := balance
+	fn(&balance)
+	dbUpdateSupply(tx, uh, previous, balance)
+	if balance.Coins.IsZero() && balance.BlockStakes.IsZero() {
+		mustDelete(tx.Bucket(bucketAddressBalances), uh)
+		return
+	}
+	mustPut(tx.Bucket(bucketAddressBalances), uh, balance)
+}
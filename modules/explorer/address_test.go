@@ -0,0 +1,513 @@
+package explorer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/extensions/relativetimelock"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func newAddressIndexTester(t *testing.T) *Explorer {
+	testdir := build.TempDir(modules.ExplorerDir, t.Name())
+	err := os.MkdirAll(testdir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := persist.OpenDatabase(explorerMetadata, filepath.Join(testdir, "explorer.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{
+			bucketBlockIDs,
+			bucketCoinOutputIDs,
+			bucketCoinOutputs,
+			bucketBlockStakeOutputIDs,
+			bucketBlockStakeOutputs,
+			bucketTransactionIDs,
+			bucketAddressBalances,
+			bucketAddressCoinOutputs,
+			bucketAddressBlockStakeOutputs,
+			bucketAddressTransactions,
+			bucketInternal,
+			bucketCoinRichList,
+			bucketBlockStakeRichList,
+			bucketLockableCoinOutputs,
+			bucketSupplyStatsHistory,
+			bucketArbitraryDataHashes,
+			bucketArbitraryDataPrefixes,
+		} {
+			_, err := tx.CreateBucket(b)
+			if err != nil {
+				return err
+			}
+		}
+		return dbSetInternal(internalSupplyStats, supplyStats{})(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Explorer{
+		cs:       &outputLookupTester{},
+		db:       db,
+		chainCts: types.TestnetChainConstants(),
+	}
+}
+
+// applyTestBlock mimics the order in which ProcessConsensusChange updates the database
+func (e *Explorer) applyTestBlock(t *testing.T, block types.Block, height types.BlockHeight) {
+	err := e.db.Update(func(tx *bolt.Tx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		if cs, ok := e.cs.(*outputLookupTester); ok {
+			cs.blocks = append(cs.blocks[:height], block)
+		}
+		tbid := types.TransactionID(block.ID())
+		dbAddTransactionID(tx, tbid, height)
+		for j, payout := range block.MinerPayouts {
+			dbAddCoinOutputID(tx, block.MinerPayoutID(uint64(j)), tbid)
+			dbAddCoinOutput(tx, block.MinerPayoutID(uint64(j)), types.CoinOutput{
+				Value:     payout.Value,
+				Condition: types.NewCondition(types.NewUnlockHashCondition(payout.UnlockHash)),
+			})
+		}
+		for _, txn := range block.Transactions {
+			txid := txn.ID()
+			dbAddTransactionID(tx, txid, height)
+			for _, ci := range txn.CoinInputs {
+				dbAddCoinOutputID(tx, ci.ParentID, txid)
+			}
+			for k, co := range txn.CoinOutputs {
+				dbAddCoinOutputID(tx, txn.CoinOutputID(uint64(k)), txid)
+				dbAddCoinOutput(tx, txn.CoinOutputID(uint64(k)), co)
+			}
+			for _, bsi := range txn.BlockStakeInputs {
+				dbAddBlockStakeOutputID(tx, bsi.ParentID, txid)
+			}
+			for k, bso := range txn.BlockStakeOutputs {
+				dbAddBlockStakeOutputID(tx, txn.BlockStakeOutputID(uint64(k)), txid)
+				dbAddBlockStakeOutput(tx, txn.BlockStakeOutputID(uint64(k)), bso)
+			}
+		}
+		e.dbApplyAddressIndex(tx, block, height)
+		dbApplyAddressHistory(tx, block, height)
+		dbApplySupplyStats(tx, block, height)
+		if e.indexArbitraryData {
+			dbApplyArbitraryDataIndex(tx, block, height)
+		}
+		dbAddBlockID(tx, block.ID(), height)
+		return dbSetInternal(internalBlockHeight, height)(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// revertTestBlock mimics the order in which ProcessConsensusChange updates the database
+func (e *Explorer) revertTestBlock(t *testing.T, block types.Block, height types.BlockHeight) {
+	err := e.db.Update(func(tx *bolt.Tx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		dbRemoveBlockID(tx, block.ID())
+		if height > 0 {
+			assertNil(dbSetInternal(internalBlockHeight, height-1)(tx))
+		}
+		dbRevertSupplyStats(tx, block, height)
+		e.dbRevertAddressIndex(tx, block)
+		dbRevertAddressHistory(tx, block, height)
+		if e.indexArbitraryData {
+			dbRevertArbitraryDataIndex(tx, block, height)
+		}
+		tbid := types.TransactionID(block.ID())
+		dbRemoveTransactionID(tx, tbid)
+		for j := range block.MinerPayouts {
+			dbRemoveCoinOutputID(tx, block.MinerPayoutID(uint64(j)), tbid)
+			dbRemoveCoinOutput(tx, block.MinerPayoutID(uint64(j)))
+		}
+		for _, txn := range block.Transactions {
+			txid := txn.ID()
+			dbRemoveTransactionID(tx, txid)
+			for _, ci := range txn.CoinInputs {
+				dbRemoveCoinOutputID(tx, ci.ParentID, txid)
+			}
+			for k := range txn.CoinOutputs {
+				dbRemoveCoinOutputID(tx, txn.CoinOutputID(uint64(k)), txid)
+				dbRemoveCoinOutput(tx, txn.CoinOutputID(uint64(k)))
+			}
+			for _, bsi := range txn.BlockStakeInputs {
+				dbRemoveBlockStakeOutputID(tx, bsi.ParentID, txid)
+			}
+			for k := range txn.BlockStakeOutputs {
+				dbRemoveBlockStakeOutputID(tx, txn.BlockStakeOutputID(uint64(k)), txid)
+				dbRemoveBlockStakeOutput(tx, txn.BlockStakeOutputID(uint64(k)))
+			}
+		}
+		if cs, ok := e.cs.(*outputLookupTester); ok && types.BlockHeight(len(cs.blocks)) > height {
+			cs.blocks = cs.blocks[:height]
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (e *Explorer) testAddressBalance(t *testing.T, uh types.UnlockHash, coins, blockStakes uint64, outputs int) {
+	t.Helper()
+	err := e.db.View(func(tx *bolt.Tx) error {
+		var balance addressBalance
+		err := dbGetAndDecode(bucketAddressBalances, uh, &balance)(tx)
+		if err != nil && err != errNotExist {
+			return err
+		}
+		if !balance.Coins.Equals64(coins) {
+			t.Errorf("expected %d coins for %s, got %s", coins, uh.String(), balance.Coins.String())
+		}
+		if !balance.BlockStakes.Equals64(blockStakes) {
+			t.Errorf("expected %d block stakes for %s, got %s", blockStakes, uh.String(), balance.BlockStakes.String())
+		}
+		var n int
+		count := func([]byte, addressOutput) error {
+			n++
+			return nil
+		}
+		err = dbForEachAddressOutput(tx, bucketAddressCoinOutputs, uh, count)
+		if err != nil {
+			return err
+		}
+		err = dbForEachAddressOutput(tx, bucketAddressBlockStakeOutputs, uh, count)
+		if err != nil {
+			return err
+		}
+		if n != outputs {
+			t.Errorf("expected %d unspent outputs for %s, got %d", outputs, uh.String(), n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddressIndex(t *testing.T) {
+	e := newAddressIndexTester(t)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+
+	genesis := types.Block{
+		Transactions: []types.Transaction{{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(100), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+			BlockStakeOutputs: []types.BlockStakeOutput{
+				{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			},
+		}},
+	}
+	e.applyTestBlock(t, genesis, 0)
+	e.testAddressBalance(t, alice, 100, 0, 1)
+	e.testAddressBalance(t, bob, 0, 10, 1)
+
+	// alice sends 60 coins to bob, of which 20 are time-locked,
+	// after which bob sends 30 of those coins back within the same block
+	txn1 := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: genesis.Transactions[0].CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(40), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			{Value: types.NewCurrency64(20), Condition: types.NewCondition(types.NewTimeLockCondition(1000, types.NewUnlockHashCondition(bob)))},
+			{Value: types.NewCurrency64(39), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	txn2 := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: txn1.CoinOutputID(0)}},
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(30), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			{Value: types.NewCurrency64(9), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	block := types.Block{
+		ParentID:     genesis.ID(),
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(2), UnlockHash: bob}},
+		Transactions: []types.Transaction{txn1, txn2},
+	}
+	e.applyTestBlock(t, block, 1)
+	e.testAddressBalance(t, alice, 69, 0, 2)
+	e.testAddressBalance(t, bob, 31, 10, 4)
+
+	// the time-locked output and the miner payout should be locked
+	err := e.db.View(func(tx *bolt.Tx) error {
+		var locked types.Currency
+		ctx := types.FulfillableContext{BlockHeight: 1}
+		err := dbForEachAddressOutput(tx, bucketAddressCoinOutputs, bob, func(_ []byte, output addressOutput) error {
+			if !output.spendable(ctx) {
+				locked = locked.Add(output.Value)
+			}
+			return nil
+		})
+		if !locked.Equals64(22) {
+			t.Errorf("expected 22 locked coins for bob, got %s", locked.String())
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reverting the blocks should restore the previous state
+	e.revertTestBlock(t, block, 1)
+	e.testAddressBalance(t, alice, 100, 0, 1)
+	e.testAddressBalance(t, bob, 0, 10, 1)
+	e.revertTestBlock(t, genesis, 0)
+	e.testAddressBalance(t, alice, 0, 0, 0)
+	e.testAddressBalance(t, bob, 0, 0, 0)
+}
+
+func TestAddressOutputCreation(t *testing.T) {
+	relativetimelock.RegisterUnlockConditionType()
+	defer relativetimelock.UnregisterUnlockConditionType()
+
+	e := newAddressIndexTester(t)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+	condition := types.NewCondition(relativetimelock.NewRelativeTimeLockCondition(
+		3, relativetimelock.LockUnitBlocks, types.NewUnlockHashCondition(alice)))
+	uh := condition.UnlockHash()
+
+	// alice receives coins which can only be spent 3 blocks after their creation
+	txn := types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(10), Condition: condition},
+		},
+	}
+	blocks := []types.Block{{}}
+	for height := 1; height < 6; height++ {
+		block := types.Block{
+			ParentID:  blocks[height-1].ID(),
+			Timestamp: types.Timestamp(height * 100),
+		}
+		switch height {
+		case 1:
+			block.Transactions = []types.Transaction{txn}
+		case 5:
+			block.Transactions = []types.Transaction{{
+				Version:    types.TransactionVersionOne,
+				CoinInputs: []types.CoinInput{{ParentID: txn.CoinOutputID(0)}},
+				CoinOutputs: []types.CoinOutput{
+					{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+				},
+			}}
+		}
+		blocks = append(blocks, block)
+	}
+
+	testLocked := func(locked uint64) {
+		t.Helper()
+		balance := e.AddressBalance(uh)
+		if !balance.LockedCoinBalance.Equals64(locked) {
+			t.Errorf("expected %d locked coins at height %d, got %s", locked, balance.Height, balance.LockedCoinBalance.String())
+		}
+	}
+	for height, block := range blocks[:4] {
+		e.applyTestBlock(t, block, types.BlockHeight(height))
+	}
+	testLocked(10)
+	e.applyTestBlock(t, blocks[4], 4)
+	testLocked(0)
+
+	// the output restored by reverting the block which spent it,
+	// should still be locked relative to the block which created it
+	e.applyTestBlock(t, blocks[5], 5)
+	e.testAddressBalance(t, uh, 0, 0, 0)
+	e.revertTestBlock(t, blocks[5], 5)
+	e.revertTestBlock(t, blocks[4], 4)
+	e.testAddressBalance(t, uh, 10, 0, 1)
+	testLocked(10)
+	err := e.db.View(func(tx *bolt.Tx) error {
+		return dbForEachAddressOutput(tx, bucketAddressCoinOutputs, uh, func(_ []byte, output addressOutput) error {
+			if output.CreationHeight != 1 || output.CreationTime != 100 {
+				t.Errorf("unexpected creation of restored output: height %d and time %d", output.CreationHeight, output.CreationTime)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddressHistory(t *testing.T) {
+	e := newAddressIndexTester(t)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+
+	// create a chain where each block pays alice through a miner payout,
+	// and contains a transaction in which alice sends her previous payout to bob
+	blocks := []types.Block{{
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(10), UnlockHash: alice}},
+	}}
+	expected := []types.TransactionID{types.TransactionID(blocks[0].ID())}
+	for height := 1; height < 5; height++ {
+		parent := blocks[height-1]
+		txn := types.Transaction{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: parent.MinerPayoutID(0)}},
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			},
+		}
+		block := types.Block{
+			ParentID:     parent.ID(),
+			MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(10), UnlockHash: alice}},
+			Transactions: []types.Transaction{txn},
+		}
+		blocks = append(blocks, block)
+		expected = append(expected, types.TransactionID(block.ID()), txn.ID())
+	}
+	for height, block := range blocks {
+		e.applyTestBlock(t, block, types.BlockHeight(height))
+	}
+
+	// get all transactions, in pages of 2, in both orders
+	for _, descending := range []bool{false, true} {
+		var (
+			ids    []types.TransactionID
+			cursor string
+		)
+		for {
+			page, next, err := e.AddressTransactions(alice, modules.AddressTransactionsFilter{
+				Cursor:     cursor,
+				Limit:      2,
+				Descending: descending,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if len(ids) != len(expected) {
+			t.Fatalf("expected %d transactions, got %d", len(expected), len(ids))
+		}
+		for idx := range ids {
+			expectedID := expected[idx]
+			if descending {
+				expectedID = expected[len(expected)-1-idx]
+			}
+			if ids[idx] != expectedID {
+				t.Errorf("unexpected transaction #%d (descending: %v): %s", idx, descending, ids[idx].String())
+			}
+		}
+	}
+
+	// filter by minimum height
+	ids, _, err := e.AddressTransactions(alice, modules.AddressTransactionsFilter{MinHeight: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != expected[7] || ids[1] != expected[8] {
+		t.Errorf("unexpected transactions for minimum height 4: %v", ids)
+	}
+	_, _, err = e.AddressTransactions(alice, modules.AddressTransactionsFilter{Cursor: "foo"})
+	if err != ErrInvalidHistoryCursor {
+		t.Errorf("expected invalid cursor error, got: %v", err)
+	}
+
+	// reverting the last block should remove its transactions
+	e.revertTestBlock(t, blocks[4], 4)
+	ids, _, err = e.AddressTransactions(bob, modules.AddressTransactionsFilter{Descending: true, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != expected[6] {
+		t.Errorf("unexpected most recent transaction of bob after revert: %v", ids)
+	}
+}
+
+type testExplorerTransactionExtension struct {
+	addresses []types.UnlockHash
+}
+
+func (testExplorerTransactionExtension) TransactionType() string { return "test" }
+func (testExplorerTransactionExtension) TransactionView(types.Transaction) (interface{}, error) {
+	return nil, nil
+}
+func (ext testExplorerTransactionExtension) TransactionAddresses(types.Transaction) ([]types.UnlockHash, error) {
+	return ext.addresses, nil
+}
+
+func TestAddressHistoryExtension(t *testing.T) {
+	const version = types.TransactionVersion(0xf0)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+	carol := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{3}}
+	types.RegisterTransactionVersion(version, types.DefaultTransactionController{})
+	modules.RegisterExplorerTransactionExtension(version, testExplorerTransactionExtension{
+		addresses: []types.UnlockHash{carol, bob},
+	})
+	defer func() {
+		modules.RegisterExplorerTransactionExtension(version, nil)
+		types.RegisterTransactionVersion(version, nil)
+	}()
+
+	e := newAddressIndexTester(t)
+	parent := types.Block{
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(10), UnlockHash: alice}},
+	}
+	txn := types.Transaction{
+		Version:    version,
+		CoinInputs: []types.CoinInput{{ParentID: parent.MinerPayoutID(0)}},
+		CoinOutputs: []types.CoinOutput{
+			{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+		},
+	}
+	block := types.Block{
+		ParentID:     parent.ID(),
+		Transactions: []types.Transaction{txn},
+	}
+	e.applyTestBlock(t, parent, 0)
+	e.applyTestBlock(t, block, 1)
+
+	// carol is only linked to the transaction through its extension,
+	// while bob is linked through both its output and its extension
+	for _, uh := range []types.UnlockHash{carol, bob} {
+		ids, _, err := e.AddressTransactions(uh, modules.AddressTransactionsFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 1 || ids[0] != txn.ID() {
+			t.Errorf("unexpected transactions of %s: %v", uh.String(), ids)
+		}
+	}
+
+	e.revertTestBlock(t, block, 1)
+	ids, _, err := e.AddressTransactions(carol, modules.AddressTransactionsFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("unexpected transactions of carol after revert: %v", ids)
+	}
+}
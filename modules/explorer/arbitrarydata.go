@@ -0,0 +1,162 @@
+package explorer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// ErrArbitraryDataIndexDisabled is returned when transactions are looked up
+	// by their arbitrary data, while the explorer doesn't index arbitrary data.
+	ErrArbitraryDataIndexDisabled = errors.New("arbitrary data index is disabled")
+	// ErrInvalidArbitraryDataQuery is returned when transactions are looked up
+	// using empty arbitrary data, or using a prefix which is too long.
+	ErrInvalidArbitraryDataQuery = fmt.Errorf(
+		"arbitrary data query has to be non-empty, and a prefix can be at most %d bytes", ArbitraryDataPrefixSize)
+	// ErrInvalidArbitraryDataCursor is returned when a cursor is given,
+	// which wasn't returned by the explorer as a next cursor.
+	ErrInvalidArbitraryDataCursor = errors.New("invalid arbitrary data cursor")
+)
+
+// ArbitraryDataPrefixSize is the maximum size of the prefix
+// transactions can be looked up by, using their arbitrary data.
+const ArbitraryDataPrefixSize = 64
+
+// Keys of the arbitrary data index are suffixed with the address history key
+// (see addressHistoryKey) of the transaction, such that transactions with
+// the same arbitrary data are sorted chronologically. Keys in bucketArbitraryDataHashes
+// are prefixed with the hash of the arbitrary data, while keys in bucketArbitraryDataPrefixes
+// are prefixed with (up to ArbitraryDataPrefixSize bytes of) the arbitrary data
+// and suffixed with the length of that prefix.
+
+// arbitraryDataHashKey returns the key of a transaction in bucketArbitraryDataHashes.
+func arbitraryDataHashKey(data []byte, height types.BlockHeight, order uint32) []byte {
+	hash := crypto.HashBytes(data)
+	return append(hash[:], addressHistoryKey(height, order)...)
+}
+
+// arbitraryDataPrefixKey returns the key of a transaction in bucketArbitraryDataPrefixes.
+func arbitraryDataPrefixKey(data []byte, height types.BlockHeight, order uint32) []byte {
+	if len(data) > ArbitraryDataPrefixSize {
+		data = data[:ArbitraryDataPrefixSize]
+	}
+	key := make([]byte, 0, len(data)+addressHistoryKeySize+1)
+	key = append(key, data...)
+	key = append(key, addressHistoryKey(height, order)...)
+	return append(key, byte(len(data)))
+}
+
+// arbitraryDataPrefixKeyData returns the (prefix of the) arbitrary data of a key in bucketArbitraryDataPrefixes.
+func arbitraryDataPrefixKeyData(key []byte) []byte {
+	return key[:int(key[len(key)-1])]
+}
+
+// ArbitraryDataTransactions returns the IDs of the transactions with arbitrary data
+// matching the given query, sorted by their arbitrary data (only relevant for prefix queries),
+// block height and order within the block. Up to limit IDs are returned (all if limit is 0),
+// as well as the cursor to use to get the next page. The returned cursor is empty when there are no more transactions.
+func (e *Explorer) ArbitraryDataTransactions(query modules.ArbitraryDataQuery, cursor string, limit int) (ids []types.TransactionID, next string, err error) {
+	if !e.indexArbitraryData {
+		return nil, "", ErrArbitraryDataIndexDisabled
+	}
+	if len(query.Data) == 0 || (query.Prefix && len(query.Data) > ArbitraryDataPrefixSize) {
+		return nil, "", ErrInvalidArbitraryDataQuery
+	}
+
+	var (
+		bucket []byte
+		prefix []byte
+		match  func(key []byte) bool
+	)
+	if query.Prefix {
+		bucket, prefix = bucketArbitraryDataPrefixes, query.Data
+		match = func(key []byte) bool {
+			// ensure the prefix doesn't overlap with the history key suffix
+			return len(arbitraryDataPrefixKeyData(key)) >= len(prefix)
+		}
+	} else {
+		hash := crypto.HashBytes(query.Data)
+		bucket, prefix = bucketArbitraryDataHashes, hash[:]
+		match = func([]byte) bool { return true }
+	}
+	var cursorKey []byte
+	if cursor != "" {
+		cursorKey, err = hex.DecodeString(cursor)
+		if err != nil || !bytes.HasPrefix(cursorKey, prefix) {
+			return nil, "", ErrInvalidArbitraryDataCursor
+		}
+	}
+
+	err = e.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		var k, v []byte
+		if cursorKey != nil {
+			k, v = c.Seek(cursorKey)
+			if k != nil && bytes.Equal(k, cursorKey) {
+				k, v = c.Next()
+			}
+		} else {
+			k, v = c.Seek(prefix)
+		}
+
+		var last []byte
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if !match(k) {
+				continue
+			}
+			if limit > 0 && len(ids) == limit {
+				// more transactions are available,
+				// continue after the last returned transaction
+				next = hex.EncodeToString(last)
+				break
+			}
+			var id types.TransactionID
+			err := siabin.Unmarshal(v, &id)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal transaction ID: %v", err)
+			}
+			ids = append(ids, id)
+			last = k
+		}
+		return nil
+	})
+	return
+}
+
+// The functions below panic on error. The panic will be caught by
+// ProcessConsensusChange.
+
+// dbApplyArbitraryDataIndex adds the transactions of the given (applied) block
+// with arbitrary data to the arbitrary data index.
+func dbApplyArbitraryDataIndex(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	hb, pb := tx.Bucket(bucketArbitraryDataHashes), tx.Bucket(bucketArbitraryDataPrefixes)
+	for i, txn := range block.Transactions {
+		if len(txn.ArbitraryData) == 0 {
+			continue
+		}
+		txid := assertSiaMarshal(txn.ID())
+		assertNil(hb.Put(arbitraryDataHashKey(txn.ArbitraryData, height, uint32(i+1)), txid))
+		assertNil(pb.Put(arbitraryDataPrefixKey(txn.ArbitraryData, height, uint32(i+1)), txid))
+	}
+}
+
+// dbRevertArbitraryDataIndex removes the transactions of the given (reverted) block
+// from the arbitrary data index.
+func dbRevertArbitraryDataIndex(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	hb, pb := tx.Bucket(bucketArbitraryDataHashes), tx.Bucket(bucketArbitraryDataPrefixes)
+	for i, txn := range block.Transactions {
+		if len(txn.ArbitraryData) == 0 {
+			continue
+		}
+		assertNil(hb.Delete(arbitraryDataHashKey(txn.ArbitraryData, height, uint32(i+1))))
+		assertNil(pb.Delete(arbitraryDataPrefixKey(txn.ArbitraryData, height, uint32(i+1))))
+	}
+}
@@ -0,0 +1,213 @@
+package explorer
+
+import (
+	"io/ioutil"
+	"testing"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// transactionLookupTester is a consensus set which only
+// supports looking up transactions by their ID.
+type transactionLookupTester struct {
+	modules.ConsensusSet
+	txns map[types.TransactionID]types.Transaction
+}
+
+func (cs transactionLookupTester) TransactionAtID(id types.TransactionID) (types.Transaction, types.TransactionShortID, bool) {
+	txn, ok := cs.txns[id]
+	return txn, 0, ok
+}
+
+func testTransactionIDs(t *testing.T, ids []types.TransactionID, expected ...types.TransactionID) {
+	t.Helper()
+	if len(ids) != len(expected) {
+		t.Fatalf("expected %d transactions, got %d: %v", len(expected), len(ids), ids)
+	}
+	for idx := range ids {
+		if ids[idx] != expected[idx] {
+			t.Errorf("unexpected transaction #%d: %s != %s", idx, ids[idx].String(), expected[idx].String())
+		}
+	}
+}
+
+func TestArbitraryDataIndex(t *testing.T) {
+	e := newAddressIndexTester(t)
+	e.indexArbitraryData = true
+	lookup := transactionLookupTester{txns: make(map[types.TransactionID]types.Transaction)}
+	e.cs = lookup
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+
+	// create a chain where each block pays alice using a transaction with a memo
+	memos := []string{"INV-1", "INV-10", "INV-2", "INV-1", "", "OTHER"}
+	blocks := []types.Block{{}}
+	var txids []types.TransactionID
+	for idx, memo := range memos {
+		txn := types.Transaction{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(uint64(idx + 1)), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+			ArbitraryData: []byte(memo),
+		}
+		lookup.txns[txn.ID()] = txn
+		txids = append(txids, txn.ID())
+		blocks = append(blocks, types.Block{
+			ParentID:     blocks[idx].ID(),
+			Transactions: []types.Transaction{txn},
+		})
+	}
+	for height, block := range blocks {
+		e.applyTestBlock(t, block, types.BlockHeight(height))
+	}
+
+	// exact matches are sorted chronologically
+	ids, next, err := e.ArbitraryDataTransactions(modules.ArbitraryDataQuery{Data: []byte("INV-1")}, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids, txids[0], txids[3])
+	if next != "" {
+		t.Errorf("unexpected next cursor: %s", next)
+	}
+
+	// prefix matches are sorted by data first, and can be paginated
+	query := modules.ArbitraryDataQuery{Data: []byte("INV-"), Prefix: true}
+	ids, next, err = e.ArbitraryDataTransactions(query, "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids, txids[0], txids[3], txids[1])
+	ids, next, err = e.ArbitraryDataTransactions(query, next, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids, txids[2])
+	if next != "" {
+		t.Errorf("unexpected next cursor: %s", next)
+	}
+
+	// invalid queries and cursors
+	_, _, err = e.ArbitraryDataTransactions(modules.ArbitraryDataQuery{Prefix: true}, "", 0)
+	if err != ErrInvalidArbitraryDataQuery {
+		t.Errorf("expected invalid query error, got: %v", err)
+	}
+	_, _, err = e.ArbitraryDataTransactions(query, "foo", 0)
+	if err != ErrInvalidArbitraryDataCursor {
+		t.Errorf("expected invalid cursor error, got: %v", err)
+	}
+
+	// the history of an address can be filtered by memo
+	ids, _, err = e.AddressTransactions(alice, modules.AddressTransactionsFilter{
+		ArbitraryData: &modules.ArbitraryDataQuery{Data: []byte("INV-1")},
+		Descending:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids, txids[3], txids[0])
+
+	// reverting blocks should remove their transactions from the index
+	e.revertTestBlock(t, blocks[6], 6)
+	e.revertTestBlock(t, blocks[5], 5)
+	e.revertTestBlock(t, blocks[4], 4)
+	ids, _, err = e.ArbitraryDataTransactions(modules.ArbitraryDataQuery{Data: []byte("INV-1")}, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids, txids[0])
+	ids, _, err = e.ArbitraryDataTransactions(modules.ArbitraryDataQuery{Data: []byte("OTHER")}, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids)
+
+	// the index can be disabled
+	e.indexArbitraryData = false
+	_, _, err = e.ArbitraryDataTransactions(query, "", 0)
+	if err != ErrArbitraryDataIndexDisabled {
+		t.Errorf("expected disabled index error, got: %v", err)
+	}
+}
+
+func TestBuildIndices(t *testing.T) {
+	e := newAddressIndexTester(t)
+	e.indexArbitraryData = true
+	e.log = persist.NewLogger(types.DefaultBlockchainInfo(), ioutil.Discard, false)
+	cs := &outputLookupTester{}
+	e.cs = cs
+
+	// an existing chain of which each block contains a transaction with a memo
+	var (
+		blocks []types.Block
+		txids  []types.TransactionID
+	)
+	for idx := 0; idx < 5; idx++ {
+		txn := types.Transaction{
+			Version:       types.TransactionVersionOne,
+			MinerFees:     []types.Currency{types.NewCurrency64(uint64(idx + 1))},
+			ArbitraryData: []byte("INV"),
+		}
+		txids = append(txids, txn.ID())
+		blocks = append(blocks, types.Block{Transactions: []types.Transaction{txn}})
+	}
+	err := e.db.Update(func(tx *bolt.Tx) error {
+		err := dbSetInternal(internalBlockHeight, types.BlockHeight(len(blocks)-1))(tx)
+		if err != nil {
+			return err
+		}
+		return dbSetIndexBuildProgress(tx, []indexBuildProgress{{Index: indexArbitraryData}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := modules.ArbitraryDataQuery{Data: []byte("INV")}
+
+	// an interrupted build keeps the batches committed prior to the interruption
+	cs.blocks = blocks[:3]
+	err = e.buildIndices(2)
+	if err == nil {
+		t.Fatal("build should fail on a missing block")
+	}
+	var progress []indexBuildProgress
+	err = e.db.View(func(tx *bolt.Tx) (err error) {
+		progress, err = dbGetIndexBuildProgress(tx)
+		return
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 1 || progress[0].Index != indexArbitraryData || progress[0].Height != 2 {
+		t.Fatalf("unexpected progress of interrupted build: %v", progress)
+	}
+	ids, _, err := e.ArbitraryDataTransactions(query, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids, txids[:2]...)
+
+	// the build is resumed from the last committed batch
+	cs.blocks = blocks
+	err = e.buildIndices(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, _, err = e.ArbitraryDataTransactions(query, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransactionIDs(t, ids, txids...)
+	err = e.db.View(func(tx *bolt.Tx) (err error) {
+		progress, err = dbGetIndexBuildProgress(tx)
+		return
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 0 {
+		t.Errorf("progress of a completed build should be deleted: %v", progress)
+	}
+}
@@ -27,12 +27,34 @@ var (
 	// used to map (single-signature) wallet addresses to all the
 	// multisig addresses they are part of
 	bucketWalletAddressToMultiSigAddressMapping = []byte("WalletAddressToMultiSigAddressMapping")
+	// used to store the confirmed balance and the unspent outputs of each address
+	bucketAddressBalances          = []byte("AddressBalances")
+	bucketAddressCoinOutputs       = []byte("AddressCoinOutputs")
+	bucketAddressBlockStakeOutputs = []byte("AddressBlockStakeOutputs")
+	// used to store the transactions of each address, sorted by block height
+	bucketAddressTransactions = []byte("AddressTransactions")
+	// used to store the addresses sorted by their coin and block stake balance
+	bucketCoinRichList       = []byte("CoinRichList")
+	bucketBlockStakeRichList = []byte("BlockStakeRichList")
+	// used to store the unspent coin outputs which can be locked, to compute the locked supply
+	bucketLockableCoinOutputs = []byte("LockableCoinOutputs")
+	// used to store only the unspent time-locked coin outputs, replaced by bucketLockableCoinOutputs
+	bucketLegacyTimeLockedCoinOutputs = []byte("TimeLockedCoinOutputs")
+	// used to store snapshots of the supply statistics, sorted by block height
+	bucketSupplyStatsHistory = []byte("SupplyStatsHistory")
+	// used to store the transactions with arbitrary data, by the hash and the prefix of that data,
+	// only if the explorer is configured to index arbitrary data
+	bucketArbitraryDataHashes   = []byte("ArbitraryDataHashes")
+	bucketArbitraryDataPrefixes = []byte("ArbitraryDataPrefixes")
 
 	errNotExist = errors.New("entry does not exist")
 
 	// keys for bucketInternal
 	internalBlockHeight  = []byte("BlockHeight")
 	internalRecentChange = []byte("RecentChange")
+	internalSupplyStats  = []byte("SupplyStats")
+	// internalIndexBuild stores the progress of the indices being built for an existing database
+	internalIndexBuild = []byte("IndexBuild")
 )
 
 // These functions all return a 'func(*bolt.Tx) error', which, allows them to
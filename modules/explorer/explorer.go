@@ -45,12 +45,45 @@ type (
 		rootTarget     types.Target
 		genesisBlock   types.Block
 		genesisBlockID types.BlockID
+
+		// indexArbitraryData defines if the transactions
+		// are indexed by their arbitrary data
+		indexArbitraryData bool
+
+		// onUpdate, if defined, is called after each processed consensus change,
+		// instead of treating a failed update as a critical error
+		onUpdate func(height types.BlockHeight, err error)
 	}
 )
 
 // New creates the internal data structures, and subscribes to
-// consensus for changes to the blockchain
-func New(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, verboseLogging bool) (*Explorer, error) {
+// consensus for changes to the blockchain. Transactions are only
+// indexed by their arbitrary data if indexArbitraryData is true.
+func New(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, verboseLogging bool, indexArbitraryData bool) (*Explorer, error) {
+	e, err := newExplorer(cs, persistDir, bcInfo, chainCts, verboseLogging, indexArbitraryData)
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve the current ConsensusChangeID
+	var recentChange modules.ConsensusChangeID
+	err = e.db.View(dbGetInternal(internalRecentChange, &recentChange))
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.ConsensusSetSubscribe(e, recentChange, nil)
+	if err != nil {
+		// TODO: restart from 0
+		return nil, errors.New("explorer subscription failed: " + err.Error())
+	}
+
+	return e, nil
+}
+
+// newExplorer creates the internal data structures of the explorer,
+// without subscribing to the consensus set.
+func newExplorer(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, verboseLogging bool, indexArbitraryData bool) (*Explorer, error) {
 	// Check that input modules are non-nil
 	if cs == nil {
 		return nil, errNilCS
@@ -66,6 +99,8 @@ func New(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo
 		rootTarget:     chainCts.RootTarget(),
 		genesisBlock:   genesisBlock,
 		genesisBlockID: genesisBlock.ID(),
+
+		indexArbitraryData: indexArbitraryData,
 	}
 
 	// Initialize the persistent structures, including the database.
@@ -73,20 +108,6 @@ func New(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo
 	if err != nil {
 		return nil, err
 	}
-
-	// retrieve the current ConsensusChangeID
-	var recentChange modules.ConsensusChangeID
-	err = e.db.View(dbGetInternal(internalRecentChange, &recentChange))
-	if err != nil {
-		return nil, err
-	}
-
-	err = cs.ConsensusSetSubscribe(e, recentChange, nil)
-	if err != nil {
-		// TODO: restart from 0
-		return nil, errors.New("explorer subscription failed: " + err.Error())
-	}
-
 	return e, nil
 }
 
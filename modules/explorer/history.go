@@ -0,0 +1,237 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// ErrInvalidHistoryCursor is returned when a cursor is given,
+	// which wasn't returned by the explorer as a next cursor.
+	ErrInvalidHistoryCursor = errors.New("invalid address history cursor")
+)
+
+// addressHistoryKeySize is the size of a key in the bucket of an address
+// within bucketAddressTransactions: the block height followed by the order
+// of the transaction within that block, both big endian encoded,
+// such that the transactions are sorted chronologically.
+const addressHistoryKeySize = 12
+
+// addressHistoryKey returns the key of a transaction within the history of an address,
+// where the order is 0 for the miner payouts of a block, and 1+i for the i-th transaction of a block.
+func addressHistoryKey(height types.BlockHeight, order uint32) []byte {
+	key := make([]byte, addressHistoryKeySize)
+	binary.BigEndian.PutUint64(key[:8], uint64(height))
+	binary.BigEndian.PutUint32(key[8:], order)
+	return key
+}
+
+// formatAddressHistoryCursor formats the key of a transaction within the history of an address
+// as a cursor, in the format <height>.<order>.
+func formatAddressHistoryCursor(key []byte) string {
+	return strconv.FormatUint(binary.BigEndian.Uint64(key[:8]), 10) + "." +
+		strconv.FormatUint(uint64(binary.BigEndian.Uint32(key[8:])), 10)
+}
+
+// parseAddressHistoryCursor parses a cursor as formatted by formatAddressHistoryCursor.
+func parseAddressHistoryCursor(cursor string) ([]byte, error) {
+	parts := strings.SplitN(cursor, ".", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidHistoryCursor
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidHistoryCursor
+	}
+	order, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidHistoryCursor
+	}
+	return addressHistoryKey(types.BlockHeight(height), uint32(order)), nil
+}
+
+// AddressTransactions returns the IDs of the transactions (and blocks, in case of miner payouts)
+// linked to the given address, sorted by block height and their order within the block.
+// Only transactions matching the filter are returned, in case an arbitrary data filter is defined
+// this excludes all miner payouts. Up to filter.Limit IDs are returned, as well as the cursor to use to get the next page.
+// The returned cursor is empty when there are no more transactions.
+func (e *Explorer) AddressTransactions(uh types.UnlockHash, filter modules.AddressTransactionsFilter) (ids []types.TransactionID, next string, err error) {
+	var cursorKey []byte
+	if filter.Cursor != "" {
+		cursorKey, err = parseAddressHistoryCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	minKey := addressHistoryKey(filter.MinHeight, 0)
+
+	err = e.db.View(func(tx *bolt.Tx) error {
+		uhb, err := siabin.Marshal(uh)
+		if err != nil {
+			return fmt.Errorf("failed to (siabin) marshal unlock hash: %v", err)
+		}
+		b := tx.Bucket(bucketAddressTransactions).Bucket(uhb)
+		if b == nil {
+			return nil // no transactions
+		}
+		c := b.Cursor()
+
+		var k, v []byte
+		var step func() ([]byte, []byte)
+		if filter.Descending {
+			step = c.Prev
+			if cursorKey != nil {
+				k, v = c.Seek(cursorKey)
+				if k == nil {
+					k, v = c.Last()
+				} else {
+					k, v = c.Prev()
+				}
+			} else {
+				k, v = c.Last()
+			}
+		} else {
+			step = c.Next
+			if cursorKey != nil && string(cursorKey) >= string(minKey) {
+				k, v = c.Seek(cursorKey)
+				if k != nil && string(k) == string(cursorKey) {
+					k, v = c.Next()
+				}
+			} else {
+				k, v = c.Seek(minKey)
+			}
+		}
+
+		var last []byte
+		for ; k != nil; k, v = step() {
+			if string(k) < string(minKey) {
+				break // only possible when iterating in descending order
+			}
+			var id types.TransactionID
+			err = siabin.Unmarshal(v, &id)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal transaction ID: %v", err)
+			}
+			if filter.ArbitraryData != nil && !e.transactionArbitraryDataMatches(k, id, *filter.ArbitraryData) {
+				continue
+			}
+			if filter.Limit > 0 && len(ids) == filter.Limit {
+				// more transactions are available,
+				// continue after the last returned transaction
+				next = formatAddressHistoryCursor(last)
+				break
+			}
+			ids = append(ids, id)
+			last = k
+		}
+		return nil
+	})
+	return
+}
+
+// transactionArbitraryDataMatches returns true if the arbitrary data of the transaction,
+// with the given key in the history of an address, matches the given query.
+func (e *Explorer) transactionArbitraryDataMatches(key []byte, id types.TransactionID, query modules.ArbitraryDataQuery) bool {
+	if binary.BigEndian.Uint32(key[8:]) == 0 {
+		return false // miner payouts have no arbitrary data
+	}
+	txn, _, exists := e.cs.TransactionAtID(id)
+	return exists && query.Matches(txn.ArbitraryData)
+}
+
+// The functions below panic on error. The panic will be caught by
+// ProcessConsensusChange.
+
+// dbApplyAddressHistory adds the transactions of the given (applied) block to the history
+// of all addresses linked to them. It has to be called after the outputs
+// of the block have been added to bucketCoinOutputs and bucketBlockStakeOutputs.
+func dbApplyAddressHistory(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	blockID := types.TransactionID(block.ID())
+	for uh := range minerPayoutAddresses(block) {
+		dbAddAddressTransaction(tx, uh, addressHistoryKey(height, 0), blockID)
+	}
+	for i, txn := range block.Transactions {
+		txid := txn.ID()
+		for uh := range dbTransactionAddresses(tx, txn) {
+			dbAddAddressTransaction(tx, uh, addressHistoryKey(height, uint32(i+1)), txid)
+		}
+	}
+}
+
+// dbRevertAddressHistory removes the transactions of the given (reverted) block from the history
+// of all addresses linked to them. It has to be called before the outputs
+// of the block are removed from bucketCoinOutputs and bucketBlockStakeOutputs.
+func dbRevertAddressHistory(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	for uh := range minerPayoutAddresses(block) {
+		dbRemoveAddressTransaction(tx, uh, addressHistoryKey(height, 0))
+	}
+	for i, txn := range block.Transactions {
+		for uh := range dbTransactionAddresses(tx, txn) {
+			dbRemoveAddressTransaction(tx, uh, addressHistoryKey(height, uint32(i+1)))
+		}
+	}
+}
+
+// minerPayoutAddresses returns the unique addresses of the miner payouts of a block.
+func minerPayoutAddresses(block types.Block) map[types.UnlockHash]struct{} {
+	uhs := make(map[types.UnlockHash]struct{}, len(block.MinerPayouts))
+	for _, payout := range block.MinerPayouts {
+		uhs[payout.UnlockHash] = struct{}{}
+	}
+	return uhs
+}
+
+// dbTransactionAddresses returns the unique addresses linked to a transaction,
+// being the addresses of its outputs, the addresses of the outputs it spends
+// and the addresses linked to it through its extension data.
+func dbTransactionAddresses(tx *bolt.Tx, txn types.Transaction) map[types.UnlockHash]struct{} {
+	uhs := make(map[types.UnlockHash]struct{})
+	for _, ci := range txn.CoinInputs {
+		var co types.CoinOutput
+		assertNil(dbGetAndDecode(bucketCoinOutputs, ci.ParentID, &co)(tx))
+		uhs[co.Condition.UnlockHash()] = struct{}{}
+	}
+	for _, co := range txn.CoinOutputs {
+		uhs[co.Condition.UnlockHash()] = struct{}{}
+	}
+	for _, bsi := range txn.BlockStakeInputs {
+		var bso types.BlockStakeOutput
+		assertNil(dbGetAndDecode(bucketBlockStakeOutputs, bsi.ParentID, &bso)(tx))
+		uhs[bso.Condition.UnlockHash()] = struct{}{}
+	}
+	for _, bso := range txn.BlockStakeOutputs {
+		uhs[bso.Condition.UnlockHash()] = struct{}{}
+	}
+	exAddresses, _ := modules.ExplorerTransactionAddresses(txn)
+	for _, uh := range exAddresses {
+		uhs[uh] = struct{}{}
+	}
+	return uhs
+}
+
+// Add/Remove transaction from the history of an address
+func dbAddAddressTransaction(tx *bolt.Tx, uh types.UnlockHash, key []byte, txid types.TransactionID) {
+	b, err := tx.Bucket(bucketAddressTransactions).CreateBucketIfNotExists(assertSiaMarshal(uh))
+	assertNil(err)
+	assertNil(b.Put(key, assertSiaMarshal(txid)))
+}
+func dbRemoveAddressTransaction(tx *bolt.Tx, uh types.UnlockHash, key []byte) {
+	ab := tx.Bucket(bucketAddressTransactions)
+	uhb := assertSiaMarshal(uh)
+	b := ab.Bucket(uhb)
+	if b == nil {
+		return // nothing to remove
+	}
+	assertNil(b.Delete(key))
+	if bucketIsEmpty(b) {
+		assertNil(ab.DeleteBucket(uhb))
+	}
+}
@@ -0,0 +1,385 @@
+package explorer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// The functions in this file are meant to be used offline, using a consensus set
+// which is not synchronizing with the network, while no daemon is using the explorer database.
+
+// reindexDir is the subdirectory of the explorer directory,
+// in which a new explorer database is built by Reindex.
+const reindexDir = "reindex"
+
+// maxVerifyInconsistencies is the maximum amount of
+// inconsistencies described by a VerifyReport.
+const maxVerifyInconsistencies = 100
+
+type (
+	// ProgressFunc is used to report the progress of the explorer maintenance functions,
+	// height being the last processed block height and target the height to reach.
+	ProgressFunc func(height, target types.BlockHeight)
+
+	// VerifyReport describes the result of verifying an explorer database
+	// against the consensus set it was built from.
+	VerifyReport struct {
+		// Height is the block height of the explorer database.
+		Height types.BlockHeight
+		// CheckedBlocks and CheckedOutputs are the amount of blocks and
+		// unspent outputs which were cross-checked with the consensus set.
+		CheckedBlocks  uint64
+		CheckedOutputs uint64
+		// InconsistencyCount is the amount of inconsistencies found,
+		// of which up to maxVerifyInconsistencies are described in Inconsistencies.
+		InconsistencyCount uint64
+		Inconsistencies    []string
+	}
+)
+
+// Consistent returns true if no inconsistencies were found.
+func (report VerifyReport) Consistent() bool {
+	return report.InconsistencyCount == 0
+}
+
+func (report *VerifyReport) addInconsistency(format string, args ...interface{}) {
+	report.InconsistencyCount++
+	if len(report.Inconsistencies) < maxVerifyInconsistencies {
+		report.Inconsistencies = append(report.Inconsistencies, fmt.Sprintf(format, args...))
+	}
+}
+
+// Backfill opens the explorer database stored in the given directory, building any index
+// it is missing, and processes all blocks of the consensus set it didn't process yet.
+func Backfill(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, verboseLogging bool, indexArbitraryData bool, progress ProgressFunc) error {
+	e, err := newExplorer(cs, persistDir, bcInfo, chainCts, verboseLogging, indexArbitraryData)
+	if err != nil {
+		return err
+	}
+	err = e.sync(progress)
+	return build.ComposeErrors(err, e.Close())
+}
+
+// Reindex rebuilds the explorer database stored in the given directory from scratch,
+// processing all blocks of the consensus set. The new database is built in a subdirectory,
+// and only replaces the existing database once all blocks have been processed.
+func Reindex(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, verboseLogging bool, indexArbitraryData bool, progress ProgressFunc) error {
+	tmpDir := filepath.Join(persistDir, reindexDir)
+	// remove the leftovers of a previous (interrupted) reindex
+	err := os.RemoveAll(tmpDir)
+	if err != nil {
+		return err
+	}
+	e, err := newExplorer(cs, tmpDir, bcInfo, chainCts, verboseLogging, indexArbitraryData)
+	if err != nil {
+		return err
+	}
+	err = build.ComposeErrors(e.sync(progress), e.Close())
+	if err != nil {
+		return err
+	}
+	err = os.Rename(filepath.Join(tmpDir, DatabaseFilename), filepath.Join(persistDir, DatabaseFilename))
+	if err != nil {
+		return fmt.Errorf("failed to replace the explorer database: %v", err)
+	}
+	return os.RemoveAll(tmpDir)
+}
+
+// Verify cross-checks the explorer database stored in the given directory with the consensus set,
+// ensuring all blocks and unspent outputs are indexed, and the indexed balances
+// and supply statistics match these unspent outputs.
+func Verify(cs modules.ConsensusSet, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, verboseLogging bool, indexArbitraryData bool, progress ProgressFunc) (VerifyReport, error) {
+	e, err := newExplorer(cs, persistDir, bcInfo, chainCts, verboseLogging, indexArbitraryData)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	report, err := e.verify(progress)
+	return report, build.ComposeErrors(err, e.Close())
+}
+
+// sync subscribes the explorer to the consensus set, starting from the last processed
+// consensus change, returning once all consensus changes have been processed.
+// Unlike a regular subscription, a failed update aborts the synchronization.
+func (e *Explorer) sync(progress ProgressFunc) error {
+	var recentChange modules.ConsensusChangeID
+	err := e.db.View(dbGetInternal(internalRecentChange, &recentChange))
+	if err != nil {
+		return err
+	}
+
+	target := e.cs.Height()
+	cancel := make(chan struct{})
+	var updateErr error
+	e.onUpdate = func(height types.BlockHeight, err error) {
+		if err != nil {
+			if updateErr == nil {
+				updateErr = err
+				close(cancel)
+			}
+			return
+		}
+		if progress != nil {
+			progress(height, target)
+		}
+	}
+	defer func() { e.onUpdate = nil }()
+
+	err = e.cs.ConsensusSetSubscribe(e, recentChange, cancel)
+	if updateErr != nil {
+		return fmt.Errorf("explorer update failed: %v", updateErr)
+	}
+	if err != nil {
+		return fmt.Errorf("explorer subscription failed: %v", err)
+	}
+	return nil
+}
+
+// verify cross-checks the explorer database with the consensus set.
+func (e *Explorer) verify(progress ProgressFunc) (report VerifyReport, err error) {
+	err = e.db.View(func(tx *bolt.Tx) error {
+		err := dbGetInternal(internalBlockHeight, &report.Height)(tx)
+		if err != nil {
+			return err
+		}
+		height := report.Height
+		if csHeight := e.cs.Height(); csHeight != height {
+			report.addInconsistency("explorer is at height %d, while the consensus set is at height %d", height, csHeight)
+			if csHeight < height {
+				height = csHeight
+			}
+		}
+
+		// ensure all blocks and unspent outputs of the consensus set are indexed
+		for bh := types.BlockHeight(0); bh <= height; bh++ {
+			block, exists := e.cs.BlockAtHeight(bh)
+			if !exists {
+				return fmt.Errorf("consensus set is missing block at height %d", bh)
+			}
+			err = e.dbVerifyBlock(tx, &report, block, bh)
+			if err != nil {
+				return err
+			}
+			report.CheckedBlocks++
+			if progress != nil {
+				progress(bh, height)
+			}
+		}
+
+		// ensure all indexed outputs are unspent,
+		// and the balances and supply statistics match them
+		return e.dbVerifyAddressIndex(tx, &report, height)
+	})
+	return
+}
+
+// dbVerifyBlock ensures the given block is indexed at the given height,
+// and its outputs which are still unspent are indexed for the addresses they belong to.
+func (e *Explorer) dbVerifyBlock(tx *bolt.Tx, report *VerifyReport, block types.Block, height types.BlockHeight) error {
+	bid := block.ID()
+	var indexedHeight types.BlockHeight
+	err := dbGetAndDecode(bucketBlockIDs, bid, &indexedHeight)(tx)
+	if err == errNotExist {
+		report.addInconsistency("block %s at height %d is not indexed", bid.String(), height)
+	} else if err != nil {
+		return err
+	} else if indexedHeight != height {
+		report.addInconsistency("block %s is indexed at height %d instead of %d", bid.String(), indexedHeight, height)
+	}
+
+	// outputs which aren't returned by the consensus set are either spent,
+	// or (in case of miner payouts) not yet matured
+	for j := range block.MinerPayouts {
+		id := block.MinerPayoutID(uint64(j))
+		if co, err := e.cs.GetCoinOutput(id); err == nil {
+			err = dbVerifyAddressOutput(tx, report, bucketAddressCoinOutputs, id, co.Value, co.Condition.UnlockHash())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, txn := range block.Transactions {
+		for k := range txn.CoinOutputs {
+			id := txn.CoinOutputID(uint64(k))
+			if co, err := e.cs.GetCoinOutput(id); err == nil {
+				err = dbVerifyAddressOutput(tx, report, bucketAddressCoinOutputs, id, co.Value, co.Condition.UnlockHash())
+				if err != nil {
+					return err
+				}
+			}
+		}
+		for k := range txn.BlockStakeOutputs {
+			id := txn.BlockStakeOutputID(uint64(k))
+			if bso, err := e.cs.GetBlockStakeOutput(id); err == nil {
+				err = dbVerifyAddressOutput(tx, report, bucketAddressBlockStakeOutputs, id, bso.Value, bso.Condition.UnlockHash())
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// dbVerifyAddressOutput ensures the given unspent output is indexed
+// in the given bucket for the given address, with the given value.
+func dbVerifyAddressOutput(tx *bolt.Tx, report *VerifyReport, bucket []byte, id interface{}, value types.Currency, uh types.UnlockHash) error {
+	report.CheckedOutputs++
+	uhb, err := siabin.Marshal(uh)
+	if err != nil {
+		return fmt.Errorf("failed to (siabin) marshal unlock hash: %v", err)
+	}
+	idb, err := siabin.Marshal(id)
+	if err != nil {
+		return fmt.Errorf("failed to (siabin) marshal output ID: %v", err)
+	}
+	var ob []byte
+	if b := tx.Bucket(bucket).Bucket(uhb); b != nil {
+		ob = b.Get(idb)
+	}
+	if ob == nil {
+		report.addInconsistency("unspent output %v of address %s is not indexed", id, uh.String())
+		return nil
+	}
+	var output addressOutput
+	err = siabin.Unmarshal(ob, &output)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal address output: %v", err)
+	}
+	if !output.Value.Equals(value) {
+		report.addInconsistency("unspent output %v of address %s is indexed with value %s instead of %s",
+			id, uh.String(), output.Value.String(), value.String())
+	}
+	return nil
+}
+
+// dbVerifyAddressIndex ensures all indexed address outputs are unspent
+// (or not yet matured) in the consensus set, and that the indexed address balances
+// and supply statistics match the indexed address outputs.
+func (e *Explorer) dbVerifyAddressIndex(tx *bolt.Tx, report *VerifyReport, height types.BlockHeight) error {
+	balances := make(map[types.UnlockHash]addressBalance)
+	err := e.dbVerifyAddressOutputs(tx, report, bucketAddressCoinOutputs, true, height, balances, func(idb []byte) (interface{}, types.Currency, bool, error) {
+		var id types.CoinOutputID
+		err := siabin.Unmarshal(idb, &id)
+		if err != nil {
+			return nil, types.Currency{}, false, err
+		}
+		co, err := e.cs.GetCoinOutput(id)
+		return id, co.Value, err == nil, nil
+	})
+	if err != nil {
+		return err
+	}
+	err = e.dbVerifyAddressOutputs(tx, report, bucketAddressBlockStakeOutputs, false, height, balances, func(idb []byte) (interface{}, types.Currency, bool, error) {
+		var id types.BlockStakeOutputID
+		err := siabin.Unmarshal(idb, &id)
+		if err != nil {
+			return nil, types.Currency{}, false, err
+		}
+		bso, err := e.cs.GetBlockStakeOutput(id)
+		return id, bso.Value, err == nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// compare the indexed balances with the balances computed from the indexed outputs
+	var expected supplyStats
+	for uh, balance := range balances {
+		expected.TotalCoins = expected.TotalCoins.Add(balance.Coins)
+		expected.TotalBlockStakes = expected.TotalBlockStakes.Add(balance.BlockStakes)
+		if !balance.Coins.IsZero() {
+			expected.CoinHolderCount++
+		}
+		if !balance.BlockStakes.IsZero() {
+			expected.BlockStakeHolderCount++
+		}
+		var indexed addressBalance
+		err = dbGetAndDecode(bucketAddressBalances, uh, &indexed)(tx)
+		if err != nil && err != errNotExist {
+			return err
+		}
+		if !indexed.Coins.Equals(balance.Coins) || !indexed.BlockStakes.Equals(balance.BlockStakes) {
+			report.addInconsistency("address %s has balance %s coins and %s block stakes instead of %s coins and %s block stakes",
+				uh.String(), indexed.Coins.String(), indexed.BlockStakes.String(), balance.Coins.String(), balance.BlockStakes.String())
+		}
+	}
+	err = tx.Bucket(bucketAddressBalances).ForEach(func(k, _ []byte) error {
+		var uh types.UnlockHash
+		err := siabin.Unmarshal(k, &uh)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal unlock hash: %v", err)
+		}
+		if _, ok := balances[uh]; !ok {
+			report.addInconsistency("address %s has a balance but no unspent outputs", uh.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// compare the supply statistics with the totals of the indexed balances
+	var stats supplyStats
+	err = dbGetInternal(internalSupplyStats, &stats)(tx)
+	if err != nil {
+		return err
+	}
+	if !stats.TotalCoins.Equals(expected.TotalCoins) || !stats.TotalBlockStakes.Equals(expected.TotalBlockStakes) {
+		report.addInconsistency("supply statistics have %s coins and %s block stakes instead of %s coins and %s block stakes",
+			stats.TotalCoins.String(), stats.TotalBlockStakes.String(), expected.TotalCoins.String(), expected.TotalBlockStakes.String())
+	}
+	if stats.CoinHolderCount != expected.CoinHolderCount || stats.BlockStakeHolderCount != expected.BlockStakeHolderCount {
+		report.addInconsistency("supply statistics have %d coin and %d block stake holders instead of %d and %d",
+			stats.CoinHolderCount, stats.BlockStakeHolderCount, expected.CoinHolderCount, expected.BlockStakeHolderCount)
+	}
+	return nil
+}
+
+// dbVerifyAddressOutputs ensures all address outputs indexed in the given bucket are
+// unspent in the consensus set with the same value, using the given lookup function,
+// unless they're not yet matured at the given height. The values of the outputs
+// are added to the coins or block stakes of the given balances.
+func (e *Explorer) dbVerifyAddressOutputs(tx *bolt.Tx, report *VerifyReport, bucket []byte, coins bool, height types.BlockHeight,
+	balances map[types.UnlockHash]addressBalance, lookup func(id []byte) (interface{}, types.Currency, bool, error)) error {
+	return tx.Bucket(bucket).ForEach(func(k, _ []byte) error {
+		var uh types.UnlockHash
+		err := siabin.Unmarshal(k, &uh)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal unlock hash: %v", err)
+		}
+		balance := balances[uh]
+		err = dbForEachAddressOutput(tx, bucket, uh, func(idb []byte, output addressOutput) error {
+			if coins {
+				balance.Coins = balance.Coins.Add(output.Value)
+			} else {
+				balance.BlockStakes = balance.BlockStakes.Add(output.Value)
+			}
+			if output.MaturityHeight > height {
+				return nil // not yet known by the consensus set
+			}
+			id, value, found, err := lookup(idb)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal output ID: %v", err)
+			}
+			if !found {
+				report.addInconsistency("indexed output %v of address %s is not unspent", id, uh.String())
+			} else if !value.Equals(output.Value) {
+				report.addInconsistency("indexed output %v of address %s has value %s instead of %s",
+					id, uh.String(), output.Value.String(), value.String())
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		balances[uh] = balance
+		return nil
+	})
+}
@@ -0,0 +1,134 @@
+package explorer
+
+import (
+	"errors"
+	"testing"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// outputLookupTester is a consensus set which only supports
+// looking up blocks by their height and unspent outputs by their ID.
+type outputLookupTester struct {
+	modules.ConsensusSet
+	blocks            []types.Block
+	coinOutputs       map[types.CoinOutputID]types.CoinOutput
+	blockStakeOutputs map[types.BlockStakeOutputID]types.BlockStakeOutput
+}
+
+func (cs *outputLookupTester) Height() types.BlockHeight {
+	return types.BlockHeight(len(cs.blocks) - 1)
+}
+
+func (cs *outputLookupTester) BlockAtHeight(height types.BlockHeight) (types.Block, bool) {
+	if height >= types.BlockHeight(len(cs.blocks)) {
+		return types.Block{}, false
+	}
+	return cs.blocks[height], true
+}
+
+func (cs *outputLookupTester) GetCoinOutput(id types.CoinOutputID) (types.CoinOutput, error) {
+	co, ok := cs.coinOutputs[id]
+	if !ok {
+		return types.CoinOutput{}, errors.New("coin output not found")
+	}
+	return co, nil
+}
+
+func (cs *outputLookupTester) GetBlockStakeOutput(id types.BlockStakeOutputID) (types.BlockStakeOutput, error) {
+	bso, ok := cs.blockStakeOutputs[id]
+	if !ok {
+		return types.BlockStakeOutput{}, errors.New("block stake output not found")
+	}
+	return bso, nil
+}
+
+func (e *Explorer) testVerify(t *testing.T, blocks, outputs, inconsistencies uint64) {
+	t.Helper()
+	var progress []types.BlockHeight
+	report, err := e.verify(func(height, target types.BlockHeight) {
+		progress = append(progress, height)
+		if target != types.BlockHeight(blocks-1) {
+			t.Errorf("unexpected target height: %d", target)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CheckedBlocks != blocks || report.CheckedOutputs != outputs || uint64(len(progress)) != blocks {
+		t.Errorf("unexpected amount of checked blocks (%d) and outputs (%d), with %d progress updates",
+			report.CheckedBlocks, report.CheckedOutputs, len(progress))
+	}
+	if report.InconsistencyCount != inconsistencies || report.Consistent() != (inconsistencies == 0) {
+		t.Errorf("expected %d inconsistencies, got %d: %v", inconsistencies, report.InconsistencyCount, report.Inconsistencies)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	e := newAddressIndexTester(t)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+
+	genesis := types.Block{
+		Transactions: []types.Transaction{{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(100), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+			BlockStakeOutputs: []types.BlockStakeOutput{
+				{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			},
+		}},
+	}
+	// bob's miner payout isn't matured yet, and is therefore unknown to the consensus set
+	block := types.Block{
+		ParentID:     genesis.ID(),
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(1), UnlockHash: bob}},
+		Transactions: []types.Transaction{{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: genesis.Transactions[0].CoinOutputID(0)}},
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(30), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+				{Value: types.NewCurrency64(69), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}},
+	}
+	e.applyTestBlock(t, genesis, 0)
+	e.applyTestBlock(t, block, 1)
+	cs := &outputLookupTester{
+		blocks: []types.Block{genesis, block},
+		coinOutputs: map[types.CoinOutputID]types.CoinOutput{
+			block.Transactions[0].CoinOutputID(0): block.Transactions[0].CoinOutputs[0],
+			block.Transactions[0].CoinOutputID(1): block.Transactions[0].CoinOutputs[1],
+		},
+		blockStakeOutputs: map[types.BlockStakeOutputID]types.BlockStakeOutput{
+			genesis.Transactions[0].BlockStakeOutputID(0): genesis.Transactions[0].BlockStakeOutputs[0],
+		},
+	}
+	e.cs = cs
+	e.testVerify(t, 2, 3, 0)
+
+	// an output spent in the consensus set, but not in the explorer, is inconsistent
+	delete(cs.coinOutputs, block.Transactions[0].CoinOutputID(0))
+	e.testVerify(t, 2, 2, 1)
+	cs.coinOutputs[block.Transactions[0].CoinOutputID(0)] = block.Transactions[0].CoinOutputs[0]
+
+	// a corrupted balance is inconsistent with the outputs of the address,
+	// and a missing block is reported as well
+	err := e.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(bucketAddressBalances).Put(assertSiaMarshal(alice), assertSiaMarshal(addressBalance{
+			Coins: types.NewCurrency64(42),
+		}))
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketBlockIDs).Delete(assertSiaMarshal(block.ID()))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.testVerify(t, 2, 3, 2)
+}
@@ -1,6 +1,7 @@
 package explorer
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -12,9 +13,16 @@ import (
 	bolt "github.com/rivine/bbolt"
 )
 
+const (
+	// DatabaseFilename contains the filename of the database
+	// in which the explorer stores its indices.
+	DatabaseFilename = modules.ExplorerDir + ".db"
+	logFile          = modules.ExplorerDir + ".log"
+)
+
 var explorerMetadata = persist.Metadata{
 	Header:  "Sia Explorer",
-	Version: "1.0.8",
+	Version: "1.0.12",
 }
 
 // initPersist initializes the persistent structures of the explorer module.
@@ -26,14 +34,14 @@ func (e *Explorer) initPersist(verbose bool) error {
 	}
 
 	// Initialize the logger.
-	logFilePath := filepath.Join(e.persistDir, "explorer.log")
+	logFilePath := filepath.Join(e.persistDir, logFile)
 	e.log, err = persist.NewFileLogger(e.bcInfo, logFilePath, verbose)
 	if err != nil {
 		return err
 	}
 
 	// Open the database
-	dbFilPath := filepath.Join(e.persistDir, "explorer.db")
+	dbFilPath := filepath.Join(e.persistDir, DatabaseFilename)
 	db, err := persist.OpenDatabase(explorerMetadata, dbFilPath)
 	if err != nil {
 		if err != persist.ErrBadVersion {
@@ -48,6 +56,46 @@ func (e *Explorer) initPersist(verbose bool) error {
 
 	// Initialize the database
 	err = e.db.Update(func(tx *bolt.Tx) error {
+		// the address indices have to be built for databases
+		// that were already in use prior to their introduction
+		var missingIndices []string
+		if tx.Bucket(bucketInternal) != nil {
+			if tx.Bucket(bucketCoinRichList) == nil || tx.Bucket(bucketLockableCoinOutputs) == nil {
+				// the supply statistics and rich lists are updated together with
+				// the address balances, hence that index has to be rebuilt as well,
+				// as it does for databases which only tracked the time-locked coin outputs
+				for _, b := range [][]byte{
+					bucketAddressBalances,
+					bucketAddressCoinOutputs,
+					bucketAddressBlockStakeOutputs,
+					bucketCoinRichList,
+					bucketBlockStakeRichList,
+					bucketLockableCoinOutputs,
+					bucketLegacyTimeLockedCoinOutputs,
+					bucketSupplyStatsHistory,
+				} {
+					if tx.Bucket(b) == nil {
+						continue
+					}
+					err := tx.DeleteBucket(b)
+					if err != nil {
+						return err
+					}
+				}
+				err := tx.Bucket(bucketInternal).Delete(internalSupplyStats)
+				if err != nil {
+					return err
+				}
+				missingIndices = append(missingIndices, indexAddresses)
+			}
+			if tx.Bucket(bucketAddressTransactions) == nil {
+				missingIndices = append(missingIndices, indexAddressHistory)
+			}
+			if e.indexArbitraryData && tx.Bucket(bucketArbitraryDataHashes) == nil {
+				missingIndices = append(missingIndices, indexArbitraryData)
+			}
+		}
+
 		buckets := [][]byte{
 			bucketBlockFacts,
 			bucketBlockIDs,
@@ -61,6 +109,14 @@ func (e *Explorer) initPersist(verbose bool) error {
 			bucketTransactionIDs,
 			bucketUnlockHashes,
 			bucketWalletAddressToMultiSigAddressMapping,
+			bucketAddressBalances,
+			bucketAddressCoinOutputs,
+			bucketAddressBlockStakeOutputs,
+			bucketAddressTransactions,
+			bucketCoinRichList,
+			bucketBlockStakeRichList,
+			bucketLockableCoinOutputs,
+			bucketSupplyStatsHistory,
 		}
 		for _, b := range buckets {
 			_, err := tx.CreateBucketIfNotExists(b)
@@ -68,15 +124,32 @@ func (e *Explorer) initPersist(verbose bool) error {
 				return err
 			}
 		}
+		// the arbitrary data index is optional, and deleted when disabled,
+		// such that it is rebuilt should it be enabled again
+		for _, b := range [][]byte{bucketArbitraryDataHashes, bucketArbitraryDataPrefixes} {
+			if e.indexArbitraryData {
+				_, err := tx.CreateBucketIfNotExists(b)
+				if err != nil {
+					return err
+				}
+			} else if tx.Bucket(b) != nil {
+				err := tx.DeleteBucket(b)
+				if err != nil {
+					return err
+				}
+			}
+		}
 
 		// set default values for the bucketInternal
 		blockHeightBytes, _ := siabin.Marshal(types.BlockHeight(0))
 		consensusChangeIDBytes, _ := siabin.Marshal(modules.ConsensusChangeID{})
+		supplyStatsBytes, _ := siabin.Marshal(supplyStats{})
 		internalDefaults := []struct {
 			key, val []byte
 		}{
 			{internalBlockHeight, blockHeightBytes},
 			{internalRecentChange, consensusChangeIDBytes},
+			{internalSupplyStats, supplyStatsBytes},
 		}
 		b := tx.Bucket(bucketInternal)
 		for _, d := range internalDefaults {
@@ -89,11 +162,196 @@ func (e *Explorer) initPersist(verbose bool) error {
 			}
 		}
 
-		return nil
+		// the missing indices are built from the genesis block,
+		// together with the indices of which the build was interrupted
+		var recentChange modules.ConsensusChangeID
+		err := dbGetInternal(internalRecentChange, &recentChange)(tx)
+		if err != nil {
+			return err
+		}
+		if recentChange == (modules.ConsensusChangeID{}) {
+			// no blocks were processed yet, and thus no indices are to be built
+			return tx.Bucket(bucketInternal).Delete(internalIndexBuild)
+		}
+		progress, err := dbGetIndexBuildProgress(tx)
+		if err != nil {
+			return err
+		}
+		builds := make([]indexBuildProgress, 0, len(progress)+len(missingIndices))
+		for _, build := range progress {
+			if build.Index == indexArbitraryData && !e.indexArbitraryData {
+				continue // index is disabled, and thus deleted
+			}
+			missing := false
+			for _, index := range missingIndices {
+				missing = missing || index == build.Index
+			}
+			if !missing {
+				builds = append(builds, build)
+			}
+		}
+		for _, index := range missingIndices {
+			builds = append(builds, indexBuildProgress{Index: index})
+		}
+		return dbSetIndexBuildProgress(tx, builds)
 	})
 	if err != nil {
 		return err
 	}
 
+	return e.buildIndices(indexBuildBatchSize)
+}
+
+// blockIndexFunc is a function which updates an index for an applied block.
+type blockIndexFunc func(tx *bolt.Tx, block types.Block, height types.BlockHeight)
+
+// The indices which are built for existing databases,
+// created prior to the introduction of these indices.
+const (
+	indexAddresses      = "addresses"
+	indexAddressHistory = "addresshistory"
+	indexArbitraryData  = "arbitrarydata"
+)
+
+// indexBuildBatchSize is the amount of blocks indexed within a single database transaction,
+// when building indices for an existing database.
+const indexBuildBatchSize = 1000
+
+// indexBuildProgress is the progress made building an index for an existing database,
+// stored in the internal bucket until the index is built for all blocks.
+type indexBuildProgress struct {
+	Index string
+	// Height of the next block to index.
+	Height types.BlockHeight
+}
+
+// blockIndexFuncs returns the functions which build the given index.
+func (e *Explorer) blockIndexFuncs(index string) ([]blockIndexFunc, error) {
+	switch index {
+	case indexAddresses:
+		// the supply statistics and rich lists are updated together with the address balances
+		return []blockIndexFunc{e.dbApplyAddressIndex, dbApplySupplyStats}, nil
+	case indexAddressHistory:
+		return []blockIndexFunc{dbApplyAddressHistory}, nil
+	case indexArbitraryData:
+		return []blockIndexFunc{dbApplyArbitraryDataIndex}, nil
+	default:
+		return nil, fmt.Errorf("unknown index %q", index)
+	}
+}
+
+// dbGetIndexBuildProgress returns the progress of the indices which are being built, if any.
+func dbGetIndexBuildProgress(tx *bolt.Tx) ([]indexBuildProgress, error) {
+	b := tx.Bucket(bucketInternal).Get(internalIndexBuild)
+	if b == nil {
+		return nil, nil
+	}
+	var progress []indexBuildProgress
+	err := siabin.Unmarshal(b, &progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to (siabin) unmarshal index build progress: %v", err)
+	}
+	return progress, nil
+}
+
+// dbSetIndexBuildProgress stores the progress of the indices which are being built,
+// deleting it once no more indices are to be built.
+func dbSetIndexBuildProgress(tx *bolt.Tx, progress []indexBuildProgress) error {
+	if len(progress) == 0 {
+		return tx.Bucket(bucketInternal).Delete(internalIndexBuild)
+	}
+	return dbSetInternal(internalIndexBuild, progress)(tx)
+}
+
+// buildIndices builds the indices of the stored index build progress for all blocks
+// the explorer already processed, used for databases created prior to the introduction of these indices.
+// The blocks are indexed in batches of the given size, each batch being committed together
+// with the progress made, such that an interrupted build is resumed when the explorer restarts.
+func (e *Explorer) buildIndices(batchSize types.BlockHeight) error {
+	var (
+		height   types.BlockHeight
+		progress []indexBuildProgress
+	)
+	err := e.db.View(func(tx *bolt.Tx) (err error) {
+		err = dbGetInternal(internalBlockHeight, &height)(tx)
+		if err != nil {
+			return err
+		}
+		progress, err = dbGetIndexBuildProgress(tx)
+		return err
+	})
+	if err != nil || len(progress) == 0 {
+		return err
+	}
+	funcs := make([][]blockIndexFunc, len(progress))
+	for idx, build := range progress {
+		funcs[idx], err = e.blockIndexFuncs(build.Index)
+		if err != nil {
+			return err
+		}
+	}
+	e.log.Println("Building missing indices for existing explorer database...")
+
+	for len(progress) > 0 {
+		// continue from the lowest height of all indices being built
+		start := progress[0].Height
+		for _, build := range progress[1:] {
+			if build.Height < start {
+				start = build.Height
+			}
+		}
+		if start > height {
+			// all blocks are indexed already
+			return e.db.Update(func(tx *bolt.Tx) error {
+				return dbSetIndexBuildProgress(tx, nil)
+			})
+		}
+		end := start + batchSize - 1
+		if end > height {
+			end = height
+		}
+		err = e.db.Update(func(tx *bolt.Tx) (err error) {
+			// use exception-style error handling, as the index functions panic on error
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("failed to build indices: %v", r)
+				}
+			}()
+			for h := start; h <= end; h++ {
+				block, exists := e.cs.BlockAtHeight(h)
+				if !exists {
+					return fmt.Errorf("consensus set is missing block at height %d", h)
+				}
+				for idx, build := range progress {
+					if build.Height > h {
+						continue
+					}
+					for _, index := range funcs[idx] {
+						index(tx, block, h)
+					}
+				}
+			}
+			// the progress is deleted together with the last batch
+			var batch []indexBuildProgress
+			if end < height {
+				batch = make([]indexBuildProgress, 0, len(progress))
+				for _, build := range progress {
+					if build.Height <= end {
+						build.Height = end + 1
+					}
+					batch = append(batch, build)
+				}
+			}
+			err = dbSetIndexBuildProgress(tx, batch)
+			if err == nil {
+				progress = batch
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		e.log.Printf("Built missing indices for blocks %d up to %d (of %d)\n", start, end, height)
+	}
 	return nil
 }
@@ -12,6 +12,11 @@ import (
 )
 
 func (e *Explorer) convertLegacyDatabase(filePath string) (db *persist.BoltDatabase, err error) {
+	db, err = convertUnindexedDatabase(filePath)
+	if err != persist.ErrBadVersion {
+		return
+	}
+
 	var legacyExplorerMetadata = persist.Metadata{
 		Header:  "Sia Explorer",
 		Version: "1.0.5",
@@ -114,6 +119,50 @@ func (e *Explorer) convertLegacyDatabase(filePath string) (db *persist.BoltDatab
 	return
 }
 
+// convertUnindexedDatabase converts a 1.0.8, 1.0.9, 1.0.10 or 1.0.11 explorer database,
+// to a database of the current version as defined by explorerMetadata.
+// The only difference is the address indices and supply statistics, which are built by initPersist,
+// as they are missing for these legacy databases. The address outputs of a 1.0.11 database
+// do not define their creation, hence its rich list is deleted such that these indices are rebuilt.
+// It keeps the database open and returns it for further usage.
+func convertUnindexedDatabase(filePath string) (db *persist.BoltDatabase, err error) {
+	var version string
+	for _, version = range []string{"1.0.11", "1.0.10", "1.0.9", "1.0.8"} {
+		var legacyExplorerMetadata = persist.Metadata{
+			Header:  "Sia Explorer",
+			Version: version,
+		}
+		db, err = persist.OpenDatabase(legacyExplorerMetadata, filePath)
+		if err == nil {
+			break
+		}
+		if err != persist.ErrBadVersion {
+			return
+		}
+	}
+	if err != nil {
+		return
+	}
+	if version == "1.0.11" {
+		err = db.Update(func(tx *bolt.Tx) error {
+			return tx.DeleteBucket(bucketCoinRichList)
+		})
+	}
+	if err == nil {
+		// set the new metadata, and save it,
+		// such that next time we have the new version stored
+		db.Header, db.Version = explorerMetadata.Header, explorerMetadata.Version
+		err = db.SaveMetadata()
+	}
+	if err != nil {
+		err := db.Close()
+		if err != nil {
+			build.Severe(err)
+		}
+	}
+	return
+}
+
 // convert052Database converts a 0.5.2 explorer database,
 // to a database of the current version as defined by explorerMetadata.
 // It keeps the database open and returns it for further usage.
@@ -0,0 +1,76 @@
+package sqlindexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect defines the SQL syntax of a relational database,
+// for the parts of the schema and statements which aren't portable.
+// Any database with a database/sql driver can be used by implementing this interface.
+type Dialect interface {
+	// Placeholder returns the placeholder of the n-th (1-based) argument of a statement.
+	Placeholder(n int) string
+	// BlobType returns the column type used for binary data.
+	BlobType() string
+	// AmountType returns the column type used for currency values, such as coin amounts.
+	// Currency values are passed as decimal strings, and can be larger than 64 bits.
+	AmountType() string
+}
+
+type (
+	sqliteDialect     struct{}
+	postgreSQLDialect struct{}
+)
+
+var (
+	// SQLite is the dialect of SQLite databases, e.g. using the sqlite3 driver.
+	SQLite Dialect = sqliteDialect{}
+	// PostgreSQL is the dialect of PostgreSQL databases, e.g. using the postgres or pgx driver.
+	PostgreSQL Dialect = postgreSQLDialect{}
+)
+
+func (sqliteDialect) Placeholder(int) string { return "?" }
+func (sqliteDialect) BlobType() string       { return "BLOB" }
+
+// AmountType implements Dialect.AmountType, storing amounts as text,
+// as SQLite stores numeric values larger than 64 bits as floating point numbers.
+func (sqliteDialect) AmountType() string { return "TEXT" }
+
+func (postgreSQLDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+func (postgreSQLDialect) BlobType() string         { return "BYTEA" }
+func (postgreSQLDialect) AmountType() string       { return "NUMERIC(78, 0)" }
+
+// DialectForDriver returns the dialect to use for the given (database/sql) driver name.
+func DialectForDriver(driverName string) (Dialect, error) {
+	switch driverName {
+	case "sqlite3", "sqlite":
+		return SQLite, nil
+	case "postgres", "pgx":
+		return PostgreSQL, nil
+	default:
+		return nil, fmt.Errorf("no SQL dialect known for driver %q", driverName)
+	}
+}
+
+// rebind replaces the '?' placeholders of the given statement
+// with the placeholders of the given dialect.
+func rebind(dialect Dialect, query string) string {
+	if dialect.Placeholder(1) == "?" {
+		return query
+	}
+	var (
+		sb strings.Builder
+		n  int
+	)
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString(dialect.Placeholder(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
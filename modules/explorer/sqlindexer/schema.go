@@ -0,0 +1,128 @@
+package sqlindexer
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// schema contains the statements creating the tables and indices of the relational schema,
+// in which the %BLOB% and %AMOUNT% column types are replaced with the types of the dialect.
+//
+// IDs and unlock hashes are stored using their (hex-encoded) string representation,
+// conditions and fulfillments using their JSON representation. Miner payouts are stored
+// as coin outputs without a transaction ID. The spent_by and spent_height columns
+// of an output are only defined once the output is spent.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS sync_state (
+		id INTEGER PRIMARY KEY,
+		change_id VARCHAR(64) NOT NULL,
+		block_count BIGINT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS blocks (
+		height BIGINT PRIMARY KEY,
+		id VARCHAR(64) NOT NULL UNIQUE,
+		parent_id VARCHAR(64) NOT NULL,
+		timestamp BIGINT NOT NULL,
+		pobs_block_height BIGINT NOT NULL,
+		pobs_transaction_index INTEGER NOT NULL,
+		pobs_output_index INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS transactions (
+		id VARCHAR(64) PRIMARY KEY,
+		height BIGINT NOT NULL,
+		block_index INTEGER NOT NULL,
+		version INTEGER NOT NULL,
+		miner_fees %AMOUNT% NOT NULL,
+		arbitrary_data %BLOB%,
+		extension_data TEXT,
+		json_data TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS coin_inputs (
+		parent_id VARCHAR(64) PRIMARY KEY,
+		transaction_id VARCHAR(64) NOT NULL,
+		input_index INTEGER NOT NULL,
+		height BIGINT NOT NULL,
+		fulfillment_type INTEGER NOT NULL,
+		fulfillment_data TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS coin_outputs (
+		id VARCHAR(64) PRIMARY KEY,
+		transaction_id VARCHAR(64),
+		output_index INTEGER NOT NULL,
+		height BIGINT NOT NULL,
+		maturity_height BIGINT NOT NULL,
+		value %AMOUNT% NOT NULL,
+		unlock_hash VARCHAR(78) NOT NULL,
+		condition_type INTEGER NOT NULL,
+		condition_data TEXT NOT NULL,
+		spent_by VARCHAR(64),
+		spent_height BIGINT
+	)`,
+	`CREATE TABLE IF NOT EXISTS block_stake_inputs (
+		parent_id VARCHAR(64) PRIMARY KEY,
+		transaction_id VARCHAR(64) NOT NULL,
+		input_index INTEGER NOT NULL,
+		height BIGINT NOT NULL,
+		fulfillment_type INTEGER NOT NULL,
+		fulfillment_data TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS block_stake_outputs (
+		id VARCHAR(64) PRIMARY KEY,
+		transaction_id VARCHAR(64) NOT NULL,
+		output_index INTEGER NOT NULL,
+		height BIGINT NOT NULL,
+		value %AMOUNT% NOT NULL,
+		unlock_hash VARCHAR(78) NOT NULL,
+		condition_type INTEGER NOT NULL,
+		condition_data TEXT NOT NULL,
+		spent_by VARCHAR(64),
+		spent_height BIGINT
+	)`,
+	`CREATE INDEX IF NOT EXISTS transactions_height ON transactions (height)`,
+	`CREATE INDEX IF NOT EXISTS coin_inputs_height ON coin_inputs (height)`,
+	`CREATE INDEX IF NOT EXISTS coin_outputs_height ON coin_outputs (height)`,
+	`CREATE INDEX IF NOT EXISTS coin_outputs_spent_height ON coin_outputs (spent_height)`,
+	`CREATE INDEX IF NOT EXISTS coin_outputs_unlock_hash ON coin_outputs (unlock_hash)`,
+	`CREATE INDEX IF NOT EXISTS block_stake_inputs_height ON block_stake_inputs (height)`,
+	`CREATE INDEX IF NOT EXISTS block_stake_outputs_height ON block_stake_outputs (height)`,
+	`CREATE INDEX IF NOT EXISTS block_stake_outputs_spent_height ON block_stake_outputs (spent_height)`,
+	`CREATE INDEX IF NOT EXISTS block_stake_outputs_unlock_hash ON block_stake_outputs (unlock_hash)`,
+}
+
+// initSchema creates the relational schema if it doesn't exist yet,
+// as well as the initial sync state.
+func (idx *Indexer) initSchema() error {
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	replacer := strings.NewReplacer("%BLOB%", idx.dialect.BlobType(), "%AMOUNT%", idx.dialect.AmountType())
+	for _, stmt := range schema {
+		_, err = tx.Exec(replacer.Replace(stmt))
+		if err != nil {
+			return err
+		}
+	}
+
+	var count int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM sync_state`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		_, err = tx.Exec(rebind(idx.dialect, `INSERT INTO sync_state (id, change_id, block_count) VALUES (1, ?, 0)`), "")
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// getSyncState returns the last processed consensus change and the amount of indexed blocks.
+func getSyncState(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) (changeID string, blockCount uint64, err error) {
+	err = q.QueryRow(`SELECT change_id, block_count FROM sync_state WHERE id = 1`).Scan(&changeID, &blockCount)
+	return
+}
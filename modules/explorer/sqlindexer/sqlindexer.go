@@ -0,0 +1,152 @@
+// Package sqlindexer provides a consensus set subscriber, exporting the blockchain
+// into a relational database, such that it can be queried using SQL by other processes,
+// which isn't possible for the (single-process) bolt database of the explorer.
+package sqlindexer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const logFile = "sqlindexer.log"
+
+var (
+	errNilCS      = errors.New("SQL indexer cannot use a nil consensus set")
+	errNilDB      = errors.New("SQL indexer cannot use a nil database")
+	errNilDialect = errors.New("SQL indexer cannot use a nil dialect")
+)
+
+// Indexer exports all blocks of the consensus set, including their transactions,
+// inputs, outputs, conditions and extension data, into a relational database.
+type Indexer struct {
+	cs       modules.ConsensusSet
+	db       *sql.DB
+	dialect  Dialect
+	log      *persist.Logger
+	chainCts types.ChainConstants
+
+	mu sync.Mutex
+	// err is the error which caused the indexer to stop processing consensus changes
+	err error
+}
+
+// Open opens and connects to the database of the given (database/sql) driver and data source,
+// returning the dialect to use for it. It fails in case no dialect is known for the driver,
+// or in case the driver isn't linked in the binary, such that it is rejected at startup.
+func Open(driverName, dataSourceName string) (*sql.DB, Dialect, error) {
+	dialect, err := DialectForDriver(driverName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var linked bool
+	for _, name := range sql.Drivers() {
+		linked = linked || name == driverName
+	}
+	if !linked {
+		return nil, nil, fmt.Errorf("SQL driver %q is not linked in this binary", driverName)
+	}
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to connect to SQL database: %v", err)
+	}
+	return db, dialect, nil
+}
+
+// New creates the relational schema in the given database if it doesn't exist yet,
+// and subscribes to the consensus set, starting from the last consensus change
+// exported to that database. The database isn't closed by the indexer.
+func New(cs modules.ConsensusSet, db *sql.DB, dialect Dialect, persistDir string, bcInfo types.BlockchainInfo, chainCts types.ChainConstants, verboseLogging bool) (*Indexer, error) {
+	// Check that input modules are non-nil
+	if cs == nil {
+		return nil, errNilCS
+	}
+	if db == nil {
+		return nil, errNilDB
+	}
+	if dialect == nil {
+		return nil, errNilDialect
+	}
+
+	idx := &Indexer{
+		cs:       cs,
+		db:       db,
+		dialect:  dialect,
+		chainCts: chainCts,
+	}
+	err := os.MkdirAll(persistDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	idx.log, err = persist.NewFileLogger(bcInfo, filepath.Join(persistDir, logFile), verboseLogging)
+	if err != nil {
+		return nil, err
+	}
+	err = idx.initSchema()
+	if err != nil {
+		idx.log.Close()
+		return nil, fmt.Errorf("failed to create SQL schema: %v", err)
+	}
+
+	// retrieve the last exported consensus change
+	changeIDStr, _, err := getSyncState(db)
+	if err != nil {
+		idx.log.Close()
+		return nil, fmt.Errorf("failed to get SQL sync state: %v", err)
+	}
+	changeID := modules.ConsensusChangeBeginning
+	if changeIDStr != "" {
+		err = (*crypto.Hash)(&changeID).LoadString(changeIDStr)
+		if err != nil {
+			idx.log.Close()
+			return nil, fmt.Errorf("invalid consensus change ID in SQL sync state: %v", err)
+		}
+	}
+
+	err = cs.ConsensusSetSubscribe(idx, changeID, nil)
+	if err != nil {
+		idx.log.Close()
+		return nil, errors.New("SQL indexer subscription failed: " + err.Error())
+	}
+	// the subscription doesn't fail when a consensus change couldn't be exported
+	if err = idx.Err(); err != nil {
+		cs.Unsubscribe(idx)
+		idx.log.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Err returns the error which caused the indexer to stop exporting consensus changes, if any.
+// Once the cause has been resolved, the indexer has to be recreated, resuming from
+// the last consensus change which was exported successfully.
+func (idx *Indexer) Err() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.err
+}
+
+// BlockCount returns the amount of blocks exported into the database.
+func (idx *Indexer) BlockCount() (uint64, error) {
+	_, blockCount, err := getSyncState(idx.db)
+	return blockCount, err
+}
+
+// Close unsubscribes the indexer from the consensus set.
+func (idx *Indexer) Close() error {
+	idx.cs.Unsubscribe(idx)
+	return idx.log.Close()
+}
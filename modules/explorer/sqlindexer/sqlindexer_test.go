@@ -0,0 +1,27 @@
+package sqlindexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	query := `UPDATE coin_outputs SET spent_by = ?, spent_height = ? WHERE id = ?`
+	if s := rebind(SQLite, query); s != query {
+		t.Errorf("unexpected SQLite statement: %s", s)
+	}
+	if s := rebind(PostgreSQL, query); s != `UPDATE coin_outputs SET spent_by = $1, spent_height = $2 WHERE id = $3` {
+		t.Errorf("unexpected PostgreSQL statement: %s", s)
+	}
+}
+
+func TestOpenUnsupportedDriver(t *testing.T) {
+	// no dialect is known for the driver
+	if _, _, err := Open("mysql", ""); err == nil {
+		t.Error("expected driver without known dialect to be rejected")
+	}
+	// a known dialect, of which the driver isn't linked in the test binary
+	if _, _, err := Open("pgx", ""); err == nil || !strings.Contains(err.Error(), "not linked") {
+		t.Errorf("expected unlinked driver to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+//go:build sqlite
+// +build sqlite
+
+package sqlindexer
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	// the indexer is tested against an embedded SQLite database,
+	// only linked when testing with the sqlite tag, as it requires cgo
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// changeReplayTester is a consensus set which replays the given consensus changes to its subscribers.
+type changeReplayTester struct {
+	modules.ConsensusSet
+	changes []modules.ConsensusChange
+	start   modules.ConsensusChangeID
+}
+
+func (cs *changeReplayTester) ConsensusSetSubscribe(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID, _ <-chan struct{}) error {
+	cs.start = start
+	replay := start == modules.ConsensusChangeBeginning
+	for _, cc := range cs.changes {
+		if replay {
+			subscriber.ProcessConsensusChange(cc)
+		}
+		replay = replay || cc.ID == start
+	}
+	return nil
+}
+
+func (cs *changeReplayTester) Unsubscribe(modules.ConsensusSetSubscriber) {}
+
+// newSQLiteDB opens a new SQLite database, stored in the test directory.
+func newSQLiteDB(t *testing.T, testDir string) *sql.DB {
+	err := os.MkdirAll(testDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(testDir, "index.db")
+	os.Remove(dbPath)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testCount returns the amount of rows matched by the given query.
+func testCount(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM `+query, args...).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func testSyncState(t *testing.T, db *sql.DB, changeID modules.ConsensusChangeID, blockCount uint64) {
+	t.Helper()
+	changeIDStr, count, err := getSyncState(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changeIDStr != types.BlockID(changeID).String() || count != blockCount {
+		t.Errorf("unexpected sync state: %s (%d blocks), expected %s (%d blocks)",
+			changeIDStr, count, types.BlockID(changeID).String(), blockCount)
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	testDir := build.TempDir("sqlindexer", t.Name())
+	db := newSQLiteDB(t, testDir)
+	defer db.Close()
+
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+	genesis := types.Block{
+		Transactions: []types.Transaction{{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(100), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+			BlockStakeOutputs: []types.BlockStakeOutput{
+				{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			},
+		}},
+	}
+	block := types.Block{
+		ParentID:     genesis.ID(),
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(1), UnlockHash: bob}},
+		Transactions: []types.Transaction{{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: genesis.Transactions[0].CoinOutputID(0)}},
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(99), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			},
+			MinerFees:     []types.Currency{types.NewCurrency64(1)},
+			ArbitraryData: []byte("INV-1"),
+		}},
+	}
+	cs := &changeReplayTester{changes: []modules.ConsensusChange{
+		{ID: modules.ConsensusChangeID{1}, AppliedBlocks: []types.Block{genesis}},
+		{ID: modules.ConsensusChangeID{2}, AppliedBlocks: []types.Block{block}},
+	}}
+
+	chainCts := types.TestnetChainConstants()
+	idx, err := New(cs, db, SQLite, testDir, types.DefaultBlockchainInfo(), chainCts, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both blocks are exported, with the genesis output spent by the second block
+	var blockID string
+	err = db.QueryRow(`SELECT id FROM blocks WHERE height = 1`).Scan(&blockID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockID != block.ID().String() {
+		t.Errorf("unexpected block ID at height 1: %s", blockID)
+	}
+	for query, expected := range map[string]int{
+		`blocks`:              2,
+		`transactions`:        2,
+		`coin_inputs`:         1,
+		`coin_outputs`:        3,
+		`block_stake_outputs`: 1,
+		`transactions WHERE height = 1 AND miner_fees = '1' AND arbitrary_data = CAST('INV-1' AS BLOB)`: 1,
+	} {
+		if count := testCount(t, db, query); count != expected {
+			t.Errorf("unexpected count of %q: %d, expected %d", query, count, expected)
+		}
+	}
+	// the miner payout has no transaction, and is only spendable once matured
+	var (
+		payoutTxID      sql.NullString
+		payoutMaturity  uint64
+		payoutHash      string
+		spentBy         sql.NullString
+		spentHeight     sql.NullInt64
+		transactionID   = block.Transactions[0].ID()
+		genesisOutputID = genesis.Transactions[0].CoinOutputID(0)
+	)
+	err = db.QueryRow(`SELECT transaction_id, maturity_height, unlock_hash FROM coin_outputs WHERE id = ?`,
+		block.MinerPayoutID(0).String()).Scan(&payoutTxID, &payoutMaturity, &payoutHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payoutTxID.Valid || payoutMaturity != uint64(1+chainCts.MaturityDelay) || payoutHash != bob.String() {
+		t.Errorf("unexpected exported miner payout: %v %d %s", payoutTxID, payoutMaturity, payoutHash)
+	}
+	if count := testCount(t, db, `coin_outputs WHERE id = ? AND value = '99' AND unlock_hash = ?`,
+		block.Transactions[0].CoinOutputID(0).String(), bob.String()); count != 1 {
+		t.Error("coin output of the second block wasn't exported")
+	}
+	err = db.QueryRow(`SELECT spent_by, spent_height FROM coin_outputs WHERE id = ?`, genesisOutputID.String()).Scan(&spentBy, &spentHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spentBy.String != transactionID.String() || spentHeight.Int64 != 1 {
+		t.Errorf("unexpected spent genesis output: %v at %v", spentBy, spentHeight)
+	}
+	testSyncState(t, db, modules.ConsensusChangeID{2}, 2)
+	if blockCount, err := idx.BlockCount(); err != nil || blockCount != 2 {
+		t.Errorf("unexpected block count: %d (%v)", blockCount, err)
+	}
+
+	// reverting a block removes everything it created at its height,
+	// prior to applying the blocks replacing it
+	fork := types.Block{ParentID: genesis.ID(), Timestamp: 1}
+	idx.ProcessConsensusChange(modules.ConsensusChange{
+		ID:             modules.ConsensusChangeID{3},
+		RevertedBlocks: []types.Block{block},
+		AppliedBlocks:  []types.Block{fork},
+	})
+	if err = idx.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for query, expected := range map[string]int{
+		`blocks`:       2,
+		`transactions`: 1,
+		`coin_inputs`:  0,
+		`coin_outputs`: 1,
+		`coin_outputs WHERE spent_by IS NULL AND spent_height IS NULL`: 1,
+		`block_stake_outputs`: 1,
+	} {
+		if count := testCount(t, db, query); count != expected {
+			t.Errorf("unexpected count of %q after fork: %d, expected %d", query, count, expected)
+		}
+	}
+	if count := testCount(t, db, `blocks WHERE height = 1 AND id = ?`, fork.ID().String()); count != 1 {
+		t.Error("fork block wasn't exported")
+	}
+	testSyncState(t, db, modules.ConsensusChangeID{3}, 2)
+
+	// a failed export is rolled back, and stops the indexer
+	_, err = db.Exec(`CREATE TRIGGER fail_blocks BEFORE INSERT ON blocks BEGIN SELECT RAISE(ABORT, 'test failure'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.ProcessConsensusChange(modules.ConsensusChange{
+		ID:             modules.ConsensusChangeID{4},
+		RevertedBlocks: []types.Block{fork},
+		AppliedBlocks:  []types.Block{{ParentID: genesis.ID(), Timestamp: 2}},
+	})
+	if idx.Err() == nil {
+		t.Fatal("expected the indexer to fail")
+	}
+	_, err = db.Exec(`DROP TRIGGER fail_blocks`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.ProcessConsensusChange(modules.ConsensusChange{
+		ID:            modules.ConsensusChangeID{5},
+		AppliedBlocks: []types.Block{{ParentID: fork.ID(), Timestamp: 3}},
+	})
+	if count := testCount(t, db, `blocks WHERE height = 1 AND id = ?`, fork.ID().String()); count != 1 {
+		t.Error("reverted fork block wasn't restored by the rollback")
+	}
+	if count := testCount(t, db, `blocks`); count != 2 {
+		t.Errorf("unexpected amount of blocks after failure: %d", count)
+	}
+	testSyncState(t, db, modules.ConsensusChangeID{3}, 2)
+	idx.Close()
+
+	// a new indexer resumes from the last exported consensus change,
+	// without recreating the schema or sync state
+	next := types.Block{ParentID: fork.ID(), Timestamp: 4}
+	cs.changes = append(cs.changes,
+		modules.ConsensusChange{ID: modules.ConsensusChangeID{3}, RevertedBlocks: []types.Block{block}, AppliedBlocks: []types.Block{fork}},
+		modules.ConsensusChange{ID: modules.ConsensusChangeID{6}, AppliedBlocks: []types.Block{next}},
+	)
+	idx, err = New(cs, db, SQLite, testDir, types.DefaultBlockchainInfo(), chainCts, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if cs.start != (modules.ConsensusChangeID{3}) {
+		t.Errorf("unexpected subscription start: %v", cs.start)
+	}
+	if count := testCount(t, db, `blocks WHERE height = 2 AND id = ?`, next.ID().String()); count != 1 {
+		t.Error("block applied after resuming wasn't exported")
+	}
+	if count := testCount(t, db, `sync_state`); count != 1 {
+		t.Errorf("unexpected amount of sync states: %d", count)
+	}
+	testSyncState(t, db, modules.ConsensusChangeID{6}, 3)
+}
@@ -0,0 +1,225 @@
+package sqlindexer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ProcessConsensusChange exports the applied blocks of the consensus change,
+// after removing its reverted blocks, within a single SQL transaction. If the export fails,
+// the indexer stops processing consensus changes, leaving the database at the last
+// successfully exported consensus change.
+func (idx *Indexer) ProcessConsensusChange(cc modules.ConsensusChange) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.err != nil {
+		return // a previous consensus change couldn't be exported
+	}
+	err := idx.processConsensusChange(cc)
+	if err != nil {
+		idx.err = fmt.Errorf("failed to export consensus change %s: %v", crypto.Hash(cc.ID).String(), err)
+		idx.log.Printf("[ERROR] %v, no longer exporting consensus changes", idx.err)
+	}
+}
+
+func (idx *Indexer) processConsensusChange(cc modules.ConsensusChange) error {
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	utx := updateTx{Tx: tx, dialect: idx.dialect, chainCts: idx.chainCts}
+
+	_, blockCount, err := getSyncState(tx)
+	if err != nil {
+		return err
+	}
+	for _, block := range cc.RevertedBlocks {
+		if blockCount == 0 {
+			return fmt.Errorf("cannot revert block %s, no blocks are exported", block.ID().String())
+		}
+		blockCount--
+		err = utx.revertBlock(types.BlockHeight(blockCount))
+		if err != nil {
+			return fmt.Errorf("failed to revert block %s: %v", block.ID().String(), err)
+		}
+	}
+	for _, block := range cc.AppliedBlocks {
+		err = utx.applyBlock(block, types.BlockHeight(blockCount))
+		if err != nil {
+			return fmt.Errorf("failed to apply block %s: %v", block.ID().String(), err)
+		}
+		blockCount++
+	}
+
+	err = utx.exec(`UPDATE sync_state SET change_id = ?, block_count = ? WHERE id = 1`,
+		crypto.Hash(cc.ID).String(), blockCount)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateTx is the SQL transaction used to export a consensus change.
+type updateTx struct {
+	*sql.Tx
+	dialect  Dialect
+	chainCts types.ChainConstants
+}
+
+func (tx updateTx) exec(query string, args ...interface{}) error {
+	_, err := tx.Exec(rebind(tx.dialect, query), args...)
+	return err
+}
+
+// revertBlock removes the block at the given height, and everything it created,
+// marking the outputs it spent as unspent again.
+func (tx updateTx) revertBlock(height types.BlockHeight) error {
+	for _, query := range []string{
+		`UPDATE coin_outputs SET spent_by = NULL, spent_height = NULL WHERE spent_height = ?`,
+		`UPDATE block_stake_outputs SET spent_by = NULL, spent_height = NULL WHERE spent_height = ?`,
+		`DELETE FROM coin_inputs WHERE height = ?`,
+		`DELETE FROM block_stake_inputs WHERE height = ?`,
+		`DELETE FROM coin_outputs WHERE height = ?`,
+		`DELETE FROM block_stake_outputs WHERE height = ?`,
+		`DELETE FROM transactions WHERE height = ?`,
+		`DELETE FROM blocks WHERE height = ?`,
+	} {
+		err := tx.exec(query, uint64(height))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyBlock adds the given block at the given height,
+// marking the outputs its transactions spend as spent.
+func (tx updateTx) applyBlock(block types.Block, height types.BlockHeight) error {
+	err := tx.exec(`INSERT INTO blocks (height, id, parent_id, timestamp, pobs_block_height, pobs_transaction_index, pobs_output_index) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		uint64(height), block.ID().String(), block.ParentID.String(), uint64(block.Timestamp),
+		uint64(block.POBSOutput.BlockHeight), block.POBSOutput.TransactionIndex, block.POBSOutput.OutputIndex)
+	if err != nil {
+		return err
+	}
+
+	for j, payout := range block.MinerPayouts {
+		err = tx.insertCoinOutput(block.MinerPayoutID(uint64(j)), nil, j, height, height+tx.chainCts.MaturityDelay,
+			types.CoinOutput{
+				Value:     payout.Value,
+				Condition: types.NewCondition(types.NewUnlockHashCondition(payout.UnlockHash)),
+			})
+		if err != nil {
+			return err
+		}
+	}
+	for i, txn := range block.Transactions {
+		err = tx.applyTransaction(txn, i, height)
+		if err != nil {
+			return fmt.Errorf("failed to apply transaction %s: %v", txn.ID().String(), err)
+		}
+	}
+	return nil
+}
+
+func (tx updateTx) applyTransaction(txn types.Transaction, blockIndex int, height types.BlockHeight) error {
+	txid := txn.ID()
+	jsonData, err := json.Marshal(txn)
+	if err != nil {
+		return err
+	}
+	var extensionData interface{}
+	if txn.Extension != nil {
+		b, err := json.Marshal(txn.Extension)
+		if err != nil {
+			return err
+		}
+		extensionData = string(b)
+	}
+	var arbitraryData interface{}
+	if len(txn.ArbitraryData) > 0 {
+		arbitraryData = txn.ArbitraryData
+	}
+	var fees types.Currency
+	for _, fee := range txn.MinerFees {
+		fees = fees.Add(fee)
+	}
+	err = tx.exec(`INSERT INTO transactions (id, height, block_index, version, miner_fees, arbitrary_data, extension_data, json_data) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		txid.String(), uint64(height), blockIndex, int(txn.Version), fees.String(), arbitraryData, extensionData, string(jsonData))
+	if err != nil {
+		return err
+	}
+
+	for i, ci := range txn.CoinInputs {
+		err = tx.insertInput("coin_inputs", ci.ParentID.String(), txid, i, height, ci.Fulfillment)
+		if err != nil {
+			return err
+		}
+		err = tx.exec(`UPDATE coin_outputs SET spent_by = ?, spent_height = ? WHERE id = ?`,
+			txid.String(), uint64(height), ci.ParentID.String())
+		if err != nil {
+			return err
+		}
+	}
+	for i, co := range txn.CoinOutputs {
+		err = tx.insertCoinOutput(txn.CoinOutputID(uint64(i)), &txid, i, height, height, co)
+		if err != nil {
+			return err
+		}
+	}
+	for i, bsi := range txn.BlockStakeInputs {
+		err = tx.insertInput("block_stake_inputs", bsi.ParentID.String(), txid, i, height, bsi.Fulfillment)
+		if err != nil {
+			return err
+		}
+		err = tx.exec(`UPDATE block_stake_outputs SET spent_by = ?, spent_height = ? WHERE id = ?`,
+			txid.String(), uint64(height), bsi.ParentID.String())
+		if err != nil {
+			return err
+		}
+	}
+	for i, bso := range txn.BlockStakeOutputs {
+		condition, err := json.Marshal(bso.Condition)
+		if err != nil {
+			return err
+		}
+		err = tx.exec(`INSERT INTO block_stake_outputs (id, transaction_id, output_index, height, value, unlock_hash, condition_type, condition_data) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			txn.BlockStakeOutputID(uint64(i)).String(), txid.String(), i, uint64(height), bso.Value.String(),
+			bso.Condition.UnlockHash().String(), int(bso.Condition.ConditionType()), string(condition))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertInput adds an input to the given input table.
+func (tx updateTx) insertInput(table, parentID string, txid types.TransactionID, index int, height types.BlockHeight, fulfillment types.UnlockFulfillmentProxy) error {
+	fulfillmentData, err := json.Marshal(fulfillment)
+	if err != nil {
+		return err
+	}
+	return tx.exec(`INSERT INTO `+table+` (parent_id, transaction_id, input_index, height, fulfillment_type, fulfillment_data) VALUES (?, ?, ?, ?, ?, ?)`,
+		parentID, txid.String(), index, uint64(height), int(fulfillment.FulfillmentType()), string(fulfillmentData))
+}
+
+// insertCoinOutput adds a coin output, created by the transaction with the given ID,
+// or as a miner payout if no transaction ID is given.
+func (tx updateTx) insertCoinOutput(id types.CoinOutputID, txid *types.TransactionID, index int, height, maturityHeight types.BlockHeight, co types.CoinOutput) error {
+	condition, err := json.Marshal(co.Condition)
+	if err != nil {
+		return err
+	}
+	var txidStr interface{}
+	if txid != nil {
+		txidStr = txid.String()
+	}
+	return tx.exec(`INSERT INTO coin_outputs (id, transaction_id, output_index, height, maturity_height, value, unlock_hash, condition_type, condition_data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id.String(), txidStr, index, uint64(height), uint64(maturityHeight), co.Value.String(),
+		co.Condition.UnlockHash().String(), int(co.Condition.ConditionType()), string(condition))
+}
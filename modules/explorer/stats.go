@@ -0,0 +1,269 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// SupplyStatsSnapshotInterval is the amount of blocks
+	// between two snapshots of the supply statistics.
+	SupplyStatsSnapshotInterval = build.Select(build.Var{
+		Standard: types.BlockHeight(1000),
+		Dev:      types.BlockHeight(100),
+		Testing:  types.BlockHeight(10),
+	}).(types.BlockHeight)
+)
+
+// richListAmountSize is the size of the big endian encoded amount,
+// which prefixes the unlock hash of an address in a rich list bucket,
+// such that the addresses are sorted by amount.
+const richListAmountSize = 32
+
+// supplyStats are the supply statistics stored in bucketInternal,
+// and updated for every block.
+type supplyStats struct {
+	TotalCoins            types.Currency
+	TotalBlockStakes      types.Currency
+	BurnedCoins           types.Currency
+	CoinHolderCount       uint64
+	BlockStakeHolderCount uint64
+}
+
+// SupplyStats returns the supply statistics at the latest block height of the explorer.
+func (e *Explorer) SupplyStats() (stats modules.SupplyStats) {
+	err := e.db.View(func(tx *bolt.Tx) error {
+		ctx, err := e.dbGetFulfillableContext(tx)
+		if err != nil {
+			return err
+		}
+		stats, err = dbGetSupplyStats(tx, ctx)
+		return err
+	})
+	if err != nil {
+		build.Critical(err)
+	}
+	return
+}
+
+// SupplyStatsHistory returns up to the given amount of the most recent
+// snapshots of the supply statistics, the most recent snapshot first.
+func (e *Explorer) SupplyStatsHistory(count int) (history []modules.SupplyStats) {
+	err := e.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketSupplyStatsHistory).Cursor()
+		for k, v := c.Last(); k != nil && len(history) < count; k, v = c.Prev() {
+			var stats modules.SupplyStats
+			err := siabin.Unmarshal(v, &stats)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal supply stats snapshot: %v", err)
+			}
+			history = append(history, stats)
+		}
+		return nil
+	})
+	if err != nil {
+		build.Critical(err)
+	}
+	return
+}
+
+// RichList returns up to the given amount of addresses
+// with the highest (confirmed) coin balance.
+func (e *Explorer) RichList(count int) []modules.AddressAmount {
+	return e.richList(bucketCoinRichList, count)
+}
+
+// BlockStakeDistribution returns up to the given amount of addresses
+// with the highest (confirmed) block stake balance.
+func (e *Explorer) BlockStakeDistribution(count int) []modules.AddressAmount {
+	return e.richList(bucketBlockStakeRichList, count)
+}
+
+func (e *Explorer) richList(bucket []byte, count int) (list []modules.AddressAmount) {
+	err := e.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		for k, _ := c.Last(); k != nil && len(list) < count; k, _ = c.Prev() {
+			entry := modules.AddressAmount{
+				Amount: types.NewCurrency(new(big.Int).SetBytes(k[:richListAmountSize])),
+			}
+			err := siabin.Unmarshal(k[richListAmountSize:], &entry.Address)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal rich list address: %v", err)
+			}
+			list = append(list, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		build.Critical(err)
+	}
+	return
+}
+
+// dbGetSupplyStats returns the supply statistics, computing the locked coins for the given context.
+func dbGetSupplyStats(tx *bolt.Tx, ctx types.FulfillableContext) (modules.SupplyStats, error) {
+	var stats supplyStats
+	err := dbGetInternal(internalSupplyStats, &stats)(tx)
+	if err != nil {
+		return modules.SupplyStats{}, err
+	}
+	var locked types.Currency
+	err = tx.Bucket(bucketLockableCoinOutputs).ForEach(func(_, v []byte) error {
+		var output addressOutput
+		err := siabin.Unmarshal(v, &output)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal lockable output: %v", err)
+		}
+		if !output.spendable(ctx) {
+			locked = locked.Add(output.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		return modules.SupplyStats{}, err
+	}
+	return modules.SupplyStats{
+		Height:                ctx.BlockHeight,
+		Timestamp:             ctx.BlockTime,
+		TotalCoins:            stats.TotalCoins,
+		LockedCoins:           locked,
+		CirculatingCoins:      stats.TotalCoins.Sub(locked),
+		BurnedCoins:           stats.BurnedCoins,
+		TotalBlockStakes:      stats.TotalBlockStakes,
+		CoinHolderCount:       stats.CoinHolderCount,
+		BlockStakeHolderCount: stats.BlockStakeHolderCount,
+	}, nil
+}
+
+// The functions below panic on error. The panic will be caught by
+// ProcessConsensusChange.
+
+// dbApplySupplyStats adds the coins burned by the given (applied) block to the supply statistics,
+// and takes a snapshot of the supply statistics every SupplyStatsSnapshotInterval blocks.
+// It has to be called after the address index has been updated for the block.
+func dbApplySupplyStats(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	burned := dbBurnedCoins(tx, block)
+	if !burned.IsZero() {
+		dbUpdateSupplyStats(tx, func(stats *supplyStats) {
+			stats.BurnedCoins = stats.BurnedCoins.Add(burned)
+		})
+	}
+	if height%SupplyStatsSnapshotInterval != 0 {
+		return
+	}
+	snapshot, err := dbGetSupplyStats(tx, types.FulfillableContext{
+		BlockHeight: height,
+		BlockTime:   block.Timestamp,
+	})
+	assertNil(err)
+	assertNil(tx.Bucket(bucketSupplyStatsHistory).Put(supplyStatsHistoryKey(height), assertSiaMarshal(snapshot)))
+}
+
+// dbRevertSupplyStats reverts the changes dbApplySupplyStats made for the given block.
+// It has to be called before the outputs of the block are removed from bucketCoinOutputs.
+func dbRevertSupplyStats(tx *bolt.Tx, block types.Block, height types.BlockHeight) {
+	burned := dbBurnedCoins(tx, block)
+	if !burned.IsZero() {
+		dbUpdateSupplyStats(tx, func(stats *supplyStats) {
+			stats.BurnedCoins = stats.BurnedCoins.Sub(burned)
+		})
+	}
+	if height%SupplyStatsSnapshotInterval == 0 {
+		assertNil(tx.Bucket(bucketSupplyStatsHistory).Delete(supplyStatsHistoryKey(height)))
+	}
+}
+
+// dbBurnedCoins returns the amount of coins burned by the transactions of the given block,
+// being the coins spent by a transaction which aren't sent to an output or paid as a fee,
+// as is for example the case for coin destruction transactions.
+func dbBurnedCoins(tx *bolt.Tx, block types.Block) (burned types.Currency) {
+	for _, txn := range block.Transactions {
+		var in, out types.Currency
+		for _, ci := range txn.CoinInputs {
+			var co types.CoinOutput
+			assertNil(dbGetAndDecode(bucketCoinOutputs, ci.ParentID, &co)(tx))
+			in = in.Add(co.Value)
+		}
+		for _, co := range txn.CoinOutputs {
+			out = out.Add(co.Value)
+		}
+		for _, fee := range txn.MinerFees {
+			out = out.Add(fee)
+		}
+		if in.Cmp(out) > 0 {
+			burned = burned.Add(in.Sub(out))
+		}
+	}
+	return
+}
+
+// dbUpdateSupplyStats updates the supply statistics using the given callback.
+func dbUpdateSupplyStats(tx *bolt.Tx, fn func(*supplyStats)) {
+	var stats supplyStats
+	assertNil(dbGetInternal(internalSupplyStats, &stats)(tx))
+	fn(&stats)
+	assertNil(dbSetInternal(internalSupplyStats, stats)(tx))
+}
+
+// dbUpdateSupply updates the supply statistics and the rich lists
+// for a balance update of an address, called by dbUpdateAddressBalance.
+func dbUpdateSupply(tx *bolt.Tx, uh types.UnlockHash, before, after addressBalance) {
+	dbUpdateRichList(tx, bucketCoinRichList, uh, before.Coins, after.Coins)
+	dbUpdateRichList(tx, bucketBlockStakeRichList, uh, before.BlockStakes, after.BlockStakes)
+	dbUpdateSupplyStats(tx, func(stats *supplyStats) {
+		stats.TotalCoins = stats.TotalCoins.Add(after.Coins).Sub(before.Coins)
+		stats.TotalBlockStakes = stats.TotalBlockStakes.Add(after.BlockStakes).Sub(before.BlockStakes)
+		stats.CoinHolderCount = updateHolderCount(stats.CoinHolderCount, before.Coins, after.Coins)
+		stats.BlockStakeHolderCount = updateHolderCount(stats.BlockStakeHolderCount, before.BlockStakes, after.BlockStakes)
+	})
+}
+
+func updateHolderCount(count uint64, before, after types.Currency) uint64 {
+	switch {
+	case before.IsZero() && !after.IsZero():
+		return count + 1
+	case !before.IsZero() && after.IsZero():
+		return count - 1
+	default:
+		return count
+	}
+}
+
+// dbUpdateRichList moves an address within a rich list from its old to its new amount.
+func dbUpdateRichList(tx *bolt.Tx, bucket []byte, uh types.UnlockHash, before, after types.Currency) {
+	if before.Equals(after) {
+		return
+	}
+	b := tx.Bucket(bucket)
+	if !before.IsZero() {
+		assertNil(b.Delete(richListKey(uh, before)))
+	}
+	if !after.IsZero() {
+		assertNil(b.Put(richListKey(uh, after), nil))
+	}
+}
+
+// richListKey returns the key of an address within a rich list.
+func richListKey(uh types.UnlockHash, amount types.Currency) []byte {
+	ab := amount.Big().Bytes()
+	if len(ab) > richListAmountSize {
+		panic(fmt.Errorf("amount %s is too large for the rich list", amount.String()))
+	}
+	key := make([]byte, richListAmountSize, richListAmountSize+33)
+	copy(key[richListAmountSize-len(ab):], ab)
+	return append(key, assertSiaMarshal(uh)...)
+}
+
+// supplyStatsHistoryKey returns the key of a snapshot of the supply statistics.
+func supplyStatsHistoryKey(height types.BlockHeight) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(height))
+	return key
+}
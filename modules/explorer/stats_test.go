@@ -0,0 +1,194 @@
+package explorer
+
+import (
+	"testing"
+
+	bolt "github.com/rivine/bbolt"
+	"github.com/threefoldtech/rivine/extensions/relativetimelock"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func (e *Explorer) testSupplyStats(t *testing.T, height types.BlockHeight, expected modules.SupplyStats) {
+	t.Helper()
+	err := e.db.View(func(tx *bolt.Tx) error {
+		stats, err := dbGetSupplyStats(tx, types.FulfillableContext{BlockHeight: height})
+		if err != nil {
+			return err
+		}
+		expected.Height = height
+		if !equalSupplyStats(stats, expected) {
+			t.Errorf("unexpected supply stats at height %d: %+v != %+v", height, stats, expected)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func equalSupplyStats(a, b modules.SupplyStats) bool {
+	return a.Height == b.Height && a.Timestamp == b.Timestamp &&
+		a.TotalCoins.Equals(b.TotalCoins) && a.LockedCoins.Equals(b.LockedCoins) &&
+		a.CirculatingCoins.Equals(b.CirculatingCoins) && a.BurnedCoins.Equals(b.BurnedCoins) &&
+		a.TotalBlockStakes.Equals(b.TotalBlockStakes) &&
+		a.CoinHolderCount == b.CoinHolderCount && a.BlockStakeHolderCount == b.BlockStakeHolderCount
+}
+
+func testRichList(t *testing.T, list []modules.AddressAmount, expected ...modules.AddressAmount) {
+	t.Helper()
+	if len(list) != len(expected) {
+		t.Fatalf("expected %d holders, got %d: %v", len(expected), len(list), list)
+	}
+	for idx := range list {
+		if list[idx].Address != expected[idx].Address || !list[idx].Amount.Equals(expected[idx].Amount) {
+			t.Errorf("unexpected holder #%d: %v != %v", idx, list[idx], expected[idx])
+		}
+	}
+}
+
+func TestSupplyStats(t *testing.T) {
+	e := newAddressIndexTester(t)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+	carol := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{3}}
+
+	genesis := types.Block{
+		Transactions: []types.Transaction{{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(100), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+				{Value: types.NewCurrency64(50), Condition: types.NewCondition(types.NewTimeLockCondition(1000, types.NewUnlockHashCondition(carol)))},
+			},
+			BlockStakeOutputs: []types.BlockStakeOutput{
+				{Value: types.NewCurrency64(10), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+			},
+		}},
+	}
+	e.applyTestBlock(t, genesis, 0)
+	genesisStats := modules.SupplyStats{
+		TotalCoins:            types.NewCurrency64(150),
+		LockedCoins:           types.NewCurrency64(50),
+		CirculatingCoins:      types.NewCurrency64(100),
+		TotalBlockStakes:      types.NewCurrency64(10),
+		CoinHolderCount:       2,
+		BlockStakeHolderCount: 1,
+	}
+	e.testSupplyStats(t, 0, genesisStats)
+	// the time-locked coins are unlocked once the lock height is reached
+	e.testSupplyStats(t, 1000, modules.SupplyStats{
+		TotalCoins:            types.NewCurrency64(150),
+		CirculatingCoins:      types.NewCurrency64(150),
+		TotalBlockStakes:      types.NewCurrency64(10),
+		CoinHolderCount:       2,
+		BlockStakeHolderCount: 1,
+	})
+
+	// alice sends 30 coins to bob, and burns 10 coins
+	block := types.Block{
+		ParentID:     genesis.ID(),
+		MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(1), UnlockHash: bob}},
+		Transactions: []types.Transaction{{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: genesis.Transactions[0].CoinOutputID(0)}},
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(30), Condition: types.NewCondition(types.NewUnlockHashCondition(bob))},
+				{Value: types.NewCurrency64(59), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}},
+	}
+	e.applyTestBlock(t, block, 1)
+	e.testSupplyStats(t, 1, modules.SupplyStats{
+		TotalCoins:            types.NewCurrency64(140),
+		LockedCoins:           types.NewCurrency64(50),
+		CirculatingCoins:      types.NewCurrency64(90),
+		BurnedCoins:           types.NewCurrency64(10),
+		TotalBlockStakes:      types.NewCurrency64(10),
+		CoinHolderCount:       3,
+		BlockStakeHolderCount: 1,
+	})
+	testRichList(t, e.RichList(10),
+		modules.AddressAmount{Address: alice, Amount: types.NewCurrency64(59)},
+		modules.AddressAmount{Address: carol, Amount: types.NewCurrency64(50)},
+		modules.AddressAmount{Address: bob, Amount: types.NewCurrency64(31)},
+	)
+	testRichList(t, e.RichList(1),
+		modules.AddressAmount{Address: alice, Amount: types.NewCurrency64(59)},
+	)
+	testRichList(t, e.BlockStakeDistribution(10),
+		modules.AddressAmount{Address: bob, Amount: types.NewCurrency64(10)},
+	)
+
+	// a snapshot is taken at the genesis block
+	history := e.SupplyStatsHistory(10)
+	if len(history) != 1 || !equalSupplyStats(history[0], genesisStats) {
+		t.Errorf("unexpected supply stats history: %v", history)
+	}
+
+	// reverting the blocks should restore the previous state
+	e.revertTestBlock(t, block, 1)
+	e.testSupplyStats(t, 0, genesisStats)
+	testRichList(t, e.RichList(10),
+		modules.AddressAmount{Address: alice, Amount: types.NewCurrency64(100)},
+		modules.AddressAmount{Address: carol, Amount: types.NewCurrency64(50)},
+	)
+	e.revertTestBlock(t, genesis, 0)
+	e.testSupplyStats(t, 0, modules.SupplyStats{})
+	testRichList(t, e.RichList(10))
+	testRichList(t, e.BlockStakeDistribution(10))
+	if history := e.SupplyStatsHistory(10); len(history) != 0 {
+		t.Errorf("unexpected supply stats history after revert: %v", history)
+	}
+}
+
+func TestSupplyStatsRelativeTimeLock(t *testing.T) {
+	relativetimelock.RegisterUnlockConditionType()
+	defer relativetimelock.UnregisterUnlockConditionType()
+
+	e := newAddressIndexTester(t)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+
+	genesis := types.Block{
+		Transactions: []types.Transaction{{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(100), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+		}},
+	}
+	e.applyTestBlock(t, genesis, 0)
+	// the coins sent to bob are locked for 10 blocks since the block which created them
+	block := types.Block{
+		ParentID: genesis.ID(),
+		Transactions: []types.Transaction{{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: genesis.Transactions[0].CoinOutputID(0)}},
+			CoinOutputs: []types.CoinOutput{
+				{Value: types.NewCurrency64(40), Condition: types.NewCondition(relativetimelock.NewRelativeTimeLockCondition(
+					10, relativetimelock.LockUnitBlocks, types.NewUnlockHashCondition(bob)))},
+				{Value: types.NewCurrency64(60), Condition: types.NewCondition(types.NewUnlockHashCondition(alice))},
+			},
+		}},
+	}
+	e.applyTestBlock(t, block, 1)
+	e.testSupplyStats(t, 10, modules.SupplyStats{
+		TotalCoins:       types.NewCurrency64(100),
+		LockedCoins:      types.NewCurrency64(40),
+		CirculatingCoins: types.NewCurrency64(60),
+		CoinHolderCount:  2,
+	})
+	e.testSupplyStats(t, 11, modules.SupplyStats{
+		TotalCoins:       types.NewCurrency64(100),
+		CirculatingCoins: types.NewCurrency64(100),
+		CoinHolderCount:  2,
+	})
+
+	e.revertTestBlock(t, block, 1)
+	e.testSupplyStats(t, 10, modules.SupplyStats{
+		TotalCoins:       types.NewCurrency64(100),
+		CirculatingCoins: types.NewCurrency64(100),
+		CoinHolderCount:  1,
+	})
+}
@@ -17,6 +17,7 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 		build.Critical("Explorer.ProcessConsensusChange called with a ConsensusChange that has no AppliedBlocks")
 	}
 
+	var blockheight types.BlockHeight
 	err := e.db.Update(func(tx *bolt.Tx) (err error) {
 		// use exception-style error handling to enable more concise update code
 		defer func() {
@@ -26,7 +27,6 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}()
 
 		// get starting block height
-		var blockheight types.BlockHeight
 		err = dbGetInternal(internalBlockHeight, &blockheight)(tx)
 		if err != nil {
 			return err
@@ -37,6 +37,15 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 			bid := block.ID()
 			tbid := types.TransactionID(bid)
 
+			// revert the address indices first,
+			// as they require the outputs of the block to be still available
+			dbRevertSupplyStats(tx, block, blockheight)
+			e.dbRevertAddressIndex(tx, block)
+			dbRevertAddressHistory(tx, block, blockheight)
+			if e.indexArbitraryData {
+				dbRevertArbitraryDataIndex(tx, block, blockheight)
+			}
+
 			blockheight--
 			dbRemoveBlockID(tx, bid)
 			dbRemoveTransactionID(tx, tbid) // Miner payouts are a transaction
@@ -90,6 +99,11 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 				for _, condition := range exData.UnlockConditions {
 					unmapUnlockConditionHash(tx, condition, txid)
 				}
+				// remove any addresses linked through the extension data by its explorer extension
+				exAddresses, _ := modules.ExplorerTransactionAddresses(txn)
+				for _, uh := range exAddresses {
+					dbRemoveUnlockHash(tx, uh, txid)
+				}
 			}
 
 			// remove the associated block facts
@@ -104,6 +118,12 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 			// special handling for genesis block
 			if bid == e.genesisBlockID {
 				e.dbAddGenesisBlock(tx)
+				e.dbApplyAddressIndex(tx, block, 0)
+				dbApplyAddressHistory(tx, block, 0)
+				dbApplySupplyStats(tx, block, 0)
+				if e.indexArbitraryData {
+					dbApplyArbitraryDataIndex(tx, block, 0)
+				}
 				continue
 			}
 
@@ -172,6 +192,19 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 				for _, condition := range exData.UnlockConditions {
 					mapUnlockConditionHash(tx, condition, txid)
 				}
+				// add any addresses linked through the extension data by its explorer extension
+				exAddresses, _ := modules.ExplorerTransactionAddresses(txn)
+				for _, uh := range exAddresses {
+					dbAddUnlockHash(tx, uh, txid)
+				}
+			}
+
+			// update the unspent outputs, balances and history of all affected addresses
+			e.dbApplyAddressIndex(tx, block, blockheight)
+			dbApplyAddressHistory(tx, block, blockheight)
+			dbApplySupplyStats(tx, block, blockheight)
+			if e.indexArbitraryData {
+				dbApplyArbitraryDataIndex(tx, block, blockheight)
 			}
 
 			// calculate and add new block facts, if possible
@@ -226,6 +259,10 @@ func (e *Explorer) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 		return nil
 	})
+	if e.onUpdate != nil {
+		e.onUpdate(blockheight, err)
+		return
+	}
 	if err != nil {
 		build.Critical("explorer update failed:", err)
 	}
@@ -575,6 +612,9 @@ func dbRemoveUnlockHash(tx *bolt.Tx, uh types.UnlockHash, txid types.Transaction
 	uhb := tx.Bucket(bucketUnlockHashes)
 	muh := assertSiaMarshal(uh)
 	b := uhb.Bucket(muh)
+	if b == nil {
+		return // already removed, e.g. when linked through both a condition and the extension data
+	}
 	mustDelete(b, txid)
 	if bucketIsEmpty(b) {
 		if err := uhb.DeleteBucket(muh); err != nil {
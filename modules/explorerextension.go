@@ -0,0 +1,131 @@
+package modules
+
+import (
+	"fmt"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// ExplorerTransactionExtension allows an extension to expose the extension data
+	// of the transactions of a transaction version it defines in the explorer,
+	// such that these transactions are shown and indexed the same way as regular transactions.
+	ExplorerTransactionExtension interface {
+		// TransactionType returns the human-readable name of the transaction type,
+		// e.g. "coin creation".
+		TransactionType() string
+
+		// TransactionView returns a decoded (JSON-encodable) view
+		// of the extension data of the given transaction.
+		TransactionView(txn types.Transaction) (interface{}, error)
+	}
+
+	// ExplorerTransactionAddressesGetter defines an optional interface for explorer transaction extensions,
+	// linking a transaction to addresses which are only referred to by its extension data,
+	// next to the addresses of the conditions defined in its common extension data.
+	// The addresses have to be derived from the transaction itself, as they
+	// are indexed as part of the address history of these addresses.
+	ExplorerTransactionAddressesGetter interface {
+		TransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error)
+	}
+
+	// ExplorerTransactionExtensionView is the view on the extension data of a transaction,
+	// as returned by the explorer.
+	ExplorerTransactionExtensionView struct {
+		// Type is the name of the transaction type,
+		// only defined if an explorer transaction extension is registered for its version.
+		Type string `json:"type,omitempty"`
+		// Addresses are the addresses linked to the transaction through its extension data.
+		Addresses []types.UnlockHash `json:"addresses,omitempty"`
+		// Data is the decoded view on the extension data.
+		Data interface{} `json:"data,omitempty"`
+	}
+)
+
+var (
+	_RegisteredExplorerTransactionExtensions = map[types.TransactionVersion]ExplorerTransactionExtension{}
+)
+
+// RegisterExplorerTransactionExtension registers or unregisters the explorer extension
+// of a given transaction version.
+//
+// NOTE: this function should only be called in the `init` func,
+// or at the very least prior to creating the explorer,
+// as the extension addresses are indexed by the explorer.
+func RegisterExplorerTransactionExtension(v types.TransactionVersion, ext ExplorerTransactionExtension) {
+	if ext == nil {
+		delete(_RegisteredExplorerTransactionExtensions, v)
+		return
+	}
+	_RegisteredExplorerTransactionExtensions[v] = ext
+}
+
+// ExplorerTransactionAddresses returns the unique addresses linked to a transaction through its extension data,
+// being the addresses of the conditions defined in its common extension data,
+// as well as the addresses returned by the explorer extension registered for its version, if any.
+// The addresses of the common extension data are returned, even if an error is returned.
+func ExplorerTransactionAddresses(txn types.Transaction) ([]types.UnlockHash, error) {
+	var addresses []types.UnlockHash
+	seen := make(map[types.UnlockHash]struct{})
+	add := func(uh types.UnlockHash) {
+		if _, ok := seen[uh]; ok {
+			return
+		}
+		seen[uh] = struct{}{}
+		addresses = append(addresses, uh)
+	}
+	exData, _ := txn.CommonExtensionData()
+	for _, condition := range exData.UnlockConditions {
+		add(condition.UnlockHash())
+	}
+	if getter, ok := _RegisteredExplorerTransactionExtensions[txn.Version].(ExplorerTransactionAddressesGetter); ok {
+		uhs, err := getter.TransactionAddresses(txn)
+		if err != nil {
+			return addresses, fmt.Errorf("failed to get extension addresses of transaction %s: %v", txn.ID().String(), err)
+		}
+		for _, uh := range uhs {
+			add(uh)
+		}
+	}
+	return addresses, nil
+}
+
+// NewExplorerTransactionExtensionView creates the view on the extension data of a transaction,
+// using the explorer extension registered for its version. Nil is returned for transactions
+// without extension data or addresses linked to it, for which no explorer extension is registered.
+func NewExplorerTransactionExtensionView(txn types.Transaction) (*ExplorerTransactionExtensionView, error) {
+	addresses, err := ExplorerTransactionAddresses(txn)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := _RegisteredExplorerTransactionExtensions[txn.Version]
+	if !ok {
+		if len(addresses) == 0 {
+			return nil, nil
+		}
+		return &ExplorerTransactionExtensionView{Addresses: addresses}, nil
+	}
+	data, err := ext.TransactionView(txn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s view of transaction %s: %v", ext.TransactionType(), txn.ID().String(), err)
+	}
+	return &ExplorerTransactionExtensionView{
+		Type:      ext.TransactionType(),
+		Addresses: addresses,
+		Data:      data,
+	}, nil
+}
+
+// FulfillmentAddress returns the address of the signer of the given fulfillment,
+// which is only known for single signature fulfillments.
+func FulfillmentAddress(fulfillment types.UnlockFulfillmentProxy) (types.UnlockHash, bool) {
+	ssf, ok := fulfillment.Fulfillment.(*types.SingleSignatureFulfillment)
+	if !ok {
+		return types.UnlockHash{}, false
+	}
+	uh, err := types.NewPubKeyUnlockHash(ssf.PublicKey)
+	if err != nil {
+		return types.UnlockHash{}, false
+	}
+	return uh, true
+}
@@ -0,0 +1,75 @@
+package modules
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type testExplorerTransactionExtension struct {
+	addresses []types.UnlockHash
+}
+
+func (testExplorerTransactionExtension) TransactionType() string { return "test" }
+func (testExplorerTransactionExtension) TransactionView(txn types.Transaction) (interface{}, error) {
+	return string(txn.ArbitraryData), nil
+}
+func (ext testExplorerTransactionExtension) TransactionAddresses(types.Transaction) ([]types.UnlockHash, error) {
+	return ext.addresses, nil
+}
+
+func TestExplorerTransactionExtensionView(t *testing.T) {
+	const version = types.TransactionVersion(0xf0)
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	txn := types.Transaction{
+		Version:       version,
+		ArbitraryData: []byte("foo"),
+	}
+
+	// no view is returned for unregistered versions without linked addresses
+	view, err := NewExplorerTransactionExtensionView(txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view != nil {
+		t.Errorf("expected no view for an unregistered version, got: %v", view)
+	}
+
+	RegisterExplorerTransactionExtension(version, testExplorerTransactionExtension{
+		addresses: []types.UnlockHash{alice, alice},
+	})
+	defer RegisterExplorerTransactionExtension(version, nil)
+	view, err = NewExplorerTransactionExtensionView(txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view == nil {
+		t.Fatal("expected a view for a registered version")
+	}
+	if view.Type != "test" || view.Data != "foo" {
+		t.Errorf("unexpected view: %v", *view)
+	}
+	if len(view.Addresses) != 1 || view.Addresses[0] != alice {
+		t.Errorf("unexpected (or duplicate) view addresses: %v", view.Addresses)
+	}
+}
+
+func TestFulfillmentAddress(t *testing.T) {
+	pk := types.Ed25519PublicKey(crypto.PublicKey{1})
+	uh, ok := FulfillmentAddress(types.NewFulfillment(&types.SingleSignatureFulfillment{PublicKey: pk}))
+	if !ok {
+		t.Fatal("expected the address of a single signature fulfillment to be known")
+	}
+	expected, err := types.NewPubKeyUnlockHash(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uh != expected {
+		t.Errorf("unexpected fulfillment address: %s != %s", uh.String(), expected.String())
+	}
+	_, ok = FulfillmentAddress(types.NewFulfillment(&types.NilFulfillment{}))
+	if ok {
+		t.Error("expected the address of a nil fulfillment to be unknown")
+	}
+}
@@ -0,0 +1,20 @@
+package gateway
+
+import (
+	"github.com/threefoldtech/rivine/pkg/metrics"
+)
+
+// RegisterMetrics implements metrics.Source.RegisterMetrics,
+// registering all collectors of the gateway.
+func (g *Gateway) RegisterMetrics(reg *metrics.Registry) error {
+	return reg.Register(metrics.NewGaugeFunc(metrics.Opts{
+		Namespace: metrics.Namespace,
+		Subsystem: "gateway",
+		Name:      "peers",
+		Help:      "Amount of peers the gateway is connected to.",
+	}, func() float64 {
+		g.mu.RLock()
+		defer g.mu.RUnlock()
+		return float64(len(g.peers))
+	}))
+}
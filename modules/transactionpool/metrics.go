@@ -0,0 +1,38 @@
+package transactionpool
+
+import (
+	"github.com/threefoldtech/rivine/pkg/metrics"
+)
+
+const metricsSubsystem = "transactionpool"
+
+// RegisterMetrics implements metrics.Source.RegisterMetrics,
+// registering all collectors of the transaction pool.
+func (tp *TransactionPool) RegisterMetrics(reg *metrics.Registry) error {
+	return reg.RegisterAll(
+		metrics.NewGaugeFunc(metrics.Opts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "transactions",
+			Help:      "Amount of unconfirmed transactions in the transaction pool.",
+		}, func() float64 {
+			tp.mu.RLock()
+			defer tp.mu.RUnlock()
+			var n int
+			for _, tSet := range tp.transactionSets {
+				n += len(tSet.Transactions)
+			}
+			return float64(n)
+		}),
+		metrics.NewGaugeFunc(metrics.Opts{
+			Namespace: metrics.Namespace,
+			Subsystem: metricsSubsystem,
+			Name:      "bytes",
+			Help:      "Encoded size in bytes of all transactions in the transaction pool.",
+		}, func() float64 {
+			tp.mu.RLock()
+			defer tp.mu.RUnlock()
+			return float64(tp.transactionListSize)
+		}),
+	)
+}
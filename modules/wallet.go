@@ -288,6 +288,11 @@ type (
 		// primary seed.
 		NextAddress() (types.UnlockHash, error)
 
+		// NextSecp256k1Address returns a new coin address, linked to a secp256k1 key,
+		// generated from the primary seed. Such an address can only be used on chains
+		// which enabled the secp256k1 signature algorithm.
+		NextSecp256k1Address() (types.UnlockHash, error)
+
 		// CreateBackup will create a backup of the wallet at the provided
 		// filepath. The backup will have all seeds and keys.
 		CreateBackup(string) error
@@ -426,6 +431,11 @@ type (
 		// GreedySign attempts to sign every input which can be signed by the keys loaded
 		// in this wallet.
 		GreedySign(types.Transaction) (types.Transaction, error)
+
+		// SignPST adds a signature to every input of the given partially signed transaction
+		// which can be signed by the keys loaded in this wallet, returning the amount of signatures added.
+		// As the PST contains the spent outputs, the consensus set is not used.
+		SignPST(*types.PartiallySignedTransaction) (int, error)
 	}
 )
 
@@ -0,0 +1,410 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// ErrExternalSignerRejected is returned when a sign request
+	// was rejected by the user of the external signer.
+	ErrExternalSignerRejected = errors.New("sign request was rejected by the external signer")
+
+	// ErrExternalSignRequestMismatch is returned by an external signer in case the hash of a sign request
+	// does not match the hash it computed from the transaction and extra objects of that request.
+	ErrExternalSignRequestMismatch = errors.New("hash of sign request does not match its transaction")
+
+	errExternalSignerNoKeys = errors.New("external signer has no public keys")
+)
+
+type (
+	// ExternalSigner is a signer which holds its private keys itself,
+	// such as a hardware wallet, and only ever returns signatures.
+	// It allows transactions to be signed without a seed
+	// being present on the machine which creates the transactions.
+	ExternalSigner interface {
+		// PublicKeys returns the public keys of which the external signer
+		// holds the private keys.
+		PublicKeys() ([]types.PublicKey, error)
+		// Sign signs the hash of the request using the private key matching
+		// the public key of the request. A signer is expected to verify that the hash
+		// matches the transaction of the request, returning ErrExternalSignRequestMismatch if not,
+		// and to show the summary of that transaction to its user,
+		// returning ErrExternalSignerRejected in case the user rejects the request.
+		Sign(req ExternalSignRequest) (types.ByteSlice, error)
+	}
+
+	// ExternalSignRequest is a request to an ExternalSigner,
+	// to sign a signature hash of a transaction. The transaction and the extra objects
+	// the hash is computed from are part of the request, such that the signer
+	// does not have to trust the given hash, nor a summary made on its behalf.
+	ExternalSignRequest struct {
+		PublicKey    types.PublicKey     `json:"publickey"`
+		Hash         crypto.Hash         `json:"hash"`
+		Transaction  types.Transaction   `json:"transaction"`
+		ExtraObjects ExternalSignObjects `json:"extraobjects"`
+	}
+
+	// ExternalSignObjects are the extra objects hashed together with the transaction
+	// of an ExternalSignRequest. They are JSON-encoded as a list of typed values,
+	// supporting the types of extra objects used by the standard fulfillments.
+	ExternalSignObjects []interface{}
+
+	// externalSignObject is the JSON encoding of a single extra object.
+	externalSignObject struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	// TransactionSummary is a human-readable summary of a transaction,
+	// created by an external signer from an ExternalSignRequest,
+	// such that the user of the external signer knows what is being signed.
+	TransactionSummary struct {
+		Version           types.TransactionVersion   `json:"version"`
+		CoinInputs        int                        `json:"coininputs"`
+		BlockStakeInputs  int                        `json:"blockstakeinputs"`
+		CoinOutputs       []TransactionSummaryOutput `json:"coinoutputs"`
+		BlockStakeOutputs []TransactionSummaryOutput `json:"blockstakeoutputs"`
+		MinerFees         types.Currency             `json:"minerfees"`
+		ArbitraryDataSize int                        `json:"arbitrarydatasize"`
+	}
+
+	// TransactionSummaryOutput summarizes a coin or block stake output
+	// as the address it is sent to and its value.
+	TransactionSummaryOutput struct {
+		Address types.UnlockHash `json:"address"`
+		Value   types.Currency   `json:"value"`
+	}
+
+	// OutputGetter is used to look up the parent outputs of the inputs to sign,
+	// it is implemented by the consensus set, and can be implemented using the
+	// HTTP API of a daemon in case the external signer is connected to a client.
+	OutputGetter interface {
+		GetCoinOutput(types.CoinOutputID) (types.CoinOutput, error)
+		GetBlockStakeOutput(types.BlockStakeOutputID) (types.BlockStakeOutput, error)
+	}
+)
+
+// The types of extra objects supported by an ExternalSignRequest.
+const (
+	externalSignObjectTypeUint64           = "uint64"
+	externalSignObjectTypeSpecifier        = "specifier"
+	externalSignObjectTypePublicKey        = "publickey"
+	externalSignObjectTypeAtomicSwapSecret = "atomicswapsecret"
+)
+
+// Verify recomputes the signature hash from the transaction and extra objects of the request,
+// returning ErrExternalSignRequestMismatch in case it does not equal the hash of the request.
+func (req ExternalSignRequest) Verify() error {
+	hash, err := req.Transaction.SignatureHash(req.ExtraObjects...)
+	if err != nil {
+		return fmt.Errorf("failed to compute the signature hash of the sign request: %v", err)
+	}
+	if hash != req.Hash {
+		return ErrExternalSignRequestMismatch
+	}
+	return nil
+}
+
+// Summary returns a human-readable summary of the transaction of the request.
+func (req ExternalSignRequest) Summary() TransactionSummary {
+	return NewTransactionSummary(req.Transaction)
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (objects ExternalSignObjects) MarshalJSON() ([]byte, error) {
+	encoded := make([]externalSignObject, 0, len(objects))
+	for idx, object := range objects {
+		var objectType string
+		switch object.(type) {
+		case uint64:
+			objectType = externalSignObjectTypeUint64
+		case types.Specifier:
+			objectType = externalSignObjectTypeSpecifier
+		case types.PublicKey:
+			objectType = externalSignObjectTypePublicKey
+		case types.AtomicSwapSecret:
+			objectType = externalSignObjectTypeAtomicSwapSecret
+		default:
+			return nil, fmt.Errorf("extra object #%d has unsupported type %T", idx, object)
+		}
+		value, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, externalSignObject{Type: objectType, Value: value})
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (objects *ExternalSignObjects) UnmarshalJSON(b []byte) error {
+	var encoded []externalSignObject
+	err := json.Unmarshal(b, &encoded)
+	if err != nil {
+		return err
+	}
+	decoded := make(ExternalSignObjects, 0, len(encoded))
+	for idx, object := range encoded {
+		var value interface{}
+		switch object.Type {
+		case externalSignObjectTypeUint64:
+			value = new(uint64)
+		case externalSignObjectTypeSpecifier:
+			value = new(types.Specifier)
+		case externalSignObjectTypePublicKey:
+			value = new(types.PublicKey)
+		case externalSignObjectTypeAtomicSwapSecret:
+			value = new(types.AtomicSwapSecret)
+		default:
+			return fmt.Errorf("extra object #%d has unknown type %q", idx, object.Type)
+		}
+		err = json.Unmarshal(object.Value, value)
+		if err != nil {
+			return fmt.Errorf("failed to decode extra object #%d: %v", idx, err)
+		}
+		// extra objects are hashed by value
+		decoded = append(decoded, reflect.ValueOf(value).Elem().Interface())
+	}
+	*objects = decoded
+	return nil
+}
+
+// NewTransactionSummary creates a human-readable summary of the given transaction.
+func NewTransactionSummary(txn types.Transaction) TransactionSummary {
+	summary := TransactionSummary{
+		Version:           txn.Version,
+		CoinInputs:        len(txn.CoinInputs),
+		BlockStakeInputs:  len(txn.BlockStakeInputs),
+		CoinOutputs:       make([]TransactionSummaryOutput, 0, len(txn.CoinOutputs)),
+		BlockStakeOutputs: make([]TransactionSummaryOutput, 0, len(txn.BlockStakeOutputs)),
+		ArbitraryDataSize: len(txn.ArbitraryData),
+	}
+	for _, co := range txn.CoinOutputs {
+		summary.CoinOutputs = append(summary.CoinOutputs, TransactionSummaryOutput{
+			Address: co.Condition.UnlockHash(),
+			Value:   co.Value,
+		})
+	}
+	for _, bso := range txn.BlockStakeOutputs {
+		summary.BlockStakeOutputs = append(summary.BlockStakeOutputs, TransactionSummaryOutput{
+			Address: bso.Condition.UnlockHash(),
+			Value:   bso.Value,
+		})
+	}
+	for _, fee := range txn.MinerFees {
+		summary.MinerFees = summary.MinerFees.Add(fee)
+	}
+	return summary
+}
+
+// String returns the summary as multiple lines of text,
+// fit to be shown to the user of an external signer.
+func (summary TransactionSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "transaction v%d spending %d coin and %d block stake inputs\n",
+		summary.Version, summary.CoinInputs, summary.BlockStakeInputs)
+	for _, co := range summary.CoinOutputs {
+		fmt.Fprintf(&b, "send %s coins to %s\n", co.Value.String(), co.Address.String())
+	}
+	for _, bso := range summary.BlockStakeOutputs {
+		fmt.Fprintf(&b, "send %s block stakes to %s\n", bso.Value.String(), bso.Address.String())
+	}
+	fmt.Fprintf(&b, "miner fees: %s", summary.MinerFees.String())
+	if summary.ArbitraryDataSize > 0 {
+		fmt.Fprintf(&b, "\narbitrary data: %d bytes", summary.ArbitraryDataSize)
+	}
+	return b.String()
+}
+
+// ExternalSign signs all inputs of the given transaction which can be signed
+// using the keys held by the given external signer, returning the amount of signatures created.
+// The parent outputs of the inputs are looked up using the given output getter,
+// as the wallet knows none of the addresses of the external signer.
+//
+// The transaction is modified in place, and is expected to be complete,
+// as all signatures cover the entire transaction.
+func ExternalSign(txn *types.Transaction, outputs OutputGetter, signer ExternalSigner) (int, error) {
+	pks, err := signer.PublicKeys()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the public keys of the external signer: %v", err)
+	}
+	if len(pks) == 0 {
+		return 0, errExternalSignerNoKeys
+	}
+	ctx := &externalSignContext{
+		txn:        txn,
+		keys:       make(map[types.UnlockHash]types.PublicKey, len(pks)),
+		hashSigner: externalHashSigner{signer: signer},
+		defaultKey: pks[0],
+	}
+	for _, pk := range pks {
+		uh, err := types.NewPubKeyUnlockHash(pk)
+		if err != nil {
+			return 0, fmt.Errorf("invalid public key %s of external signer: %v", pk.String(), err)
+		}
+		ctx.keys[uh] = pk
+	}
+
+	// sign all coin inputs
+	for i := range txn.CoinInputs {
+		ci := &txn.CoinInputs[i]
+		co, err := outputs.GetCoinOutput(ci.ParentID)
+		if err != nil {
+			return ctx.signed, fmt.Errorf("failed to get parent coin output %s: %v", ci.ParentID.String(), err)
+		}
+		err = ctx.signFulfillment(&ci.Fulfillment, co.Condition.Condition, uint64(i))
+		if err != nil {
+			return ctx.signed, err
+		}
+	}
+
+	// sign all block stake inputs
+	for i := range txn.BlockStakeInputs {
+		bsi := &txn.BlockStakeInputs[i]
+		bso, err := outputs.GetBlockStakeOutput(bsi.ParentID)
+		if err != nil {
+			return ctx.signed, fmt.Errorf("failed to get parent block stake output %s: %v", bsi.ParentID.String(), err)
+		}
+		err = ctx.signFulfillment(&bsi.Fulfillment, bso.Condition.Condition, uint64(i))
+		if err != nil {
+			return ctx.signed, err
+		}
+	}
+
+	// sign the extension if required
+	err = txn.SignExtension(func(fulfillment *types.UnlockFulfillmentProxy, condition types.UnlockConditionProxy, extraObjects ...interface{}) error {
+		if fulfillment == nil {
+			return errors.New("failed to sign extension: nil fulfillment proxy cannot be signed")
+		}
+		if condition.ConditionType() == types.ConditionTypeNil {
+			return ctx.signFulfillment(fulfillment, &types.NilCondition{}, extraObjects...)
+		}
+		return ctx.signFulfillment(fulfillment, condition.Condition, extraObjects...)
+	})
+	if err != nil {
+		return ctx.signed, fmt.Errorf("failed to sign extension, using tx-defined logic: %v", err)
+	}
+	return ctx.signed, nil
+}
+
+// ExternalSignPST adds a signature to every input of the given partially signed transaction
+// which can be signed using the keys held by the given external signer,
+// returning the amount of signatures added.
+func ExternalSignPST(pst *types.PartiallySignedTransaction, signer ExternalSigner) (int, error) {
+	pks, err := signer.PublicKeys()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the public keys of the external signer: %v", err)
+	}
+	keys := make(map[types.UnlockHash]types.PublicKey, len(pks))
+	for _, pk := range pks {
+		uh, err := types.NewPubKeyUnlockHash(pk)
+		if err != nil {
+			return 0, fmt.Errorf("invalid public key %s of external signer: %v", pk.String(), err)
+		}
+		keys[uh] = pk
+	}
+	hashSigner := externalHashSigner{signer: signer}
+	return pst.Sign(func(uh types.UnlockHash) (types.PublicKey, interface{}, bool) {
+		pk, ok := keys[uh]
+		return pk, hashSigner, ok
+	})
+}
+
+// externalSignContext is the state used to sign a single transaction with an external signer.
+type externalSignContext struct {
+	txn        *types.Transaction
+	keys       map[types.UnlockHash]types.PublicKey
+	hashSigner externalHashSigner
+	defaultKey types.PublicKey
+	signed     int
+}
+
+// signFulfillment signs the given fulfillment in the same way as the transaction builder does,
+// using the keys of the external signer instead of the keys of the wallet.
+func (ctx *externalSignContext) signFulfillment(fulfillment *types.UnlockFulfillmentProxy, cond types.MarshalableUnlockCondition, extraObjects ...interface{}) error {
+	switch uh := cond.UnlockHash(); uh.Type {
+	case types.UnlockTypeNil:
+		fulfillment.Fulfillment = types.NewSingleSignatureFulfillment(ctx.defaultKey)
+		err := fulfillment.Fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: extraObjects,
+			Transaction:  *ctx.txn,
+			Key:          ctx.hashSigner,
+		})
+		if err != nil {
+			return err
+		}
+		ctx.signed++
+
+	case types.UnlockTypePubKey:
+		if pk, exists := ctx.keys[uh]; exists {
+			fulfillment.Fulfillment = types.NewSingleSignatureFulfillment(pk)
+			err := fulfillment.Fulfillment.Sign(types.FulfillmentSignContext{
+				ExtraObjects: extraObjects,
+				Transaction:  *ctx.txn,
+				Key:          ctx.hashSigner,
+			})
+			if err != nil {
+				return err
+			}
+			ctx.signed++
+		}
+
+	case types.UnlockTypeMultiSig:
+		uhs, _ := getMultisigConditionProperties(cond)
+		if len(uhs) == 0 {
+			return fmt.Errorf("unexpected condition type %T for multi sig condition", cond)
+		}
+		if fulfillment.FulfillmentType() == types.FulfillmentTypeNil {
+			fulfillment.Fulfillment = &types.MultiSignatureFulfillment{}
+		}
+		for _, uh := range uhs {
+			if pk, exists := ctx.keys[uh]; exists {
+				err := fulfillment.Sign(types.FulfillmentSignContext{
+					ExtraObjects: extraObjects,
+					Transaction:  *ctx.txn,
+					Key: types.ExternalKeyPair{
+						PublicKey: pk,
+						Signer:    ctx.hashSigner,
+					},
+				})
+				if err != nil {
+					return err
+				}
+				ctx.signed++
+			}
+		}
+
+	default:
+		// conditions which wrap another condition, under an unlock hash of their own,
+		// are signed as the wrapped condition
+		if getter, ok := cond.(types.MarshalableUnlockConditionGetter); ok {
+			return ctx.signFulfillment(fulfillment, getter.GetMarshalableUnlockCondition(), extraObjects...)
+		}
+		return fmt.Errorf("failed to sign fulfillment: unexpected condition type %T", cond)
+	}
+
+	return nil
+}
+
+// externalHashSigner implements types.HashSigner,
+// turning each signature hash into a request for the external signer.
+type externalHashSigner struct {
+	signer ExternalSigner
+}
+
+// SignHash implements types.HashSigner.SignHash
+func (hs externalHashSigner) SignHash(pk types.PublicKey, hash crypto.Hash, txn types.Transaction, extraObjects []interface{}) (types.ByteSlice, error) {
+	return hs.signer.Sign(ExternalSignRequest{
+		PublicKey:    pk,
+		Hash:         hash,
+		Transaction:  txn,
+		ExtraObjects: extraObjects,
+	})
+}
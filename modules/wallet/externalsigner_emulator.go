@@ -0,0 +1,67 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ExternalSignerEmulator is a software ExternalSigner emulating a hardware wallet,
+// using the keys derived from a seed in the same way as the wallet does.
+// It is meant for testing only, as it holds the seed in memory.
+type ExternalSignerEmulator struct {
+	keys []spendableKey
+
+	// Approve is called for each (verified) sign request, emulating the user
+	// approving (or rejecting) the transaction summary shown by the device.
+	// All requests are approved in case Approve is nil.
+	Approve func(req ExternalSignRequest) bool
+}
+
+// NewExternalSignerEmulator creates an ExternalSignerEmulator,
+// holding the first keyCount keys of the given seed.
+func NewExternalSignerEmulator(seed modules.Seed, keyCount uint64) (*ExternalSignerEmulator, error) {
+	e := &ExternalSignerEmulator{keys: make([]spendableKey, 0, keyCount)}
+	for index := uint64(0); index < keyCount; index++ {
+		key, err := generateSpendableKey(seed, index)
+		if err != nil {
+			return nil, err
+		}
+		e.keys = append(e.keys, key)
+	}
+	return e, nil
+}
+
+// PublicKeys implements ExternalSigner.PublicKeys
+func (e *ExternalSignerEmulator) PublicKeys() ([]types.PublicKey, error) {
+	pks := make([]types.PublicKey, 0, len(e.keys))
+	for _, key := range e.keys {
+		pks = append(pks, key.PublicKey)
+	}
+	return pks, nil
+}
+
+// Sign implements ExternalSigner.Sign
+func (e *ExternalSignerEmulator) Sign(req ExternalSignRequest) (types.ByteSlice, error) {
+	for _, key := range e.keys {
+		if key.PublicKey.Algorithm != req.PublicKey.Algorithm || !bytes.Equal(key.PublicKey.Key, req.PublicKey.Key) {
+			continue
+		}
+		// never trust the given hash, as it might not be the hash of the transaction shown to the user
+		err := req.Verify()
+		if err != nil {
+			return nil, err
+		}
+		if e.Approve != nil && !e.Approve(req) {
+			return nil, ErrExternalSignerRejected
+		}
+		algorithm, err := types.GetSignatureAlgorithm(key.PublicKey.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return algorithm.SignHash(req.Hash, key.SecretKey)
+	}
+	return nil, fmt.Errorf("unknown public key %s", req.PublicKey.String())
+}
@@ -0,0 +1,292 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// testOutputGetter implements OutputGetter using in-memory outputs.
+type testOutputGetter struct {
+	coinOutputs       map[types.CoinOutputID]types.CoinOutput
+	blockStakeOutputs map[types.BlockStakeOutputID]types.BlockStakeOutput
+}
+
+func (og testOutputGetter) GetCoinOutput(id types.CoinOutputID) (types.CoinOutput, error) {
+	co, ok := og.coinOutputs[id]
+	if !ok {
+		return types.CoinOutput{}, errors.New("coin output not found")
+	}
+	return co, nil
+}
+
+func (og testOutputGetter) GetBlockStakeOutput(id types.BlockStakeOutputID) (types.BlockStakeOutput, error) {
+	bso, ok := og.blockStakeOutputs[id]
+	if !ok {
+		return types.BlockStakeOutput{}, errors.New("block stake output not found")
+	}
+	return bso, nil
+}
+
+// newTestExternalSigner creates an emulated external signer,
+// connected to a client over an in-memory connection.
+func newTestExternalSigner(t *testing.T, keyCount uint64) (*ExternalSignerEmulator, *ExternalSignerClient) {
+	var seed modules.Seed
+	_, err := rand.Read(seed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	emulator, err := NewExternalSignerEmulator(seed, keyCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConn, deviceConn := net.Pipe()
+	go ServeExternalSigner(deviceConn, emulator)
+	return emulator, NewExternalSignerClient(clientConn)
+}
+
+func TestExternalSign(t *testing.T) {
+	emulator, client := newTestExternalSigner(t, 2)
+	defer client.Close()
+
+	pks, err := client.PublicKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pks) != 2 {
+		t.Fatalf("unexpected amount of public keys: %d", len(pks))
+	}
+	uhs := make([]types.UnlockHash, 0, len(pks))
+	for _, pk := range pks {
+		uh, err := types.NewPubKeyUnlockHash(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uhs = append(uhs, uh)
+	}
+	foreignUH := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+
+	outputs := testOutputGetter{
+		coinOutputs: map[types.CoinOutputID]types.CoinOutput{
+			{1}: {
+				Value:     types.NewCurrency64(10),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(uhs[0])),
+			},
+			{2}: {
+				Value:     types.NewCurrency64(20),
+				Condition: types.NewCondition(types.NewMultiSignatureCondition(types.UnlockHashSlice{uhs[1], foreignUH}, 1)),
+			},
+			{3}: {
+				Value:     types.NewCurrency64(30),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(foreignUH)),
+			},
+		},
+		blockStakeOutputs: map[types.BlockStakeOutputID]types.BlockStakeOutput{
+			{4}: {
+				Value:     types.NewCurrency64(1),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(uhs[1])),
+			},
+		},
+	}
+	txn := types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{
+			{ParentID: types.CoinOutputID{1}},
+			{ParentID: types.CoinOutputID{2}},
+			{ParentID: types.CoinOutputID{3}},
+		},
+		CoinOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(59),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(foreignUH)),
+		}},
+		BlockStakeInputs: []types.BlockStakeInput{
+			{ParentID: types.BlockStakeOutputID{4}},
+		},
+		BlockStakeOutputs: []types.BlockStakeOutput{{
+			Value:     types.NewCurrency64(1),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(foreignUH)),
+		}},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+
+	var requests []ExternalSignRequest
+	emulator.Approve = func(req ExternalSignRequest) bool {
+		requests = append(requests, req)
+		return true
+	}
+	signed, err := ExternalSign(&txn, outputs, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed != 3 || len(requests) != 3 {
+		t.Fatalf("unexpected amount of signatures: %d (%d requests)", signed, len(requests))
+	}
+	summary := NewTransactionSummary(txn)
+	if requests[0].Summary().String() != summary.String() {
+		t.Errorf("unexpected summary:\n%s\n!=\n%s", requests[0].Summary().String(), summary.String())
+	}
+	// the multisig input hashes the public key as an extra object
+	if len(requests[1].ExtraObjects) != 2 {
+		t.Errorf("unexpected extra objects of multisig sign request: %v", requests[1].ExtraObjects)
+	}
+
+	// the inputs of the external signer should be fulfilled, while the foreign input should be left untouched
+	for idx, ci := range txn.CoinInputs[:2] {
+		co := outputs.coinOutputs[ci.ParentID]
+		err = co.Condition.Fulfill(ci.Fulfillment, types.FulfillContext{
+			ExtraObjects: []interface{}{uint64(idx)},
+			Transaction:  txn,
+		})
+		if err != nil {
+			t.Errorf("coin input #%d is not fulfilled: %v", idx, err)
+		}
+	}
+	if ft := txn.CoinInputs[2].Fulfillment.FulfillmentType(); ft != types.FulfillmentTypeNil {
+		t.Errorf("foreign coin input should not be signed, but has fulfillment type %d", ft)
+	}
+	bso := outputs.blockStakeOutputs[txn.BlockStakeInputs[0].ParentID]
+	err = bso.Condition.Fulfill(txn.BlockStakeInputs[0].Fulfillment, types.FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  txn,
+	})
+	if err != nil {
+		t.Errorf("block stake input is not fulfilled: %v", err)
+	}
+}
+
+func TestExternalSignRejected(t *testing.T) {
+	emulator, client := newTestExternalSigner(t, 1)
+	defer client.Close()
+	emulator.Approve = func(ExternalSignRequest) bool { return false }
+
+	pks, err := client.PublicKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uh, err := types.NewPubKeyUnlockHash(pks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := testOutputGetter{
+		coinOutputs: map[types.CoinOutputID]types.CoinOutput{
+			{1}: {
+				Value:     types.NewCurrency64(10),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(uh)),
+			},
+		},
+	}
+	txn := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		MinerFees:  []types.Currency{types.NewCurrency64(10)},
+	}
+	_, err = ExternalSign(&txn, outputs, client)
+	if err != ErrExternalSignerRejected {
+		t.Errorf("expected rejection, but got: %v", err)
+	}
+}
+
+// invalidHashSigner returns signatures of the wrong hash.
+type invalidHashSigner struct {
+	*ExternalSignerEmulator
+}
+
+func (s invalidHashSigner) Sign(req ExternalSignRequest) (types.ByteSlice, error) {
+	req.ExtraObjects = ExternalSignObjects{uint64(42)}
+	hash, err := req.Transaction.SignatureHash(req.ExtraObjects...)
+	if err != nil {
+		return nil, err
+	}
+	req.Hash = hash
+	return s.ExternalSignerEmulator.Sign(req)
+}
+
+func TestExternalSignInvalidSignature(t *testing.T) {
+	emulator, client := newTestExternalSigner(t, 1)
+	defer client.Close()
+
+	pks, err := emulator.PublicKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uh, err := types.NewPubKeyUnlockHash(pks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := testOutputGetter{
+		coinOutputs: map[types.CoinOutputID]types.CoinOutput{
+			{1}: {
+				Value:     types.NewCurrency64(10),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(uh)),
+			},
+		},
+	}
+	txn := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		MinerFees:  []types.Currency{types.NewCurrency64(10)},
+	}
+	_, err = ExternalSign(&txn, outputs, invalidHashSigner{emulator})
+	if err == nil {
+		t.Error("signature of the wrong hash should not be accepted")
+	}
+}
+
+func TestExternalSignRequestMismatch(t *testing.T) {
+	emulator, client := newTestExternalSigner(t, 1)
+	defer client.Close()
+	var approved bool
+	emulator.Approve = func(ExternalSignRequest) bool {
+		approved = true
+		return true
+	}
+
+	pks, err := client.PublicKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: types.CoinOutputID{1}}},
+		MinerFees:  []types.Currency{types.NewCurrency64(10)},
+	}
+	hash, err := txn.SignatureHash(uint64(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := ExternalSignRequest{
+		PublicKey:    pks[0],
+		Hash:         hash,
+		Transaction:  txn,
+		ExtraObjects: ExternalSignObjects{uint64(0)},
+	}
+	if _, err = client.Sign(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// a request of which the hash does not match the summarized transaction should be rejected
+	approved = false
+	mismatch := req
+	mismatch.Transaction.MinerFees = []types.Currency{types.NewCurrency64(1)}
+	_, err = emulator.Sign(mismatch)
+	if err != ErrExternalSignRequestMismatch {
+		t.Errorf("expected mismatch error, but got: %v", err)
+	}
+	if _, err = client.Sign(mismatch); err == nil {
+		t.Error("sign request with a mismatching hash should be rejected by the served signer")
+	}
+	// as well as a request of which the hash does not match the extra objects
+	mismatch = req
+	mismatch.ExtraObjects = ExternalSignObjects{uint64(1)}
+	if _, err = emulator.Sign(mismatch); err != ErrExternalSignRequestMismatch {
+		t.Errorf("expected mismatch error, but got: %v", err)
+	}
+	if approved {
+		t.Error("mismatching sign requests should never be shown to the user")
+	}
+}
@@ -0,0 +1,182 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// The external signer protocol exchanges newline-delimited JSON messages
+// over a local transport, each request being answered by a single response.
+const (
+	externalSignerMethodPublicKeys = "publickeys"
+	externalSignerMethodSign       = "sign"
+)
+
+type (
+	// externalSignerRequest is a request send to an external signer.
+	externalSignerRequest struct {
+		Method string               `json:"method"`
+		Sign   *ExternalSignRequest `json:"sign,omitempty"`
+	}
+
+	// externalSignerResponse is the response returned by an external signer.
+	externalSignerResponse struct {
+		PublicKeys []types.PublicKey `json:"publickeys,omitempty"`
+		Signature  types.ByteSlice   `json:"signature,omitempty"`
+		Rejected   bool              `json:"rejected,omitempty"`
+		Error      string            `json:"error,omitempty"`
+	}
+)
+
+// ExternalSignerClient is an ExternalSigner which communicates
+// with an external device over a local transport.
+type ExternalSignerClient struct {
+	conn    io.ReadWriteCloser
+	encoder *json.Encoder
+	decoder *json.Decoder
+	mu      sync.Mutex
+}
+
+// NewExternalSignerClient creates an ExternalSignerClient,
+// communicating with an external signer over the given connection.
+func NewExternalSignerClient(conn io.ReadWriteCloser) *ExternalSignerClient {
+	return &ExternalSignerClient{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}
+}
+
+// DialExternalSigner connects to an external signer over a local transport.
+// The address is either "unix:<path>" for a unix socket, "tcp:<host:port>"
+// for a loopback TCP address, or the path of a (serial) device file.
+//
+// Only local transports are supported, as the sign requests (including their transactions)
+// are not encrypted, nor is the external signer authenticated.
+func DialExternalSigner(address string) (*ExternalSignerClient, error) {
+	switch {
+	case strings.HasPrefix(address, "unix:"):
+		conn, err := net.Dial("unix", strings.TrimPrefix(address, "unix:"))
+		if err != nil {
+			return nil, err
+		}
+		return NewExternalSignerClient(conn), nil
+
+	case strings.HasPrefix(address, "tcp:"):
+		hostport := strings.TrimPrefix(address, "tcp:")
+		host, _, err := net.SplitHostPort(hostport)
+		if err != nil {
+			return nil, err
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return nil, fmt.Errorf("external signer address %s is not a loopback address", hostport)
+		}
+		conn, err := net.Dial("tcp", hostport)
+		if err != nil {
+			return nil, err
+		}
+		return NewExternalSignerClient(conn), nil
+
+	default:
+		device, err := os.OpenFile(address, os.O_RDWR, 0)
+		if err != nil {
+			return nil, err
+		}
+		return NewExternalSignerClient(device), nil
+	}
+}
+
+// PublicKeys implements ExternalSigner.PublicKeys
+func (c *ExternalSignerClient) PublicKeys() ([]types.PublicKey, error) {
+	resp, err := c.call(externalSignerRequest{Method: externalSignerMethodPublicKeys})
+	if err != nil {
+		return nil, err
+	}
+	return resp.PublicKeys, nil
+}
+
+// Sign implements ExternalSigner.Sign
+func (c *ExternalSignerClient) Sign(req ExternalSignRequest) (types.ByteSlice, error) {
+	resp, err := c.call(externalSignerRequest{Method: externalSignerMethodSign, Sign: &req})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Signature) == 0 {
+		return nil, errors.New("external signer returned no signature")
+	}
+	return resp.Signature, nil
+}
+
+// Close closes the connection to the external signer.
+func (c *ExternalSignerClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *ExternalSignerClient) call(req externalSignerRequest) (externalSignerResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.encoder.Encode(req)
+	if err != nil {
+		return externalSignerResponse{}, fmt.Errorf("failed to send %s request to external signer: %v", req.Method, err)
+	}
+	var resp externalSignerResponse
+	err = c.decoder.Decode(&resp)
+	if err != nil {
+		return externalSignerResponse{}, fmt.Errorf("failed to receive %s response from external signer: %v", req.Method, err)
+	}
+	if resp.Rejected {
+		return externalSignerResponse{}, ErrExternalSignerRejected
+	}
+	if resp.Error != "" {
+		return externalSignerResponse{}, errors.New("external signer: " + resp.Error)
+	}
+	return resp, nil
+}
+
+// ServeExternalSigner serves the requests received over the given connection,
+// using the given signer, until the connection is closed.
+// It implements the device side of the external signer protocol,
+// such that any ExternalSigner can be exposed over a local transport.
+func ServeExternalSigner(conn io.ReadWriter, signer ExternalSigner) error {
+	encoder, decoder := json.NewEncoder(conn), json.NewDecoder(conn)
+	for {
+		var req externalSignerRequest
+		err := decoder.Decode(&req)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		var resp externalSignerResponse
+		switch req.Method {
+		case externalSignerMethodPublicKeys:
+			resp.PublicKeys, err = signer.PublicKeys()
+		case externalSignerMethodSign:
+			if req.Sign == nil {
+				err = errors.New("sign request is missing")
+				break
+			}
+			resp.Signature, err = signer.Sign(*req.Sign)
+		default:
+			err = fmt.Errorf("unknown method %q", req.Method)
+		}
+		if err == ErrExternalSignerRejected {
+			resp.Rejected = true
+		} else if err != nil {
+			resp.Error = err.Error()
+		}
+		err = encoder.Encode(resp)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package wallet
+
+import (
+	"math/big"
+
+	"github.com/threefoldtech/rivine/pkg/metrics"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// RegisterMetrics implements metrics.Source.RegisterMetrics,
+// registering all collectors of the wallet.
+func (w *Wallet) RegisterMetrics(reg *metrics.Registry) error {
+	return reg.Register(metrics.NewLabeledGaugeFunc(metrics.Opts{
+		Namespace: metrics.Namespace,
+		Subsystem: "wallet",
+		Name:      "balance",
+		Help:      "Confirmed balance of the wallet in the smallest unit, only reported while the wallet is unlocked.",
+	}, w.balanceSamples))
+}
+
+// balanceSamples returns the confirmed (un)locked coin and block stake balances,
+// or no samples at all in case the wallet is locked.
+func (w *Wallet) balanceSamples() []metrics.Sample {
+	unlockedCoins, unlockedBlockStakes, err := w.ConfirmedBalance()
+	if err != nil {
+		return nil
+	}
+	lockedCoins, lockedBlockStakes, err := w.ConfirmedLockedBalance()
+	if err != nil {
+		return nil
+	}
+	return []metrics.Sample{
+		balanceSample("coins", "unlocked", unlockedCoins),
+		balanceSample("coins", "locked", lockedCoins),
+		balanceSample("blockstakes", "unlocked", unlockedBlockStakes),
+		balanceSample("blockstakes", "locked", lockedBlockStakes),
+	}
+}
+
+func balanceSample(asset, state string, c types.Currency) metrics.Sample {
+	f, _ := new(big.Float).SetInt(c.Big()).Float64()
+	return metrics.Sample{
+		Labels: []metrics.Label{
+			{Name: "asset", Value: asset},
+			{Name: "state", Value: state},
+		},
+		Value: f,
+	}
+}
@@ -51,6 +51,10 @@ type persistentData struct {
 	// to be generated.
 	PrimarySeedFile     SeedFile
 	PrimarySeedProgress uint64
+	// PrimarySeedSecp256k1Progress tracks the secp256k1 keys/addresses
+	// generated from the primary seed, which are derived independently
+	// from the (default) ed25519 keys/addresses.
+	PrimarySeedSecp256k1Progress uint64
 
 	// AuxiliarySeedFiles is a set of seeds that the wallet can spend from, but is
 	// no longer using to generate addresses. The primary use case is loading
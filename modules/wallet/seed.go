@@ -45,12 +45,55 @@ func generateSpendableKey(seed modules.Seed, index uint64) (spendableKey, error)
 	}
 	sk, pk := crypto.GenerateKeyPairDeterministic(h)
 	return spendableKey{
-		PublicKey: pk,
-		SecretKey: sk,
+		PublicKey: types.Ed25519PublicKey(pk),
+		SecretKey: types.ByteSlice(sk[:]),
 		Index:     index,
 	}, nil
 }
 
+// generateSecp256k1SpendableKey creates the secp256k1 keys and unlock conditions for seed at a
+// given index. The algorithm specifier is part of the derivation,
+// such that the secp256k1 keys are unrelated to the ed25519 keys of the same seed and index.
+func generateSecp256k1SpendableKey(seed modules.Seed, index uint64) (spendableKey, error) {
+	h, err := crypto.HashAll(seed, types.SignatureAlgoSecp256k1Specifier, index)
+	if err != nil {
+		return spendableKey{}, err
+	}
+	sk, pk := crypto.GenerateSecp256k1KeyPairDeterministic(h)
+	return spendableKey{
+		PublicKey: types.Secp256k1PublicKey(pk),
+		SecretKey: types.ByteSlice(sk[:]),
+		Index:     index,
+	}, nil
+}
+
+// integrateSpendableKeys generates the spendable keys of the given seed for
+// all indices in the range [0, depth), and adds them to the wallet.
+func (w *Wallet) integrateSpendableKeys(seed modules.Seed, depth uint64, generate func(modules.Seed, uint64) (spendableKey, error)) error {
+	for i := uint64(0); i < depth; i++ {
+		spendableKey, err := generate(seed, i)
+		if err != nil {
+			return err
+		}
+		uh, err := spendableKey.UnlockHash()
+		if err != nil {
+			return err
+		}
+		w.keys[uh] = spendableKey
+	}
+	return nil
+}
+
+// integrateSecp256k1SpendableKeys generates the secp256k1 spendable keys of the given seed
+// for all indices in the range [0, depth), and adds them to the wallet.
+// No keys are generated in case the secp256k1 signature algorithm is not enabled for this chain.
+func (w *Wallet) integrateSecp256k1SpendableKeys(seed modules.Seed, depth uint64) error {
+	if !types.IsSecp256k1SignatureAlgorithmEnabled() {
+		return nil
+	}
+	return w.integrateSpendableKeys(seed, depth, generateSecp256k1SpendableKey)
+}
+
 // encryptAndSaveSeedFile encrypts and saves a seed file.
 func (w *Wallet) encryptAndSaveSeedFile(masterKey crypto.TwofishKey, seed modules.Seed) (SeedFile, error) {
 	var uid UniqueID
@@ -135,17 +178,13 @@ func loadPlainSeedFile(sf SeedFile) (modules.Seed, error) {
 // 'publicKeysPerSeed' addresses that the wallet is able to spend.
 // integrateSeed should not be called with the primary seed.
 func (w *Wallet) integrateSeed(seed modules.Seed) error {
-	for i := uint64(0); i < modules.PublicKeysPerSeed; i++ {
-		// Generate the key and check it is new to the wallet.
-		spendableKey, err := generateSpendableKey(seed, i)
-		if err != nil {
-			return err
-		}
-		uh, err := spendableKey.UnlockHash()
-		if err != nil {
-			return err
-		}
-		w.keys[uh] = spendableKey
+	err := w.integrateSpendableKeys(seed, modules.PublicKeysPerSeed, generateSpendableKey)
+	if err != nil {
+		return err
+	}
+	err = w.integrateSecp256k1SpendableKeys(seed, modules.PublicKeysPerSeed)
+	if err != nil {
+		return err
 	}
 	w.seeds = append(w.seeds, seed)
 	return nil
@@ -224,18 +263,16 @@ func (w *Wallet) createSeed(seed modules.Seed, depth uint64, fs func(modules.See
 	w.primarySeed = seed
 	w.persist.PrimarySeedFile = seedFile
 	w.persist.PrimarySeedProgress = depth - modules.WalletSeedPreloadDepth
+	w.persist.PrimarySeedSecp256k1Progress = 0
 	// The wallet preloads keys to prevent confusion for people using the same
 	// seed/wallet file in multiple places.
-	for i := uint64(0); i < depth; i++ {
-		spendableKey, err := generateSpendableKey(seed, i)
-		if err != nil {
-			return err
-		}
-		uh, err := spendableKey.UnlockHash()
-		if err != nil {
-			return err
-		}
-		w.keys[uh] = spendableKey
+	err = w.integrateSpendableKeys(seed, depth, generateSpendableKey)
+	if err != nil {
+		return err
+	}
+	err = w.integrateSecp256k1SpendableKeys(seed, modules.WalletSeedPreloadDepth)
+	if err != nil {
+		return err
 	}
 	return w.saveSettingsSync()
 }
@@ -262,16 +299,13 @@ func (w *Wallet) initPrimarySeed(sf func(SeedFile) (modules.Seed, error)) error
 	}
 	// The wallet preloads keys to prevent confusion when using the same wallet
 	// in multiple places.
-	for i := uint64(0); i < w.persist.PrimarySeedProgress+modules.WalletSeedPreloadDepth; i++ {
-		spendableKey, err := generateSpendableKey(seed, i)
-		if err != nil {
-			return err
-		}
-		uh, err := spendableKey.UnlockHash()
-		if err != nil {
-			return err
-		}
-		w.keys[uh] = spendableKey
+	err = w.integrateSpendableKeys(seed, w.persist.PrimarySeedProgress+modules.WalletSeedPreloadDepth, generateSpendableKey)
+	if err != nil {
+		return err
+	}
+	err = w.integrateSecp256k1SpendableKeys(seed, w.persist.PrimarySeedSecp256k1Progress+modules.WalletSeedPreloadDepth)
+	if err != nil {
+		return err
 	}
 	w.primarySeed = seed
 	w.seeds = append(w.seeds, seed)
@@ -338,6 +372,38 @@ func (w *Wallet) nextPrimarySeedAddress() (types.UnlockHash, error) {
 	return spendableKey.UnlockHash()
 }
 
+// nextPrimarySeedSecp256k1Address fetches the next secp256k1 address from the primary seed.
+func (w *Wallet) nextPrimarySeedSecp256k1Address() (types.UnlockHash, error) {
+	// Check that the wallet has been unlocked.
+	if !w.unlocked {
+		return types.UnlockHash{}, modules.ErrLockedWallet
+	}
+	// Check that the secp256k1 signature algorithm has been activated,
+	// as outputs sent to the address could not be spent otherwise.
+	if !types.IsSecp256k1SignatureAlgorithmActive(w.cs.Height()) {
+		return types.UnlockHash{}, types.NewClientError(types.ErrInactiveSignAlgorithmType, types.ClientErrorBadRequest)
+	}
+
+	// Integrate the next key into the wallet, and return the unlock
+	// conditions. Because the wallet preloads keys, the progress used is
+	// 'PrimarySeedSecp256k1Progress+modules.WalletSeedPreloadDepth'.
+	spendableKey, err := generateSecp256k1SpendableKey(w.primarySeed, w.persist.PrimarySeedSecp256k1Progress+modules.WalletSeedPreloadDepth)
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	uh, err := spendableKey.UnlockHash()
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	w.keys[uh] = spendableKey
+	w.persist.PrimarySeedSecp256k1Progress++
+	err = w.saveSettingsSync()
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	return uh, nil
+}
+
 // AllSeeds returns a list of all seeds known to and used by the wallet.
 func (w *Wallet) AllSeeds() ([]modules.Seed, error) {
 	w.mu.Lock()
@@ -370,6 +436,19 @@ func (w *Wallet) NextAddress() (types.UnlockHash, error) {
 	return w.nextPrimarySeedAddress()
 }
 
+// NextSecp256k1Address returns an unlock hash that is ready to receive coins or blockstakes,
+// linked to a secp256k1 key rather than an ed25519 key.
+// The address is generated using the primary address seed.
+func (w *Wallet) NextSecp256k1Address() (types.UnlockHash, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockHash{}, err
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nextPrimarySeedSecp256k1Address()
+}
+
 // LoadSeed will track all of the addresses generated by the input seed,
 // reclaiming any funds that were lost due to a deleted file or lost encryption
 // key. An error will be returned if the seed has already been integrated with
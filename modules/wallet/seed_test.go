@@ -314,3 +314,91 @@ func TestLoadSeed(t *testing.T) {
 		t.Error("AllSeeds returned the wrong seed")
 	}
 }
+
+// TestNextSecp256k1Address checks that secp256k1 addresses can be generated
+// from the primary seed once activated, and that they remain known on subsequent loads of the wallet.
+func TestNextSecp256k1Address(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	// not parallel, as the activation of the secp256k1 signature algorithm is global
+	types.EnableSecp256k1SignatureAlgorithm(1)
+	defer types.DisableSecp256k1SignatureAlgorithm()
+
+	cs := newConsensusSetStub()
+
+	wt, err := createWalletTesterWithStubCS(t.Name(), cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// no secp256k1 addresses can be generated prior to the activation height
+	_, err = wt.wallet.NextSecp256k1Address()
+	if cErr, ok := err.(types.ClientError); !ok || cErr.Err != types.ErrInactiveSignAlgorithmType {
+		t.Fatalf("expected inactive signature algorithm error, not: %v", err)
+	}
+	err = cs.addTransactionAsBlock(types.UnlockHash{Type: types.UnlockTypePubKey}, types.NewCurrency64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := wt.wallet.NextSecp256k1Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, sk, err := wt.wallet.GetKey(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk.Algorithm != types.SignatureAlgoSecp256k1 {
+		t.Errorf("unexpected public key algorithm: %v", pk.Algorithm)
+	}
+	if len(sk) != crypto.Secp256k1SecretKeySize {
+		t.Errorf("unexpected secret key size: %d", len(sk))
+	}
+	uh, err := types.NewPubKeyUnlockHash(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uh != addr {
+		t.Errorf("address %v does not match the unlock hash %v of its public key", addr, uh)
+	}
+
+	err = cs.addTransactionAsBlock(addr, types.NewCurrency64(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Equals64(1000) {
+		t.Errorf("wallet requires 1000 coins at this point, has %v", c)
+	}
+
+	// the address (and its progress) should be remembered after reloading the wallet
+	err = wt.wallet.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir), types.DefaultBlockchainInfo(), types.TestnetChainConstants(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet = w
+	err = w.Unlock(wt.walletMasterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = w.GetKey(addr); err != nil {
+		t.Errorf("secp256k1 address is no longer known after reloading the wallet: %v", err)
+	}
+	nextAddr, err := w.NextSecp256k1Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextAddr == addr {
+		t.Error("secp256k1 address was generated twice")
+	}
+}
@@ -579,7 +579,7 @@ func (tb *transactionBuilder) signFulfillment(fulfillment *types.UnlockFulfillme
 		fallthrough
 	case types.UnlockTypePubKey:
 		if key, exists := tb.wallet.keys[uh]; exists {
-			fulfillment.Fulfillment = types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(key.PublicKey))
+			fulfillment.Fulfillment = types.NewSingleSignatureFulfillment(key.PublicKey)
 			err := fulfillment.Fulfillment.Sign(types.FulfillmentSignContext{
 				ExtraObjects: extraObjects,
 				Transaction:  tb.transaction,
@@ -605,8 +605,8 @@ func (tb *transactionBuilder) signFulfillment(fulfillment *types.UnlockFulfillme
 					ExtraObjects: extraObjects,
 					Transaction:  tb.transaction,
 					Key: types.KeyPair{
-						PublicKey:  types.Ed25519PublicKey(key.PublicKey),
-						PrivateKey: types.ByteSlice(key.SecretKey[:]),
+						PublicKey:  key.PublicKey,
+						PrivateKey: key.SecretKey,
 					},
 				})
 				if err != nil {
@@ -617,6 +617,11 @@ func (tb *transactionBuilder) signFulfillment(fulfillment *types.UnlockFulfillme
 		}
 
 	default:
+		// conditions which wrap another condition, under an unlock hash of their own,
+		// are signed as the wrapped condition
+		if getter, ok := cond.(types.MarshalableUnlockConditionGetter); ok {
+			return tb.signFulfillment(fulfillment, getter.GetMarshalableUnlockCondition(), extraObjects...)
+		}
 		return fmt.Errorf("failed to sign fulfillment: unexpected condition type %T", cond)
 	}
 
@@ -263,3 +263,20 @@ func (w *Wallet) GreedySign(txn types.Transaction) (types.Transaction, error) {
 	signedTxn, _ := txnBuilder.View()
 	return signedTxn, err
 }
+
+// SignPST adds a signature to every input of the given partially signed transaction
+// which can be signed using the keys loaded in this wallet.
+func (w *Wallet) SignPST(pst *types.PartiallySignedTransaction) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.unlocked {
+		return 0, modules.ErrLockedWallet
+	}
+	return pst.Sign(func(uh types.UnlockHash) (types.PublicKey, interface{}, bool) {
+		key, ok := w.keys[uh]
+		if !ok {
+			return types.PublicKey{}, nil, false
+		}
+		return key.PublicKey, key.SecretKey, true
+	})
+}
@@ -36,9 +36,10 @@ var (
 // matched to the corresponding public keys in the unlock conditions. All
 // addresses that are to be used in 'FundSiacoins' or 'FundSiafunds' in the
 // transaction builder must conform to this form of spendable key.
+// The secret key is to be interpreted according to the algorithm of the public key.
 type spendableKey struct {
-	PublicKey crypto.PublicKey
-	SecretKey crypto.SecretKey
+	PublicKey types.PublicKey
+	SecretKey types.ByteSlice
 	Index     uint64
 }
 
@@ -48,7 +49,7 @@ func (sk spendableKey) WipeSecret() spendableKey {
 }
 
 func (sk spendableKey) UnlockHash() (types.UnlockHash, error) {
-	return types.NewEd25519PubKeyUnlockHash(sk.PublicKey)
+	return types.NewPubKeyUnlockHash(sk.PublicKey)
 }
 
 // Wallet is an object that tracks balances, creates keys and addresses,
@@ -271,7 +272,7 @@ func (w *Wallet) getKey(address types.UnlockHash) (types.PublicKey, types.ByteSl
 	if !found {
 		return types.PublicKey{}, types.ByteSlice{}, errUnknownAddress
 	}
-	return types.Ed25519PublicKey(sp.PublicKey), types.ByteSlice(sp.SecretKey[:]), nil
+	return sp.PublicKey, sp.SecretKey, nil
 }
 func (w *Wallet) keyExists(address types.UnlockHash) (bool, error) {
 	if !w.unlocked {
@@ -0,0 +1,164 @@
+package modules
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// WebhooksDir is the name of the directory that is used to store
+	// the persistent data of the webhook manager.
+	WebhooksDir = "webhooks"
+
+	// WebhookMaxAddresses is the maximum amount of addresses a single webhook can watch.
+	WebhookMaxAddresses = 1000
+
+	// WebhookSignatureHeader is the HTTP header of a webhook delivery,
+	// containing the hex-encoded HMAC-SHA256 of the request body,
+	// keyed with the secret of the webhook.
+	WebhookSignatureHeader = "X-Rivine-Signature"
+)
+
+var (
+	// ErrWebhookNotFound is returned in case a webhook is not registered
+	// with the webhook manager.
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// The types of the events delivered to a webhook.
+const (
+	// WebhookEventTypeUnconfirmed is the type of the event delivered when a transaction
+	// which sends funds to, or spends funds of, a watched address is added to the transaction pool.
+	WebhookEventTypeUnconfirmed WebhookEventType = iota + 1
+	// WebhookEventTypeConfirmed is the type of the event delivered when a transaction
+	// (or miner payout) which sends funds to, or spends funds of, a watched address
+	// has reached the amount of confirmations required by the webhook.
+	WebhookEventTypeConfirmed
+	// WebhookEventTypeReverted is the type of the event delivered when the block containing
+	// such a transaction (or miner payout) is reverted, e.g. because of a reorg.
+	WebhookEventTypeReverted
+)
+
+type (
+	// WebhookEventType defines the type of a webhook event.
+	WebhookEventType uint8
+
+	// Webhook is a callback URL registered with the webhook manager,
+	// to which the events of the watched addresses are delivered.
+	Webhook struct {
+		ID        uint64             `json:"id"`
+		URL       string             `json:"url"`
+		Addresses []types.UnlockHash `json:"addresses"`
+		// Confirmations is the amount of confirmations a transaction
+		// requires before the confirmed event is delivered.
+		Confirmations types.BlockHeight `json:"confirmations"`
+		// Secret is used to sign the deliveries, and is only returned
+		// when the webhook is registered.
+		Secret  string          `json:"secret,omitempty"`
+		Created types.Timestamp `json:"created"`
+	}
+
+	// WebhookEvent is the payload POSTed to the URL of a webhook.
+	WebhookEvent struct {
+		// ID identifies the event, and can be used to ignore events delivered more than once.
+		ID        uint64           `json:"id"`
+		WebhookID uint64           `json:"webhookid"`
+		Type      WebhookEventType `json:"type"`
+		Address   types.UnlockHash `json:"address"`
+		// TransactionID is not defined for miner payouts.
+		TransactionID *types.TransactionID `json:"transactionid,omitempty"`
+		// BlockID and Height are not defined for unconfirmed events.
+		BlockID       *types.BlockID    `json:"blockid,omitempty"`
+		Height        types.BlockHeight `json:"height,omitempty"`
+		Confirmations types.BlockHeight `json:"confirmations"`
+		// Sum of the outputs sent to and spent by the address.
+		CoinsReceived       types.Currency  `json:"coinsreceived"`
+		CoinsSpent          types.Currency  `json:"coinsspent"`
+		BlockStakesReceived types.Currency  `json:"blockstakesreceived"`
+		BlockStakesSpent    types.Currency  `json:"blockstakesspent"`
+		Timestamp           types.Timestamp `json:"timestamp"`
+	}
+
+	// WebhookDelivery is an event queued for delivery to a webhook.
+	WebhookDelivery struct {
+		Event    WebhookEvent `json:"event"`
+		Attempts uint64       `json:"attempts"`
+		// NextAttempt is the time at which the delivery is (re)tried.
+		NextAttempt types.Timestamp `json:"nextattempt"`
+		// LastError is the reason the last attempt failed, if any.
+		LastError string `json:"lasterror,omitempty"`
+	}
+
+	// WebhookManager delivers signed events to registered webhooks,
+	// when a watched address receives or spends funds, when such a transaction
+	// is confirmed, or when it is reverted. Failed deliveries are retried
+	// from a persistent queue.
+	WebhookManager interface {
+		io.Closer
+
+		// RegisterWebhook registers a new webhook for the given URL and addresses,
+		// returning it with the (generated) secret used to sign its deliveries.
+		// Zero confirmations are treated as a single confirmation.
+		RegisterWebhook(url string, addresses []types.UnlockHash, confirmations types.BlockHeight) (Webhook, error)
+
+		// Webhooks returns all registered webhooks, without their secret.
+		Webhooks() ([]Webhook, error)
+
+		// Webhook returns a registered webhook, without its secret.
+		Webhook(id uint64) (Webhook, error)
+
+		// RemoveWebhook removes a registered webhook, dropping its queued deliveries.
+		RemoveWebhook(id uint64) error
+
+		// WebhookDeliveries returns the queued deliveries of a registered webhook,
+		// in the order they will be delivered.
+		WebhookDeliveries(id uint64) ([]WebhookDelivery, error)
+	}
+)
+
+// String returns the event type as a string.
+func (et WebhookEventType) String() string {
+	switch et {
+	case WebhookEventTypeUnconfirmed:
+		return "unconfirmed"
+	case WebhookEventTypeConfirmed:
+		return "confirmed"
+	case WebhookEventTypeReverted:
+		return "reverted"
+	default:
+		return fmt.Sprintf("unknown event type %d", et)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+func (et WebhookEventType) MarshalJSON() ([]byte, error) {
+	if et < WebhookEventTypeUnconfirmed || et > WebhookEventTypeReverted {
+		return nil, fmt.Errorf("cannot marshal unknown webhook event type %d", et)
+	}
+	return []byte(`"` + et.String() + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+func (et *WebhookEventType) UnmarshalJSON(b []byte) error {
+	for candidate := WebhookEventTypeUnconfirmed; candidate <= WebhookEventTypeReverted; candidate++ {
+		if string(b) == `"`+candidate.String()+`"` {
+			*et = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown webhook event type %s", string(b))
+}
+
+// WebhookSignature returns the hex-encoded HMAC-SHA256 of the given payload,
+// keyed with the given webhook secret, as sent in the WebhookSignatureHeader of a delivery.
+func WebhookSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
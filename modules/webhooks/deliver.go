@@ -0,0 +1,235 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// deliveryTimeout is the maximum duration of a single delivery attempt.
+	deliveryTimeout = 10 * time.Second
+	// retryBaseDelay is the delay after the first failed attempt,
+	// doubled for every next failed attempt, up to maxRetryDelay.
+	retryBaseDelay = 10 * time.Second
+	maxRetryDelay  = time.Hour
+	// maxDeliveryAttempts is the amount of attempts after which a delivery is dropped,
+	// such that a webhook which stays unreachable does not grow the queue forever.
+	maxDeliveryAttempts = 20
+	// idleDelay is the delay after which the queue is checked when no deliveries are due.
+	idleDelay = time.Minute
+)
+
+// newDeliveryClient creates the HTTP client used to deliver events.
+// Redirects are not followed, such that a webhook cannot redirect
+// the signed events to another (e.g. internal) URL than the registered one.
+func newDeliveryClient() *http.Client {
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dueDelivery is a queued event which is due for delivery to its webhook.
+type dueDelivery struct {
+	url    string
+	secret string
+	event  modules.WebhookEvent
+}
+
+// threadedDeliverEvents delivers the queued events when they are due,
+// until the manager is closed.
+func (m *Manager) threadedDeliverEvents() {
+	if err := m.tg.Add(); err != nil {
+		return
+	}
+	defer m.tg.Done()
+	for {
+		delay := m.deliverEvents()
+		if delay <= 0 {
+			select {
+			case <-m.tg.StopChan():
+				return
+			default:
+				continue
+			}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-m.tg.StopChan():
+			timer.Stop()
+			return
+		case <-m.changes:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// deliverEvents attempts to deliver the first queued event of every webhook, if it is due,
+// such that the events of a webhook are delivered in order. Failed deliveries are rescheduled.
+// It returns the delay until the next delivery is due.
+func (m *Manager) deliverEvents() time.Duration {
+	m.mu.Lock()
+	now := types.CurrentTimestamp()
+	var due []dueDelivery
+	for _, delivery := range m.queueHeads() {
+		if delivery.NextAttempt > now {
+			continue
+		}
+		webhook, ok := m.webhooks[delivery.Event.WebhookID]
+		if !ok {
+			continue
+		}
+		due = append(due, dueDelivery{
+			url:    webhook.URL,
+			secret: webhook.Secret,
+			event:  delivery.Event,
+		})
+	}
+	m.mu.Unlock()
+
+	// deliver to the webhooks in parallel, without holding the lock,
+	// such that a slow webhook does not delay the others
+	results := make(map[uint64]error, len(due))
+	if len(due) != 0 {
+		var (
+			wg      sync.WaitGroup
+			errs    = make([]error, len(due))
+			stopped = m.tg.StopChan()
+		)
+		for idx := range due {
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+				errs[idx] = m.deliver(due[idx], stopped)
+			}(idx)
+		}
+		wg.Wait()
+		for idx := range due {
+			results[due[idx].event.ID] = errs[idx]
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now = types.CurrentTimestamp()
+	if len(results) != 0 {
+		queue := m.queue[:0]
+		for _, delivery := range m.queue {
+			err, attempted := results[delivery.Event.ID]
+			if !attempted {
+				queue = append(queue, delivery)
+				continue
+			}
+			if err == nil {
+				m.log.Debugf("delivered event %d to webhook %d", delivery.Event.ID, delivery.Event.WebhookID)
+				continue
+			}
+			delivery.Attempts++
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= maxDeliveryAttempts {
+				m.log.Printf("dropped event %d of webhook %d after %d failed attempts: %v",
+					delivery.Event.ID, delivery.Event.WebhookID, delivery.Attempts, err)
+				continue
+			}
+			delivery.NextAttempt = now + types.Timestamp(retryDelay(delivery.Attempts)/time.Second)
+			m.log.Debugf("failed to deliver event %d to webhook %d (attempt %d): %v",
+				delivery.Event.ID, delivery.Event.WebhookID, delivery.Attempts, err)
+			queue = append(queue, delivery)
+		}
+		m.queue = queue
+		m.saveSync()
+	}
+
+	delay := idleDelay
+	for _, delivery := range m.queueHeads() {
+		if delivery.NextAttempt <= now {
+			return 0
+		}
+		if d := time.Duration(delivery.NextAttempt-now) * time.Second; d < delay {
+			delay = d
+		}
+	}
+	return delay
+}
+
+// queueHeads returns the first queued delivery of every webhook.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) queueHeads() []modules.WebhookDelivery {
+	var (
+		heads []modules.WebhookDelivery
+		seen  = make(map[uint64]struct{})
+	)
+	for _, delivery := range m.queue {
+		if _, ok := seen[delivery.Event.WebhookID]; ok {
+			continue
+		}
+		seen[delivery.Event.WebhookID] = struct{}{}
+		heads = append(heads, delivery)
+	}
+	return heads
+}
+
+// deliver POSTs the event of the given delivery as JSON to the URL of its webhook,
+// signing the body using the secret of the webhook. Any non-2XX response is considered a failure,
+// including redirects, as these are not followed.
+func (m *Manager) deliver(delivery dueDelivery, stopped <-chan struct{}) error {
+	body, err := json.Marshal(delivery.event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, delivery.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	// abort the attempt when the manager is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopped:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(modules.WebhookSignatureHeader, modules.WebhookSignature(delivery.secret, body))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain (part of) the body, such that the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// retryDelay returns the delay before the next attempt of a delivery,
+// which failed the given amount of times.
+func retryDelay(attempts uint64) time.Duration {
+	delay := retryBaseDelay
+	for i := uint64(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
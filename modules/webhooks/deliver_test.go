@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+)
+
+func TestDeliverEvents(t *testing.T) {
+	const secret = "secret"
+	var (
+		mu        sync.Mutex
+		fail      = true
+		delivered []uint64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if signature := req.Header.Get(modules.WebhookSignatureHeader); signature != modules.WebhookSignature(secret, body) {
+			t.Errorf("unexpected signature: %s", signature)
+		}
+		var event modules.WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delivered = append(delivered, event.ID)
+	}))
+	defer server.Close()
+
+	m := newTestManager(t, nil)
+	m.webhooks[1] = &modules.Webhook{ID: 1, URL: server.URL, Secret: secret, Confirmations: 1}
+	m.enqueue(modules.WebhookEvent{WebhookID: 1, Type: modules.WebhookEventTypeUnconfirmed})
+	m.enqueue(modules.WebhookEvent{WebhookID: 1, Type: modules.WebhookEventTypeConfirmed, Confirmations: 1})
+
+	// a failed delivery is rescheduled, blocking the next events of the webhook
+	delay := m.deliverEvents()
+	if delay < retryBaseDelay-time.Second || delay > retryBaseDelay {
+		t.Errorf("unexpected delay after failed delivery: %v", delay)
+	}
+	if len(m.queue) != 2 || m.queue[0].Attempts != 1 || m.queue[0].LastError == "" || m.queue[1].Attempts != 0 {
+		t.Fatalf("unexpected queue after failed delivery: %v", m.queue)
+	}
+
+	// once the webhook is reachable, all events are delivered in order
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	m.queue[0].NextAttempt = 0
+	for delay = 0; delay == 0; {
+		delay = m.deliverEvents()
+	}
+	if delay != idleDelay || len(m.queue) != 0 {
+		t.Errorf("unexpected delay (%v) or queue after delivery: %v", delay, m.queue)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(delivered) != 2 || delivered[0] != 1 || delivered[1] != 2 {
+		t.Errorf("unexpected delivered events: %v", delivered)
+	}
+}
+
+func TestDeliverNoRedirect(t *testing.T) {
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	m := newTestManager(t, nil)
+	err := m.deliver(dueDelivery{
+		url:    server.URL,
+		secret: "secret",
+		event:  modules.WebhookEvent{ID: 1, WebhookID: 1, Type: modules.WebhookEventTypeUnconfirmed},
+	}, nil)
+	if err == nil {
+		t.Error("a redirect should be considered a failed delivery")
+	}
+	if redirected {
+		t.Error("the redirect should not have been followed")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	testCases := []struct {
+		Attempts uint64
+		Delay    time.Duration
+	}{
+		{1, retryBaseDelay},
+		{2, 2 * retryBaseDelay},
+		{4, 8 * retryBaseDelay},
+		{maxDeliveryAttempts, maxRetryDelay},
+	}
+	for idx, testCase := range testCases {
+		if delay := retryDelay(testCase.Attempts); delay != testCase.Delay {
+			t.Errorf("#%d: unexpected delay after %d attempts: %v != %v", idx, testCase.Attempts, delay, testCase.Delay)
+		}
+	}
+}
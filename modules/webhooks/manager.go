@@ -0,0 +1,287 @@
+// Package webhooks provides the webhook manager, which delivers signed
+// notifications to registered callback URLs when a watched address receives
+// or spends funds, when such a transaction reaches the required amount of
+// confirmations, or when it is reverted by a reorg. Deliveries which fail
+// are retried from a persistent queue.
+package webhooks
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/NebulousLabs/fastrand"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	siasync "github.com/threefoldtech/rivine/sync"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	errNilCS    = errors.New("webhook manager cannot use a nil consensus set")
+	errNilTpool = errors.New("webhook manager cannot use a nil transaction pool")
+)
+
+// Manager delivers the events of the watched addresses to the registered webhooks,
+// and implements modules.WebhookManager.
+type Manager struct {
+	cs     modules.ConsensusSet
+	tpool  modules.TransactionPool
+	client *http.Client
+
+	persistDir string
+	bcInfo     types.BlockchainInfo
+	log        *persist.Logger
+
+	// webhooks contains all registered webhooks, indexed by the addresses they watch in watched.
+	// pending contains the confirmed events waiting for more confirmations,
+	// while queue contains the events which still have to be delivered, in order.
+	// pool contains the transactions of the transaction pool which have been seen already.
+	webhooks      map[uint64]*modules.Webhook
+	watched       map[types.UnlockHash][]uint64
+	pending       []modules.WebhookEvent
+	queue         []modules.WebhookDelivery
+	pool          map[types.TransactionID]struct{}
+	nextWebhookID uint64
+	nextEventID   uint64
+	recentChange  modules.ConsensusChangeID
+	mu            sync.Mutex
+
+	changes chan struct{}
+	tg      siasync.ThreadGroup
+}
+
+// New creates a new webhook manager, loading the persisted webhooks and queued deliveries,
+// and subscribing to consensus and the transaction pool in order to watch the addresses of the webhooks.
+func New(cs modules.ConsensusSet, tpool modules.TransactionPool, persistDir string, bcInfo types.BlockchainInfo, verboseLogging bool) (*Manager, error) {
+	// Check that input modules are non-nil
+	if cs == nil {
+		return nil, errNilCS
+	}
+	if tpool == nil {
+		return nil, errNilTpool
+	}
+
+	m := &Manager{
+		cs:            cs,
+		tpool:         tpool,
+		client:        newDeliveryClient(),
+		persistDir:    persistDir,
+		bcInfo:        bcInfo,
+		webhooks:      make(map[uint64]*modules.Webhook),
+		watched:       make(map[types.UnlockHash][]uint64),
+		pool:          make(map[types.TransactionID]struct{}),
+		nextWebhookID: 1,
+		nextEventID:   1,
+		recentChange:  modules.ConsensusChangeRecent,
+		changes:       make(chan struct{}, 1),
+	}
+
+	// Initialize the persistent structures.
+	err := m.initPersist(verboseLogging)
+	if err != nil {
+		return nil, err
+	}
+
+	// deliver the events in a separate thread,
+	// such that slow webhooks do not block consensus or the transaction pool
+	go m.threadedDeliverEvents()
+
+	// continue from the last processed change, such that no event is missed while the daemon was offline
+	err = cs.ConsensusSetSubscribe(m, m.recentChange, nil)
+	if err == modules.ErrInvalidConsensusChangeID {
+		// The consensus set does not recognize the provided consensus change id,
+		// continue from the current block instead, as rescanning the entire chain
+		// would deliver all past events of the watched addresses once again.
+		m.log.Println("[WARN] Unknown consensus change, continuing from the current block: events may have been missed")
+		m.mu.Lock()
+		resetErr := m.resetConsensusState()
+		m.mu.Unlock()
+		if resetErr != nil {
+			return nil, resetErr
+		}
+		err = cs.ConsensusSetSubscribe(m, modules.ConsensusChangeRecent, nil)
+	}
+	if err != nil {
+		return nil, errors.New("webhook manager consensus subscription failed: " + err.Error())
+	}
+	tpool.TransactionPoolSubscribe(m)
+	return m, nil
+}
+
+// Close unsubscribes the manager from consensus and the transaction pool,
+// and stops delivering events. Queued deliveries are resumed when the manager is restarted.
+func (m *Manager) Close() error {
+	m.tpool.Unsubscribe(m)
+	m.cs.Unsubscribe(m)
+	err := m.tg.Stop()
+	if err != nil {
+		return err
+	}
+	if m.log != nil {
+		err = m.log.Close()
+		if err != nil {
+			// State of the logger is unknown, a println will suffice.
+			fmt.Println("Error shutting down webhook manager logger:", err)
+		}
+	}
+	return nil
+}
+
+// RegisterWebhook implements modules.WebhookManager.RegisterWebhook
+func (m *Manager) RegisterWebhook(rawurl string, addresses []types.UnlockHash, confirmations types.BlockHeight) (modules.Webhook, error) {
+	if err := m.tg.Add(); err != nil {
+		return modules.Webhook{}, err
+	}
+	defer m.tg.Done()
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return modules.Webhook{}, fmt.Errorf("invalid webhook URL: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return modules.Webhook{}, fmt.Errorf("invalid webhook URL %q: an absolute http(s) URL is required", rawurl)
+	}
+	if len(addresses) == 0 {
+		return modules.Webhook{}, errors.New("a webhook has to watch at least one address")
+	}
+	if len(addresses) > modules.WebhookMaxAddresses {
+		return modules.Webhook{}, fmt.Errorf("a webhook can watch at most %d addresses", modules.WebhookMaxAddresses)
+	}
+	unique := make(map[types.UnlockHash]struct{}, len(addresses))
+	watched := make([]types.UnlockHash, 0, len(addresses))
+	for _, uh := range addresses {
+		if uh.Type == types.UnlockTypeNil {
+			return modules.Webhook{}, errors.New("a webhook cannot watch the nil address")
+		}
+		if _, ok := unique[uh]; ok {
+			continue
+		}
+		unique[uh] = struct{}{}
+		watched = append(watched, uh)
+	}
+	if confirmations == 0 {
+		confirmations = 1
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook := &modules.Webhook{
+		ID:            m.nextWebhookID,
+		URL:           u.String(),
+		Addresses:     watched,
+		Confirmations: confirmations,
+		Secret:        hex.EncodeToString(fastrand.Bytes(32)),
+		Created:       types.CurrentTimestamp(),
+	}
+	m.nextWebhookID++
+	m.webhooks[webhook.ID] = webhook
+	m.indexWebhooks()
+	m.log.Printf("registered webhook %d for %d addresses", webhook.ID, len(webhook.Addresses))
+	return copyWebhook(*webhook, true), m.saveSync()
+}
+
+// Webhooks implements modules.WebhookManager.Webhooks
+func (m *Manager) Webhooks() ([]modules.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhooks := make([]modules.Webhook, 0, len(m.webhooks))
+	for _, webhook := range m.webhooks {
+		webhooks = append(webhooks, copyWebhook(*webhook, false))
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+// Webhook implements modules.WebhookManager.Webhook
+func (m *Manager) Webhook(id uint64) (modules.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return modules.Webhook{}, modules.ErrWebhookNotFound
+	}
+	return copyWebhook(*webhook, false), nil
+}
+
+// RemoveWebhook implements modules.WebhookManager.RemoveWebhook
+func (m *Manager) RemoveWebhook(id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.webhooks[id]; !ok {
+		return modules.ErrWebhookNotFound
+	}
+	delete(m.webhooks, id)
+	m.indexWebhooks()
+	pending := m.pending[:0]
+	for _, event := range m.pending {
+		if event.WebhookID != id {
+			pending = append(pending, event)
+		}
+	}
+	m.pending = pending
+	queue := m.queue[:0]
+	for _, delivery := range m.queue {
+		if delivery.Event.WebhookID != id {
+			queue = append(queue, delivery)
+		}
+	}
+	m.queue = queue
+	m.log.Printf("removed webhook %d", id)
+	return m.saveSync()
+}
+
+// WebhookDeliveries implements modules.WebhookManager.WebhookDeliveries
+func (m *Manager) WebhookDeliveries(id uint64) ([]modules.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.webhooks[id]; !ok {
+		return nil, modules.ErrWebhookNotFound
+	}
+	deliveries := []modules.WebhookDelivery{}
+	for _, delivery := range m.queue {
+		if delivery.Event.WebhookID == id {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// indexWebhooks indexes the registered webhooks by the addresses they watch.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) indexWebhooks() {
+	m.watched = make(map[types.UnlockHash][]uint64)
+	for id, webhook := range m.webhooks {
+		for _, uh := range webhook.Addresses {
+			m.watched[uh] = append(m.watched[uh], id)
+		}
+	}
+	for _, ids := range m.watched {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+}
+
+// copyWebhook returns a copy of the given webhook,
+// which only contains the secret if requested.
+func copyWebhook(webhook modules.Webhook, withSecret bool) modules.Webhook {
+	webhook.Addresses = append([]types.UnlockHash(nil), webhook.Addresses...)
+	if !withSecret {
+		webhook.Secret = ""
+	}
+	return webhook
+}
+
+// signalChange signals the delivery thread that new events have been queued.
+func (m *Manager) signalChange() {
+	select {
+	case m.changes <- struct{}{}:
+	default:
+		// a delivery round is already pending
+	}
+}
@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+)
+
+const (
+	logFile     = "webhooks.log"
+	persistFile = "webhooks.json"
+)
+
+var persistMetadata = persist.Metadata{
+	Header:  "Webhook Manager",
+	Version: "1.0.0",
+}
+
+// persistence is the data that is kept when the manager is restarted.
+type persistence struct {
+	RecentChange  modules.ConsensusChangeID `json:"recentchange"`
+	Webhooks      []modules.Webhook         `json:"webhooks"`
+	Pending       []modules.WebhookEvent    `json:"pending"`
+	Queue         []modules.WebhookDelivery `json:"queue"`
+	NextWebhookID uint64                    `json:"nextwebhookid"`
+	NextEventID   uint64                    `json:"nexteventid"`
+}
+
+// initPersist initializes the persistent structures of the manager.
+func (m *Manager) initPersist(verbose bool) error {
+	// Make the persist directory
+	err := os.MkdirAll(m.persistDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	// Initialize the logger.
+	m.log, err = persist.NewFileLogger(m.bcInfo, filepath.Join(m.persistDir, logFile), verbose)
+	if err != nil {
+		return err
+	}
+
+	// Load the webhooks and queued deliveries, if any were persisted before.
+	var data persistence
+	err = persist.LoadJSON(persistMetadata, &data, filepath.Join(m.persistDir, persistFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	m.recentChange = data.RecentChange
+	m.pending = data.Pending
+	m.queue = data.Queue
+	m.nextWebhookID = data.NextWebhookID
+	m.nextEventID = data.NextEventID
+	for idx := range data.Webhooks {
+		webhook := data.Webhooks[idx]
+		m.webhooks[webhook.ID] = &webhook
+	}
+	m.indexWebhooks()
+	return nil
+}
+
+// saveSync persists the webhooks and queued deliveries,
+// it is expected that the caller holds the lock of the manager.
+func (m *Manager) saveSync() error {
+	data := persistence{
+		RecentChange:  m.recentChange,
+		Webhooks:      make([]modules.Webhook, 0, len(m.webhooks)),
+		Pending:       m.pending,
+		Queue:         m.queue,
+		NextWebhookID: m.nextWebhookID,
+		NextEventID:   m.nextEventID,
+	}
+	for _, webhook := range m.webhooks {
+		data.Webhooks = append(data.Webhooks, *webhook)
+	}
+	err := persist.SaveJSON(persistMetadata, data, filepath.Join(m.persistDir, persistFile))
+	if err != nil {
+		m.log.Printf("failed to persist the webhooks: %v", err)
+	}
+	return err
+}
+
+// resetConsensusState drops all state derived from consensus changes,
+// which are the events waiting for more confirmations and the most recent change.
+// The webhooks and the queued deliveries are kept.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) resetConsensusState() error {
+	m.pending = nil
+	m.recentChange = modules.ConsensusChangeRecent
+	return m.saveSync()
+}
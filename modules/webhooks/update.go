@@ -0,0 +1,253 @@
+package webhooks
+
+import (
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber,
+// queueing the reverted events of the watched addresses of reverted blocks,
+// and the confirmed events of the transactions which reached the confirmations
+// required by their webhook.
+//
+// Events are not delivered in this call, as that would block consensus.
+func (m *Manager) ProcessConsensusChange(cc modules.ConsensusChange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	outputs := newChangeOutputs(cc)
+	queued := len(m.queue)
+	for _, block := range cc.RevertedBlocks {
+		height, _ := m.cs.BlockHeightOfBlock(block)
+		m.revertBlock(block, height, outputs)
+	}
+	for _, block := range cc.AppliedBlocks {
+		height, _ := m.cs.BlockHeightOfBlock(block)
+		m.applyBlock(block, height, outputs)
+	}
+	m.recentChange = cc.ID
+	m.saveSync()
+
+	if len(m.queue) > queued {
+		m.signalChange()
+	}
+}
+
+// ReceiveUpdatedUnconfirmedTransactions implements modules.TransactionPoolSubscriber.ReceiveUpdatedUnconfirmedTransactions,
+// queueing the unconfirmed events of the watched addresses for the transactions added to the transaction pool.
+func (m *Manager) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction, cc modules.ConsensusChange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	outputs := newChangeOutputs(cc)
+	queued := len(m.queue)
+	pool := make(map[types.TransactionID]struct{}, len(txns))
+	for _, txn := range txns {
+		txid := txn.ID()
+		pool[txid] = struct{}{}
+		if _, ok := m.pool[txid]; ok {
+			continue // already seen
+		}
+		for _, event := range m.transactionEvents(txn, outputs) {
+			event.Type = modules.WebhookEventTypeUnconfirmed
+			m.enqueue(event)
+		}
+	}
+	m.pool = pool
+
+	if len(m.queue) > queued {
+		m.saveSync()
+		m.signalChange()
+	}
+	return nil
+}
+
+// applyBlock adds the events of the given block as pending,
+// and queues the pending events which reached the confirmations required by their webhook.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) applyBlock(block types.Block, height types.BlockHeight, outputs changeOutputs) {
+	for _, event := range m.blockEvents(block, height, outputs) {
+		event.Type = modules.WebhookEventTypeConfirmed
+		m.pending = append(m.pending, event)
+	}
+	pending := m.pending[:0]
+	for _, event := range m.pending {
+		webhook, ok := m.webhooks[event.WebhookID]
+		if !ok {
+			continue
+		}
+		confirmations := height - event.Height + 1
+		if confirmations >= webhook.Confirmations {
+			event.Confirmations = confirmations
+			m.enqueue(event)
+			continue
+		}
+		pending = append(pending, event)
+	}
+	m.pending = pending
+}
+
+// revertBlock drops the pending events of the given block,
+// and queues a reverted event for every event of the block.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) revertBlock(block types.Block, height types.BlockHeight, outputs changeOutputs) {
+	blockID := block.ID()
+	pending := m.pending[:0]
+	for _, event := range m.pending {
+		if event.BlockID == nil || *event.BlockID != blockID {
+			pending = append(pending, event)
+		}
+	}
+	m.pending = pending
+	for _, event := range m.blockEvents(block, height, outputs) {
+		event.Type = modules.WebhookEventTypeReverted
+		m.enqueue(event)
+	}
+}
+
+// enqueue assigns an ID to the given event, and queues it for immediate delivery.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) enqueue(event modules.WebhookEvent) {
+	event.ID = m.nextEventID
+	m.nextEventID++
+	event.Timestamp = types.CurrentTimestamp()
+	m.queue = append(m.queue, modules.WebhookDelivery{
+		Event:       event,
+		NextAttempt: event.Timestamp,
+	})
+	m.log.Debugf("queued %s event %d of address %s for webhook %d",
+		event.Type.String(), event.ID, event.Address.String(), event.WebhookID)
+}
+
+// blockEvents returns the (untyped) events of the watched addresses
+// which receive a miner payout in the given block, or which are part of one of its transactions.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) blockEvents(block types.Block, height types.BlockHeight, outputs changeOutputs) []modules.WebhookEvent {
+	set := newEventSet(m.watched, nil)
+	for _, mp := range block.MinerPayouts {
+		if event := set.match(mp.UnlockHash); event != nil {
+			event.CoinsReceived = event.CoinsReceived.Add(mp.Value)
+		}
+	}
+	events := set.webhookEvents()
+	for _, txn := range block.Transactions {
+		events = append(events, m.transactionEvents(txn, outputs)...)
+	}
+	blockID := block.ID()
+	for idx := range events {
+		events[idx].BlockID = &blockID
+		events[idx].Height = height
+	}
+	return events
+}
+
+// transactionEvents returns the (untyped) events of the watched addresses
+// which receive or spend funds in the given transaction, or which are referenced by its extension data.
+// It is expected that the caller holds the lock of the manager.
+func (m *Manager) transactionEvents(txn types.Transaction, outputs changeOutputs) []modules.WebhookEvent {
+	txid := txn.ID()
+	set := newEventSet(m.watched, &txid)
+	for _, ci := range txn.CoinInputs {
+		co, ok := outputs.coins[ci.ParentID]
+		if !ok {
+			continue
+		}
+		if event := set.match(co.Condition.UnlockHash()); event != nil {
+			event.CoinsSpent = event.CoinsSpent.Add(co.Value)
+		}
+	}
+	for _, co := range txn.CoinOutputs {
+		if event := set.match(co.Condition.UnlockHash()); event != nil {
+			event.CoinsReceived = event.CoinsReceived.Add(co.Value)
+		}
+	}
+	for _, bsi := range txn.BlockStakeInputs {
+		bso, ok := outputs.blockStakes[bsi.ParentID]
+		if !ok {
+			continue
+		}
+		if event := set.match(bso.Condition.UnlockHash()); event != nil {
+			event.BlockStakesSpent = event.BlockStakesSpent.Add(bso.Value)
+		}
+	}
+	for _, bso := range txn.BlockStakeOutputs {
+		if event := set.match(bso.Condition.UnlockHash()); event != nil {
+			event.BlockStakesReceived = event.BlockStakesReceived.Add(bso.Value)
+		}
+	}
+	// link the transaction to the addresses referenced by its extension data as well,
+	// as done for the address history of the explorer
+	uhs, _ := modules.ExplorerTransactionAddresses(txn)
+	for _, uh := range uhs {
+		set.match(uh)
+	}
+	return set.webhookEvents()
+}
+
+// eventSet accumulates the funds received and spent by the watched addresses,
+// in the order the addresses are matched.
+type eventSet struct {
+	watched       map[types.UnlockHash][]uint64
+	transactionID *types.TransactionID
+	order         []types.UnlockHash
+	events        map[types.UnlockHash]*modules.WebhookEvent
+}
+
+func newEventSet(watched map[types.UnlockHash][]uint64, transactionID *types.TransactionID) *eventSet {
+	return &eventSet{
+		watched:       watched,
+		transactionID: transactionID,
+		events:        make(map[types.UnlockHash]*modules.WebhookEvent),
+	}
+}
+
+// match returns the event of the given address, or nil if the address is not watched.
+func (set *eventSet) match(uh types.UnlockHash) *modules.WebhookEvent {
+	if _, ok := set.watched[uh]; !ok {
+		return nil
+	}
+	event, ok := set.events[uh]
+	if !ok {
+		event = &modules.WebhookEvent{
+			Address:       uh,
+			TransactionID: set.transactionID,
+		}
+		set.events[uh] = event
+		set.order = append(set.order, uh)
+	}
+	return event
+}
+
+// webhookEvents returns a copy of the matched events for every webhook watching their address.
+func (set *eventSet) webhookEvents() []modules.WebhookEvent {
+	var events []modules.WebhookEvent
+	for _, uh := range set.order {
+		for _, id := range set.watched[uh] {
+			event := *set.events[uh]
+			event.WebhookID = id
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// changeOutputs are the outputs created and spent by a consensus change,
+// used to resolve the outputs spent by the inputs of its transactions.
+type changeOutputs struct {
+	coins       map[types.CoinOutputID]types.CoinOutput
+	blockStakes map[types.BlockStakeOutputID]types.BlockStakeOutput
+}
+
+func newChangeOutputs(cc modules.ConsensusChange) changeOutputs {
+	outputs := changeOutputs{
+		coins:       make(map[types.CoinOutputID]types.CoinOutput, len(cc.CoinOutputDiffs)),
+		blockStakes: make(map[types.BlockStakeOutputID]types.BlockStakeOutput, len(cc.BlockStakeOutputDiffs)),
+	}
+	for _, diff := range cc.CoinOutputDiffs {
+		outputs.coins[diff.ID] = diff.CoinOutput
+	}
+	for _, diff := range cc.BlockStakeOutputDiffs {
+		outputs.blockStakes[diff.ID] = diff.BlockStakeOutput
+	}
+	return outputs
+}
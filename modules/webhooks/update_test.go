@@ -0,0 +1,195 @@
+package webhooks
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// testConsensusSet only resolves the heights of the blocks of a test.
+type testConsensusSet struct {
+	modules.ConsensusSet
+	heights map[types.BlockID]types.BlockHeight
+}
+
+func (cs *testConsensusSet) BlockHeightOfBlock(block types.Block) (types.BlockHeight, bool) {
+	height, ok := cs.heights[block.ID()]
+	return height, ok
+}
+
+func newTestManager(t *testing.T, cs modules.ConsensusSet) *Manager {
+	persistDir := build.TempDir(modules.WebhooksDir, t.Name())
+	err := os.MkdirAll(persistDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Manager{
+		cs:            cs,
+		client:        newDeliveryClient(),
+		persistDir:    persistDir,
+		log:           persist.NewLogger(types.DefaultBlockchainInfo(), ioutil.Discard, false),
+		webhooks:      make(map[uint64]*modules.Webhook),
+		watched:       make(map[types.UnlockHash][]uint64),
+		pool:          make(map[types.TransactionID]struct{}),
+		nextWebhookID: 1,
+		nextEventID:   1,
+		changes:       make(chan struct{}, 1),
+	}
+}
+
+func TestWebhookEvents(t *testing.T) {
+	cs := &testConsensusSet{heights: make(map[types.BlockID]types.BlockHeight)}
+	m := newTestManager(t, cs)
+
+	watched := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	other := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+	m.webhooks[1] = &modules.Webhook{ID: 1, Addresses: []types.UnlockHash{watched}, Confirmations: 1}
+	m.webhooks[2] = &modules.Webhook{ID: 2, Addresses: []types.UnlockHash{watched}, Confirmations: 3}
+	m.webhooks[3] = &modules.Webhook{ID: 3, Addresses: []types.UnlockHash{other}, Confirmations: 1}
+	m.indexWebhooks()
+
+	spentID := types.CoinOutputID{1}
+	spentOutput := types.CoinOutput{
+		Value:     types.NewCurrency64(30),
+		Condition: types.NewCondition(types.NewUnlockHashCondition(watched)),
+	}
+	txn := types.Transaction{
+		Version:    types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{ParentID: spentID}},
+		CoinOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(10),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(watched)),
+		}},
+	}
+	txid := txn.ID()
+	diffs := []modules.CoinOutputDiff{{
+		Direction:  modules.DiffRevert,
+		ID:         spentID,
+		CoinOutput: spentOutput,
+	}}
+
+	// transactions are only notified once while they are in the pool
+	m.ReceiveUpdatedUnconfirmedTransactions([]types.Transaction{txn}, modules.ConsensusChange{CoinOutputDiffs: diffs})
+	m.ReceiveUpdatedUnconfirmedTransactions([]types.Transaction{txn}, modules.ConsensusChange{CoinOutputDiffs: diffs})
+	checkQueue(t, m, []modules.WebhookEvent{
+		{ID: 1, WebhookID: 1, Type: modules.WebhookEventTypeUnconfirmed},
+		{ID: 2, WebhookID: 2, Type: modules.WebhookEventTypeUnconfirmed},
+	})
+	for _, delivery := range m.queue {
+		event := delivery.Event
+		if event.Address != watched || event.TransactionID == nil || *event.TransactionID != txid || event.BlockID != nil {
+			t.Errorf("unexpected unconfirmed event: %v", event)
+		}
+		if !event.CoinsReceived.Equals64(10) || !event.CoinsSpent.Equals64(30) {
+			t.Errorf("unexpected funds of unconfirmed event: received %s, spent %s", event.CoinsReceived.String(), event.CoinsSpent.String())
+		}
+	}
+	m.queue = nil
+
+	blocks := []types.Block{
+		{Timestamp: 1, Transactions: []types.Transaction{txn}},
+		{Timestamp: 2, MinerPayouts: []types.MinerPayout{{Value: types.NewCurrency64(5), UnlockHash: other}}},
+		{Timestamp: 3},
+	}
+	for idx, block := range blocks {
+		cs.heights[block.ID()] = types.BlockHeight(idx + 1)
+	}
+
+	// the first webhook requires a single confirmation, the second one three
+	m.ProcessConsensusChange(modules.ConsensusChange{
+		AppliedBlocks:   blocks[:2],
+		CoinOutputDiffs: diffs,
+	})
+	checkQueue(t, m, []modules.WebhookEvent{
+		{ID: 3, WebhookID: 1, Type: modules.WebhookEventTypeConfirmed, Height: 1, Confirmations: 1},
+		{ID: 4, WebhookID: 3, Type: modules.WebhookEventTypeConfirmed, Height: 2, Confirmations: 1},
+	})
+	if event := m.queue[1].Event; event.TransactionID != nil || !event.CoinsReceived.Equals64(5) {
+		t.Errorf("unexpected miner payout event: %v", event)
+	}
+	if len(m.pending) != 1 || m.pending[0].WebhookID != 2 {
+		t.Fatalf("unexpected pending events: %v", m.pending)
+	}
+	m.queue = nil
+
+	// reverting the blocks reverts all their events, and drops the pending ones
+	m.ProcessConsensusChange(modules.ConsensusChange{
+		RevertedBlocks:  []types.Block{blocks[1], blocks[0]},
+		CoinOutputDiffs: diffs,
+	})
+	checkQueue(t, m, []modules.WebhookEvent{
+		{ID: 5, WebhookID: 3, Type: modules.WebhookEventTypeReverted, Height: 2},
+		{ID: 6, WebhookID: 1, Type: modules.WebhookEventTypeReverted, Height: 1},
+		{ID: 7, WebhookID: 2, Type: modules.WebhookEventTypeReverted, Height: 1},
+	})
+	if len(m.pending) != 0 {
+		t.Fatalf("unexpected pending events: %v", m.pending)
+	}
+	m.queue = nil
+
+	// the confirmed event of the second webhook is only queued at its third confirmation
+	m.ProcessConsensusChange(modules.ConsensusChange{
+		AppliedBlocks:   blocks[:2],
+		CoinOutputDiffs: diffs,
+	})
+	if len(m.queue) != 2 || len(m.pending) != 1 {
+		t.Fatalf("unexpected amount of queued (%d) and pending (%d) events", len(m.queue), len(m.pending))
+	}
+	m.queue = nil
+	m.ProcessConsensusChange(modules.ConsensusChange{AppliedBlocks: blocks[2:]})
+	checkQueue(t, m, []modules.WebhookEvent{
+		{ID: 10, WebhookID: 2, Type: modules.WebhookEventTypeConfirmed, Height: 1, Confirmations: 3},
+	})
+	if len(m.pending) != 0 {
+		t.Fatalf("unexpected pending events: %v", m.pending)
+	}
+
+	// removing a webhook drops its queued deliveries
+	err := m.RemoveWebhook(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQueue(t, m, nil)
+	if err := m.RemoveWebhook(2); err != modules.ErrWebhookNotFound {
+		t.Errorf("unexpected error when removing an unknown webhook: %v", err)
+	}
+}
+
+func checkQueue(t *testing.T, m *Manager, expected []modules.WebhookEvent) {
+	t.Helper()
+	if len(m.queue) != len(expected) {
+		t.Fatalf("unexpected amount of queued events: %d != %d", len(m.queue), len(expected))
+	}
+	for idx, delivery := range m.queue {
+		event := delivery.Event
+		if event.ID != expected[idx].ID || event.WebhookID != expected[idx].WebhookID || event.Type != expected[idx].Type ||
+			event.Height != expected[idx].Height || event.Confirmations != expected[idx].Confirmations {
+			t.Errorf("unexpected queued event #%d: %d (webhook %d) %s at height %d with %d confirmations",
+				idx, event.ID, event.WebhookID, event.Type.String(), event.Height, event.Confirmations)
+		}
+	}
+}
+
+func TestResetConsensusState(t *testing.T) {
+	m := newTestManager(t, nil)
+	m.webhooks[1] = &modules.Webhook{ID: 1, URL: "http://localhost", Confirmations: 2}
+	m.pending = []modules.WebhookEvent{{ID: 1, WebhookID: 1, Type: modules.WebhookEventTypeConfirmed}}
+	m.enqueue(modules.WebhookEvent{WebhookID: 1, Type: modules.WebhookEventTypeUnconfirmed})
+	m.recentChange = modules.ConsensusChangeID{1}
+
+	err := m.resetConsensusState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.pending) != 0 || m.recentChange != modules.ConsensusChangeRecent {
+		t.Errorf("consensus state was not reset: %v", m.pending)
+	}
+	if len(m.webhooks) != 1 || len(m.queue) != 1 {
+		t.Errorf("webhooks and queued deliveries should be kept: %v", m.queue)
+	}
+}
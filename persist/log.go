@@ -1,67 +1,433 @@
 package persist
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/threefoldtech/rivine/build"
 	"github.com/threefoldtech/rivine/types"
 )
 
+// LogLevel defines the severity of a log message.
+// Messages with a level lower than the level of a Logger are discarded.
+type LogLevel uint32
+
+// all log levels supported by the Logger, from least to most severe
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelCritical
+)
+
+var logLevelNames = [...]string{"debug", "info", "warn", "error", "critical"}
+
+// String implements fmt.Stringer.String
+func (lvl LogLevel) String() string {
+	if int(lvl) < len(logLevelNames) {
+		return logLevelNames[lvl]
+	}
+	return fmt.Sprintf("LogLevel(%d)", uint32(lvl))
+}
+
+// LoadString loads the log level from its (case insensitive) name.
+func (lvl *LogLevel) LoadString(str string) error {
+	str = strings.ToLower(strings.TrimSpace(str))
+	switch str {
+	case "warning":
+		str = "warn"
+	case "severe":
+		str = "error"
+	}
+	for idx, name := range logLevelNames {
+		if name == str {
+			*lvl = LogLevel(idx)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid log level %q, has to be one of: %s", str, strings.Join(logLevelNames[:], "|"))
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText
+func (lvl LogLevel) MarshalText() ([]byte, error) {
+	return []byte(lvl.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.UnmarshalText
+func (lvl *LogLevel) UnmarshalText(text []byte) error {
+	return lvl.LoadString(string(text))
+}
+
+// LogFormat defines the format in which log messages are written.
+type LogFormat uint8
+
+// all log formats supported by the Logger
+const (
+	// LogFormatText writes each message as a free-form line,
+	// prefixed with the date, time and source location.
+	LogFormatText LogFormat = iota
+	// LogFormatJSON writes each message as a single-line JSON object.
+	LogFormatJSON
+)
+
+// String implements fmt.Stringer.String
+func (f LogFormat) String() string {
+	switch f {
+	case LogFormatText:
+		return "text"
+	case LogFormatJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("LogFormat(%d)", uint8(f))
+	}
+}
+
+// LoadString loads the log format from its (case insensitive) name.
+func (f *LogFormat) LoadString(str string) error {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "text":
+		*f = LogFormatText
+	case "json":
+		*f = LogFormatJSON
+	default:
+		return fmt.Errorf("invalid log format %q, has to be one of: text|json", str)
+	}
+	return nil
+}
+
+// LogConfig defines how the loggers of all modules log.
+type LogConfig struct {
+	// Format in which all messages are written.
+	Format LogFormat
+	// Level used for all modules which do not have a level defined in ModuleLevels.
+	Level LogLevel
+	// ModuleLevels allows a specific level to be defined per module,
+	// where the module name is the name of its log file without the ".log" extension.
+	ModuleLevels map[string]LogLevel
+	// MaxSize is the size in megabytes a log file can reach before it gets rotated,
+	// rotation is disabled if it is 0.
+	MaxSize int64
+	// MaxBackups is the amount of rotated log files to keep.
+	MaxBackups int
+}
+
+// DefaultLogConfig returns the default log configuration.
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Format:     LogFormatText,
+		Level:      LogLevelInfo,
+		MaxSize:    0,
+		MaxBackups: 3,
+	}
+}
+
+// levelFor returns the level configured for the given module.
+func (cfg LogConfig) levelFor(module string) LogLevel {
+	if lvl, ok := cfg.ModuleLevels[module]; ok {
+		return lvl
+	}
+	return cfg.Level
+}
+
+// logRegistry keeps track of the active log configuration
+// and all open loggers, such that their level can be changed at runtime.
+var logRegistry = struct {
+	mu      sync.Mutex
+	cfg     LogConfig
+	loggers map[*Logger]struct{}
+}{
+	cfg:     DefaultLogConfig(),
+	loggers: make(map[*Logger]struct{}),
+}
+
+// SetLogConfig defines the log configuration used by all loggers created from now on,
+// and applies the (module) levels of the configuration to all open loggers.
+func SetLogConfig(cfg LogConfig) {
+	logRegistry.mu.Lock()
+	defer logRegistry.mu.Unlock()
+	levels := make(map[string]LogLevel, len(cfg.ModuleLevels))
+	for module, lvl := range cfg.ModuleLevels {
+		levels[module] = lvl
+	}
+	cfg.ModuleLevels = levels
+	logRegistry.cfg = cfg
+	for l := range logRegistry.loggers {
+		l.SetLevel(cfg.levelFor(l.module))
+	}
+}
+
+// SetModuleLogLevel defines the level of the given module,
+// both for its open loggers as well as for its loggers created from now on.
+func SetModuleLogLevel(module string, lvl LogLevel) {
+	logRegistry.mu.Lock()
+	defer logRegistry.mu.Unlock()
+	if logRegistry.cfg.ModuleLevels == nil {
+		logRegistry.cfg.ModuleLevels = make(map[string]LogLevel)
+	}
+	logRegistry.cfg.ModuleLevels[module] = lvl
+	for l := range logRegistry.loggers {
+		if l.module == module {
+			l.SetLevel(lvl)
+		}
+	}
+}
+
+// ModuleLogLevels returns the level of all open loggers,
+// as well as the configured level of all modules which have no open logger.
+// When a module has multiple open loggers, the least severe level is returned.
+func ModuleLogLevels() map[string]LogLevel {
+	logRegistry.mu.Lock()
+	defer logRegistry.mu.Unlock()
+	levels := make(map[string]LogLevel)
+	for module, lvl := range logRegistry.cfg.ModuleLevels {
+		levels[module] = lvl
+	}
+	seen := make(map[string]bool)
+	for l := range logRegistry.loggers {
+		if lvl := l.Level(); !seen[l.module] || lvl < levels[l.module] {
+			levels[l.module] = lvl
+		}
+		seen[l.module] = true
+	}
+	return levels
+}
+
+func currentLogConfig() LogConfig {
+	logRegistry.mu.Lock()
+	defer logRegistry.mu.Unlock()
+	return logRegistry.cfg
+}
+
 // Logger is a wrapper for the standard library logger that enforces logging
 // with the Sia-standard settings. It also supports a Close method, which
 // attempts to close the underlying io.Writer.
+//
+// Each message has a level, messages with a level lower
+// than the level of the Logger are discarded. Messages logged using
+// the Print methods have their level inferred from a prefix such as
+// "WARN:" or "[ERROR]", defaulting to the info level.
 type Logger struct {
-	*log.Logger
-	w       io.Writer
-	verbose bool
+	w      io.Writer
+	std    *log.Logger
+	module string
+	format LogFormat
+	level  uint32 // atomic
+	mu     sync.Mutex
 }
 
 // Close logs a shutdown message and closes the Logger's underlying io.Writer,
 // if it is also an io.Closer.
 func (l *Logger) Close() error {
-	l.Output(2, "SHUTDOWN: Logging has terminated.")
-	if c, ok := l.w.(io.Closer); ok {
+	l.output(2, LogLevelCritical, "SHUTDOWN: Logging has terminated.")
+	logRegistry.mu.Lock()
+	delete(logRegistry.loggers, l)
+	logRegistry.mu.Unlock()
+	if c, ok := l.Writer().(io.Closer); ok {
 		return c.Close()
 	}
 	return nil
 }
 
+// Module returns the name of the module this logger logs for.
+func (l *Logger) Module() string {
+	return l.module
+}
+
+// Level returns the current level of the Logger.
+func (l *Logger) Level() LogLevel {
+	return LogLevel(atomic.LoadUint32(&l.level))
+}
+
+// SetLevel changes the level of the Logger.
+func (l *Logger) SetLevel(lvl LogLevel) {
+	atomic.StoreUint32(&l.level, uint32(lvl))
+}
+
+// Output writes the output for a logging event,
+// inferring its level from the message prefix.
+// It has the same semantics as log.Logger.Output.
+func (l *Logger) Output(calldepth int, s string) error {
+	return l.output(calldepth+1, inferLogLevel(s), s)
+}
+
+// Print logs a message, inferring its level from the message prefix.
+// Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Print(v ...interface{}) {
+	msg := fmt.Sprint(v...)
+	l.output(2, inferLogLevel(msg), msg)
+}
+
+// Printf logs a message, inferring its level from the message prefix.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Printf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	l.output(2, inferLogLevel(msg), msg)
+}
+
+// Println logs a message, inferring its level from the message prefix.
+// Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Println(v ...interface{}) {
+	msg := fmt.Sprintln(v...)
+	l.output(2, inferLogLevel(msg), msg)
+}
+
+// Fatal logs a message at the critical level, followed by a call to os.Exit(1).
+// Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Fatal(v ...interface{}) {
+	l.output(2, LogLevelCritical, fmt.Sprint(v...))
+	os.Exit(1)
+}
+
+// Fatalf logs a message at the critical level, followed by a call to os.Exit(1).
+// Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	l.output(2, LogLevelCritical, fmt.Sprintf(format, v...))
+	os.Exit(1)
+}
+
+// Fatalln logs a message at the critical level, followed by a call to os.Exit(1).
+// Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Fatalln(v ...interface{}) {
+	l.output(2, LogLevelCritical, fmt.Sprintln(v...))
+	os.Exit(1)
+}
+
+// Panic logs a message at the critical level, followed by a call to panic().
+// Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Panic(v ...interface{}) {
+	msg := fmt.Sprint(v...)
+	l.output(2, LogLevelCritical, msg)
+	panic(msg)
+}
+
+// Panicf logs a message at the critical level, followed by a call to panic().
+// Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Panicf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	l.output(2, LogLevelCritical, msg)
+	panic(msg)
+}
+
+// Panicln logs a message at the critical level, followed by a call to panic().
+// Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Panicln(v ...interface{}) {
+	msg := fmt.Sprintln(v...)
+	l.output(2, LogLevelCritical, msg)
+	panic(msg)
+}
+
+// Flags returns the output flags of the Logger,
+// which only apply to the text format.
+func (l *Logger) Flags() int {
+	return l.std.Flags()
+}
+
+// SetFlags sets the output flags of the Logger,
+// which only apply to the text format.
+func (l *Logger) SetFlags(flag int) {
+	l.std.SetFlags(flag)
+}
+
+// Prefix returns the output prefix of the Logger,
+// which only applies to the text format.
+func (l *Logger) Prefix() string {
+	return l.std.Prefix()
+}
+
+// SetPrefix sets the output prefix of the Logger,
+// which only applies to the text format.
+func (l *Logger) SetPrefix(prefix string) {
+	l.std.SetPrefix(prefix)
+}
+
+// Writer returns the output destination of the Logger.
+func (l *Logger) Writer() io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w
+}
+
+// SetOutput sets the output destination of the Logger.
+// The previous destination is not closed.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w = w
+	l.std.SetOutput(w)
+}
+
 // Critical logs a message with a CRITICAL prefix that guides the user to the
 // Sia github tracker. If debug mode is enabled, it will also write the message
 // to os.Stderr and panic. Critical should only be called if there has been a
 // developer error, otherwise Severe should be called.
 func (l *Logger) Critical(v ...interface{}) {
-	l.Output(2, "CRITICAL: "+fmt.Sprintln(v...))
+	l.output(2, LogLevelCritical, "CRITICAL: "+fmt.Sprintln(v...))
 	build.Critical(v...)
 }
 
-// Debug is equivalent to Logger.Print when build.DEBUG is true. Otherwise it
-// is a no-op.
+// Debug logs a message at the debug level.
+// Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Debug(v ...interface{}) {
-	if l.verbose {
-		l.Output(2, fmt.Sprint(v...))
-	}
+	l.output(2, LogLevelDebug, fmt.Sprint(v...))
 }
 
-// Debugf is equivalent to Logger.Printf when build.DEBUG is true. Otherwise it
-// is a no-op.
+// Debugf logs a message at the debug level.
+// Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Debugf(format string, v ...interface{}) {
-	if l.verbose {
-		l.Output(2, fmt.Sprintf(format, v...))
-	}
+	l.output(2, LogLevelDebug, fmt.Sprintf(format, v...))
 }
 
-// Debugln is equivalent to Logger.Println when build.DEBUG is true. Otherwise
-// it is a no-op.
+// Debugln logs a message at the debug level.
+// Arguments are handled in the manner of fmt.Println.
 func (l *Logger) Debugln(v ...interface{}) {
-	if l.verbose {
-		l.Output(2, "[DEBUG] "+fmt.Sprintln(v...))
-	}
+	l.output(2, LogLevelDebug, "[DEBUG] "+fmt.Sprintln(v...))
+}
+
+// Infof logs a message at the info level.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.output(2, LogLevelInfo, "INFO: "+fmt.Sprintf(format, v...))
+}
+
+// Infoln logs a message at the info level.
+// Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Infoln(v ...interface{}) {
+	l.output(2, LogLevelInfo, "INFO: "+fmt.Sprintln(v...))
+}
+
+// Warnf logs a message at the warn level.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.output(2, LogLevelWarn, "WARN: "+fmt.Sprintf(format, v...))
+}
+
+// Warnln logs a message at the warn level.
+// Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Warnln(v ...interface{}) {
+	l.output(2, LogLevelWarn, "WARN: "+fmt.Sprintln(v...))
+}
+
+// Errorf logs a message at the error level.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.output(2, LogLevelError, "ERROR: "+fmt.Sprintf(format, v...))
+}
+
+// Errorln logs a message at the error level.
+// Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Errorln(v ...interface{}) {
+	l.output(2, LogLevelError, "ERROR: "+fmt.Sprintln(v...))
 }
 
 // Severe logs a message with a SEVERE prefix. If debug mode is enabled, it
@@ -70,19 +436,138 @@ func (l *Logger) Debugln(v ...interface{}) {
 // addressed ASAP but does not necessarily require that the machine crash or
 // exit.
 func (l *Logger) Severe(v ...interface{}) {
-	l.Output(2, "SEVERE: "+fmt.Sprintln(v...))
+	l.output(2, LogLevelError, "SEVERE: "+fmt.Sprintln(v...))
 	build.Severe(v...)
 }
 
+// jsonLogEntry is the format of a single message logged in the JSON format.
+type jsonLogEntry struct {
+	Time    string   `json:"time"`
+	Level   LogLevel `json:"level"`
+	Module  string   `json:"module,omitempty"`
+	Caller  string   `json:"caller,omitempty"`
+	Message string   `json:"msg"`
+}
+
+// output writes the message, if its level is at least the level of the logger.
+// calldepth is relative to the caller of output.
+func (l *Logger) output(calldepth int, lvl LogLevel, msg string) error {
+	if lvl < l.Level() {
+		return nil
+	}
+	if l.format != LogFormatJSON {
+		return l.std.Output(calldepth+1, msg)
+	}
+	entry := jsonLogEntry{
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+		Level:   lvl,
+		Module:  l.module,
+		Message: strings.TrimSpace(trimLogLevelPrefix(msg)),
+	}
+	if _, file, line, ok := runtime.Caller(calldepth); ok {
+		entry.Caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(b, '\n'))
+	return err
+}
+
+// logLevelPrefixes are the message prefixes used to infer
+// the level of messages logged using the Print methods.
+var logLevelPrefixes = []struct {
+	prefix string
+	level  LogLevel
+}{
+	{"DEBUG", LogLevelDebug},
+	{"INFO", LogLevelInfo},
+	{"WARN", LogLevelWarn},
+	{"ERROR", LogLevelError},
+	{"SEVERE", LogLevelError},
+	{"CRITICAL", LogLevelCritical},
+	{"STARTUP", LogLevelCritical},
+	{"SHUTDOWN", LogLevelCritical},
+}
+
+// matchLogLevelPrefix returns the index of the matched prefix,
+// and the message without that prefix. The index is -1 if no prefix matches.
+func matchLogLevelPrefix(msg string) (int, string) {
+	s := strings.TrimPrefix(msg, "[")
+	for idx, p := range logLevelPrefixes {
+		if !strings.HasPrefix(s, p.prefix) {
+			continue
+		}
+		rest := s[len(p.prefix):]
+		if !strings.HasPrefix(rest, ":") && !strings.HasPrefix(rest, "]") {
+			continue
+		}
+		return idx, strings.TrimLeft(rest[1:], " ")
+	}
+	return -1, msg
+}
+
+// inferLogLevel infers the level of a message based on its prefix,
+// defaulting to the info level.
+func inferLogLevel(msg string) LogLevel {
+	idx, _ := matchLogLevelPrefix(msg)
+	if idx < 0 {
+		return LogLevelInfo
+	}
+	return logLevelPrefixes[idx].level
+}
+
+// trimLogLevelPrefix removes the level prefix from a message,
+// as the level is already part of a JSON log entry.
+// Startup and shutdown prefixes are kept, as they are not levels.
+func trimLogLevelPrefix(msg string) string {
+	idx, rest := matchLogLevelPrefix(msg)
+	if idx < 0 || logLevelPrefixes[idx].prefix == "STARTUP" || logLevelPrefixes[idx].prefix == "SHUTDOWN" {
+		return msg
+	}
+	return rest
+}
+
 // NewLogger returns a logger that can be closed. Calls should not be made to
 // the logger after 'Close' has been called.
 func NewLogger(info types.BlockchainInfo, w io.Writer, verbose bool) *Logger {
-	l := log.New(w, "", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile|log.LUTC)
-	// Call depth is 3 because NewLogger is usually called by NewFileLogger
-	l.Output(3, fmt.Sprintf(
+	return newLogger(info, w, "", verbose, currentLogConfig())
+}
+
+// NewModuleLogger returns a logger for the given module that can be closed,
+// using the level configured for that module. Calls should not be made to
+// the logger after 'Close' has been called.
+func NewModuleLogger(info types.BlockchainInfo, w io.Writer, module string, verbose bool) *Logger {
+	return newLogger(info, w, module, verbose, currentLogConfig())
+}
+
+func newLogger(info types.BlockchainInfo, w io.Writer, module string, verbose bool, cfg LogConfig) *Logger {
+	l := &Logger{
+		w:      w,
+		std:    log.New(w, "", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile|log.LUTC),
+		module: module,
+		format: cfg.Format,
+	}
+	// verbose logging enables the debug level,
+	// regardless of the configured level
+	lvl := cfg.levelFor(module)
+	if verbose {
+		lvl = LogLevelDebug
+	}
+	l.SetLevel(lvl)
+	// Call depth is 3 because newLogger is always called by one of the exported constructors
+	l.output(3, LogLevelCritical, fmt.Sprintf(
 		"STARTUP: Logging has started. %s Version %s",
 		info.Name, info.ChainVersion.String()))
-	return &Logger{l, w, verbose}
+	if module != "" && w != ioutil.Discard {
+		logRegistry.mu.Lock()
+		logRegistry.loggers[l] = struct{}{}
+		logRegistry.mu.Unlock()
+	}
+	return l
 }
 
 // closeableFile wraps an os.File to perform sanity checks on its Write and
@@ -125,13 +610,27 @@ func (cf *closeableFile) Write(b []byte) (int, error) {
 // NewFileLogger returns a logger that logs to logFilename. The file is opened
 // in append mode, and created if it does not exist.
 // If verbose is set, Debug log statements are printed as well.
+//
+// The name of the log file, without its extension, is used as the module name,
+// and the file is rotated if a maximum size is configured using SetLogConfig.
 func NewFileLogger(info types.BlockchainInfo, logFilename string, verbose bool) (*Logger, error) {
-	logFile, err := os.OpenFile(logFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
-	if err != nil {
-		return nil, err
+	cfg := currentLogConfig()
+	var w io.Writer
+	if cfg.MaxSize > 0 {
+		rf, err := newRotatingFile(logFilename, cfg.MaxSize<<20, cfg.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		w = rf
+	} else {
+		logFile, err := os.OpenFile(logFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+		if err != nil {
+			return nil, err
+		}
+		w = &closeableFile{File: logFile}
 	}
-	cf := &closeableFile{File: logFile}
-	return NewLogger(info, cf, verbose), nil
+	module := strings.TrimSuffix(filepath.Base(logFilename), filepath.Ext(logFilename))
+	return newLogger(info, w, module, verbose, cfg), nil
 }
 
 //NewDiscardLogger creates a logger on which all calls succceed but do nothing.
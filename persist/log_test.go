@@ -1,6 +1,8 @@
 package persist
 
 import (
+	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -134,6 +136,169 @@ func TestVerboseLogger(t *testing.T) {
 	validatelogfile(t, logFilename, expectedSubstring, 3)
 
 }
+
+// TestLoggerLevels checks that messages below the level of a logger are discarded,
+// and that the level of the Print methods is inferred from their prefix.
+func TestLoggerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(types.DefaultBlockchainInfo(), &buf, false)
+	l.SetLevel(LogLevelWarn)
+	buf.Reset()
+
+	l.Println("INFO: should be discarded")
+	l.Debugln("should be discarded")
+	l.Infof("should be discarded")
+	l.Println("WARN: should be written")
+	l.Printf("[ERROR] should be written")
+	l.Errorln("should be written")
+	l.Println("should be discarded as well")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %v", len(lines), lines)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "should be written") {
+			t.Error("unexpected line:", line)
+		}
+	}
+}
+
+// TestLoggerStdlibMethods checks that the methods of the standard library logger
+// are still supported by the Logger.
+func TestLoggerStdlibMethods(t *testing.T) {
+	var buf, other bytes.Buffer
+	l := NewLogger(types.DefaultBlockchainInfo(), &buf, false)
+	defer l.Close()
+
+	l.SetOutput(&other)
+	if l.Writer() != &other {
+		t.Error("unexpected writer after SetOutput")
+	}
+	l.SetFlags(0)
+	l.SetPrefix("test: ")
+	if l.Flags() != 0 || l.Prefix() != "test: " {
+		t.Errorf("unexpected flags (%d) or prefix (%q)", l.Flags(), l.Prefix())
+	}
+	buf.Reset()
+	l.Println("written to the new output")
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output to the previous writer: %q", buf.String())
+	}
+	if other.String() != "test: written to the new output\n" {
+		t.Errorf("unexpected output: %q", other.String())
+	}
+
+	// panics are logged, even when the level would discard info messages
+	l.SetLevel(LogLevelError)
+	other.Reset()
+	func() {
+		defer func() {
+			if r := recover(); r != "something went wrong" {
+				t.Errorf("unexpected panic: %v", r)
+			}
+		}()
+		l.Panicf("something %s", "went wrong")
+	}()
+	if other.String() != "test: something went wrong\n" {
+		t.Errorf("unexpected output: %q", other.String())
+	}
+}
+
+// TestLoggerJSON checks that the JSON format writes one parsable entry per message.
+func TestLoggerJSON(t *testing.T) {
+	cfg := DefaultLogConfig()
+	cfg.Format = LogFormatJSON
+	var buf bytes.Buffer
+	l := newLogger(types.DefaultBlockchainInfo(), &buf, "test", false, cfg)
+	buf.Reset()
+
+	l.Println("WARN: something happened")
+	var entry jsonLogEntry
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != LogLevelWarn {
+		t.Error("unexpected level:", entry.Level)
+	}
+	if entry.Module != "test" {
+		t.Error("unexpected module:", entry.Module)
+	}
+	if entry.Message != "something happened" {
+		t.Errorf("unexpected message: %q", entry.Message)
+	}
+	if !strings.HasPrefix(entry.Caller, "log_test.go:") {
+		t.Error("unexpected caller:", entry.Caller)
+	}
+	l.Close()
+}
+
+// TestSetModuleLogLevel checks that the level of open module loggers can be changed at runtime.
+func TestSetModuleLogLevel(t *testing.T) {
+	defer SetLogConfig(DefaultLogConfig())
+
+	var buf bytes.Buffer
+	l := NewModuleLogger(types.DefaultBlockchainInfo(), &buf, "testmodule", false)
+	if l.Level() != LogLevelInfo {
+		t.Fatal("unexpected default level:", l.Level())
+	}
+	SetModuleLogLevel("testmodule", LogLevelDebug)
+	if l.Level() != LogLevelDebug {
+		t.Fatal("level was not changed:", l.Level())
+	}
+	if lvl := ModuleLogLevels()["testmodule"]; lvl != LogLevelDebug {
+		t.Fatal("unexpected reported level:", lvl)
+	}
+	// loggers created afterwards use the module level as well
+	l2 := NewModuleLogger(types.DefaultBlockchainInfo(), &buf, "testmodule", false)
+	if l2.Level() != LogLevelDebug {
+		t.Fatal("new logger does not use the module level:", l2.Level())
+	}
+	l.Close()
+	l2.Close()
+}
+
+// TestLoggerRotation checks that a log file gets rotated once it reaches its maximum size.
+func TestLoggerRotation(t *testing.T) {
+	testdir := build.TempDir(persistDir, t.Name())
+	err := os.MkdirAll(testdir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logFilename := filepath.Join(testdir, "test.log")
+	rf, err := newRotatingFile(logFilename, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err = rf.Write([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = rf.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for filename, expected := range map[string]string{
+		logFilename:        "fourth\n",
+		logFilename + ".1": "third\n",
+		logFilename + ".2": "second\n",
+	} {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected {
+			t.Errorf("%s: expected %q, got %q", filename, expected, string(b))
+		}
+	}
+	if _, err = os.Stat(logFilename + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only 2 backups to be kept")
+	}
+}
+
 func validatelogfile(t *testing.T, logFilename string, expectedSubstrings []string, numberOfLines int) {
 	fileData, err := ioutil.ReadFile(logFilename)
 	if err != nil {
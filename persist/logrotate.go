@@ -0,0 +1,114 @@
+package persist
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/threefoldtech/rivine/build"
+)
+
+// rotatingFile is a log file which is rotated once it reaches a maximum size.
+// On rotation the file is renamed to "<name>.1", while existing backups
+// are shifted ("<name>.1" becomes "<name>.2", ...), keeping at most maxBackups.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file   *os.File
+	size   int64
+	closed bool
+	mu     sync.Mutex
+}
+
+// newRotatingFile opens (or creates) the file at the given path in append mode.
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	rf := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	err := rf.open()
+	if err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rf.file = file
+	rf.size = stat.Size()
+	return nil
+}
+
+// rotate closes the current file, shifts all backups and opens a new file.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Sync(); err != nil {
+		return err
+	}
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+	if rf.maxBackups <= 0 {
+		if err := os.Remove(rf.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return rf.open()
+	}
+	for i := rf.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", rf.path, i), fmt.Sprintf("%s.%d", rf.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(rf.path, rf.path+".1"); err != nil {
+		return err
+	}
+	return rf.open()
+}
+
+// Write takes the input data and writes it to the file,
+// rotating the file first if the data would make it exceed its maximum size.
+func (rf *rotatingFile) Write(b []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	// Sanity check - close should not have been called yet.
+	if rf.closed {
+		build.Critical("cannot write to the file after it has been closed")
+	}
+	if rf.size > 0 && rf.size+int64(len(b)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, fmt.Errorf("failed to rotate log file %s: %v", rf.path, err)
+		}
+	}
+	n, err := rf.file.Write(b)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Close closes the file and sets the closed flag.
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	// Sanity check - close should not have been called yet.
+	if rf.closed {
+		build.Critical("cannot close the file; already closed")
+	}
+
+	// Ensure that all data has actually hit the disk.
+	if err := rf.file.Sync(); err != nil {
+		return err
+	}
+	rf.closed = true
+	return rf.file.Close()
+}
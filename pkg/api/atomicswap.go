@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// AtomicSwapsGET contains all atomic swap contracts tracked by the atomic swap manager.
+	AtomicSwapsGET struct {
+		Swaps []modules.AtomicSwap `json:"swaps"`
+	}
+
+	// AtomicSwapGET contains a requested atomic swap contract tracked by the atomic swap manager.
+	AtomicSwapGET struct {
+		Swap modules.AtomicSwap `json:"swap"`
+	}
+
+	// AtomicSwapPOST contains the atomic swap contract to track,
+	// optionally defining its secret and the order it fills.
+	AtomicSwapPOST struct {
+		OutputID types.CoinOutputID      `json:"outputid"`
+		Secret   *types.AtomicSwapSecret `json:"secret,omitempty"`
+		OrderID  uint64                  `json:"orderid,omitempty"`
+	}
+
+	// AtomicSwapOrdersGET contains all orders of the atomic swap orderbook.
+	AtomicSwapOrdersGET struct {
+		Orders []modules.AtomicSwapOrder `json:"orders"`
+	}
+
+	// AtomicSwapOrderGET contains a requested order of the atomic swap orderbook.
+	AtomicSwapOrderGET struct {
+		Order modules.AtomicSwapOrder `json:"order"`
+	}
+
+	// AtomicSwapOrderPOST contains the order to add to the atomic swap orderbook.
+	AtomicSwapOrderPOST struct {
+		Amount       types.Currency   `json:"amount"`
+		Asset        string           `json:"asset"`
+		AssetAmount  string           `json:"assetamount"`
+		Counterparty types.UnlockHash `json:"counterparty"`
+	}
+)
+
+// RegisterAtomicSwapHTTPHandlers registers the default Rivine handlers for all default Rivine atomic swap manager HTTP endpoints.
+func RegisterAtomicSwapHTTPHandlers(router Router, manager modules.AtomicSwapManager, requiredPassword string) {
+	if manager == nil {
+		build.Critical("no atomic swap manager module given")
+	}
+	if router == nil {
+		build.Critical("no httprouter Router given")
+	}
+
+	router.GET("/atomicswap/swaps", RequirePasswordHandler(NewAtomicSwapsHandler(manager), requiredPassword))
+	router.POST("/atomicswap/swaps", RequirePasswordHandler(NewAtomicSwapTrackHandler(manager), requiredPassword))
+	router.GET("/atomicswap/swaps/:id", RequirePasswordHandler(NewAtomicSwapHandler(manager), requiredPassword))
+	router.GET("/atomicswap/orders", RequirePasswordHandler(NewAtomicSwapOrdersHandler(manager), requiredPassword))
+	router.POST("/atomicswap/orders", RequirePasswordHandler(NewAtomicSwapPlaceOrderHandler(manager), requiredPassword))
+	router.GET("/atomicswap/orders/:id", RequirePasswordHandler(NewAtomicSwapOrderHandler(manager), requiredPassword))
+	router.POST("/atomicswap/orders/:id/cancel", RequirePasswordHandler(NewAtomicSwapCancelOrderHandler(manager), requiredPassword))
+}
+
+// NewAtomicSwapsHandler creates a handler to handle the API calls to GET /atomicswap/swaps.
+func NewAtomicSwapsHandler(manager modules.AtomicSwapManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		swaps, err := manager.Swaps()
+		if err != nil {
+			WriteError(w, Error{"error after call to /atomicswap/swaps: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, AtomicSwapsGET{Swaps: swaps})
+	}
+}
+
+// NewAtomicSwapTrackHandler creates a handler to handle the API calls to POST /atomicswap/swaps.
+func NewAtomicSwapTrackHandler(manager modules.AtomicSwapManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body AtomicSwapPOST
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			WriteError(w, Error{"error decoding the supplied atomic swap contract: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		var swap modules.AtomicSwap
+		if body.OrderID != 0 {
+			swap, err = manager.FillOrder(body.OrderID, body.OutputID, body.Secret)
+		} else {
+			swap, err = manager.Track(body.OutputID, body.Secret)
+		}
+		if err != nil {
+			WriteError(w, Error{"error after call to /atomicswap/swaps: " + err.Error()}, atomicSwapErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, AtomicSwapGET{Swap: swap})
+	}
+}
+
+// NewAtomicSwapHandler creates a handler to handle the API calls to GET /atomicswap/swaps/:id.
+func NewAtomicSwapHandler(manager modules.AtomicSwapManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id types.CoinOutputID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			WriteError(w, Error{"invalid coin output ID: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		swap, err := manager.Swap(id)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, atomicSwapErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, AtomicSwapGET{Swap: swap})
+	}
+}
+
+// NewAtomicSwapOrdersHandler creates a handler to handle the API calls to GET /atomicswap/orders.
+func NewAtomicSwapOrdersHandler(manager modules.AtomicSwapManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		orders, err := manager.Orders()
+		if err != nil {
+			WriteError(w, Error{"error after call to /atomicswap/orders: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, AtomicSwapOrdersGET{Orders: orders})
+	}
+}
+
+// NewAtomicSwapPlaceOrderHandler creates a handler to handle the API calls to POST /atomicswap/orders.
+func NewAtomicSwapPlaceOrderHandler(manager modules.AtomicSwapManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body AtomicSwapOrderPOST
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			WriteError(w, Error{"error decoding the supplied atomic swap order: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		order, err := manager.PlaceOrder(modules.AtomicSwapOrder{
+			Amount:       body.Amount,
+			Asset:        body.Asset,
+			AssetAmount:  body.AssetAmount,
+			Counterparty: body.Counterparty,
+		})
+		if err != nil {
+			WriteError(w, Error{"error after call to /atomicswap/orders: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, AtomicSwapOrderGET{Order: order})
+	}
+}
+
+// NewAtomicSwapOrderHandler creates a handler to handle the API calls to GET /atomicswap/orders/:id.
+func NewAtomicSwapOrderHandler(manager modules.AtomicSwapManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := atomicSwapOrderIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		order, err := manager.Order(id)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, atomicSwapErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, AtomicSwapOrderGET{Order: order})
+	}
+}
+
+// NewAtomicSwapCancelOrderHandler creates a handler to handle the API calls to POST /atomicswap/orders/:id/cancel.
+func NewAtomicSwapCancelOrderHandler(manager modules.AtomicSwapManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := atomicSwapOrderIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		err := manager.CancelOrder(id)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, atomicSwapErrorToHTTPStatus(err))
+			return
+		}
+		WriteSuccess(w)
+	}
+}
+
+func atomicSwapOrderIDFromParams(w http.ResponseWriter, ps httprouter.Params) (uint64, bool) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+	if err != nil {
+		WriteError(w, Error{"invalid atomic swap order ID: " + err.Error()}, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func atomicSwapErrorToHTTPStatus(err error) int {
+	switch err {
+	case modules.ErrAtomicSwapNotTracked, modules.ErrAtomicSwapOrderNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,679 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// event types, as emitted by the /events stream
+const (
+	EventTypeBlockApplied       = "block.applied"
+	EventTypeBlockReverted      = "block.reverted"
+	EventTypeConsensusChange    = "consensus.change"
+	EventTypeTransactionAdded   = "pool.added"
+	EventTypeTransactionRemoved = "pool.removed"
+	EventTypeAddress            = "address"
+	EventTypeError              = "error"
+)
+
+// event topics, which can be subscribed to using the /events stream
+const (
+	EventTopicBlocks    = "blocks"
+	EventTopicPool      = "pool"
+	EventTopicAddresses = "addresses"
+)
+
+// address event statuses, defining the state of the transaction of an address event
+const (
+	AddressEventStatusUnconfirmed = "unconfirmed"
+	AddressEventStatusConfirmed   = "confirmed"
+	AddressEventStatusReverted    = "reverted"
+)
+
+const (
+	// maxEventStreamAddresses is the maximum amount of addresses a single event stream can watch.
+	maxEventStreamAddresses = 1000
+	// eventStreamBufferSize is the amount of event batches buffered per stream,
+	// a stream which falls behind more than this is closed.
+	eventStreamBufferSize = 1024
+	// maxEventStreamReplayChanges is the maximum amount of consensus changes a stream
+	// can catch up with when resumed from a cursor. As the consensus set is locked while
+	// catching up, it is lower than the buffer size, such that catching up never waits for the client.
+	maxEventStreamReplayChanges = 1000
+	// maxEventStreams is the maximum amount of streams which can be open at the same time.
+	maxEventStreams = 100
+	// eventStreamKeepAliveInterval is the interval at which an idle stream is kept alive.
+	eventStreamKeepAliveInterval = time.Second * 30
+)
+
+var (
+	errEventStreamTooSlow      = errors.New("event stream closed: client is not keeping up with the events")
+	errEventStreamCursorTooOld = fmt.Errorf("cursor too old: a stream can catch up with at most %d consensus changes", maxEventStreamReplayChanges)
+)
+
+type (
+	// Event is an event emitted by the /events stream,
+	// defining the object (if any) matching its type.
+	Event struct {
+		Type            string                `json:"type"`
+		Block           *EventBlock           `json:"block,omitempty"`
+		ConsensusChange *EventConsensusChange `json:"consensuschange,omitempty"`
+		Transaction     *EventTransaction     `json:"transaction,omitempty"`
+		Address         *EventAddress         `json:"address,omitempty"`
+		Error           *Error                `json:"error,omitempty"`
+	}
+
+	// EventBlock is a block applied to or reverted from the blockchain.
+	EventBlock struct {
+		ID             types.BlockID         `json:"id"`
+		ParentID       types.BlockID         `json:"parentid"`
+		Height         types.BlockHeight     `json:"height"`
+		Timestamp      types.Timestamp       `json:"timestamp"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+
+	// EventConsensusChange is emitted after all events of a consensus change,
+	// its ID can be used as the cursor to resume a stream with.
+	EventConsensusChange struct {
+		ID     crypto.Hash       `json:"id"`
+		Height types.BlockHeight `json:"height"`
+		Synced bool              `json:"synced"`
+	}
+
+	// EventTransaction is a transaction added to or removed from the transaction pool.
+	// The transaction itself is only defined for transactions added to the pool.
+	EventTransaction struct {
+		ID          types.TransactionID `json:"id"`
+		Transaction *types.Transaction  `json:"transaction,omitempty"`
+	}
+
+	// EventAddress is emitted for every transaction (or block, in case of miner payouts)
+	// which affects a watched address, when it is added to the transaction pool,
+	// when it is confirmed as part of a block, and when that block is reverted.
+	EventAddress struct {
+		Address types.UnlockHash `json:"address"`
+		Status  string           `json:"status"`
+		// TransactionID is not defined for miner payouts.
+		TransactionID *types.TransactionID `json:"transactionid,omitempty"`
+		// BlockID and Height are not defined for unconfirmed transactions.
+		BlockID *types.BlockID    `json:"blockid,omitempty"`
+		Height  types.BlockHeight `json:"height,omitempty"`
+
+		CoinsReceived       types.Currency `json:"coinsreceived"`
+		CoinsSpent          types.Currency `json:"coinsspent"`
+		BlockStakesReceived types.Currency `json:"blockstakesreceived"`
+		BlockStakesSpent    types.Currency `json:"blockstakesspent"`
+	}
+)
+
+// RegisterEventsHTTPHandlers registers the default Rivine handler for the /events stream,
+// the transaction pool is optional, and only required for pool events.
+func RegisterEventsHTTPHandlers(router Router, cs modules.ConsensusSet, tpool modules.TransactionPool) {
+	if cs == nil {
+		build.Critical("no consensus set module given")
+	}
+	if router == nil {
+		build.Critical("no httprouter Router given")
+	}
+	router.GET("/events", NewEventsHandler(cs, tpool))
+}
+
+// NewEventsHandler creates a handler to handle API calls to /events,
+// streaming consensus, transaction pool and address events as they happen.
+// Events are sent as Server-Sent Events, or as WebSocket text messages
+// in case the request is a WebSocket handshake.
+func NewEventsHandler(cs modules.ConsensusSet, tpool modules.TransactionPool) httprouter.Handle {
+	upgrader := websocket.Upgrader{}
+	var streams int32
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		q := req.URL.Query()
+
+		// parse the optional addresses to watch
+		addresses := map[types.UnlockHash]struct{}{}
+		for _, param := range q["addresses"] {
+			for _, str := range strings.Split(param, ",") {
+				if str == "" {
+					continue
+				}
+				uh, err := ScanAddress(str)
+				if err != nil {
+					WriteError(w, Error{"invalid address " + str + ": " + err.Error()}, http.StatusBadRequest)
+					return
+				}
+				addresses[uh] = struct{}{}
+			}
+		}
+		if len(addresses) > maxEventStreamAddresses {
+			WriteError(w, Error{fmt.Sprintf("cannot watch more than %d addresses", maxEventStreamAddresses)}, http.StatusBadRequest)
+			return
+		}
+
+		// parse the optional topics, all available topics are subscribed to by default
+		topics := map[string]bool{
+			EventTopicBlocks:    true,
+			EventTopicPool:      tpool != nil,
+			EventTopicAddresses: len(addresses) > 0,
+		}
+		if str := q.Get("topics"); str != "" {
+			topics = map[string]bool{}
+			for _, topic := range strings.Split(str, ",") {
+				switch topic {
+				case EventTopicBlocks:
+				case EventTopicPool:
+					if tpool == nil {
+						WriteError(w, Error{"pool events are not available: no transaction pool module"}, http.StatusBadRequest)
+						return
+					}
+				case EventTopicAddresses:
+					if len(addresses) == 0 {
+						WriteError(w, Error{"addresses events require at least one address to be given"}, http.StatusBadRequest)
+						return
+					}
+				default:
+					WriteError(w, Error{"unknown event topic " + topic}, http.StatusBadRequest)
+					return
+				}
+				topics[topic] = true
+			}
+		}
+
+		// parse the optional cursor, also accepted as the Last-Event-ID of a resumed SSE stream
+		start := modules.ConsensusChangeRecent
+		cursor := q.Get("cursor")
+		if cursor == "" {
+			cursor = req.Header.Get("Last-Event-ID")
+		}
+		if cursor != "" {
+			hash, err := ScanHash(cursor)
+			if err != nil {
+				WriteError(w, Error{"invalid cursor: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			start = modules.ConsensusChangeID(hash)
+		}
+
+		// limit the amount of open streams, as each one is subscribed to the modules
+		if atomic.AddInt32(&streams, 1) > maxEventStreams {
+			atomic.AddInt32(&streams, -1)
+			WriteError(w, Error{"too many open event streams, try again later"}, http.StatusServiceUnavailable)
+			return
+		}
+		defer atomic.AddInt32(&streams, -1)
+
+		// start the stream
+		var (
+			ew  eventWriter
+			err error
+		)
+		if websocket.IsWebSocketUpgrade(req) {
+			ew, err = newWebSocketEventWriter(upgrader, w, req)
+			if err != nil {
+				// the upgrader already replied with an HTTP error
+				return
+			}
+		} else {
+			ew, err = newSSEEventWriter(w)
+			if err != nil {
+				WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+				return
+			}
+		}
+		defer ew.Close()
+
+		sub := newEventSubscriber(cs, tpool, topics, addresses)
+		defer sub.close()
+		go sub.subscribe(start)
+
+		keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case events := <-sub.events:
+				err = ew.WriteEvents(events)
+			case <-keepAlive.C:
+				err = ew.WriteKeepAlive()
+			case <-sub.failed:
+				ew.WriteEvents([]Event{{Type: EventTypeError, Error: &Error{sub.err.Error()}}})
+				return
+			case <-ew.Done():
+				return
+			case <-req.Context().Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventSubscriber is subscribed to the consensus set and transaction pool for a single stream,
+// converting their updates into events, filtered by the topics and addresses of the stream.
+type eventSubscriber struct {
+	cs        modules.ConsensusSet
+	tpool     modules.TransactionPool
+	topics    map[string]bool
+	addresses map[types.UnlockHash]struct{}
+
+	events chan []Event
+	// replaying is set (to 1) while catching up with the consensus set,
+	// during which the amount of replayed consensus changes is counted
+	replaying int32
+	replayed  int
+
+	// the previous transaction pool set, only accessed by the transaction pool subscriber
+	pool map[types.TransactionID]struct{}
+
+	done       chan struct{}
+	subscribed chan struct{}
+	failed     chan struct{}
+	failOnce   sync.Once
+	err        error
+	// stopped is closed when the stream is closed or failed,
+	// aborting the subscription if it is still catching up
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newEventSubscriber(cs modules.ConsensusSet, tpool modules.TransactionPool, topics map[string]bool, addresses map[types.UnlockHash]struct{}) *eventSubscriber {
+	return &eventSubscriber{
+		cs:         cs,
+		tpool:      tpool,
+		topics:     topics,
+		addresses:  addresses,
+		events:     make(chan []Event, eventStreamBufferSize),
+		pool:       map[types.TransactionID]struct{}{},
+		done:       make(chan struct{}),
+		subscribed: make(chan struct{}),
+		failed:     make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// subscribe subscribes to the modules required by the topics of the stream,
+// catching up with the consensus set starting from the given consensus change.
+func (es *eventSubscriber) subscribe(start modules.ConsensusChangeID) {
+	defer close(es.subscribed)
+	if es.topics[EventTopicBlocks] || es.topics[EventTopicAddresses] {
+		atomic.StoreInt32(&es.replaying, 1)
+		err := es.cs.ConsensusSetSubscribe(es, start, es.stopped)
+		atomic.StoreInt32(&es.replaying, 0)
+		if err != nil {
+			if err == modules.ErrInvalidConsensusChangeID {
+				err = errors.New("unknown cursor: consensus change not found")
+			}
+			es.fail(err)
+			return
+		}
+	}
+	if es.tpool != nil && (es.topics[EventTopicPool] || es.topics[EventTopicAddresses]) {
+		es.tpool.TransactionPoolSubscribe(es)
+	}
+}
+
+// close stops the stream, unsubscribing from the modules it subscribed to.
+func (es *eventSubscriber) close() {
+	close(es.done)
+	es.stop()
+	<-es.subscribed
+	es.cs.Unsubscribe(es)
+	if es.tpool != nil {
+		es.tpool.Unsubscribe(es)
+	}
+}
+
+func (es *eventSubscriber) fail(err error) {
+	es.failOnce.Do(func() {
+		es.err = err
+		close(es.failed)
+	})
+	es.stop()
+}
+
+func (es *eventSubscriber) stop() {
+	es.stopOnce.Do(func() { close(es.stopped) })
+}
+
+// send the events of a single update to the stream. The stream fails immediately when its buffer is full,
+// such that a slow client cannot block the modules it subscribed to.
+func (es *eventSubscriber) send(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	select {
+	case es.events <- events:
+	case <-es.done:
+	case <-es.failed:
+	default:
+		es.fail(errEventStreamTooSlow)
+	}
+}
+
+// ProcessConsensusChange implements modules.ConsensusSetSubscriber.ProcessConsensusChange,
+// emitting the block, address and consensus change events of the consensus change.
+func (es *eventSubscriber) ProcessConsensusChange(cc modules.ConsensusChange) {
+	if atomic.LoadInt32(&es.replaying) == 1 {
+		// limit how long the consensus set is locked by catching up
+		es.replayed++
+		if es.replayed > maxEventStreamReplayChanges {
+			es.fail(errEventStreamCursorTooOld)
+			return
+		}
+	}
+	outputs := newEventOutputs(cc)
+	var (
+		events []Event
+		height types.BlockHeight
+	)
+	for _, block := range cc.RevertedBlocks {
+		height, _ = es.cs.BlockHeightOfBlock(block)
+		if es.topics[EventTopicBlocks] {
+			events = append(events, Event{Type: EventTypeBlockReverted, Block: newEventBlock(block, height)})
+		}
+		events = es.appendBlockAddressEvents(events, block, height, AddressEventStatusReverted, outputs)
+	}
+	for _, block := range cc.AppliedBlocks {
+		height, _ = es.cs.BlockHeightOfBlock(block)
+		if es.topics[EventTopicBlocks] {
+			events = append(events, Event{Type: EventTypeBlockApplied, Block: newEventBlock(block, height)})
+		}
+		events = es.appendBlockAddressEvents(events, block, height, AddressEventStatusConfirmed, outputs)
+	}
+	events = append(events, Event{
+		Type: EventTypeConsensusChange,
+		ConsensusChange: &EventConsensusChange{
+			ID:     crypto.Hash(cc.ID),
+			Height: height,
+			Synced: cc.Synced,
+		},
+	})
+	es.send(events)
+}
+
+// ReceiveUpdatedUnconfirmedTransactions implements modules.TransactionPoolSubscriber.ReceiveUpdatedUnconfirmedTransactions,
+// emitting the pool and address events for the transactions added to and removed from the transaction pool.
+func (es *eventSubscriber) ReceiveUpdatedUnconfirmedTransactions(txns []types.Transaction, cc modules.ConsensusChange) error {
+	outputs := newEventOutputs(cc)
+	var events []Event
+	pool := make(map[types.TransactionID]struct{}, len(txns))
+	for idx := range txns {
+		txn := txns[idx]
+		txid := txn.ID()
+		pool[txid] = struct{}{}
+		if _, ok := es.pool[txid]; ok {
+			continue
+		}
+		if es.topics[EventTopicPool] {
+			events = append(events, Event{
+				Type:        EventTypeTransactionAdded,
+				Transaction: &EventTransaction{ID: txid, Transaction: &txn},
+			})
+		}
+		events = es.appendTransactionAddressEvents(events, txn, nil, 0, AddressEventStatusUnconfirmed, outputs)
+	}
+	if es.topics[EventTopicPool] {
+		for txid := range es.pool {
+			if _, ok := pool[txid]; !ok {
+				events = append(events, Event{
+					Type:        EventTypeTransactionRemoved,
+					Transaction: &EventTransaction{ID: txid},
+				})
+			}
+		}
+	}
+	es.pool = pool
+	es.send(events)
+	return nil
+}
+
+func (es *eventSubscriber) appendBlockAddressEvents(events []Event, block types.Block, height types.BlockHeight, status string, outputs eventOutputs) []Event {
+	if !es.topics[EventTopicAddresses] {
+		return events
+	}
+	blockID := block.ID()
+	for _, mp := range block.MinerPayouts {
+		if _, ok := es.addresses[mp.UnlockHash]; ok {
+			events = append(events, Event{Type: EventTypeAddress, Address: &EventAddress{
+				Address:       mp.UnlockHash,
+				Status:        status,
+				BlockID:       &blockID,
+				Height:        height,
+				CoinsReceived: mp.Value,
+			}})
+		}
+	}
+	for _, txn := range block.Transactions {
+		events = es.appendTransactionAddressEvents(events, txn, &blockID, height, status, outputs)
+	}
+	return events
+}
+
+func (es *eventSubscriber) appendTransactionAddressEvents(events []Event, txn types.Transaction, blockID *types.BlockID, height types.BlockHeight, status string, outputs eventOutputs) []Event {
+	if !es.topics[EventTopicAddresses] {
+		return events
+	}
+	txid := txn.ID()
+	var (
+		order   []types.UnlockHash
+		matches = map[types.UnlockHash]*EventAddress{}
+	)
+	match := func(uh types.UnlockHash) *EventAddress {
+		if _, ok := es.addresses[uh]; !ok {
+			return nil
+		}
+		ev, ok := matches[uh]
+		if !ok {
+			ev = &EventAddress{
+				Address:       uh,
+				Status:        status,
+				TransactionID: &txid,
+				BlockID:       blockID,
+				Height:        height,
+			}
+			matches[uh] = ev
+			order = append(order, uh)
+		}
+		return ev
+	}
+	for _, ci := range txn.CoinInputs {
+		co, ok := outputs.coins[ci.ParentID]
+		if !ok {
+			continue
+		}
+		if ev := match(co.Condition.UnlockHash()); ev != nil {
+			ev.CoinsSpent = ev.CoinsSpent.Add(co.Value)
+		}
+	}
+	for _, co := range txn.CoinOutputs {
+		if ev := match(co.Condition.UnlockHash()); ev != nil {
+			ev.CoinsReceived = ev.CoinsReceived.Add(co.Value)
+		}
+	}
+	for _, bsi := range txn.BlockStakeInputs {
+		bso, ok := outputs.blockStakes[bsi.ParentID]
+		if !ok {
+			continue
+		}
+		if ev := match(bso.Condition.UnlockHash()); ev != nil {
+			ev.BlockStakesSpent = ev.BlockStakesSpent.Add(bso.Value)
+		}
+	}
+	for _, bso := range txn.BlockStakeOutputs {
+		if ev := match(bso.Condition.UnlockHash()); ev != nil {
+			ev.BlockStakesReceived = ev.BlockStakesReceived.Add(bso.Value)
+		}
+	}
+	// link the transaction to the addresses referenced by its extension data as well,
+	// as done for the address history of the explorer
+	uhs, _ := modules.ExplorerTransactionAddresses(txn)
+	for _, uh := range uhs {
+		match(uh)
+	}
+	for _, uh := range order {
+		events = append(events, Event{Type: EventTypeAddress, Address: matches[uh]})
+	}
+	return events
+}
+
+func newEventBlock(block types.Block, height types.BlockHeight) *EventBlock {
+	eb := &EventBlock{
+		ID:             block.ID(),
+		ParentID:       block.ParentID,
+		Height:         height,
+		Timestamp:      block.Timestamp,
+		TransactionIDs: make([]types.TransactionID, 0, len(block.Transactions)),
+	}
+	for _, txn := range block.Transactions {
+		eb.TransactionIDs = append(eb.TransactionIDs, txn.ID())
+	}
+	return eb
+}
+
+// eventOutputs are the outputs created and spent by a consensus change,
+// used to resolve the outputs spent by the inputs of its transactions.
+type eventOutputs struct {
+	coins       map[types.CoinOutputID]types.CoinOutput
+	blockStakes map[types.BlockStakeOutputID]types.BlockStakeOutput
+}
+
+func newEventOutputs(cc modules.ConsensusChange) eventOutputs {
+	outputs := eventOutputs{
+		coins:       make(map[types.CoinOutputID]types.CoinOutput, len(cc.CoinOutputDiffs)),
+		blockStakes: make(map[types.BlockStakeOutputID]types.BlockStakeOutput, len(cc.BlockStakeOutputDiffs)),
+	}
+	for _, diff := range cc.CoinOutputDiffs {
+		outputs.coins[diff.ID] = diff.CoinOutput
+	}
+	for _, diff := range cc.BlockStakeOutputDiffs {
+		outputs.blockStakes[diff.ID] = diff.BlockStakeOutput
+	}
+	return outputs
+}
+
+// eventWriter writes events to a stream, using a specific protocol.
+type eventWriter interface {
+	WriteEvents([]Event) error
+	WriteKeepAlive() error
+	// Done is closed when the client closed the stream.
+	Done() <-chan struct{}
+	Close() error
+}
+
+// sseEventWriter writes events as Server-Sent Events, using the consensus change ID
+// of consensus change events as the event ID, such that a resumed stream continues from there.
+type sseEventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newSSEEventWriter(w http.ResponseWriter) (*sseEventWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported by the HTTP server")
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseEventWriter{w: w, flusher: flusher}, nil
+}
+
+func (sw *sseEventWriter) WriteEvents(events []Event) error {
+	for _, event := range events {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if event.ConsensusChange != nil {
+			_, err = fmt.Fprintf(sw.w, "id: %s\n", event.ConsensusChange.ID.String())
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(sw.w, "event: %s\ndata: %s\n\n", event.Type, b)
+		if err != nil {
+			return err
+		}
+	}
+	sw.flusher.Flush()
+	return nil
+}
+
+func (sw *sseEventWriter) WriteKeepAlive() error {
+	_, err := fmt.Fprint(sw.w, ": keep-alive\n\n")
+	if err != nil {
+		return err
+	}
+	sw.flusher.Flush()
+	return nil
+}
+
+// Done implements eventWriter.Done, the request context is used
+// to detect when the client closed an SSE stream.
+func (sw *sseEventWriter) Done() <-chan struct{} { return nil }
+
+func (sw *sseEventWriter) Close() error { return nil }
+
+// webSocketEventWriter writes events as WebSocket text messages, one event per message.
+type webSocketEventWriter struct {
+	conn *websocket.Conn
+	done chan struct{}
+}
+
+func newWebSocketEventWriter(upgrader websocket.Upgrader, w http.ResponseWriter, req *http.Request) (*webSocketEventWriter, error) {
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	ww := &webSocketEventWriter{conn: conn, done: make(chan struct{})}
+	// read (and discard) the messages of the client, which is required to process
+	// the control messages of the client, and to detect when the connection is closed
+	go func() {
+		defer close(ww.done)
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ww, nil
+}
+
+func (ww *webSocketEventWriter) WriteEvents(events []Event) error {
+	for _, event := range events {
+		err := ww.conn.WriteJSON(event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ww *webSocketEventWriter) WriteKeepAlive() error {
+	return ww.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventStreamKeepAliveInterval))
+}
+
+func (ww *webSocketEventWriter) Done() <-chan struct{} { return ww.done }
+
+func (ww *webSocketEventWriter) Close() error {
+	ww.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return ww.conn.Close()
+}
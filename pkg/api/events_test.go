@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// eventsTestConsensusSet replays a fixed list of consensus changes to its subscribers.
+type eventsTestConsensusSet struct {
+	modules.ConsensusSet
+
+	mu      sync.Mutex
+	changes []modules.ConsensusChange
+	heights map[types.BlockID]types.BlockHeight
+}
+
+func (cs *eventsTestConsensusSet) ConsensusSetSubscribe(subscriber modules.ConsensusSetSubscriber, start modules.ConsensusChangeID, cancel <-chan struct{}) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var changes []modules.ConsensusChange
+	switch start {
+	case modules.ConsensusChangeBeginning:
+		changes = cs.changes
+	case modules.ConsensusChangeRecent:
+	default:
+		found := false
+		for idx, cc := range cs.changes {
+			if cc.ID == start {
+				changes, found = cs.changes[idx+1:], true
+				break
+			}
+		}
+		if !found {
+			return modules.ErrInvalidConsensusChangeID
+		}
+	}
+	for _, cc := range changes {
+		select {
+		case <-cancel:
+			return errors.New("subscription cancelled")
+		default:
+			subscriber.ProcessConsensusChange(cc)
+		}
+	}
+	return nil
+}
+
+func (cs *eventsTestConsensusSet) Unsubscribe(modules.ConsensusSetSubscriber) {}
+
+func (cs *eventsTestConsensusSet) BlockHeightOfBlock(block types.Block) (types.BlockHeight, bool) {
+	height, ok := cs.heights[block.ID()]
+	return height, ok
+}
+
+func newEventsTestConsensusSet() (*eventsTestConsensusSet, types.UnlockHash, types.UnlockHash) {
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+
+	genesis := types.Block{
+		Timestamp: 1,
+		Transactions: []types.Transaction{{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{{
+				Value:     types.NewCurrency64(100),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(alice)),
+			}},
+		}},
+	}
+	genesisOutputID := genesis.Transactions[0].CoinOutputID(0)
+	block := types.Block{
+		ParentID:  genesis.ID(),
+		Timestamp: 2,
+		MinerPayouts: []types.MinerPayout{{
+			Value:      types.NewCurrency64(10),
+			UnlockHash: bob,
+		}},
+		Transactions: []types.Transaction{{
+			Version:    types.TransactionVersionOne,
+			CoinInputs: []types.CoinInput{{ParentID: genesisOutputID}},
+			CoinOutputs: []types.CoinOutput{
+				{
+					Value:     types.NewCurrency64(60),
+					Condition: types.NewCondition(types.NewUnlockHashCondition(bob)),
+				},
+				{
+					Value:     types.NewCurrency64(39),
+					Condition: types.NewCondition(types.NewUnlockHashCondition(alice)),
+				},
+			},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}},
+	}
+	return &eventsTestConsensusSet{
+		changes: []modules.ConsensusChange{
+			{
+				ID:            modules.ConsensusChangeID(crypto.Hash{0xa}),
+				AppliedBlocks: []types.Block{genesis},
+				CoinOutputDiffs: []modules.CoinOutputDiff{{
+					Direction:  modules.DiffApply,
+					ID:         genesisOutputID,
+					CoinOutput: genesis.Transactions[0].CoinOutputs[0],
+				}},
+			},
+			{
+				ID:            modules.ConsensusChangeID(crypto.Hash{0xb}),
+				AppliedBlocks: []types.Block{block},
+				CoinOutputDiffs: []modules.CoinOutputDiff{{
+					Direction:  modules.DiffRevert,
+					ID:         genesisOutputID,
+					CoinOutput: genesis.Transactions[0].CoinOutputs[0],
+				}},
+				Synced: true,
+			},
+		},
+		heights: map[types.BlockID]types.BlockHeight{
+			genesis.ID(): 0,
+			block.ID():   1,
+		},
+	}, alice, bob
+}
+
+func TestEventSubscriberProcessConsensusChange(t *testing.T) {
+	cs, alice, bob := newEventsTestConsensusSet()
+	sub := newEventSubscriber(cs, nil, map[string]bool{
+		EventTopicBlocks:    true,
+		EventTopicAddresses: true,
+	}, map[types.UnlockHash]struct{}{alice: {}, bob: {}})
+	sub.ProcessConsensusChange(cs.changes[1])
+	events := <-sub.events
+
+	var eventTypes []string
+	for _, event := range events {
+		eventTypes = append(eventTypes, event.Type)
+	}
+	expectedTypes := []string{EventTypeBlockApplied, EventTypeAddress, EventTypeAddress, EventTypeAddress, EventTypeConsensusChange}
+	if strings.Join(eventTypes, ",") != strings.Join(expectedTypes, ",") {
+		t.Fatalf("unexpected event types: %v != %v", eventTypes, expectedTypes)
+	}
+	if events[0].Block.Height != 1 || len(events[0].Block.TransactionIDs) != 1 {
+		t.Errorf("unexpected block event: %v", *events[0].Block)
+	}
+
+	// miner payout of bob
+	payout := events[1].Address
+	if payout.Address != bob || payout.TransactionID != nil || payout.BlockID == nil ||
+		payout.Status != AddressEventStatusConfirmed || !payout.CoinsReceived.Equals64(10) {
+		t.Errorf("unexpected miner payout address event: %v", *payout)
+	}
+	// alice spends her genesis output, sending change back to herself
+	aliceEvent := events[2].Address
+	if aliceEvent.Address != alice || aliceEvent.TransactionID == nil ||
+		!aliceEvent.CoinsSpent.Equals64(100) || !aliceEvent.CoinsReceived.Equals64(39) {
+		t.Errorf("unexpected address event of alice: %v", *aliceEvent)
+	}
+	// bob receives coins from alice
+	bobEvent := events[3].Address
+	if bobEvent.Address != bob || bobEvent.Height != 1 ||
+		!bobEvent.CoinsSpent.IsZero() || !bobEvent.CoinsReceived.Equals64(60) {
+		t.Errorf("unexpected address event of bob: %v", *bobEvent)
+	}
+	change := events[4].ConsensusChange
+	if change.ID != crypto.Hash(cs.changes[1].ID) || change.Height != 1 || !change.Synced {
+		t.Errorf("unexpected consensus change event: %v", *change)
+	}
+}
+
+func TestEventSubscriberReceiveUpdatedUnconfirmedTransactions(t *testing.T) {
+	cs, _, bob := newEventsTestConsensusSet()
+	sub := newEventSubscriber(cs, nil, map[string]bool{
+		EventTopicPool:      true,
+		EventTopicAddresses: true,
+	}, map[types.UnlockHash]struct{}{bob: {}})
+	txn := cs.changes[1].AppliedBlocks[0].Transactions[0]
+
+	sub.ReceiveUpdatedUnconfirmedTransactions([]types.Transaction{txn}, modules.ConsensusChange{})
+	events := <-sub.events
+	if len(events) != 2 || events[0].Type != EventTypeTransactionAdded || events[1].Type != EventTypeAddress {
+		t.Fatalf("unexpected events for added transaction: %v", events)
+	}
+	if events[0].Transaction.ID != txn.ID() || events[0].Transaction.Transaction == nil {
+		t.Errorf("unexpected pool event: %v", *events[0].Transaction)
+	}
+	if events[1].Address.Status != AddressEventStatusUnconfirmed || events[1].Address.BlockID != nil {
+		t.Errorf("unexpected address event: %v", *events[1].Address)
+	}
+
+	// the same pool does not result in any events
+	sub.ReceiveUpdatedUnconfirmedTransactions([]types.Transaction{txn}, modules.ConsensusChange{})
+	sub.ReceiveUpdatedUnconfirmedTransactions(nil, modules.ConsensusChange{})
+	events = <-sub.events
+	if len(events) != 1 || events[0].Type != EventTypeTransactionRemoved || events[0].Transaction.ID != txn.ID() {
+		t.Fatalf("unexpected events for removed transaction: %v", events)
+	}
+}
+
+func TestEventSubscriberReplayLimit(t *testing.T) {
+	cs := &eventsTestConsensusSet{}
+	for i := 0; i <= maxEventStreamReplayChanges; i++ {
+		cs.changes = append(cs.changes, modules.ConsensusChange{ID: modules.ConsensusChangeID{0xc, byte(i >> 8), byte(i)}})
+	}
+
+	// catching up with the maximum amount of changes fits in the buffer of the stream
+	sub := newEventSubscriber(cs, nil, map[string]bool{EventTopicBlocks: true}, nil)
+	sub.subscribe(cs.changes[0].ID)
+	select {
+	case <-sub.failed:
+		t.Fatalf("unexpected failure: %v", sub.err)
+	default:
+	}
+	if len(sub.events) != maxEventStreamReplayChanges {
+		t.Errorf("unexpected amount of buffered changes: %d", len(sub.events))
+	}
+
+	// catching up with more changes fails the stream, aborting the subscription
+	sub = newEventSubscriber(cs, nil, map[string]bool{EventTopicBlocks: true}, nil)
+	sub.subscribe(modules.ConsensusChangeBeginning)
+	select {
+	case <-sub.failed:
+		if sub.err != errEventStreamCursorTooOld {
+			t.Errorf("unexpected failure: %v", sub.err)
+		}
+	default:
+		t.Fatal("expected stream to fail")
+	}
+	if len(sub.events) != maxEventStreamReplayChanges {
+		t.Errorf("unexpected amount of buffered changes: %d", len(sub.events))
+	}
+}
+
+func newEventsTestServer(cs modules.ConsensusSet) *httptest.Server {
+	router := httprouter.New()
+	RegisterEventsHTTPHandlers(router, cs, nil)
+	return httptest.NewServer(router)
+}
+
+func TestEventsHandlerSSE(t *testing.T) {
+	cs, _, _ := newEventsTestConsensusSet()
+	srv := newEventsTestServer(cs)
+	defer srv.Close()
+
+	// resume the stream after the first consensus change
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", crypto.Hash(cs.changes[0].ID).String())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+		if strings.HasPrefix(line, "data: ") && strings.Contains(line, EventTypeConsensusChange) {
+			break
+		}
+	}
+	expectedPrefixes := []string{
+		"event: " + EventTypeBlockApplied, "data: ", "",
+		"id: " + crypto.Hash(cs.changes[1].ID).String(), "event: " + EventTypeConsensusChange, "data: ",
+	}
+	if len(lines) != len(expectedPrefixes) {
+		t.Fatalf("unexpected SSE stream: %q", lines)
+	}
+	for idx, prefix := range expectedPrefixes {
+		if !strings.HasPrefix(lines[idx], prefix) {
+			t.Errorf("unexpected SSE line #%d: %q, expected prefix %q", idx, lines[idx], prefix)
+		}
+	}
+	var event Event
+	err = json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventTypeBlockApplied || event.Block == nil || event.Block.Height != 1 {
+		t.Errorf("unexpected block event: %v", event)
+	}
+}
+
+func TestEventsHandlerWebSocket(t *testing.T) {
+	cs, alice, _ := newEventsTestConsensusSet()
+	srv := newEventsTestServer(cs)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/events?topics=addresses&addresses=" + alice.String() +
+		"&cursor=" + crypto.Hash(modules.ConsensusChangeBeginning).String()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var received []Event
+	for len(received) < 4 {
+		var event Event
+		err = conn.ReadJSON(&event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, event)
+	}
+	// only address and consensus change events are sent for the addresses topic
+	expectedTypes := []string{EventTypeAddress, EventTypeConsensusChange, EventTypeAddress, EventTypeConsensusChange}
+	for idx, event := range received {
+		if event.Type != expectedTypes[idx] {
+			t.Errorf("unexpected event #%d: %v", idx, event)
+		}
+	}
+	if received[2].Address == nil || received[2].Address.Address != alice || received[2].Address.CoinsSpent.String() != "100" {
+		t.Errorf("unexpected address event: %v", received[2].Address)
+	}
+
+	// an unknown cursor results in an error event
+	conn, _, err = websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/events?cursor="+crypto.Hash{3}.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var event Event
+	err = conn.ReadJSON(&event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventTypeError || event.Error == nil {
+		t.Errorf("expected an error event, got: %v", event)
+	}
+}
+
+func TestEventsHandlerInvalidParameters(t *testing.T) {
+	cs, _, _ := newEventsTestConsensusSet()
+	srv := newEventsTestServer(cs)
+	defer srv.Close()
+
+	for _, query := range []string{
+		"topics=foo",
+		"topics=pool",      // no transaction pool
+		"topics=addresses", // no addresses
+		"addresses=foo",    // invalid address
+		"cursor=foo",       // invalid cursor
+	} {
+		resp, err := http.Get(srv.URL + "/events?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("unexpected status for query %q: %d", query, resp.StatusCode)
+		}
+	}
+}
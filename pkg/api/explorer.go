@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"sort"
@@ -54,6 +55,10 @@ type (
 		BlockStakeOutputIDs          []types.BlockStakeOutputID `json:"blockstakeoutputids"`
 		BlockStakeOutputUnlockHashes []types.UnlockHash         `json:"blockstakeunlockhashes"`
 
+		// Extension is the view on the extension data of the transaction,
+		// as exposed by the explorer extension registered for its version, if any.
+		Extension *modules.ExplorerTransactionExtensionView `json:"extension,omitempty"`
+
 		Unconfirmed bool `json:"unconfirmed"`
 	}
 
@@ -147,6 +152,13 @@ func buildExplorerTransactionWithMappedCoinOutputs(explorer modules.Explorer, he
 		et.BlockStakeOutputUnlockHashes = append(et.BlockStakeOutputUnlockHashes, bso.Condition.UnlockHash())
 	}
 
+	// Add the view on the extension data, should the txn have any.
+	var err error
+	et.Extension, err = modules.NewExplorerTransactionExtensionView(txn)
+	if err != nil {
+		build.Severe(err)
+	}
+
 	return et
 }
 
@@ -235,6 +247,15 @@ type (
 		modules.BlockFacts
 	}
 
+	// ExplorerSQLIndexerGET is the object returned as a response to a GET request to
+	// /explorer/sql, containing the status of the SQL indexer of the explorer.
+	ExplorerSQLIndexerGET struct {
+		// BlockCount is the amount of blocks exported into the database.
+		BlockCount uint64 `json:"blockcount"`
+		// Error is the error which caused the indexer to stop exporting blocks, if any.
+		Error string `json:"error,omitempty"`
+	}
+
 	// ExplorerBlockGET is the object returned by a GET request to
 	// /explorer/block.
 	ExplorerBlockGET struct {
@@ -257,9 +278,70 @@ type (
 		Transactions      []ExplorerTransaction `json:"transactions"`
 		MultiSigAddresses []types.UnlockHash    `json:"multisigaddresses"`
 		Unconfirmed       bool                  `json:"unconfirmed"`
+		// NextCursor is only defined for a paginated unlockhash request,
+		// in which case it can be used to get the next page of transactions.
+		NextCursor string `json:"nextcursor,omitempty"`
+	}
+
+	// ExplorerArbitraryDataGET is the object returned as a response to a GET request to
+	// /explorer/arbitrarydata. NextCursor is only defined if more transactions are available.
+	ExplorerArbitraryDataGET struct {
+		Transactions []ExplorerTransaction `json:"transactions"`
+		NextCursor   string                `json:"nextcursor,omitempty"`
+	}
+
+	// ExplorerSupplyStatsGET is the object returned as a response to a GET request to
+	// /explorer/stats/supply. History contains the most recent snapshots of the
+	// supply statistics, the most recent snapshot first, if requested.
+	ExplorerSupplyStatsGET struct {
+		Supply  modules.SupplyStats   `json:"supply"`
+		History []modules.SupplyStats `json:"history,omitempty"`
+	}
+
+	// ExplorerRichListGET is the object returned as a response to a GET request to
+	// /explorer/stats/richlist. Holders contains the addresses with the highest balance
+	// of the requested asset, sorted by balance, the highest balance first.
+	ExplorerRichListGET struct {
+		Height  types.BlockHeight       `json:"height"`
+		Holders []modules.AddressAmount `json:"holders"`
 	}
 )
 
+const (
+	// defaultRichListLimit is the amount of holders returned by
+	// /explorer/stats/richlist if no limit is defined.
+	defaultRichListLimit = 100
+	// maxRichListLimit is the maximum amount of holders returned by /explorer/stats/richlist.
+	maxRichListLimit = 1000
+	// maxSupplyStatsHistory is the maximum amount of snapshots returned by /explorer/stats/supply.
+	maxSupplyStatsHistory = 1000
+	// defaultArbitraryDataLimit is the amount of transactions returned by
+	// /explorer/arbitrarydata if no limit is defined.
+	defaultArbitraryDataLimit = 100
+	// maxArbitraryDataLimit is the maximum amount of transactions returned by /explorer/arbitrarydata.
+	maxArbitraryDataLimit = 1000
+)
+
+// ExplorerSQLIndexer is the SQL indexer of the explorer,
+// exporting the blockchain into a relational database.
+type ExplorerSQLIndexer interface {
+	// BlockCount returns the amount of blocks exported into the database.
+	BlockCount() (uint64, error)
+	// Err returns the error which caused the indexer to stop exporting consensus changes, if any.
+	Err() error
+}
+
+// RegisterExplorerSQLIndexerHTTPHandlers registers the handlers of the SQL indexer of the explorer.
+func RegisterExplorerSQLIndexerHTTPHandlers(router Router, indexer ExplorerSQLIndexer) {
+	if indexer == nil {
+		build.Critical("no explorer SQL indexer given")
+	}
+	if router == nil {
+		build.Critical("no httprouter Router given")
+	}
+	router.GET("/explorer/sql", NewExplorerSQLIndexerHandler(indexer))
+}
+
 // RegisterExplorerHTTPHandlers registers the default Rivine handlers for all default Rivine Explprer HTTP endpoints.
 func RegisterExplorerHTTPHandlers(router Router, cs modules.ConsensusSet, explorer modules.Explorer, tpool modules.TransactionPool) {
 	if cs == nil {
@@ -275,10 +357,16 @@ func RegisterExplorerHTTPHandlers(router Router, cs modules.ConsensusSet, explor
 	router.GET("/explorer", NewExplorerRootHandler(explorer))
 	router.GET("/explorer/blocks/:height", NewExplorerBlocksHandler(cs, explorer))
 	router.GET("/explorer/hashes/:hash", NewExplorerHashHandler(explorer, tpool))
+	router.GET("/explorer/addresses/:addr/balance", NewExplorerAddressBalanceHandler(explorer))
+	router.GET("/explorer/addresses/:addr/outputs", NewExplorerAddressOutputsHandler(explorer))
+	router.GET("/explorer/arbitrarydata", NewExplorerArbitraryDataHandler(explorer))
 	router.GET("/explorer/stats/history", NewExplorerHistoryStatsHandler(explorer))
 	router.GET("/explorer/stats/range", NewExplorerRangeStatsHandler(explorer))
+	router.GET("/explorer/stats/supply", NewExplorerSupplyStatsHandler(explorer))
+	router.GET("/explorer/stats/richlist", NewExplorerRichListHandler(explorer))
 	router.GET("/explorer/constants", NewExplorerConstantsHandler(explorer))
 	router.GET("/explorer/downloader/status", NewConsensusRootHandler(cs))
+	RegisterExplorerGraphQLHandler(router, cs, explorer)
 }
 
 // NewExplorerBlocksHandler creates a handler to handle API calls to /explorer/blocks/:height.
@@ -325,36 +413,71 @@ func NewExplorerHashHandler(explorer modules.Explorer, tpool modules.Transaction
 			// a colliding unlock hash (such a collision can only happen if done
 			// intentionally) will be unable to find their unlock hash in the
 			// blockchain through the explorer hash lookup.
-			var (
-				txns   []ExplorerTransaction
-				blocks []ExplorerBlock
-			)
-			if txids := explorer.UnlockHash(addr); len(txids) != 0 {
-				// parse the optional filters for the unlockhash request
-				var filters TransactionSetFilters
-				if str := req.FormValue("minheight"); str != "" {
-					n, err := strconv.ParseUint(str, 10, 64)
-					if err != nil {
-						WriteError(w, Error{"invalid minheight filter: " + err.Error()}, http.StatusBadRequest)
-						return
+			// parse the optional filters and pagination parameters for the unlockhash request
+			var filter modules.AddressTransactionsFilter
+			if str := req.FormValue("minheight"); str != "" {
+				n, err := strconv.ParseUint(str, 10, 64)
+				if err != nil {
+					WriteError(w, Error{"invalid minheight filter: " + err.Error()}, http.StatusBadRequest)
+					return
+				}
+				filter.MinHeight = types.BlockHeight(n)
+			}
+			if str := req.FormValue("limit"); str != "" {
+				n, err := strconv.ParseUint(str, 10, 31)
+				if err != nil {
+					WriteError(w, Error{"invalid limit: " + err.Error()}, http.StatusBadRequest)
+					return
+				}
+				filter.Limit = int(n)
+			}
+			filter.Cursor = req.FormValue("cursor")
+			switch order := req.FormValue("order"); order {
+			case "", "asc":
+			case "desc":
+				filter.Descending = true
+			default:
+				WriteError(w, Error{"invalid order, expected asc or desc: " + order}, http.StatusBadRequest)
+				return
+			}
+			filter.ArbitraryData, err = parseArbitraryDataQuery(req, "memo", "memohex", "memomatch")
+			if err != nil {
+				WriteError(w, Error{"invalid memo filter: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+
+			// build the transaction set for the (paginated) transactions for the given unlock hash,
+			// taking into account the given filters
+			txids, next, err := explorer.AddressTransactions(addr, filter)
+			if err != nil {
+				WriteError(w, Error{"failed to get transactions for address: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			txns, blocks := BuildTransactionSet(explorer, txids, TransactionSetFilters{})
+			// unconfirmed transactions are the most recent ones,
+			// and are therefore only part of either the first or last page
+			if (filter.Descending && filter.Cursor == "") || (!filter.Descending && next == "") {
+				for _, txn := range getUnconfirmedTransactions(explorer, tpool, addr) {
+					if filter.ArbitraryData == nil || filter.ArbitraryData.Matches(txn.RawTransaction.ArbitraryData) {
+						txns = append(txns, txn)
 					}
-					filters.MinBlockHeight = types.BlockHeight(n)
 				}
-				// build the transaction set for all transactions for the given unlock hash,
-				// taking into account the given filters
-				txns, blocks = BuildTransactionSet(explorer, txids, filters)
 			}
-			txns = append(txns, getUnconfirmedTransactions(explorer, tpool, addr)...)
 			multiSigAddresses := explorer.MultiSigAddresses(addr)
 			if len(txns) != 0 || len(blocks) != 0 || len(multiSigAddresses) != 0 {
 				// Sort transactions by height
-				sort.Sort(explorerTransactionsByHeight(txns))
+				if filter.Descending {
+					sort.Sort(sort.Reverse(explorerTransactionsByHeight(txns)))
+				} else {
+					sort.Sort(explorerTransactionsByHeight(txns))
+				}
 
 				WriteJSON(w, ExplorerHashGET{
 					HashType:          HashTypeUnlockHashStr,
 					Blocks:            blocks,
 					Transactions:      txns,
 					MultiSigAddresses: multiSigAddresses,
+					NextCursor:        next,
 				})
 				return
 			}
@@ -452,6 +575,93 @@ func NewExplorerHashHandler(explorer modules.Explorer, tpool modules.Transaction
 	}
 }
 
+// NewExplorerAddressBalanceHandler creates a handler to handle GET requests to /explorer/addresses/:addr/balance.
+func NewExplorerAddressBalanceHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		addr, err := ScanAddress(ps.ByName("addr"))
+		if err != nil {
+			WriteError(w, Error{"invalid address: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, explorer.AddressBalance(addr))
+	}
+}
+
+// NewExplorerAddressOutputsHandler creates a handler to handle GET requests to /explorer/addresses/:addr/outputs.
+func NewExplorerAddressOutputsHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		addr, err := ScanAddress(ps.ByName("addr"))
+		if err != nil {
+			WriteError(w, Error{"invalid address: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, explorer.AddressOutputs(addr))
+	}
+}
+
+// NewExplorerArbitraryDataHandler creates a handler to handle GET requests to /explorer/arbitrarydata.
+func NewExplorerArbitraryDataHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		query, err := parseArbitraryDataQuery(req, "text", "hex", "match")
+		if err != nil {
+			WriteError(w, Error{"invalid arbitrary data query: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if query == nil {
+			WriteError(w, Error{"no arbitrary data given, define it using the text or hex query parameter"}, http.StatusBadRequest)
+			return
+		}
+		limit := defaultArbitraryDataLimit
+		if str := req.FormValue("limit"); str != "" {
+			n, err := strconv.ParseUint(str, 10, 31)
+			if err != nil || n == 0 || n > maxArbitraryDataLimit {
+				WriteError(w, Error{fmt.Sprintf("invalid limit: should be a number in the range [1, %d]", maxArbitraryDataLimit)}, http.StatusBadRequest)
+				return
+			}
+			limit = int(n)
+		}
+		txids, next, err := explorer.ArbitraryDataTransactions(*query, req.FormValue("cursor"), limit)
+		if err != nil {
+			WriteError(w, Error{"failed to get transactions for arbitrary data: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		txns, _ := BuildTransactionSet(explorer, txids, TransactionSetFilters{})
+		WriteJSON(w, ExplorerArbitraryDataGET{
+			Transactions: txns,
+			NextCursor:   next,
+		})
+	}
+}
+
+// parseArbitraryDataQuery parses an optional arbitrary data query, given as text or hex-encoded data,
+// using the given query parameter names. Nil is returned if neither of the two is given.
+func parseArbitraryDataQuery(req *http.Request, textKey, hexKey, matchKey string) (*modules.ArbitraryDataQuery, error) {
+	var query modules.ArbitraryDataQuery
+	text, hexData := req.FormValue(textKey), req.FormValue(hexKey)
+	switch {
+	case text != "" && hexData != "":
+		return nil, fmt.Errorf("%s and %s cannot be combined", textKey, hexKey)
+	case text != "":
+		query.Data = []byte(text)
+	case hexData != "":
+		var err error
+		query.Data, err = hex.DecodeString(hexData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", hexKey, err)
+		}
+	default:
+		return nil, nil
+	}
+	switch match := req.FormValue(matchKey); match {
+	case "", "exact":
+	case "prefix":
+		query.Prefix = true
+	default:
+		return nil, fmt.Errorf("invalid %s, expected exact or prefix: %s", matchKey, match)
+	}
+	return &query, nil
+}
+
 // NewExplorerRootHandler creates a handler to handle API calls to /explorer
 func NewExplorerRootHandler(explorer modules.Explorer) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -462,6 +672,22 @@ func NewExplorerRootHandler(explorer modules.Explorer) httprouter.Handle {
 	}
 }
 
+// NewExplorerSQLIndexerHandler creates a handler to handle API calls to /explorer/sql
+func NewExplorerSQLIndexerHandler(indexer ExplorerSQLIndexer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		blockCount, err := indexer.BlockCount()
+		if err != nil {
+			WriteError(w, Error{"failed to get the SQL sync state: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		resp := ExplorerSQLIndexerGET{BlockCount: blockCount}
+		if err = indexer.Err(); err != nil {
+			resp.Error = err.Error()
+		}
+		WriteJSON(w, resp)
+	}
+}
+
 // NewExplorerConstantsHandler creates a handler to handle API calls to /explorer/constants
 func NewExplorerConstantsHandler(explorer modules.Explorer) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -489,6 +715,52 @@ func NewExplorerHistoryStatsHandler(explorer modules.Explorer) httprouter.Handle
 	}
 }
 
+// NewExplorerSupplyStatsHandler creates a handler to handle API calls to /explorer/stats/supply
+func NewExplorerSupplyStatsHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var resp ExplorerSupplyStatsGET
+		if str := req.FormValue("history"); str != "" {
+			n, err := strconv.ParseUint(str, 10, 31)
+			if err != nil || n > maxSupplyStatsHistory {
+				WriteError(w, Error{fmt.Sprintf("invalid history: should be a number in the range [0, %d]", maxSupplyStatsHistory)}, http.StatusBadRequest)
+				return
+			}
+			resp.History = explorer.SupplyStatsHistory(int(n))
+		}
+		resp.Supply = explorer.SupplyStats()
+		WriteJSON(w, resp)
+	}
+}
+
+// NewExplorerRichListHandler creates a handler to handle API calls to /explorer/stats/richlist
+func NewExplorerRichListHandler(explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		limit := defaultRichListLimit
+		if str := req.FormValue("limit"); str != "" {
+			n, err := strconv.ParseUint(str, 10, 31)
+			if err != nil || n == 0 || n > maxRichListLimit {
+				WriteError(w, Error{fmt.Sprintf("invalid limit: should be a number in the range [1, %d]", maxRichListLimit)}, http.StatusBadRequest)
+				return
+			}
+			limit = int(n)
+		}
+		var holders []modules.AddressAmount
+		switch asset := req.FormValue("asset"); asset {
+		case "", "coins":
+			holders = explorer.RichList(limit)
+		case "blockstakes":
+			holders = explorer.BlockStakeDistribution(limit)
+		default:
+			WriteError(w, Error{"invalid asset: should be coins or blockstakes, not " + asset}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, ExplorerRichListGET{
+			Height:  explorer.LatestBlockFacts().Height,
+			Holders: holders,
+		})
+	}
+}
+
 // NewExplorerRangeStatsHandler creates a handler to handle API calls to /explorer/stats/range
 func NewExplorerRangeStatsHandler(explorer modules.Explorer) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -0,0 +1,1232 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// defaultGraphQLListLimit is the amount of items returned by
+	// a paginated GraphQL list field if no limit is defined.
+	defaultGraphQLListLimit = 50
+	// maxGraphQLListLimit is the maximum amount of items returned by a paginated GraphQL list field.
+	maxGraphQLListLimit = 500
+	// defaultGraphQLListSize is the amount of items assumed to be returned
+	// by a GraphQL list field without a limit, when computing the complexity of a query.
+	defaultGraphQLListSize = 10
+
+	// maxGraphQLRequestSize is the maximum size (in bytes) of a GraphQL request body or query.
+	maxGraphQLRequestSize = 64 * 1024
+	// maxGraphQLQueryDepth is the maximum nesting depth of the fields of a GraphQL query,
+	// deep enough for the standard introspection query.
+	maxGraphQLQueryDepth = 15
+	// maxGraphQLQueryComplexity is the maximum complexity of a GraphQL query,
+	// computed as the amount of fields it resolves, taking the (maximum) size of the lists into account.
+	maxGraphQLQueryComplexity = 50000
+)
+
+type (
+	// ExplorerGraphQLRequest is the body of a POST request to /explorer/graphql.
+	ExplorerGraphQLRequest struct {
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		OperationName string                 `json:"operationName,omitempty"`
+	}
+
+	// the sources of the GraphQL object types,
+	// from which their fields are resolved
+	graphQLChain struct{}
+	graphQLBlock struct {
+		Block  types.Block
+		Height types.BlockHeight
+	}
+	graphQLTransaction struct {
+		Transaction types.Transaction
+		Block       types.Block
+		Height      types.BlockHeight
+	}
+	graphQLCoinOutput struct {
+		ID     types.CoinOutputID
+		Output types.CoinOutput
+	}
+	graphQLBlockStakeOutput struct {
+		ID     types.BlockStakeOutputID
+		Output types.BlockStakeOutput
+	}
+	graphQLAddressHistory struct {
+		Transactions []graphQLTransaction
+		Blocks       []graphQLBlock
+		NextCursor   string
+	}
+)
+
+// RegisterExplorerGraphQLHandler registers the GraphQL endpoint of the explorer,
+// exposing the chain data tracked by the explorer and consensus set using a single, nested query language.
+func RegisterExplorerGraphQLHandler(router Router, cs modules.ConsensusSet, explorer modules.Explorer) {
+	if cs == nil {
+		build.Critical("no consensus module given")
+	}
+	if explorer == nil {
+		build.Critical("no explorer module given")
+	}
+	if router == nil {
+		build.Critical("no httprouter Router given")
+	}
+	schema, err := NewExplorerGraphQLSchema(cs, explorer)
+	if err != nil {
+		build.Critical("failed to create explorer GraphQL schema: " + err.Error())
+	}
+	handler := NewExplorerGraphQLHandler(schema)
+	router.GET("/explorer/graphql", handler)
+	router.POST("/explorer/graphql", handler)
+}
+
+// NewExplorerGraphQLHandler creates a handler to handle GraphQL queries to /explorer/graphql,
+// defined as the body of a POST request, or using the query string parameters of a GET request.
+// Errors which occur while executing a valid query are returned as part of the GraphQL response.
+// Queries which are too large, too deep or too complex are rejected before they are executed.
+func NewExplorerGraphQLHandler(schema graphql.Schema) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body ExplorerGraphQLRequest
+		if req.Method == http.MethodPost {
+			err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxGraphQLRequestSize)).Decode(&body)
+			if err != nil {
+				WriteError(w, Error{"invalid GraphQL request body: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		} else {
+			body.Query = req.FormValue("query")
+			body.OperationName = req.FormValue("operationName")
+			if str := req.FormValue("variables"); str != "" {
+				err := json.Unmarshal([]byte(str), &body.Variables)
+				if err != nil {
+					WriteError(w, Error{"invalid GraphQL variables: " + err.Error()}, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		if body.Query == "" {
+			WriteError(w, Error{"no GraphQL query given"}, http.StatusBadRequest)
+			return
+		}
+		if len(body.Query) > maxGraphQLRequestSize {
+			WriteError(w, Error{fmt.Sprintf("GraphQL query exceeds the maximum size of %d bytes", maxGraphQLRequestSize)}, http.StatusBadRequest)
+			return
+		}
+		err := checkGraphQLQueryComplexity(schema, body.Query, body.Variables)
+		if err != nil {
+			WriteError(w, Error{"invalid GraphQL query: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  body.Query,
+			VariableValues: body.Variables,
+			OperationName:  body.OperationName,
+			Context:        req.Context(),
+		}))
+	}
+}
+
+// NewExplorerGraphQLSchema creates the GraphQL schema of the explorer,
+// resolving blocks, transactions, outputs, addresses, conditions and chain statistics
+// using the given explorer and consensus set.
+func NewExplorerGraphQLSchema(cs modules.ConsensusSet, explorer modules.Explorer) (graphql.Schema, error) {
+	r := graphQLResolver{cs: cs, explorer: explorer}
+
+	var (
+		blockType, transactionType, addressType, coinOutputType, blockStakeOutputType *graphql.Object
+	)
+
+	conditionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Condition",
+		Description: "An unlock condition, which has to be fulfilled in order to spend an output.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"type": conditionField(graphql.NewNonNull(graphql.Int), func(c types.UnlockConditionProxy) interface{} {
+					return int(c.ConditionType())
+				}),
+				"address": conditionField(graphql.NewNonNull(addressType), func(c types.UnlockConditionProxy) interface{} {
+					return c.UnlockHash()
+				}),
+				"json": conditionField(graphQLJSON, func(c types.UnlockConditionProxy) interface{} {
+					return c
+				}),
+			}
+		}),
+	})
+
+	blockFactsType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "BlockFacts",
+		Description: "Statistics about the blockchain as they were at a given block.",
+		Fields: graphql.Fields{
+			"blockId":   factsField(graphql.NewNonNull(graphql.String), func(f modules.BlockFacts) interface{} { return f.BlockID.String() }),
+			"height":    factsField(graphql.NewNonNull(graphql.Int), func(f modules.BlockFacts) interface{} { return int(f.Height) }),
+			"timestamp": factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return uint64(f.MaturityTimestamp) }),
+			"difficulty": factsField(graphql.NewNonNull(graphql.String), func(f modules.BlockFacts) interface{} {
+				return f.Difficulty.String()
+			}),
+			"estimatedActiveBlockStakes": factsField(graphql.NewNonNull(graphql.String), func(f modules.BlockFacts) interface{} {
+				return f.EstimatedActiveBS.String()
+			}),
+			"totalCoins":             factsField(graphql.NewNonNull(graphql.String), func(f modules.BlockFacts) interface{} { return f.TotalCoins.String() }),
+			"arbitraryDataTotalSize": factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.ArbitraryDataTotalSize }),
+			"minerPayoutCount":       factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.MinerPayoutCount }),
+			"transactionCount":       factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.TransactionCount }),
+			"coinInputCount":         factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.CoinInputCount }),
+			"coinOutputCount":        factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.CoinOutputCount }),
+			"blockStakeInputCount":   factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.BlockStakeInputCount }),
+			"blockStakeOutputCount":  factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.BlockStakeOutputCount }),
+			"minerFeeCount":          factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.MinerFeeCount }),
+			"arbitraryDataCount":     factsField(graphql.NewNonNull(graphQLUint64), func(f modules.BlockFacts) interface{} { return f.ArbitraryDataCount }),
+		},
+	})
+
+	supplyStatsType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "SupplyStats",
+		Description: "The coin and block stake supply, as it was at a given block height.",
+		Fields: graphql.Fields{
+			"height":                supplyField(graphql.NewNonNull(graphql.Int), func(s modules.SupplyStats) interface{} { return int(s.Height) }),
+			"timestamp":             supplyField(graphql.NewNonNull(graphQLUint64), func(s modules.SupplyStats) interface{} { return uint64(s.Timestamp) }),
+			"totalCoins":            supplyField(graphql.NewNonNull(graphql.String), func(s modules.SupplyStats) interface{} { return s.TotalCoins.String() }),
+			"lockedCoins":           supplyField(graphql.NewNonNull(graphql.String), func(s modules.SupplyStats) interface{} { return s.LockedCoins.String() }),
+			"circulatingCoins":      supplyField(graphql.NewNonNull(graphql.String), func(s modules.SupplyStats) interface{} { return s.CirculatingCoins.String() }),
+			"burnedCoins":           supplyField(graphql.NewNonNull(graphql.String), func(s modules.SupplyStats) interface{} { return s.BurnedCoins.String() }),
+			"totalBlockStakes":      supplyField(graphql.NewNonNull(graphql.String), func(s modules.SupplyStats) interface{} { return s.TotalBlockStakes.String() }),
+			"coinHolderCount":       supplyField(graphql.NewNonNull(graphQLUint64), func(s modules.SupplyStats) interface{} { return s.CoinHolderCount }),
+			"blockStakeHolderCount": supplyField(graphql.NewNonNull(graphQLUint64), func(s modules.SupplyStats) interface{} { return s.BlockStakeHolderCount }),
+		},
+	})
+
+	addressAmountType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AddressAmount",
+		Description: "The (confirmed) amount of coins or block stakes owned by an address.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"address": addressAmountField(graphql.NewNonNull(addressType), func(a modules.AddressAmount) interface{} { return a.Address }),
+				"amount":  addressAmountField(graphql.NewNonNull(graphql.String), func(a modules.AddressAmount) interface{} { return a.Amount.String() }),
+			}
+		}),
+	})
+
+	extensionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TransactionExtension",
+		Description: "The extension data of a transaction, as exposed by the explorer extension registered for its version.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"type": &graphql.Field{Type: graphql.String},
+				"addresses": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(addressType))),
+				},
+				"data": &graphql.Field{Type: graphQLJSON},
+			}
+		}),
+	})
+
+	coinInputType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "CoinInput",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"parentId": coinInputField(graphql.NewNonNull(graphql.String), func(ci types.CoinInput) (interface{}, error) {
+					return ci.ParentID.String(), nil
+				}),
+				"parentOutput": coinInputField(coinOutputType, func(ci types.CoinInput) (interface{}, error) {
+					return r.coinOutput(ci.ParentID), nil
+				}),
+				"fulfillment": coinInputField(graphQLJSON, func(ci types.CoinInput) (interface{}, error) {
+					return ci.Fulfillment, nil
+				}),
+			}
+		}),
+	})
+
+	blockStakeInputType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "BlockStakeInput",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"parentId": blockStakeInputField(graphql.NewNonNull(graphql.String), func(bsi types.BlockStakeInput) (interface{}, error) {
+					return bsi.ParentID.String(), nil
+				}),
+				"parentOutput": blockStakeInputField(blockStakeOutputType, func(bsi types.BlockStakeInput) (interface{}, error) {
+					return r.blockStakeOutput(bsi.ParentID), nil
+				}),
+				"fulfillment": blockStakeInputField(graphQLJSON, func(bsi types.BlockStakeInput) (interface{}, error) {
+					return bsi.Fulfillment, nil
+				}),
+			}
+		}),
+	})
+
+	coinOutputType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "CoinOutput",
+		Description: "A coin output, created by a transaction or as a miner payout of a block.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": coinOutputField(graphql.NewNonNull(graphql.String), func(co graphQLCoinOutput) (interface{}, error) {
+					return co.ID.String(), nil
+				}),
+				"value": coinOutputField(graphql.NewNonNull(graphql.String), func(co graphQLCoinOutput) (interface{}, error) {
+					return co.Output.Value.String(), nil
+				}),
+				"condition": coinOutputField(graphql.NewNonNull(conditionType), func(co graphQLCoinOutput) (interface{}, error) {
+					return co.Output.Condition, nil
+				}),
+				"address": coinOutputField(graphql.NewNonNull(addressType), func(co graphQLCoinOutput) (interface{}, error) {
+					return co.Output.Condition.UnlockHash(), nil
+				}),
+				"spentBy": &graphql.Field{
+					Type:        transactionType,
+					Description: "The transaction spending the output, null as long as it is unspent.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						co := p.Source.(graphQLCoinOutput)
+						return r.spendingTransaction(r.explorer.CoinOutputID(co.ID), func(txn types.Transaction) bool {
+							for _, ci := range txn.CoinInputs {
+								if ci.ParentID == co.ID {
+									return true
+								}
+							}
+							return false
+						}), nil
+					},
+				},
+			}
+		}),
+	})
+
+	blockStakeOutputType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "BlockStakeOutput",
+		Description: "A block stake output, created by a transaction.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": blockStakeOutputField(graphql.NewNonNull(graphql.String), func(bso graphQLBlockStakeOutput) (interface{}, error) {
+					return bso.ID.String(), nil
+				}),
+				"value": blockStakeOutputField(graphql.NewNonNull(graphql.String), func(bso graphQLBlockStakeOutput) (interface{}, error) {
+					return bso.Output.Value.String(), nil
+				}),
+				"condition": blockStakeOutputField(graphql.NewNonNull(conditionType), func(bso graphQLBlockStakeOutput) (interface{}, error) {
+					return bso.Output.Condition, nil
+				}),
+				"address": blockStakeOutputField(graphql.NewNonNull(addressType), func(bso graphQLBlockStakeOutput) (interface{}, error) {
+					return bso.Output.Condition.UnlockHash(), nil
+				}),
+				"spentBy": &graphql.Field{
+					Type:        transactionType,
+					Description: "The transaction spending the output, null as long as it is unspent.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						bso := p.Source.(graphQLBlockStakeOutput)
+						return r.spendingTransaction(r.explorer.BlockStakeOutputID(bso.ID), func(txn types.Transaction) bool {
+							for _, bsi := range txn.BlockStakeInputs {
+								if bsi.ParentID == bso.ID {
+									return true
+								}
+							}
+							return false
+						}), nil
+					},
+				},
+			}
+		}),
+	})
+
+	transactionType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Transaction",
+		Description: "A transaction, as included in a block.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": transactionField(graphql.NewNonNull(graphql.String), func(t graphQLTransaction) (interface{}, error) {
+					return t.Transaction.ID().String(), nil
+				}),
+				"version": transactionField(graphql.NewNonNull(graphql.Int), func(t graphQLTransaction) (interface{}, error) {
+					return int(t.Transaction.Version), nil
+				}),
+				"height": transactionField(graphql.NewNonNull(graphql.Int), func(t graphQLTransaction) (interface{}, error) {
+					return int(t.Height), nil
+				}),
+				"order": transactionField(graphql.NewNonNull(graphql.Int), func(t graphQLTransaction) (interface{}, error) {
+					id := t.Transaction.ID()
+					for idx, txn := range t.Block.Transactions {
+						if txn.ID() == id {
+							return idx, nil
+						}
+					}
+					return 0, nil
+				}),
+				"block": transactionField(graphql.NewNonNull(blockType), func(t graphQLTransaction) (interface{}, error) {
+					return graphQLBlock{Block: t.Block, Height: t.Height}, nil
+				}),
+				"coinInputs": transactionField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(coinInputType))), func(t graphQLTransaction) (interface{}, error) {
+					return t.Transaction.CoinInputs, nil
+				}),
+				"coinOutputs": transactionField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(coinOutputType))), func(t graphQLTransaction) (interface{}, error) {
+					outputs := make([]graphQLCoinOutput, 0, len(t.Transaction.CoinOutputs))
+					for idx, co := range t.Transaction.CoinOutputs {
+						outputs = append(outputs, graphQLCoinOutput{ID: t.Transaction.CoinOutputID(uint64(idx)), Output: co})
+					}
+					return outputs, nil
+				}),
+				"blockStakeInputs": transactionField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(blockStakeInputType))), func(t graphQLTransaction) (interface{}, error) {
+					return t.Transaction.BlockStakeInputs, nil
+				}),
+				"blockStakeOutputs": transactionField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(blockStakeOutputType))), func(t graphQLTransaction) (interface{}, error) {
+					outputs := make([]graphQLBlockStakeOutput, 0, len(t.Transaction.BlockStakeOutputs))
+					for idx, bso := range t.Transaction.BlockStakeOutputs {
+						outputs = append(outputs, graphQLBlockStakeOutput{ID: t.Transaction.BlockStakeOutputID(uint64(idx)), Output: bso})
+					}
+					return outputs, nil
+				}),
+				"minerFees": transactionField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), func(t graphQLTransaction) (interface{}, error) {
+					fees := make([]string, 0, len(t.Transaction.MinerFees))
+					for _, fee := range t.Transaction.MinerFees {
+						fees = append(fees, fee.String())
+					}
+					return fees, nil
+				}),
+				"arbitraryData": &graphql.Field{
+					Type:        graphql.String,
+					Description: "The arbitrary data of the transaction, base64-encoded (default) or as text.",
+					Args: graphql.FieldConfigArgument{
+						"text": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						data := p.Source.(graphQLTransaction).Transaction.ArbitraryData
+						if len(data) == 0 {
+							return nil, nil
+						}
+						if p.Args["text"].(bool) {
+							return string(data), nil
+						}
+						return types.ByteSlice(data).String(), nil
+					},
+				},
+				"extension": transactionField(extensionType, func(t graphQLTransaction) (interface{}, error) {
+					view, err := modules.NewExplorerTransactionExtensionView(t.Transaction)
+					if err != nil || view == nil {
+						return nil, err
+					}
+					return view, nil
+				}),
+				"raw": transactionField(graphql.NewNonNull(graphQLJSON), func(t graphQLTransaction) (interface{}, error) {
+					return t.Transaction, nil
+				}),
+			}
+		}),
+	})
+
+	blockType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Block",
+		Description: "A block of the blockchain.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": blockField(graphql.NewNonNull(graphql.String), func(b graphQLBlock) (interface{}, error) {
+					return b.Block.ID().String(), nil
+				}),
+				"height": blockField(graphql.NewNonNull(graphql.Int), func(b graphQLBlock) (interface{}, error) {
+					return int(b.Height), nil
+				}),
+				"parentId": blockField(graphql.NewNonNull(graphql.String), func(b graphQLBlock) (interface{}, error) {
+					return b.Block.ParentID.String(), nil
+				}),
+				"parent": blockField(blockType, func(b graphQLBlock) (interface{}, error) {
+					if b.Height == 0 {
+						return nil, nil
+					}
+					return r.blockAtHeight(b.Height - 1), nil
+				}),
+				"timestamp": blockField(graphql.NewNonNull(graphQLUint64), func(b graphQLBlock) (interface{}, error) {
+					return uint64(b.Block.Timestamp), nil
+				}),
+				"minerPayouts": blockField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(coinOutputType))), func(b graphQLBlock) (interface{}, error) {
+					outputs := make([]graphQLCoinOutput, 0, len(b.Block.MinerPayouts))
+					for idx, mp := range b.Block.MinerPayouts {
+						outputs = append(outputs, graphQLCoinOutput{
+							ID: b.Block.MinerPayoutID(uint64(idx)),
+							Output: types.CoinOutput{
+								Value:     mp.Value,
+								Condition: types.NewCondition(types.NewUnlockHashCondition(mp.UnlockHash)),
+							},
+						})
+					}
+					return outputs, nil
+				}),
+				"transactions": blockField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transactionType))), func(b graphQLBlock) (interface{}, error) {
+					txns := make([]graphQLTransaction, 0, len(b.Block.Transactions))
+					for _, txn := range b.Block.Transactions {
+						txns = append(txns, graphQLTransaction{Transaction: txn, Block: b.Block, Height: b.Height})
+					}
+					return txns, nil
+				}),
+				"facts": blockField(blockFactsType, func(b graphQLBlock) (interface{}, error) {
+					facts, ok := r.explorer.BlockFacts(b.Height)
+					if !ok {
+						return nil, nil
+					}
+					return facts, nil
+				}),
+			}
+		}),
+	})
+
+	unspentCoinOutputType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UnspentCoinOutput",
+		Fields: graphql.Fields{
+			"output": &graphql.Field{
+				Type: graphql.NewNonNull(coinOutputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					co := p.Source.(modules.AddressCoinOutput)
+					return graphQLCoinOutput{ID: co.ID, Output: co.CoinOutput}, nil
+				},
+			},
+			"maturityHeight": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "The height starting from which a miner payout can be spent, null for other outputs.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					co := p.Source.(modules.AddressCoinOutput)
+					if co.MaturityHeight == 0 {
+						return nil, nil
+					}
+					return int(co.MaturityHeight), nil
+				},
+			},
+			"locked": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(modules.AddressCoinOutput).Locked, nil
+				},
+			},
+		},
+	})
+
+	unspentBlockStakeOutputType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UnspentBlockStakeOutput",
+		Fields: graphql.Fields{
+			"output": &graphql.Field{
+				Type: graphql.NewNonNull(blockStakeOutputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					bso := p.Source.(modules.AddressBlockStakeOutput)
+					return graphQLBlockStakeOutput{ID: bso.ID, Output: bso.BlockStakeOutput}, nil
+				},
+			},
+			"locked": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(modules.AddressBlockStakeOutput).Locked, nil
+				},
+			},
+		},
+	})
+
+	addressBalanceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AddressBalance",
+		Description: "The confirmed, locked and unlocked balance of an address.",
+		Fields: graphql.Fields{
+			"height":                     balanceField(graphql.NewNonNull(graphql.Int), func(b modules.AddressBalance) interface{} { return int(b.Height) }),
+			"confirmedCoinBalance":       balanceField(graphql.NewNonNull(graphql.String), func(b modules.AddressBalance) interface{} { return b.ConfirmedCoinBalance.String() }),
+			"lockedCoinBalance":          balanceField(graphql.NewNonNull(graphql.String), func(b modules.AddressBalance) interface{} { return b.LockedCoinBalance.String() }),
+			"unlockedCoinBalance":        balanceField(graphql.NewNonNull(graphql.String), func(b modules.AddressBalance) interface{} { return b.UnlockedCoinBalance.String() }),
+			"confirmedBlockStakeBalance": balanceField(graphql.NewNonNull(graphql.String), func(b modules.AddressBalance) interface{} { return b.ConfirmedBlockStakeBalance.String() }),
+			"lockedBlockStakeBalance":    balanceField(graphql.NewNonNull(graphql.String), func(b modules.AddressBalance) interface{} { return b.LockedBlockStakeBalance.String() }),
+			"unlockedBlockStakeBalance":  balanceField(graphql.NewNonNull(graphql.String), func(b modules.AddressBalance) interface{} { return b.UnlockedBlockStakeBalance.String() }),
+		},
+	})
+
+	addressHistoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AddressHistory",
+		Description: "A page of the transactions and blocks (with miner payouts) linked to an address.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"transactions": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transactionType)))},
+				"blocks":       &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(blockType)))},
+				"nextCursor": &graphql.Field{
+					Type:        graphql.String,
+					Description: "The cursor to get the next page with, null if there are no more pages.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if cursor := p.Source.(graphQLAddressHistory).NextCursor; cursor != "" {
+							return cursor, nil
+						}
+						return nil, nil
+					},
+				},
+			}
+		}),
+	})
+
+	addressType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Address",
+		Description: "An address (unlock hash), owning outputs and linked to transactions.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"hash": addressField(graphql.NewNonNull(graphql.String), func(uh types.UnlockHash) interface{} {
+					return uh.String()
+				}),
+				"type": addressField(graphql.NewNonNull(graphql.Int), func(uh types.UnlockHash) interface{} {
+					return int(uh.Type)
+				}),
+				"balance": addressField(graphql.NewNonNull(addressBalanceType), func(uh types.UnlockHash) interface{} {
+					return r.explorer.AddressBalance(uh)
+				}),
+				"coinOutputs": addressField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(unspentCoinOutputType))), func(uh types.UnlockHash) interface{} {
+					return r.explorer.AddressOutputs(uh).CoinOutputs
+				}),
+				"blockStakeOutputs": addressField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(unspentBlockStakeOutputType))), func(uh types.UnlockHash) interface{} {
+					return r.explorer.AddressOutputs(uh).BlockStakeOutputs
+				}),
+				"multiSigAddresses": addressField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(addressType))), func(uh types.UnlockHash) interface{} {
+					return r.explorer.MultiSigAddresses(uh)
+				}),
+				"history": &graphql.Field{
+					Type:        graphql.NewNonNull(addressHistoryType),
+					Description: "The transactions and blocks linked to the address, sorted by block height.",
+					Args: graphql.FieldConfigArgument{
+						"limit":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLListLimit},
+						"cursor":     &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+						"minHeight":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+						"descending": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						limit, err := graphQLListLimit(p.Args)
+						if err != nil {
+							return nil, err
+						}
+						minHeight, err := graphQLHeightArg(p.Args, "minHeight")
+						if err != nil {
+							return nil, err
+						}
+						return r.addressHistory(p.Source.(types.UnlockHash), modules.AddressTransactionsFilter{
+							MinHeight:  minHeight,
+							Cursor:     p.Args["cursor"].(string),
+							Limit:      limit,
+							Descending: p.Args["descending"].(bool),
+						})
+					},
+				},
+			}
+		}),
+	})
+
+	assetType := graphql.NewEnum(graphql.EnumConfig{
+		Name: "Asset",
+		Values: graphql.EnumValueConfigMap{
+			"COINS":       &graphql.EnumValueConfig{Value: "coins"},
+			"BLOCKSTAKES": &graphql.EnumValueConfig{Value: "blockstakes"},
+		},
+	})
+
+	chainType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Chain",
+		Description: "The state of the blockchain, as tracked by the explorer.",
+		Fields: graphql.Fields{
+			"height": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(graphql.ResolveParams) (interface{}, error) {
+					return int(r.cs.Height()), nil
+				},
+			},
+			"currentBlock": &graphql.Field{
+				Type: graphql.NewNonNull(blockType),
+				Resolve: func(graphql.ResolveParams) (interface{}, error) {
+					return graphQLBlock{Block: r.cs.CurrentBlock(), Height: r.cs.Height()}, nil
+				},
+			},
+			"facts": &graphql.Field{
+				Type:        graphql.NewNonNull(blockFactsType),
+				Description: "The facts of the latest block processed by the explorer.",
+				Resolve: func(graphql.ResolveParams) (interface{}, error) {
+					return r.explorer.LatestBlockFacts(), nil
+				},
+			},
+			"supply": &graphql.Field{
+				Type: graphql.NewNonNull(supplyStatsType),
+				Resolve: func(graphql.ResolveParams) (interface{}, error) {
+					return r.explorer.SupplyStats(), nil
+				},
+			},
+			"supplyHistory": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(supplyStatsType))),
+				Description: "The most recent snapshots of the supply statistics, the most recent snapshot first.",
+				Args: graphql.FieldConfigArgument{
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLListLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, err := graphQLListLimit(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return r.explorer.SupplyStatsHistory(limit), nil
+				},
+			},
+			"richList": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(addressAmountType))),
+				Description: "The addresses with the highest balance of the given asset, the highest balance first.",
+				Args: graphql.FieldConfigArgument{
+					"asset": &graphql.ArgumentConfig{Type: assetType, DefaultValue: "coins"},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLListLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, err := graphQLListLimit(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					if p.Args["asset"] == "blockstakes" {
+						return r.explorer.BlockStakeDistribution(limit), nil
+					}
+					return r.explorer.RichList(limit), nil
+				},
+			},
+			"constants": &graphql.Field{
+				Type: graphql.NewNonNull(graphQLJSON),
+				Resolve: func(graphql.ResolveParams) (interface{}, error) {
+					return r.explorer.Constants(), nil
+				},
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"chain": &graphql.Field{
+				Type: graphql.NewNonNull(chainType),
+				Resolve: func(graphql.ResolveParams) (interface{}, error) {
+					return graphQLChain{}, nil
+				},
+			},
+			"block": &graphql.Field{
+				Type:        blockType,
+				Description: "Looks up a block by its ID or height.",
+				Args: graphql.FieldConfigArgument{
+					"id":     &graphql.ArgumentConfig{Type: graphql.String},
+					"height": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if str, ok := p.Args["id"].(string); ok {
+						hash, err := ScanHash(str)
+						if err != nil {
+							return nil, fmt.Errorf("invalid block ID: %v", err)
+						}
+						block, height, exists := r.explorer.Block(types.BlockID(hash))
+						if !exists {
+							return nil, nil
+						}
+						return graphQLBlock{Block: block, Height: height}, nil
+					}
+					if _, ok := p.Args["height"]; ok {
+						height, err := graphQLHeightArg(p.Args, "height")
+						if err != nil {
+							return nil, err
+						}
+						return r.blockAtHeight(height), nil
+					}
+					return nil, errors.New("either the id or height of the block has to be given")
+				},
+			},
+			"blocks": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(blockType))),
+				Description: "Returns consecutive blocks, starting from the given height, or the current height if descending.",
+				Args: graphql.FieldConfigArgument{
+					"fromHeight": &graphql.ArgumentConfig{Type: graphql.Int},
+					"limit":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLListLimit},
+					"descending": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, err := graphQLListLimit(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					descending := p.Args["descending"].(bool)
+					var height types.BlockHeight
+					if _, ok := p.Args["fromHeight"]; ok {
+						height, err = graphQLHeightArg(p.Args, "fromHeight")
+						if err != nil {
+							return nil, err
+						}
+					} else if descending {
+						height = r.cs.Height()
+					}
+					return r.blocks(height, limit, descending), nil
+				},
+			},
+			"transaction": &graphql.Field{
+				Type:        transactionType,
+				Description: "Looks up a (confirmed) transaction by its ID.",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, err := ScanHash(p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid transaction ID: %v", err)
+					}
+					if txn, ok := r.transaction(types.TransactionID(hash)); ok {
+						return txn, nil
+					}
+					return nil, nil
+				},
+			},
+			"address": &graphql.Field{
+				Type: addressType,
+				Args: graphql.FieldConfigArgument{
+					"hash": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					uh, err := ScanAddress(p.Args["hash"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid address: %v", err)
+					}
+					return uh, nil
+				},
+			},
+			"coinOutput": &graphql.Field{
+				Type: coinOutputType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, err := ScanHash(p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid coin output ID: %v", err)
+					}
+					return r.coinOutput(types.CoinOutputID(hash)), nil
+				},
+			},
+			"blockStakeOutput": &graphql.Field{
+				Type: blockStakeOutputType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, err := ScanHash(p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid block stake output ID: %v", err)
+					}
+					return r.blockStakeOutput(types.BlockStakeOutputID(hash)), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// graphQLResolver resolves the objects of the explorer GraphQL schema,
+// returning a nil interface for objects which do not exist.
+type graphQLResolver struct {
+	cs       modules.ConsensusSet
+	explorer modules.Explorer
+}
+
+func (r graphQLResolver) blockAtHeight(height types.BlockHeight) interface{} {
+	block, exists := r.cs.BlockAtHeight(height)
+	if !exists {
+		return nil
+	}
+	return graphQLBlock{Block: block, Height: height}
+}
+
+func (r graphQLResolver) blocks(height types.BlockHeight, limit int, descending bool) []graphQLBlock {
+	blocks := make([]graphQLBlock, 0, limit)
+	for len(blocks) < limit {
+		block, exists := r.cs.BlockAtHeight(height)
+		if !exists {
+			break
+		}
+		blocks = append(blocks, graphQLBlock{Block: block, Height: height})
+		if descending {
+			if height == 0 {
+				break
+			}
+			height--
+		} else {
+			height++
+		}
+	}
+	return blocks
+}
+
+// transaction returns the transaction with the given ID,
+// returning false if it doesn't exist or if the ID is the ID of a block.
+func (r graphQLResolver) transaction(id types.TransactionID) (graphQLTransaction, bool) {
+	block, height, exists := r.explorer.Transaction(id)
+	if !exists {
+		return graphQLTransaction{}, false
+	}
+	for _, txn := range block.Transactions {
+		if txn.ID() == id {
+			return graphQLTransaction{Transaction: txn, Block: block, Height: height}, true
+		}
+	}
+	return graphQLTransaction{}, false
+}
+
+// spendingTransaction returns the first of the given transactions
+// which spends the output, or a nil interface if none does.
+func (r graphQLResolver) spendingTransaction(txids []types.TransactionID, spends func(types.Transaction) bool) interface{} {
+	for _, txid := range txids {
+		txn, ok := r.transaction(txid)
+		if ok && spends(txn.Transaction) {
+			return txn
+		}
+	}
+	return nil
+}
+
+func (r graphQLResolver) coinOutput(id types.CoinOutputID) interface{} {
+	co, exists := r.explorer.CoinOutput(id)
+	if !exists {
+		return nil
+	}
+	return graphQLCoinOutput{ID: id, Output: co}
+}
+
+func (r graphQLResolver) blockStakeOutput(id types.BlockStakeOutputID) interface{} {
+	bso, exists := r.explorer.BlockStakeOutput(id)
+	if !exists {
+		return nil
+	}
+	return graphQLBlockStakeOutput{ID: id, Output: bso}
+}
+
+// addressHistory returns the transactions linked to an address, where the IDs which are not
+// the ID of a transaction are the IDs of blocks, linked to the address by their miner payouts.
+func (r graphQLResolver) addressHistory(uh types.UnlockHash, filter modules.AddressTransactionsFilter) (graphQLAddressHistory, error) {
+	txids, next, err := r.explorer.AddressTransactions(uh, filter)
+	if err != nil {
+		return graphQLAddressHistory{}, err
+	}
+	history := graphQLAddressHistory{
+		Transactions: []graphQLTransaction{},
+		Blocks:       []graphQLBlock{},
+		NextCursor:   next,
+	}
+	for _, txid := range txids {
+		if txn, ok := r.transaction(txid); ok {
+			history.Transactions = append(history.Transactions, txn)
+			continue
+		}
+		block, height, exists := r.explorer.Block(types.BlockID(txid))
+		if exists {
+			history.Blocks = append(history.Blocks, graphQLBlock{Block: block, Height: height})
+		}
+	}
+	return history, nil
+}
+
+// graphQLListLimit returns the limit argument of a paginated list field.
+func graphQLListLimit(args map[string]interface{}) (int, error) {
+	limit, _ := args["limit"].(int)
+	if limit <= 0 || limit > maxGraphQLListLimit {
+		return 0, fmt.Errorf("invalid limit %d, has to be in the range [1, %d]", limit, maxGraphQLListLimit)
+	}
+	return limit, nil
+}
+
+// checkGraphQLQueryComplexity returns an error if the nesting depth or complexity
+// of the given query exceeds the limits of the explorer, prior to executing it.
+// Queries which cannot be parsed are not checked, as their execution fails anyway.
+func checkGraphQLQueryComplexity(schema graphql.Schema, query string, variables map[string]interface{}) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+	c := graphQLComplexity{
+		schema:    schema,
+		variables: variables,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		visiting:  make(map[string]bool),
+	}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			c.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			err = c.selectionSet(schema.QueryType(), op.SelectionSet, 1, 1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// graphQLComplexity computes the complexity of a GraphQL query,
+// as the amount of fields it resolves: every field costs the product of the
+// sizes of the lists it is nested in. The size of a list is its limit argument,
+// or defaultGraphQLListSize for lists which cannot be limited.
+type graphQLComplexity struct {
+	schema     graphql.Schema
+	variables  map[string]interface{}
+	fragments  map[string]*ast.FragmentDefinition
+	visiting   map[string]bool
+	complexity int
+}
+
+func (c *graphQLComplexity) selectionSet(typ *graphql.Object, set *ast.SelectionSet, depth, multiplier int) error {
+	if set == nil {
+		return nil
+	}
+	for _, selection := range set.Selections {
+		var err error
+		switch selection := selection.(type) {
+		case *ast.Field:
+			err = c.field(typ, selection, depth, multiplier)
+		case *ast.InlineFragment:
+			err = c.selectionSet(c.typeCondition(typ, selection.TypeCondition), selection.SelectionSet, depth, multiplier)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := c.fragments[name]
+			if !ok || c.visiting[name] {
+				// unknown and cyclic fragments are rejected by the validation of the query
+				continue
+			}
+			c.visiting[name] = true
+			err = c.selectionSet(c.typeCondition(typ, fragment.TypeCondition), fragment.SelectionSet, depth, multiplier)
+			delete(c.visiting, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *graphQLComplexity) field(typ *graphql.Object, field *ast.Field, depth, multiplier int) error {
+	if depth > maxGraphQLQueryDepth {
+		return fmt.Errorf("query exceeds the maximum depth of %d", maxGraphQLQueryDepth)
+	}
+	c.complexity += multiplier
+	if c.complexity > maxGraphQLQueryComplexity {
+		return fmt.Errorf("query exceeds the maximum complexity of %d", maxGraphQLQueryComplexity)
+	}
+	if field.SelectionSet == nil {
+		return nil
+	}
+
+	var def *graphql.FieldDefinition
+	switch name := field.Name.Value; name {
+	case graphql.SchemaMetaFieldDef.Name:
+		def = graphql.SchemaMetaFieldDef
+	case graphql.TypeMetaFieldDef.Name:
+		def = graphql.TypeMetaFieldDef
+	default:
+		if typ != nil {
+			def = typ.Fields()[name]
+		}
+	}
+	if def == nil {
+		// unknown fields are rejected by the validation of the query,
+		// their selections are still counted, as if the field was an object
+		return c.selectionSet(nil, field.SelectionSet, depth+1, multiplier)
+	}
+
+	// unwrap the field type, multiplying the complexity of its selections with the size of every list
+	fieldType := def.Type
+	for {
+		switch t := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = t.OfType
+			continue
+		case *graphql.List:
+			multiplier *= c.listSize(def, field)
+			if multiplier > maxGraphQLQueryComplexity {
+				multiplier = maxGraphQLQueryComplexity + 1
+			}
+			fieldType = t.OfType
+			continue
+		}
+		break
+	}
+	object, _ := fieldType.(*graphql.Object)
+	return c.selectionSet(object, field.SelectionSet, depth+1, multiplier)
+}
+
+// listSize returns the (maximum) size of the list returned by the given field.
+func (c *graphQLComplexity) listSize(def *graphql.FieldDefinition, field *ast.Field) int {
+	var limitArg *graphql.Argument
+	for _, arg := range def.Args {
+		if arg.Name() == "limit" {
+			limitArg = arg
+			break
+		}
+	}
+	if limitArg == nil {
+		return defaultGraphQLListSize
+	}
+	limit, _ := limitArg.DefaultValue.(int)
+	for _, arg := range field.Arguments {
+		if arg.Name == nil || arg.Name.Value != "limit" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			limit, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch v := c.variables[value.Name.Value].(type) {
+			case int:
+				limit = v
+			case float64:
+				limit = int(v)
+			}
+		}
+	}
+	// invalid limits are rejected by the resolver of the field
+	if limit <= 0 || limit > maxGraphQLListLimit {
+		return maxGraphQLListLimit
+	}
+	return limit
+}
+
+// typeCondition returns the object type of the given type condition of a fragment,
+// or the given type if no (known) type condition is defined.
+func (c *graphQLComplexity) typeCondition(typ *graphql.Object, cond *ast.Named) *graphql.Object {
+	if cond == nil || cond.Name == nil {
+		return typ
+	}
+	if object, ok := c.schema.Type(cond.Name.Value).(*graphql.Object); ok {
+		return object
+	}
+	return typ
+}
+
+// graphQLHeightArg returns a block height argument.
+func graphQLHeightArg(args map[string]interface{}, name string) (types.BlockHeight, error) {
+	height, _ := args[name].(int)
+	if height < 0 {
+		return 0, fmt.Errorf("invalid %s %d, cannot be negative", name, height)
+	}
+	return types.BlockHeight(height), nil
+}
+
+// field constructors, resolving a field from the typed source of its object type
+
+func blockField(typ graphql.Output, resolve func(graphQLBlock) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(graphQLBlock))
+	}}
+}
+
+func transactionField(typ graphql.Output, resolve func(graphQLTransaction) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(graphQLTransaction))
+	}}
+}
+
+func coinInputField(typ graphql.Output, resolve func(types.CoinInput) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(types.CoinInput))
+	}}
+}
+
+func blockStakeInputField(typ graphql.Output, resolve func(types.BlockStakeInput) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(types.BlockStakeInput))
+	}}
+}
+
+func coinOutputField(typ graphql.Output, resolve func(graphQLCoinOutput) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(graphQLCoinOutput))
+	}}
+}
+
+func blockStakeOutputField(typ graphql.Output, resolve func(graphQLBlockStakeOutput) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(graphQLBlockStakeOutput))
+	}}
+}
+
+func conditionField(typ graphql.Output, resolve func(types.UnlockConditionProxy) interface{}) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(types.UnlockConditionProxy)), nil
+	}}
+}
+
+func addressField(typ graphql.Output, resolve func(types.UnlockHash) interface{}) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(types.UnlockHash)), nil
+	}}
+}
+
+func addressAmountField(typ graphql.Output, resolve func(modules.AddressAmount) interface{}) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(modules.AddressAmount)), nil
+	}}
+}
+
+func balanceField(typ graphql.Output, resolve func(modules.AddressBalance) interface{}) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(modules.AddressBalance)), nil
+	}}
+}
+
+func factsField(typ graphql.Output, resolve func(modules.BlockFacts) interface{}) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(modules.BlockFacts)), nil
+	}}
+}
+
+func supplyField(typ graphql.Output, resolve func(modules.SupplyStats) interface{}) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return resolve(p.Source.(modules.SupplyStats)), nil
+	}}
+}
+
+var (
+	// graphQLJSON is a scalar type for values which are returned using their JSON representation,
+	// such as conditions, fulfillments and extension data.
+	graphQLJSON = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "JSON",
+		Description: "A value using its (Rivine) JSON representation.",
+		Serialize: func(value interface{}) interface{} {
+			return value
+		},
+	})
+
+	// graphQLUint64 is a scalar type for unsigned 64-bit integers, such as timestamps and counts,
+	// which can exceed the 32-bit range of the builtin Int type.
+	graphQLUint64 = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "Uint64",
+		Description: "An unsigned 64-bit integer.",
+		Serialize: func(value interface{}) interface{} {
+			if n, ok := value.(uint64); ok {
+				return n
+			}
+			return nil
+		},
+		ParseValue: func(value interface{}) interface{} {
+			switch n := value.(type) {
+			case int:
+				if n >= 0 {
+					return uint64(n)
+				}
+			case float64:
+				if n >= 0 {
+					return uint64(n)
+				}
+			}
+			return nil
+		},
+		ParseLiteral: func(valueAST ast.Value) interface{} {
+			if v, ok := valueAST.(*ast.IntValue); ok {
+				var n uint64
+				if _, err := fmt.Sscan(v.Value, &n); err == nil {
+					return n
+				}
+			}
+			return nil
+		},
+	})
+)
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// graphQLTestChain implements the parts of the consensus set and explorer
+// used by the GraphQL schema, on top of a fixed list of blocks.
+type graphQLTestChain struct {
+	modules.ConsensusSet
+	modules.Explorer
+
+	blocks []types.Block
+}
+
+func (c *graphQLTestChain) Close() error { return nil }
+
+func (c *graphQLTestChain) Height() types.BlockHeight {
+	return types.BlockHeight(len(c.blocks) - 1)
+}
+
+func (c *graphQLTestChain) CurrentBlock() types.Block {
+	return c.blocks[len(c.blocks)-1]
+}
+
+func (c *graphQLTestChain) BlockAtHeight(height types.BlockHeight) (types.Block, bool) {
+	if int(height) >= len(c.blocks) {
+		return types.Block{}, false
+	}
+	return c.blocks[height], true
+}
+
+func (c *graphQLTestChain) Block(id types.BlockID) (types.Block, types.BlockHeight, bool) {
+	for height, block := range c.blocks {
+		if block.ID() == id {
+			return block, types.BlockHeight(height), true
+		}
+	}
+	return types.Block{}, 0, false
+}
+
+func (c *graphQLTestChain) Transaction(id types.TransactionID) (types.Block, types.BlockHeight, bool) {
+	for height, block := range c.blocks {
+		if types.TransactionID(block.ID()) == id {
+			return block, types.BlockHeight(height), true
+		}
+		for _, txn := range block.Transactions {
+			if txn.ID() == id {
+				return block, types.BlockHeight(height), true
+			}
+		}
+	}
+	return types.Block{}, 0, false
+}
+
+func (c *graphQLTestChain) CoinOutput(id types.CoinOutputID) (types.CoinOutput, bool) {
+	for _, block := range c.blocks {
+		for _, txn := range block.Transactions {
+			for idx, co := range txn.CoinOutputs {
+				if txn.CoinOutputID(uint64(idx)) == id {
+					return co, true
+				}
+			}
+		}
+	}
+	return types.CoinOutput{}, false
+}
+
+func (c *graphQLTestChain) CoinOutputID(id types.CoinOutputID) []types.TransactionID {
+	var txids []types.TransactionID
+	for _, block := range c.blocks {
+		for _, txn := range block.Transactions {
+			for idx := range txn.CoinOutputs {
+				if txn.CoinOutputID(uint64(idx)) == id {
+					txids = append(txids, txn.ID())
+				}
+			}
+			for _, ci := range txn.CoinInputs {
+				if ci.ParentID == id {
+					txids = append(txids, txn.ID())
+				}
+			}
+		}
+	}
+	return txids
+}
+
+func (c *graphQLTestChain) AddressTransactions(uh types.UnlockHash, filter modules.AddressTransactionsFilter) ([]types.TransactionID, string, error) {
+	var txids []types.TransactionID
+	for _, block := range c.blocks {
+		for _, mp := range block.MinerPayouts {
+			if mp.UnlockHash == uh {
+				txids = append(txids, types.TransactionID(block.ID()))
+			}
+		}
+		for _, txn := range block.Transactions {
+			for _, co := range txn.CoinOutputs {
+				if co.Condition.UnlockHash() == uh {
+					txids = append(txids, txn.ID())
+				}
+			}
+		}
+	}
+	return txids, "", nil
+}
+
+func newGraphQLTestChain() (*graphQLTestChain, types.UnlockHash) {
+	alice := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{1}}
+	bob := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: [32]byte{2}}
+	genesis := types.Block{
+		Timestamp: 1,
+		Transactions: []types.Transaction{{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{{
+				Value:     types.NewCurrency64(100),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(alice)),
+			}},
+		}},
+	}
+	block := types.Block{
+		ParentID:  genesis.ID(),
+		Timestamp: 2,
+		MinerPayouts: []types.MinerPayout{{
+			Value:      types.NewCurrency64(10),
+			UnlockHash: bob,
+		}},
+	}
+	txn := types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{{
+			ParentID: genesis.Transactions[0].CoinOutputID(0),
+		}},
+		CoinOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(99),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(bob)),
+		}},
+		MinerFees:     []types.Currency{types.NewCurrency64(1)},
+		ArbitraryData: []byte("hello"),
+	}
+	block.Transactions = []types.Transaction{txn}
+	return &graphQLTestChain{blocks: []types.Block{genesis, block}}, bob
+}
+
+func testGraphQLQuery(t *testing.T, schema graphql.Schema, query string, variables map[string]interface{}) map[string]interface{} {
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  query,
+		VariableValues: variables,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors for query %q: %v", query, result.Errors)
+	}
+	// marshal and unmarshal the data, such that it can be compared as it would be by a client
+	b, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data map[string]interface{}
+	err = json.Unmarshal(b, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestExplorerGraphQLSchema(t *testing.T) {
+	chain, bob := newGraphQLTestChain()
+	schema, err := NewExplorerGraphQLSchema(chain, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// resolve a block, nesting its transactions, spent outputs and their spending transaction
+	data := testGraphQLQuery(t, schema, `{
+		chain { height currentBlock { id } }
+		block(height: 1) {
+			height
+			timestamp
+			parent { height transactions { coinOutputs { value spentBy { height } } } }
+			minerPayouts { value address { hash } }
+			transactions {
+				order
+				minerFees
+				arbitraryData(text: true)
+				coinInputs { parentOutput { value condition { type } } }
+				coinOutputs { value address { hash } }
+			}
+		}
+	}`, nil)
+	chainData := data["chain"].(map[string]interface{})
+	if chainData["height"] != float64(1) {
+		t.Errorf("unexpected chain height: %v", chainData["height"])
+	}
+	if chainData["currentBlock"].(map[string]interface{})["id"] != chain.blocks[1].ID().String() {
+		t.Errorf("unexpected current block: %v", chainData["currentBlock"])
+	}
+	block := data["block"].(map[string]interface{})
+	if block["height"] != float64(1) || block["timestamp"] != float64(2) {
+		t.Errorf("unexpected block: %v", block)
+	}
+	spentBy := block["parent"].(map[string]interface{})["transactions"].([]interface{})[0].(map[string]interface{})["coinOutputs"].([]interface{})[0].(map[string]interface{})["spentBy"]
+	if spentBy == nil || spentBy.(map[string]interface{})["height"] != float64(1) {
+		t.Errorf("unexpected spending transaction of genesis output: %v", spentBy)
+	}
+	payout := block["minerPayouts"].([]interface{})[0].(map[string]interface{})
+	if payout["value"] != "10" || payout["address"].(map[string]interface{})["hash"] != bob.String() {
+		t.Errorf("unexpected miner payout: %v", payout)
+	}
+	txn := block["transactions"].([]interface{})[0].(map[string]interface{})
+	if txn["order"] != float64(0) || txn["arbitraryData"] != "hello" {
+		t.Errorf("unexpected transaction: %v", txn)
+	}
+	if fees := txn["minerFees"].([]interface{}); len(fees) != 1 || fees[0] != "1" {
+		t.Errorf("unexpected miner fees: %v", fees)
+	}
+	parentOutput := txn["coinInputs"].([]interface{})[0].(map[string]interface{})["parentOutput"].(map[string]interface{})
+	if parentOutput["value"] != "100" || parentOutput["condition"].(map[string]interface{})["type"] != float64(types.ConditionTypeUnlockHash) {
+		t.Errorf("unexpected parent output: %v", parentOutput)
+	}
+
+	// resolve the history of an address, linked to both a block (miner payout) and a transaction
+	data = testGraphQLQuery(t, schema, `query($hash: String!) {
+		address(hash: $hash) {
+			history(limit: 10) {
+				transactions { id }
+				blocks { height }
+				nextCursor
+			}
+		}
+	}`, map[string]interface{}{"hash": bob.String()})
+	history := data["address"].(map[string]interface{})["history"].(map[string]interface{})
+	if txns := history["transactions"].([]interface{}); len(txns) != 1 || txns[0].(map[string]interface{})["id"] != chain.blocks[1].Transactions[0].ID().String() {
+		t.Errorf("unexpected address transactions: %v", txns)
+	}
+	if blocks := history["blocks"].([]interface{}); len(blocks) != 1 || blocks[0].(map[string]interface{})["height"] != float64(1) {
+		t.Errorf("unexpected address blocks: %v", blocks)
+	}
+	if history["nextCursor"] != nil {
+		t.Errorf("unexpected next cursor: %v", history["nextCursor"])
+	}
+
+	// paginate blocks, in both directions
+	data = testGraphQLQuery(t, schema, `{
+		asc: blocks(limit: 5) { height }
+		desc: blocks(limit: 1, descending: true) { height }
+	}`, nil)
+	if blocks := data["asc"].([]interface{}); len(blocks) != 2 {
+		t.Errorf("unexpected ascending blocks: %v", blocks)
+	}
+	if blocks := data["desc"].([]interface{}); len(blocks) != 1 || blocks[0].(map[string]interface{})["height"] != float64(1) {
+		t.Errorf("unexpected descending blocks: %v", blocks)
+	}
+
+	// block IDs are not resolved as transactions, and unknown objects resolve to null
+	data = testGraphQLQuery(t, schema, `query($id: String!) {
+		transaction(id: $id) { id }
+		block(id: $id) { height }
+	}`, map[string]interface{}{"id": chain.blocks[1].ID().String()})
+	if data["transaction"] != nil {
+		t.Errorf("expected no transaction for a block ID, got: %v", data["transaction"])
+	}
+	if data["block"] == nil {
+		t.Error("expected a block for a block ID")
+	}
+
+	// invalid arguments are returned as errors
+	for _, query := range []string{
+		`{ blocks(limit: 0) { height } }`,
+		`{ blocks(limit: 501) { height } }`,
+		`{ block(height: -1) { height } }`,
+		`{ block { height } }`,
+		`{ address(hash: "foo") { hash } }`,
+	} {
+		result := graphql.Do(graphql.Params{Schema: schema, RequestString: query})
+		if !result.HasErrors() {
+			t.Errorf("expected an error for query %q", query)
+		}
+	}
+}
+
+func TestExplorerGraphQLHandler(t *testing.T) {
+	chain, _ := newGraphQLTestChain()
+	router := httprouter.New()
+	RegisterExplorerGraphQLHandler(router, chain, chain)
+
+	testCases := []struct {
+		Request *http.Request
+		Status  int
+	}{
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape("{ chain { height } }"), nil), http.StatusOK},
+		{httptest.NewRequest(http.MethodPost, "/explorer/graphql", strings.NewReader(`{"query":"{ chain { height } }"}`)), http.StatusOK},
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql", nil), http.StatusBadRequest},
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql?query=foo&variables=bar", nil), http.StatusBadRequest},
+		{httptest.NewRequest(http.MethodPost, "/explorer/graphql", strings.NewReader(`{`)), http.StatusBadRequest},
+		// requests which are too large, too deep or too complex are rejected before they are executed
+		{httptest.NewRequest(http.MethodPost, "/explorer/graphql", strings.NewReader(`{"query":"{ chain { height } }`+strings.Repeat(" ", maxGraphQLRequestSize)+`"}`)), http.StatusBadRequest},
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape("{ chain { height } }"+strings.Repeat(" ", maxGraphQLRequestSize)), nil), http.StatusBadRequest},
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape(
+			"{ chain { height } block(height: 1) { "+strings.Repeat("parent { ", maxGraphQLQueryDepth)+"height"+strings.Repeat(" }", maxGraphQLQueryDepth)+" } }"), nil), http.StatusBadRequest},
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape(
+			"{ chain { height } blocks(limit: 500) { transactions { coinOutputs { spentBy { coinOutputs { value } } } } } }"), nil), http.StatusBadRequest},
+		{httptest.NewRequest(http.MethodPost, "/explorer/graphql", strings.NewReader(
+			`{"query":"query($limit: Int) { chain { height } blocks(limit: $limit) { transactions { coinOutputs { spentBy { coinOutputs { value } } } } } }","variables":{"limit":500}}`)), http.StatusBadRequest},
+		// queries within the limits are executed
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape(
+			"{ chain { height } block(height: 1) { "+strings.Repeat("parent { ", maxGraphQLQueryDepth-3)+"height"+strings.Repeat(" }", maxGraphQLQueryDepth-3)+" } }"), nil), http.StatusOK},
+		{httptest.NewRequest(http.MethodGet, "/explorer/graphql?query="+url.QueryEscape(
+			"{ chain { height } blocks(limit: 5) { transactions { coinOutputs { spentBy { coinOutputs { value } } } } } }"), nil), http.StatusOK},
+	}
+	for idx, testCase := range testCases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, testCase.Request)
+		if w.Code != testCase.Status {
+			t.Errorf("test case #%d: unexpected status %d, expected %d: %s", idx, w.Code, testCase.Status, w.Body.String())
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var result struct {
+			Data struct {
+				Chain struct {
+					Height int `json:"height"`
+				} `json:"chain"`
+			} `json:"data"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		if err != nil {
+			t.Errorf("test case #%d: %v", idx, err)
+		} else if result.Data.Chain.Height != 1 {
+			t.Errorf("test case #%d: unexpected height: %d", idx, result.Data.Chain.Height)
+		}
+	}
+}
+
+func TestExplorerGraphQLQueryComplexity(t *testing.T) {
+	chain, _ := newGraphQLTestChain()
+	schema, err := NewExplorerGraphQLSchema(chain, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Query     string
+		Variables map[string]interface{}
+		Valid     bool
+	}{
+		// the standard introspection query is within the limits
+		{`query IntrospectionQuery {
+			__schema {
+				queryType { name }
+				types { ...FullType }
+				directives { name description args { ...InputValue } }
+			}
+		}
+		fragment FullType on __Type {
+			kind name description
+			fields(includeDeprecated: true) { name description args { ...InputValue } type { ...TypeRef } isDeprecated deprecationReason }
+			inputFields { ...InputValue }
+			interfaces { ...TypeRef }
+			enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+			possibleTypes { ...TypeRef }
+		}
+		fragment InputValue on __InputValue { name description type { ...TypeRef } defaultValue }
+		fragment TypeRef on __Type {
+			kind name ofType { kind name ofType { kind name ofType { kind name ofType {
+				kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+		}`, nil, true},
+		// the depth is counted through fragments
+		{`{ block(height: 1) { ...Parents } }
+		fragment Parents on Block { parent { parent { parent { parent { parent { ...Parents2 } } } } } }
+		fragment Parents2 on Block { parent { parent { parent { parent { parent { ...Parents3 } } } } } }
+		fragment Parents3 on Block { parent { parent { parent { parent { parent { height } } } } } }`, nil, false},
+		// aliased fields are counted separately
+		{`{ a: blocks(limit: 500) { transactions { coinOutputs { value } } } b: blocks(limit: 500) { transactions { coinOutputs { value } } } }`, nil, false},
+		{`{ a: blocks(limit: 200) { transactions { coinOutputs { value } } } }`, nil, true},
+		// list sizes are taken from the limit argument, its variable or its default value
+		{`query($limit: Int) { blocks(limit: $limit) { transactions { coinOutputs { spentBy { id } } } } }`, map[string]interface{}{"limit": 500}, false},
+		{`query($limit: Int) { blocks(limit: $limit) { transactions { coinOutputs { spentBy { id } } } } }`, map[string]interface{}{"limit": 10}, true},
+		{`{ blocks { transactions { coinOutputs { spentBy { coinOutputs { value } } } } } }`, nil, false},
+		// cyclic fragments and invalid queries are left to the validation of the query
+		{`{ block(height: 1) { ...A } } fragment A on Block { parent { ...A } }`, nil, true},
+		{`{ block(height: 1) {`, nil, true},
+	}
+	for idx, testCase := range testCases {
+		err := checkGraphQLQueryComplexity(schema, testCase.Query, testCase.Variables)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected query to be rejected", idx)
+		}
+	}
+
+	// the introspection query can still be executed
+	result := graphql.Do(graphql.Params{Schema: schema, RequestString: testCases[0].Query})
+	if len(result.Errors) != 0 {
+		t.Fatal(result.Errors)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/persist"
+)
+
+type (
+	// DaemonLoggingGET contains the log level of each module.
+	DaemonLoggingGET struct {
+		Levels map[string]persist.LogLevel `json:"levels"`
+	}
+)
+
+// RegisterLoggingHTTPHandlers registers the handlers which allow
+// the log levels of the modules to be inspected and changed at runtime.
+func RegisterLoggingHTTPHandlers(router Router, requiredPassword string) {
+	if router == nil {
+		build.Critical("no httprouter Router given")
+	}
+	router.GET("/daemon/logging", NewDaemonLoggingRootHandler())
+	router.POST("/daemon/logging", RequirePasswordHandler(NewDaemonLoggingSetLevelHandler(), requiredPassword))
+}
+
+// NewDaemonLoggingRootHandler creates a handler to handle the API call asking for the log level of each module.
+func NewDaemonLoggingRootHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		WriteJSON(w, DaemonLoggingGET{
+			Levels: persist.ModuleLogLevels(),
+		})
+	}
+}
+
+// NewDaemonLoggingSetLevelHandler creates a handler to handle the API call changing the log level of a module.
+func NewDaemonLoggingSetLevelHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		module := req.FormValue("module")
+		if module == "" {
+			WriteError(w, Error{"no module given"}, http.StatusBadRequest)
+			return
+		}
+		var lvl persist.LogLevel
+		err := lvl.LoadString(req.FormValue("level"))
+		if err != nil {
+			WriteError(w, Error{"invalid log level: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		persist.SetModuleLogLevel(module, lvl)
+		WriteSuccess(w)
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/pkg/metrics"
+)
+
+// RegisterMetricsHTTPHandlers registers the handler which exposes all metrics of the given registry,
+// in the Prometheus text exposition format, on the "/metrics" endpoint.
+func RegisterMetricsHTTPHandlers(router Router, reg *metrics.Registry, requiredPassword string) {
+	if reg == nil {
+		build.Critical("no metrics registry given")
+	}
+	if router == nil {
+		build.Critical("no httprouter Router given")
+	}
+	router.GET("/metrics", RequirePasswordHandler(NewMetricsHandler(reg), requiredPassword))
+}
+
+// NewMetricsHandler creates a handler to handle the API call asking for all metrics of the daemon.
+func NewMetricsHandler(reg *metrics.Registry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		reg.ServeHTTP(w, req)
+	}
+}
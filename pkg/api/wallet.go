@@ -198,6 +198,13 @@ type (
 	WalletPublicKeyGET struct {
 		PublicKey types.PublicKey `json:"publickey"`
 	}
+
+	// WalletSignPSTPOSTResp contains the partially signed transaction
+	// returned by a POST call to /wallet/sign/pst, as well as the amount of signatures added.
+	WalletSignPSTPOSTResp struct {
+		PST    types.PartiallySignedTransaction `json:"pst"`
+		Signed int                              `json:"signed"`
+	}
 )
 
 // RegisterWalletHTTPHandlers registers the default Rivine handlers for all default Rivine Wallet HTTP endpoints.
@@ -230,6 +237,7 @@ func RegisterWalletHTTPHandlers(router Router, wallet modules.Wallet, requiredPa
 	router.GET("/wallet/locked", RequirePasswordHandler(NewWalletListLockedHandler(wallet), requiredPassword))
 	router.POST("/wallet/create/transaction", RequirePasswordHandler(NewWalletCreateTransactionHandler(wallet), requiredPassword))
 	router.POST("/wallet/sign", RequirePasswordHandler(NewWalletSignHandler(wallet), requiredPassword))
+	router.POST("/wallet/sign/pst", RequirePasswordHandler(NewWalletSignPSTHandler(wallet), requiredPassword))
 	router.GET("/wallet/publickey", RequirePasswordHandler(NewWalletGetPublicKeyHandler(wallet), requiredPassword))
 	router.GET("/wallet/fund/coins", RequirePasswordHandler(NewWalletFundCoinsHandler(wallet), requiredPassword))
 }
@@ -325,9 +333,36 @@ func NewWalletBlockStakeStatsHandler(wallet modules.Wallet) httprouter.Handle {
 }
 
 // NewWalletAddressHandler creates a handler to handle API calls to /wallet/address.
+// An address linked to a secp256k1 key, rather than an ed25519 key,
+// can be requested using the optional algorithm query parameter,
+// once the secp256k1 signature algorithm has been activated.
 func NewWalletAddressHandler(wallet modules.Wallet) httprouter.Handle {
 	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-		unlockHash, err := wallet.NextAddress()
+		var algorithm types.SignatureAlgoType
+		if str := req.FormValue("algorithm"); str == "" {
+			algorithm = types.SignatureAlgoEd25519
+		} else if err := algorithm.LoadString(str); err != nil {
+			WriteError(w, Error{"error after call to /wallet/address: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		var (
+			unlockHash types.UnlockHash
+			err        error
+		)
+		switch algorithm {
+		case types.SignatureAlgoEd25519:
+			unlockHash, err = wallet.NextAddress()
+		case types.SignatureAlgoSecp256k1:
+			if !types.IsSecp256k1SignatureAlgorithmEnabled() {
+				WriteError(w, Error{"error after call to /wallet/address: signature algorithm " + algorithm.String() + " is not enabled"}, http.StatusBadRequest)
+				return
+			}
+			// the wallet returns a client error in case the algorithm is not yet activated
+			unlockHash, err = wallet.NextSecp256k1Address()
+		default:
+			WriteError(w, Error{"error after call to /wallet/address: unsupported signature algorithm " + algorithm.String()}, http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			WriteError(w, Error{"error after call to /wallet/addresses: " + err.Error()}, walletErrorToHTTPStatus(err))
 			return
@@ -803,6 +838,27 @@ func NewWalletSignHandler(wallet modules.Wallet) httprouter.Handle {
 	}
 }
 
+// NewWalletSignPSTHandler creates a handler to handle API calls to POST /wallet/sign/pst
+func NewWalletSignPSTHandler(wallet modules.Wallet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body types.PartiallySignedTransaction
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			WriteError(w, Error{"error decoding the supplied partially signed transaction: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err := body.Validate(); err != nil {
+			WriteError(w, Error{"invalid partially signed transaction: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		signed, err := wallet.SignPST(&body)
+		if err != nil {
+			WriteError(w, Error{"error after call to /wallet/sign/pst: " + err.Error()}, walletErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, WalletSignPSTPOSTResp{PST: body, Signed: signed})
+	}
+}
+
 // NewWalletFundCoinsHandler creates a handler to handle the API calls to /wallet/fund/coins?amount=.
 // While it might be handy for other use cases, it is needed for 3bot registration
 func NewWalletFundCoinsHandler(wallet modules.Wallet) httprouter.Handle {
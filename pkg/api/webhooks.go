@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// WebhooksGET contains all webhooks registered with the webhook manager.
+	WebhooksGET struct {
+		Webhooks []modules.Webhook `json:"webhooks"`
+	}
+
+	// WebhookGET contains a requested webhook registered with the webhook manager.
+	WebhookGET struct {
+		Webhook modules.Webhook `json:"webhook"`
+	}
+
+	// WebhookPOST contains the webhook to register,
+	// optionally defining the amount of confirmations it requires.
+	WebhookPOST struct {
+		URL           string             `json:"url"`
+		Addresses     []types.UnlockHash `json:"addresses"`
+		Confirmations types.BlockHeight  `json:"confirmations,omitempty"`
+	}
+
+	// WebhookDeliveriesGET contains the queued deliveries of a requested webhook.
+	WebhookDeliveriesGET struct {
+		Deliveries []modules.WebhookDelivery `json:"deliveries"`
+	}
+)
+
+// RegisterWebhooksHTTPHandlers registers the default Rivine handlers for all default Rivine webhook manager HTTP endpoints.
+func RegisterWebhooksHTTPHandlers(router Router, manager modules.WebhookManager, requiredPassword string) {
+	if manager == nil {
+		build.Critical("no webhook manager module given")
+	}
+	if router == nil {
+		build.Critical("no httprouter Router given")
+	}
+
+	router.GET("/webhooks", RequirePasswordHandler(NewWebhooksHandler(manager), requiredPassword))
+	router.POST("/webhooks", RequirePasswordHandler(NewWebhookRegisterHandler(manager), requiredPassword))
+	router.GET("/webhooks/:id", RequirePasswordHandler(NewWebhookHandler(manager), requiredPassword))
+	router.GET("/webhooks/:id/deliveries", RequirePasswordHandler(NewWebhookDeliveriesHandler(manager), requiredPassword))
+	router.POST("/webhooks/:id/remove", RequirePasswordHandler(NewWebhookRemoveHandler(manager), requiredPassword))
+}
+
+// NewWebhooksHandler creates a handler to handle the API calls to GET /webhooks.
+func NewWebhooksHandler(manager modules.WebhookManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		webhooks, err := manager.Webhooks()
+		if err != nil {
+			WriteError(w, Error{"error after call to /webhooks: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, WebhooksGET{Webhooks: webhooks})
+	}
+}
+
+// NewWebhookRegisterHandler creates a handler to handle the API calls to POST /webhooks.
+func NewWebhookRegisterHandler(manager modules.WebhookManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var body WebhookPOST
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			WriteError(w, Error{"error decoding the supplied webhook: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		webhook, err := manager.RegisterWebhook(body.URL, body.Addresses, body.Confirmations)
+		if err != nil {
+			WriteError(w, Error{"error after call to /webhooks: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, WebhookGET{Webhook: webhook})
+	}
+}
+
+// NewWebhookHandler creates a handler to handle the API calls to GET /webhooks/:id.
+func NewWebhookHandler(manager modules.WebhookManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := webhookIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		webhook, err := manager.Webhook(id)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, webhookErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, WebhookGET{Webhook: webhook})
+	}
+}
+
+// NewWebhookDeliveriesHandler creates a handler to handle the API calls to GET /webhooks/:id/deliveries.
+func NewWebhookDeliveriesHandler(manager modules.WebhookManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := webhookIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		deliveries, err := manager.WebhookDeliveries(id)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, webhookErrorToHTTPStatus(err))
+			return
+		}
+		WriteJSON(w, WebhookDeliveriesGET{Deliveries: deliveries})
+	}
+}
+
+// NewWebhookRemoveHandler creates a handler to handle the API calls to POST /webhooks/:id/remove.
+func NewWebhookRemoveHandler(manager modules.WebhookManager) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		id, ok := webhookIDFromParams(w, ps)
+		if !ok {
+			return
+		}
+		err := manager.RemoveWebhook(id)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, webhookErrorToHTTPStatus(err))
+			return
+		}
+		WriteSuccess(w)
+	}
+}
+
+func webhookIDFromParams(w http.ResponseWriter, ps httprouter.Params) (uint64, bool) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+	if err != nil {
+		WriteError(w, Error{"invalid webhook ID: " + err.Error()}, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func webhookErrorToHTTPStatus(err error) int {
+	switch err {
+	case modules.ErrWebhookNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
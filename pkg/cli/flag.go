@@ -3,6 +3,7 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/threefoldtech/rivine/build"
 	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
 )
 
 const (
@@ -317,6 +319,56 @@ func (flag *netAddressArray) String() string {
 	return str[:len(str)-1]
 }
 
+// ModuleLogLevelsFlagVar defines a map[string]persist.LogLevel flag with specified name and usage string.
+// The argument m points to a map[string]persist.LogLevel variable in which to store the validated values of the flags.
+// Each value has the format `<module>=<level>`, multiple values can be defined comma-separated,
+// or as separate flags (using the same name).
+func ModuleLogLevelsFlagVar(f *pflag.FlagSet, m *map[string]persist.LogLevel, name string, usage string) {
+	f.Var(&moduleLogLevels{levels: m}, name, usage)
+}
+
+type moduleLogLevels struct {
+	levels *map[string]persist.LogLevel
+}
+
+// Set implements pflag.Value.Set
+func (flag *moduleLogLevels) Set(val string) error {
+	if *flag.levels == nil {
+		*flag.levels = make(map[string]persist.LogLevel)
+	}
+	for _, pair := range strings.Split(val, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid module log level %q: expected format <module>=<level>", pair)
+		}
+		var lvl persist.LogLevel
+		err := lvl.LoadString(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid log level for module %s: %v", parts[0], err)
+		}
+		(*flag.levels)[parts[0]] = lvl
+	}
+	return nil
+}
+
+// Type implements pflag.Value.Type
+func (flag *moduleLogLevels) Type() string {
+	return "ModuleLogLevels"
+}
+
+// String implements pflag.Value.String
+func (flag *moduleLogLevels) String() string {
+	if flag.levels == nil || len(*flag.levels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(*flag.levels))
+	for module, lvl := range *flag.levels {
+		pairs = append(pairs, module+"="+lvl.String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
 var computeTimeNow = func() time.Time {
 	return time.Now()
 }
@@ -267,6 +267,7 @@ func createAtomicSwapCmd(client *CommandLineClient) *cobra.Command {
 		redeemCmd,
 		refundCmd,
 	)
+	addAtomicSwapManagerCmds(rootCmd, client)
 
 	// create flags
 	rootCmd.PersistentFlags().BoolVarP(&atomicSwapCmd.rootCfg.YesToAll, "yes", "y", false,
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// addAtomicSwapManagerCmds adds the commands which interact with
+// the atomic swap manager module of the daemon to the given atomic swap root command.
+func addAtomicSwapManagerCmds(rootCmd *cobra.Command, client *CommandLineClient) {
+	managerCmd := &atomicSwapManagerCmd{cli: client}
+
+	var (
+		trackCmd = &cobra.Command{
+			Use:   "track outputid",
+			Short: "Track an atomic swap contract using the atomic swap manager of the daemon.",
+			Long: `Track an atomic swap contract using the atomic swap manager of the daemon,
+such that it is redeemed as soon as its secret is known, or refunded as soon as
+its time lock has been reached. The wallet of the daemon has to own either
+the sender or the receiver address of the contract.
+
+Optionally the secret of the contract can be given, as well as the ID of the
+order of the local orderbook filled by the contract.`,
+			Run: Wrap(managerCmd.trackCmd),
+		}
+
+		swapsCmd = &cobra.Command{
+			Use:   "swaps [outputid]",
+			Short: "List the atomic swap contracts tracked by the atomic swap manager of the daemon.",
+			Long: `List the atomic swap contracts tracked by the atomic swap manager of the daemon,
+or only the tracked contract of the given output ID, if an output ID is given.`,
+			Args: cobra.RangeArgs(0, 1),
+			Run:  managerCmd.swapsCmd,
+		}
+
+		ordersCmd = &cobra.Command{
+			Use:   "orders [id]",
+			Short: "List the orders of the local atomic swap orderbook.",
+			Long: `List the orders of the local atomic swap orderbook,
+or only the order of the given ID, if an ID is given.`,
+			Args: cobra.RangeArgs(0, 1),
+			Run:  managerCmd.ordersCmd,
+		}
+
+		placeOrderCmd = &cobra.Command{
+			Use:   "placeorder amount asset assetamount",
+			Short: "Place an order in the local atomic swap orderbook.",
+			Long: `Place an order in the local atomic swap orderbook,
+offering the given amount of coins in exchange for the given amount of another asset.
+The asset and its amount are free-form, as they live on another chain.`,
+			Run: Wrap(managerCmd.placeOrderCmd),
+		}
+
+		cancelOrderCmd = &cobra.Command{
+			Use:   "cancelorder id",
+			Short: "Cancel an open order of the local atomic swap orderbook.",
+			Run:   Wrap(managerCmd.cancelOrderCmd),
+		}
+	)
+	rootCmd.AddCommand(
+		trackCmd,
+		swapsCmd,
+		ordersCmd,
+		placeOrderCmd,
+		cancelOrderCmd,
+	)
+
+	trackCmd.Flags().Var(
+		cli.StringLoaderFlag{StringLoader: &managerCmd.trackCfg.Secret}, "secret",
+		"optionally define the secret of the atomic swap contract")
+	trackCmd.Flags().Uint64Var(
+		&managerCmd.trackCfg.OrderID, "order", 0,
+		"optionally define the ID of the order filled by the atomic swap contract")
+	placeOrderCmd.Flags().Var(
+		cli.StringLoaderFlag{StringLoader: &managerCmd.placeOrderCfg.Counterparty}, "counterparty",
+		"optionally define the address of the counterparty of the order")
+}
+
+type atomicSwapManagerCmd struct {
+	cli *CommandLineClient
+
+	trackCfg struct {
+		Secret  types.AtomicSwapSecret
+		OrderID uint64
+	}
+	placeOrderCfg struct {
+		Counterparty types.UnlockHash
+	}
+}
+
+func (managerCmd *atomicSwapManagerCmd) trackCmd(outputIDStr string) {
+	body := api.AtomicSwapPOST{
+		OrderID: managerCmd.trackCfg.OrderID,
+	}
+	err := body.OutputID.LoadString(outputIDStr)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "failed to parse outputid-argument:", err)
+	}
+	if managerCmd.trackCfg.Secret != (types.AtomicSwapSecret{}) {
+		body.Secret = &managerCmd.trackCfg.Secret
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		cli.Die("failed to JSON marshal the atomic swap track request:", err)
+	}
+	var resp api.AtomicSwapGET
+	err = managerCmd.cli.PostWithResponse("/atomicswap/swaps", string(data), &resp)
+	if err != nil {
+		cli.DieWithError("failed to track atomic swap contract", err)
+	}
+	managerCmd.printJSON(resp.Swap)
+}
+
+func (managerCmd *atomicSwapManagerCmd) swapsCmd(cmd *cobra.Command, args []string) {
+	if len(args) == 1 {
+		var id types.CoinOutputID
+		err := id.LoadString(args[0])
+		if err != nil {
+			cli.DieWithExitCode(cli.ExitCodeUsage, "failed to parse outputid-argument:", err)
+		}
+		var resp api.AtomicSwapGET
+		err = managerCmd.cli.GetWithResponse("/atomicswap/swaps/"+id.String(), &resp)
+		if err != nil {
+			cli.DieWithError("failed to get tracked atomic swap contract", err)
+		}
+		managerCmd.printJSON(resp.Swap)
+		return
+	}
+	var resp api.AtomicSwapsGET
+	err := managerCmd.cli.GetWithResponse("/atomicswap/swaps", &resp)
+	if err != nil {
+		cli.DieWithError("failed to get tracked atomic swap contracts", err)
+	}
+	managerCmd.printJSON(resp.Swaps)
+}
+
+func (managerCmd *atomicSwapManagerCmd) ordersCmd(cmd *cobra.Command, args []string) {
+	if len(args) == 1 {
+		id := parseAtomicSwapOrderID(args[0])
+		var resp api.AtomicSwapOrderGET
+		err := managerCmd.cli.GetWithResponse("/atomicswap/orders/"+strconv.FormatUint(id, 10), &resp)
+		if err != nil {
+			cli.DieWithError("failed to get atomic swap order", err)
+		}
+		managerCmd.printJSON(resp.Order)
+		return
+	}
+	var resp api.AtomicSwapOrdersGET
+	err := managerCmd.cli.GetWithResponse("/atomicswap/orders", &resp)
+	if err != nil {
+		cli.DieWithError("failed to get atomic swap orders", err)
+	}
+	managerCmd.printJSON(resp.Orders)
+}
+
+func (managerCmd *atomicSwapManagerCmd) placeOrderCmd(amount, asset, assetAmount string) {
+	data, err := json.Marshal(api.AtomicSwapOrderPOST{
+		Amount:       parseCoinArg(managerCmd.cli.CreateCurrencyConvertor(), amount),
+		Asset:        asset,
+		AssetAmount:  assetAmount,
+		Counterparty: managerCmd.placeOrderCfg.Counterparty,
+	})
+	if err != nil {
+		cli.Die("failed to JSON marshal the atomic swap order:", err)
+	}
+	var resp api.AtomicSwapOrderGET
+	err = managerCmd.cli.PostWithResponse("/atomicswap/orders", string(data), &resp)
+	if err != nil {
+		cli.DieWithError("failed to place atomic swap order", err)
+	}
+	managerCmd.printJSON(resp.Order)
+}
+
+func (managerCmd *atomicSwapManagerCmd) cancelOrderCmd(idStr string) {
+	id := parseAtomicSwapOrderID(idStr)
+	err := managerCmd.cli.Post("/atomicswap/orders/"+strconv.FormatUint(id, 10)+"/cancel", "")
+	if err != nil {
+		cli.DieWithError("failed to cancel atomic swap order", err)
+	}
+	fmt.Printf("Cancelled atomic swap order %d\n", id)
+}
+
+func (managerCmd *atomicSwapManagerCmd) printJSON(obj interface{}) {
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	err := e.Encode(obj)
+	if err != nil {
+		cli.Die("failed to encode the result as JSON:", err)
+	}
+}
+
+func parseAtomicSwapOrderID(str string) uint64 {
+	id, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "failed to parse id-argument:", err)
+	}
+	return id
+}
@@ -123,6 +123,7 @@ type OptionalCommandLineClientCommands struct {
 	GatewayCmd    *cobra.Command
 	ExploreCmd    *cobra.Command
 	MergeCmd      *cobra.Command
+	PSTCmd        *cobra.Command
 }
 
 // NewCommandLineClient creates a new CLI client, which can be run as it is,
@@ -186,6 +187,7 @@ func NewCommandLineClient(address, name, userAgent string, opts *OptionalCommand
 			fmt.Printf("%s daemon stopped.\n", client.Config.ChainName)
 		}),
 	})
+	client.RootCmd.AddCommand(createLoggingCmd(client))
 
 	if opts == nil {
 		client.WalletCmd = createWalletCmd(client)
@@ -202,6 +204,9 @@ func NewCommandLineClient(address, name, userAgent string, opts *OptionalCommand
 
 		client.MergeCmd = createMergeCmd(client)
 		client.RootCmd.AddCommand(client.MergeCmd)
+
+		client.PSTCmd = createPSTCmd(client)
+		client.RootCmd.AddCommand(client.PSTCmd)
 	} else {
 		if opts.WalletCmd == nil {
 			client.WalletCmd = createWalletCmd(client)
@@ -237,6 +242,13 @@ func NewCommandLineClient(address, name, userAgent string, opts *OptionalCommand
 			client.MergeCmd = opts.MergeCmd
 		}
 		client.RootCmd.AddCommand(client.MergeCmd)
+
+		if opts.PSTCmd == nil {
+			client.PSTCmd = createPSTCmd(client)
+		} else {
+			client.PSTCmd = opts.PSTCmd
+		}
+		client.RootCmd.AddCommand(client.PSTCmd)
 	}
 
 	// parse flags
@@ -268,6 +280,7 @@ type CommandLineClient struct {
 	GatewayCmd    *cobra.Command
 	ExploreCmd    *cobra.Command
 	MergeCmd      *cobra.Command
+	PSTCmd        *cobra.Command
 }
 
 // preRunE checks that all preConditions match
@@ -0,0 +1,43 @@
+package client
+
+import (
+	rivineapi "github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ConsensusClient is used to easily interact
+// with the consensus set through the HTTP REST API.
+type ConsensusClient struct {
+	bc BaseClient
+}
+
+// NewConsensusClient creates a new ConsensusClient,
+// that can be used for easy interaction with the Consensus API exposed via the HTTP REST API.
+func NewConsensusClient(bc BaseClient) *ConsensusClient {
+	if bc == nil {
+		panic("no BaseClient given")
+	}
+	return &ConsensusClient{
+		bc: bc,
+	}
+}
+
+// GetCoinOutput returns the unspent coin output for the given ID.
+func (cs *ConsensusClient) GetCoinOutput(id types.CoinOutputID) (types.CoinOutput, error) {
+	var resp rivineapi.ConsensusGetUnspentCoinOutput
+	err := cs.bc.HTTP().GetWithResponse("/consensus/unspent/coinoutputs/"+id.String(), &resp)
+	if err != nil {
+		return types.CoinOutput{}, err
+	}
+	return resp.Output, nil
+}
+
+// GetBlockStakeOutput returns the unspent block stake output for the given ID.
+func (cs *ConsensusClient) GetBlockStakeOutput(id types.BlockStakeOutputID) (types.BlockStakeOutput, error) {
+	var resp rivineapi.ConsensusGetUnspentBlockstakeOutput
+	err := cs.bc.HTTP().GetWithResponse("/consensus/unspent/blockstakeoutputs/"+id.String(), &resp)
+	if err != nil {
+		return types.BlockStakeOutput{}, err
+	}
+	return resp.Output, nil
+}
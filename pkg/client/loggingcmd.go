@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+)
+
+func createLoggingCmd(cli *CommandLineClient) *cobra.Command {
+	loggingCmd := &loggingCmd{cli: cli}
+
+	// create root logging command and all subs
+	var (
+		rootCmd = &cobra.Command{
+			Use:   "logging",
+			Short: "View the log level of each module",
+			Long:  "View the log level of each module of the daemon.",
+			Run:   Wrap(loggingCmd.rootCmd),
+		}
+		setLevelCmd = &cobra.Command{
+			Use:   "set <module> <level>",
+			Short: "Change the log level of a module",
+			Long: `Change the log level of a module of the daemon at runtime,
+the level has to be one of: debug, info, warn, error, critical.`,
+			Run: Wrap(loggingCmd.setLevelCmd),
+		}
+	)
+	rootCmd.AddCommand(setLevelCmd)
+
+	// return root command
+	return rootCmd
+}
+
+type loggingCmd struct {
+	cli *CommandLineClient
+}
+
+// rootCmd is the handler for the command `logging`.
+// Prints the log level of each module.
+func (loggingCmd *loggingCmd) rootCmd() {
+	var resp api.DaemonLoggingGET
+	err := loggingCmd.cli.GetWithResponse("/daemon/logging", &resp)
+	if err != nil {
+		cli.Die("Could not get the log levels:", err)
+	}
+	if len(resp.Levels) == 0 {
+		fmt.Println("No module loggers to show.")
+		return
+	}
+	modules := make([]string, 0, len(resp.Levels))
+	for module := range resp.Levels {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Module\tLevel")
+	for _, module := range modules {
+		fmt.Fprintf(w, "%s\t%s\n", module, resp.Levels[module].String())
+	}
+	w.Flush()
+}
+
+// setLevelCmd is the handler for the command `logging set <module> <level>`.
+// Changes the log level of a module.
+func (loggingCmd *loggingCmd) setLevelCmd(module, level string) {
+	var lvl persist.LogLevel
+	err := lvl.LoadString(level)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid log level:", err)
+	}
+	values := url.Values{}
+	values.Set("module", module)
+	values.Set("level", lvl.String())
+	err = loggingCmd.cli.Post("/daemon/logging", values.Encode())
+	if err != nil {
+		cli.Die("Could not change the log level:", err)
+	}
+	fmt.Printf("Log level of %s set to %s.\n", module, lvl.String())
+}
@@ -12,14 +12,18 @@ import (
 	"github.com/threefoldtech/rivine/types"
 )
 
+// mergeDeprecationMessage is printed (to the STDERR) when the deprecated merge commands are used.
+const mergeDeprecationMessage = `use 'pst create' and 'pst combine' instead, which verify all signatures prior to combining them`
+
 func createMergeCmd(*CommandLineClient) *cobra.Command {
 	mergeCmd := new(mergeCmd)
 
 	// create root merge command and all subs
 	var (
 		rootCmd = &cobra.Command{
-			Use:   "merge",
-			Short: "merge transaction inputs",
+			Use:        "merge",
+			Short:      "merge transaction inputs",
+			Deprecated: mergeDeprecationMessage,
 			// Run field is not set, as the create command itself is not a valid command.
 			// A subcommand must be provided.
 		}
@@ -31,9 +35,13 @@ Currently only multisignature inputs can be merged.
 
 Duplicate signatures are only deleted if there are more signatures for a public key given,
 than that the condition defines for that public key's unlock hash.
+
+Deprecated: use 'pst combine' instead, which carries the outputs spent
+and verifies all signatures prior to combining them.
 `,
-			Args: cobra.MinimumNArgs(2),
-			Run:  mergeCmd.mergeTransactions,
+			Deprecated: mergeDeprecationMessage,
+			Args:       cobra.MinimumNArgs(2),
+			Run:        mergeCmd.mergeTransactions,
 		}
 	)
 	rootCmd.AddCommand(mergeTxCmd)
@@ -0,0 +1,285 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/modules/wallet"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func createPSTCmd(client *CommandLineClient) *cobra.Command {
+	pstCmd := &pstCmd{cli: client}
+
+	// create root pst command and all subs
+	var (
+		rootCmd = &cobra.Command{
+			Use:   "pst",
+			Short: "Create, sign, combine and finalize partially signed transactions",
+			Long: `Create, sign, combine and finalize partially signed transactions (PST).
+
+A PST bundles an unsigned transaction with the outputs spent by its inputs,
+hints about the keys that can sign those inputs, and the signatures collected so far.
+It can therefore be signed offline, and by multiple parties, after which the signed PSTs
+are combined and finalized into a signed transaction.`,
+			// Run field is not set, as the pst command itself is not a valid command.
+			// A subcommand must be provided.
+		}
+		createCmd = &cobra.Command{
+			Use:   "create <txnjson>",
+			Short: "Create a PST from an unsigned transaction",
+			Long: `Create a PST from an unsigned transaction,
+looking up the outputs spent by its inputs using the consensus set of the daemon.`,
+			Run: Wrap(pstCmd.createCmd),
+		}
+		signCmd = &cobra.Command{
+			Use:   "sign <pstjson>",
+			Short: "Sign the inputs of a PST",
+			Long: `Sign all inputs of a PST which can be signed by the wallet of the daemon,
+or by the external signer (e.g. hardware wallet) at the address given using the --device flag.
+As the PST contains the outputs spent, the daemon does not have to be synced.`,
+			Run: Wrap(pstCmd.signCmd),
+		}
+		combineCmd = &cobra.Command{
+			Use:   "combine <pstjson1> <pstjson2> [pstjsonN...]",
+			Short: "Combine the signatures of multiple PSTs",
+			Long: `Combine the signatures of multiple PSTs of the same transaction,
+signed by different parties, into a single PST. All signatures are verified prior to being combined.`,
+			Args: cobra.MinimumNArgs(2),
+			Run:  pstCmd.combineCmd,
+		}
+		finalizeCmd = &cobra.Command{
+			Use:   "finalize <pstjson>",
+			Short: "Finalize a PST into a signed transaction",
+			Long: `Finalize a PST into a signed transaction, which requires all its inputs
+to have collected sufficient signatures. The transaction is optionally sent to the transaction pool.`,
+			Run: Wrap(pstCmd.finalizeCmd),
+		}
+		inspectCmd = &cobra.Command{
+			Use:   "inspect <pstjson>",
+			Short: "Inspect a PST",
+			Long:  "Print a human-readable overview of a PST, including the signing progress of each input.",
+			Run:   Wrap(pstCmd.inspectCmd),
+		}
+	)
+	rootCmd.AddCommand(
+		createCmd,
+		signCmd,
+		combineCmd,
+		finalizeCmd,
+		inspectCmd,
+	)
+
+	// define config of commands that have a config
+	createCmd.Flags().StringSliceVar(
+		&pstCmd.createCfg.Hints, "hint", nil,
+		"define the key index of an address as <address>:<index>, helping offline signers to find the key")
+	signCmd.Flags().StringVar(
+		&pstCmd.signCfg.Device, "device", "",
+		"sign using the external signer (e.g. hardware wallet) at the given address, instead of the wallet of the daemon")
+	finalizeCmd.Flags().BoolVar(
+		&pstCmd.finalizeCfg.Send, "send", false,
+		"send the finalized transaction to the transaction pool of the daemon")
+
+	// return root command
+	return rootCmd
+}
+
+type pstCmd struct {
+	cli *CommandLineClient
+
+	createCfg struct {
+		Hints []string
+	}
+	signCfg struct {
+		Device string
+	}
+	finalizeCfg struct {
+		Send bool
+	}
+}
+
+func (pstCmd *pstCmd) createCmd(txnjson string) {
+	var txn types.Transaction
+	err := json.Unmarshal([]byte(txnjson), &txn)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid transaction:", err)
+	}
+	hints := make([]types.PSTKeyHint, 0, len(pstCmd.createCfg.Hints))
+	for _, str := range pstCmd.createCfg.Hints {
+		hints = append(hints, parsePSTKeyHint(str))
+	}
+
+	bc, err := NewBaseClientFromCommandLineClient(pstCmd.cli)
+	if err != nil {
+		cli.DieWithError("failed to create base client:", err)
+	}
+	cs := NewConsensusClient(bc)
+	coinOutputs := make([]types.CoinOutput, 0, len(txn.CoinInputs))
+	for _, ci := range txn.CoinInputs {
+		co, err := cs.GetCoinOutput(ci.ParentID)
+		if err != nil {
+			cli.DieWithError(fmt.Sprintf("failed to get unspent coin output %s:", ci.ParentID.String()), err)
+		}
+		coinOutputs = append(coinOutputs, co)
+	}
+	blockStakeOutputs := make([]types.BlockStakeOutput, 0, len(txn.BlockStakeInputs))
+	for _, bsi := range txn.BlockStakeInputs {
+		bso, err := cs.GetBlockStakeOutput(bsi.ParentID)
+		if err != nil {
+			cli.DieWithError(fmt.Sprintf("failed to get unspent block stake output %s:", bsi.ParentID.String()), err)
+		}
+		blockStakeOutputs = append(blockStakeOutputs, bso)
+	}
+
+	pst, err := types.NewPartiallySignedTransaction(txn, coinOutputs, blockStakeOutputs)
+	if err != nil {
+		cli.Die("failed to create PST:", err)
+	}
+	for _, hint := range hints {
+		pst.AddHint(hint)
+	}
+	json.NewEncoder(os.Stdout).Encode(pst)
+}
+
+func (pstCmd *pstCmd) signCmd(pstjson string) {
+	pst := parsePST(pstjson)
+
+	var signed int
+	if pstCmd.signCfg.Device != "" {
+		signer, err := wallet.DialExternalSigner(pstCmd.signCfg.Device)
+		if err != nil {
+			cli.Die("failed to connect to external signer:", err)
+		}
+		defer signer.Close()
+		signed, err = wallet.ExternalSignPST(&pst, signer)
+		if err != nil {
+			cli.DieWithError("failed to sign PST:", err)
+		}
+	} else {
+		var resp api.WalletSignPSTPOSTResp
+		err := pstCmd.cli.PostWithResponse("/wallet/sign/pst", pstjson, &resp)
+		if err != nil {
+			cli.DieWithError("failed to sign PST:", err)
+		}
+		pst, signed = resp.PST, resp.Signed
+	}
+	fmt.Fprintf(os.Stderr, "added %d signature(s)\n", signed)
+	json.NewEncoder(os.Stdout).Encode(pst)
+}
+
+func (pstCmd *pstCmd) combineCmd(cmd *cobra.Command, args []string) {
+	pst := parsePST(args[0])
+	for idx, arg := range args[1:] {
+		other := parsePST(arg)
+		err := pst.Combine(other)
+		if err != nil {
+			cli.Die(fmt.Sprintf("PST #%d cannot be combined with the previous PST(s): %v", idx+2, err))
+		}
+	}
+	json.NewEncoder(os.Stdout).Encode(pst)
+}
+
+func (pstCmd *pstCmd) finalizeCmd(pstjson string) {
+	pst := parsePST(pstjson)
+	txn, err := pst.Finalize()
+	if err != nil {
+		cli.Die("failed to finalize PST:", err)
+	}
+	if !pstCmd.finalizeCfg.Send {
+		json.NewEncoder(os.Stdout).Encode(txn)
+		return
+	}
+	bc, err := NewBaseClientFromCommandLineClient(pstCmd.cli)
+	if err != nil {
+		cli.DieWithError("failed to create base client:", err)
+	}
+	txnID, err := NewTransactionPoolClient(bc).AddTransactiom(txn)
+	if err != nil {
+		cli.DieWithError("failed to send transaction to the transaction pool:", err)
+	}
+	fmt.Println("Transaction sent, ID:", txnID.String())
+}
+
+func (pstCmd *pstCmd) inspectCmd(pstjson string) {
+	pst := parsePST(pstjson)
+	summary := wallet.NewTransactionSummary(pst.Transaction)
+
+	fmt.Println("Transaction ID:", pst.Transaction.ID().String())
+	fmt.Println(summary.String())
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "input\tvalue\tsignatures\tsigners")
+	complete := true
+	printInputs := func(kind string, inputs []types.PSTInput) {
+		for idx, input := range inputs {
+			collected, required, err := input.SignatureProgress()
+			if err != nil {
+				cli.Die(fmt.Sprintf("invalid %s input #%d: %v", kind, idx, err))
+			}
+			if collected < required {
+				complete = false
+			}
+			signers := make([]string, 0, len(input.Hints))
+			for _, hint := range input.Hints {
+				signer := hint.UnlockHash.String()
+				if hint.KeyIndex != nil {
+					signer += ":" + strconv.FormatUint(*hint.KeyIndex, 10)
+				}
+				signers = append(signers, signer)
+			}
+			fmt.Fprintf(w, "%s #%d\t%s\t%d/%d\t%s\n", kind, idx, input.Value.String(), collected, required, strings.Join(signers, " "))
+		}
+	}
+	printInputs("coin", pst.CoinInputs)
+	printInputs("block stake", pst.BlockStakeInputs)
+	w.Flush()
+
+	fmt.Println()
+	if complete {
+		fmt.Println("All inputs have sufficient signatures, the PST can be finalized.")
+	} else {
+		fmt.Println("Not all inputs have sufficient signatures yet.")
+	}
+}
+
+// parsePST parses and validates a PST given as a JSON argument.
+func parsePST(str string) types.PartiallySignedTransaction {
+	var pst types.PartiallySignedTransaction
+	err := json.Unmarshal([]byte(str), &pst)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid PST:", err)
+	}
+	err = pst.Validate()
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid PST:", err)
+	}
+	return pst
+}
+
+// parsePSTKeyHint parses a key hint given as <address>:<index>.
+func parsePSTKeyHint(str string) types.PSTKeyHint {
+	parts := strings.SplitN(str, ":", 2)
+	if len(parts) != 2 {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid hint, expected <address>:<index>, got:", str)
+	}
+	var hint types.PSTKeyHint
+	err := hint.UnlockHash.LoadString(parts[0])
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid hint address:", err)
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		cli.DieWithExitCode(cli.ExitCodeUsage, "invalid hint key index:", err)
+	}
+	hint.KeyIndex = &index
+	return hint
+}
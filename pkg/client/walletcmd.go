@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/wallet"
 	"github.com/threefoldtech/rivine/pkg/api"
 	clipkg "github.com/threefoldtech/rivine/pkg/cli"
 	"github.com/threefoldtech/rivine/types"
@@ -71,7 +72,12 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 			Use:   "sign <txnjson>",
 			Short: "Sign inputs from the transaction",
 			Long: `Signs as much of the inputs transaction. Iterate over every input, and check if they can be signed
-	by any of the keys in the wallet.`,
+	by any of the keys in the wallet.
+
+	When the --device flag is given, the inputs are signed by an external signer (such as a hardware wallet)
+	instead, connected over a local transport, which shows a summary of the transaction for each signature.
+	The address of the device is either "unix:<path>", "tcp:<loopback host:port>" or the path of a device file.
+	No wallet is required on the daemon in this case, as only its consensus set is used.`,
 			Run: Wrap(walletCmd.signTxCmd),
 		}
 		seedsCmd = &cobra.Command{
@@ -304,11 +310,21 @@ func createWalletCmd(cli *CommandLineClient) *WalletCommand {
 		&walletCmd.sendBlockStakesCfg.RefundAddressNew,
 		"refund-address-new", false, "generate a new refund address if a refund needs to happen")
 
+	// address cmd flags
+	addressCmd.Flags().BoolVar(
+		&walletCmd.walletAddressCfg.Secp256k1, "secp256k1", false,
+		"generate an address linked to a secp256k1 key instead of an ed25519 key")
+
 	// all addresses cmd flags
 	addressesCmd.Flags().BoolVarP(
 		&walletCmd.walletAddressesCfg.ShowIndices, "index", "i", false,
 		"prefix each address with its index")
 
+	// sign cmd flags
+	signTxCmd.Flags().StringVar(
+		&walletCmd.signTxCfg.Device, "device", "",
+		"sign using the external signer (e.g. hardware wallet) at the given address, instead of the wallet of the daemon")
+
 	// return root command
 	return &WalletCommand{
 		Command:       rootCmd,
@@ -353,16 +369,26 @@ type walletCmd struct {
 		Plain bool
 		Seed  string
 	}
+	walletAddressCfg struct {
+		Secp256k1 bool
+	}
 	walletAddressesCfg struct {
 		ShowIndices bool
 	}
+	signTxCfg struct {
+		Device string
+	}
 }
 
 // addressCmd fetches a new address from the wallet that will be able to
 // receive coins.
 func (walletCmd *walletCmd) addressCmd() {
+	resource := "/wallet/address"
+	if walletCmd.walletAddressCfg.Secp256k1 {
+		resource += "?algorithm=" + types.SignatureAlgoSecp256k1.String()
+	}
 	addr := new(api.WalletAddressGET)
-	err := walletCmd.cli.GetWithResponse("/wallet/address", addr)
+	err := walletCmd.cli.GetWithResponse(resource, addr)
 	if err != nil {
 		clipkg.DieWithError("Could not generate new address:", err)
 	}
@@ -1262,6 +1288,11 @@ func (walletCmd *walletCmd) createBlockStakeTxCmd(cmd *cobra.Command, args []str
 }
 
 func (walletCmd *walletCmd) signTxCmd(txnjson string) {
+	if walletCmd.signTxCfg.Device != "" {
+		walletCmd.signTxWithDevice(txnjson)
+		return
+	}
+
 	var txn types.Transaction
 	err := walletCmd.cli.PostWithResponse("/wallet/sign", txnjson, &txn)
 	if err != nil {
@@ -1270,3 +1301,35 @@ func (walletCmd *walletCmd) signTxCmd(txnjson string) {
 
 	json.NewEncoder(os.Stdout).Encode(txn)
 }
+
+// signTxWithDevice signs the given transaction using an external signer,
+// looking up the parent outputs of its inputs using the consensus set of the daemon.
+func (walletCmd *walletCmd) signTxWithDevice(txnjson string) {
+	var txn types.Transaction
+	err := json.Unmarshal([]byte(txnjson), &txn)
+	if err != nil {
+		clipkg.DieWithExitCode(clipkg.ExitCodeUsage, "Invalid transaction:", err)
+	}
+	bc, err := NewBaseClientFromCommandLineClient(walletCmd.cli)
+	if err != nil {
+		clipkg.DieWithError("Failed to create base client:", err)
+	}
+	signer, err := wallet.DialExternalSigner(walletCmd.signTxCfg.Device)
+	if err != nil {
+		clipkg.Die("Failed to connect to external signer:", err)
+	}
+	defer signer.Close()
+
+	signed, err := wallet.ExternalSign(&txn, NewConsensusClient(bc), signer)
+	if err != nil {
+		if err == wallet.ErrExternalSignerRejected {
+			clipkg.DieWithExitCode(clipkg.ExitCodeCancelled, "Failed to sign transaction:", err)
+		}
+		clipkg.DieWithError("Failed to sign transaction:", err)
+	}
+	if signed == 0 {
+		fmt.Fprintln(os.Stderr, "No inputs could be signed by the external signer")
+	}
+
+	json.NewEncoder(os.Stdout).Encode(txn)
+}
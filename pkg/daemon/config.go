@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
 	"github.com/threefoldtech/rivine/pkg/cli"
 	"github.com/threefoldtech/rivine/types"
 )
@@ -57,6 +58,9 @@ type (
 
 		//Verbose (debug) logging
 		VerboseLogging bool
+		// LogConfig defines the format, (per-module) levels
+		// and rotation of the log files of all modules
+		LogConfig persist.LogConfig
 
 		// Optional BootstrapPeers we want to use instead of the default NetworkConfigs.
 		BootstrapPeers []modules.NetAddress
@@ -64,6 +68,16 @@ type (
 		// DebugConsensusDB is an optional filepath in which json encoded
 		// consensus database stats will be saved
 		DebugConsensusDB string
+
+		// ExplorerIndexArbitraryData indicates if the explorer (if enabled)
+		// should index transactions by their arbitrary data
+		ExplorerIndexArbitraryData bool
+
+		// ExplorerSQLDriver and ExplorerSQLDataSource define the (database/sql) driver
+		// and data source of the relational database into which the explorer (if enabled)
+		// exports the blockchain, no blockchain is exported if no driver is defined
+		ExplorerSQLDriver     string
+		ExplorerSQLDataSource string
 	}
 
 	// NetworkConfig are variables for a particular chain. Currently, these are genesis constants and bootstrap peers
@@ -94,10 +108,16 @@ func DefaultConfig() Config {
 		ProfileDir:        "profiles",
 		RootPersistentDir: "",
 		VerboseLogging:    false,
+		LogConfig:         persist.DefaultLogConfig(),
 
 		BootstrapPeers: nil,
 
 		DebugConsensusDB: "",
+
+		ExplorerIndexArbitraryData: false,
+
+		ExplorerSQLDriver:     "",
+		ExplorerSQLDataSource: "",
 	}
 }
 
@@ -111,6 +131,12 @@ func (cfg *Config) RegisterAsFlags(flagSet *pflag.FlagSet) {
 			cfg.BlockchainInfo.Name)
 
 	flagSet.BoolVarP(&cfg.VerboseLogging, "verboselogging", "v", false, "enable logging of debug information in the logfiles of the modules")
+	flagSet.Var(cli.StringLoaderFlag{StringLoader: &cfg.LogConfig.Format}, "log-format", "format of the log files of the modules, one of: text, json")
+	flagSet.Var(cli.StringLoaderFlag{StringLoader: &cfg.LogConfig.Level}, "log-level", "minimum level of logged messages, one of: debug, info, warn, error, critical")
+	cli.ModuleLogLevelsFlagVar(flagSet, &cfg.LogConfig.ModuleLevels, "log-module-level",
+		"overwrite the log level for a specific module, using the format <module>=<level> (e.g. consensus=debug)")
+	flagSet.Int64Var(&cfg.LogConfig.MaxSize, "log-max-size", cfg.LogConfig.MaxSize, "size in megabytes a log file can reach before it is rotated, 0 disables rotation")
+	flagSet.IntVar(&cfg.LogConfig.MaxBackups, "log-max-backups", cfg.LogConfig.MaxBackups, "amount of rotated log files to keep per module")
 	flagSet.BoolVarP(&cfg.NoBootstrap, "no-bootstrap", "", cfg.NoBootstrap, "disable bootstrapping on this run")
 	flagSet.BoolVarP(&cfg.Profile, "profile", "", cfg.Profile, "enable profiling")
 	flagSet.StringVarP(&cfg.RPCaddr, "rpc-addr", "", cfg.RPCaddr, "which port the gateway listens on")
@@ -118,6 +144,12 @@ func (cfg *Config) RegisterAsFlags(flagSet *pflag.FlagSet) {
 	flagSet.BoolVarP(&cfg.AllowAPIBind, "disable-api-security", "", cfg.AllowAPIBind, fmt.Sprintf("allow the daemon of %s to listen on a non-localhost address (DANGEROUS)", cfg.BlockchainInfo.Name))
 	flagSet.StringVarP(&cfg.BlockchainInfo.NetworkName, "network", "n", cfg.BlockchainInfo.NetworkName, "the name of the network to which the daemon connects")
 	flagSet.StringVar(&cfg.DebugConsensusDB, "consensus-db-stats", cfg.DebugConsensusDB, "file path in which json encoded database stats will be saved")
+	flagSet.BoolVar(&cfg.ExplorerIndexArbitraryData, "explorer-index-arbitrary-data", cfg.ExplorerIndexArbitraryData,
+		"index transactions by their arbitrary data in the explorer, allowing them to be looked up by (the prefix of) that data")
+	flagSet.StringVar(&cfg.ExplorerSQLDriver, "explorer-sql-driver", cfg.ExplorerSQLDriver,
+		"SQL driver (e.g. sqlite3 or postgres) of the relational database into which the explorer exports the blockchain")
+	flagSet.StringVar(&cfg.ExplorerSQLDataSource, "explorer-sql-dsn", cfg.ExplorerSQLDataSource,
+		"data source name (e.g. a file path or connection URL) of the relational database into which the explorer exports the blockchain")
 
 	cli.NetAddressArrayFlagVar(flagSet, &cfg.BootstrapPeers, "bootstrap-peers",
 		"overwrite the bootstrap peers to use, instead of using the default bootstrap peers")
@@ -68,6 +68,30 @@ the blockchain.`,
 			ConsensusSetModule.Identifier(),
 		),
 	}
+
+	AtomicSwapModule = &Module{
+		Name: "Atomic Swap",
+		Description: `The atomic swap manager tracks the atomic swap contracts of the wallet,
+redeeming them as soon as their secret is revealed on chain, and refunding them
+once their time lock has been reached. It also keeps a local orderbook.`,
+		Dependencies: ForceNewIdentifierSet(
+			ConsensusSetModule.Identifier(),
+			TransactionPoolModule.Identifier(),
+			WalletModule.Identifier(),
+		),
+	}
+
+	WebhooksModule = &Module{
+		Name: "Notification Webhooks",
+		Description: `The webhook manager notifies registered callback URLs with signed payloads
+when a watched address receives or spends funds, when such a transaction reaches
+the required amount of confirmations, or when it is reverted. Failed notifications
+are retried from a persistent queue.`,
+		Dependencies: ForceNewIdentifierSet(
+			ConsensusSetModule.Identifier(),
+			TransactionPoolModule.Identifier(),
+		),
+	}
 )
 
 // DefaultModuleSetFlag returns a new ModuleSetFlag,
@@ -98,6 +122,8 @@ func DefaultModuleSet() ModuleSet {
 		WalletModule,
 		BlockCreatorModule,
 		ExplorerModule,
+		AtomicSwapModule,
+		WebhooksModule,
 	)
 	if err != nil {
 		build.Critical(err)
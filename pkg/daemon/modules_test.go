@@ -80,6 +80,8 @@ func TestModuleMethodIdentifier(t *testing.T) {
 		{WalletModule, 'w'},
 		{BlockCreatorModule, 'b'},
 		{ExplorerModule, 'e'},
+		{AtomicSwapModule, 'a'},
+		{WebhooksModule, 'n'},
 	}
 	for idx, testCase := range testCases {
 		identifier := testCase.Module.Identifier()
@@ -91,7 +93,7 @@ func TestModuleMethodIdentifier(t *testing.T) {
 
 func TestDefaultModuleIdentifiers(t *testing.T) {
 	set := DefaultModuleSet()
-	expectedIdentifiers := []ModuleIdentifier{'g', 'c', 't', 'w', 'b', 'e'}
+	expectedIdentifiers := []ModuleIdentifier{'g', 'c', 't', 'w', 'b', 'e', 'a', 'n'}
 	if len(set.modules) != len(expectedIdentifiers) {
 		t.Fatal("unexpected length for default module set: ", len(set.modules), "!=", len(expectedIdentifiers))
 	}
@@ -114,6 +116,8 @@ func TestModuleSetMethodCreateDependencySetFor(t *testing.T) {
 		{'w', ModuleIdentifierSet{identifiers: []ModuleIdentifier{'w', 'c', 'g', 't'}}},
 		{'b', ModuleIdentifierSet{identifiers: []ModuleIdentifier{'b', 'c', 'g', 't', 'w'}}},
 		{'e', ModuleIdentifierSet{identifiers: []ModuleIdentifier{'e', 'c', 'g'}}},
+		{'a', ModuleIdentifierSet{identifiers: []ModuleIdentifier{'a', 'c', 'g', 't', 'w'}}},
+		{'n', ModuleIdentifierSet{identifiers: []ModuleIdentifier{'n', 'c', 'g', 't'}}},
 	}
 	for idx, testCase := range testCases {
 		dependencies, err := set.CreateDependencySetFor(testCase.Identifier)
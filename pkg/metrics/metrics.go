@@ -0,0 +1,539 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricType defines the type of a metric family,
+// as understood by the Prometheus text exposition format.
+type MetricType string
+
+// all metric types supported by this package
+const (
+	TypeCounter   MetricType = "counter"
+	TypeGauge     MetricType = "gauge"
+	TypeHistogram MetricType = "histogram"
+)
+
+// Namespace is the namespace used by all metrics reported by the Rivine modules.
+const Namespace = "rivine"
+
+// DefaultBuckets are the default histogram buckets (in seconds),
+// tailored to measure the latency of operations such as
+// block validation or plugin updates.
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// errors returned by the registry
+var (
+	ErrInvalidMetricName = errors.New("invalid metric name")
+	ErrDuplicateMetric   = errors.New("a metric with that name is already registered")
+)
+
+type (
+	// Label is a single name-value pair,
+	// used to identify a sample within a metric family.
+	Label struct {
+		Name  string
+		Value string
+	}
+
+	// Sample is a single value of a metric family.
+	Sample struct {
+		// Suffix is appended to the family name when writing the sample,
+		// used by histograms for their _bucket, _sum and _count samples.
+		Suffix string
+		Labels []Label
+		Value  float64
+	}
+
+	// Family is a named group of samples,
+	// sharing the same help string and type.
+	Family struct {
+		Name    string
+		Help    string
+		Type    MetricType
+		Samples []Sample
+	}
+
+	// Collector is the interface implemented by anything that can
+	// report metric families at the time the metrics are scraped.
+	Collector interface {
+		// Names returns the names of all families reported by this collector,
+		// used by the registry to detect duplicate registrations.
+		Names() []string
+		// Collect returns the current state of all families of this collector.
+		Collect() []Family
+	}
+
+	// Source is implemented by modules which register their own collectors.
+	Source interface {
+		RegisterMetrics(reg *Registry) error
+	}
+)
+
+// Opts are the options shared by all metrics of this package.
+type Opts struct {
+	// Namespace, Subsystem and Name are joined with underscores
+	// to form the fully qualified name of the metric.
+	Namespace string
+	Subsystem string
+	Name      string
+	Help      string
+}
+
+// FullName returns the fully qualified name of the metric.
+func (opts Opts) FullName() string {
+	var parts []string
+	for _, part := range []string{opts.Namespace, opts.Subsystem, opts.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// Registry collects metrics from all registered collectors,
+// and can serve them over HTTP in the Prometheus text exposition format.
+//
+// Registry is thread-safe.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+	names      map[string]struct{}
+}
+
+// NewRegistry creates a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Register a collector, returning an error in case
+// any of its family names is invalid or already registered.
+func (reg *Registry) Register(c Collector) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	names := c.Names()
+	for _, name := range names {
+		if !isValidMetricName(name) {
+			return fmt.Errorf("%v: %q", ErrInvalidMetricName, name)
+		}
+		if _, ok := reg.names[name]; ok {
+			return fmt.Errorf("%v: %q", ErrDuplicateMetric, name)
+		}
+	}
+	for _, name := range names {
+		reg.names[name] = struct{}{}
+	}
+	reg.collectors = append(reg.collectors, c)
+	return nil
+}
+
+// RegisterAll registers all given collectors,
+// stopping at the first error.
+func (reg *Registry) RegisterAll(cs ...Collector) error {
+	for _, c := range cs {
+		err := reg.Register(c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterSource registers the collectors of the given value,
+// should it implement the Source interface. It is a no-op otherwise.
+func (reg *Registry) RegisterSource(v interface{}) error {
+	src, ok := v.(Source)
+	if !ok {
+		return nil
+	}
+	return src.RegisterMetrics(reg)
+}
+
+// Gather collects all families of all registered collectors,
+// sorted by name.
+func (reg *Registry) Gather() []Family {
+	reg.mu.Lock()
+	collectors := make([]Collector, len(reg.collectors))
+	copy(collectors, reg.collectors)
+	reg.mu.Unlock()
+
+	var families []Family
+	for _, c := range collectors {
+		families = append(families, c.Collect()...)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+	return families
+}
+
+// WriteTo writes all gathered families to the given writer,
+// using the Prometheus text exposition format.
+func (reg *Registry) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	for _, family := range reg.Gather() {
+		writeFamily(&sb, family)
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// ServeHTTP implements http.Handler,
+// serving all metrics in the Prometheus text exposition format.
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	reg.WriteTo(w) // ignore error, as the connection is most likely gone
+}
+
+func writeFamily(sb *strings.Builder, family Family) {
+	if family.Help != "" {
+		fmt.Fprintf(sb, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+	}
+	fmt.Fprintf(sb, "# TYPE %s %s\n", family.Name, family.Type)
+	for _, sample := range family.Samples {
+		sb.WriteString(family.Name)
+		sb.WriteString(sample.Suffix)
+		if len(sample.Labels) > 0 {
+			sb.WriteByte('{')
+			for idx, label := range sample.Labels {
+				if idx > 0 {
+					sb.WriteByte(',')
+				}
+				fmt.Fprintf(sb, "%s=\"%s\"", label.Name, escapeLabelValue(label.Value))
+			}
+			sb.WriteByte('}')
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(formatFloat(sample.Value))
+		sb.WriteByte('\n')
+	}
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpReplacer.Replace(s) }
+func escapeLabelValue(s string) string { return labelReplacer.Replace(s) }
+
+// isValidMetricName checks if the name matches [a-zA-Z_:][a-zA-Z0-9_:]*
+func isValidMetricName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for idx, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_' || r == ':' {
+			continue
+		}
+		if idx > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// Counter is a metric which value can only go up.
+//
+// Counter is thread-safe.
+type Counter struct {
+	opts  Opts
+	mu    sync.Mutex
+	value float64
+}
+
+// NewCounter creates a new counter.
+func NewCounter(opts Opts) *Counter {
+	return &Counter{opts: opts}
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add the given non-negative value to the counter.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		return // counters can only go up
+	}
+	c.mu.Lock()
+	c.value += v
+	c.mu.Unlock()
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// Names implements Collector.Names
+func (c *Counter) Names() []string { return []string{c.opts.FullName()} }
+
+// Collect implements Collector.Collect
+func (c *Counter) Collect() []Family {
+	return []Family{{
+		Name:    c.opts.FullName(),
+		Help:    c.opts.Help,
+		Type:    TypeCounter,
+		Samples: []Sample{{Value: c.Value()}},
+	}}
+}
+
+// GaugeFunc is a gauge which value is computed
+// by calling a function at the time the metrics are collected.
+type GaugeFunc struct {
+	opts Opts
+	fn   func() float64
+}
+
+// NewGaugeFunc creates a new gauge, using the given function to compute its value.
+func NewGaugeFunc(opts Opts, fn func() float64) *GaugeFunc {
+	return &GaugeFunc{opts: opts, fn: fn}
+}
+
+// Names implements Collector.Names
+func (g *GaugeFunc) Names() []string { return []string{g.opts.FullName()} }
+
+// Collect implements Collector.Collect
+func (g *GaugeFunc) Collect() []Family {
+	return []Family{{
+		Name:    g.opts.FullName(),
+		Help:    g.opts.Help,
+		Type:    TypeGauge,
+		Samples: []Sample{{Value: g.fn()}},
+	}}
+}
+
+// LabeledGaugeFunc is a gauge which labeled samples are computed
+// by calling a function at the time the metrics are collected.
+// The function can return no samples, in which case the family is still reported.
+type LabeledGaugeFunc struct {
+	opts Opts
+	fn   func() []Sample
+}
+
+// NewLabeledGaugeFunc creates a new labeled gauge, using the given function to compute its samples.
+func NewLabeledGaugeFunc(opts Opts, fn func() []Sample) *LabeledGaugeFunc {
+	return &LabeledGaugeFunc{opts: opts, fn: fn}
+}
+
+// Names implements Collector.Names
+func (g *LabeledGaugeFunc) Names() []string { return []string{g.opts.FullName()} }
+
+// Collect implements Collector.Collect
+func (g *LabeledGaugeFunc) Collect() []Family {
+	return []Family{{
+		Name:    g.opts.FullName(),
+		Help:    g.opts.Help,
+		Type:    TypeGauge,
+		Samples: g.fn(),
+	}}
+}
+
+// HistogramOpts are the options used to create a (labeled) histogram.
+type HistogramOpts struct {
+	Opts
+	// Buckets defines the upper bounds of the buckets,
+	// DefaultBuckets are used if none are given.
+	Buckets []float64
+}
+
+// histogramData contains the state of a single histogram
+type histogramData struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (hd *histogramData) observe(buckets []float64, v float64) {
+	for idx, upperBound := range buckets {
+		if v <= upperBound {
+			hd.counts[idx]++
+		}
+	}
+	hd.count++
+	hd.sum += v
+}
+
+func (hd *histogramData) samples(buckets []float64, labels []Label) []Sample {
+	samples := make([]Sample, 0, len(buckets)+3)
+	for idx, upperBound := range buckets {
+		samples = append(samples, Sample{
+			Suffix: "_bucket",
+			Labels: append(copyLabels(labels), Label{Name: "le", Value: formatFloat(upperBound)}),
+			Value:  float64(hd.counts[idx]),
+		})
+	}
+	samples = append(samples, Sample{
+		Suffix: "_bucket",
+		Labels: append(copyLabels(labels), Label{Name: "le", Value: "+Inf"}),
+		Value:  float64(hd.count),
+	}, Sample{
+		Suffix: "_sum",
+		Labels: copyLabels(labels),
+		Value:  hd.sum,
+	}, Sample{
+		Suffix: "_count",
+		Labels: copyLabels(labels),
+		Value:  float64(hd.count),
+	})
+	return samples
+}
+
+func copyLabels(labels []Label) []Label {
+	if len(labels) == 0 {
+		return nil
+	}
+	cp := make([]Label, len(labels), len(labels)+1)
+	copy(cp, labels)
+	return cp
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	cp := make([]float64, len(buckets))
+	copy(cp, buckets)
+	sort.Float64s(cp)
+	return cp
+}
+
+// Histogram samples observations and counts them in configurable buckets.
+//
+// Histogram is thread-safe.
+type Histogram struct {
+	opts    Opts
+	buckets []float64
+	mu      sync.Mutex
+	data    histogramData
+}
+
+// NewHistogram creates a new histogram.
+func NewHistogram(opts HistogramOpts) *Histogram {
+	buckets := sortedBuckets(opts.Buckets)
+	return &Histogram{
+		opts:    opts.Opts,
+		buckets: buckets,
+		data:    histogramData{counts: make([]uint64, len(buckets))},
+	}
+}
+
+// Observe adds a single observation to the histogram.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	h.data.observe(h.buckets, v)
+	h.mu.Unlock()
+}
+
+// ObserveSince observes the duration (in seconds) elapsed since the given time.
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Names implements Collector.Names
+func (h *Histogram) Names() []string { return []string{h.opts.FullName()} }
+
+// Collect implements Collector.Collect
+func (h *Histogram) Collect() []Family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return []Family{{
+		Name:    h.opts.FullName(),
+		Help:    h.opts.Help,
+		Type:    TypeHistogram,
+		Samples: h.data.samples(h.buckets, nil),
+	}}
+}
+
+// HistogramVec is a histogram partitioned by the value of a single label.
+//
+// HistogramVec is thread-safe.
+type HistogramVec struct {
+	opts      Opts
+	buckets   []float64
+	labelName string
+	mu        sync.Mutex
+	data      map[string]*histogramData
+}
+
+// NewHistogramVec creates a new histogram, partitioned by the given label.
+func NewHistogramVec(opts HistogramOpts, labelName string) *HistogramVec {
+	return &HistogramVec{
+		opts:      opts.Opts,
+		buckets:   sortedBuckets(opts.Buckets),
+		labelName: labelName,
+		data:      make(map[string]*histogramData),
+	}
+}
+
+// Observe adds a single observation to the histogram of the given label value.
+func (h *HistogramVec) Observe(labelValue string, v float64) {
+	h.mu.Lock()
+	hd, ok := h.data[labelValue]
+	if !ok {
+		hd = &histogramData{counts: make([]uint64, len(h.buckets))}
+		h.data[labelValue] = hd
+	}
+	hd.observe(h.buckets, v)
+	h.mu.Unlock()
+}
+
+// ObserveSince observes the duration (in seconds) elapsed since the given time,
+// for the histogram of the given label value.
+func (h *HistogramVec) ObserveSince(labelValue string, start time.Time) {
+	h.Observe(labelValue, time.Since(start).Seconds())
+}
+
+// Names implements Collector.Names
+func (h *HistogramVec) Names() []string { return []string{h.opts.FullName()} }
+
+// Collect implements Collector.Collect
+func (h *HistogramVec) Collect() []Family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	labelValues := make([]string, 0, len(h.data))
+	for labelValue := range h.data {
+		labelValues = append(labelValues, labelValue)
+	}
+	sort.Strings(labelValues)
+	family := Family{
+		Name: h.opts.FullName(),
+		Help: h.opts.Help,
+		Type: TypeHistogram,
+	}
+	for _, labelValue := range labelValues {
+		family.Samples = append(family.Samples, h.data[labelValue].samples(
+			h.buckets, []Label{{Name: h.labelName, Value: labelValue}})...)
+	}
+	return []Family{family}
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	reg := NewRegistry()
+	counter := NewCounter(Opts{Namespace: "rivine", Subsystem: "test", Name: "events_total", Help: "Amount of events."})
+	counter.Inc()
+	counter.Add(2)
+	counter.Add(-5) // ignored
+	height := 42.
+	gauge := NewGaugeFunc(Opts{Namespace: "rivine", Name: "height"}, func() float64 { return height })
+	labeled := NewLabeledGaugeFunc(Opts{Name: "balance", Help: "a \\ help\nstring"}, func() []Sample {
+		return []Sample{{Labels: []Label{{Name: "asset", Value: `co"ins`}}, Value: 1e21}}
+	})
+	err := reg.RegisterAll(counter, gauge, labeled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	_, err = reg.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP balance a \\ help\nstring
+# TYPE balance gauge
+balance{asset="co\"ins"} 1e+21
+# TYPE rivine_height gauge
+rivine_height 42
+# HELP rivine_test_events_total Amount of events.
+# TYPE rivine_test_events_total counter
+rivine_test_events_total 3
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestRegistryRegisterErrors(t *testing.T) {
+	reg := NewRegistry()
+	err := reg.Register(NewCounter(Opts{Name: "foo"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = reg.Register(NewGaugeFunc(Opts{Name: "foo"}, func() float64 { return 0 }))
+	if err == nil {
+		t.Fatal("expected duplicate registration to fail")
+	}
+	for _, name := range []string{"", "1foo", "foo-bar", "foo bar"} {
+		err = reg.Register(NewCounter(Opts{Name: name}))
+		if err == nil {
+			t.Errorf("expected registration of invalid name %q to fail", name)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram(HistogramOpts{Opts: Opts{Name: "latency"}, Buckets: []float64{1, 0.5}})
+	h.Observe(0.25)
+	h.Observe(0.75)
+	h.Observe(2)
+	families := h.Collect()
+	if len(families) != 1 {
+		t.Fatal("unexpected amount of families:", len(families))
+	}
+	var lines []string
+	for _, sample := range families[0].Samples {
+		lines = append(lines, sample.Suffix+" "+formatFloat(sample.Value))
+	}
+	expected := "_bucket 1,_bucket 2,_bucket 3,_sum 3,_count 3"
+	if got := strings.Join(lines, ","); got != expected {
+		t.Fatalf("unexpected samples %q, expected %q", got, expected)
+	}
+	if le := families[0].Samples[0].Labels[0].Value; le != "0.5" {
+		t.Fatalf("expected buckets to be sorted, first bucket is %s", le)
+	}
+}
+
+func TestHistogramVecServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	h := NewHistogramVec(HistogramOpts{Opts: Opts{Name: "apply_seconds"}, Buckets: []float64{1}}, "plugin")
+	h.Observe("minting", 0.5)
+	h.Observe("authcointx", 3)
+	err := reg.Register(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatal("unexpected content type:", ct)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		`apply_seconds_bucket{plugin="authcointx",le="1"} 0`,
+		`apply_seconds_bucket{plugin="authcointx",le="+Inf"} 1`,
+		`apply_seconds_bucket{plugin="minting",le="1"} 1`,
+		`apply_seconds_sum{plugin="minting"} 0.5`,
+		`apply_seconds_count{plugin="minting"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected body to contain %q, body:\n%s", line, body)
+		}
+	}
+}
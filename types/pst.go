@@ -0,0 +1,468 @@
+package types
+
+// pst.go defines the partially signed transaction (PST) format,
+// used to sign a transaction offline and/or by multiple parties.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+const (
+	// PSTVersionOne is the first and current version of the PST format.
+	PSTVersionOne uint8 = 1
+)
+
+var (
+	// ErrPSTIncomplete is returned when finalizing a PST
+	// of which not all inputs have collected sufficient signatures.
+	ErrPSTIncomplete = errors.New("partially signed transaction does not have sufficient signatures")
+	// ErrPSTMismatch is returned when combining two PSTs
+	// which do not define the same transaction.
+	ErrPSTMismatch = errors.New("partially signed transactions define different transactions")
+)
+
+type (
+	// PartiallySignedTransaction (PST) bundles an unsigned transaction,
+	// the outputs spent by its inputs and the signatures collected so far.
+	//
+	// As it contains all information required to sign the transaction,
+	// a PST can be signed offline, without access to the consensus set,
+	// and as all signatures are kept separately, PSTs signed by different parties
+	// can be combined, before being finalized into a signed transaction.
+	//
+	// Only inputs locked by a (wrapped) single or multi signature condition
+	// can be signed using a PST, extension data is not signed.
+	PartiallySignedTransaction struct {
+		Version uint8 `json:"version"`
+		// Transaction is the transaction to sign, its fulfillments are never defined,
+		// such that its ID identifies the PST.
+		Transaction Transaction `json:"transaction"`
+		// CoinInputs and BlockStakeInputs contain the PST data
+		// of the coin and block stake inputs, in the order of the transaction inputs.
+		CoinInputs       []PSTInput `json:"coininputs,omitempty"`
+		BlockStakeInputs []PSTInput `json:"blockstakeinputs,omitempty"`
+	}
+
+	// PSTInput contains the PST data of a single (coin or block stake) input.
+	PSTInput struct {
+		// Value and Condition of the output spent by the input.
+		Value     Currency             `json:"value"`
+		Condition UnlockConditionProxy `json:"condition"`
+		// Hints define the keys that can sign the input,
+		// helping (offline) signers to find the key to sign with.
+		Hints []PSTKeyHint `json:"hints,omitempty"`
+		// Signatures collected so far.
+		Signatures []PublicKeySignaturePair `json:"signatures,omitempty"`
+	}
+
+	// PSTKeyHint defines a key that can sign an input, by its address,
+	// and optionally the index with which it is derived from its seed.
+	PSTKeyHint struct {
+		UnlockHash UnlockHash `json:"unlockhash"`
+		KeyIndex   *uint64    `json:"keyindex,omitempty"`
+	}
+
+	// PSTKeyGetter is used to sign a PST, returning the public key and
+	// (private) key of the given address, and false if no key is known for it.
+	// The key is either a ByteSlice or a HashSigner.
+	PSTKeyGetter func(uh UnlockHash) (pk PublicKey, key interface{}, ok bool)
+)
+
+// NewPartiallySignedTransaction creates a PST for the given transaction,
+// using the given outputs, spent by the coin and block stake inputs of the transaction,
+// in the same order as those inputs. Any fulfillments of the transaction are dropped.
+func NewPartiallySignedTransaction(txn Transaction, coinOutputs []CoinOutput, blockStakeOutputs []BlockStakeOutput) (PartiallySignedTransaction, error) {
+	if len(coinOutputs) != len(txn.CoinInputs) {
+		return PartiallySignedTransaction{}, fmt.Errorf(
+			"transaction has %d coin inputs, while %d spent coin outputs are given", len(txn.CoinInputs), len(coinOutputs))
+	}
+	if len(blockStakeOutputs) != len(txn.BlockStakeInputs) {
+		return PartiallySignedTransaction{}, fmt.Errorf(
+			"transaction has %d block stake inputs, while %d spent block stake outputs are given", len(txn.BlockStakeInputs), len(blockStakeOutputs))
+	}
+	pst := PartiallySignedTransaction{
+		Version:     PSTVersionOne,
+		Transaction: txn,
+	}
+	// copy the inputs, as to not modify the fulfillments of the given transaction
+	pst.Transaction.CoinInputs = make([]CoinInput, 0, len(txn.CoinInputs))
+	for idx, ci := range txn.CoinInputs {
+		pst.Transaction.CoinInputs = append(pst.Transaction.CoinInputs, CoinInput{ParentID: ci.ParentID})
+		input, err := newPSTInput(coinOutputs[idx].Value, coinOutputs[idx].Condition)
+		if err != nil {
+			return PartiallySignedTransaction{}, fmt.Errorf("invalid coin input #%d: %v", idx, err)
+		}
+		pst.CoinInputs = append(pst.CoinInputs, input)
+	}
+	pst.Transaction.BlockStakeInputs = make([]BlockStakeInput, 0, len(txn.BlockStakeInputs))
+	for idx, bsi := range txn.BlockStakeInputs {
+		pst.Transaction.BlockStakeInputs = append(pst.Transaction.BlockStakeInputs, BlockStakeInput{ParentID: bsi.ParentID})
+		input, err := newPSTInput(blockStakeOutputs[idx].Value, blockStakeOutputs[idx].Condition)
+		if err != nil {
+			return PartiallySignedTransaction{}, fmt.Errorf("invalid block stake input #%d: %v", idx, err)
+		}
+		pst.BlockStakeInputs = append(pst.BlockStakeInputs, input)
+	}
+	return pst, nil
+}
+
+func newPSTInput(value Currency, condition UnlockConditionProxy) (PSTInput, error) {
+	uhs, _, _, err := pstSignConditionProperties(condition.Condition)
+	if err != nil {
+		return PSTInput{}, err
+	}
+	input := PSTInput{
+		Value:     value,
+		Condition: condition,
+	}
+	for _, uh := range uhs {
+		input.Hints = append(input.Hints, PSTKeyHint{UnlockHash: uh})
+	}
+	return input, nil
+}
+
+// Validate validates the structure of the PST, as well as all its collected signatures.
+func (pst *PartiallySignedTransaction) Validate() error {
+	if pst.Version != PSTVersionOne {
+		return fmt.Errorf("unsupported PST version %d", pst.Version)
+	}
+	if len(pst.CoinInputs) != len(pst.Transaction.CoinInputs) {
+		return errors.New("PST coin inputs do not match the coin inputs of its transaction")
+	}
+	if len(pst.BlockStakeInputs) != len(pst.Transaction.BlockStakeInputs) {
+		return errors.New("PST block stake inputs do not match the block stake inputs of its transaction")
+	}
+	for idx, ci := range pst.Transaction.CoinInputs {
+		if ci.Fulfillment.FulfillmentType() != FulfillmentTypeNil {
+			return fmt.Errorf("coin input #%d of PST transaction is fulfilled", idx)
+		}
+		err := pst.verifySignatures(pst.CoinInputs[idx], uint64(idx))
+		if err != nil {
+			return fmt.Errorf("invalid signature of coin input #%d: %v", idx, err)
+		}
+	}
+	for idx, bsi := range pst.Transaction.BlockStakeInputs {
+		if bsi.Fulfillment.FulfillmentType() != FulfillmentTypeNil {
+			return fmt.Errorf("block stake input #%d of PST transaction is fulfilled", idx)
+		}
+		err := pst.verifySignatures(pst.BlockStakeInputs[idx], uint64(idx))
+		if err != nil {
+			return fmt.Errorf("invalid signature of block stake input #%d: %v", idx, err)
+		}
+	}
+	return nil
+}
+
+// Sign adds the signatures of all keys returned by the given key getter
+// for the inputs of the PST, returning the amount of signatures added.
+func (pst *PartiallySignedTransaction) Sign(keys PSTKeyGetter) (int, error) {
+	var signed int
+	for idx := range pst.CoinInputs {
+		n, err := pst.signInput(&pst.CoinInputs[idx], uint64(idx), keys)
+		signed += n
+		if err != nil {
+			return signed, fmt.Errorf("failed to sign coin input #%d: %v", idx, err)
+		}
+	}
+	for idx := range pst.BlockStakeInputs {
+		n, err := pst.signInput(&pst.BlockStakeInputs[idx], uint64(idx), keys)
+		signed += n
+		if err != nil {
+			return signed, fmt.Errorf("failed to sign block stake input #%d: %v", idx, err)
+		}
+	}
+	return signed, nil
+}
+
+func (pst *PartiallySignedTransaction) signInput(input *PSTInput, idx uint64, keys PSTKeyGetter) (int, error) {
+	uhs, _, multisig, err := pstSignConditionProperties(input.Condition.Condition)
+	if err != nil {
+		return 0, err
+	}
+	if len(uhs) == 0 {
+		// a nil condition can be fulfilled by any key
+		uhs = []UnlockHash{NilUnlockHash}
+	}
+	var signed int
+	for _, uh := range uhs {
+		pk, key, ok := keys(uh)
+		if !ok || input.hasSignature(pk) {
+			continue
+		}
+		var fulfillment MarshalableUnlockFulfillment
+		if multisig {
+			if signer, ok := key.(HashSigner); ok {
+				key = ExternalKeyPair{PublicKey: pk, Signer: signer}
+			} else if sk, ok := key.(ByteSlice); ok {
+				key = KeyPair{PublicKey: pk, PrivateKey: sk}
+			} else {
+				return signed, fmt.Errorf("%T is an unknown key type", key)
+			}
+			fulfillment = &MultiSignatureFulfillment{}
+		} else {
+			fulfillment = NewSingleSignatureFulfillment(pk)
+		}
+		err = fulfillment.Sign(FulfillmentSignContext{
+			ExtraObjects: []interface{}{idx},
+			Transaction:  pst.Transaction,
+			Key:          key,
+		})
+		if err != nil {
+			return signed, err
+		}
+		var pair PublicKeySignaturePair
+		switch f := fulfillment.(type) {
+		case *SingleSignatureFulfillment:
+			pair = PublicKeySignaturePair{PublicKey: f.PublicKey, Signature: f.Signature}
+		case *MultiSignatureFulfillment:
+			pair = f.Pairs[0]
+		}
+		input.Signatures = append(input.Signatures, pair)
+		signed++
+	}
+	return signed, nil
+}
+
+// Combine adds the signatures collected by the other PST,
+// which has to define the same transaction, to this PST.
+// The signatures of the other PST are verified prior to being added.
+func (pst *PartiallySignedTransaction) Combine(other PartiallySignedTransaction) error {
+	err := other.Validate()
+	if err != nil {
+		return err
+	}
+	if pst.Transaction.ID() != other.Transaction.ID() {
+		return ErrPSTMismatch
+	}
+	for idx := range pst.CoinInputs {
+		err = pst.CoinInputs[idx].combine(other.CoinInputs[idx])
+		if err != nil {
+			return fmt.Errorf("failed to combine coin input #%d: %v", idx, err)
+		}
+	}
+	for idx := range pst.BlockStakeInputs {
+		err = pst.BlockStakeInputs[idx].combine(other.BlockStakeInputs[idx])
+		if err != nil {
+			return fmt.Errorf("failed to combine block stake input #%d: %v", idx, err)
+		}
+	}
+	return nil
+}
+
+func (input *PSTInput) combine(other PSTInput) error {
+	if !input.Value.Equals(other.Value) || !input.Condition.Equal(other.Condition) {
+		return errors.New("spent outputs are different")
+	}
+	for _, pair := range other.Signatures {
+		if !input.hasSignature(pair.PublicKey) {
+			input.Signatures = append(input.Signatures, pair)
+		}
+	}
+	for _, hint := range other.Hints {
+		input.addHint(hint)
+	}
+	return nil
+}
+
+func (input *PSTInput) addHint(hint PSTKeyHint) {
+	for idx := range input.Hints {
+		if input.Hints[idx].UnlockHash.Cmp(hint.UnlockHash) != 0 {
+			continue
+		}
+		if input.Hints[idx].KeyIndex == nil {
+			input.Hints[idx].KeyIndex = hint.KeyIndex
+		}
+		return
+	}
+	input.Hints = append(input.Hints, hint)
+}
+
+// AddHint adds the given key hint to all inputs which can be signed by the key of the hint.
+func (pst *PartiallySignedTransaction) AddHint(hint PSTKeyHint) {
+	for _, inputs := range [][]PSTInput{pst.CoinInputs, pst.BlockStakeInputs} {
+		for idx := range inputs {
+			for _, h := range inputs[idx].Hints {
+				if h.UnlockHash.Cmp(hint.UnlockHash) == 0 {
+					inputs[idx].addHint(hint)
+					break
+				}
+			}
+		}
+	}
+}
+
+// SignatureProgress returns the amount of signatures collected for the input,
+// and the amount of signatures required to fulfill the input.
+func (input PSTInput) SignatureProgress() (collected, required uint64, err error) {
+	uhs, required, _, err := pstSignConditionProperties(input.Condition.Condition)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint64(len(input.conditionSignatures(uhs))), required, nil
+}
+
+// Finalize creates the signed transaction, using the collected signatures,
+// returning ErrPSTIncomplete if any input has insufficient signatures.
+func (pst *PartiallySignedTransaction) Finalize() (Transaction, error) {
+	err := pst.Validate()
+	if err != nil {
+		return Transaction{}, err
+	}
+	txn := pst.Transaction
+	txn.CoinInputs = make([]CoinInput, 0, len(pst.Transaction.CoinInputs))
+	for idx, ci := range pst.Transaction.CoinInputs {
+		ci.Fulfillment, err = pst.CoinInputs[idx].fulfillment()
+		if err == ErrPSTIncomplete {
+			return Transaction{}, err
+		}
+		if err != nil {
+			return Transaction{}, fmt.Errorf("failed to fulfill coin input #%d: %v", idx, err)
+		}
+		txn.CoinInputs = append(txn.CoinInputs, ci)
+	}
+	txn.BlockStakeInputs = make([]BlockStakeInput, 0, len(pst.Transaction.BlockStakeInputs))
+	for idx, bsi := range pst.Transaction.BlockStakeInputs {
+		bsi.Fulfillment, err = pst.BlockStakeInputs[idx].fulfillment()
+		if err == ErrPSTIncomplete {
+			return Transaction{}, err
+		}
+		if err != nil {
+			return Transaction{}, fmt.Errorf("failed to fulfill block stake input #%d: %v", idx, err)
+		}
+		txn.BlockStakeInputs = append(txn.BlockStakeInputs, bsi)
+	}
+	return txn, nil
+}
+
+func (input PSTInput) fulfillment() (UnlockFulfillmentProxy, error) {
+	uhs, required, multisig, err := pstSignConditionProperties(input.Condition.Condition)
+	if err != nil {
+		return UnlockFulfillmentProxy{}, err
+	}
+	signatures := input.conditionSignatures(uhs)
+	if uint64(len(signatures)) < required {
+		return UnlockFulfillmentProxy{}, ErrPSTIncomplete
+	}
+	if multisig {
+		return NewFulfillment(&MultiSignatureFulfillment{
+			Pairs: signatures[:required],
+		}), nil
+	}
+	return NewFulfillment(&SingleSignatureFulfillment{
+		PublicKey: signatures[0].PublicKey,
+		Signature: signatures[0].Signature,
+	}), nil
+}
+
+// conditionSignatures returns the collected signatures of distinct keys which can sign the input,
+// in the order of the given addresses of its condition. Any signature is returned
+// for a condition without addresses, as such a (nil) condition can be signed by any key.
+func (input PSTInput) conditionSignatures(uhs []UnlockHash) []PublicKeySignaturePair {
+	if len(uhs) == 0 {
+		return input.Signatures
+	}
+	var (
+		signatures []PublicKeySignaturePair
+		selected   = make([]bool, len(input.Signatures))
+	)
+	for _, uh := range uhs {
+		for idx, pair := range input.Signatures {
+			if selected[idx] {
+				continue
+			}
+			puh, err := NewPubKeyUnlockHash(pair.PublicKey)
+			if err != nil || puh.Cmp(uh) != 0 {
+				continue
+			}
+			selected[idx] = true
+			signatures = append(signatures, pair)
+			break
+		}
+	}
+	return signatures
+}
+
+// verifySignatures verifies all signatures of the given input,
+// which have to be signed by distinct keys.
+func (pst *PartiallySignedTransaction) verifySignatures(input PSTInput, idx uint64) error {
+	for i, pair := range input.Signatures {
+		if (PSTInput{Signatures: input.Signatures[:i]}).hasSignature(pair.PublicKey) {
+			return fmt.Errorf("public key %s signed the input more than once", pair.PublicKey.String())
+		}
+		err := pst.verifySignature(input, idx, pair)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifySignature verifies that the given signature pair is a valid signature
+// of the given input, by a key which can sign that input.
+func (pst *PartiallySignedTransaction) verifySignature(input PSTInput, idx uint64, pair PublicKeySignaturePair) error {
+	uhs, _, multisig, err := pstSignConditionProperties(input.Condition.Condition)
+	if err != nil {
+		return err
+	}
+	if len(uhs) > 0 {
+		uh, err := NewPubKeyUnlockHash(pair.PublicKey)
+		if err != nil {
+			return err
+		}
+		var found bool
+		for _, signer := range uhs {
+			if signer.Cmp(uh) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("public key %s cannot sign the input", pair.PublicKey.String())
+		}
+	}
+	extraObjects := []interface{}{idx}
+	if multisig {
+		extraObjects = append(extraObjects, pair.PublicKey)
+	}
+	return verifyHashUsingPublicKey(pair.PublicKey, pst.Transaction, pair.Signature, extraObjects)
+}
+
+func (input PSTInput) hasSignature(pk PublicKey) bool {
+	for _, pair := range input.Signatures {
+		if pair.PublicKey.Algorithm == pk.Algorithm && bytes.Equal(pair.PublicKey.Key, pk.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// pstSignConditionProperties returns the addresses which can sign the given condition,
+// the amount of signatures required, and whether or not it requires a multi signature fulfillment.
+// The nil condition returns no addresses, as it can be signed by any key.
+func pstSignConditionProperties(condition MarshalableUnlockCondition) (uhs []UnlockHash, required uint64, multisig bool, err error) {
+	if condition == nil {
+		return nil, 1, false, nil
+	}
+	switch uh := condition.UnlockHash(); uh.Type {
+	case UnlockTypeNil:
+		return nil, 1, false, nil
+	case UnlockTypePubKey:
+		return []UnlockHash{uh}, 1, false, nil
+	case UnlockTypeMultiSig:
+		type multisigCondition interface {
+			UnlockHashSliceGetter
+			GetMinimumSignatureCount() uint64
+		}
+		if c, ok := condition.(multisigCondition); ok {
+			return c.UnlockHashSlice(), c.GetMinimumSignatureCount(), true, nil
+		}
+	}
+	// conditions which wrap another condition, under an unlock hash of their own,
+	// are signed as the wrapped condition
+	if getter, ok := condition.(MarshalableUnlockConditionGetter); ok {
+		return pstSignConditionProperties(getter.GetMarshalableUnlockCondition())
+	}
+	return nil, 0, false, fmt.Errorf("condition type %d cannot be signed using a PST", condition.ConditionType())
+}
@@ -0,0 +1,286 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+)
+
+type pstTestKey struct {
+	pk PublicKey
+	sk ByteSlice
+	uh UnlockHash
+}
+
+func newPSTTestKey(t *testing.T) pstTestKey {
+	sk, pk := crypto.GenerateKeyPair()
+	key := pstTestKey{
+		pk: Ed25519PublicKey(pk),
+		sk: ByteSlice(sk[:]),
+	}
+	var err error
+	key.uh, err = NewPubKeyUnlockHash(key.pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func (key pstTestKey) getter() PSTKeyGetter {
+	return func(uh UnlockHash) (PublicKey, interface{}, bool) {
+		if uh.Cmp(key.uh) != 0 {
+			return PublicKey{}, nil, false
+		}
+		return key.pk, key.sk, true
+	}
+}
+
+func TestPartiallySignedTransaction(t *testing.T) {
+	alice, bob := newPSTTestKey(t), newPSTTestKey(t)
+
+	coinOutputs := []CoinOutput{
+		{
+			Value:     NewCurrency64(10),
+			Condition: NewCondition(NewUnlockHashCondition(alice.uh)),
+		},
+		{
+			Value:     NewCurrency64(20),
+			Condition: NewCondition(NewMultiSignatureCondition(UnlockHashSlice{alice.uh, bob.uh}, 2)),
+		},
+	}
+	blockStakeOutputs := []BlockStakeOutput{
+		{
+			Value:     NewCurrency64(1),
+			Condition: NewCondition(NewTimeLockCondition(42, NewUnlockHashCondition(bob.uh))),
+		},
+	}
+	txn := Transaction{
+		Version: TransactionVersionOne,
+		CoinInputs: []CoinInput{
+			{ParentID: CoinOutputID{1}},
+			{ParentID: CoinOutputID{2}},
+		},
+		CoinOutputs: []CoinOutput{{
+			Value:     NewCurrency64(29),
+			Condition: NewCondition(NewUnlockHashCondition(bob.uh)),
+		}},
+		BlockStakeInputs: []BlockStakeInput{{ParentID: BlockStakeOutputID{3}}},
+		BlockStakeOutputs: []BlockStakeOutput{{
+			Value:     NewCurrency64(1),
+			Condition: NewCondition(NewUnlockHashCondition(alice.uh)),
+		}},
+		MinerFees: []Currency{NewCurrency64(1)},
+	}
+
+	pst, err := NewPartiallySignedTransaction(txn, coinOutputs, blockStakeOutputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pst.CoinInputs[1].Hints) != 2 {
+		t.Errorf("expected 2 hints for the multisig input, got %d", len(pst.CoinInputs[1].Hints))
+	}
+
+	// both parties sign their own copy, as if signed offline
+	b, err := json.Marshal(pst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bobPST PartiallySignedTransaction
+	err = json.Unmarshal(b, &bobPST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := pst.Sign(alice.getter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed != 2 {
+		t.Errorf("expected alice to sign 2 inputs, signed %d", signed)
+	}
+	signed, err = bobPST.Sign(bob.getter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed != 2 {
+		t.Errorf("expected bob to sign 2 inputs, signed %d", signed)
+	}
+
+	_, err = pst.Finalize()
+	if err != ErrPSTIncomplete {
+		t.Fatalf("expected incomplete PST, got: %v", err)
+	}
+	collected, required, err := pst.CoinInputs[1].SignatureProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected != 1 || required != 2 {
+		t.Errorf("unexpected progress of multisig input: %d/%d", collected, required)
+	}
+
+	err = pst.Combine(bobPST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// combining twice should not add any signatures
+	err = pst.Combine(bobPST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(pst.CoinInputs[1].Signatures); n != 2 {
+		t.Errorf("expected 2 signatures for the multisig input, got %d", n)
+	}
+
+	signedTxn, err := pst.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, ci := range signedTxn.CoinInputs {
+		err = coinOutputs[idx].Condition.Fulfill(ci.Fulfillment, FulfillContext{
+			ExtraObjects: []interface{}{uint64(idx)},
+			Transaction:  signedTxn,
+		})
+		if err != nil {
+			t.Errorf("coin input #%d is not fulfilled: %v", idx, err)
+		}
+	}
+	err = blockStakeOutputs[0].Condition.Fulfill(signedTxn.BlockStakeInputs[0].Fulfillment, FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		BlockHeight:  42,
+		Transaction:  signedTxn,
+	})
+	if err != nil {
+		t.Errorf("block stake input is not fulfilled: %v", err)
+	}
+	// the PST itself remains unsigned
+	if ft := pst.Transaction.CoinInputs[0].Fulfillment.FulfillmentType(); ft != FulfillmentTypeNil {
+		t.Errorf("PST transaction should not be fulfilled, but has fulfillment type %d", ft)
+	}
+}
+
+func TestPartiallySignedTransactionCombineInvalid(t *testing.T) {
+	alice, bob := newPSTTestKey(t), newPSTTestKey(t)
+	txn := Transaction{
+		Version:    TransactionVersionOne,
+		CoinInputs: []CoinInput{{ParentID: CoinOutputID{1}}},
+		MinerFees:  []Currency{NewCurrency64(10)},
+	}
+	coinOutputs := []CoinOutput{{
+		Value:     NewCurrency64(10),
+		Condition: NewCondition(NewMultiSignatureCondition(UnlockHashSlice{alice.uh, bob.uh}, 2)),
+	}}
+	pst, err := NewPartiallySignedTransaction(txn, coinOutputs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a signature of another transaction should be rejected
+	otherTxn := txn
+	otherTxn.MinerFees = []Currency{NewCurrency64(11)}
+	otherPST, err := NewPartiallySignedTransaction(otherTxn, coinOutputs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = otherPST.Sign(bob.getter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pst.Combine(otherPST)
+	if err != ErrPSTMismatch {
+		t.Errorf("expected mismatch, got: %v", err)
+	}
+	forgedPST := pst
+	forgedPST.CoinInputs = []PSTInput{otherPST.CoinInputs[0]}
+	err = pst.Combine(forgedPST)
+	if err == nil {
+		t.Error("signature of another transaction should not be combined")
+	}
+
+	// a signature of a key which cannot sign the input should be rejected
+	eve := newPSTTestKey(t)
+	forgedPST = pst
+	forgedPST.CoinInputs = []PSTInput{pst.CoinInputs[0]}
+	forgedPST.CoinInputs[0].Signatures = nil
+	forgedPST.CoinInputs[0].Condition = NewCondition(NewMultiSignatureCondition(UnlockHashSlice{alice.uh, eve.uh}, 2))
+	_, err = forgedPST.Sign(eve.getter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedPST.CoinInputs[0].Condition = pst.CoinInputs[0].Condition
+	err = pst.Combine(forgedPST)
+	if err == nil {
+		t.Error("signature of an unrelated key should not be combined")
+	}
+
+	// a key signing the input more than once should be rejected,
+	// as it cannot provide more than one of the required signatures
+	forgedPST = pst
+	forgedPST.CoinInputs = []PSTInput{pst.CoinInputs[0]}
+	forgedPST.CoinInputs[0].Signatures = nil
+	_, err = forgedPST.Sign(alice.getter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedPST.CoinInputs[0].Signatures = append(forgedPST.CoinInputs[0].Signatures, forgedPST.CoinInputs[0].Signatures[0])
+	err = forgedPST.Validate()
+	if err == nil {
+		t.Error("duplicate signature should not be valid")
+	}
+	err = pst.Combine(forgedPST)
+	if err == nil {
+		t.Error("duplicate signature should not be combined")
+	}
+}
+
+func TestPartiallySignedTransactionSignatureOrder(t *testing.T) {
+	alice, bob := newPSTTestKey(t), newPSTTestKey(t)
+	txn := Transaction{
+		Version:    TransactionVersionOne,
+		CoinInputs: []CoinInput{{ParentID: CoinOutputID{1}}},
+		MinerFees:  []Currency{NewCurrency64(10)},
+	}
+	coinOutputs := []CoinOutput{{
+		Value:     NewCurrency64(10),
+		Condition: NewCondition(NewMultiSignatureCondition(UnlockHashSlice{bob.uh, alice.uh}, 2)),
+	}}
+	pst, err := NewPartiallySignedTransaction(txn, coinOutputs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobPST := pst
+	bobPST.CoinInputs = []PSTInput{pst.CoinInputs[0]}
+
+	// alice signs first, while the condition lists bob first
+	_, err = pst.Sign(alice.getter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bobPST.Sign(bob.getter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pst.Combine(bobPST)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedTxn, err := pst.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fulfillment, ok := signedTxn.CoinInputs[0].Fulfillment.Fulfillment.(*MultiSignatureFulfillment)
+	if !ok {
+		t.Fatalf("unexpected fulfillment type %d", signedTxn.CoinInputs[0].Fulfillment.FulfillmentType())
+	}
+	if len(fulfillment.Pairs) != 2 ||
+		fulfillment.Pairs[0].PublicKey.String() != bob.pk.String() || fulfillment.Pairs[1].PublicKey.String() != alice.pk.String() {
+		t.Errorf("expected the signatures of bob and alice, in that order, got: %v", fulfillment.Pairs)
+	}
+	err = coinOutputs[0].Condition.Fulfill(signedTxn.CoinInputs[0].Fulfillment, FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  signedTxn,
+	})
+	if err != nil {
+		t.Errorf("coin input is not fulfilled: %v", err)
+	}
+}
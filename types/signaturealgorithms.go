@@ -0,0 +1,351 @@
+package types
+
+// signaturealgorithms.go contains the registry of signature algorithms,
+// as well as the implementations of the signature algorithms supported by default.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/build"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+)
+
+type (
+	// SignatureAlgorithm defines the functionality a signature algorithm has to provide,
+	// in order to be used for the public keys and signatures of fulfillments.
+	//
+	// All methods receive the raw public key and signature bytes, as found in the PublicKey
+	// and fulfillment, such that an algorithm can define its own key and signature formats.
+	SignatureAlgorithm interface {
+		// Specifier returns the unique specifier of the algorithm,
+		// used for the JSON and siabin encodings of public keys.
+		Specifier() Specifier
+
+		// PublicKeySize returns the fixed size (in bytes) of the public keys of this algorithm,
+		// required for the rivbin decoding of public keys.
+		PublicKeySize() int
+
+		// ValidatePublicKey checks whether the given public key is valid for this algorithm.
+		ValidatePublicKey(pk ByteSlice) error
+		// IsStandardSignature checks whether the given public key and signature are a valid pair,
+		// and whether the algorithm is accepted within the given context,
+		// as part of the IsStandardFulfillment check of fulfillments.
+		IsStandardSignature(pk, signature ByteSlice, ctx ValidationContext) error
+
+		// SignHash signs the given hash, using the given (secret) key.
+		// It is up to the algorithm to define what key types are supported,
+		// but all algorithms are expected to support at least the ByteSlice type.
+		SignHash(hash crypto.Hash, key interface{}) (ByteSlice, error)
+		// VerifyHash verifies the signature of the given hash, using the given public key.
+		VerifyHash(hash crypto.Hash, pk, signature ByteSlice) error
+
+		// UnlockHash derives the (UnlockTypePubKey) unlock hash from a public key of this algorithm.
+		UnlockHash(pk PublicKey) (UnlockHash, error)
+	}
+
+	// HashSigner can be used as the key of a FulfillmentSignContext (or ExternalKeyPair),
+	// in order to sign using a signer that does not expose its private key,
+	// such as a hardware wallet. The returned signature has to be valid
+	// for the given public key, according to the algorithm of that key.
+	//
+	// The transaction and extra objects the signature hash is computed from are given as well,
+	// such that a signer can recompute the hash itself, rather than having to trust the given hash.
+	HashSigner interface {
+		SignHash(pk PublicKey, hash crypto.Hash, tx Transaction, extraObjects []interface{}) (ByteSlice, error)
+	}
+)
+
+// RegisterSignatureAlgorithm is used to register a signature algorithm,
+// linking it to a signature algorithm type.
+// The specifier of the algorithm has to be unique among all registered algorithms.
+//
+// RegisterSignatureAlgorithm can also used to unregister a signature algorithm,
+// by calling this function with nil as the SignatureAlgorithm.
+//
+// Adding a signature algorithm to a live chain is a protocol upgrade,
+// see /doc/ProtocolUpgrade.md for more information on how to do this safely.
+func RegisterSignatureAlgorithm(sat SignatureAlgoType, algorithm SignatureAlgorithm) {
+	if sat == SignatureAlgoNil {
+		build.Critical("cannot register a signature algorithm for the nil signature algorithm type")
+		return
+	}
+	if algorithm == nil {
+		delete(_RegisteredSignatureAlgorithms, sat)
+		return
+	}
+	specifier := algorithm.Specifier()
+	for registeredType, registeredAlgorithm := range _RegisteredSignatureAlgorithms {
+		if registeredType != sat && registeredAlgorithm.Specifier() == specifier {
+			build.Critical(fmt.Sprintf("specifier %s is already used by signature algorithm type %d", specifier.String(), registeredType))
+			return
+		}
+	}
+	_RegisteredSignatureAlgorithms[sat] = algorithm
+}
+
+// GetSignatureAlgorithm returns the signature algorithm registered for the given type,
+// returning ErrUnknownSignAlgorithmType in case no algorithm is registered for it.
+func GetSignatureAlgorithm(sat SignatureAlgoType) (SignatureAlgorithm, error) {
+	algorithm, ok := _RegisteredSignatureAlgorithms[sat]
+	if !ok {
+		return nil, ErrUnknownSignAlgorithmType
+	}
+	return algorithm, nil
+}
+
+// Hidden global used to collect the standard as well as the user-defined
+// signature algorithms, each linked to their signature algorithm type.
+//
+// Manipulated by the RegisterSignatureAlgorithm function.
+var _RegisteredSignatureAlgorithms = map[SignatureAlgoType]SignatureAlgorithm{
+	SignatureAlgoEd25519:   ed25519SignatureAlgorithm{},
+	SignatureAlgoSecp256k1: secp256k1SignatureAlgorithm{},
+}
+
+// pubKeyUnlockHash computes the unlock hash of a public key,
+// as the hash of its siabin encoding.
+func pubKeyUnlockHash(pk PublicKey) (UnlockHash, error) {
+	pkb, err := siabin.Marshal(pk)
+	if err != nil {
+		return UnlockHash{}, err
+	}
+	h, err := crypto.HashObject(pkb)
+	if err != nil {
+		return UnlockHash{}, err
+	}
+	return UnlockHash{
+		Type: UnlockTypePubKey,
+		Hash: h,
+	}, nil
+}
+
+// ed25519SignatureAlgorithm implements the Ed25519 signature algorithm,
+// the default algorithm, see SignatureAlgoEd25519 for more information.
+type ed25519SignatureAlgorithm struct{}
+
+// Specifier implements SignatureAlgorithm.Specifier
+func (ed25519SignatureAlgorithm) Specifier() Specifier {
+	return SignatureAlgoEd25519Specifier
+}
+
+// PublicKeySize implements SignatureAlgorithm.PublicKeySize
+func (ed25519SignatureAlgorithm) PublicKeySize() int {
+	return crypto.PublicKeySize
+}
+
+// ValidatePublicKey implements SignatureAlgorithm.ValidatePublicKey
+func (ed25519SignatureAlgorithm) ValidatePublicKey(pk ByteSlice) error {
+	if len(pk) != crypto.PublicKeySize {
+		return errors.New("invalid public key size")
+	}
+	var edPK crypto.PublicKey
+	copy(edPK[:], pk)
+	if edPK.IsNil() {
+		return crypto.ErrPublicNilKey
+	}
+	return nil
+}
+
+// IsStandardSignature implements SignatureAlgorithm.IsStandardSignature
+func (ed25519SignatureAlgorithm) IsStandardSignature(pk, signature ByteSlice, _ ValidationContext) error {
+	if len(pk) != crypto.PublicKeySize {
+		return errors.New("invalid public key size in transaction")
+	}
+	if len(signature) != crypto.SignatureSize {
+		return errors.New("invalid signature size in transaction")
+	}
+	return nil
+}
+
+// SignHash implements SignatureAlgorithm.SignHash
+func (ed25519SignatureAlgorithm) SignHash(hash crypto.Hash, key interface{}) (ByteSlice, error) {
+	// decode the ed-secretKey
+	var edSK crypto.SecretKey
+	switch k := key.(type) {
+	case crypto.SecretKey:
+		edSK = k
+	case ByteSlice:
+		if len(k) != crypto.SecretKeySize {
+			return nil, errors.New("invalid secret key size")
+		}
+		copy(edSK[:], k)
+	case []byte:
+		if len(k) != crypto.SecretKeySize {
+			return nil, errors.New("invalid secret key size")
+		}
+		copy(edSK[:], k)
+	default:
+		return nil, fmt.Errorf("%T is an unknown secret key type", key)
+	}
+	if edSK.IsNil() {
+		return nil, crypto.ErrSecretNilKey
+	}
+	sig := crypto.SignHash(hash, edSK)
+	return sig[:], nil
+}
+
+// VerifyHash implements SignatureAlgorithm.VerifyHash
+func (ed25519SignatureAlgorithm) VerifyHash(hash crypto.Hash, pk, signature ByteSlice) error {
+	// Decode the public key and signature.
+	var (
+		edPK  crypto.PublicKey
+		edSig crypto.Signature
+	)
+	copy(edPK[:], pk)
+	copy(edSig[:], signature)
+	if edPK.IsNil() {
+		return crypto.ErrPublicNilKey
+	}
+	return crypto.VerifyHash(hash, edPK, edSig)
+}
+
+// UnlockHash implements SignatureAlgorithm.UnlockHash
+func (ed25519SignatureAlgorithm) UnlockHash(pk PublicKey) (UnlockHash, error) {
+	return pubKeyUnlockHash(pk)
+}
+
+// secp256k1Activation defines if and from which block height
+// the secp256k1 signature algorithm is accepted as part of (standard) fulfillments.
+var secp256k1Activation struct {
+	Enabled bool
+	Height  BlockHeight
+}
+
+// EnableSecp256k1SignatureAlgorithm enables the secp256k1 signature algorithm,
+// accepting it as part of fulfillments of transactions included
+// in blocks with a height equal to or greater than the given activation height.
+//
+// Adding a signature algorithm to a live chain is a protocol upgrade,
+// see /doc/ProtocolUpgrade.md for more information on how to do this safely.
+// Chains which support it from their genesis block can use an activation height of 0.
+func EnableSecp256k1SignatureAlgorithm(activationHeight BlockHeight) {
+	secp256k1Activation.Enabled = true
+	secp256k1Activation.Height = activationHeight
+}
+
+// DisableSecp256k1SignatureAlgorithm disables the secp256k1 signature algorithm,
+// which is the default.
+func DisableSecp256k1SignatureAlgorithm() {
+	secp256k1Activation.Enabled = false
+	secp256k1Activation.Height = 0
+}
+
+// IsSecp256k1SignatureAlgorithmEnabled returns true if the secp256k1 signature algorithm
+// is enabled, regardless of whether or not its activation height has been reached already.
+func IsSecp256k1SignatureAlgorithmEnabled() bool {
+	return secp256k1Activation.Enabled
+}
+
+// IsSecp256k1SignatureAlgorithmActive returns true if the secp256k1 signature algorithm
+// is enabled and accepted as part of fulfillments of transactions included in a block of the given height.
+func IsSecp256k1SignatureAlgorithmActive(height BlockHeight) bool {
+	return secp256k1Activation.Enabled && height >= secp256k1Activation.Height
+}
+
+// ValidateSignatureAlgorithmActivation returns ErrInactiveSignAlgorithmType in case
+// the signature algorithm of the given public key is not (yet) accepted
+// as part of fulfillments of transactions included in a block of the given height.
+func ValidateSignatureAlgorithmActivation(pk PublicKey, height BlockHeight) error {
+	if pk.Algorithm == SignatureAlgoSecp256k1 && !IsSecp256k1SignatureAlgorithmActive(height) {
+		return ErrInactiveSignAlgorithmType
+	}
+	return nil
+}
+
+// secp256k1SignatureAlgorithm implements the secp256k1 (ECDSA) signature algorithm,
+// see SignatureAlgoSecp256k1 for more information.
+type secp256k1SignatureAlgorithm struct{}
+
+// Specifier implements SignatureAlgorithm.Specifier
+func (secp256k1SignatureAlgorithm) Specifier() Specifier {
+	return SignatureAlgoSecp256k1Specifier
+}
+
+// PublicKeySize implements SignatureAlgorithm.PublicKeySize
+func (secp256k1SignatureAlgorithm) PublicKeySize() int {
+	return crypto.Secp256k1PublicKeySize
+}
+
+// ValidatePublicKey implements SignatureAlgorithm.ValidatePublicKey
+func (secp256k1SignatureAlgorithm) ValidatePublicKey(pk ByteSlice) error {
+	if len(pk) != crypto.Secp256k1PublicKeySize {
+		return errors.New("invalid public key size")
+	}
+	var secpPK crypto.Secp256k1PublicKey
+	copy(secpPK[:], pk)
+	if secpPK.IsNil() {
+		return crypto.ErrPublicNilKey
+	}
+	return nil
+}
+
+// IsStandardSignature implements SignatureAlgorithm.IsStandardSignature
+func (secp256k1SignatureAlgorithm) IsStandardSignature(pk, signature ByteSlice, ctx ValidationContext) error {
+	// an unconfirmed transaction will be part of the next block at the earliest
+	height := ctx.BlockHeight
+	if !ctx.Confirmed {
+		height++
+	}
+	if !IsSecp256k1SignatureAlgorithmActive(height) {
+		return errors.New("secp256k1 public key type is not (yet) accepted")
+	}
+	if len(pk) != crypto.Secp256k1PublicKeySize {
+		return errors.New("invalid public key size in transaction")
+	}
+	if len(signature) != crypto.Secp256k1SignatureSize {
+		return errors.New("invalid signature size in transaction")
+	}
+	return nil
+}
+
+// SignHash implements SignatureAlgorithm.SignHash
+func (secp256k1SignatureAlgorithm) SignHash(hash crypto.Hash, key interface{}) (ByteSlice, error) {
+	// decode the secp256k1 secretKey
+	var secpSK crypto.Secp256k1SecretKey
+	switch k := key.(type) {
+	case crypto.Secp256k1SecretKey:
+		secpSK = k
+	case ByteSlice:
+		if len(k) != crypto.Secp256k1SecretKeySize {
+			return nil, errors.New("invalid secret key size")
+		}
+		copy(secpSK[:], k)
+	case []byte:
+		if len(k) != crypto.Secp256k1SecretKeySize {
+			return nil, errors.New("invalid secret key size")
+		}
+		copy(secpSK[:], k)
+	default:
+		return nil, fmt.Errorf("%T is an unknown secret key type", key)
+	}
+	if secpSK.IsNil() {
+		return nil, crypto.ErrSecretNilKey
+	}
+	sig := crypto.SignHashSecp256k1(hash, secpSK)
+	return sig[:], nil
+}
+
+// VerifyHash implements SignatureAlgorithm.VerifyHash
+func (secp256k1SignatureAlgorithm) VerifyHash(hash crypto.Hash, pk, signature ByteSlice) error {
+	// Decode the public key and signature.
+	var (
+		secpPK  crypto.Secp256k1PublicKey
+		secpSig crypto.Secp256k1Signature
+	)
+	if len(pk) != crypto.Secp256k1PublicKeySize {
+		return crypto.ErrInvalidSecp256k1PublicKey
+	}
+	if len(signature) != crypto.Secp256k1SignatureSize {
+		return crypto.ErrInvalidSignature
+	}
+	copy(secpPK[:], pk)
+	copy(secpSig[:], signature)
+	return crypto.VerifyHashSecp256k1(hash, secpPK, secpSig)
+}
+
+// UnlockHash implements SignatureAlgorithm.UnlockHash
+func (secp256k1SignatureAlgorithm) UnlockHash(pk PublicKey) (UnlockHash, error) {
+	return pubKeyUnlockHash(pk)
+}
@@ -0,0 +1,175 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/NebulousLabs/fastrand"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+)
+
+// testSignatureAlgorithm is an (insecure) signature algorithm used to test the signature algorithm registry,
+// where the public key equals the secret key and the signature is the hash of the key and signed hash.
+type testSignatureAlgorithm struct{}
+
+const testSignatureAlgoType SignatureAlgoType = 42
+
+var testSignatureAlgoSpecifier = Specifier{'t', 'e', 's', 't'}
+
+func (testSignatureAlgorithm) Specifier() Specifier { return testSignatureAlgoSpecifier }
+func (testSignatureAlgorithm) PublicKeySize() int   { return 16 }
+
+func (testSignatureAlgorithm) ValidatePublicKey(pk ByteSlice) error {
+	if len(pk) != 16 {
+		return errors.New("invalid public key size")
+	}
+	return nil
+}
+
+func (algo testSignatureAlgorithm) IsStandardSignature(pk, signature ByteSlice, _ ValidationContext) error {
+	if len(signature) != crypto.HashSize {
+		return errors.New("invalid signature size")
+	}
+	return algo.ValidatePublicKey(pk)
+}
+
+func (testSignatureAlgorithm) SignHash(hash crypto.Hash, key interface{}) (ByteSlice, error) {
+	k, ok := key.(ByteSlice)
+	if !ok {
+		return nil, errors.New("unknown key type")
+	}
+	sig, err := crypto.HashAll(k, hash)
+	if err != nil {
+		return nil, err
+	}
+	return sig[:], nil
+}
+
+func (algo testSignatureAlgorithm) VerifyHash(hash crypto.Hash, pk, signature ByteSlice) error {
+	sig, err := algo.SignHash(hash, pk)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sig, signature) {
+		return crypto.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (testSignatureAlgorithm) UnlockHash(pk PublicKey) (UnlockHash, error) {
+	return UnlockHash{
+		Type: UnlockTypePubKey,
+		Hash: crypto.HashBytes(pk.Key),
+	}, nil
+}
+
+func TestRegisterSignatureAlgorithm(t *testing.T) {
+	pk := PublicKey{
+		Algorithm: testSignatureAlgoType,
+		Key:       fastrand.Bytes(16),
+	}
+	var sat SignatureAlgoType
+	if err := sat.LoadString("test"); err == nil {
+		t.Fatal("unregistered signature algorithm is not expected to be known")
+	}
+	if _, err := GetSignatureAlgorithm(testSignatureAlgoType); err != ErrUnknownSignAlgorithmType {
+		t.Fatalf("unexpected error for unregistered signature algorithm: %v", err)
+	}
+
+	RegisterSignatureAlgorithm(testSignatureAlgoType, testSignatureAlgorithm{})
+	defer RegisterSignatureAlgorithm(testSignatureAlgoType, nil)
+
+	// the algorithm is now known to all encodings
+	if err := sat.LoadString("test"); err != nil || sat != testSignatureAlgoType {
+		t.Fatalf("failed to load registered signature algorithm: %v (%d)", err, sat)
+	}
+	if str := pk.String(); str != "test:"+pk.Key.String() {
+		t.Fatalf("unexpected public key string: %s", str)
+	}
+	b, err := json.Marshal(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonPK PublicKey
+	if err = json.Unmarshal(b, &jsonPK); err != nil {
+		t.Fatal(err)
+	}
+	b, err = siabin.Marshal(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var siaPK PublicKey
+	if err = siabin.Unmarshal(b, &siaPK); err != nil {
+		t.Fatal(err)
+	}
+	b, err = rivbin.Marshal(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rivPK PublicKey
+	if err = rivbin.Unmarshal(b, &rivPK); err != nil {
+		t.Fatal(err)
+	}
+	for _, decodedPK := range []PublicKey{jsonPK, siaPK, rivPK} {
+		if decodedPK.Algorithm != pk.Algorithm || !bytes.Equal(decodedPK.Key, pk.Key) {
+			t.Errorf("%v != %v", decodedPK, pk)
+		}
+	}
+
+	// the unlock hash is derived by the algorithm
+	uh, err := NewPubKeyUnlockHash(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := crypto.HashBytes(pk.Key); uh.Hash != expected {
+		t.Fatalf("unexpected unlock hash: %v", uh)
+	}
+
+	// the algorithm is used to sign and verify fulfillments
+	condition := NewCondition(NewUnlockHashCondition(uh))
+	txn := Transaction{
+		Version: TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []CoinInput{{
+			Fulfillment: NewFulfillment(NewSingleSignatureFulfillment(pk)),
+		}},
+	}
+	err = txn.CoinInputs[0].Fulfillment.Sign(FulfillmentSignContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  txn,
+		Key:          pk.Key,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = txn.CoinInputs[0].Fulfillment.IsStandardFulfillment(ValidationContext{}); err != nil {
+		t.Fatal(err)
+	}
+	err = condition.Fulfill(txn.CoinInputs[0].Fulfillment, FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		BlockTime:    CurrentTimestamp(),
+		Transaction:  txn,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the specifier of a registered algorithm cannot be reused
+	func() {
+		defer func() {
+			if e := recover(); e == nil {
+				t.Error("registering a duplicate specifier is expected to panic")
+			}
+		}()
+		RegisterSignatureAlgorithm(testSignatureAlgoType+1, testSignatureAlgorithm{})
+	}()
+
+	// once unregistered, the algorithm is no longer standard
+	RegisterSignatureAlgorithm(testSignatureAlgoType, nil)
+	if err = txn.CoinInputs[0].Fulfillment.IsStandardFulfillment(ValidationContext{}); err == nil {
+		t.Fatal("unregistered signature algorithm is not expected to be standard")
+	}
+}
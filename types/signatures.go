@@ -27,15 +27,20 @@ const (
 	// SignatureAlgoNil identifies a nil SignatureAlgoType value.
 	SignatureAlgoNil SignatureAlgoType = iota
 	// SignatureAlgoEd25519 identifies the Ed25519 signature Algorithm,
-	// the default (and only) algorithm supported by this chain.
+	// the default algorithm supported by this chain.
 	SignatureAlgoEd25519
+	// SignatureAlgoSecp256k1 identifies the ECDSA signature Algorithm over the secp256k1 curve,
+	// as used by Bitcoin and Ethereum. It is only supported by chains which enabled it,
+	// see EnableSecp256k1SignatureAlgorithm for more information.
+	SignatureAlgoSecp256k1
 )
 
 // These Specifiers enumerate the string versions of the types of signatures that are recognized
 // by this implementation. see Consensus.md for more details.
 var (
-	SignatureAlgoNilSpecifier     = Specifier{}
-	SignatureAlgoEd25519Specifier = Specifier{'e', 'd', '2', '5', '5', '1', '9'}
+	SignatureAlgoNilSpecifier       = Specifier{}
+	SignatureAlgoEd25519Specifier   = Specifier{'e', 'd', '2', '5', '5', '1', '9'}
+	SignatureAlgoSecp256k1Specifier = Specifier{'s', 'e', 'c', 'p', '2', '5', '6', 'k', '1'}
 )
 
 func (sat SignatureAlgoType) String() string {
@@ -45,38 +50,41 @@ func (sat SignatureAlgoType) String() string {
 // Specifier returns the specifier linked to this Signature Algorithm Type,
 // returns the SignatureAlgoNilSpecifier if the algorithm type is unknown.
 func (sat SignatureAlgoType) Specifier() Specifier {
-	switch sat {
-	case SignatureAlgoEd25519:
-		return SignatureAlgoEd25519Specifier
-	default:
+	algorithm, ok := _RegisteredSignatureAlgorithms[sat]
+	if !ok {
 		return SignatureAlgoNilSpecifier
 	}
+	return algorithm.Specifier()
 }
 
 // LoadString loads the stringified algo type as its single byte representation.
 func (sat *SignatureAlgoType) LoadString(str string) error {
-	switch str {
-	case SignatureAlgoEd25519Specifier.String():
-		*sat = SignatureAlgoEd25519
-	case SignatureAlgoNilSpecifier.String():
+	if str == SignatureAlgoNilSpecifier.String() {
 		*sat = SignatureAlgoNil
-	default:
-		return fmt.Errorf("unknown SignatureAlgoType string: %s", str)
+		return nil
 	}
-	return nil
+	for registeredType, algorithm := range _RegisteredSignatureAlgorithms {
+		if algorithm.Specifier().String() == str {
+			*sat = registeredType
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown SignatureAlgoType string: %s", str)
 }
 
 // LoadSpecifier loads the algorithm type in specifier-format.
 func (sat *SignatureAlgoType) LoadSpecifier(specifier Specifier) error {
-	switch specifier {
-	case SignatureAlgoEd25519Specifier:
-		*sat = SignatureAlgoEd25519
-	case SignatureAlgoNilSpecifier:
+	if specifier == SignatureAlgoNilSpecifier {
 		*sat = SignatureAlgoNil
-	default:
-		return fmt.Errorf("unknown SignatureAlgoType specifier: %s", specifier.String())
+		return nil
 	}
-	return nil
+	for registeredType, algorithm := range _RegisteredSignatureAlgorithms {
+		if algorithm.Specifier() == specifier {
+			*sat = registeredType
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown SignatureAlgoType specifier: %s", specifier.String())
 }
 
 // Signature-related errors
@@ -116,6 +124,15 @@ func Ed25519PublicKey(pk crypto.PublicKey) PublicKey {
 	}
 }
 
+// Secp256k1PublicKey returns pk as a PublicKey, denoting its algorithm as
+// Secp256k1.
+func Secp256k1PublicKey(pk crypto.Secp256k1PublicKey) PublicKey {
+	return PublicKey{
+		Algorithm: SignatureAlgoSecp256k1,
+		Key:       pk[:],
+	}
+}
+
 // SignatureHash returns the hash of all fields in a transaction,
 // relevant to a Tx sig.
 func (t Transaction) SignatureHash(extraObjects ...interface{}) (crypto.Hash, error) {
@@ -243,14 +260,15 @@ func (pk *PublicKey) UnmarshalRivine(r io.Reader) error {
 		return err
 	}
 	// create the expected sized byte slice, depending on the algorithm type
-	switch pk.Algorithm {
-	case SignatureAlgoEd25519:
-		pk.Key = make(ByteSlice, crypto.PublicKeySize)
-	case SignatureAlgoNil:
+	if pk.Algorithm == SignatureAlgoNil {
 		pk.Key = nil
-	default:
+		return nil
+	}
+	algorithm, ok := _RegisteredSignatureAlgorithms[pk.Algorithm]
+	if !ok {
 		return fmt.Errorf("unknown SignatureAlgoType %d", pk.Algorithm)
 	}
+	pk.Key = make(ByteSlice, algorithm.PublicKeySize())
 	// read byte slice
 	_, err = io.ReadFull(r, pk.Key[:])
 	return err
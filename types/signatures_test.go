@@ -8,6 +8,8 @@ import (
 
 	"github.com/NebulousLabs/fastrand"
 	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
 )
 
 // TestEd25519PublicKey tests the Ed25519PublicKey function.
@@ -139,3 +141,55 @@ func TestPublicKeyString(t *testing.T) {
 		t.Error("got wrong value for spk.String():", spk.String())
 	}
 }
+
+// TestSecp256k1PublicKey tests the Secp256k1PublicKey function,
+// as well as the encoding of such a public key.
+func TestSecp256k1PublicKey(t *testing.T) {
+	_, pk := crypto.GenerateSecp256k1KeyPair()
+	spk := Secp256k1PublicKey(pk)
+	if spk.Algorithm != SignatureAlgoSecp256k1 {
+		t.Error("spk created key with wrong algorithm specifier:", spk.Algorithm)
+	}
+	if !bytes.Equal(spk.Key, pk[:]) {
+		t.Error("Secp256k1PublicKey created key with wrong data")
+	}
+
+	str := spk.String()
+	if !strings.HasPrefix(str, "secp256k1:") {
+		t.Errorf("unexpected secp256k1 public key string: %s", str)
+	}
+	var loadedPK PublicKey
+	if err := loadedPK.LoadString(str); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loadedPK, spk) {
+		t.Errorf("%v != %v", loadedPK, spk)
+	}
+
+	b, err := siabin.Marshal(spk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedPK = PublicKey{}
+	if err = siabin.Unmarshal(b, &loadedPK); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loadedPK, spk) {
+		t.Errorf("%v != %v", loadedPK, spk)
+	}
+
+	b, err = rivbin.Marshal(spk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 1+crypto.Secp256k1PublicKeySize {
+		t.Errorf("unexpected rivine-encoded secp256k1 public key size: %d", len(b))
+	}
+	loadedPK = PublicKey{}
+	if err = rivbin.Unmarshal(b, &loadedPK); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loadedPK, spk) {
+		t.Errorf("%v != %v", loadedPK, spk)
+	}
+}
@@ -230,8 +230,22 @@ type (
 		// BlockTime defines the time of the currently last registered block,
 		// the transaction belonged to.
 		BlockTime Timestamp
+		// OutputCreationHeight defines the height of the block
+		// which created the output that is being spent.
+		OutputCreationHeight BlockHeight
+		// OutputCreationTime defines the time of the block
+		// which created the output that is being spent.
+		OutputCreationTime Timestamp
+		// HasOutputCreation defines if OutputCreationHeight and OutputCreationTime are known,
+		// conditions relative to the creation of the output are never fulfilled if not.
+		HasOutputCreation bool
 		// (Parent) transaction the fulfillment belongs to.
 		Transaction Transaction
+		// SpentCoinOutputs and SpentBlockStakeOutputs define (optionally)
+		// the outputs spent by the inputs of the (parent) transaction,
+		// such that a condition can restrict where the value it locks is sent to.
+		SpentCoinOutputs       map[CoinOutputID]CoinOutput
+		SpentBlockStakeOutputs map[BlockStakeOutputID]BlockStakeOutput
 	}
 
 	// FulfillableContext is given as part of the fulfillable call of an UnlockCondition,
@@ -242,6 +256,15 @@ type (
 		// BlockTime defines the time of the currently last registered block,
 		// the transaction belonged to.
 		BlockTime Timestamp
+		// OutputCreationHeight defines the height of the block
+		// which created the output, if known.
+		OutputCreationHeight BlockHeight
+		// OutputCreationTime defines the time of the block
+		// which created the output, if known.
+		OutputCreationTime Timestamp
+		// HasOutputCreation defines if OutputCreationHeight and OutputCreationTime are known,
+		// conditions relative to the creation of the output are never fulfillable if not.
+		HasOutputCreation bool
 	}
 
 	// FundValidationContext is used for coin- and block stake- validators,
@@ -408,6 +431,11 @@ var (
 	// NOTE That verification of unknown signing algorithm types does always succeed!
 	ErrUnknownSignAlgorithmType = errors.New("unknown signature algorithm type")
 
+	// ErrInactiveSignAlgorithmType is an error returned in case
+	// a fulfillment is signed using a signature algorithm which
+	// is not (yet) accepted at the block height of the transaction.
+	ErrInactiveSignAlgorithmType = errors.New("signature algorithm is not (yet) accepted")
+
 	// ErrInsufficientSignatures is an error returned when a multisig
 	// condition is attempted to be fulfilled, but the fulfillment does not
 	// (yet) have the required amount of signatures
@@ -586,6 +614,13 @@ type (
 		PublicKey  PublicKey
 		PrivateKey ByteSlice
 	}
+
+	// ExternalKeyPair is a public key, of which the matching private key
+	// is held by an external signer, such as a hardware wallet.
+	ExternalKeyPair struct {
+		PublicKey PublicKey
+		Signer    HashSigner
+	}
 )
 
 const (
@@ -624,8 +659,8 @@ var (
 func (n *NilCondition) Fulfill(fulfillment UnlockFulfillment, ctx FulfillContext) error {
 	switch tf := fulfillment.(type) {
 	case *SingleSignatureFulfillment:
-		return verifyHashUsingPublicKey(tf.PublicKey,
-			ctx.Transaction, tf.Signature, ctx.ExtraObjects)
+		return verifyFulfillmentSignature(tf.PublicKey,
+			ctx, tf.Signature, ctx.ExtraObjects)
 	default:
 		return ErrUnexpectedUnlockFulfillment
 	}
@@ -712,7 +747,7 @@ func (uh *UnlockHashCondition) Fulfill(fulfillment UnlockFulfillment, ctx Fulfil
 		if euh != uh.TargetUnlockHash {
 			return errors.New("single signature fulfillment provides wrong public key")
 		}
-		return verifyHashUsingPublicKey(tf.PublicKey, ctx.Transaction, tf.Signature, ctx.ExtraObjects)
+		return verifyFulfillmentSignature(tf.PublicKey, ctx, tf.Signature, ctx.ExtraObjects)
 
 	case *LegacyAtomicSwapFulfillment:
 		// only UnlockTypeAtomicSwap is supported when fulfilling using a LegacyAtomicSwapFulfillment
@@ -748,8 +783,8 @@ func (uh *UnlockHashCondition) Fulfill(fulfillment UnlockFulfillment, ctx Fulfil
 			}
 
 			// verify signature
-			err := verifyHashUsingPublicKey(
-				tf.PublicKey, ctx.Transaction, tf.Signature,
+			err := verifyFulfillmentSignature(
+				tf.PublicKey, ctx, tf.Signature,
 				mergeExtraObjects(ctx.ExtraObjects, tf.PublicKey, tf.Secret))
 			if err != nil {
 				return err
@@ -772,8 +807,8 @@ func (uh *UnlockHashCondition) Fulfill(fulfillment UnlockFulfillment, ctx Fulfil
 
 		// after the deadline (timelock),
 		// only the original sender can reclaim the unspend output
-		return verifyHashUsingPublicKey(
-			tf.PublicKey, ctx.Transaction, tf.Signature,
+		return verifyFulfillmentSignature(
+			tf.PublicKey, ctx, tf.Signature,
 			mergeExtraObjects(ctx.ExtraObjects, tf.PublicKey))
 
 	case *anyAtomicSwapFulfillment:
@@ -848,8 +883,8 @@ func (ss *SingleSignatureFulfillment) FulfillmentType() FulfillmentType {
 }
 
 // IsStandardFulfillment implements UnlockFulfillment.IsStandardFulfillment
-func (ss *SingleSignatureFulfillment) IsStandardFulfillment(ValidationContext) error {
-	return strictSignatureCheck(ss.PublicKey, ss.Signature)
+func (ss *SingleSignatureFulfillment) IsStandardFulfillment(ctx ValidationContext) error {
+	return strictSignatureCheck(ss.PublicKey, ss.Signature, ctx)
 }
 
 // Equal implements UnlockFulfillment.Equal
@@ -912,8 +947,8 @@ func (as *AtomicSwapCondition) Fulfill(fulfillment UnlockFulfillment, ctx Fulfil
 			}
 
 			// verify signature
-			return verifyHashUsingPublicKey(
-				tf.PublicKey, ctx.Transaction, tf.Signature,
+			return verifyFulfillmentSignature(
+				tf.PublicKey, ctx, tf.Signature,
 				mergeExtraObjects(ctx.ExtraObjects, tf.PublicKey, tf.Secret))
 		}
 
@@ -929,8 +964,8 @@ func (as *AtomicSwapCondition) Fulfill(fulfillment UnlockFulfillment, ctx Fulfil
 			return ErrInvalidRedeemer
 		}
 		// verify the signature is indeed done by
-		return verifyHashUsingPublicKey(
-			tf.PublicKey, ctx.Transaction, tf.Signature,
+		return verifyFulfillmentSignature(
+			tf.PublicKey, ctx, tf.Signature,
 			mergeExtraObjects(ctx.ExtraObjects, tf.PublicKey))
 
 	case *LegacyAtomicSwapFulfillment:
@@ -1077,8 +1112,8 @@ func (as *AtomicSwapFulfillment) Sign(ctx FulfillmentSignContext) error {
 func (as *AtomicSwapFulfillment) FulfillmentType() FulfillmentType { return FulfillmentTypeAtomicSwap }
 
 // IsStandardFulfillment implements UnlockFulfillment.IsStandardFulfillment
-func (as *AtomicSwapFulfillment) IsStandardFulfillment(ValidationContext) error {
-	return strictSignatureCheck(as.PublicKey, as.Signature)
+func (as *AtomicSwapFulfillment) IsStandardFulfillment(ctx ValidationContext) error {
+	return strictSignatureCheck(as.PublicKey, as.Signature, ctx)
 }
 
 // Equal implements UnlockFulfillment.Equal
@@ -1142,7 +1177,7 @@ func (as *LegacyAtomicSwapFulfillment) FulfillmentType() FulfillmentType {
 }
 
 // IsStandardFulfillment implements UnlockFulfillment.IsStandardFulfillment
-func (as *LegacyAtomicSwapFulfillment) IsStandardFulfillment(ValidationContext) error {
+func (as *LegacyAtomicSwapFulfillment) IsStandardFulfillment(ctx ValidationContext) error {
 	if as.Sender.Type != UnlockTypePubKey || as.Receiver.Type != UnlockTypePubKey {
 		return errors.New("unsupported unlock hash type")
 	}
@@ -1152,7 +1187,7 @@ func (as *LegacyAtomicSwapFulfillment) IsStandardFulfillment(ValidationContext)
 	if as.HashedSecret == (AtomicSwapHashedSecret{}) {
 		return errors.New("nil hashed secret not allowed")
 	}
-	return strictSignatureCheck(as.PublicKey, as.Signature)
+	return strictSignatureCheck(as.PublicKey, as.Signature, ctx)
 }
 
 // Equal implements UnlockFulfillment.Equal
@@ -1607,8 +1642,8 @@ func (ms *MultiSignatureCondition) Fulfill(fulfillment UnlockFulfillment, ctx Fu
 
 	// Finally verify all the signatures
 	for _, pks := range tf.Pairs {
-		if err := verifyHashUsingPublicKey(
-			pks.PublicKey, ctx.Transaction, pks.Signature,
+		if err := verifyFulfillmentSignature(
+			pks.PublicKey, ctx, pks.Signature,
 			mergeExtraObjects(ctx.ExtraObjects, pks.PublicKey),
 		); err != nil {
 			return err
@@ -1751,13 +1786,13 @@ func (ms *MultiSignatureFulfillment) FulfillmentType() FulfillmentType {
 }
 
 // IsStandardFulfillment implements UnlockFulfillment.IsStandardFulfillment
-func (ms *MultiSignatureFulfillment) IsStandardFulfillment(ValidationContext) error {
+func (ms *MultiSignatureFulfillment) IsStandardFulfillment(ctx ValidationContext) error {
 	if len(ms.Pairs) == 0 {
 		return errors.New("At least one pair must be provided")
 	}
 	var err error
 	for _, pair := range ms.Pairs {
-		err = strictSignatureCheck(pair.PublicKey, pair.Signature)
+		err = strictSignatureCheck(pair.PublicKey, pair.Signature, ctx)
 		if err != nil {
 			break
 		}
@@ -1796,20 +1831,28 @@ func (ms *MultiSignatureFulfillment) Equal(f UnlockFulfillment) bool {
 
 // Sign implements UnlockFulfillment.Sign
 func (ms *MultiSignatureFulfillment) Sign(ctx FulfillmentSignContext) (err error) {
-	keypair, ok := ctx.Key.(KeyPair)
-	if !ok {
+	var (
+		pk  PublicKey
+		key interface{}
+	)
+	switch keypair := ctx.Key.(type) {
+	case KeyPair:
+		pk, key = keypair.PublicKey, keypair.PrivateKey
+	case ExternalKeyPair:
+		pk, key = keypair.PublicKey, keypair.Signer
+	default:
 		return errors.New("Invalid keypair to sign this input")
 	}
 
 	signature, err := signHashUsingPublicKey(
-		keypair.PublicKey, ctx.Transaction, keypair.PrivateKey,
-		mergeExtraObjects(ctx.ExtraObjects, keypair.PublicKey))
+		pk, ctx.Transaction, key,
+		mergeExtraObjects(ctx.ExtraObjects, pk))
 	if err != nil {
 		return
 	}
 
 	// Only modify the fulfillment in case the signature was created successfully
-	ms.Pairs = append(ms.Pairs, PublicKeySignaturePair{PublicKey: keypair.PublicKey, Signature: signature})
+	ms.Pairs = append(ms.Pairs, PublicKeySignaturePair{PublicKey: pk, Signature: signature})
 	return
 }
 
@@ -2371,20 +2414,14 @@ var (
 
 // strictSignatureCheck is used as part of the IsStandardFulfillment
 // check of any Fulfillment which has a signature as part of its body.
-// It ensures that the given public key and signature are a valid pair.
-func strictSignatureCheck(pk PublicKey, signature ByteSlice) error {
-	switch pk.Algorithm {
-	case SignatureAlgoEd25519:
-		if len(pk.Key) != crypto.PublicKeySize {
-			return errors.New("invalid public key size in transaction")
-		}
-		if len(signature) != crypto.SignatureSize {
-			return errors.New("invalid signature size in transaction")
-		}
-		return nil
-	default:
+// It ensures that the given public key and signature are a valid pair,
+// and that the signature algorithm is accepted within the given context.
+func strictSignatureCheck(pk PublicKey, signature ByteSlice, ctx ValidationContext) error {
+	algorithm, ok := _RegisteredSignatureAlgorithms[pk.Algorithm]
+	if !ok {
 		return errors.New("unrecognized public key type in transaction")
 	}
+	return algorithm.IsStandardSignature(pk.Key, signature, ctx)
 }
 
 func mergeExtraObjects(extraObjects []interface{}, fulfillmentDefinedObjects ...interface{}) []interface{} {
@@ -2403,40 +2440,42 @@ func mergeExtraObjects(extraObjects []interface{}, fulfillmentDefinedObjects ...
 // using the given (optional private) key, and using any extra objects (on top of the normal properties).
 // The public key is to be given, as based on that the function can figure out what algorithm to use,
 // and this also allows the function to know how to interpret the given (private) key.
+//
+// In case the given key is a HashSigner, the hash is signed by that signer instead,
+// after which the returned signature is verified, as the signer is not trusted to sign correctly.
 func signHashUsingPublicKey(pk PublicKey, tx Transaction, key interface{}, extraObjects []interface{}) ([]byte, error) {
-	switch pk.Algorithm {
-	case SignatureAlgoEd25519:
-		// decode the ed-secretKey
-		var edSK crypto.SecretKey
-		switch k := key.(type) {
-		case crypto.SecretKey:
-			edSK = k
-		case ByteSlice:
-			if len(k) != crypto.SecretKeySize {
-				return nil, errors.New("invalid secret key size")
-			}
-			copy(edSK[:], k)
-		case []byte:
-			if len(k) != crypto.SecretKeySize {
-				return nil, errors.New("invalid secret key size")
-			}
-			copy(edSK[:], k)
-		default:
-			return nil, fmt.Errorf("%T is an unknown secret key type", key)
-		}
-		if edSK.IsNil() {
-			return nil, crypto.ErrSecretNilKey
-		}
-		sigHash, err := tx.SignatureHash(extraObjects...)
-		if err != nil {
-			return nil, err
-		}
-		sig := crypto.SignHash(sigHash, edSK)
-		return sig[:], nil
-
-	default:
+	algorithm, ok := _RegisteredSignatureAlgorithms[pk.Algorithm]
+	if !ok {
 		return nil, ErrUnknownSignAlgorithmType
 	}
+	sigHash, err := tx.SignatureHash(extraObjects...)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(HashSigner)
+	if !ok {
+		return algorithm.SignHash(sigHash, key)
+	}
+	signature, err := signer.SignHash(pk, sigHash, tx, extraObjects)
+	if err != nil {
+		return nil, err
+	}
+	err = algorithm.VerifyHash(sigHash, pk.Key, signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature returned by external signer: %v", err)
+	}
+	return signature, nil
+}
+
+// verifyFulfillmentSignature verifies the given signature as part of a fulfillment,
+// ensuring first that the signature algorithm of the given public key
+// is accepted at the block height of the given context.
+func verifyFulfillmentSignature(pk PublicKey, ctx FulfillContext, sig []byte, extraObjects []interface{}) error {
+	err := ValidateSignatureAlgorithmActivation(pk, ctx.BlockHeight)
+	if err != nil {
+		return err
+	}
+	return verifyHashUsingPublicKey(pk, ctx.Transaction, sig, extraObjects)
 }
 
 // verifyHashUsingPublicKey verfies the given signature.
@@ -2448,30 +2487,16 @@ func signHashUsingPublicKey(pk PublicKey, tx Transaction, key interface{}, extra
 // 2. using the algorithm type of the given public key,
 //    as to figure out what signature algorithm is used,
 //    and thus being able to know how to verify the given signature;
-func verifyHashUsingPublicKey(pk PublicKey, tx Transaction, sig []byte, extraObjects []interface{}) (err error) {
-	switch pk.Algorithm {
-	case SignatureAlgoEd25519:
-		// Decode the public key and signature.
-		var (
-			edPK  crypto.PublicKey
-			edSig crypto.Signature
-		)
-		copy(edPK[:], pk.Key)
-		copy(edSig[:], sig)
-		if edPK.IsNil() {
-			return crypto.ErrPublicNilKey
-		}
-		cryptoSig := crypto.Signature(edSig)
-		var sigHash crypto.Hash
-		sigHash, err = tx.SignatureHash(extraObjects...)
-		if err == nil {
-			err = crypto.VerifyHash(sigHash, edPK, cryptoSig)
-		}
-
-	default:
-		err = ErrUnknownSignAlgorithmType
+func verifyHashUsingPublicKey(pk PublicKey, tx Transaction, sig []byte, extraObjects []interface{}) error {
+	algorithm, ok := _RegisteredSignatureAlgorithms[pk.Algorithm]
+	if !ok {
+		return ErrUnknownSignAlgorithmType
 	}
-	return
+	sigHash, err := tx.SignatureHash(extraObjects...)
+	if err != nil {
+		return err
+	}
+	return algorithm.VerifyHash(sigHash, pk.Key, sig)
 }
 
 // ComputeLegacyFulfillmentUnlockHash computes unlock hashes as they used to be computed,
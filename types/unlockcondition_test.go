@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/NebulousLabs/fastrand"
 	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
 	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
@@ -3257,3 +3258,151 @@ func TestMultisigAddresses(t *testing.T) {
 		t.Fatalf("expected uh2 (%s) to be equal to uh3 (%s), but the they weren't", uh2.String(), uh3.String())
 	}
 }
+
+func TestSecp256k1SingleSignatureFulfillment(t *testing.T) {
+	EnableSecp256k1SignatureAlgorithm(0)
+	defer DisableSecp256k1SignatureAlgorithm()
+
+	sk, cpk := crypto.GenerateSecp256k1KeyPair()
+	pk := Secp256k1PublicKey(cpk)
+	uh, err := NewPubKeyUnlockHash(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := NewCondition(NewUnlockHashCondition(uh))
+
+	txn := Transaction{
+		Version: TestnetChainConstants().DefaultTransactionVersion,
+		CoinInputs: []CoinInput{{
+			ParentID:    CoinOutputID(crypto.HashBytes(fastrand.Bytes(8))),
+			Fulfillment: NewFulfillment(NewSingleSignatureFulfillment(pk)),
+		}},
+		CoinOutputs: []CoinOutput{{
+			Value:     NewCurrency64(1000),
+			Condition: condition,
+		}},
+	}
+	err = txn.CoinInputs[0].Fulfillment.Sign(FulfillmentSignContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  txn,
+		Key:          sk,
+	})
+	if err != nil {
+		t.Fatal("signing coin input failed", err)
+	}
+	fulfillContext := FulfillContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		BlockTime:    CurrentTimestamp(),
+		Transaction:  txn,
+	}
+	if err = condition.Fulfill(txn.CoinInputs[0].Fulfillment, fulfillContext); err != nil {
+		t.Fatal("fulfilling coin input failed", err)
+	}
+
+	// a fulfillment signed with another key should not be valid
+	otherSK, _ := crypto.GenerateSecp256k1KeyPair()
+	ss := txn.CoinInputs[0].Fulfillment.Fulfillment.(*SingleSignatureFulfillment)
+	validSignature := ss.Signature
+	ss.Signature = nil
+	err = txn.CoinInputs[0].Fulfillment.Sign(FulfillmentSignContext{
+		ExtraObjects: []interface{}{uint64(0)},
+		Transaction:  txn,
+		Key:          ByteSlice(otherSK[:]),
+	})
+	if err != nil {
+		t.Fatal("signing coin input failed", err)
+	}
+	if err = condition.Fulfill(txn.CoinInputs[0].Fulfillment, fulfillContext); err == nil {
+		t.Fatal("fulfilling coin input using the wrong key succeeded")
+	}
+	ss.Signature = validSignature
+
+	// the fulfillment should survive all encoding roundtrips
+	for _, roundtrip := range []func() (UnlockFulfillmentProxy, error){
+		func() (f UnlockFulfillmentProxy, err error) {
+			b, err := json.Marshal(txn.CoinInputs[0].Fulfillment)
+			if err == nil {
+				err = json.Unmarshal(b, &f)
+			}
+			return
+		},
+		func() (f UnlockFulfillmentProxy, err error) {
+			b, err := siabin.Marshal(txn.CoinInputs[0].Fulfillment)
+			if err == nil {
+				err = siabin.Unmarshal(b, &f)
+			}
+			return
+		},
+		func() (f UnlockFulfillmentProxy, err error) {
+			b, err := rivbin.Marshal(txn.CoinInputs[0].Fulfillment)
+			if err == nil {
+				err = rivbin.Unmarshal(b, &f)
+			}
+			return
+		},
+	} {
+		f, err := roundtrip()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !f.Equal(txn.CoinInputs[0].Fulfillment.Fulfillment) {
+			t.Fatalf("fulfillment %v does not equal %v after roundtrip", f, txn.CoinInputs[0].Fulfillment)
+		}
+	}
+
+	// the secp256k1 algorithm is only standard when enabled, and only starting from its activation height
+	testCases := []struct {
+		Enabled          bool
+		ActivationHeight BlockHeight
+		Context          ValidationContext
+		Standard         bool
+	}{
+		{false, 0, ValidationContext{Confirmed: true, BlockHeight: 42}, false},
+		{true, 0, ValidationContext{Confirmed: true, BlockHeight: 0}, true},
+		{true, 42, ValidationContext{Confirmed: true, BlockHeight: 41}, false},
+		{true, 42, ValidationContext{Confirmed: true, BlockHeight: 42}, true},
+		{true, 42, ValidationContext{Confirmed: false, BlockHeight: 40}, false},
+		{true, 42, ValidationContext{Confirmed: false, BlockHeight: 41}, true},
+	}
+	for idx, testCase := range testCases {
+		if testCase.Enabled {
+			EnableSecp256k1SignatureAlgorithm(testCase.ActivationHeight)
+		} else {
+			DisableSecp256k1SignatureAlgorithm()
+		}
+		err := txn.CoinInputs[0].Fulfillment.IsStandardFulfillment(testCase.Context)
+		if testCase.Standard && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Standard && err == nil {
+			t.Errorf("test case #%d: expected fulfillment to be non-standard", idx)
+		}
+	}
+
+	// the secp256k1 algorithm is only valid when enabled, and only starting from its activation height
+	fulfillTestCases := []struct {
+		Enabled          bool
+		ActivationHeight BlockHeight
+		BlockHeight      BlockHeight
+		Valid            bool
+	}{
+		{false, 0, 42, false},
+		{true, 0, 0, true},
+		{true, 42, 41, false},
+		{true, 42, 42, true},
+		{true, 42, 43, true},
+	}
+	for idx, testCase := range fulfillTestCases {
+		if testCase.Enabled {
+			EnableSecp256k1SignatureAlgorithm(testCase.ActivationHeight)
+		} else {
+			DisableSecp256k1SignatureAlgorithm()
+		}
+		fulfillContext.BlockHeight = testCase.BlockHeight
+		err := condition.Fulfill(txn.CoinInputs[0].Fulfillment, fulfillContext)
+		if testCase.Valid && err != nil {
+			t.Errorf("fulfill test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err != ErrInactiveSignAlgorithmType {
+			t.Errorf("fulfill test case #%d: expected error %v, not: %v", idx, ErrInactiveSignAlgorithmType, err)
+		}
+	}
+}
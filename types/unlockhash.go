@@ -86,20 +86,13 @@ func NewEd25519PubKeyUnlockHash(pk crypto.PublicKey) (UnlockHash, error) {
 }
 
 // NewPubKeyUnlockHash creates a new unlock hash of type UnlockTypePubKey,
-// using a given Sia-standard Public key.
+// using a given Sia-standard Public key. The unlock hash is derived
+// by the signature algorithm of the public key, if it is registered.
 func NewPubKeyUnlockHash(pk PublicKey) (UnlockHash, error) {
-	pkb, err := siabin.Marshal(pk)
-	if err != nil {
-		return UnlockHash{}, err
-	}
-	h, err := crypto.HashObject(pkb)
-	if err != nil {
-		return UnlockHash{}, err
+	if algorithm, ok := _RegisteredSignatureAlgorithms[pk.Algorithm]; ok {
+		return algorithm.UnlockHash(pk)
 	}
-	return UnlockHash{
-		Type: UnlockTypePubKey,
-		Hash: h,
-	}, nil
+	return pubKeyUnlockHash(pk)
 }
 
 // NewUnlockHash creates a new unlock hash